@@ -1,6 +1,13 @@
 package v1
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -21,9 +28,16 @@ type CompositionList struct {
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Synthesizer",type=string,JSONPath=`.spec.synthesizer.name`
-// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.status.currentSynthesis.synthesized`
+// +kubebuilder:printcolumn:name="Synthesized",type=date,JSONPath=`.status.currentSynthesis.synthesized`
+// +kubebuilder:printcolumn:name="Reconciled",type=date,JSONPath=`.status.currentSynthesis.reconciled`
+// +kubebuilder:printcolumn:name="Ready",type=date,JSONPath=`.status.currentSynthesis.ready`
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.simplified.status`
 // +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.simplified.error`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Health",type=string,JSONPath=`.status.health`,priority=1
+// +kubebuilder:selectablefield:JSONPath=`.spec.synthesizer.name`
+// +kubebuilder:selectablefield:JSONPath=`.status.simplified.status`
+// +kubebuilder:selectablefield:JSONPath=`.status.health`
 type Composition struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -45,14 +59,272 @@ type CompositionSpec struct {
 	// A set of environment variables that will be made available inside the synthesis Pod.
 	// +kubebuilder:validation:MaxItems:=500
 	SynthesisEnv []EnvVar `json:"synthesisEnv,omitempty"`
+
+	// DeletionProtection requires deletion of the composition to be confirmed by setting the
+	// "eno.azure.io/deletion-confirmed" annotation to "true". Until then, none of the composition's
+	// resources will be removed and the finalizer will be retained.
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// DeletionStrategy determines whether the composition's resources are deleted along with it.
+	// Supersedes the deprecated "eno.azure.io/deletion-strategy" annotation, which is honored only when this is unset.
+	// +kubebuilder:validation:Enum:=Delete;Orphan
+	DeletionStrategy DeletionStrategy `json:"deletionStrategy,omitempty"`
+
+	// ReconcileInterval overrides the reconcile interval of every resource in the composition that declares one.
+	// Useful for e.g. reconciling less often in development environments to reduce cost.
+	ReconcileInterval *ReconcileIntervalOverride `json:"reconcileInterval,omitempty"`
+
+	// TargetNamespace is injected into synthesized namespaced resources that don't specify a namespace.
+	// This allows one synthesizer to serve many namespaces without templating namespaces itself.
+	TargetNamespace *TargetNamespace `json:"targetNamespace,omitempty"`
+
+	// NameTransform adds a prefix and/or suffix to the names of synthesized resources.
+	// This allows multiple compositions using the same synthesizer to coexist in one namespace.
+	NameTransform *NameTransform `json:"nameTransform,omitempty"`
+
+	// CommonLabels are set on every synthesized resource, overriding any labels of the same key set by the synthesizer.
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+
+	// CommonAnnotations are set on every synthesized resource, overriding any annotations of the same key set by the synthesizer.
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
+
+	// Identifiers are allocated from IdentifierPools and passed to the synthesizer as inputs.
+	// Each composition holds at most one value per pool, which is stable until the composition is deleted or stops requesting it.
+	Identifiers []IdentifierRequest `json:"identifiers,omitempty"`
+
+	// Manifests are used as the composition's synthesis output instead of running a synthesizer.
+	// Eno synthesizes them in-process, so spec.synthesizer is ignored and no synthesizer pods are created.
+	// Useful for small sets of glue resources, and for testing reconciliation in isolation.
+	Manifests *ManifestSource `json:"manifests,omitempty"`
+
+	// Import records the current state of existing resources as the composition's previous synthesis before it is first synthesized.
+	// This allows the first synthesis to compute accurate three-way patches for resources that were previously managed by another tool,
+	// rather than treating them as new. Has no effect once the composition has been synthesized.
+	Import *ImportSpec `json:"import,omitempty"`
+
+	// Upstreams pass resources synthesized by other compositions in the same namespace to the synthesizer as inputs.
+	// The composition isn't synthesized until every upstream has been synthesized successfully,
+	// and is resynthesized whenever an upstream is. Ignored by compositions that set manifests.
+	Upstreams []UpstreamInput `json:"upstreams,omitempty"`
+
+	// Pin restricts synthesis to a particular synthesizer generation and input revisions.
+	// Set by promotion (see Composition.Promote) so that exactly what was synthesized for another composition is synthesized again.
+	Pin *CompositionPin `json:"pin,omitempty"`
+}
+
+// CompositionPin holds the synthesizer generation and input revisions that a composition must be synthesized with.
+// The composition isn't synthesized while they don't match.
+type CompositionPin struct {
+	SynthesizerGeneration int64            `json:"synthesizerGeneration,omitempty"`
+	InputRevisions        []InputRevisions `json:"inputRevisions,omitempty"`
+}
+
+// UpstreamInputs pass the output of another composition to the synthesizer as inputs.
+type UpstreamInput struct {
+	// Key is the input key used to pass the upstream's resources to the synthesizer.
+	// +required
+	Key string `json:"key"`
+
+	// Composition is the name of the upstream composition in the same namespace.
+	// +required
+	Composition string `json:"composition"`
+
+	// Resources select which of the upstream's synthesized resources are passed.
+	// Only a summary of the upstream's synthesis is passed when empty.
+	Resources []UpstreamResourceSelector `json:"resources,omitempty"`
+}
+
+type UpstreamResourceSelector struct {
+	Group string `json:"group,omitempty"`
+	// +required
+	Kind string `json:"kind"`
+	// Name matches every resource of the kind when empty.
+	Name string `json:"name,omitempty"`
+}
+
+// ImportSpec selects the existing resources to be imported as a composition's previous state.
+type ImportSpec struct {
+	// Resources are the types of resource to import.
+	// +required
+	Resources []ResourceRef `json:"resources"`
+
+	// Namespace must be empty or the composition's namespace.
+	// Resources are only imported from the composition's namespace, and cluster-scoped resources are never imported.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Selector matches the labels of the resources to import.
+	// +required
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// FieldManager limits the imported fields to those owned by the given field manager e.g. "helm" or "kubectl-client-side-apply".
+	// Otherwise every field is imported, so fields set by other clients are removed if the synthesizer doesn't output them.
+	FieldManager string `json:"fieldManager,omitempty"`
+}
+
+// ImportsSecrets returns true when the import includes Secrets, which must be allowed by the operator.
+func (i *ImportSpec) ImportsSecrets() bool {
+	for _, ref := range i.Resources {
+		if ref.Group == "" && ref.Kind == "Secret" {
+			return true
+		}
+	}
+	return false
+}
+
+// ManifestSource holds static manifests, inline and/or in a ConfigMap.
+type ManifestSource struct {
+	// Inline is a stream of YAML or JSON manifests.
+	Inline string `json:"inline,omitempty"`
+
+	// ConfigMap is the name of a ConfigMap in the composition's namespace.
+	// Each of its values is a stream of YAML or JSON manifests, read in order of their keys.
+	// The ConfigMap is read during synthesis, so changes to it take effect when the composition is next synthesized.
+	ConfigMap string `json:"configMap,omitempty"`
+}
+
+// NameTransform renames synthesized resources, along with references to them from other synthesized resources.
+// References in pod templates (ConfigMaps, Secrets, ServiceAccounts, and PersistentVolumeClaims) are updated automatically.
+type NameTransform struct {
+	// +kubebuilder:validation:MaxLength:=63
+	Prefix string `json:"prefix,omitempty"`
+
+	// +kubebuilder:validation:MaxLength:=63
+	Suffix string `json:"suffix,omitempty"`
+
+	// References are additional fields that refer to other synthesized resources by name.
+	References []NameReference `json:"references,omitempty"`
+}
+
+// NameReference identifies a field that refers to another resource by name.
+// The field is only updated when it refers to a resource that was renamed by the same synthesis.
+type NameReference struct {
+	// Kind of the resources that contain the reference.
+	// +required
+	Kind string `json:"kind"`
+
+	// Path of the field within the resource. Fields are separated by dots, and "[]" matches every element of a list.
+	// For example: "spec.template.spec.volumes[].configMap.name".
+	// +required
+	Path string `json:"path"`
+
+	// TargetKind is the kind of the referenced resource.
+	// +required
+	TargetKind string `json:"targetKind"`
+}
+
+// TargetNamespace sets the namespace of a composition's namespaced resources.
+type TargetNamespace struct {
+	// Name of the namespace.
+	// +required
+	// +kubebuilder:validation:MinLength:=1
+	Name string `json:"name"`
+
+	// Override replaces the namespace of resources that already specify one.
+	Override bool `json:"override,omitempty"`
+}
+
+// ReconcileIntervalOverride replaces or scales the reconcile intervals declared by a composition's resources.
+// Resources that don't declare a reconcile interval are not affected.
+type ReconcileIntervalOverride struct {
+	// Interval replaces the reconcile interval of the resources.
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// ScalePercent scales the reconcile interval of the resources (after applying Interval, if set).
+	// For example: 200 reconciles half as often, 50 reconciles twice as often.
+	// +kubebuilder:validation:Minimum:=1
+	ScalePercent *int32 `json:"scalePercent,omitempty"`
 }
 
+// Apply returns the effective reconcile interval given the one declared by a resource.
+func (r *ReconcileIntervalOverride) Apply(declared *metav1.Duration) *metav1.Duration {
+	if r == nil || declared == nil || declared.Duration <= 0 {
+		return declared
+	}
+	next := declared.Duration
+	if r.Interval != nil {
+		next = r.Interval.Duration
+	}
+	if r.ScalePercent != nil && *r.ScalePercent > 0 {
+		next = next * time.Duration(*r.ScalePercent) / 100
+	}
+	return &metav1.Duration{Duration: next}
+}
+
+type DeletionStrategy string
+
+const (
+	// DeletionStrategyDelete removes every resource of the composition when the composition is deleted.
+	DeletionStrategyDelete DeletionStrategy = "Delete"
+
+	// DeletionStrategyOrphan leaves the composition's resources in place when the composition is deleted.
+	DeletionStrategyOrphan DeletionStrategy = "Orphan"
+)
+
 type CompositionStatus struct {
 	Simplified         *SimplifiedStatus `json:"simplified,omitempty"`
 	CurrentSynthesis   *Synthesis        `json:"currentSynthesis,omitempty"`
 	PreviousSynthesis  *Synthesis        `json:"previousSynthesis,omitempty"`
 	PendingResynthesis *metav1.Time      `json:"pendingResynthesis,omitempty"`
-	InputRevisions     []InputRevisions  `json:"inputRevisions,omitempty"`
+
+	// PendingResynthesisReason is the reason of the pending resynthesis, if any.
+	PendingResynthesisReason string `json:"pendingResynthesisReason,omitempty"`
+
+	InputRevisions   []InputRevisions  `json:"inputRevisions,omitempty"`
+	DeletionProgress *DeletionProgress `json:"deletionProgress,omitempty"`
+
+	// Conditions include Degraded, which is set while resources that have already become ready are no longer ready,
+	// and DeletionBlocked, which is set while the deletion of protected resources is blocked.
+	//
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Health is a coarse summary of the composition's resources, intended for fleet-level dashboards and alerts.
+	//
+	// +kubebuilder:validation:Enum=Healthy;Progressing;Degraded;Unknown
+	Health CompositionHealth `json:"health,omitempty"`
+
+	// SynthesizerState holds values recorded by the synthesizer (e.g. allocated names or IP ranges),
+	// which are passed back to subsequent syntheses so they can be idempotent.
+	SynthesizerState map[string]string `json:"synthesizerState,omitempty"`
+
+	// Exports are values extracted from the live state of resources by their eno.azure.io/export-<name> annotations.
+	// They're published once the current synthesis becomes ready, so they reflect the most recent synthesis to do so.
+	Exports map[string]string `json:"exports,omitempty"`
+}
+
+// CompositionHealth summarizes the state of a composition's resources.
+type CompositionHealth string
+
+const (
+	// HealthHealthy compositions have reconciled every resource of their current synthesis, and every resource is ready.
+	HealthHealthy CompositionHealth = "Healthy"
+	// HealthProgressing compositions are synthesizing, reconciling, or waiting for resources to become ready.
+	HealthProgressing CompositionHealth = "Progressing"
+	// HealthDegraded compositions have failed synthesis or have resources that regressed after becoming ready.
+	HealthDegraded CompositionHealth = "Degraded"
+	// HealthUnknown compositions have not been synthesized yet.
+	HealthUnknown CompositionHealth = "Unknown"
+)
+
+// DegradedCondition is set on compositions when resources of the current synthesis have regressed from ready to not ready,
+// or were deleted out-of-band. The composition's readiness is not affected, since readiness latches for each synthesis.
+const DegradedCondition = "Degraded"
+
+// DeletionBlockedCondition is set on compositions when resources that should be deleted are protected
+// by the eno.azure.io/protect annotation. The composition can't finish deleting until the annotation is removed.
+const DeletionBlockedCondition = "DeletionBlocked"
+
+// DeletionProgress summarizes the resources that are still blocking the deletion of a composition.
+type DeletionProgress struct {
+	// Number of resources that have not yet been deleted.
+	RemainingResources int `json:"remainingResources"`
+
+	// Kinds of the resources that have not yet been deleted (bounded).
+	BlockingKinds []string `json:"blockingKinds,omitempty"`
+
+	// EstimatedCompletion is extrapolated from the rate at which resources have been deleted so far.
+	EstimatedCompletion *metav1.Time `json:"estimatedCompletion,omitempty"`
 }
 
 type SimplifiedStatus struct {
@@ -106,8 +378,48 @@ type Synthesis struct {
 	// Deferred is true when this synthesis was caused by a change to either the synthesizer
 	// or an input with a ref that sets `Defer == true`.
 	Deferred bool `json:"deferred,omitempty"`
+
+	// ReadinessMessages describe (a bounded sample of) the resources that are not yet ready.
+	// Cleared once every resource has become ready.
+	ReadinessMessages []string `json:"readinessMessages,omitempty"`
+
+	// Reason describes why the synthesis was initiated e.g. SpecChanged or SynthesizerRollout.
+	Reason string `json:"reason,omitempty"`
+
+	// Progress counts the resources that have been reconciled and become ready so far,
+	// so clients can render the progress of large rollouts without reading every resource slice.
+	Progress *SynthesisProgress `json:"progress,omitempty"`
 }
 
+type SynthesisProgress struct {
+	// Total is the number of resources in the synthesis, including those being deleted.
+	Total int `json:"total"`
+
+	Reconciled int `json:"reconciled"`
+	Ready      int `json:"ready"`
+
+	// Percent is the percentage of resources that are ready, rounded down.
+	Percent int `json:"percent"`
+}
+
+// Reasons that a synthesis can be initiated.
+// Clients that request resynthesis by setting status.pendingResynthesis can give their own reason
+// in status.pendingResynthesisReason, otherwise Manual is used.
+const (
+	SynthesisReasonInitial            = "Initial"
+	SynthesisReasonSpecChanged        = "SpecChanged"
+	SynthesisReasonInputChanged       = "InputChanged"
+	SynthesisReasonSynthesizerRollout = "SynthesizerRollout"
+	SynthesisReasonDeletion           = "Deletion"
+	SynthesisReasonManual             = "Manual"
+
+	// SynthesisReasonImport is the reason of the previous synthesis recorded by spec.import - it isn't a real synthesis.
+	SynthesisReasonImport = "Import"
+
+	// SynthesisReasonRollback restores the previous synthesis of a composition whose synthesizer rollout was aborted.
+	SynthesisReasonRollback = "Rollback"
+)
+
 type Result struct {
 	Message  string            `json:"message,omitempty"`
 	Severity string            `json:"severity,omitempty"`
@@ -143,6 +455,19 @@ func (s *Synthesis) Failed() bool {
 	return false
 }
 
+// ShouldOrphan returns true when the composition's resources should outlive it.
+func (c *Composition) ShouldOrphan() bool {
+	if c.Spec.DeletionStrategy != "" {
+		return c.Spec.DeletionStrategy == DeletionStrategyOrphan
+	}
+	return c.Annotations["eno.azure.io/deletion-strategy"] == "orphan"
+}
+
+// Passthrough returns true when the composition embeds its manifests rather than using a synthesizer.
+func (c *Composition) Passthrough() bool {
+	return c.Spec.Manifests != nil
+}
+
 func (c *Composition) InputsExist(syn *Synthesizer) bool {
 	refs := map[string]struct{}{}
 	for _, ref := range syn.Spec.Refs {
@@ -167,9 +492,122 @@ func (c *Composition) InputsExist(syn *Synthesizer) bool {
 			return false
 		}
 	}
+	for _, up := range c.Spec.Upstreams {
+		if !c.hasInputRevision(up.Key) {
+			return false
+		}
+	}
 	return true
 }
 
+func (c *Composition) hasInputRevision(key string) bool {
+	for _, rev := range c.Status.InputRevisions {
+		if rev.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// PinMismatched returns true when the synthesizer's generation or the composition's input revisions don't match its pin (if any).
+// Inputs that haven't been observed yet are covered by InputsExist.
+func (c *Composition) PinMismatched(synth *Synthesizer) bool {
+	pin := c.Spec.Pin
+	if pin == nil {
+		return false
+	}
+	if pin.SynthesizerGeneration != 0 && pin.SynthesizerGeneration != synth.Generation {
+		return true
+	}
+	for _, want := range pin.InputRevisions {
+		for _, rev := range c.Status.InputRevisions {
+			if rev.Key == want.Key && !want.Equal(rev) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PromotedFromAnnotation and PromotedSynthesisAnnotation are set on compositions created by Promote
+// to identify the composition and synthesis they were promoted from.
+const (
+	PromotedFromAnnotation      = "eno.azure.io/promoted-from"
+	PromotedSynthesisAnnotation = "eno.azure.io/promoted-synthesis"
+)
+
+// Promote returns a copy of the composition with the given namespace and name, pinned to the synthesizer generation
+// and input revisions of the composition's most recent successful synthesis. This allows exactly what was synthesized
+// in one environment (e.g. staging) to be promoted to another.
+//
+// Bindings are copied as-is, so the copy reads the same input resources. Upstream and identifier inputs aren't pinned
+// since they're resolved in the copy's namespace.
+func (c *Composition) Promote(namespace, name string) (*Composition, error) {
+	syn := c.OutputSynthesis()
+	if syn == nil {
+		return nil, errors.New("composition has not been synthesized successfully")
+	}
+	if c.Passthrough() {
+		return nil, errors.New("compositions with static manifests can't be promoted")
+	}
+
+	out := &Composition{}
+	out.Name = name
+	out.Namespace = namespace
+	for k, v := range c.Labels {
+		if out.Labels == nil {
+			out.Labels = map[string]string{}
+		}
+		out.Labels[k] = v
+	}
+	out.Annotations = map[string]string{}
+	for k, v := range c.Annotations {
+		if k == "kubectl.kubernetes.io/last-applied-configuration" {
+			continue
+		}
+		out.Annotations[k] = v
+	}
+	out.Annotations[PromotedFromAnnotation] = c.Namespace + "/" + c.Name
+	out.Annotations[PromotedSynthesisAnnotation] = syn.UUID
+
+	c.Spec.DeepCopyInto(&out.Spec)
+	out.Spec.Pin = &CompositionPin{SynthesizerGeneration: syn.ObservedSynthesizerGeneration}
+	for _, rev := range syn.InputRevisions {
+		for _, b := range c.Spec.Bindings {
+			if b.Key == rev.Key {
+				out.Spec.Pin.InputRevisions = append(out.Spec.Pin.InputRevisions, rev)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// OutputRevision identifies the composition's output (its most recent successful synthesis and exports)
+// for use as an input revision of downstream compositions. Empty when the composition hasn't been synthesized successfully.
+func (c *Composition) OutputRevision() string {
+	syn := c.OutputSynthesis()
+	if syn == nil {
+		return ""
+	}
+	if len(c.Status.Exports) == 0 {
+		return syn.UUID
+	}
+	js, _ := json.Marshal(c.Status.Exports) // keys are sorted
+	hash := sha256.Sum256(js)
+	return syn.UUID + "/" + hex.EncodeToString(hash[:8])
+}
+
+// OutputSynthesis returns the composition's most recent successful synthesis, or nil if it hasn't been synthesized successfully.
+// Failed syntheses aren't reconciled, so the previous synthesis still represents the composition's output.
+func (c *Composition) OutputSynthesis() *Synthesis {
+	syn := c.Status.CurrentSynthesis
+	if syn == nil || syn.Synthesized == nil || syn.Failed() {
+		syn = c.Status.PreviousSynthesis
+	}
+	return syn
+}
+
 // InputsOutOfLockstep returns true when one or more inputs that specify a revision do not match the others.
 // It also returns true if any revision is derived from a synthesizer generation
 // older than the provided synthesizer.
@@ -211,6 +649,65 @@ func (s *CompositionStatus) GetCurrentSynthesisUUID() string {
 	return s.CurrentSynthesis.UUID
 }
 
+// CompositionNameAnnotation and CompositionNamespaceAnnotation are set on resources reconciled by Eno
+// to identify the composition that manages them.
+const (
+	CompositionNameAnnotation      = "eno.azure.io/composition-name"
+	CompositionNamespaceAnnotation = "eno.azure.io/composition-namespace"
+)
+
+// DeletionConfirmedAnnotation confirms the deletion of a composition that has enabled DeletionProtection.
+const DeletionConfirmedAnnotation = "eno.azure.io/deletion-confirmed"
+
+// DeletionBlocked returns true when the composition has been deleted but deletion protection
+// is enabled and the deletion has not yet been confirmed.
+func (c *Composition) DeletionBlocked() bool {
+	return c.DeletionTimestamp != nil && c.Spec.DeletionProtection && c.Annotations[DeletionConfirmedAnnotation] != "true"
+}
+
+// SynthesisPriority returns the priority of the composition's syntheses relative to other compositions,
+// as set by the "eno.azure.io/synthesis-priority" annotation. Higher values are dispatched first.
+// Values that aren't integers are rejected by the composition validation webhook, and are treated as 0 when it isn't enabled.
+func (c *Composition) SynthesisPriority() int {
+	p, _ := strconv.Atoi(c.Annotations["eno.azure.io/synthesis-priority"])
+	return p
+}
+
+// StrictSequencingAnnotation requires each synthesis of a composition to be reconciled before the next one is started,
+// so changes are applied strictly in generation order at the cost of throughput.
+const StrictSequencingAnnotation = "eno.azure.io/strict-sequencing"
+
+// SequencingBlocked returns true when the composition requires strict sequencing and its current synthesis
+// has been dispatched but not yet reconciled, so a new synthesis can't be started.
+// Failed syntheses don't block, since they will never be reconciled.
+func (c *Composition) SequencingBlocked() bool {
+	if c.Annotations[StrictSequencingAnnotation] != "true" || c.DeletionTimestamp != nil {
+		return false
+	}
+	syn := c.Status.CurrentSynthesis
+	return syn != nil && syn.UUID != "" && syn.Reconciled == nil && !syn.Failed()
+}
+
+// ConcurrentEditWarningsAnnotation enables Warning events on the composition when Eno reverts changes that
+// another client (e.g. a human using kubectl) made to one of its resources.
+const ConcurrentEditWarningsAnnotation = "eno.azure.io/concurrent-edit-warnings"
+
+// ConcurrentEditGracePeriodAnnotation holds a duration for which Eno leaves changes made by other clients in place
+// before reverting them, which smooths break-glass operations. Changes are reverted immediately by default.
+const ConcurrentEditGracePeriodAnnotation = "eno.azure.io/concurrent-edit-grace-period"
+
+// ConcurrentEditWarningsEnabled returns true when the composition has opted into concurrent edit warnings.
+// Setting a grace period implies warnings.
+func (c *Composition) ConcurrentEditWarningsEnabled() bool {
+	return c.Annotations[ConcurrentEditWarningsAnnotation] == "true" || c.ConcurrentEditGracePeriod() > 0
+}
+
+// ConcurrentEditGracePeriod returns the parsed value of ConcurrentEditGracePeriodAnnotation, or zero if it isn't set.
+func (c *Composition) ConcurrentEditGracePeriod() time.Duration {
+	d, _ := time.ParseDuration(c.Annotations[ConcurrentEditGracePeriodAnnotation])
+	return d
+}
+
 func (c *Composition) ShouldIgnoreSideEffects() bool {
 	return c.Annotations["eno.azure.io/ignore-side-effects"] == "true"
 }
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -165,6 +166,116 @@ func TestSynthesisFailed(t *testing.T) {
 	}
 }
 
+func TestCompositionDeletionBlocked(t *testing.T) {
+	now := metav1.Now()
+	tests := []struct {
+		Name        string
+		Comp        Composition
+		Expectation bool
+	}{
+		{
+			Name:        "Not deleted",
+			Comp:        Composition{Spec: CompositionSpec{DeletionProtection: true}},
+			Expectation: false,
+		},
+		{
+			Name:        "Deleted without protection",
+			Comp:        Composition{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}},
+			Expectation: false,
+		},
+		{
+			Name: "Deleted with protection",
+			Comp: Composition{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now},
+				Spec:       CompositionSpec{DeletionProtection: true},
+			},
+			Expectation: true,
+		},
+		{
+			Name: "Deleted with protection and confirmation",
+			Comp: Composition{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now, Annotations: map[string]string{DeletionConfirmedAnnotation: "true"}},
+				Spec:       CompositionSpec{DeletionProtection: true},
+			},
+			Expectation: false,
+		},
+		{
+			Name: "Deleted with protection and invalid confirmation",
+			Comp: Composition{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now, Annotations: map[string]string{DeletionConfirmedAnnotation: "yes"}},
+				Spec:       CompositionSpec{DeletionProtection: true},
+			},
+			Expectation: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expectation, tt.Comp.DeletionBlocked())
+		})
+	}
+}
+
+func TestCompositionSequencingBlocked(t *testing.T) {
+	now := metav1.Now()
+	strict := metav1.ObjectMeta{Annotations: map[string]string{StrictSequencingAnnotation: "true"}}
+	tests := []struct {
+		Name        string
+		Comp        Composition
+		Expectation bool
+	}{
+		{
+			Name:        "Not strict",
+			Comp:        Composition{Status: CompositionStatus{CurrentSynthesis: &Synthesis{UUID: "foo"}}},
+			Expectation: false,
+		},
+		{
+			Name:        "No synthesis",
+			Comp:        Composition{ObjectMeta: strict},
+			Expectation: false,
+		},
+		{
+			Name:        "Pending synthesis",
+			Comp:        Composition{ObjectMeta: strict, Status: CompositionStatus{CurrentSynthesis: &Synthesis{}}},
+			Expectation: false,
+		},
+		{
+			Name:        "Dispatched synthesis",
+			Comp:        Composition{ObjectMeta: strict, Status: CompositionStatus{CurrentSynthesis: &Synthesis{UUID: "foo"}}},
+			Expectation: true,
+		},
+		{
+			Name:        "Synthesized but not reconciled",
+			Comp:        Composition{ObjectMeta: strict, Status: CompositionStatus{CurrentSynthesis: &Synthesis{UUID: "foo", Synthesized: &now}}},
+			Expectation: true,
+		},
+		{
+			Name:        "Reconciled",
+			Comp:        Composition{ObjectMeta: strict, Status: CompositionStatus{CurrentSynthesis: &Synthesis{UUID: "foo", Synthesized: &now, Reconciled: &now}}},
+			Expectation: false,
+		},
+		{
+			Name:        "Failed",
+			Comp:        Composition{ObjectMeta: strict, Status: CompositionStatus{CurrentSynthesis: &Synthesis{UUID: "foo", Synthesized: &now, Results: []Result{{Severity: "error"}}}}},
+			Expectation: false,
+		},
+		{
+			Name: "Deleted",
+			Comp: Composition{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now, Annotations: strict.Annotations},
+				Status:     CompositionStatus{CurrentSynthesis: &Synthesis{UUID: "foo"}},
+			},
+			Expectation: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expectation, tt.Comp.SequencingBlocked())
+		})
+	}
+}
+
 func TestCompositionInputsExist(t *testing.T) {
 	tests := []struct {
 		Name        string
@@ -255,6 +366,23 @@ func TestCompositionInputsExist(t *testing.T) {
 			},
 			Expectation: false,
 		},
+		{
+			Name: "Upstream with revision",
+			Comp: Composition{
+				Spec: CompositionSpec{Upstreams: []UpstreamInput{{Key: "up", Composition: "upstream"}}},
+				Status: CompositionStatus{InputRevisions: []InputRevisions{
+					{Key: "up"},
+				}},
+			},
+			Expectation: true,
+		},
+		{
+			Name: "Upstream without revision",
+			Comp: Composition{
+				Spec: CompositionSpec{Upstreams: []UpstreamInput{{Key: "up", Composition: "upstream"}}},
+			},
+			Expectation: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -407,3 +535,144 @@ func TestInputsInLockstep(t *testing.T) {
 		})
 	}
 }
+
+func TestCompositionShouldOrphan(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Comp        Composition
+		Expectation bool
+	}{
+		{
+			Name:        "Default",
+			Expectation: false,
+		},
+		{
+			Name:        "Orphan field",
+			Comp:        Composition{Spec: CompositionSpec{DeletionStrategy: DeletionStrategyOrphan}},
+			Expectation: true,
+		},
+		{
+			Name:        "Deprecated annotation",
+			Comp:        Composition{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"eno.azure.io/deletion-strategy": "orphan"}}},
+			Expectation: true,
+		},
+		{
+			Name: "Field takes precedence over annotation",
+			Comp: Composition{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"eno.azure.io/deletion-strategy": "orphan"}},
+				Spec:       CompositionSpec{DeletionStrategy: DeletionStrategyDelete},
+			},
+			Expectation: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expectation, tt.Comp.ShouldOrphan())
+		})
+	}
+}
+
+func TestReconcileIntervalOverrideApply(t *testing.T) {
+	declared := &metav1.Duration{Duration: 10 * time.Minute}
+
+	var nilOverride *ReconcileIntervalOverride
+	assert.Equal(t, declared, nilOverride.Apply(declared))
+
+	override := &ReconcileIntervalOverride{Interval: &metav1.Duration{Duration: time.Hour}}
+	assert.Equal(t, time.Hour, override.Apply(declared).Duration)
+	assert.Nil(t, override.Apply(nil))
+
+	override.ScalePercent = ptr.To(int32(50))
+	assert.Equal(t, 30*time.Minute, override.Apply(declared).Duration)
+}
+
+func TestCompositionOutputRevision(t *testing.T) {
+	comp := &Composition{}
+	assert.Equal(t, "", comp.OutputRevision())
+
+	// Failed syntheses aren't output
+	comp.Status.PreviousSynthesis = &Synthesis{UUID: "prev"}
+	comp.Status.CurrentSynthesis = &Synthesis{UUID: "current", Synthesized: &metav1.Time{}, Results: []Result{{Severity: "error"}}}
+	assert.Equal(t, "prev", comp.OutputRevision())
+
+	comp.Status.CurrentSynthesis.Results = nil
+	assert.Equal(t, "current", comp.OutputRevision())
+
+	// Exports are part of the revision
+	comp.Status.Exports = map[string]string{"ip": "10.0.0.1"}
+	withExports := comp.OutputRevision()
+	assert.NotEqual(t, "current", withExports)
+
+	comp.Status.Exports["ip"] = "10.0.0.2"
+	assert.NotEqual(t, withExports, comp.OutputRevision())
+}
+
+func TestCompositionPinMismatched(t *testing.T) {
+	syn := &Synthesizer{}
+	syn.Generation = 3
+
+	comp := &Composition{}
+	assert.False(t, comp.PinMismatched(syn))
+
+	comp.Spec.Pin = &CompositionPin{SynthesizerGeneration: 2}
+	assert.True(t, comp.PinMismatched(syn))
+
+	comp.Spec.Pin.SynthesizerGeneration = 3
+	assert.False(t, comp.PinMismatched(syn))
+
+	// Inputs that haven't been observed yet don't mismatch
+	comp.Spec.Pin.InputRevisions = []InputRevisions{{Key: "foo", ResourceVersion: "1"}, {Key: "bar", Revision: ptr.To(5)}}
+	assert.False(t, comp.PinMismatched(syn))
+
+	comp.Status.InputRevisions = []InputRevisions{{Key: "foo", ResourceVersion: "1"}, {Key: "bar", ResourceVersion: "10", Revision: ptr.To(5)}}
+	assert.False(t, comp.PinMismatched(syn))
+
+	comp.Status.InputRevisions[1].Revision = ptr.To(6)
+	assert.True(t, comp.PinMismatched(syn))
+}
+
+func TestCompositionPromote(t *testing.T) {
+	comp := &Composition{}
+	comp.Name = "app"
+	comp.Namespace = "staging"
+	comp.Labels = map[string]string{"team": "foo"}
+	comp.Annotations = map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}", "foo": "bar"}
+	comp.Spec.Synthesizer.Name = "test-syn"
+	comp.Spec.Bindings = []Binding{{Key: "config", Resource: ResourceBinding{Name: "config", Namespace: "inputs"}}}
+	comp.Spec.Upstreams = []UpstreamInput{{Key: "network", Composition: "network"}}
+
+	_, err := comp.Promote("prod", "app")
+	assert.Error(t, err)
+
+	comp.Status.CurrentSynthesis = &Synthesis{
+		UUID:                          "test-uuid",
+		Synthesized:                   &metav1.Time{},
+		ObservedSynthesizerGeneration: 4,
+		InputRevisions: []InputRevisions{
+			{Key: "config", ResourceVersion: "123"},
+			{Key: "network", ResourceVersion: "upstream-uuid"},
+		},
+	}
+	promoted, err := comp.Promote("prod", "app-prod")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "prod", promoted.Namespace)
+	assert.Equal(t, "app-prod", promoted.Name)
+	assert.Equal(t, map[string]string{"team": "foo"}, promoted.Labels)
+	assert.Equal(t, map[string]string{
+		"foo":                       "bar",
+		PromotedFromAnnotation:      "staging/app",
+		PromotedSynthesisAnnotation: "test-uuid",
+	}, promoted.Annotations)
+	assert.Equal(t, comp.Spec.Bindings, promoted.Spec.Bindings)
+	assert.Equal(t, &CompositionPin{
+		SynthesizerGeneration: 4,
+		InputRevisions:        []InputRevisions{{Key: "config", ResourceVersion: "123"}}, // upstreams aren't pinned
+	}, promoted.Spec.Pin)
+	assert.Nil(t, comp.Spec.Pin)
+
+	comp.Spec.Manifests = &ManifestSource{Inline: "{}"}
+	_, err = comp.Promote("prod", "app-prod")
+	assert.Error(t, err)
+}
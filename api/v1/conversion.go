@@ -0,0 +1,9 @@
+package v1
+
+// v1 is the conversion hub: future API versions convert to and from these types
+// by implementing sigs.k8s.io/controller-runtime/pkg/conversion.Convertible.
+
+func (*Composition) Hub()   {}
+func (*Synthesizer) Hub()   {}
+func (*ResourceSlice) Hub() {}
+func (*Symphony) Hub()      {}
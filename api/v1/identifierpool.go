@@ -0,0 +1,92 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// +kubebuilder:object:root=true
+type IdentifierPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IdentifierPool `json:"items"`
+}
+
+// IdentifierPools allocate values that are unique within the pool and stable for the lifetime of each composition holding one.
+// Compositions request identifiers using spec.identifiers, and synthesizers receive them as inputs.
+//
+// This solves the "deterministic but unique value per composition" problem (e.g. port offsets, VLAN IDs, shard numbers)
+// without requiring synthesizers to coordinate through an external database.
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Strategy",type=string,JSONPath=`.spec.strategy`
+// +kubebuilder:printcolumn:name="Allocated",type=integer,JSONPath=`.status.allocated`
+type IdentifierPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IdentifierPoolSpec   `json:"spec,omitempty"`
+	Status IdentifierPoolStatus `json:"status,omitempty"`
+}
+
+type IdentifierPoolSpec struct {
+	// Strategy determines how values are allocated.
+	//
+	// - Sequence (default): increasing integers starting at spec.start. Values are never reused.
+	// - Slot: the lowest integer in [start, start+size) not held by another composition. Values are reused once released.
+	// - Hash: a stable hash of the composition's namespace and name. Collisions within the pool are resolved by rehashing.
+	//
+	// +kubebuilder:validation:Enum:=Sequence;Slot;Hash
+	Strategy IdentifierStrategy `json:"strategy,omitempty"`
+
+	// Start is the first value allocated by the Sequence and Slot strategies.
+	Start int64 `json:"start,omitempty"`
+
+	// Size is the number of values available to the Slot strategy.
+	// +kubebuilder:validation:Minimum:=0
+	Size int64 `json:"size,omitempty"`
+}
+
+type IdentifierStrategy string
+
+const (
+	IdentifierStrategySequence IdentifierStrategy = "Sequence"
+	IdentifierStrategySlot     IdentifierStrategy = "Slot"
+	IdentifierStrategyHash     IdentifierStrategy = "Hash"
+)
+
+type IdentifierPoolStatus struct {
+	// Next is the next value to be allocated by the Sequence strategy.
+	Next int64 `json:"next,omitempty"`
+
+	// Allocated is the number of values currently held.
+	Allocated int `json:"allocated,omitempty"`
+
+	// Allocations are released by Eno once the composition holding them has been deleted
+	// or no longer requests an identifier from the pool.
+	Allocations []IdentifierAllocation `json:"allocations,omitempty"`
+}
+
+type IdentifierAllocation struct {
+	// Composition is the name of the composition holding the value.
+	// Compositions can only allocate from pools in their own namespace.
+	Composition string `json:"composition"`
+
+	// CompositionUID distinguishes the holder from any later composition of the same name.
+	CompositionUID types.UID `json:"compositionUID"`
+
+	Value string `json:"value"`
+
+	Allocated metav1.Time `json:"allocated"`
+}
+
+// IdentifierRequests allocate a value from an IdentifierPool and pass it to the synthesizer as an input.
+type IdentifierRequest struct {
+	// Key is the input key used to pass the value to the synthesizer.
+	Key string `json:"key"`
+
+	// Pool is the name of an IdentifierPool in the composition's namespace.
+	// Compositions can request at most one identifier from each pool.
+	Pool string `json:"pool"`
+}
@@ -0,0 +1,57 @@
+package v1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// +kubebuilder:object:root=true
+type EnoQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EnoQuota `json:"items"`
+}
+
+// EnoQuotas limit the Eno capacity consumed by the compositions in their namespace.
+// Every quota in a namespace is enforced, so the most restrictive limit wins.
+//
+// Limits are enforced at different points:
+//
+// - Compositions: new compositions are rejected by the admission webhook.
+// - Syntheses: pending syntheses aren't dispatched until the namespace's rate falls below the limit.
+// - Resources: syntheses that would exceed the limit fail without changing any resources.
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Compositions",type=integer,JSONPath=`.status.compositions`
+// +kubebuilder:printcolumn:name="Resources",type=integer,JSONPath=`.status.resources`
+type EnoQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EnoQuotaSpec   `json:"spec,omitempty"`
+	Status EnoQuotaStatus `json:"status,omitempty"`
+}
+
+// Nil limits are not enforced.
+type EnoQuotaSpec struct {
+	// MaxCompositions is the max number of compositions in the namespace.
+	// Existing compositions are not affected when the limit is lowered.
+	// +kubebuilder:validation:Minimum:=0
+	MaxCompositions *int64 `json:"maxCompositions,omitempty"`
+
+	// MaxSynthesesPerHour is the max number of syntheses dispatched for compositions in the namespace over any one hour window.
+	// +kubebuilder:validation:Minimum:=0
+	MaxSynthesesPerHour *int64 `json:"maxSynthesesPerHour,omitempty"`
+
+	// MaxResources is the max number of resources synthesized by all compositions in the namespace.
+	// Deleted resources (tombstones) are not counted.
+	// +kubebuilder:validation:Minimum:=0
+	MaxResources *int64 `json:"maxResources,omitempty"`
+}
+
+type EnoQuotaStatus struct {
+	// Compositions is the number of compositions in the namespace.
+	Compositions int64 `json:"compositions,omitempty"`
+
+	// Resources is the number of resources synthesized by compositions in the namespace,
+	// as of each composition's most recent successful synthesis.
+	Resources int64 `json:"resources,omitempty"`
+}
@@ -32,6 +32,10 @@ type Manifest struct {
 
 	// Deleted is true when this manifest represents a "tombstone" - a resource that should no longer exist.
 	Deleted bool `json:"deleted,omitempty"`
+
+	// DisableUpdates prevents Eno from updating the resource once it exists.
+	// Set from the "eno.azure.io/disable-updates" annotation of the synthesized resource.
+	DisableUpdates bool `json:"disableUpdates,omitempty"`
 }
 
 type ResourceSliceStatus struct {
@@ -43,6 +47,31 @@ type ResourceState struct {
 	Reconciled bool         `json:"reconciled,omitempty"`
 	Ready      *metav1.Time `json:"ready,omitempty"`
 	Deleted    bool         `json:"deleted,omitempty"`
+
+	// Message is a human-readable description of why the resource isn't ready yet.
+	Message string `json:"message,omitempty"`
+
+	// Blocked is true when the resource should be deleted, but deletion is blocked by the eno.azure.io/protect annotation.
+	Blocked bool `json:"blocked,omitempty"`
+
+	// Degraded is true when the resource has become ready but is no longer ready, or was deleted out-of-band.
+	// Ready is not cleared, since readiness latches for each synthesis.
+	Degraded bool `json:"degraded,omitempty"`
+
+	// GeneratedName is the name assigned by the apiserver to resources that use metadata.generateName.
+	// It's recorded when the resource is created, and used to find the resource in subsequent reconciliations.
+	GeneratedName string `json:"generatedName,omitempty"`
+
+	// HandsOffUntil is set while updates to the resource are suspended by its eno.azure.io/hands-off-until annotation.
+	HandsOffUntil *metav1.Time `json:"handsOffUntil,omitempty"`
+
+	// Exports are the values extracted by the resource's eno.azure.io/export-<name> annotations once it became ready.
+	Exports map[string]string `json:"exports,omitempty"`
+
+	// ExpiresAt is when the resource will be deleted by its eno.azure.io/expires-after annotation.
+	// Expired is true once it has been deleted, after which it isn't recreated until the next synthesis.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+	Expired   bool         `json:"expired,omitempty"`
 }
 
 type ResourceSliceRef struct {
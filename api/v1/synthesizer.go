@@ -21,6 +21,10 @@ type SynthesizerList struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
 // +kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.spec.image`
+// +kubebuilder:printcolumn:name="Generation",type=integer,JSONPath=`.metadata.generation`
+// +kubebuilder:printcolumn:name="Rollout Started",type=date,JSONPath=`.status.rolloutStarted`
+// +kubebuilder:printcolumn:name="Rollout Finished",type=date,JSONPath=`.status.rolloutFinished`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type Synthesizer struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -62,15 +66,184 @@ type SynthesizerSpec struct {
 
 	// PodOverrides sets values in the pods used to execute this synthesizer.
 	PodOverrides PodOverrides `json:"podOverrides,omitempty"`
+
+	// Rollout optionally stops the rollout of synthesizer changes when too many
+	// resynthesized compositions fail to become ready.
+	Rollout *RolloutPolicy `json:"rollout,omitempty"`
+
+	// ResourceDefaults are merged into the annotations of synthesized resources by kind.
+	// Annotations set by the synthesizer take precedence over these defaults.
+	ResourceDefaults []ResourceDefaults `json:"resourceDefaults,omitempty"`
+
+	// Determinism fixes parts of the synthesis environment so synthesizer output is reproducible.
+	Determinism *DeterminismOptions `json:"determinism,omitempty"`
+
+	// ImpactAnalysis optionally dry-runs each new generation of the synthesizer against a sample of its compositions
+	// before the generation is rolled out. The results are reported by status.impactAnalysis.
+	ImpactAnalysis *ImpactAnalysisPolicy `json:"impactAnalysis,omitempty"`
+}
+
+type ImpactAnalysisPolicy struct {
+	// SampleSize is the max number of compositions to dry-run.
+	//
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=50
+	SampleSize int `json:"sampleSize,omitempty"`
+}
+
+type DeterminismOptions struct {
+	// Clock is exposed to the synthesizer as the current time instead of the wall clock:
+	// as ENO_SYNTHESIS_TIME (RFC3339) and SOURCE_DATE_EPOCH (unix seconds).
+	// Synthesizers are expected to read the time from these variables.
+	Clock *metav1.Time `json:"clock,omitempty"`
+
+	// RandomSeed is exposed to the synthesizer as ENO_RANDOM_SEED.
+	// Synthesizers are expected to seed any source of randomness with it.
+	RandomSeed *int64 `json:"randomSeed,omitempty"`
+
+	// DetectNondeterminism runs the synthesizer twice with identical inputs and fails
+	// synthesis if the outputs differ. This doubles the cost of every synthesis.
+	DetectNondeterminism bool `json:"detectNondeterminism,omitempty"`
+}
+
+type ResourceDefaults struct {
+	// Group and Kind of the resources that these defaults apply to.
+	// The first matching entry is used when several match the same resource.
+	Group string `json:"group,omitempty"`
+	// +required
+	Kind string `json:"kind,omitempty"`
+
+	// ReadinessChecks are CEL expressions keyed by check name, equivalent to eno.azure.io/readiness-<name> annotations.
+	// The "default" key is equivalent to the eno.azure.io/readiness annotation.
+	// Only applied to resources that don't have any readiness annotations.
+	ReadinessChecks map[string]string `json:"readinessChecks,omitempty"`
+
+	// ReadinessGroup is equivalent to the eno.azure.io/readiness-group annotation.
+	ReadinessGroup *int `json:"readinessGroup,omitempty"`
+
+	// ReconcileInterval is equivalent to the eno.azure.io/reconcile-interval annotation.
+	ReconcileInterval *metav1.Duration `json:"reconcileInterval,omitempty"`
+}
+
+type RolloutPolicy struct {
+	// The rollout is aborted when more than this percentage of the compositions resynthesized
+	// using the current synthesizer generation have failed synthesis or readiness.
+	//
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Maximum:=100
+	MaxFailurePercent int `json:"maxFailurePercent"`
+
+	// Resynthesized compositions are considered to have failed readiness if they are not ready within this period.
+	//
+	// +kubebuilder:default="15m"
+	ReadinessTimeout *metav1.Duration `json:"readinessTimeout,omitempty"`
+
+	// Rollback reverts failed compositions to their previous synthesis when the rollout is aborted.
+	Rollback bool `json:"rollback,omitempty"`
 }
 
 type PodOverrides struct {
 	Labels      map[string]string           `json:"labels,omitempty"`
 	Annotations map[string]string           `json:"annotations,omitempty"`
 	Resources   corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// ImagePullSecrets are added to any image pull secrets configured by the controller.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// ImagePullPolicy of the synthesizer container. Overrides the controller's default.
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
 }
 
 type SynthesizerStatus struct {
+	// ObservedGeneration is the synthesizer generation currently being rolled out.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Compositions counted by the synthesizer generation used by their current synthesis.
+	CompositionsByGeneration []GenerationCount `json:"compositionsByGeneration,omitempty"`
+
+	// RolloutStarted is the time at which the controller first observed the current generation.
+	RolloutStarted *metav1.Time `json:"rolloutStarted,omitempty"`
+
+	// RolloutFinished is the time at which every eligible composition was resynthesized with the current generation.
+	RolloutFinished *metav1.Time `json:"rolloutFinished,omitempty"`
+
+	// RolloutFailures is the number of compositions that failed synthesis using the current generation.
+	RolloutFailures int `json:"rolloutFailures,omitempty"`
+
+	// ImpactAnalysis reports how the current generation would change the output of a sample of compositions.
+	ImpactAnalysis *ImpactAnalysisStatus `json:"impactAnalysis,omitempty"`
+
+	// Conditions include FailedRollout, which is set when the rollout of the current generation has been aborted,
+	// and CanaryFailed, which is set while a canary composition that failed synthesis blocks the rollout.
+	//
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+type ImpactAnalysisStatus struct {
+	// ObservedGeneration is the synthesizer generation that was analyzed.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	Started   *metav1.Time `json:"started,omitempty"`
+	Completed *metav1.Time `json:"completed,omitempty"`
+
+	// Totals of the analyzed compositions' changes.
+	Added    int `json:"added,omitempty"`
+	Removed  int `json:"removed,omitempty"`
+	Modified int `json:"modified,omitempty"`
+
+	// Failed is the number of sampled compositions that would fail synthesis (or couldn't be analyzed).
+	Failed int `json:"failed,omitempty"`
+
+	Compositions []CompositionImpact `json:"compositions,omitempty"`
+}
+
+// CompositionImpact counts the resources that a composition's next synthesis would add, remove, or modify.
+type CompositionImpact struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+
+	// Analyzed is the time at which the composition's dry run completed.
+	Analyzed *metav1.Time `json:"analyzed,omitempty"`
+
+	Added    int `json:"added,omitempty"`
+	Removed  int `json:"removed,omitempty"`
+	Modified int `json:"modified,omitempty"`
+
+	// Error is set when synthesis would fail, or the composition couldn't be analyzed.
+	Error string `json:"error,omitempty"`
+}
+
+// ImpactAnalysisPending returns true while the rollout of the current generation is waiting for impact analysis.
+func (s *Synthesizer) ImpactAnalysisPending() bool {
+	if s.Spec.ImpactAnalysis == nil {
+		return false
+	}
+	status := s.Status.ImpactAnalysis
+	return status == nil || status.ObservedGeneration != s.Generation || status.Completed == nil
+}
+
+// StreamLogsAnnotation enables copying the synthesizer's logs into a ConfigMap next to each of its compositions
+// named "<composition>-synthesis-logs", for environments where composition authors can't read the synthesizer pods' logs.
+// Only the most recent output of each composition's latest synthesis is kept.
+const StreamLogsAnnotation = "eno.azure.io/stream-logs"
+
+// StreamLogs returns true when the synthesizer's logs should be copied to its compositions' namespaces.
+func (s *Synthesizer) StreamLogs() bool {
+	return s.Annotations[StreamLogsAnnotation] == "true"
+}
+
+// FailedRolloutCondition is set on synthesizers whose rollout has been aborted by their rollout policy.
+const FailedRolloutCondition = "FailedRollout"
+
+// CanaryFailedCondition is set on synthesizers whose rollout is blocked by a canary composition that failed synthesis.
+const CanaryFailedCondition = "CanaryFailed"
+
+type GenerationCount struct {
+	Generation   int64 `json:"generation"`
+	Compositions int   `json:"compositions"`
 }
 
 type SynthesizerRef struct {
@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSynthesizerImpactAnalysisPending(t *testing.T) {
+	syn := &Synthesizer{}
+	syn.Generation = 2
+	assert.False(t, syn.ImpactAnalysisPending())
+
+	syn.Spec.ImpactAnalysis = &ImpactAnalysisPolicy{SampleSize: 5}
+	assert.True(t, syn.ImpactAnalysisPending())
+
+	now := metav1.Now()
+	syn.Status.ImpactAnalysis = &ImpactAnalysisStatus{ObservedGeneration: 1, Completed: &now}
+	assert.True(t, syn.ImpactAnalysisPending())
+
+	syn.Status.ImpactAnalysis = &ImpactAnalysisStatus{ObservedGeneration: 2}
+	assert.True(t, syn.ImpactAnalysisPending())
+
+	syn.Status.ImpactAnalysis.Completed = &now
+	assert.False(t, syn.ImpactAnalysisPending())
+}
@@ -23,4 +23,6 @@ func init() {
 	SchemeBuilder.Register(&CompositionList{}, &Composition{})
 	SchemeBuilder.Register(&SymphonyList{}, &Symphony{})
 	SchemeBuilder.Register(&ResourceSliceList{}, &ResourceSlice{})
+	SchemeBuilder.Register(&IdentifierPoolList{}, &IdentifierPool{})
+	SchemeBuilder.Register(&EnoQuotaList{}, &EnoQuota{})
 }
@@ -5,6 +5,7 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -52,116 +53,584 @@ func (in *Composition) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositionImpact) DeepCopyInto(out *CompositionImpact) {
+	*out = *in
+	if in.Analyzed != nil {
+		in, out := &in.Analyzed, &out.Analyzed
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositionImpact.
+func (in *CompositionImpact) DeepCopy() *CompositionImpact {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositionImpact)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CompositionList) DeepCopyInto(out *CompositionList) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Composition, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Composition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositionList.
+func (in *CompositionList) DeepCopy() *CompositionList {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CompositionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositionPin) DeepCopyInto(out *CompositionPin) {
+	*out = *in
+	if in.InputRevisions != nil {
+		in, out := &in.InputRevisions, &out.InputRevisions
+		*out = make([]InputRevisions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositionPin.
+func (in *CompositionPin) DeepCopy() *CompositionPin {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositionPin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositionSpec) DeepCopyInto(out *CompositionSpec) {
+	*out = *in
+	out.Synthesizer = in.Synthesizer
+	if in.Bindings != nil {
+		in, out := &in.Bindings, &out.Bindings
+		*out = make([]Binding, len(*in))
+		copy(*out, *in)
+	}
+	if in.SynthesisEnv != nil {
+		in, out := &in.SynthesisEnv, &out.SynthesisEnv
+		*out = make([]EnvVar, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReconcileInterval != nil {
+		in, out := &in.ReconcileInterval, &out.ReconcileInterval
+		*out = new(ReconcileIntervalOverride)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TargetNamespace != nil {
+		in, out := &in.TargetNamespace, &out.TargetNamespace
+		*out = new(TargetNamespace)
+		**out = **in
+	}
+	if in.NameTransform != nil {
+		in, out := &in.NameTransform, &out.NameTransform
+		*out = new(NameTransform)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CommonLabels != nil {
+		in, out := &in.CommonLabels, &out.CommonLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CommonAnnotations != nil {
+		in, out := &in.CommonAnnotations, &out.CommonAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Identifiers != nil {
+		in, out := &in.Identifiers, &out.Identifiers
+		*out = make([]IdentifierRequest, len(*in))
+		copy(*out, *in)
+	}
+	if in.Manifests != nil {
+		in, out := &in.Manifests, &out.Manifests
+		*out = new(ManifestSource)
+		**out = **in
+	}
+	if in.Import != nil {
+		in, out := &in.Import, &out.Import
+		*out = new(ImportSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Upstreams != nil {
+		in, out := &in.Upstreams, &out.Upstreams
+		*out = make([]UpstreamInput, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Pin != nil {
+		in, out := &in.Pin, &out.Pin
+		*out = new(CompositionPin)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositionSpec.
+func (in *CompositionSpec) DeepCopy() *CompositionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositionStatus) DeepCopyInto(out *CompositionStatus) {
+	*out = *in
+	if in.Simplified != nil {
+		in, out := &in.Simplified, &out.Simplified
+		*out = new(SimplifiedStatus)
+		**out = **in
+	}
+	if in.CurrentSynthesis != nil {
+		in, out := &in.CurrentSynthesis, &out.CurrentSynthesis
+		*out = new(Synthesis)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreviousSynthesis != nil {
+		in, out := &in.PreviousSynthesis, &out.PreviousSynthesis
+		*out = new(Synthesis)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PendingResynthesis != nil {
+		in, out := &in.PendingResynthesis, &out.PendingResynthesis
+		*out = (*in).DeepCopy()
+	}
+	if in.InputRevisions != nil {
+		in, out := &in.InputRevisions, &out.InputRevisions
+		*out = make([]InputRevisions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeletionProgress != nil {
+		in, out := &in.DeletionProgress, &out.DeletionProgress
+		*out = new(DeletionProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SynthesizerState != nil {
+		in, out := &in.SynthesizerState, &out.SynthesizerState
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Exports != nil {
+		in, out := &in.Exports, &out.Exports
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositionStatus.
+func (in *CompositionStatus) DeepCopy() *CompositionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeletionProgress) DeepCopyInto(out *DeletionProgress) {
+	*out = *in
+	if in.BlockingKinds != nil {
+		in, out := &in.BlockingKinds, &out.BlockingKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EstimatedCompletion != nil {
+		in, out := &in.EstimatedCompletion, &out.EstimatedCompletion
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeletionProgress.
+func (in *DeletionProgress) DeepCopy() *DeletionProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(DeletionProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeterminismOptions) DeepCopyInto(out *DeterminismOptions) {
+	*out = *in
+	if in.Clock != nil {
+		in, out := &in.Clock, &out.Clock
+		*out = (*in).DeepCopy()
+	}
+	if in.RandomSeed != nil {
+		in, out := &in.RandomSeed, &out.RandomSeed
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeterminismOptions.
+func (in *DeterminismOptions) DeepCopy() *DeterminismOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(DeterminismOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnoQuota) DeepCopyInto(out *EnoQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnoQuota.
+func (in *EnoQuota) DeepCopy() *EnoQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(EnoQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EnoQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnoQuotaList) DeepCopyInto(out *EnoQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EnoQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnoQuotaList.
+func (in *EnoQuotaList) DeepCopy() *EnoQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(EnoQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EnoQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnoQuotaSpec) DeepCopyInto(out *EnoQuotaSpec) {
+	*out = *in
+	if in.MaxCompositions != nil {
+		in, out := &in.MaxCompositions, &out.MaxCompositions
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxSynthesesPerHour != nil {
+		in, out := &in.MaxSynthesesPerHour, &out.MaxSynthesesPerHour
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxResources != nil {
+		in, out := &in.MaxResources, &out.MaxResources
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnoQuotaSpec.
+func (in *EnoQuotaSpec) DeepCopy() *EnoQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EnoQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnoQuotaStatus) DeepCopyInto(out *EnoQuotaStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnoQuotaStatus.
+func (in *EnoQuotaStatus) DeepCopy() *EnoQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EnoQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvVar) DeepCopyInto(out *EnvVar) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvVar.
+func (in *EnvVar) DeepCopy() *EnvVar {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvVar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdentifierAllocation) DeepCopyInto(out *IdentifierAllocation) {
+	*out = *in
+	in.Allocated.DeepCopyInto(&out.Allocated)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentifierAllocation.
+func (in *IdentifierAllocation) DeepCopy() *IdentifierAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(IdentifierAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdentifierPool) DeepCopyInto(out *IdentifierPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentifierPool.
+func (in *IdentifierPool) DeepCopy() *IdentifierPool {
+	if in == nil {
+		return nil
+	}
+	out := new(IdentifierPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IdentifierPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdentifierPoolList) DeepCopyInto(out *IdentifierPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IdentifierPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentifierPoolList.
+func (in *IdentifierPoolList) DeepCopy() *IdentifierPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(IdentifierPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IdentifierPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdentifierPoolSpec) DeepCopyInto(out *IdentifierPoolSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentifierPoolSpec.
+func (in *IdentifierPoolSpec) DeepCopy() *IdentifierPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IdentifierPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdentifierPoolStatus) DeepCopyInto(out *IdentifierPoolStatus) {
+	*out = *in
+	if in.Allocations != nil {
+		in, out := &in.Allocations, &out.Allocations
+		*out = make([]IdentifierAllocation, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositionList.
-func (in *CompositionList) DeepCopy() *CompositionList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentifierPoolStatus.
+func (in *IdentifierPoolStatus) DeepCopy() *IdentifierPoolStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CompositionList)
+	out := new(IdentifierPoolStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CompositionList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdentifierRequest) DeepCopyInto(out *IdentifierRequest) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentifierRequest.
+func (in *IdentifierRequest) DeepCopy() *IdentifierRequest {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(IdentifierRequest)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CompositionSpec) DeepCopyInto(out *CompositionSpec) {
+func (in *ImpactAnalysisPolicy) DeepCopyInto(out *ImpactAnalysisPolicy) {
 	*out = *in
-	out.Synthesizer = in.Synthesizer
-	if in.Bindings != nil {
-		in, out := &in.Bindings, &out.Bindings
-		*out = make([]Binding, len(*in))
-		copy(*out, *in)
-	}
-	if in.SynthesisEnv != nil {
-		in, out := &in.SynthesisEnv, &out.SynthesisEnv
-		*out = make([]EnvVar, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositionSpec.
-func (in *CompositionSpec) DeepCopy() *CompositionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImpactAnalysisPolicy.
+func (in *ImpactAnalysisPolicy) DeepCopy() *ImpactAnalysisPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(CompositionSpec)
+	out := new(ImpactAnalysisPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CompositionStatus) DeepCopyInto(out *CompositionStatus) {
+func (in *ImpactAnalysisStatus) DeepCopyInto(out *ImpactAnalysisStatus) {
 	*out = *in
-	if in.Simplified != nil {
-		in, out := &in.Simplified, &out.Simplified
-		*out = new(SimplifiedStatus)
-		**out = **in
-	}
-	if in.CurrentSynthesis != nil {
-		in, out := &in.CurrentSynthesis, &out.CurrentSynthesis
-		*out = new(Synthesis)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PreviousSynthesis != nil {
-		in, out := &in.PreviousSynthesis, &out.PreviousSynthesis
-		*out = new(Synthesis)
-		(*in).DeepCopyInto(*out)
+	if in.Started != nil {
+		in, out := &in.Started, &out.Started
+		*out = (*in).DeepCopy()
 	}
-	if in.PendingResynthesis != nil {
-		in, out := &in.PendingResynthesis, &out.PendingResynthesis
+	if in.Completed != nil {
+		in, out := &in.Completed, &out.Completed
 		*out = (*in).DeepCopy()
 	}
-	if in.InputRevisions != nil {
-		in, out := &in.InputRevisions, &out.InputRevisions
-		*out = make([]InputRevisions, len(*in))
+	if in.Compositions != nil {
+		in, out := &in.Compositions, &out.Compositions
+		*out = make([]CompositionImpact, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositionStatus.
-func (in *CompositionStatus) DeepCopy() *CompositionStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImpactAnalysisStatus.
+func (in *ImpactAnalysisStatus) DeepCopy() *ImpactAnalysisStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CompositionStatus)
+	out := new(ImpactAnalysisStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EnvVar) DeepCopyInto(out *EnvVar) {
+func (in *ImportSpec) DeepCopyInto(out *ImportSpec) {
 	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResourceRef, len(*in))
+		copy(*out, *in)
+	}
+	in.Selector.DeepCopyInto(&out.Selector)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvVar.
-func (in *EnvVar) DeepCopy() *EnvVar {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportSpec.
+func (in *ImportSpec) DeepCopy() *ImportSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(EnvVar)
+	out := new(ImportSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -198,6 +667,21 @@ func (in *InputResource) DeepCopy() *InputResource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenerationCount) DeepCopyInto(out *GenerationCount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenerationCount.
+func (in *GenerationCount) DeepCopy() *GenerationCount {
+	if in == nil {
+		return nil
+	}
+	out := new(GenerationCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InputRevisions) DeepCopyInto(out *InputRevisions) {
 	*out = *in
@@ -238,6 +722,56 @@ func (in *Manifest) DeepCopy() *Manifest {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestSource) DeepCopyInto(out *ManifestSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestSource.
+func (in *ManifestSource) DeepCopy() *ManifestSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NameReference) DeepCopyInto(out *NameReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NameReference.
+func (in *NameReference) DeepCopy() *NameReference {
+	if in == nil {
+		return nil
+	}
+	out := new(NameReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NameTransform) DeepCopyInto(out *NameTransform) {
+	*out = *in
+	if in.References != nil {
+		in, out := &in.References, &out.References
+		*out = make([]NameReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NameTransform.
+func (in *NameTransform) DeepCopy() *NameTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(NameTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodOverrides) DeepCopyInto(out *PodOverrides) {
 	*out = *in
@@ -256,6 +790,11 @@ func (in *PodOverrides) DeepCopyInto(out *PodOverrides) {
 		}
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodOverrides.
@@ -268,6 +807,31 @@ func (in *PodOverrides) DeepCopy() *PodOverrides {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconcileIntervalOverride) DeepCopyInto(out *ReconcileIntervalOverride) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ScalePercent != nil {
+		in, out := &in.ScalePercent, &out.ScalePercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcileIntervalOverride.
+func (in *ReconcileIntervalOverride) DeepCopy() *ReconcileIntervalOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ReconcileIntervalOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Ref) DeepCopyInto(out *Ref) {
 	*out = *in
@@ -314,6 +878,38 @@ func (in *ResourceRef) DeepCopy() *ResourceRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceDefaults) DeepCopyInto(out *ResourceDefaults) {
+	*out = *in
+	if in.ReadinessChecks != nil {
+		in, out := &in.ReadinessChecks, &out.ReadinessChecks
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ReadinessGroup != nil {
+		in, out := &in.ReadinessGroup, &out.ReadinessGroup
+		*out = new(int)
+		**out = **in
+	}
+	if in.ReconcileInterval != nil {
+		in, out := &in.ReconcileInterval, &out.ReconcileInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceDefaults.
+func (in *ResourceDefaults) DeepCopy() *ResourceDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceSlice) DeepCopyInto(out *ResourceSlice) {
 	*out = *in
@@ -437,6 +1033,21 @@ func (in *ResourceState) DeepCopyInto(out *ResourceState) {
 		in, out := &in.Ready, &out.Ready
 		*out = (*in).DeepCopy()
 	}
+	if in.HandsOffUntil != nil {
+		in, out := &in.HandsOffUntil, &out.HandsOffUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.Exports != nil {
+		in, out := &in.Exports, &out.Exports
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceState.
@@ -471,6 +1082,26 @@ func (in *Result) DeepCopy() *Result {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutPolicy) DeepCopyInto(out *RolloutPolicy) {
+	*out = *in
+	if in.ReadinessTimeout != nil {
+		in, out := &in.ReadinessTimeout, &out.ReadinessTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutPolicy.
+func (in *RolloutPolicy) DeepCopy() *RolloutPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SimplifiedStatus) DeepCopyInto(out *SimplifiedStatus) {
 	*out = *in
@@ -657,6 +1288,16 @@ func (in *Synthesis) DeepCopyInto(out *Synthesis) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ReadinessMessages != nil {
+		in, out := &in.ReadinessMessages, &out.ReadinessMessages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(SynthesisProgress)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Synthesis.
@@ -669,13 +1310,28 @@ func (in *Synthesis) DeepCopy() *Synthesis {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SynthesisProgress) DeepCopyInto(out *SynthesisProgress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynthesisProgress.
+func (in *SynthesisProgress) DeepCopy() *SynthesisProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(SynthesisProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Synthesizer) DeepCopyInto(out *Synthesizer) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Synthesizer.
@@ -772,6 +1428,28 @@ func (in *SynthesizerSpec) DeepCopyInto(out *SynthesizerSpec) {
 		copy(*out, *in)
 	}
 	in.PodOverrides.DeepCopyInto(&out.PodOverrides)
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceDefaults != nil {
+		in, out := &in.ResourceDefaults, &out.ResourceDefaults
+		*out = make([]ResourceDefaults, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Determinism != nil {
+		in, out := &in.Determinism, &out.Determinism
+		*out = new(DeterminismOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImpactAnalysis != nil {
+		in, out := &in.ImpactAnalysis, &out.ImpactAnalysis
+		*out = new(ImpactAnalysisPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynthesizerSpec.
@@ -787,6 +1465,31 @@ func (in *SynthesizerSpec) DeepCopy() *SynthesizerSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SynthesizerStatus) DeepCopyInto(out *SynthesizerStatus) {
 	*out = *in
+	if in.CompositionsByGeneration != nil {
+		in, out := &in.CompositionsByGeneration, &out.CompositionsByGeneration
+		*out = make([]GenerationCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.RolloutStarted != nil {
+		in, out := &in.RolloutStarted, &out.RolloutStarted
+		*out = (*in).DeepCopy()
+	}
+	if in.RolloutFinished != nil {
+		in, out := &in.RolloutFinished, &out.RolloutFinished
+		*out = (*in).DeepCopy()
+	}
+	if in.ImpactAnalysis != nil {
+		in, out := &in.ImpactAnalysis, &out.ImpactAnalysis
+		*out = new(ImpactAnalysisStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynthesizerStatus.
@@ -799,6 +1502,56 @@ func (in *SynthesizerStatus) DeepCopy() *SynthesizerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetNamespace) DeepCopyInto(out *TargetNamespace) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetNamespace.
+func (in *TargetNamespace) DeepCopy() *TargetNamespace {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetNamespace)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamInput) DeepCopyInto(out *UpstreamInput) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]UpstreamResourceSelector, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamInput.
+func (in *UpstreamInput) DeepCopy() *UpstreamInput {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamInput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamResourceSelector) DeepCopyInto(out *UpstreamResourceSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamResourceSelector.
+func (in *UpstreamResourceSelector) DeepCopy() *UpstreamResourceSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamResourceSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Variation) DeepCopyInto(out *Variation) {
 	*out = *in
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -12,21 +14,33 @@ import (
 	"github.com/go-logr/zapr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 
 	v1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/archive"
+	"github.com/Azure/eno/internal/bundle"
 	"github.com/Azure/eno/internal/controllers/aggregation"
 	"github.com/Azure/eno/internal/controllers/flowcontrol"
+	"github.com/Azure/eno/internal/controllers/identifier"
+	"github.com/Azure/eno/internal/controllers/quota"
 	"github.com/Azure/eno/internal/controllers/replication"
 	"github.com/Azure/eno/internal/controllers/rollout"
 	"github.com/Azure/eno/internal/controllers/synthesis"
 	"github.com/Azure/eno/internal/controllers/watch"
 	"github.com/Azure/eno/internal/controllers/watchdog"
+	"github.com/Azure/eno/internal/discovery"
 	"github.com/Azure/eno/internal/execution"
+	"github.com/Azure/eno/internal/k8s"
 	"github.com/Azure/eno/internal/manager"
+	"github.com/Azure/eno/internal/migration"
+	"github.com/Azure/eno/internal/telemetry"
+	"github.com/Azure/eno/internal/webhooks"
 )
 
 func main() {
@@ -34,6 +48,13 @@ func main() {
 		installExecutor()
 		return
 	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate-storage" {
+		if err := runStorageMigration(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 	if strings.HasSuffix(os.Args[0], "executor") {
 		runExecutor()
 		return
@@ -48,14 +69,27 @@ func main() {
 func runController() error {
 	ctx := ctrl.SetupSignalHandler()
 	var (
-		debugLogging     bool
-		watchdogThres    time.Duration
-		rolloutCooldown  time.Duration
-		dispatchCooldown time.Duration
-		taintToleration  string
-		nodeAffinity     string
-		concurrencyLimit int
-		synconf          = &synthesis.Config{}
+		debugLogging        bool
+		watchdogThres       time.Duration
+		rolloutCooldown     time.Duration
+		canarySoak          time.Duration
+		dispatchCooldown    time.Duration
+		aggregationInterval time.Duration
+		taintToleration     string
+		nodeAffinity        string
+		concurrencyLimit    int
+		preemptionQPS       float64
+		defaultingPolicy    string
+		fleetEndpoint       bool
+		archiveSink         string
+		bundleDir           string
+		bundleInterval      time.Duration
+		podLabels           string
+		pullSecrets         string
+		pullPolicy          string
+		registryMirrors     string
+		requiredEndpoints   string
+		synconf             = &synthesis.Config{}
 
 		mgrOpts = &manager.Options{
 			Rest: ctrl.GetConfigOrDie(),
@@ -66,19 +100,69 @@ func runController() error {
 	flag.StringVar(&synconf.ExecutorImage, "executor-image", os.Getenv("EXECUTOR_IMAGE"), "Reference to the image that will be used to execute synthesizers. Defaults to EXECUTOR_IMAGE.")
 	flag.StringVar(&synconf.PodServiceAccount, "synthesizer-pod-service-account", "", "Service account name to be assigned to synthesizer Pods.")
 	flag.DurationVar(&synconf.ContainerCreationTimeout, "container-creation-ttl", time.Second*3, "Timeout when waiting for kubelet to ack scheduled pods. Protects tail latency from kubelet network partitions")
+	flag.StringVar(&synconf.PostProcessorURL, "post-processor-url", "", "Optional URL of an HTTP service that will receive (and may mutate) the complete output of every synthesis before it is persisted.")
+	flag.DurationVar(&synconf.PostProcessorTimeout, "post-processor-timeout", time.Minute, "Timeout of requests to --post-processor-url")
+	flag.StringVar(&pullSecrets, "synthesizer-image-pull-secrets", "", "Comma-separated names of secrets in the synthesizer pod namespace used to pull the executor and synthesizer images")
+	flag.StringVar(&pullPolicy, "synthesizer-image-pull-policy", "", "Default image pull policy of synthesizer pod containers (Always, IfNotPresent, or Never). Synthesizers can override it")
+	flag.StringVar(&registryMirrors, "registry-mirrors", "", "Comma-separated registry=mirror pairs used to rewrite the executor and synthesizer images e.g. docker.io=mirror.example.com/dockerhub")
+	flag.StringVar(&podLabels, "synthesizer-pod-labels", "", "Comma-separated key=value labels added to every synthesizer pod e.g. to match NetworkPolicies that allow their egress")
+	flag.StringVar(&synconf.EgressProxy, "synthesizer-egress-proxy", "", "Optional proxy URL set as HTTP_PROXY and HTTPS_PROXY in synthesizer pods")
+	flag.StringVar(&synconf.NoProxy, "synthesizer-no-proxy", "", "Optional NO_PROXY value set in synthesizer pods. Should include the apiserver's address when --synthesizer-egress-proxy is set")
+	flag.StringVar(&requiredEndpoints, "synthesizer-required-endpoints", "", "Comma-separated host:port addresses that must be reachable from synthesizer pods before synthesizers are executed")
+	flag.BoolVar(&synconf.SopsDecryption, "sops-decryption", false, "Decrypt SOPS-encrypted ConfigMap and Secret inputs before synthesis. Requires the sops binary in the executor image")
+	flag.StringVar(&synconf.SopsKeySecret, "sops-key-secret", "", "Optional secret in the synthesizer pod namespace whose keys are exposed to sops as environment variables e.g. SOPS_AGE_KEY")
+	flag.BoolVar(&synconf.AllowSecretImports, "allow-secret-imports", false, "Allow compositions to import Secrets from their namespace with spec.import")
 	flag.BoolVar(&debugLogging, "debug", true, "Enable debug logging")
 	flag.DurationVar(&watchdogThres, "watchdog-threshold", time.Minute, "How long before the watchdog considers a mid-transition resource to be stuck")
 	flag.DurationVar(&rolloutCooldown, "rollout-cooldown", time.Minute, "How long before an update to a related resource (synthesizer, bindings, etc.) will trigger a second composition's re-synthesis")
-	flag.DurationVar(&dispatchCooldown, "dispatch-cooldown", time.Millisecond*100, "Min period between the dispatch of two syntheses. Effectively limits the rate of pod creation.")
+	flag.DurationVar(&canarySoak, "canary-soak-period", time.Minute*10, "How long canary compositions must be ready with a synthesizer change before it's rolled out to the rest of the fleet")
+	flag.DurationVar(&dispatchCooldown, "dispatch-cooldown", time.Millisecond*100, "Min period between synthesis dispatch passes. Each pass fills every slot available under the concurrency limit.")
+	flag.DurationVar(&aggregationInterval, "aggregation-min-interval", 0, "Min period between status updates of any one composition by the resource slice aggregation controller. Changes within the period are batched into a single write. Zero disables batching.")
 	flag.StringVar(&taintToleration, "taint-toleration", "", "Node NoSchedule taint to be tolerated by synthesizer pods e.g. taintKey=taintValue to match on value, just taintKey to match on presence of the taint")
 	flag.StringVar(&nodeAffinity, "node-affinity", "", "Synthesizer pods will be created with this required node affinity expression e.g. labelKey=labelValue to match on value, just labelKey to match on presence of the label")
+	flag.Float64Var(&preemptionQPS, "synthesis-preemption-qps", 0, "Max rate at which active syntheses can be preempted by higher priority syntheses when the concurrency limit has been reached. Zero disables preemption.")
 	flag.IntVar(&concurrencyLimit, "concurrency-limit", 10, "Upper bound on active syntheses. This effectively limits the number of running synthesizer pods spawned by Eno.")
+	flag.StringVar(&defaultingPolicy, "defaulting-policy", "", "Optional path to a yaml file containing platform-wide defaults for compositions and synthesizers. Requires --webhook-port.")
+	flag.StringVar(&archiveSink, "archive-sink", "", "Optional destination for the final manifests and status of deleted compositions. Either configmap:<namespace> or an http(s) URL.")
+	flag.StringVar(&bundleDir, "synthesizer-bundle-dir", "", "Optional directory of synthesizer bundle files. Each bundle is registered as a synthesizer.")
+	flag.DurationVar(&bundleInterval, "synthesizer-bundle-interval", time.Minute, "Interval at which --synthesizer-bundle-dir is re-read")
+	flag.BoolVar(&fleetEndpoint, "fleet-endpoint", false, "Serve a JSON summary of every composition and synthesizer at /fleet on the metrics server.")
+	flag.StringVar(&mgrOpts.Rest.UserAgent, "user-agent", "eno-controller", "User agent of requests to the apiserver. The name of the controller making each request is appended e.g. eno-controller/synthesisController")
 	mgrOpts.Bind(flag.CommandLine)
 	flag.Parse()
 
 	synconf.NodeAffinityKey, synconf.NodeAffinityValue = parseKeyValue(nodeAffinity)
 	synconf.TaintTolerationKey, synconf.TaintTolerationValue = parseKeyValue(taintToleration)
 
+	for _, name := range strings.Split(pullSecrets, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			synconf.ImagePullSecrets = append(synconf.ImagePullSecrets, name)
+		}
+	}
+	switch policy := corev1.PullPolicy(pullPolicy); policy {
+	case "", corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		synconf.ImagePullPolicy = policy
+	default:
+		return fmt.Errorf("invalid --synthesizer-image-pull-policy: %q", pullPolicy)
+	}
+	mirrors, err := synthesis.ParseRegistryMirrors(registryMirrors)
+	if err != nil {
+		return fmt.Errorf("invalid --registry-mirrors: %w", err)
+	}
+	synconf.RegistryMirrors = mirrors
+
+	if podLabels != "" {
+		synconf.PodLabels, err = labels.ConvertSelectorToLabelsMap(podLabels)
+		if err != nil {
+			return fmt.Errorf("invalid --synthesizer-pod-labels: %w", err)
+		}
+	}
+	for _, endpoint := range strings.Split(requiredEndpoints, ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			synconf.RequiredEndpoints = append(synconf.RequiredEndpoints, endpoint)
+		}
+	}
+
 	if synconf.ExecutorImage == "" {
 		return fmt.Errorf("a value is required in --executor-image or EXECUTOR_IMAGE")
 	}
@@ -97,22 +181,57 @@ func runController() error {
 	}
 	logger := zapr.NewLogger(zl)
 
-	mgrOpts.Rest.UserAgent = "eno-controller"
+	mgrOpts.Rest = k8s.InstrumentClient(k8s.WithControllerUserAgent(mgrOpts.Rest), k8s.Upstream)
 	mgr, err := manager.New(logger, mgrOpts)
 	if err != nil {
 		return fmt.Errorf("constructing manager: %w", err)
 	}
 
+	if mgrOpts.WebhookPort != 0 {
+		webhooks.NewConversionWebhook(mgr)
+		err = webhooks.NewCompositionValidator(mgr, webhooks.CompositionValidatorOptions{AllowSecretImports: synconf.AllowSecretImports})
+		if err != nil {
+			return fmt.Errorf("constructing composition validation webhook: %w", err)
+		}
+	}
+	if defaultingPolicy != "" {
+		if mgrOpts.WebhookPort == 0 {
+			return fmt.Errorf("--defaulting-policy requires --webhook-port")
+		}
+		policy, err := webhooks.LoadDefaultingPolicy(defaultingPolicy)
+		if err != nil {
+			return err
+		}
+		err = webhooks.NewDefaulters(mgr, policy)
+		if err != nil {
+			return fmt.Errorf("constructing defaulting webhooks: %w", err)
+		}
+	}
+
 	err = rollout.NewController(mgr, rolloutCooldown)
 	if err != nil {
 		return fmt.Errorf("constructing rollout controller: %w", err)
 	}
 
-	err = rollout.NewSynthesizerController(mgr)
+	err = rollout.NewSynthesizerController(mgr, canarySoak)
 	if err != nil {
 		return fmt.Errorf("constructing rollout controller: %w", err)
 	}
 
+	if archiveSink != "" {
+		synconf.Archive, err = archive.ParseSink(mgr.GetClient(), archiveSink)
+		if err != nil {
+			return fmt.Errorf("parsing --archive-sink: %w", err)
+		}
+	}
+
+	if bundleDir != "" {
+		err = bundle.NewLoader(mgr, bundleDir, bundleInterval)
+		if err != nil {
+			return fmt.Errorf("constructing synthesizer bundle loader: %w", err)
+		}
+	}
+
 	err = synthesis.NewPodLifecycleController(mgr, synconf)
 	if err != nil {
 		return fmt.Errorf("constructing pod lifecycle controller: %w", err)
@@ -123,7 +242,29 @@ func runController() error {
 		return fmt.Errorf("constructing resource slice cleanup controller: %w", err)
 	}
 
-	err = watchdog.NewController(mgr, watchdogThres)
+	err = synthesis.NewImpactAnalysisController(mgr, synconf)
+	if err != nil {
+		return fmt.Errorf("constructing impact analysis controller: %w", err)
+	}
+
+	err = mgr.AddMetricsServerExtraHandler("/metrics/catalog", telemetry.CatalogHandler())
+	if err != nil {
+		return fmt.Errorf("adding metrics catalog endpoint: %w", err)
+	}
+	err = mgr.AddMetricsServerExtraHandler("/metrics/dashboard", telemetry.DashboardHandler("Eno Controller"))
+	if err != nil {
+		return fmt.Errorf("adding metrics dashboard endpoint: %w", err)
+	}
+
+	var fleet *watchdog.Fleet
+	if fleetEndpoint {
+		fleet = &watchdog.Fleet{}
+		err = mgr.AddMetricsServerExtraHandler("/fleet", fleet)
+		if err != nil {
+			return fmt.Errorf("adding fleet endpoint: %w", err)
+		}
+	}
+	err = watchdog.NewController(mgr, watchdogThres, fleet)
 	if err != nil {
 		return fmt.Errorf("constructing watchdog controller: %w", err)
 	}
@@ -143,17 +284,32 @@ func runController() error {
 		return fmt.Errorf("constructing composition status aggregation controller: %w", err)
 	}
 
-	err = aggregation.NewSliceController(mgr)
+	err = aggregation.NewSliceController(mgr, aggregationInterval)
 	if err != nil {
 		return fmt.Errorf("constructing status aggregation controller: %w", err)
 	}
 
+	err = aggregation.NewDeletionController(mgr)
+	if err != nil {
+		return fmt.Errorf("constructing deletion progress controller: %w", err)
+	}
+
+	err = identifier.NewPoolController(mgr)
+	if err != nil {
+		return fmt.Errorf("constructing identifier pool controller: %w", err)
+	}
+
+	err = quota.NewController(mgr)
+	if err != nil {
+		return fmt.Errorf("constructing quota controller: %w", err)
+	}
+
 	err = watch.NewController(mgr)
 	if err != nil {
 		return fmt.Errorf("constructing watch controller: %w", err)
 	}
 
-	err = flowcontrol.NewSynthesisConcurrencyLimiter(mgr, concurrencyLimit, dispatchCooldown)
+	err = flowcontrol.NewSynthesisConcurrencyLimiter(mgr, concurrencyLimit, dispatchCooldown, preemptionQPS)
 	if err != nil {
 		return fmt.Errorf("constructing synthesis concurrency limiter : %w", err)
 	}
@@ -170,6 +326,30 @@ func parseKeyValue(input string) (key, val string) {
 	return
 }
 
+// runStorageMigration rewrites every Eno resource using the current storage version of its CRD.
+// It's intended to be run as a Job after upgrading to a release that changes the storage version.
+func runStorageMigration() error {
+	rc := ctrl.GetConfigOrDie()
+	rc.UserAgent = "eno-storage-migration"
+
+	zl, err := zap.NewProductionConfig().Build()
+	if err != nil {
+		return err
+	}
+	ctx := logr.NewContext(ctrl.SetupSignalHandler(), zapr.NewLogger(zl))
+
+	scheme := runtime.NewScheme()
+	err = v1.SchemeBuilder.AddToScheme(scheme)
+	if err != nil {
+		return err
+	}
+	cli, err := client.New(rc, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	return migration.MigrateStorage(ctx, cli)
+}
+
 func installExecutor() {
 	self := os.Args[0]
 	file, err := os.Open(self)
@@ -188,6 +368,21 @@ func installExecutor() {
 	if err != nil {
 		panic(err)
 	}
+
+	// The sops binary is only present in images built with SOPS support
+	if sops, err := os.Open("/sops"); err == nil {
+		defer sops.Close()
+		dest, err := os.OpenFile("/eno/sops", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0777)
+		if err != nil {
+			panic(err)
+		}
+		defer dest.Close()
+
+		_, err = io.Copy(dest, sops)
+		if err != nil {
+			panic(err)
+		}
+	}
 }
 
 func runExecutor() {
@@ -200,6 +395,10 @@ func runExecutor() {
 		panic(err)
 	}
 	logger := zapr.NewLogger(zl)
+	if err := execution.ProtectEnv(); err != nil {
+		logger.Error(err, "protecting executor environment")
+		os.Exit(1)
+	}
 	ctx := logr.NewContext(ctrl.SetupSignalHandler(), logger)
 
 	hc, err := rest.HTTPClientFor(rc)
@@ -216,6 +415,10 @@ func runExecutor() {
 		logger.Error(err, "building scheme")
 		os.Exit(1)
 	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		logger.Error(err, "adding core types to scheme")
+		os.Exit(1)
+	}
 	client, err := client.New(rc, client.Options{
 		Scheme: scheme,
 		Mapper: rm,
@@ -225,14 +428,55 @@ func runExecutor() {
 		os.Exit(1)
 	}
 
+	// The openapi schema is only fetched when the composition enables unknown field checks
+	schemas, err := discovery.NewCache(rc)
+	if err != nil {
+		logger.Error(err, "building discovery client")
+		os.Exit(1)
+	}
+
+	env := execution.LoadEnv()
+	logs := &execution.LogBuffer{}
 	e := &execution.Executor{
 		Reader:  client,
 		Writer:  client,
-		Handler: execution.NewExecHandler(),
+		Handler: execution.NewExecHandlerWithLogs(logs),
+		Schemas: schemas,
+		Mapper:  rm,
+		Logs:    logs,
+	}
+	if env.PostProcessorURL != "" {
+		e.PostProcessor = execution.NewHTTPPostProcessor(env.PostProcessorURL, env.PostProcessorTimeout)
+	}
+	if env.SopsBinary != "" {
+		e.Decrypter = execution.NewSopsDecrypter(env.SopsBinary)
+	}
+	if env.DryRun {
+		runDryRun(ctx, e, env)
+		return
 	}
-	err = e.Synthesize(ctx, execution.LoadEnv())
+	err = e.Synthesize(ctx, env)
 	if err != nil {
 		logger.Error(err, "synthesizing")
 		os.Exit(1)
 	}
 }
+
+// runDryRun reports the result of a dry run as the container's termination message, where it's read by the controller.
+func runDryRun(ctx context.Context, e *execution.Executor, env *execution.Env) {
+	logger := logr.FromContextOrDiscard(ctx)
+	result, err := e.DryRun(ctx, env)
+	if err != nil {
+		logger.Error(err, "dry run")
+		os.Exit(1)
+	}
+	js, err := json.Marshal(result)
+	if err != nil {
+		logger.Error(err, "encoding dry run result")
+		os.Exit(1)
+	}
+	if err := os.WriteFile("/dev/termination-log", js, 0644); err != nil {
+		logger.Error(err, "writing dry run result")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,80 @@
+// eno-loadtest creates synthetic compositions against a cluster running Eno and reports their convergence latency.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/loadtest"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		cfg     = &loadtest.Config{}
+		timeout time.Duration
+		output  string
+		cleanup bool
+	)
+	flag.IntVar(&cfg.Compositions, "compositions", 100, "Number of compositions to create")
+	flag.IntVar(&cfg.Resources, "resources", 10, "Number of ConfigMaps synthesized for each composition")
+	flag.StringVar(&cfg.Namespace, "namespace", "default", "Namespace of the compositions and their resources")
+	flag.Float64Var(&cfg.MutationRate, "mutation-rate", 1, "Composition updates per second after initial convergence. Zero disables mutations")
+	flag.DurationVar(&cfg.Duration, "duration", time.Minute, "How long to mutate compositions for")
+	flag.StringVar(&cfg.Image, "synthesizer-image", "docker.io/ubuntu:latest", "Synthesizer image. Must include bash")
+	flag.DurationVar(&timeout, "timeout", time.Minute*30, "Upper bound on the duration of the entire run")
+	flag.StringVar(&output, "output", "text", "Report format: text or json")
+	flag.BoolVar(&cleanup, "cleanup", true, "Delete the compositions once the run completes")
+	flag.Parse()
+
+	zl, err := zap.NewProduction()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctrl.SetupSignalHandler(), timeout)
+	defer cancel()
+	ctx = logr.NewContext(ctx, zapr.NewLogger(zl))
+
+	scheme := runtime.NewScheme()
+	if err := apiv1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		return err
+	}
+	cli, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	report, err := loadtest.Run(ctx, cli, cfg)
+	if err != nil {
+		return err
+	}
+	if cleanup {
+		if err := loadtest.Cleanup(ctx, cli, cfg.Namespace); err != nil {
+			return err
+		}
+	}
+
+	if output == "json" {
+		return json.NewEncoder(os.Stdout).Encode(report)
+	}
+	report.Print(os.Stdout)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-logr/zapr"
@@ -11,6 +12,9 @@ import (
 	"go.uber.org/zap/zapcore"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/Azure/eno/internal/controllers/liveness"
@@ -19,6 +23,7 @@ import (
 	"github.com/Azure/eno/internal/k8s"
 	"github.com/Azure/eno/internal/manager"
 	"github.com/Azure/eno/internal/reconstitution"
+	"github.com/Azure/eno/internal/telemetry"
 )
 
 func main() {
@@ -35,29 +40,69 @@ func run() error {
 		debugLogging                 bool
 		remoteKubeconfigFile         string
 		remoteQPS                    float64
+		remoteTLSSecret              string
+		remoteTLSServerName          string
+		remoteTLSRefreshInterval     time.Duration
+		remoteCredentialRefresh      time.Duration
+		remoteUserAgent              string
+		remoteImpersonateUser        string
+		remoteImpersonateGroups      string
 		compositionSelector          string
 		compositionNamespace         string
 		namespaceCreationGracePeriod time.Duration
 		namespaceCleanup             bool
+		logPatchKinds                string
+		listKinds                    string
+		listSelector                 string
+		profilesPath                 string
+		patchStrategies              string
+		schemalessPatchStrategy      string
+		listMergeKeys                string
 
 		mgrOpts = &manager.Options{
 			Rest: ctrl.GetConfigOrDie(),
 		}
 
-		recOpts = reconciliation.Options{
-			DiscoveryRPS: 2,
-		}
+		recOpts = reconciliation.Options{}
 	)
 	flag.DurationVar(&writeBatchInterval, "write-batch-interval", time.Second*5, "The max throughput of composition status updates")
 	flag.BoolVar(&debugLogging, "debug", true, "Enable debug logging")
 	flag.StringVar(&remoteKubeconfigFile, "remote-kubeconfig", "", "Path to the kubeconfig of the apiserver where the resources will be reconciled. The config from the environment is used if this is not provided")
 	flag.Float64Var(&remoteQPS, "remote-qps", 50, "Max requests per second to the remote apiserver")
+	flag.StringVar(&remoteTLSSecret, "remote-tls-secret", "", "Optional namespace/name of a Secret holding the client certificate (tls.crt, tls.key) and/or CA bundle (ca.crt) used to connect to the remote apiserver. Reloaded periodically to support rotation")
+	flag.StringVar(&remoteTLSServerName, "remote-tls-server-name", "", "Optional server name (SNI) used to connect to and verify the remote apiserver's certificate")
+	flag.DurationVar(&remoteTLSRefreshInterval, "remote-tls-refresh-interval", time.Minute, "Interval at which --remote-tls-secret is reloaded")
+	flag.DurationVar(&remoteCredentialRefresh, "remote-credential-refresh-interval", 0, "Reload the credentials (tokens, exec plugins) of --remote-kubeconfig at this interval, and soon after the remote apiserver rejects them, so rotated credentials are used without a restart. Zero disables reloading")
 	flag.DurationVar(&recOpts.Timeout, "timeout", time.Minute, "Per-resource reconciliation timeout. Avoids cases where client retries/timeouts are configured poorly and the loop gets blocked")
+	flag.DurationVar(&recOpts.HealthProbeInterval, "downstream-health-probe-interval", time.Second*10, "Interval at which the remote apiserver's health is probed. Zero disables the health gate")
+	flag.IntVar(&recOpts.HealthProbeThreshold, "downstream-health-probe-threshold", 3, "Reconciliation is paused after this many consecutive failed health probes, and resumes once a probe succeeds")
+	flag.IntVar(&recOpts.CircuitBreakerThreshold, "circuit-breaker-threshold", 10, "Writes of a resource type are paused after this many consecutive server-side failures e.g. due to an unavailable admission webhook. Zero disables the circuit breaker")
+	flag.DurationVar(&recOpts.CircuitBreakerCooldown, "circuit-breaker-cooldown", time.Minute, "How long writes of a resource type are paused once its circuit breaker opens")
 	flag.DurationVar(&recOpts.ReadinessPollInterval, "readiness-poll-interval", time.Second*5, "Interval at which non-ready resources will be checked for readiness")
+	flag.DurationVar(&recOpts.AdmissionSettleDuration, "admission-settle-duration", time.Second*5, "How long webhook configurations and APIServices must have been ready before later readiness groups are reconciled, unless overridden by the eno.azure.io/settle-duration annotation. Zero disables the delay")
 	flag.StringVar(&compositionSelector, "composition-label-selector", labels.Everything().String(), "Optional label selector for compositions to be reconciled")
 	flag.StringVar(&compositionNamespace, "composition-namespace", metav1.NamespaceAll, "Optional namespace to limit compositions that will be reconciled")
 	flag.DurationVar(&namespaceCreationGracePeriod, "ns-creation-grace-period", time.Second, "A namespace is assumed to be missing if it doesn't exist once one of its resources has existed for this long")
 	flag.BoolVar(&namespaceCleanup, "namespace-cleanup", true, "Clean up orphaned resources caused by namespace force-deletions")
+	flag.BoolVar(&recOpts.OwnerAnnotations, "owner-annotations", true, "Annotate reconciled resources with the name and namespace of the composition that manages them")
+	flag.StringVar(&recOpts.ApplySetNamespace, "applyset-namespace", "", "Maintain an ApplySet for each composition, with a parent ConfigMap in this namespace of the remote cluster. Disabled when empty")
+	flag.StringVar(&recOpts.StatusMirrorNamespace, "status-mirror-namespace", "", "Maintain a ConfigMap summarizing the status of each composition in this namespace of the remote cluster, for users without access to the upstream cluster. Disabled when empty")
+	flag.StringVar(&logPatchKinds, "log-patch-kinds", "", "Comma-separated list of resource types (Kind.group e.g. Deployment.apps, ConfigMap) whose patches will be logged in full. Only the modified field paths are logged for other types")
+	flag.StringVar(&listKinds, "list-kinds", "", "Comma-separated list of resource types (Kind.group e.g. Deployment.apps, ConfigMap) whose current state will be read using a single LIST per namespace instead of a GET per resource. Useful for compositions with many resources of the same kind")
+	flag.StringVar(&listSelector, "list-label-selector", "", "Optional label selector applied to LIST requests for --list-kinds. Resources not matching the selector are read with a GET")
+	flag.BoolVar(&recOpts.SecretRefs, "resolve-secret-refs", false, "Resolve $(secret:namespace/name/key) placeholders in synthesized manifests from Secrets in the composition's namespace when resources are applied. Requires permission to read secrets")
+	flag.BoolVar(&recOpts.Protobuf, "downstream-protobuf", true, "Read native resource types from the remote apiserver using protobuf instead of json. Custom resources always use json")
+	flag.DurationVar(&recOpts.ListTTL, "list-ttl", time.Second*5, "How long the results of a LIST for --list-kinds are used before being refreshed")
+	flag.IntVar(&recOpts.ManagedFieldsThreshold, "managed-fields-threshold", 50, "Resources with more managedFields entries than this are reported with a metric and event. Zero disables the threshold")
+	flag.BoolVar(&recOpts.ManagedFieldsCleanup, "managed-fields-cleanup", false, "Reset the managedFields of resources that exceed --managed-fields-threshold")
+	flag.StringVar(&profilesPath, "profiles", "", "Optional path to a yaml file defining behavior profiles (reconcile interval scaling, write concurrency, drift correction) and the compositions they apply to")
+	flag.StringVar(&patchStrategies, "patch-strategies", "", "Comma-separated Kind.group=strategy pairs overriding how resources of particular types are updated. Strategies: merge, strategic, apply (server-side apply)")
+	flag.StringVar(&schemalessPatchStrategy, "schemaless-patch-strategy", string(reconciliation.PatchStrategyMerge), "Patch strategy (merge or apply) for types without a usable openapi model e.g. those served by some aggregated apiservers. Apply falls back to merge when the apiserver doesn't support it")
+	flag.StringVar(&listMergeKeys, "list-merge-keys", "", "Comma-separated Kind.group:path=key entries (e.g. Widget.example.com:spec.routes=name) that cause merge patches to merge the items of particular lists by key instead of replacing the entire list")
+	flag.StringVar(&mgrOpts.Rest.UserAgent, "user-agent", "eno-reconciler", "User agent of requests to the apiserver. The name of the controller making each request is appended e.g. eno-reconciler/reconciliationController")
+	flag.StringVar(&remoteUserAgent, "remote-user-agent", "", "User agent of requests to the remote apiserver. Defaults to --user-agent when --remote-kubeconfig isn't set. Changing it changes the field manager that owns reconciled fields")
+	flag.StringVar(&remoteImpersonateUser, "remote-impersonate-user", "", "Optional user to impersonate when making requests to the remote apiserver, e.g. to match a particular API Priority and Fairness FlowSchema. Requires permission to impersonate")
+	flag.StringVar(&remoteImpersonateGroups, "remote-impersonate-groups", "", "Optional comma-separated groups to impersonate when making requests to the remote apiserver. Requires permission to impersonate")
 	mgrOpts.Bind(flag.CommandLine)
 	flag.Parse()
 
@@ -82,7 +127,8 @@ func run() error {
 		mgrOpts.CompositionSelector = labels.Everything()
 	}
 
-	mgrOpts.Rest.UserAgent = "eno-reconciler"
+	upstreamConfig := mgrOpts.Rest
+	mgrOpts.Rest = k8s.InstrumentClient(k8s.WithControllerUserAgent(upstreamConfig), k8s.Upstream)
 	mgr, err := manager.NewReconciler(logger, mgrOpts)
 	if err != nil {
 		return fmt.Errorf("constructing manager: %w", err)
@@ -95,7 +141,9 @@ func run() error {
 		}
 	}
 
-	remoteConfig := mgr.GetConfig()
+	// The manager's config reports requests as upstream
+	remoteConfig := rest.CopyConfig(upstreamConfig)
+	remoteConfig.QPS = mgr.GetConfig().QPS
 	if remoteKubeconfigFile != "" {
 		if remoteConfig, err = k8s.GetRESTConfig(remoteKubeconfigFile); err != nil {
 			return err
@@ -103,6 +151,50 @@ func run() error {
 		if remoteQPS != 0 {
 			remoteConfig.QPS = float32(remoteQPS)
 		}
+		if remoteCredentialRefresh > 0 {
+			reloader := k8s.NewCredentialReloader(remoteKubeconfigFile, remoteCredentialRefresh)
+			remoteConfig = reloader.Apply(remoteConfig)
+			if err := reloader.Load(ctx); err != nil {
+				return fmt.Errorf("loading remote credentials: %w", err)
+			}
+			if err := mgr.Add(reloader); err != nil {
+				return fmt.Errorf("adding credential reloader: %w", err)
+			}
+		}
+	}
+	if remoteUserAgent != "" {
+		remoteConfig.UserAgent = remoteUserAgent
+	}
+	if remoteImpersonateGroups != "" && remoteImpersonateUser == "" {
+		return fmt.Errorf("--remote-impersonate-groups requires --remote-impersonate-user")
+	}
+	if remoteImpersonateUser != "" {
+		remoteConfig.Impersonate.UserName = remoteImpersonateUser
+		for _, group := range strings.Split(remoteImpersonateGroups, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				remoteConfig.Impersonate.Groups = append(remoteConfig.Impersonate.Groups, group)
+			}
+		}
+	}
+	remoteConfig = k8s.WithControllerUserAgent(k8s.CountAuthFailures(remoteConfig))
+	remoteConfig = k8s.InstrumentClient(remoteConfig, k8s.Downstream)
+	if remoteTLSServerName != "" {
+		remoteConfig = rest.CopyConfig(remoteConfig)
+		remoteConfig.ServerName = remoteTLSServerName
+	}
+	if remoteTLSSecret != "" {
+		ns, name, ok := strings.Cut(remoteTLSSecret, "/")
+		if !ok {
+			return fmt.Errorf("invalid --remote-tls-secret: expected namespace/name")
+		}
+		reloader := k8s.NewTLSSecretReloader(mgr.GetAPIReader(), types.NamespacedName{Namespace: ns, Name: name}, remoteTLSRefreshInterval)
+		remoteConfig = reloader.Apply(remoteConfig)
+		if err := reloader.Load(ctx); err != nil {
+			return fmt.Errorf("loading --remote-tls-secret: %w", err)
+		}
+		if err := mgr.Add(reloader); err != nil {
+			return fmt.Errorf("adding tls secret reloader: %w", err)
+		}
 	}
 
 	// Burst of 1 allows the first write to happen immediately, while subsequent writes are debounced/batched at writeBatchInterval.
@@ -114,6 +206,40 @@ func run() error {
 	recOpts.Cache = rCache
 	recOpts.WriteBuffer = writeBuffer
 	recOpts.Downstream = remoteConfig
+	for _, kind := range strings.Split(logPatchKinds, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			recOpts.LogPatchGroupKinds = append(recOpts.LogPatchGroupKinds, schema.ParseGroupKind(kind))
+		}
+	}
+	for _, kind := range strings.Split(listKinds, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			recOpts.ListGroupKinds = append(recOpts.ListGroupKinds, schema.ParseGroupKind(kind))
+		}
+	}
+	recOpts.PatchStrategies, err = reconciliation.ParsePatchStrategies(patchStrategies)
+	if err != nil {
+		return fmt.Errorf("invalid --patch-strategies: %w", err)
+	}
+	recOpts.SchemalessPatchStrategy, err = reconciliation.ParsePatchStrategy(schemalessPatchStrategy)
+	if err != nil {
+		return fmt.Errorf("invalid --schemaless-patch-strategy: %w", err)
+	}
+	recOpts.ListMergeKeys, err = reconciliation.ParseListMergeKeys(listMergeKeys)
+	if err != nil {
+		return fmt.Errorf("invalid --list-merge-keys: %w", err)
+	}
+	if listSelector != "" {
+		recOpts.ListSelector, err = labels.Parse(listSelector)
+		if err != nil {
+			return fmt.Errorf("invalid list label selector: %w", err)
+		}
+	}
+	if profilesPath != "" {
+		recOpts.Profiles, err = reconciliation.LoadProfiles(profilesPath)
+		if err != nil {
+			return err
+		}
+	}
 	reconciler, err := reconciliation.New(recOpts)
 	if err != nil {
 		return fmt.Errorf("constructing reconciliation controller: %w", err)
@@ -123,5 +249,25 @@ func run() error {
 		return fmt.Errorf("constructing reconstitution manager: %w", err)
 	}
 
+	err = mgr.AddMetricsServerExtraHandler("/explain", reconciler)
+	if err != nil {
+		return fmt.Errorf("registering explain handler: %w", err)
+	}
+
+	err = mgr.AddMetricsServerExtraHandler("/search", reconstitution.NewSearchHandler(mgr.GetClient(), rCache))
+	if err != nil {
+		return fmt.Errorf("registering search handler: %w", err)
+	}
+
+	err = mgr.AddMetricsServerExtraHandler("/metrics/catalog", telemetry.CatalogHandler())
+	if err != nil {
+		return fmt.Errorf("registering metrics catalog handler: %w", err)
+	}
+
+	err = mgr.AddMetricsServerExtraHandler("/metrics/dashboard", telemetry.DashboardHandler("Eno Reconciler"))
+	if err != nil {
+		return fmt.Errorf("registering metrics dashboard handler: %w", err)
+	}
+
 	return mgr.Start(ctx)
 }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/Azure/eno/pkg/enoclient"
+)
+
+func runDiff() error {
+	var (
+		namespace string
+		from      string
+		to        string
+		output    string
+	)
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	flags.StringVar(&namespace, "n", "default", "Namespace of the composition")
+	flags.StringVar(&from, "from", "", "UUID of the synthesis to compare from. Defaults to the previous synthesis")
+	flags.StringVar(&to, "to", "", "UUID of the synthesis to compare to. Defaults to the current synthesis")
+	flags.StringVar(&output, "o", "text", "Output format: text or json")
+
+	// Allow flags before or after the composition name
+	var name string
+	remaining := os.Args[2:]
+	for len(remaining) > 0 {
+		if err := flags.Parse(remaining); err != nil {
+			return err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		if name != "" {
+			return errors.New("expected a single composition name")
+		}
+		name = flags.Arg(0)
+		remaining = flags.Args()[1:]
+	}
+	if name == "" {
+		return errors.New("expected a composition name")
+	}
+	if output != "text" && output != "json" {
+		return fmt.Errorf("unknown output format %q", output)
+	}
+
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return err
+	}
+	cli, err := enoclient.New(config)
+	if err != nil {
+		return err
+	}
+	changes, err := cli.GetDiff(context.Background(), types.NamespacedName{Name: name, Namespace: namespace}, from, to)
+	if err != nil {
+		return err
+	}
+
+	if output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(changes)
+	}
+	if len(changes) == 0 {
+		fmt.Println("no changes")
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Printf("# %s %s\n%s\n", change.Type, change.Ref, change.Diff)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// kubectl-eno is a kubectl plugin for operators of clusters managed by Eno.
+//
+// Usage:
+//
+//	kubectl eno owner <Kind.version.group> <name> [-n namespace]
+//	kubectl eno verify-synthesizer --synthesizer <file> [--input <file>]... [--command <binary>]
+//	kubectl eno diff <composition> [-n namespace] [--from uuid] [--to uuid] [-o text|json]
+//	kubectl eno promote <composition> [-n namespace] --to namespace [--name name] [--dry-run]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/k8s"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+const usage = `usage:
+  kubectl eno owner <Kind.version.group> <name> [-n namespace] [--upstream-kubeconfig path]
+  kubectl eno verify-synthesizer --synthesizer <file> [--input <file>]... [--command <binary>] [--max-output-bytes n]
+  kubectl eno diff <composition> [-n namespace] [--from uuid] [--to uuid] [-o text|json]
+  kubectl eno promote <composition> [-n namespace] --to namespace [--name name] [--dry-run]`
+
+func run() error {
+	if len(os.Args) < 2 {
+		return errors.New(usage)
+	}
+	switch os.Args[1] {
+	case "owner":
+		return runOwner()
+	case "verify-synthesizer":
+		return runVerifySynthesizer()
+	case "diff":
+		return runDiff()
+	case "promote":
+		return runPromote()
+	default:
+		return errors.New(usage)
+	}
+}
+
+func runOwner() error {
+	var (
+		namespace          string
+		upstreamKubeconfig string
+	)
+	flags := flag.NewFlagSet("owner", flag.ExitOnError)
+	flags.StringVar(&namespace, "n", "", "Namespace of the resource (omit for cluster-scoped resources)")
+	flags.StringVar(&upstreamKubeconfig, "upstream-kubeconfig", "", "Path to the kubeconfig of the cluster that holds Eno compositions. The current cluster is used if this is not provided")
+
+	// Allow flags before or after the positional args
+	args := []string{}
+	remaining := os.Args[2:]
+	for len(remaining) > 0 {
+		if err := flags.Parse(remaining); err != nil {
+			return err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		args = append(args, flags.Arg(0))
+		remaining = flags.Args()[1:]
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("expected a resource type (Kind.version.group e.g. Deployment.v1.apps, ConfigMap.v1.) and name")
+	}
+	gvk, _ := schema.ParseKindArg(args[0])
+	if gvk == nil {
+		return fmt.Errorf("resource type must be fully qualified as Kind.version.group (e.g. Deployment.v1.apps, ConfigMap.v1.)")
+	}
+
+	downstream, err := ctrl.GetConfig()
+	if err != nil {
+		return err
+	}
+	upstream := downstream
+	if upstreamKubeconfig != "" {
+		if upstream, err = k8s.GetRESTConfig(upstreamKubeconfig); err != nil {
+			return err
+		}
+	}
+
+	return lookupOwner(context.Background(), downstream, upstream, *gvk, types.NamespacedName{Name: args[1], Namespace: namespace})
+}
+
+func lookupOwner(ctx context.Context, downstream, upstream *rest.Config, gvk schema.GroupVersionKind, nsn types.NamespacedName) error {
+	dcli, err := client.New(downstream, client.Options{})
+	if err != nil {
+		return err
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := dcli.Get(ctx, nsn, obj); err != nil {
+		return fmt.Errorf("getting resource: %w", err)
+	}
+
+	anno := obj.GetAnnotations()
+	compNSN := types.NamespacedName{Name: anno[apiv1.CompositionNameAnnotation], Namespace: anno[apiv1.CompositionNamespaceAnnotation]}
+	if compNSN.Name == "" {
+		return fmt.Errorf("resource is not managed by Eno (or was last reconciled by a version that didn't set the %s annotation)", apiv1.CompositionNameAnnotation)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := apiv1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		return err
+	}
+	ucli, err := client.New(upstream, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	comp := &apiv1.Composition{}
+	if err := ucli.Get(ctx, compNSN, comp); err != nil {
+		return fmt.Errorf("getting composition %s: %w", compNSN, err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintf(w, "COMPOSITION\tNAMESPACE\tSYNTHESIZER\tSYNTHESIS\tSYNTHESIZER GENERATION\n")
+	var uuid, gen string
+	if syn := comp.Status.CurrentSynthesis; syn != nil {
+		uuid = syn.UUID
+		gen = fmt.Sprint(syn.ObservedSynthesizerGeneration)
+	}
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", comp.Name, comp.Namespace, comp.Spec.Synthesizer.Name, uuid, gen)
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+func runPromote() error {
+	var (
+		namespace   string
+		toNamespace string
+		toName      string
+		dryRun      bool
+	)
+	flags := flag.NewFlagSet("promote", flag.ExitOnError)
+	flags.StringVar(&namespace, "n", "default", "Namespace of the composition")
+	flags.StringVar(&toNamespace, "to", "", "Namespace to promote the composition to")
+	flags.StringVar(&toName, "name", "", "Name of the promoted composition. Defaults to the name of the composition")
+	flags.BoolVar(&dryRun, "dry-run", false, "Print the promoted composition instead of writing it")
+
+	// Allow flags before or after the composition name
+	var name string
+	remaining := os.Args[2:]
+	for len(remaining) > 0 {
+		if err := flags.Parse(remaining); err != nil {
+			return err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		if name != "" {
+			return errors.New("expected a single composition name")
+		}
+		name = flags.Arg(0)
+		remaining = flags.Args()[1:]
+	}
+	if name == "" {
+		return errors.New("expected a composition name")
+	}
+	if toNamespace == "" {
+		return errors.New("expected a namespace to promote to (--to)")
+	}
+	if toName == "" {
+		toName = name
+	}
+	if toNamespace == namespace && toName == name {
+		return errors.New("a composition can't be promoted to itself")
+	}
+
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return err
+	}
+	scheme := runtime.NewScheme()
+	if err := apiv1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		return err
+	}
+	cli, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	comp := &apiv1.Composition{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, comp); err != nil {
+		return fmt.Errorf("getting composition: %w", err)
+	}
+	promoted, err := comp.Promote(toNamespace, toName)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		promoted.APIVersion = apiv1.SchemeGroupVersion.String()
+		promoted.Kind = "Composition"
+		js, err := yaml.Marshal(promoted)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(js)
+		return err
+	}
+	return writePromoted(ctx, cli, promoted)
+}
+
+// writePromoted creates the promoted composition, or replaces the spec of an earlier promotion.
+func writePromoted(ctx context.Context, cli client.Client, promoted *apiv1.Composition) error {
+	current := &apiv1.Composition{}
+	err := cli.Get(ctx, client.ObjectKeyFromObject(promoted), current)
+	if client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("getting promoted composition: %w", err)
+	}
+	if err != nil {
+		if err := cli.Create(ctx, promoted); err != nil {
+			return fmt.Errorf("creating promoted composition: %w", err)
+		}
+		fmt.Printf("composition %s/%s created (synthesis %s)\n", promoted.Namespace, promoted.Name, promoted.Annotations[apiv1.PromotedSynthesisAnnotation])
+		return nil
+	}
+
+	if current.Annotations[apiv1.PromotedFromAnnotation] == "" {
+		return fmt.Errorf("composition %s/%s already exists and wasn't created by promotion", current.Namespace, current.Name)
+	}
+	current.Spec = promoted.Spec
+	if current.Annotations == nil {
+		current.Annotations = map[string]string{}
+	}
+	current.Annotations[apiv1.PromotedFromAnnotation] = promoted.Annotations[apiv1.PromotedFromAnnotation]
+	current.Annotations[apiv1.PromotedSynthesisAnnotation] = promoted.Annotations[apiv1.PromotedSynthesisAnnotation]
+	if err := cli.Update(ctx, current); err != nil {
+		return fmt.Errorf("updating promoted composition: %w", err)
+	}
+	fmt.Printf("composition %s/%s updated (synthesis %s)\n", current.Namespace, current.Name, promoted.Annotations[apiv1.PromotedSynthesisAnnotation])
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/conformance"
+	"github.com/Azure/eno/internal/execution"
+)
+
+type stringSlice []string
+
+func (s *stringSlice) String() string       { return strings.Join(*s, ",") }
+func (s *stringSlice) Set(val string) error { *s = append(*s, val); return nil }
+
+func runVerifySynthesizer() error {
+	var (
+		synthPath      string
+		inputPaths     stringSlice
+		command        string
+		maxOutputBytes int
+	)
+	flags := flag.NewFlagSet("verify-synthesizer", flag.ExitOnError)
+	flags.StringVar(&synthPath, "synthesizer", "", "Path to the synthesizer's manifest")
+	flags.Var(&inputPaths, "input", "Path to an input resource annotated with eno.azure.io/input-key. Can be given more than once")
+	flags.StringVar(&command, "command", "", "Run this local binary instead of the synthesizer's image (with docker)")
+	flags.IntVar(&maxOutputBytes, "max-output-bytes", conformance.DefaultMaxOutputBytes, "Upper bound on the total size of the synthesizer's output")
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		return err
+	}
+	if synthPath == "" {
+		return errors.New("--synthesizer is required")
+	}
+
+	synth := &apiv1.Synthesizer{}
+	if err := decodeFile(synthPath, synth); err != nil {
+		return fmt.Errorf("reading synthesizer: %w", err)
+	}
+	inputs := []*unstructured.Unstructured{}
+	for _, path := range inputPaths {
+		input := &unstructured.Unstructured{}
+		if err := decodeFile(path, input); err != nil {
+			return fmt.Errorf("reading input: %w", err)
+		}
+		inputs = append(inputs, input)
+	}
+
+	// The exec handler runs the synthesizer's command, so point it at either the local binary or docker
+	cmd := synth.Spec.Command
+	if len(cmd) == 0 {
+		cmd = []string{"synthesize"}
+	}
+	if command != "" {
+		cmd = append([]string{command}, cmd[1:]...)
+	} else {
+		cmd = append([]string{"docker", "run", "--rm", "-i", "--network=none", synth.Spec.Image}, cmd...)
+	}
+	synth.Spec.Command = cmd
+
+	results, err := conformance.Verify(context.Background(), &conformance.Options{
+		Synthesizer:    synth,
+		Handler:        execution.NewExecHandler(),
+		Inputs:         inputs,
+		MaxOutputBytes: maxOutputBytes,
+	})
+	if err != nil {
+		return err
+	}
+
+	var failed bool
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "CHECK\tRESULT\tMESSAGE\n")
+	for _, result := range results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+			failed = true
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Check, status, result.Message)
+	}
+	w.Flush()
+
+	if failed {
+		return errors.New("synthesizer is not conformant")
+	}
+	return nil
+}
+
+func decodeFile(path string, out any) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(out)
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s is empty", path)
+	}
+	return err
+}
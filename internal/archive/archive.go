@@ -0,0 +1,134 @@
+// Package archive exports the final state of compositions before they are deleted,
+// so post-mortem analysis remains possible after the composition and its resource slices are gone.
+package archive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+// AnnotationKey is set on compositions once they have been archived to avoid exporting them more than once.
+// The value is AnnotationValueFailed when the sink failed to accept the archive, which doesn't block deletion.
+const AnnotationKey = "eno.azure.io/archived"
+
+const (
+	AnnotationValueArchived = "true"
+	AnnotationValueFailed   = "failed"
+)
+
+// Archive is the final state of a composition.
+type Archive struct {
+	Name        string                  `json:"name"`
+	Namespace   string                  `json:"namespace"`
+	UID         types.UID               `json:"uid"`
+	Synthesizer string                  `json:"synthesizer"`
+	Archived    time.Time               `json:"archived"`
+	Status      apiv1.CompositionStatus `json:"status"`
+	Manifests   []string                `json:"manifests"`
+}
+
+// Sink persists archives somewhere that outlives the composition.
+// Implementations must be idempotent since the same archive may be written more than once.
+type Sink interface {
+	Write(ctx context.Context, archive *Archive) error
+}
+
+// New builds an archive from the composition and the resource slices of its current synthesis.
+// Resources that were removed from the synthesis (tombstones) are not included, and the values of Secrets are redacted.
+func New(ctx context.Context, reader client.Reader, comp *apiv1.Composition) (*Archive, error) {
+	a := &Archive{
+		Name:        comp.Name,
+		Namespace:   comp.Namespace,
+		UID:         comp.UID,
+		Synthesizer: comp.Spec.Synthesizer.Name,
+		Archived:    time.Now(),
+		Status:      *comp.Status.DeepCopy(),
+	}
+	a.Status.Simplified = nil
+	if comp.Status.CurrentSynthesis == nil {
+		return a, nil
+	}
+
+	for _, ref := range comp.Status.CurrentSynthesis.ResourceSlices {
+		slice := &apiv1.ResourceSlice{}
+		err := reader.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: comp.Namespace}, slice)
+		if errors.IsNotFound(err) {
+			continue // already cleaned up - archive what we can
+		}
+		if err != nil {
+			return nil, fmt.Errorf("getting resource slice %q: %w", ref.Name, err)
+		}
+		for _, res := range slice.Spec.Resources {
+			if !res.Deleted {
+				a.Manifests = append(a.Manifests, redact(res.Manifest))
+			}
+		}
+	}
+	return a, nil
+}
+
+// RedactedValue replaces the values of Secrets in archives, since sinks aren't necessarily as protected as Secrets.
+const RedactedValue = "<redacted>"
+
+// redact replaces the values of the given manifest if it's a Secret.
+// The keys are kept so the archive still shows the shape of the Secret.
+// Manifests that can't be redacted reliably are replaced entirely.
+func redact(manifest string) string {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON([]byte(manifest)); err != nil {
+		return RedactedValue // it could be a Secret
+	}
+	if gvk := obj.GroupVersionKind(); gvk.Group != "" || gvk.Kind != "Secret" {
+		return manifest
+	}
+	for _, field := range []string{"data", "stringData"} {
+		val, ok := obj.Object[field]
+		if !ok {
+			continue
+		}
+		values, ok := val.(map[string]any)
+		if !ok {
+			obj.Object[field] = RedactedValue
+			continue
+		}
+		for key := range values {
+			values[key] = RedactedValue
+		}
+	}
+	if anno := obj.GetAnnotations(); anno != nil {
+		delete(anno, "kubectl.kubernetes.io/last-applied-configuration")
+		obj.SetAnnotations(anno)
+	}
+	js, err := obj.MarshalJSON()
+	if err != nil {
+		return RedactedValue
+	}
+	return string(js)
+}
+
+// ParseSink returns the sink described by the given string:
+//   - configmap:<namespace> writes a ConfigMap per composition into the given namespace
+//   - http:// or https:// URLs receive each archive as a JSON POST request
+func ParseSink(cli client.Client, str string) (Sink, error) {
+	switch {
+	case strings.HasPrefix(str, "configmap:"):
+		ns := strings.TrimPrefix(str, "configmap:")
+		if ns == "" {
+			return nil, fmt.Errorf("configmap archive sink requires a namespace")
+		}
+		return NewConfigMapSink(cli, ns), nil
+	case strings.HasPrefix(str, "http://"), strings.HasPrefix(str, "https://"):
+		return NewHTTPSink(str, time.Minute), nil
+	default:
+		return nil, fmt.Errorf("unsupported archive sink %q", str)
+	}
+}
@@ -0,0 +1,152 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/testutil"
+)
+
+func TestNew(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = "default"
+	slice.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}`},
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"bar"}}`, Deleted: true},
+	}
+	require.NoError(t, cli.Create(ctx, slice))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.UID = "test-uid"
+	comp.Spec.Synthesizer.Name = "test-synth"
+	comp.Status.Simplified = &apiv1.SimplifiedStatus{Status: "Deleting"}
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{
+		UUID:           "test-uuid",
+		ResourceSlices: []*apiv1.ResourceSliceRef{{Name: "test-slice"}, {Name: "missing-slice"}},
+	}
+
+	a, err := New(ctx, cli, comp)
+	require.NoError(t, err)
+	assert.Equal(t, "test-comp", a.Name)
+	assert.Equal(t, "test-synth", a.Synthesizer)
+	assert.Equal(t, "test-uuid", a.Status.CurrentSynthesis.UUID)
+	assert.Nil(t, a.Status.Simplified)
+	assert.Equal(t, []string{`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}`}, a.Manifests)
+}
+
+func TestNewRedactsSecrets(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = "default"
+	slice.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "creds"}, "data": {"password": "aHVudGVyMg=="}, "stringData": {"token": "hunter2"}}`},
+		{Manifest: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "config"}, "data": {"foo": "bar"}}`},
+	}
+	require.NoError(t, cli.Create(ctx, slice))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{ResourceSlices: []*apiv1.ResourceSliceRef{{Name: "test-slice"}}}
+
+	a, err := New(ctx, cli, comp)
+	require.NoError(t, err)
+	require.Len(t, a.Manifests, 2)
+	assert.NotContains(t, a.Manifests[0], "aHVudGVyMg==")
+	assert.NotContains(t, a.Manifests[0], "hunter2")
+
+	secret := struct {
+		Data       map[string]string `json:"data"`
+		StringData map[string]string `json:"stringData"`
+	}{}
+	require.NoError(t, json.Unmarshal([]byte(a.Manifests[0]), &secret))
+	assert.Equal(t, RedactedValue, secret.Data["password"])
+	assert.Equal(t, RedactedValue, secret.StringData["token"])
+	assert.Contains(t, a.Manifests[1], `"foo": "bar"`)
+}
+
+func TestRedactFailsClosed(t *testing.T) {
+	// Unparseable manifests might be Secrets
+	assert.Equal(t, RedactedValue, redact(`{"apiVersion": "v1", "kind": "Secret", "data": {"password": "aHVudGVyMg=="`))
+
+	// Malformed data fields are replaced entirely
+	out := redact(`{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "creds"}, "data": "aHVudGVyMg=="}`)
+	secret := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(out), &secret))
+	assert.Equal(t, RedactedValue, secret["data"])
+}
+
+func TestConfigMapSink(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+	sink := NewConfigMapSink(cli, "archives")
+
+	a := &Archive{Name: "test-comp", Namespace: "default", UID: "test-uid", Manifests: []string{"foo"}}
+	require.NoError(t, sink.Write(ctx, a))
+
+	a.Manifests = []string{"bar"}
+	require.NoError(t, sink.Write(ctx, a)) // idempotent
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = "eno-archive-test-uid"
+	cm.Namespace = "archives"
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(cm), cm))
+	assert.Equal(t, "test-comp", cm.Annotations["eno.azure.io/composition-name"])
+
+	gz, err := gzip.NewReader(bytes.NewReader(cm.BinaryData[configMapDataKey]))
+	require.NoError(t, err)
+	out := &Archive{}
+	require.NoError(t, json.NewDecoder(gz).Decode(out))
+	assert.Equal(t, []string{"bar"}, out.Manifests)
+}
+
+func TestHTTPSink(t *testing.T) {
+	var received *Archive
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = &Archive{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(received))
+		if received.Name == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := testutil.NewContext(t)
+	sink, err := ParseSink(nil, srv.URL)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write(ctx, &Archive{Name: "test-comp"}))
+	assert.Equal(t, "test-comp", received.Name)
+
+	assert.Error(t, sink.Write(ctx, &Archive{Name: "fail"}))
+}
+
+func TestParseSink(t *testing.T) {
+	_, err := ParseSink(nil, "configmap:eno-archives")
+	assert.NoError(t, err)
+
+	_, err = ParseSink(nil, "configmap:")
+	assert.Error(t, err)
+
+	_, err = ParseSink(nil, "oci://registry/repo")
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const configMapDataKey = "archive.json.gz"
+
+type configMapSink struct {
+	client    client.Client
+	namespace string
+}
+
+// NewConfigMapSink writes each archive into a gzipped ConfigMap named after the composition's UID.
+// Archives larger than the ConfigMap size limit will fail to be written.
+func NewConfigMapSink(cli client.Client, namespace string) Sink {
+	return &configMapSink{client: cli, namespace: namespace}
+}
+
+func (c *configMapSink) Write(ctx context.Context, a *Archive) error {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	if err := json.NewEncoder(gz).Encode(a); err != nil {
+		return fmt.Errorf("encoding archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("compressing archive: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = fmt.Sprintf("eno-archive-%s", a.UID)
+	cm.Namespace = c.namespace
+	cm.Annotations = map[string]string{
+		"eno.azure.io/composition-name":      a.Name,
+		"eno.azure.io/composition-namespace": a.Namespace,
+	}
+	cm.BinaryData = map[string][]byte{configMapDataKey: buf.Bytes()}
+
+	err := c.client.Create(ctx, cm)
+	if errors.IsAlreadyExists(err) {
+		err = c.client.Update(ctx, cm)
+	}
+	if err != nil {
+		return fmt.Errorf("writing archive configmap: %w", err)
+	}
+	return nil
+}
+
+type httpSink struct {
+	client *http.Client
+	url    string
+}
+
+// NewHTTPSink POSTs each archive as JSON to the given URL.
+// This is useful for forwarding archives to blob storage or an artifact registry through a small relay service.
+func NewHTTPSink(url string, timeout time.Duration) Sink {
+	return &httpSink{client: &http.Client{Timeout: timeout}, url: url}
+}
+
+func (h *httpSink) Write(ctx context.Context, a *Archive) error {
+	body := &bytes.Buffer{}
+	if err := json.NewEncoder(body).Encode(a); err != nil {
+		return fmt.Errorf("encoding archive: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
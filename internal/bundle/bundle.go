@@ -0,0 +1,194 @@
+// Package bundle implements a file format for distributing synthesizers to disconnected environments.
+//
+// A bundle describes one version of a synthesizer: its image, input schema (refs), resource defaults, and
+// other spec fields, along with version metadata. Bundles are promoted between environments by copying
+// the bundle file alongside the image it references. The loader registers every bundle found in a
+// directory (typically a volume populated from a private registry or artifact store) as a Synthesizer.
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+const (
+	APIVersion = "eno.azure.io/v1"
+	Kind       = "SynthesizerBundle"
+
+	// VersionAnnotation holds the version of the bundle that a synthesizer was registered from.
+	VersionAnnotation = "eno.azure.io/bundle-version"
+
+	// NameLabel is set on synthesizers registered from bundles.
+	NameLabel = "eno.azure.io/bundle"
+)
+
+// Bundle is one version of a synthesizer.
+type Bundle struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// Name of the synthesizer registered from this bundle.
+	Name string `json:"name"`
+
+	// Version is an opaque version string. The synthesizer is only updated when it changes.
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+
+	// Created is informational, and can be used to trace bundles back to their build.
+	Created *time.Time `json:"created,omitempty"`
+
+	// Synthesizer is the spec of the registered synthesizer.
+	Synthesizer apiv1.SynthesizerSpec `json:"synthesizer"`
+}
+
+// Load reads a yaml or json encoded bundle from the given file.
+func Load(path string) (*Bundle, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{}
+	if err := yaml.UnmarshalStrict(raw, b); err != nil {
+		return nil, fmt.Errorf("parsing bundle: %w", err)
+	}
+	return b, b.validate()
+}
+
+func (b *Bundle) validate() error {
+	if b.APIVersion != APIVersion || b.Kind != Kind {
+		return fmt.Errorf("expected apiVersion %q and kind %q", APIVersion, Kind)
+	}
+	if b.Name == "" || b.Version == "" {
+		return fmt.Errorf("name and version are required")
+	}
+	if b.Synthesizer.Image == "" {
+		return fmt.Errorf("synthesizer.image is required")
+	}
+	return nil
+}
+
+// LoadDir reads every bundle file (*.yaml, *.yml, or *.json) in the given directory.
+// Bundles are returned in order of their file names.
+func LoadDir(dir string) ([]*Bundle, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var bundles []*Bundle
+	names := map[string]string{}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		b, err := Load(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("loading bundle %q: %w", entry.Name(), err)
+		}
+		if other, ok := names[b.Name]; ok {
+			return nil, fmt.Errorf("bundles %q and %q both define synthesizer %q", other, entry.Name(), b.Name)
+		}
+		names[b.Name] = entry.Name()
+		bundles = append(bundles, b)
+	}
+	sort.Slice(bundles, func(i, j int) bool { return names[bundles[i].Name] < names[bundles[j].Name] })
+	return bundles, nil
+}
+
+// Register creates or updates the synthesizer defined by the bundle.
+// Synthesizers that were already registered from the same bundle version are not modified.
+func Register(ctx context.Context, cli client.Client, b *Bundle) (bool, error) {
+	syn := &apiv1.Synthesizer{}
+	err := cli.Get(ctx, client.ObjectKey{Name: b.Name}, syn)
+	if errors.IsNotFound(err) {
+		syn.Name = b.Name
+		b.apply(syn)
+		return true, cli.Create(ctx, syn)
+	}
+	if err != nil {
+		return false, fmt.Errorf("getting synthesizer: %w", err)
+	}
+
+	if syn.Labels[NameLabel] != b.Name {
+		return false, fmt.Errorf("synthesizer %q exists but wasn't registered from a bundle", b.Name)
+	}
+	if syn.Annotations[VersionAnnotation] == b.Version {
+		return false, nil
+	}
+
+	b.apply(syn)
+	return true, cli.Update(ctx, syn)
+}
+
+func (b *Bundle) apply(syn *apiv1.Synthesizer) {
+	if syn.Labels == nil {
+		syn.Labels = map[string]string{}
+	}
+	syn.Labels[NameLabel] = b.Name
+	if syn.Annotations == nil {
+		syn.Annotations = map[string]string{}
+	}
+	syn.Annotations[VersionAnnotation] = b.Version
+	syn.Spec = *b.Synthesizer.DeepCopy()
+}
+
+// Loader periodically registers the bundles found in a directory.
+type Loader struct {
+	client   client.Client
+	dir      string
+	interval time.Duration
+}
+
+// NewLoader adds a bundle loader to the manager.
+func NewLoader(mgr manager.Manager, dir string, interval time.Duration) error {
+	return mgr.Add(&Loader{client: mgr.GetClient(), dir: dir, interval: interval})
+}
+
+func (l *Loader) Start(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("controller", "bundleLoader", "dir", l.dir)
+	ctx = logr.NewContext(ctx, logger)
+
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		if err := l.load(ctx); err != nil {
+			logger.Error(err, "loading synthesizer bundles")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *Loader) load(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	bundles, err := LoadDir(l.dir)
+	if err != nil {
+		return err
+	}
+	for _, b := range bundles {
+		changed, err := Register(ctx, l.client, b)
+		if err != nil {
+			return fmt.Errorf("registering bundle %q: %w", b.Name, err)
+		}
+		if changed {
+			logger.V(0).Info("registered synthesizer from bundle", "synthesizerName", b.Name, "bundleVersion", b.Version)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package bundle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/testutil"
+)
+
+const testBundle = `
+apiVersion: eno.azure.io/v1
+kind: SynthesizerBundle
+name: test-synth
+version: "1.2.3"
+description: test bundle
+synthesizer:
+  image: registry.example.com/synth:v1.2.3
+  refs:
+    - key: config
+      resource:
+        version: v1
+        kind: ConfigMap
+  resourceDefaults:
+    - group: apps
+      kind: Deployment
+      readinessGroup: 1
+`
+
+func writeFile(t *testing.T, dir, name, content string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "b.yaml", testBundle)
+	writeFile(t, dir, "a.json", `{"apiVersion": "eno.azure.io/v1", "kind": "SynthesizerBundle", "name": "another", "version": "1", "synthesizer": {"image": "foo"}}`)
+	writeFile(t, dir, "README.md", "not a bundle")
+
+	bundles, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, bundles, 2)
+	assert.Equal(t, "another", bundles[0].Name)
+	assert.Equal(t, "test-synth", bundles[1].Name)
+	assert.Equal(t, "1.2.3", bundles[1].Version)
+	assert.Equal(t, "registry.example.com/synth:v1.2.3", bundles[1].Synthesizer.Image)
+	assert.Equal(t, "config", bundles[1].Synthesizer.Refs[0].Key)
+	assert.Equal(t, 1, *bundles[1].Synthesizer.ResourceDefaults[0].ReadinessGroup)
+
+	// Duplicate names
+	writeFile(t, dir, "c.yaml", testBundle)
+	_, err = LoadDir(dir)
+	assert.Error(t, err)
+}
+
+func TestLoadErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"wrong kind":      "apiVersion: eno.azure.io/v1\nkind: Synthesizer\nname: foo\nversion: \"1\"\nsynthesizer: {image: foo}",
+		"missing version": "apiVersion: eno.azure.io/v1\nkind: SynthesizerBundle\nname: foo\nsynthesizer: {image: foo}",
+		"missing image":   "apiVersion: eno.azure.io/v1\nkind: SynthesizerBundle\nname: foo\nversion: \"1\"",
+		"unknown field":   "apiVersion: eno.azure.io/v1\nkind: SynthesizerBundle\nname: foo\nversion: \"1\"\nsynthesizer: {image: foo, foo: bar}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "bundle.yaml", content)
+			_, err := Load(filepath.Join(dir, "bundle.yaml"))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	dir := t.TempDir()
+	writeFile(t, dir, "bundle.yaml", testBundle)
+	b, err := Load(filepath.Join(dir, "bundle.yaml"))
+	require.NoError(t, err)
+
+	// Create
+	changed, err := Register(ctx, cli, b)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	syn := &apiv1.Synthesizer{}
+	require.NoError(t, cli.Get(ctx, client.ObjectKey{Name: b.Name}, syn))
+	assert.Equal(t, "1.2.3", syn.Annotations[VersionAnnotation])
+	assert.Equal(t, b.Name, syn.Labels[NameLabel])
+	assert.Equal(t, b.Synthesizer.Image, syn.Spec.Image)
+
+	// Same version is a no-op
+	changed, err = Register(ctx, cli, b)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// New version
+	b.Version = "1.2.4"
+	b.Synthesizer.Image = "registry.example.com/synth:v1.2.4"
+	changed, err = Register(ctx, cli, b)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKey{Name: b.Name}, syn))
+	assert.Equal(t, "1.2.4", syn.Annotations[VersionAnnotation])
+	assert.Equal(t, b.Synthesizer.Image, syn.Spec.Image)
+
+	// Synthesizers not registered from bundles aren't modified
+	other := &apiv1.Synthesizer{}
+	other.Name = "other"
+	require.NoError(t, cli.Create(ctx, other))
+	b.Name = other.Name
+	_, err = Register(ctx, cli, b)
+	assert.Error(t, err)
+}
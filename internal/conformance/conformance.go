@@ -0,0 +1,244 @@
+// Package conformance verifies that synthesizers comply with the contract expected by Eno,
+// by running them several times against variations of the same inputs.
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/execution"
+	"github.com/Azure/eno/internal/readiness"
+	"github.com/Azure/eno/internal/resource"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+)
+
+// DefaultMaxOutputBytes is the default upper bound on the total size of a synthesizer's output.
+const DefaultMaxOutputBytes = 1024 * 1024 * 10
+
+// Options configures a verification run.
+type Options struct {
+	Synthesizer *apiv1.Synthesizer
+	Handler     execution.SynthesizerHandle
+
+	// Inputs are bound to the synthesizer's refs by their eno.azure.io/input-key annotation.
+	Inputs []*unstructured.Unstructured
+
+	MaxOutputBytes int
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Check   string
+	Passed  bool
+	Message string
+}
+
+// Verify runs the synthesizer against a suite of inputs generated from the given inputs and returns the result of every check.
+// Errors are only returned when the inputs themselves are invalid.
+func Verify(ctx context.Context, opts *Options) ([]*Result, error) {
+	if opts.MaxOutputBytes == 0 {
+		opts.MaxOutputBytes = DefaultMaxOutputBytes
+	}
+	inputs, err := bindInputs(opts.Synthesizer, opts.Inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline, err := run(ctx, opts, inputs)
+	results := []*Result{newResult("Succeeds", err)}
+	if err != nil {
+		return results, nil // nothing else can be checked
+	}
+
+	results = append(results,
+		newResult("ValidManifests", validateManifests(ctx, baseline)),
+		newResult("UniqueIdentities", checkUniqueIdentities(baseline)),
+		newResult("BoundedOutput", checkOutputSize(baseline, opts.MaxOutputBytes)),
+	)
+
+	repeated, err := run(ctx, opts, inputs)
+	if err == nil {
+		err = compareOutputs(baseline, repeated)
+	}
+	results = append(results, newResult("Deterministic", err))
+
+	reordered := slices.Clone(inputs)
+	slices.Reverse(reordered)
+	output, err := run(ctx, opts, reordered)
+	if err == nil {
+		err = compareOutputs(baseline, output)
+	}
+	results = append(results, newResult("OrderIndependent", err))
+
+	output, err = run(ctx, opts, withMetadataNoise(inputs))
+	if err == nil {
+		err = compareIdentities(baseline, output)
+	}
+	results = append(results, newResult("StableIdentities", err))
+
+	return results, nil
+}
+
+func newResult(check string, err error) *Result {
+	if err != nil {
+		return &Result{Check: check, Message: err.Error()}
+	}
+	return &Result{Check: check, Passed: true}
+}
+
+func bindInputs(synth *apiv1.Synthesizer, inputs []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	byKey := map[string]*unstructured.Unstructured{}
+	for _, input := range inputs {
+		key := input.GetAnnotations()["eno.azure.io/input-key"]
+		if key == "" {
+			return nil, fmt.Errorf("input %q is missing the eno.azure.io/input-key annotation", input.GetName())
+		}
+		byKey[key] = input
+	}
+
+	bound := make([]*unstructured.Unstructured, len(synth.Spec.Refs))
+	for i, ref := range synth.Spec.Refs {
+		input, ok := byKey[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("input %q is referenced by the synthesizer, but not provided", ref.Key)
+		}
+		bound[i] = input
+	}
+	return bound, nil
+}
+
+func run(ctx context.Context, opts *Options, inputs []*unstructured.Unstructured) (*krmv1.ResourceList, error) {
+	rl := &krmv1.ResourceList{
+		Kind:       krmv1.ResourceListKind,
+		APIVersion: krmv1.SchemeGroupVersion.String(),
+	}
+	for _, input := range inputs {
+		rl.Items = append(rl.Items, input.DeepCopy())
+	}
+
+	output, err := opts.Handler(ctx, opts.Synthesizer, rl)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range output.Results {
+		if result.Severity == krmv1.ResultSeverityError {
+			return nil, fmt.Errorf("synthesizer returned an error result: %s", result.Message)
+		}
+	}
+	return output, nil
+}
+
+// withMetadataNoise returns copies of the inputs with server-populated metadata changed,
+// which should never affect the identity of synthesized resources.
+func withMetadataNoise(inputs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	out := make([]*unstructured.Unstructured, len(inputs))
+	for i, input := range inputs {
+		input = input.DeepCopy()
+		input.SetResourceVersion(fmt.Sprintf("%d", 1000+i))
+		input.SetUID(types.UID(fmt.Sprintf("conformance-%d", i)))
+		input.SetGeneration(input.GetGeneration() + 1)
+		input.SetManagedFields(nil)
+		out[i] = input
+	}
+	return out
+}
+
+func validateManifests(ctx context.Context, rl *krmv1.ResourceList) error {
+	renv, err := readiness.NewEnv()
+	if err != nil {
+		return err
+	}
+	comp := &apiv1.Composition{}
+	rss, err := resource.Slice(comp, nil, rl.Items, DefaultMaxOutputBytes)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, slice := range rss {
+		for i := range slice.Spec.Resources {
+			if _, err := resource.NewResource(ctx, renv, slice, i); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid manifests: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func identity(obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	return fmt.Sprintf("%s/%s/%s/%s", gvk.Group, gvk.Kind, obj.GetNamespace(), obj.GetName())
+}
+
+func checkUniqueIdentities(rl *krmv1.ResourceList) error {
+	seen := map[string]struct{}{}
+	for _, item := range rl.Items {
+		id := identity(item)
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("resource %s was returned more than once", id)
+		}
+		seen[id] = struct{}{}
+	}
+	return nil
+}
+
+func checkOutputSize(rl *krmv1.ResourceList, max int) error {
+	var n int
+	for _, item := range rl.Items {
+		js, err := item.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		n += len(js)
+	}
+	if n > max {
+		return fmt.Errorf("output is %d bytes, which exceeds the limit of %d bytes", n, max)
+	}
+	return nil
+}
+
+func compareOutputs(a, b *krmv1.ResourceList) error {
+	if len(a.Items) != len(b.Items) {
+		return fmt.Errorf("returned %d resources, then %d resources", len(a.Items), len(b.Items))
+	}
+	for i := range a.Items {
+		ajs, err := json.Marshal(a.Items[i])
+		if err != nil {
+			return err
+		}
+		bjs, err := json.Marshal(b.Items[i])
+		if err != nil {
+			return err
+		}
+		if string(ajs) != string(bjs) {
+			return fmt.Errorf("resource %d (%s) differs between runs", i, identity(a.Items[i]))
+		}
+	}
+	return nil
+}
+
+func compareIdentities(a, b *krmv1.ResourceList) error {
+	ids := func(rl *krmv1.ResourceList) []string {
+		out := make([]string, len(rl.Items))
+		for i, item := range rl.Items {
+			out[i] = identity(item)
+		}
+		slices.Sort(out)
+		return out
+	}
+	aIDs, bIDs := ids(a), ids(b)
+	if !slices.Equal(aIDs, bIDs) {
+		return fmt.Errorf("resource identities changed when only input metadata changed: %v -> %v", aIDs, bIDs)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/testutil"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+)
+
+func newConfigMap(name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": name, "namespace": "default"},
+	}}
+}
+
+func newInput(key string) *unstructured.Unstructured {
+	input := newConfigMap("input-" + key)
+	input.SetAnnotations(map[string]string{"eno.azure.io/input-key": key})
+	return input
+}
+
+func TestVerify(t *testing.T) {
+	synth := &apiv1.Synthesizer{}
+	synth.Spec.Refs = []apiv1.Ref{{Key: "foo"}, {Key: "bar"}}
+	inputs := []*unstructured.Unstructured{newInput("foo"), newInput("bar")}
+
+	var calls int
+	tests := []struct {
+		Name    string
+		Handler func(*krmv1.ResourceList) *krmv1.ResourceList
+		Failed  []string
+	}{
+		{
+			Name: "compliant",
+			Handler: func(rl *krmv1.ResourceList) *krmv1.ResourceList {
+				return &krmv1.ResourceList{Items: []*unstructured.Unstructured{newConfigMap("output")}}
+			},
+		},
+		{
+			Name: "nondeterministic",
+			Handler: func(rl *krmv1.ResourceList) *krmv1.ResourceList {
+				calls++
+				cm := newConfigMap("output")
+				cm.SetLabels(map[string]string{"call": string(rune('a' + calls))})
+				return &krmv1.ResourceList{Items: []*unstructured.Unstructured{cm}}
+			},
+			Failed: []string{"Deterministic", "OrderIndependent"},
+		},
+		{
+			Name: "order dependent",
+			Handler: func(rl *krmv1.ResourceList) *krmv1.ResourceList {
+				return &krmv1.ResourceList{Items: []*unstructured.Unstructured{newConfigMap("output-" + rl.Items[0].GetName())}}
+			},
+			Failed: []string{"OrderIndependent"},
+		},
+		{
+			Name: "unstable identity",
+			Handler: func(rl *krmv1.ResourceList) *krmv1.ResourceList {
+				return &krmv1.ResourceList{Items: []*unstructured.Unstructured{newConfigMap("output-" + rl.Items[0].GetResourceVersion())}}
+			},
+			Failed: []string{"StableIdentities"},
+		},
+		{
+			Name: "duplicates and invalid manifests",
+			Handler: func(rl *krmv1.ResourceList) *krmv1.ResourceList {
+				return &krmv1.ResourceList{Items: []*unstructured.Unstructured{newConfigMap("output"), newConfigMap("output"), newConfigMap("")}}
+			},
+			Failed: []string{"ValidManifests", "UniqueIdentities"},
+		},
+		{
+			Name: "error result",
+			Handler: func(rl *krmv1.ResourceList) *krmv1.ResourceList {
+				return &krmv1.ResourceList{Results: []*krmv1.Result{{Message: "boom", Severity: krmv1.ResultSeverityError}}}
+			},
+			Failed: []string{"Succeeds"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			results, err := Verify(testutil.NewContext(t), &Options{
+				Synthesizer: synth,
+				Inputs:      inputs,
+				Handler: func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+					return tc.Handler(rl), nil
+				},
+			})
+			require.NoError(t, err)
+
+			failed := []string{}
+			for _, result := range results {
+				if !result.Passed {
+					failed = append(failed, result.Check)
+				}
+			}
+			if tc.Failed == nil {
+				tc.Failed = []string{}
+			}
+			assert.ElementsMatch(t, tc.Failed, failed)
+		})
+	}
+}
+
+func TestVerifyMissingInput(t *testing.T) {
+	synth := &apiv1.Synthesizer{}
+	synth.Spec.Refs = []apiv1.Ref{{Key: "foo"}}
+
+	_, err := Verify(testutil.NewContext(t), &Options{Synthesizer: synth})
+	assert.ErrorContains(t, err, `input "foo" is referenced`)
+}
+
+func TestCheckOutputSize(t *testing.T) {
+	rl := &krmv1.ResourceList{Items: []*unstructured.Unstructured{newConfigMap("output")}}
+	assert.NoError(t, checkOutputSize(rl, 1024))
+	assert.Error(t, checkOutputSize(rl, 10))
+}
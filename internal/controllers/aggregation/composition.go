@@ -8,6 +8,7 @@ import (
 	"github.com/Azure/eno/internal/manager"
 	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -21,9 +22,9 @@ func NewCompositionController(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&apiv1.Composition{}).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "compositionAggregationController")).
-		Complete(&compositionController{
+		Complete(manager.WithController("compositionAggregationController", &compositionController{
 			client: mgr.GetClient(),
-		})
+		}))
 }
 
 func (c *compositionController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -40,11 +41,13 @@ func (c *compositionController) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	next := c.aggregate(synth, comp)
-	if equality.Semantic.DeepEqual(next, comp.Status.Simplified) {
+	health := aggregateHealth(comp)
+	if equality.Semantic.DeepEqual(next, comp.Status.Simplified) && health == comp.Status.Health {
 		return ctrl.Result{}, nil
 	}
 	copy := comp.DeepCopy()
 	copy.Status.Simplified = next
+	copy.Status.Health = health
 	if err := c.client.Status().Patch(ctx, copy, client.MergeFrom(comp)); err != nil {
 		return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
 	}
@@ -58,6 +61,10 @@ func (c *compositionController) aggregate(synth *apiv1.Synthesizer, comp *apiv1.
 		copy = &apiv1.SimplifiedStatus{}
 	}
 
+	if comp.DeletionBlocked() {
+		copy.Status = "AwaitingDeletionConfirmation"
+		return copy
+	}
 	if comp.DeletionTimestamp != nil {
 		copy.Status = "Deleting"
 		return copy
@@ -68,6 +75,9 @@ func (c *compositionController) aggregate(synth *apiv1.Synthesizer, comp *apiv1.
 	if !comp.InputsExist(synth) {
 		copy.Status = "MissingInputs"
 	}
+	if comp.PinMismatched(synth) {
+		copy.Status = "MismatchedPin"
+	}
 	if comp.Status.CurrentSynthesis == nil {
 		return copy
 	}
@@ -114,6 +124,24 @@ func (c *compositionController) aggregate(synth *apiv1.Synthesizer, comp *apiv1.
 	if comp.InputsOutOfLockstep(synth) {
 		copy.Status = "MismatchedInputs"
 	}
+	if comp.PinMismatched(synth) {
+		copy.Status = "MismatchedPin"
+	}
 
 	return copy
 }
+
+// aggregateHealth summarizes the composition's synthesis and resource status into a coarse health state.
+func aggregateHealth(comp *apiv1.Composition) apiv1.CompositionHealth {
+	syn := comp.Status.CurrentSynthesis
+	if syn == nil || (syn.Synthesized == nil && !syn.Failed()) {
+		return apiv1.HealthUnknown
+	}
+	if syn.Failed() || meta.IsStatusConditionTrue(comp.Status.Conditions, apiv1.DegradedCondition) {
+		return apiv1.HealthDegraded
+	}
+	if syn.Ready == nil || comp.DeletionTimestamp != nil || comp.Status.PendingResynthesis != nil {
+		return apiv1.HealthProgressing
+	}
+	return apiv1.HealthHealthy
+}
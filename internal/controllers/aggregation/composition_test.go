@@ -231,3 +231,74 @@ func TestCompositionSimplificationI(t *testing.T) {
 		return comp.Status.Simplified != nil && comp.Status.Simplified.Status != ""
 	})
 }
+
+func TestCompositionHealth(t *testing.T) {
+	degraded := []metav1.Condition{{Type: apiv1.DegradedCondition, Status: metav1.ConditionTrue}}
+
+	tests := []struct {
+		Name     string
+		Input    apiv1.CompositionStatus
+		Deleting bool
+		Expected apiv1.CompositionHealth
+	}{
+		{
+			Name:     "no synthesis",
+			Expected: apiv1.HealthUnknown,
+		},
+		{
+			Name:     "synthesizing",
+			Input:    apiv1.CompositionStatus{CurrentSynthesis: &apiv1.Synthesis{UUID: "uuid"}},
+			Expected: apiv1.HealthUnknown,
+		},
+		{
+			Name: "synthesis failed",
+			Input: apiv1.CompositionStatus{CurrentSynthesis: &apiv1.Synthesis{
+				UUID:    "uuid",
+				Results: []apiv1.Result{{Message: "foo", Severity: "error"}},
+			}},
+			Expected: apiv1.HealthDegraded,
+		},
+		{
+			Name:     "reconciling",
+			Input:    apiv1.CompositionStatus{CurrentSynthesis: &apiv1.Synthesis{UUID: "uuid", Synthesized: ptr.To(metav1.Now())}},
+			Expected: apiv1.HealthProgressing,
+		},
+		{
+			Name:     "ready",
+			Input:    apiv1.CompositionStatus{CurrentSynthesis: &apiv1.Synthesis{UUID: "uuid", Synthesized: ptr.To(metav1.Now()), Ready: ptr.To(metav1.Now())}},
+			Expected: apiv1.HealthHealthy,
+		},
+		{
+			Name: "regressed",
+			Input: apiv1.CompositionStatus{
+				CurrentSynthesis: &apiv1.Synthesis{UUID: "uuid", Synthesized: ptr.To(metav1.Now()), Ready: ptr.To(metav1.Now())},
+				Conditions:       degraded,
+			},
+			Expected: apiv1.HealthDegraded,
+		},
+		{
+			Name:     "deleting",
+			Input:    apiv1.CompositionStatus{CurrentSynthesis: &apiv1.Synthesis{UUID: "uuid", Synthesized: ptr.To(metav1.Now()), Ready: ptr.To(metav1.Now())}},
+			Deleting: true,
+			Expected: apiv1.HealthProgressing,
+		},
+		{
+			Name: "pending resynthesis",
+			Input: apiv1.CompositionStatus{
+				CurrentSynthesis:   &apiv1.Synthesis{UUID: "uuid", Synthesized: ptr.To(metav1.Now()), Ready: ptr.To(metav1.Now())},
+				PendingResynthesis: ptr.To(metav1.Now()),
+			},
+			Expected: apiv1.HealthProgressing,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			comp := &apiv1.Composition{Status: tc.Input}
+			if tc.Deleting {
+				comp.DeletionTimestamp = ptr.To(metav1.Now())
+			}
+			assert.Equal(t, tc.Expected, aggregateHealth(comp))
+		})
+	}
+}
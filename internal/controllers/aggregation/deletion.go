@@ -0,0 +1,144 @@
+package aggregation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/manager"
+	"github.com/go-logr/logr"
+)
+
+// maxBlockingKinds bounds the number of kinds listed in a composition's deletion progress.
+const maxBlockingKinds = 10
+
+// deletionController reports the progress of composition deletion in the composition's status.
+type deletionController struct {
+	client    client.Client
+	noCache   client.Reader
+	recorder  record.EventRecorder
+	timeNowFn func() time.Time
+}
+
+func NewDeletionController(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("deletionProgressController").
+		For(&apiv1.Composition{}).
+		Owns(&apiv1.ResourceSlice{}).
+		WithLogConstructor(manager.NewLogConstructor(mgr, "deletionProgressController")).
+		Complete(manager.WithController("deletionProgressController", &deletionController{
+			client:    mgr.GetClient(),
+			noCache:   mgr.GetAPIReader(),
+			recorder:  mgr.GetEventRecorderFor("eno-controller"),
+			timeNowFn: time.Now,
+		}))
+}
+
+func (d *deletionController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	comp := &apiv1.Composition{}
+	err := d.client.Get(ctx, req.NamespacedName, comp)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("getting composition: %w", err))
+	}
+	if comp.DeletionTimestamp == nil || comp.DeletionBlocked() || comp.Status.CurrentSynthesis == nil {
+		return ctrl.Result{}, nil
+	}
+	logger = logger.WithValues("compositionName", comp.Name, "compositionNamespace", comp.Namespace, "synthesisID", comp.Status.GetCurrentSynthesisUUID())
+
+	// The informer cache doesn't hold resource manifests, so we read slices from the apiserver to find the kinds of remaining resources.
+	// This is only done for compositions that are being deleted, so it doesn't add meaningful load.
+	items := []*apiv1.ResourceSlice{}
+	for _, ref := range comp.Status.CurrentSynthesis.ResourceSlices {
+		slice := &apiv1.ResourceSlice{}
+		err := d.noCache.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: comp.Namespace}, slice)
+		if errors.IsNotFound(err) {
+			continue // already cleaned up
+		}
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("getting resource slice: %w", err)
+		}
+		items = append(items, slice)
+	}
+
+	next := buildDeletionProgress(comp, items, d.timeNowFn())
+	if prev := comp.Status.DeletionProgress; prev != nil && prev.RemainingResources == next.RemainingResources && equality.Semantic.DeepEqual(prev.BlockingKinds, next.BlockingKinds) {
+		return ctrl.Result{}, nil // the estimate is only updated when progress is made to avoid needless writes
+	}
+
+	copy := comp.DeepCopy()
+	copy.Status.DeletionProgress = next
+	if err := d.client.Status().Patch(ctx, copy, client.MergeFrom(comp)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("updating deletion progress: %w", err)
+	}
+	d.recorder.Eventf(comp, corev1.EventTypeNormal, "DeletionProgress", "%d resources remaining: %s", next.RemainingResources, strings.Join(next.BlockingKinds, ", "))
+	logger.V(1).Info("updated deletion progress", "remainingResources", next.RemainingResources)
+
+	return ctrl.Result{}, nil
+}
+
+// buildDeletionProgress summarizes the resources that have not yet been deleted from the given slices.
+func buildDeletionProgress(comp *apiv1.Composition, items []*apiv1.ResourceSlice, now time.Time) *apiv1.DeletionProgress {
+	progress := &apiv1.DeletionProgress{}
+	if comp.ShouldOrphan() {
+		return progress
+	}
+
+	var total int
+	kinds := map[string]struct{}{}
+	for _, slice := range items {
+		for i, res := range slice.Spec.Resources {
+			deleted := len(slice.Status.Resources) > i && slice.Status.Resources[i].Deleted
+			if res.Deleted && deleted {
+				continue // removed before the composition was deleted
+			}
+			total++
+			if deleted {
+				continue
+			}
+			progress.RemainingResources++
+			if gk := parseGroupKind(res.Manifest); gk.Kind != "" {
+				kinds[gk.String()] = struct{}{}
+			}
+		}
+	}
+
+	for kind := range kinds {
+		progress.BlockingKinds = append(progress.BlockingKinds, kind)
+	}
+	slices.Sort(progress.BlockingKinds)
+	if len(progress.BlockingKinds) > maxBlockingKinds {
+		progress.BlockingKinds = progress.BlockingKinds[:maxBlockingKinds]
+	}
+
+	// Extrapolate from the average rate of deletion since the composition was deleted
+	deleted := total - progress.RemainingResources
+	if deleted > 0 && progress.RemainingResources > 0 && comp.DeletionTimestamp != nil {
+		elapsed := now.Sub(comp.DeletionTimestamp.Time)
+		progress.EstimatedCompletion = &metav1.Time{Time: now.Add(elapsed / time.Duration(deleted) * time.Duration(progress.RemainingResources))}
+	}
+
+	return progress
+}
+
+func parseGroupKind(manifest string) schema.GroupKind {
+	meta := metav1.TypeMeta{}
+	if err := json.Unmarshal([]byte(manifest), &meta); err != nil {
+		return schema.GroupKind{}
+	}
+	return meta.GroupVersionKind().GroupKind()
+}
@@ -0,0 +1,56 @@
+package aggregation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+func TestBuildDeletionProgress(t *testing.T) {
+	now := time.Now()
+	comp := &apiv1.Composition{}
+	comp.DeletionTimestamp = &metav1.Time{Time: now.Add(-time.Minute)}
+
+	slice := &apiv1.ResourceSlice{
+		Spec: apiv1.ResourceSliceSpec{
+			Resources: []apiv1.Manifest{
+				{Manifest: `{"apiVersion":"v1","kind":"ConfigMap"}`},
+				{Manifest: `{"apiVersion":"apps/v1","kind":"Deployment"}`},
+				{Manifest: `{"apiVersion":"apps/v1","kind":"Deployment"}`},
+				{Manifest: `{"apiVersion":"v1","kind":"Secret"}`, Deleted: true},
+				{Manifest: `{"apiVersion":"v1","kind":"Service"}`},
+			},
+		},
+		Status: apiv1.ResourceSliceStatus{
+			Resources: []apiv1.ResourceState{
+				{Reconciled: true, Deleted: true},
+				{Reconciled: true},
+				{Reconciled: true},
+				{Reconciled: true, Deleted: true}, // tombstone - not counted
+			},
+		},
+	}
+
+	progress := buildDeletionProgress(comp, []*apiv1.ResourceSlice{slice}, now)
+	assert.Equal(t, 3, progress.RemainingResources)
+	assert.Equal(t, []string{"Deployment.apps", "Service"}, progress.BlockingKinds)
+	if assert.NotNil(t, progress.EstimatedCompletion) {
+		assert.Equal(t, now.Add(time.Minute*3), progress.EstimatedCompletion.Time)
+	}
+
+	// No estimate without any progress
+	slice.Status.Resources[0].Deleted = false
+	progress = buildDeletionProgress(comp, []*apiv1.ResourceSlice{slice}, now)
+	assert.Equal(t, 4, progress.RemainingResources)
+	assert.Nil(t, progress.EstimatedCompletion)
+
+	// Orphaned resources don't block deletion
+	comp.Annotations = map[string]string{"eno.azure.io/deletion-strategy": "orphan"}
+	progress = buildDeletionProgress(comp, []*apiv1.ResourceSlice{slice}, now)
+	assert.Equal(t, 0, progress.RemainingResources)
+	assert.Nil(t, progress.BlockingKinds)
+}
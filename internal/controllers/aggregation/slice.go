@@ -3,9 +3,18 @@ package aggregation
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -14,18 +23,32 @@ import (
 	"github.com/go-logr/logr"
 )
 
+// maxReadinessMessages bounds the number of not-ready resources described in the composition's status.
+const maxReadinessMessages = 5
+
 type sliceController struct {
 	client client.Client
+
+	// minUpdateInterval is the min period between status updates of any one composition.
+	// Changes that happen within the interval are batched into a single update once it has passed.
+	minUpdateInterval time.Duration
+	mut               sync.Mutex
+	lastUpdate        map[types.NamespacedName]time.Time
+	lastPrune         time.Time
 }
 
-func NewSliceController(mgr ctrl.Manager) error {
+// NewSliceController aggregates the status of resource slices into their compositions.
+// Status updates are debounced such that each composition is written at most once per minUpdateInterval (zero disables batching).
+func NewSliceController(mgr ctrl.Manager, minUpdateInterval time.Duration) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&apiv1.Composition{}).
 		Owns(&apiv1.ResourceSlice{}).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "sliceAggregationController")).
-		Complete(&sliceController{
-			client: mgr.GetClient(),
-		})
+		Complete(manager.WithController("sliceAggregationController", &sliceController{
+			client:            mgr.GetClient(),
+			minUpdateInterval: minUpdateInterval,
+			lastUpdate:        map[types.NamespacedName]time.Time{},
+		}))
 }
 
 func (s *sliceController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -42,13 +65,16 @@ func (s *sliceController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		"compositionNamespace", comp.Namespace,
 		"synthesisID", comp.Status.GetCurrentSynthesisUUID())
 
-	if compositionStatusTerminal(comp) {
+	if comp.Status.CurrentSynthesis == nil || comp.Status.CurrentSynthesis.Synthesized == nil {
 		return ctrl.Result{}, nil
 	}
 
 	var maxReadyTime *metav1.Time
+	var readinessMsgs, degradedMsgs, blockedMsgs []string
+	var exports map[string]string
 	ready := true
 	reconciled := true
+	progress := &apiv1.SynthesisProgress{}
 	for _, ref := range comp.Status.CurrentSynthesis.ResourceSlices {
 		slice := &apiv1.ResourceSlice{}
 		slice.Name = ref.Name
@@ -64,10 +90,11 @@ func (s *sliceController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		}
 
 		// Status might be lagging behind
+		progress.Total += len(slice.Spec.Resources)
 		if len(slice.Status.Resources) == 0 && len(slice.Spec.Resources) > 0 {
 			ready = false
 			reconciled = false
-			break
+			continue
 		}
 
 		for _, state := range slice.Status.Resources {
@@ -76,19 +103,76 @@ func (s *sliceController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			// One more special case: it's also been reconciled when it still exists but the composition is deleting and is configured to orphan resources.
 			if resourceNotReconciled(comp, &state) {
 				reconciled = false
+			} else {
+				progress.Reconciled++
 			}
 
 			// Readiness
 			if state.Ready == nil {
 				ready = false
+				if state.Message != "" && len(readinessMsgs) < maxReadinessMessages {
+					readinessMsgs = append(readinessMsgs, state.Message)
+				}
+			} else {
+				progress.Ready++
 			}
 			if state.Ready != nil && (maxReadyTime == nil || maxReadyTime.Before(state.Ready)) {
 				maxReadyTime = state.Ready
 			}
+			if state.Degraded && len(degradedMsgs) < maxReadinessMessages {
+				degradedMsgs = append(degradedMsgs, state.Message)
+			}
+
+			// The first resource to export a given name wins
+			for name, val := range state.Exports {
+				if exports == nil {
+					exports = map[string]string{}
+				}
+				if _, ok := exports[name]; !ok {
+					exports[name] = val
+				}
+			}
 		}
+
+		for i, state := range slice.Status.Resources {
+			if state.Blocked && i < len(slice.Spec.Resources) && len(blockedMsgs) < maxReadinessMessages {
+				blockedMsgs = append(blockedMsgs, blockedResourceName(&slice.Spec.Resources[i]))
+			}
+		}
+	}
+
+	if ready {
+		readinessMsgs = nil
+	}
+	progress.Percent = 100
+	if progress.Total > 0 {
+		progress.Percent = progress.Ready * 100 / progress.Total
 	}
+	progressChanged := !equality.Semantic.DeepEqual(comp.Status.CurrentSynthesis.Progress, progress)
+	comp.Status.CurrentSynthesis.Progress = progress
 
-	if compositionStatusInSync(comp, reconciled, ready) {
+	// Exports are only published once the synthesis is ready, so consumers never see a mix of old and new values
+	exportsChanged := ready && !maps.Equal(comp.Status.Exports, exports)
+	if exportsChanged {
+		comp.Status.Exports = exports
+	}
+
+	degradedChanged := setDegradedCondition(comp, degradedMsgs)
+	blockedChanged := setDeletionBlockedCondition(comp, blockedMsgs)
+	if compositionStatusInSync(comp, reconciled, ready, readinessMsgs) {
+		if !degradedChanged && !blockedChanged && !progressChanged && !exportsChanged {
+			return ctrl.Result{}, nil
+		}
+		if wait := s.throttle(req.NamespacedName); wait > 0 {
+			logger.V(1).Info("deferring composition status update", "wait", wait.Milliseconds())
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+		err = s.client.Status().Update(ctx, comp)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("updating composition '%s' status: %w", comp.Name, err)
+		}
+		s.recordUpdate(req.NamespacedName)
+		logger.V(1).Info("updated composition conditions and progress", "compositionName", comp.Name, "degraded", len(degradedMsgs) > 0, "deletionBlocked", len(blockedMsgs) > 0, "percent", progress.Percent)
 		return ctrl.Result{}, nil
 	}
 
@@ -97,6 +181,11 @@ func (s *sliceController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		maxReadyTime = comp.Status.CurrentSynthesis.Reconciled
 	}
 
+	if wait := s.throttle(req.NamespacedName); wait > 0 {
+		logger.V(1).Info("deferring composition status update", "wait", wait.Milliseconds())
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
 	now := metav1.Now()
 	if ready && maxReadyTime != nil {
 		comp.Status.CurrentSynthesis.Ready = maxReadyTime
@@ -110,8 +199,9 @@ func (s *sliceController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	} else {
 		comp.Status.CurrentSynthesis.Ready = nil
 	}
+	comp.Status.CurrentSynthesis.ReadinessMessages = readinessMsgs
 
-	if reconciled {
+	if reconciled && comp.Status.CurrentSynthesis.Reconciled == nil {
 		comp.Status.CurrentSynthesis.Reconciled = &now
 
 		if synthed := comp.Status.CurrentSynthesis.Synthesized; synthed != nil {
@@ -120,7 +210,7 @@ func (s *sliceController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 				"latency", latency.Abs().Milliseconds(),
 				"compositionName", comp.Name)
 		}
-	} else {
+	} else if !reconciled {
 		comp.Status.CurrentSynthesis.Reconciled = nil
 	}
 
@@ -129,27 +219,116 @@ func (s *sliceController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, fmt.Errorf("updating composition '%s' status: %w", comp.Name, err)
 
 	}
+	s.recordUpdate(req.NamespacedName)
 	logger.V(0).Info("aggregated resource status into composition", "compositionName", comp.Name)
 
 	return ctrl.Result{}, nil
 }
 
+// throttle returns how long to wait before the composition's status can be updated again, or zero if it can be updated now.
+// Requeueing after the returned period batches any changes that land in the meantime into a single write.
+func (s *sliceController) throttle(key types.NamespacedName) time.Duration {
+	if s.minUpdateInterval <= 0 {
+		return 0
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	last, ok := s.lastUpdate[key]
+	if !ok {
+		return 0
+	}
+	return s.minUpdateInterval - time.Since(last)
+}
+
+// recordUpdate tracks the time at which the composition's status was last written.
+// Entries older than the interval no longer throttle anything, so they're pruned at most once per interval.
+func (s *sliceController) recordUpdate(key types.NamespacedName) {
+	if s.minUpdateInterval <= 0 {
+		return
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastPrune) > s.minUpdateInterval {
+		for k, ts := range s.lastUpdate {
+			if now.Sub(ts) >= s.minUpdateInterval {
+				delete(s.lastUpdate, k)
+			}
+		}
+		s.lastPrune = now
+	}
+	s.lastUpdate[key] = now
+}
+
+// setDegradedCondition reflects resources that have regressed since becoming ready in the composition's conditions.
+// Returns true if the conditions changed.
+func setDegradedCondition(comp *apiv1.Composition, msgs []string) bool {
+	if len(msgs) == 0 {
+		if meta.FindStatusCondition(comp.Status.Conditions, apiv1.DegradedCondition) == nil {
+			return false // don't bother setting the condition until a resource has actually regressed
+		}
+		return meta.SetStatusCondition(&comp.Status.Conditions, metav1.Condition{
+			Type:               apiv1.DegradedCondition,
+			Status:             metav1.ConditionFalse,
+			Reason:             "ResourcesReady",
+			ObservedGeneration: comp.Generation,
+		})
+	}
+	return meta.SetStatusCondition(&comp.Status.Conditions, metav1.Condition{
+		Type:               apiv1.DegradedCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "ReadinessRegressed",
+		Message:            strings.Join(msgs, "; "),
+		ObservedGeneration: comp.Generation,
+	})
+}
+
+// setDeletionBlockedCondition reflects protected resources whose deletion is blocked in the composition's conditions.
+// Returns true if the conditions changed.
+func setDeletionBlockedCondition(comp *apiv1.Composition, names []string) bool {
+	if len(names) == 0 {
+		if meta.FindStatusCondition(comp.Status.Conditions, apiv1.DeletionBlockedCondition) == nil {
+			return false // don't bother setting the condition until a deletion has actually been blocked
+		}
+		return meta.SetStatusCondition(&comp.Status.Conditions, metav1.Condition{
+			Type:               apiv1.DeletionBlockedCondition,
+			Status:             metav1.ConditionFalse,
+			Reason:             "NoProtectedResources",
+			ObservedGeneration: comp.Generation,
+		})
+	}
+	return meta.SetStatusCondition(&comp.Status.Conditions, metav1.Condition{
+		Type:               apiv1.DeletionBlockedCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "ResourcesProtected",
+		Message:            "deletion is blocked by the eno.azure.io/protect annotation of: " + strings.Join(names, ", "),
+		ObservedGeneration: comp.Generation,
+	})
+}
+
+// blockedResourceName describes the resource of the given manifest e.g. "ConfigMap default/foo".
+func blockedResourceName(manifest *apiv1.Manifest) string {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON([]byte(manifest.Manifest)); err != nil {
+		return "unknown resource"
+	}
+	if obj.GetNamespace() == "" {
+		return obj.GetKind() + " " + obj.GetName()
+	}
+	return obj.GetKind() + " " + obj.GetNamespace() + "/" + obj.GetName()
+}
+
 // resourceNotReconciled returns true when a resource should be considered reconciled.
 // - When its status has Reconciled == true
 // - When it has been deleted and the composition has also been deleted
 // - When it has been deleted and the composition is configured to orphan resources
 func resourceNotReconciled(comp *apiv1.Composition, state *apiv1.ResourceState) bool {
-	shouldOrphan := comp.Annotations != nil && comp.Annotations["eno.azure.io/deletion-strategy"] == "orphan"
-	return !state.Reconciled || (!state.Deleted && !shouldOrphan && comp.DeletionTimestamp != nil)
-}
-
-// compositionStatusTerminal determines if a status has reached the point that it can no longer
-// progress, from the perspective of the status aggregation controller.
-func compositionStatusTerminal(comp *apiv1.Composition) bool {
-	return comp.Status.CurrentSynthesis == nil || comp.Status.CurrentSynthesis.Synthesized == nil || (comp.Status.CurrentSynthesis.Ready != nil && comp.Status.CurrentSynthesis.Reconciled != nil)
+	return !state.Reconciled || (!state.Deleted && !comp.ShouldOrphan() && comp.DeletionTimestamp != nil)
 }
 
-// compositionStatusInSync compares the given bool representation of a composition's state against its current status struct.
-func compositionStatusInSync(comp *apiv1.Composition, reconciled, ready bool) bool {
-	return (comp.Status.CurrentSynthesis.Reconciled != nil) == reconciled && (comp.Status.CurrentSynthesis.Ready != nil) == ready
+// compositionStatusInSync compares the given representation of a composition's state against its current status struct.
+func compositionStatusInSync(comp *apiv1.Composition, reconciled, ready bool, readinessMsgs []string) bool {
+	return (comp.Status.CurrentSynthesis.Reconciled != nil) == reconciled && (comp.Status.CurrentSynthesis.Ready != nil) == ready && slices.Equal(comp.Status.CurrentSynthesis.ReadinessMessages, readinessMsgs)
 }
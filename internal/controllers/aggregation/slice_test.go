@@ -7,6 +7,7 @@ import (
 	"github.com/Azure/eno/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -289,3 +290,367 @@ func TestOrphanedOnPurpose(t *testing.T) {
 	assert.Nil(t, comp.Status.CurrentSynthesis.Ready)
 	assert.NotNil(t, comp.Status.CurrentSynthesis.Reconciled)
 }
+
+func TestReadinessMessageAggregation(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	now := metav1.Now()
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice-1"
+	slice.Namespace = "default"
+	slice.Spec.Resources = []apiv1.Manifest{{Manifest: "{}"}, {Manifest: "{}"}}
+	slice.Status.Resources = []apiv1.ResourceState{
+		{Ready: &now, Reconciled: true},
+		{Reconciled: true, Message: "Deployment default/foo: 2/5 replicas available"},
+	}
+	require.NoError(t, cli.Create(ctx, slice))
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test"
+	comp.Namespace = "default"
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{
+		Synthesized:    &now,
+		ResourceSlices: []*apiv1.ResourceSliceRef{{Name: slice.Name}},
+	}
+	require.NoError(t, cli.Create(ctx, comp))
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	a := &sliceController{client: cli}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: comp.Namespace, Name: comp.Name}}
+	_, err := a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Nil(t, comp.Status.CurrentSynthesis.Ready)
+	assert.Equal(t, []string{"Deployment default/foo: 2/5 replicas available"}, comp.Status.CurrentSynthesis.ReadinessMessages)
+
+	// Messages are cleared once the resource becomes ready
+	slice.Status.Resources[1] = apiv1.ResourceState{Ready: &now, Reconciled: true}
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	_, err = a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.NotNil(t, comp.Status.CurrentSynthesis.Ready)
+	assert.Nil(t, comp.Status.CurrentSynthesis.ReadinessMessages)
+}
+
+func TestDegradedAggregation(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	now := metav1.Now()
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice-1"
+	slice.Namespace = "default"
+	slice.Spec.Resources = []apiv1.Manifest{{Manifest: "{}"}}
+	slice.Status.Resources = []apiv1.ResourceState{{Ready: &now, Reconciled: true}}
+	require.NoError(t, cli.Create(ctx, slice))
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test"
+	comp.Namespace = "default"
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{
+		Synthesized:    &now,
+		ResourceSlices: []*apiv1.ResourceSliceRef{{Name: slice.Name}},
+	}
+	require.NoError(t, cli.Create(ctx, comp))
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	a := &sliceController{client: cli}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: comp.Namespace, Name: comp.Name}}
+	_, err := a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	// The condition isn't set until a resource regresses
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	require.NotNil(t, comp.Status.CurrentSynthesis.Ready)
+	assert.Nil(t, meta.FindStatusCondition(comp.Status.Conditions, apiv1.DegradedCondition))
+
+	// Regression
+	slice.Status.Resources[0].Degraded = true
+	slice.Status.Resources[0].Message = "ConfigMap foo: resource does not exist"
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	_, err = a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.NotNil(t, comp.Status.CurrentSynthesis.Ready, "readiness is latched")
+	cond := meta.FindStatusCondition(comp.Status.Conditions, apiv1.DegradedCondition)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, "ConfigMap foo: resource does not exist", cond.Message)
+
+	// Recovery
+	slice.Status.Resources[0].Degraded = false
+	slice.Status.Resources[0].Message = ""
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	_, err = a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.True(t, meta.IsStatusConditionFalse(comp.Status.Conditions, apiv1.DegradedCondition))
+}
+
+func TestReadinessRegressionAggregation(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	now := metav1.Now()
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice-1"
+	slice.Namespace = "default"
+	slice.Spec.Resources = []apiv1.Manifest{{Manifest: "{}"}}
+	slice.Status.Resources = []apiv1.ResourceState{{Ready: &now, Reconciled: true}}
+	require.NoError(t, cli.Create(ctx, slice))
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test"
+	comp.Namespace = "default"
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{
+		Synthesized:    &now,
+		ResourceSlices: []*apiv1.ResourceSliceRef{{Name: slice.Name}},
+	}
+	require.NoError(t, cli.Create(ctx, comp))
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	a := &sliceController{client: cli}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: comp.Namespace, Name: comp.Name}}
+	_, err := a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	require.NotNil(t, comp.Status.CurrentSynthesis.Ready)
+	reconciledTime := comp.Status.CurrentSynthesis.Reconciled
+	require.NotNil(t, reconciledTime)
+
+	// Resources with continuous readiness can become unready
+	slice.Status.Resources[0].Ready = nil
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	_, err = a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Nil(t, comp.Status.CurrentSynthesis.Ready)
+	assert.Equal(t, reconciledTime.Unix(), comp.Status.CurrentSynthesis.Reconciled.Unix())
+}
+
+func TestDeletionBlockedAggregation(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	now := metav1.Now()
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice-1"
+	slice.Namespace = "default"
+	slice.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo","namespace":"default"}}`, Deleted: true},
+		{Manifest: `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"bar"}}`},
+	}
+	slice.Status.Resources = []apiv1.ResourceState{{Reconciled: true}, {Ready: &now, Reconciled: true}}
+	require.NoError(t, cli.Create(ctx, slice))
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test"
+	comp.Namespace = "default"
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{
+		Synthesized:    &now,
+		ResourceSlices: []*apiv1.ResourceSliceRef{{Name: slice.Name}},
+	}
+	require.NoError(t, cli.Create(ctx, comp))
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	a := &sliceController{client: cli}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: comp.Namespace, Name: comp.Name}}
+	_, err := a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	// The condition isn't set until a deletion is blocked
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Nil(t, meta.FindStatusCondition(comp.Status.Conditions, apiv1.DeletionBlockedCondition))
+
+	// Blocked
+	slice.Status.Resources[0].Blocked = true
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	_, err = a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	cond := meta.FindStatusCondition(comp.Status.Conditions, apiv1.DeletionBlockedCondition)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, "deletion is blocked by the eno.azure.io/protect annotation of: ConfigMap default/foo", cond.Message)
+
+	// Unblocked
+	slice.Status.Resources[0].Blocked = false
+	slice.Status.Resources[0].Deleted = true
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	_, err = a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.True(t, meta.IsStatusConditionFalse(comp.Status.Conditions, apiv1.DeletionBlockedCondition))
+}
+
+func TestProgressAggregation(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	now := metav1.Now()
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice-1"
+	slice.Namespace = "default"
+	slice.Spec.Resources = []apiv1.Manifest{{Manifest: "{}"}, {Manifest: "{}"}}
+	slice.Status.Resources = []apiv1.ResourceState{{Ready: &now, Reconciled: true}, {Reconciled: true}}
+	require.NoError(t, cli.Create(ctx, slice))
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	stale := &apiv1.ResourceSlice{}
+	stale.Name = "test-slice-2"
+	stale.Namespace = "default"
+	stale.Spec.Resources = []apiv1.Manifest{{Manifest: "{}"}}
+	require.NoError(t, cli.Create(ctx, stale))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test"
+	comp.Namespace = "default"
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{
+		Synthesized:    &now,
+		ResourceSlices: []*apiv1.ResourceSliceRef{{Name: slice.Name}, {Name: stale.Name}},
+	}
+	require.NoError(t, cli.Create(ctx, comp))
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	a := &sliceController{client: cli}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: comp.Namespace, Name: comp.Name}}
+	_, err := a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	// Resources of slices without status yet count towards the total
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Equal(t, &apiv1.SynthesisProgress{Total: 3, Reconciled: 2, Ready: 1, Percent: 33}, comp.Status.CurrentSynthesis.Progress)
+
+	// Progress is updated even when the composition's readiness doesn't change
+	stale.Status.Resources = []apiv1.ResourceState{{Ready: &now, Reconciled: true}}
+	require.NoError(t, cli.Status().Update(ctx, stale))
+
+	_, err = a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Equal(t, &apiv1.SynthesisProgress{Total: 3, Reconciled: 3, Ready: 2, Percent: 66}, comp.Status.CurrentSynthesis.Progress)
+	assert.Nil(t, comp.Status.CurrentSynthesis.Ready)
+}
+
+func TestExportAggregation(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	now := metav1.Now()
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice-1"
+	slice.Namespace = "default"
+	slice.Spec.Resources = []apiv1.Manifest{{Manifest: "{}"}, {Manifest: "{}"}}
+	slice.Status.Resources = []apiv1.ResourceState{
+		{Ready: &now, Reconciled: true, Exports: map[string]string{"ip": "10.0.0.1", "port": "443"}},
+		{Reconciled: true, Exports: map[string]string{"ip": "10.0.0.2"}},
+	}
+	require.NoError(t, cli.Create(ctx, slice))
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test"
+	comp.Namespace = "default"
+	comp.Status.Exports = map[string]string{"ip": "10.0.0.0"}
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{
+		Synthesized:    &now,
+		ResourceSlices: []*apiv1.ResourceSliceRef{{Name: slice.Name}},
+	}
+	require.NoError(t, cli.Create(ctx, comp))
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	a := &sliceController{client: cli}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: comp.Namespace, Name: comp.Name}}
+	_, err := a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	// Exports aren't published until the synthesis is ready
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Equal(t, map[string]string{"ip": "10.0.0.0"}, comp.Status.Exports)
+
+	slice.Status.Resources[1].Ready = &now
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	_, err = a.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Equal(t, map[string]string{"ip": "10.0.0.1", "port": "443"}, comp.Status.Exports)
+	assert.NotNil(t, comp.Status.CurrentSynthesis.Ready)
+}
+
+func TestBatchedAggregation(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	now := metav1.Now()
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice-1"
+	slice.Namespace = "default"
+	slice.Spec.Resources = []apiv1.Manifest{{Manifest: "{}"}, {Manifest: "{}"}}
+	slice.Status.Resources = []apiv1.ResourceState{{Ready: &now, Reconciled: true}, {Reconciled: true}}
+	require.NoError(t, cli.Create(ctx, slice))
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test"
+	comp.Namespace = "default"
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{
+		Synthesized:    &now,
+		ResourceSlices: []*apiv1.ResourceSliceRef{{Name: slice.Name}},
+	}
+	require.NoError(t, cli.Create(ctx, comp))
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	a := &sliceController{client: cli, minUpdateInterval: time.Hour, lastUpdate: map[types.NamespacedName]time.Time{}}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: comp.Namespace, Name: comp.Name}}
+	res, err := a.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Zero(t, res.RequeueAfter)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.NotNil(t, comp.Status.CurrentSynthesis.Reconciled)
+	assert.Nil(t, comp.Status.CurrentSynthesis.Ready)
+
+	// The next change is deferred until the interval has passed
+	slice.Status.Resources[1].Ready = &now
+	require.NoError(t, cli.Status().Update(ctx, slice))
+
+	res, err = a.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Greater(t, res.RequeueAfter, time.Minute*59)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Nil(t, comp.Status.CurrentSynthesis.Ready)
+
+	// ...at which point it's written
+	a.lastUpdate[req.NamespacedName] = time.Now().Add(-time.Hour)
+	res, err = a.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Zero(t, res.RequeueAfter)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.NotNil(t, comp.Status.CurrentSynthesis.Ready)
+	assert.Equal(t, 100, comp.Status.CurrentSynthesis.Progress.Percent)
+}
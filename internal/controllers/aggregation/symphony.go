@@ -21,9 +21,9 @@ func NewSymphonyController(mgr ctrl.Manager) error {
 		For(&apiv1.Symphony{}).
 		Owns(&apiv1.Composition{}).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "symphonyAggregationController")).
-		Complete(&symphonyController{
+		Complete(manager.WithController("symphonyAggregationController", &symphonyController{
 			client: mgr.GetClient(),
-		})
+		}))
 }
 
 func (c *symphonyController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -2,7 +2,8 @@ package flowcontrol
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
-	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/Azure/eno/internal/telemetry"
 )
 
 var (
@@ -18,9 +19,37 @@ var (
 			Help: "Count of the syntheses that are being synthesized",
 		},
 	)
+	synthesisSlotUtilization = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "eno_synthesis_slot_utilization_ratio",
+			Help: "Ratio of active syntheses to the concurrency limit",
+		},
+	)
+	synthesesDispatched = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "eno_syntheses_dispatched_total",
+			Help: "Count of the syntheses dispatched by the concurrency limiter",
+		},
+	)
+	synthesesPreempted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "eno_syntheses_preempted_total",
+			Help: "Count of the active syntheses preempted by higher priority syntheses",
+		},
+	)
+	throttledSyntheses = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "eno_throttled_syntheses",
+			Help: "Count of the pending syntheses held back because their composition's recent syntheses have failed or their namespace's synthesis quota is exhausted",
+		},
+	)
 )
 
 func init() {
-	metrics.Registry.MustRegister(pendingSyntheses)
-	metrics.Registry.MustRegister(activeSyntheses)
+	telemetry.MustRegister(pendingSyntheses)
+	telemetry.MustRegister(activeSyntheses)
+	telemetry.MustRegister(synthesisSlotUtilization)
+	telemetry.MustRegister(synthesesDispatched)
+	telemetry.MustRegister(synthesesPreempted)
+	telemetry.MustRegister(throttledSyntheses)
 }
@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"sort"
 	"time"
 
 	apiv1 "github.com/Azure/eno/api/v1"
 	"github.com/Azure/eno/internal/manager"
 	"github.com/go-logr/logr"
 	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -20,18 +23,55 @@ type synthesisConcurrencyLimiter struct {
 	client   client.Client
 	limit    int
 	cooldown time.Duration
+
+	// preemption bounds the rate at which active syntheses can be preempted by higher priority pending syntheses.
+	// Preemption is disabled when nil.
+	preemption *rate.Limiter
+
+	// dispatched holds the resource version of compositions (as seen by the informer) prior to their dispatch.
+	// Several syntheses are dispatched per pass, so the informer cache may not reflect the previous pass's dispatches yet.
+	// Compositions that still have the same resource version are counted as active to avoid exceeding the limit.
+	dispatched map[types.NamespacedName]string
+
+	// failures tracks consecutive failed syntheses per composition.
+	// Compositions that keep failing are deprioritized and throttled so they can't monopolize synthesis slots.
+	// Streaks are only held in memory, so they reset when the controller restarts or leadership changes.
+	failures map[types.NamespacedName]*failureStreak
+
+	// quotaDispatches holds the dispatch times of each namespace's syntheses during the last quota window.
+	// Like failure streaks, they're only held in memory, so EnoQuota synthesis rate limits reset when leadership changes.
+	quotaDispatches map[string][]time.Time
+}
+
+type failureStreak struct {
+	UUID        string // of the most recently observed synthesis
+	Count       int
+	LastFailure time.Time
 }
 
-func NewSynthesisConcurrencyLimiter(mgr ctrl.Manager, limit int, cooldown time.Duration) error {
+const (
+	failureBackoffBase = time.Second * 10
+	failureBackoffMax  = time.Minute * 10
+	quotaWindow        = time.Hour
+)
+
+// NewSynthesisConcurrencyLimiter dispatches pending syntheses while honoring the given concurrency limit.
+// Higher priority syntheses are preempted at up to preemptionQPS when the limit has been reached (zero disables preemption).
+func NewSynthesisConcurrencyLimiter(mgr ctrl.Manager, limit int, cooldown time.Duration, preemptionQPS float64) error {
+	c := &synthesisConcurrencyLimiter{
+		client:   mgr.GetClient(),
+		limit:    limit,
+		cooldown: cooldown,
+	}
+	if preemptionQPS > 0 {
+		c.preemption = rate.NewLimiter(rate.Limit(preemptionQPS), 1)
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("synthesisConcurrencyLimiter").
 		Watches(&apiv1.Composition{}, manager.SingleEventHandler()).
+		Watches(&apiv1.EnoQuota{}, manager.SingleEventHandler()).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "synthesisConcurrencyLimiter")).
-		Complete(&synthesisConcurrencyLimiter{
-			client:   mgr.GetClient(),
-			limit:    limit,
-			cooldown: cooldown,
-		})
+		Complete(manager.WithController("synthesisConcurrencyLimiter", c))
 }
 
 func (c *synthesisConcurrencyLimiter) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -43,38 +83,116 @@ func (c *synthesisConcurrencyLimiter) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, err
 	}
 
-	var active int
+	quotas, err := c.listQuotaRateLimits(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := time.Now()
+	var active, throttled int
+	var nextEligible time.Duration
 	var pending []*apiv1.Composition
+	var preemptable *apiv1.Composition // lowest priority active synthesis
+	dispatched := map[types.NamespacedName]string{}
+	failures := map[types.NamespacedName]*failureStreak{}
 	for _, comp := range list.Items {
 		comp := comp
+		key := client.ObjectKeyFromObject(&comp)
+		if streak := c.observeFailures(key, comp.Status.CurrentSynthesis, now); streak != nil {
+			failures[key] = streak
+		}
+
 		current := comp.Status.CurrentSynthesis
 		if current == nil || current.Synthesized != nil {
 			continue // not ready or already synthesized
 		}
-		if current.UUID == "" {
-			pending = append(pending, &comp)
-		} else {
+		if current.UUID != "" {
 			active++
+			if preemptable == nil || comp.SynthesisPriority() < preemptable.SynthesisPriority() {
+				preemptable = &comp
+			}
+			continue
 		}
+
+		if rv, ok := c.dispatched[key]; ok && rv == comp.ResourceVersion {
+			dispatched[key] = rv
+			active++ // the informer hasn't caught up with our dispatch yet
+			continue
+		}
+		wait := failures[key].backoff(now)
+		if wait == 0 {
+			wait = c.quotaWait(comp.Namespace, quotas, now)
+		}
+		if wait > 0 {
+			throttled++
+			if nextEligible == 0 || wait < nextEligible {
+				nextEligible = wait
+			}
+			continue
+		}
+		pending = append(pending, &comp)
 	}
+	c.dispatched = dispatched
+	c.failures = failures
 	activeSyntheses.Set(float64(active))
-	pendingSyntheses.Set(float64(len(pending)))
+	pendingSyntheses.Set(float64(len(pending) + throttled))
+	throttledSyntheses.Set(float64(throttled))
+	if c.limit > 0 {
+		synthesisSlotUtilization.Set(float64(active) / float64(c.limit))
+	}
+
+	if len(pending) == 0 {
+		return ctrl.Result{RequeueAfter: nextEligible}, nil // nothing to dispatch (yet)
+	}
+
+	// Dispatch in order of priority, then by failure streak, randomizing within each level
+	rand.Shuffle(len(pending), func(i, j int) { pending[i], pending[j] = pending[j], pending[i] })
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pi, pj := pending[i].SynthesisPriority(), pending[j].SynthesisPriority(); pi != pj {
+			return pi > pj
+		}
+		return c.failures[client.ObjectKeyFromObject(pending[i])].count() < c.failures[client.ObjectKeyFromObject(pending[j])].count()
+	})
 
 	if active >= c.limit {
-		logger.V(1).Info("refusing to dispatch synthesis because concurrency limit has been reached", "active", active, "pending", pending)
+		if preemptable != nil && c.shouldPreempt(pending[0], preemptable) {
+			return ctrl.Result{Requeue: true, RequeueAfter: c.cooldown}, c.preempt(ctx, preemptable)
+		}
+		logger.V(1).Info("refusing to dispatch synthesis because concurrency limit has been reached", "active", active, "pending", len(pending))
 		return ctrl.Result{}, nil
 	}
 
-	if len(pending) == 0 {
-		return ctrl.Result{}, nil // nothing to dispatch
+	// Fill every available slot
+	var n int
+	for _, next := range pending {
+		if active+n >= c.limit {
+			break
+		}
+		if c.quotaWait(next.Namespace, quotas, now) > 0 {
+			continue // the namespace's quota was exhausted by this pass
+		}
+		ok, err := c.dispatch(ctx, next)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if ok {
+			n++
+		}
 	}
-	next := pending[rand.Intn(len(pending))]
-	logger = logger.WithValues("compositionName", next.Name,
-		"compositionNamespace", next.Namespace,
-		"compositionGeneration", next.Generation,
-		"synthesisID", next.Status.GetCurrentSynthesisUUID())
+	if c.limit > 0 {
+		synthesisSlotUtilization.Set(float64(active+n) / float64(c.limit))
+	}
+
+	return ctrl.Result{Requeue: true, RequeueAfter: c.cooldown}, nil
+}
+
+// dispatch assigns a UUID to the composition's pending synthesis, which allows it to be synthesized.
+// The patch is conditional on the UUID not having already been set, so stale informers can't cause double dispatches.
+func (c *synthesisConcurrencyLimiter) dispatch(ctx context.Context, comp *apiv1.Composition) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("compositionName", comp.Name,
+		"compositionNamespace", comp.Namespace,
+		"compositionGeneration", comp.Generation)
 
-	// Dispatch the next pending synthesis
 	path := "/status/currentSynthesis/uuid"
 	patch := []map[string]any{
 		{"op": "test", "path": path, "value": nil},
@@ -82,13 +200,146 @@ func (c *synthesisConcurrencyLimiter) Reconcile(ctx context.Context, req ctrl.Re
 	}
 	patchJS, err := json.Marshal(&patch)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("encoding patch: %w", err)
+		return false, fmt.Errorf("encoding patch: %w", err)
 	}
 
-	if err := c.client.Status().Patch(ctx, next, client.RawPatch(types.JSONPatchType, patchJS)); err != nil {
-		return ctrl.Result{}, fmt.Errorf("writing uuid to composition status: %w", err)
+	key := client.ObjectKeyFromObject(comp)
+	rv := comp.ResourceVersion
+	if err := c.client.Status().Patch(ctx, comp, client.RawPatch(types.JSONPatchType, patchJS)); err != nil {
+		if errors.IsInvalid(err) || errors.IsNotFound(err) {
+			logger.V(1).Info("skipping dispatch of synthesis that is no longer pending", "error", err.Error())
+			return false, nil
+		}
+		return false, fmt.Errorf("writing uuid to composition status: %w", err)
+	}
+	if c.dispatched == nil {
+		c.dispatched = map[types.NamespacedName]string{}
+	}
+	c.dispatched[key] = rv
+	if c.quotaDispatches == nil {
+		c.quotaDispatches = map[string][]time.Time{}
 	}
-	logger.V(0).Info("dispatched synthesis")
+	c.quotaDispatches[comp.Namespace] = append(c.quotaDispatches[comp.Namespace], time.Now())
+	synthesesDispatched.Inc()
+	logger.V(0).Info("dispatched synthesis", "synthesisID", comp.Status.GetCurrentSynthesisUUID())
 
-	return ctrl.Result{Requeue: true, RequeueAfter: c.cooldown}, nil
+	return true, nil
+}
+
+// listQuotaRateLimits returns the most restrictive EnoQuota synthesis rate limit of each namespace that has one.
+func (c *synthesisConcurrencyLimiter) listQuotaRateLimits(ctx context.Context) (map[string]int64, error) {
+	list := &apiv1.EnoQuotaList{}
+	err := c.client.List(ctx, list)
+	if err != nil {
+		return nil, fmt.Errorf("listing quotas: %w", err)
+	}
+
+	limits := map[string]int64{}
+	for _, quota := range list.Items {
+		limit := quota.Spec.MaxSynthesesPerHour
+		if limit == nil {
+			continue
+		}
+		if current, ok := limits[quota.Namespace]; !ok || *limit < current {
+			limits[quota.Namespace] = *limit
+		}
+	}
+	return limits, nil
+}
+
+// quotaWait returns the remaining time before another synthesis can be dispatched in the namespace without exceeding its quota.
+// Dispatches that have aged out of the quota window are forgotten.
+func (c *synthesisConcurrencyLimiter) quotaWait(ns string, limits map[string]int64, now time.Time) time.Duration {
+	recent := c.quotaDispatches[ns]
+	for len(recent) > 0 && now.Sub(recent[0]) >= quotaWindow {
+		recent = recent[1:]
+	}
+	if len(recent) == 0 {
+		delete(c.quotaDispatches, ns)
+	} else {
+		c.quotaDispatches[ns] = recent
+	}
+
+	limit, ok := limits[ns]
+	if !ok || int64(len(recent)) < limit {
+		return 0
+	}
+	if limit == 0 {
+		return quotaWindow
+	}
+	return max(recent[int64(len(recent))-limit].Add(quotaWindow).Sub(now), 0)
+}
+
+// observeFailures updates the composition's failure streak given its current synthesis.
+// Returns nil when the composition has no failure streak.
+func (c *synthesisConcurrencyLimiter) observeFailures(key types.NamespacedName, current *apiv1.Synthesis, now time.Time) *failureStreak {
+	streak := c.failures[key]
+	if current == nil || current.Synthesized == nil || (streak != nil && streak.UUID == current.UUID) {
+		return streak // nothing new to observe
+	}
+	if !current.Failed() {
+		return nil
+	}
+	if streak == nil {
+		streak = &failureStreak{}
+	}
+	streak.UUID = current.UUID
+	streak.Count++
+	streak.LastFailure = now
+	return streak
+}
+
+func (f *failureStreak) count() int {
+	if f == nil {
+		return 0
+	}
+	return f.Count
+}
+
+// backoff returns the remaining time before a composition with this failure streak can be dispatched again.
+func (f *failureStreak) backoff(now time.Time) time.Duration {
+	if f == nil || f.Count == 0 {
+		return 0
+	}
+	wait := failureBackoffMax
+	if f.Count < 16 {
+		wait = min(failureBackoffBase<<(f.Count-1), failureBackoffMax)
+	}
+	return max(wait-now.Sub(f.LastFailure), 0)
+}
+
+// shouldPreempt returns true when the active synthesis should be preempted in favor of the pending one.
+func (c *synthesisConcurrencyLimiter) shouldPreempt(pending, active *apiv1.Composition) bool {
+	return c.preemption != nil && pending.SynthesisPriority() > active.SynthesisPriority() && c.preemption.Allow()
+}
+
+// preempt returns an active synthesis to the pending state by removing its UUID.
+// The synthesizer pod is considered to be superseded and will be deleted, and its output will not be written.
+func (c *synthesisConcurrencyLimiter) preempt(ctx context.Context, comp *apiv1.Composition) error {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("compositionName", comp.Name,
+		"compositionNamespace", comp.Namespace,
+		"compositionGeneration", comp.Generation,
+		"synthesisID", comp.Status.GetCurrentSynthesisUUID())
+
+	path := "/status/currentSynthesis/uuid"
+	patch := []map[string]any{
+		{"op": "test", "path": path, "value": comp.Status.CurrentSynthesis.UUID},
+		{"op": "remove", "path": path},
+	}
+	patchJS, err := json.Marshal(&patch)
+	if err != nil {
+		return fmt.Errorf("encoding patch: %w", err)
+	}
+
+	if err := c.client.Status().Patch(ctx, comp, client.RawPatch(types.JSONPatchType, patchJS)); err != nil {
+		if errors.IsInvalid(err) || errors.IsNotFound(err) {
+			logger.V(1).Info("skipping preemption of synthesis that is no longer active", "error", err.Error())
+			return nil
+		}
+		return fmt.Errorf("removing uuid from composition status: %w", err)
+	}
+	synthesesPreempted.Inc()
+	logger.V(0).Info("preempted synthesis", "priority", comp.SynthesisPriority())
+
+	return nil
 }
@@ -2,11 +2,16 @@ package flowcontrol
 
 import (
 	"testing"
+	"time"
 
 	apiv1 "github.com/Azure/eno/api/v1"
 	"github.com/Azure/eno/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -94,3 +99,253 @@ func TestSynthesisConcurrencyLimitOver(t *testing.T) {
 
 	assert.Equal(t, 1, active) // only one was dispatched
 }
+
+func TestSynthesisConcurrencyLimitFillsSlots(t *testing.T) {
+	cli := testutil.NewClient(t)
+	ctx := testutil.NewContext(t)
+	c := &synthesisConcurrencyLimiter{}
+	c.client = cli
+	c.limit = 2
+
+	comps := []*apiv1.Composition{}
+	for _, name := range []string{"test-comp-1", "test-comp-2", "test-comp-3"} {
+		comp := &apiv1.Composition{}
+		comp.Name = name
+		require.NoError(t, cli.Create(ctx, comp))
+
+		comp.Status.CurrentSynthesis = &apiv1.Synthesis{}
+		require.NoError(t, cli.Status().Update(ctx, comp))
+		comps = append(comps, comp)
+	}
+
+	// A single pass dispatches up to the limit
+	_, err := c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+
+	var active int
+	for _, comp := range comps {
+		require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+		if comp.Status.CurrentSynthesis.UUID != "" {
+			active++
+		}
+	}
+	assert.Equal(t, 2, active)
+}
+
+func TestSynthesisConcurrencyLimitStaleInformer(t *testing.T) {
+	cli := testutil.NewClient(t)
+	ctx := testutil.NewContext(t)
+	c := &synthesisConcurrencyLimiter{}
+	c.client = cli
+	c.limit = 1
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	require.NoError(t, cli.Create(ctx, comp))
+
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	comp2 := &apiv1.Composition{}
+	comp2.Name = "test-comp-2"
+	require.NoError(t, cli.Create(ctx, comp2))
+
+	comp2.Status.CurrentSynthesis = &apiv1.Synthesis{}
+	require.NoError(t, cli.Status().Update(ctx, comp2))
+
+	// Simulate an informer that hasn't observed the dispatch of the first composition yet
+	c.dispatched = map[types.NamespacedName]string{client.ObjectKeyFromObject(comp): comp.ResourceVersion}
+
+	_, err := c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp2), comp2))
+	assert.Empty(t, comp2.Status.CurrentSynthesis.UUID)
+	assert.Len(t, c.dispatched, 1)
+}
+
+func TestSynthesisConcurrencyLimitPriority(t *testing.T) {
+	cli := testutil.NewClient(t)
+	ctx := testutil.NewContext(t)
+	c := &synthesisConcurrencyLimiter{}
+	c.client = cli
+	c.limit = 1
+
+	low := &apiv1.Composition{}
+	low.Name = "low"
+	require.NoError(t, cli.Create(ctx, low))
+	low.Status.CurrentSynthesis = &apiv1.Synthesis{}
+	require.NoError(t, cli.Status().Update(ctx, low))
+
+	high := &apiv1.Composition{}
+	high.Name = "high"
+	high.Annotations = map[string]string{"eno.azure.io/synthesis-priority": "10"}
+	require.NoError(t, cli.Create(ctx, high))
+	high.Status.CurrentSynthesis = &apiv1.Synthesis{}
+	require.NoError(t, cli.Status().Update(ctx, high))
+
+	_, err := c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(high), high))
+	assert.NotEmpty(t, high.Status.CurrentSynthesis.UUID)
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(low), low))
+	assert.Empty(t, low.Status.CurrentSynthesis.UUID)
+}
+
+func TestSynthesisConcurrencyLimitPreemption(t *testing.T) {
+	cli := testutil.NewClient(t)
+	ctx := testutil.NewContext(t)
+	c := &synthesisConcurrencyLimiter{}
+	c.client = cli
+	c.limit = 1
+
+	low := &apiv1.Composition{}
+	low.Name = "low"
+	require.NoError(t, cli.Create(ctx, low))
+	low.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "active"}
+	require.NoError(t, cli.Status().Update(ctx, low))
+
+	high := &apiv1.Composition{}
+	high.Name = "high"
+	high.Annotations = map[string]string{"eno.azure.io/synthesis-priority": "10"}
+	require.NoError(t, cli.Create(ctx, high))
+	high.Status.CurrentSynthesis = &apiv1.Synthesis{}
+	require.NoError(t, cli.Status().Update(ctx, high))
+
+	// Preemption is disabled by default
+	_, err := c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(low), low))
+	assert.Equal(t, "active", low.Status.CurrentSynthesis.UUID)
+
+	// Preempt the low priority synthesis
+	c.preemption = rate.NewLimiter(rate.Inf, 1)
+	_, err = c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(low), low))
+	assert.Empty(t, low.Status.CurrentSynthesis.UUID)
+
+	// The high priority synthesis is dispatched next
+	_, err = c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(high), high))
+	assert.NotEmpty(t, high.Status.CurrentSynthesis.UUID)
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(low), low))
+	assert.Empty(t, low.Status.CurrentSynthesis.UUID)
+}
+
+func TestSynthesisConcurrencyLimitFailureBackoff(t *testing.T) {
+	cli := testutil.NewClient(t)
+	ctx := testutil.NewContext(t)
+	c := &synthesisConcurrencyLimiter{}
+	c.client = cli
+	c.limit = 1
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	require.NoError(t, cli.Create(ctx, comp))
+
+	// Observe a failed synthesis
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "failed", Synthesized: ptr.To(metav1.Now()), Results: []apiv1.Result{{Severity: "error"}}}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	_, err := c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.failures[client.ObjectKeyFromObject(comp)].count())
+
+	// The next synthesis is throttled
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	result, err := c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+	assert.Greater(t, result.RequeueAfter, time.Duration(0))
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Empty(t, comp.Status.CurrentSynthesis.UUID)
+
+	// Dispatched once the backoff period has elapsed
+	c.failures[client.ObjectKeyFromObject(comp)].LastFailure = time.Now().Add(-time.Hour)
+	_, err = c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.NotEmpty(t, comp.Status.CurrentSynthesis.UUID)
+}
+
+func TestFailureStreakBackoff(t *testing.T) {
+	now := time.Now()
+	var streak *failureStreak
+	assert.Zero(t, streak.backoff(now))
+
+	streak = &failureStreak{Count: 1, LastFailure: now}
+	assert.Equal(t, failureBackoffBase, streak.backoff(now))
+
+	streak.Count = 3
+	assert.Equal(t, failureBackoffBase*4, streak.backoff(now))
+	assert.Equal(t, failureBackoffBase*3, streak.backoff(now.Add(failureBackoffBase)))
+
+	streak.Count = 100
+	assert.Equal(t, failureBackoffMax, streak.backoff(now))
+	assert.Zero(t, streak.backoff(now.Add(failureBackoffMax*2)))
+}
+
+func TestSynthesisConcurrencyLimitQuota(t *testing.T) {
+	cli := testutil.NewClient(t)
+	ctx := testutil.NewContext(t)
+	c := &synthesisConcurrencyLimiter{}
+	c.client = cli
+	c.limit = 10
+
+	quota := &apiv1.EnoQuota{}
+	quota.Name = "test-quota"
+	quota.Namespace = "limited"
+	quota.Spec.MaxSynthesesPerHour = ptr.To(int64(1))
+	require.NoError(t, cli.Create(ctx, quota))
+
+	comps := []*apiv1.Composition{}
+	for _, key := range []types.NamespacedName{{Name: "test-comp-1", Namespace: "limited"}, {Name: "test-comp-2", Namespace: "limited"}, {Name: "test-comp-3", Namespace: "default"}} {
+		comp := &apiv1.Composition{}
+		comp.Name = key.Name
+		comp.Namespace = key.Namespace
+		require.NoError(t, cli.Create(ctx, comp))
+
+		comp.Status.CurrentSynthesis = &apiv1.Synthesis{}
+		require.NoError(t, cli.Status().Update(ctx, comp))
+		comps = append(comps, comp)
+	}
+
+	// Only one synthesis is dispatched in the limited namespace
+	_, err := c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+
+	active := map[string]int{}
+	var waiting *apiv1.Composition
+	for _, comp := range comps {
+		require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+		if comp.Status.CurrentSynthesis.UUID != "" {
+			active[comp.Namespace]++
+		} else {
+			waiting = comp
+		}
+	}
+	assert.Equal(t, map[string]int{"limited": 1, "default": 1}, active)
+	require.NotNil(t, waiting)
+
+	// The next pass waits for the window to elapse
+	result, err := c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+	assert.Greater(t, result.RequeueAfter, time.Minute*59)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(waiting), waiting))
+	assert.Empty(t, waiting.Status.CurrentSynthesis.UUID)
+
+	// Dispatched once the window has elapsed
+	c.quotaDispatches["limited"][0] = time.Now().Add(-quotaWindow)
+	_, err = c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(waiting), waiting))
+	assert.NotEmpty(t, waiting.Status.CurrentSynthesis.UUID)
+}
@@ -0,0 +1,107 @@
+package identifier
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/manager"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// poolController releases identifiers held by compositions that have been deleted or no longer request them.
+// Allocation happens during synthesis - see the execution package.
+type poolController struct {
+	client        client.Client
+	noCacheReader client.Reader
+}
+
+func NewPoolController(mgr ctrl.Manager) error {
+	c := &poolController{
+		client:        mgr.GetClient(),
+		noCacheReader: mgr.GetAPIReader(),
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&apiv1.IdentifierPool{}).
+		Watches(&apiv1.Composition{}, handler.EnqueueRequestsFromMapFunc(c.mapComposition)).
+		WithLogConstructor(manager.NewLogConstructor(mgr, "identifierPoolController")).
+		Complete(manager.WithController("identifierPoolController", c))
+}
+
+func (c *poolController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	pool := &apiv1.IdentifierPool{}
+	err := c.client.Get(ctx, req.NamespacedName, pool)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	logger = logger.WithValues("identifierPoolName", pool.Name, "identifierPoolNamespace", pool.Namespace)
+
+	var held []apiv1.IdentifierAllocation
+	for _, alloc := range pool.Status.Allocations {
+		// Confirm with the apiserver before releasing, since the cache may not have caught up with a new composition
+		key := types.NamespacedName{Name: alloc.Composition, Namespace: pool.Namespace}
+		ok, err := holdsIdentifier(ctx, c.client, key, alloc.CompositionUID, pool.Name)
+		if err == nil && !ok {
+			ok, err = holdsIdentifier(ctx, c.noCacheReader, key, alloc.CompositionUID, pool.Name)
+		}
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if ok {
+			held = append(held, alloc)
+			continue
+		}
+		logger.V(0).Info("releasing identifier", "compositionName", alloc.Composition, "value", alloc.Value)
+	}
+	if len(held) == len(pool.Status.Allocations) {
+		return ctrl.Result{}, nil
+	}
+
+	pool.Status.Allocations = held
+	pool.Status.Allocated = len(held)
+	err = c.client.Status().Update(ctx, pool)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("updating pool status: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// holdsIdentifier returns true when the composition exists and requests an identifier from the given pool.
+// A composition that was recreated with the same name doesn't hold the previous one's identifiers.
+func holdsIdentifier(ctx context.Context, reader client.Reader, key types.NamespacedName, uid types.UID, pool string) (bool, error) {
+	comp := &apiv1.Composition{}
+	err := reader.Get(ctx, key, comp)
+	if err != nil || comp.UID != uid {
+		return false, client.IgnoreNotFound(err)
+	}
+	for _, req := range comp.Spec.Identifiers {
+		if req.Pool == pool {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// mapComposition enqueues every pool in the composition's namespace, since
+// the pools it no longer requests aren't visible in the new version of the composition.
+func (c *poolController) mapComposition(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &apiv1.IdentifierPoolList{}
+	err := c.client.List(ctx, list, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "listing identifier pools")
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, len(list.Items))
+	for i, pool := range list.Items {
+		reqs[i] = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&pool)}
+	}
+	return reqs
+}
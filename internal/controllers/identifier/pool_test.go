@@ -0,0 +1,53 @@
+package identifier
+
+import (
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestPoolRelease(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	mgr := testutil.NewManager(t)
+	cli := mgr.GetClient()
+	require.NoError(t, NewPoolController(mgr.Manager))
+	mgr.Start(t)
+
+	pool := &apiv1.IdentifierPool{}
+	pool.Name = "test-pool"
+	pool.Namespace = "default"
+	require.NoError(t, cli.Create(ctx, pool))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Identifiers = []apiv1.IdentifierRequest{{Key: "foo", Pool: pool.Name}}
+	require.NoError(t, cli.Create(ctx, comp))
+
+	// Allocations of compositions that exist and request the pool are retained, even if a previous composition had the same name
+	pool.Status.Allocations = []apiv1.IdentifierAllocation{
+		{Composition: comp.Name, CompositionUID: comp.UID, Value: "0", Allocated: metav1.Now()},
+		{Composition: comp.Name, CompositionUID: "recreated-comp-uid", Value: "1", Allocated: metav1.Now()},
+		{Composition: "deleted-comp", CompositionUID: "deleted-comp-uid", Value: "2", Allocated: metav1.Now()},
+	}
+	pool.Status.Allocated = 3
+	require.NoError(t, cli.Status().Update(ctx, pool))
+
+	testutil.Eventually(t, func() bool {
+		require.NoError(t, client.IgnoreNotFound(cli.Get(ctx, client.ObjectKeyFromObject(pool), pool)))
+		return len(pool.Status.Allocations) == 1 && pool.Status.Allocations[0].Value == "0" && pool.Status.Allocated == 1
+	})
+
+	// Released when the composition stops requesting it
+	comp.Spec.Identifiers = nil
+	require.NoError(t, cli.Update(ctx, comp))
+
+	testutil.Eventually(t, func() bool {
+		require.NoError(t, client.IgnoreNotFound(cli.Get(ctx, client.ObjectKeyFromObject(pool), pool)))
+		return len(pool.Status.Allocations) == 0
+	})
+}
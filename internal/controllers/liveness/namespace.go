@@ -43,11 +43,11 @@ func NewNamespaceController(mgr ctrl.Manager, checks int, creationGracePeriod ti
 	}
 
 	return b.WithLogConstructor(manager.NewLogConstructor(mgr, "namespaceLivenessController")).
-		Complete(&namespaceController{
+		Complete(manager.WithController("namespaceLivenessController", &namespaceController{
 			client:                mgr.GetClient(),
 			creationGracePeriod:   creationGracePeriod,
 			orphanCheckIterations: checks,
-		})
+		}))
 }
 
 func (c *namespaceController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -0,0 +1,113 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/manager"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// controller reports the usage of each EnoQuota's namespace in its status.
+// Limits are enforced elsewhere - see the composition webhook, synthesis concurrency limiter, and execution package.
+type controller struct {
+	client client.Client
+}
+
+func NewController(mgr ctrl.Manager) error {
+	c := &controller{
+		client: mgr.GetClient(),
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&apiv1.EnoQuota{}).
+		Watches(&apiv1.Composition{}, handler.EnqueueRequestsFromMapFunc(c.mapComposition)).
+		WithLogConstructor(manager.NewLogConstructor(mgr, "quotaController")).
+		Complete(manager.WithController("quotaController", c))
+}
+
+func (c *controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	quota := &apiv1.EnoQuota{}
+	err := c.client.Get(ctx, req.NamespacedName, quota)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	logger = logger.WithValues("quotaName", quota.Name, "quotaNamespace", quota.Namespace)
+
+	list := &apiv1.CompositionList{}
+	err = c.client.List(ctx, list, client.InNamespace(quota.Namespace))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("listing compositions: %w", err)
+	}
+
+	status := apiv1.EnoQuotaStatus{Compositions: int64(len(list.Items))}
+	for _, comp := range list.Items {
+		n, err := c.countResources(ctx, &comp)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		status.Resources += n
+	}
+	if status == quota.Status {
+		return ctrl.Result{}, nil
+	}
+
+	quota.Status = status
+	err = c.client.Status().Update(ctx, quota)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("updating quota status: %w", err)
+	}
+	logger.V(1).Info("updated quota usage", "compositions", status.Compositions, "resources", status.Resources)
+	return ctrl.Result{}, nil
+}
+
+// countResources returns the number of resources (excluding tombstones) in the composition's most recent successful synthesis.
+// Failed syntheses aren't reconciled, so their resources don't count against the quota.
+func (c *controller) countResources(ctx context.Context, comp *apiv1.Composition) (int64, error) {
+	syn := comp.OutputSynthesis()
+	if syn == nil {
+		return 0, nil
+	}
+
+	var n int64
+	for _, ref := range syn.ResourceSlices {
+		slice := &apiv1.ResourceSlice{}
+		slice.Name = ref.Name
+		slice.Namespace = comp.Namespace
+		err := c.client.Get(ctx, client.ObjectKeyFromObject(slice), slice)
+		if errors.IsNotFound(err) {
+			continue // the composition will be updated (and enqueue the quota again) soon
+		}
+		if err != nil {
+			return 0, fmt.Errorf("getting resource slice: %w", err)
+		}
+		for _, res := range slice.Spec.Resources {
+			if !res.Deleted {
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+func (c *controller) mapComposition(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &apiv1.EnoQuotaList{}
+	err := c.client.List(ctx, list, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "listing quotas")
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, len(list.Items))
+	for i, quota := range list.Items {
+		reqs[i] = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&quota)}
+	}
+	return reqs
+}
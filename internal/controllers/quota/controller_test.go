@@ -0,0 +1,68 @@
+package quota
+
+import (
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestQuotaUsage(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	mgr := testutil.NewManager(t)
+	cli := mgr.GetClient()
+	require.NoError(t, NewController(mgr.Manager))
+	mgr.Start(t)
+
+	quota := &apiv1.EnoQuota{}
+	quota.Name = "test-quota"
+	quota.Namespace = "default"
+	require.NoError(t, cli.Create(ctx, quota))
+
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = "default"
+	slice.Spec.Resources = []apiv1.Manifest{{Manifest: "{}"}, {Manifest: "{}"}, {Manifest: "{}", Deleted: true}}
+	require.NoError(t, cli.Create(ctx, slice))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	require.NoError(t, cli.Create(ctx, comp))
+
+	testutil.Eventually(t, func() bool {
+		require.NoError(t, client.IgnoreNotFound(cli.Get(ctx, client.ObjectKeyFromObject(quota), quota)))
+		return quota.Status.Compositions == 1 && quota.Status.Resources == 0
+	})
+
+	// Tombstones aren't counted
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid", Synthesized: ptr.To(metav1.Now()), ResourceSlices: []*apiv1.ResourceSliceRef{{Name: slice.Name}}}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	testutil.Eventually(t, func() bool {
+		require.NoError(t, client.IgnoreNotFound(cli.Get(ctx, client.ObjectKeyFromObject(quota), quota)))
+		return quota.Status.Resources == 2
+	})
+
+	// Failed syntheses fall back to the previous synthesis
+	comp.Status.PreviousSynthesis = comp.Status.CurrentSynthesis
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "failed-uuid", Synthesized: ptr.To(metav1.Now()), Results: []apiv1.Result{{Severity: "error"}}}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	testutil.Eventually(t, func() bool {
+		require.NoError(t, client.IgnoreNotFound(cli.Get(ctx, client.ObjectKeyFromObject(quota), quota)))
+		return quota.Status.Resources == 2 && quota.Status.Compositions == 1
+	})
+
+	// Deleted compositions are no longer counted
+	require.NoError(t, cli.Delete(ctx, comp))
+
+	testutil.Eventually(t, func() bool {
+		require.NoError(t, client.IgnoreNotFound(cli.Get(ctx, client.ObjectKeyFromObject(quota), quota)))
+		return quota.Status.Compositions == 0 && quota.Status.Resources == 0
+	})
+}
@@ -0,0 +1,202 @@
+package reconciliation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/manager"
+	"github.com/Azure/eno/internal/reconstitution"
+	"github.com/go-logr/logr"
+)
+
+// See https://github.com/kubernetes/enhancements/tree/master/keps/sig-cli/3659-kubectl-apply-prune
+const (
+	applySetPartOfLabel          = "applyset.kubernetes.io/part-of"
+	applySetIDLabel              = "applyset.kubernetes.io/id"
+	applySetToolingAnnotation    = "applyset.kubernetes.io/tooling"
+	applySetGroupKindsAnnotation = "applyset.kubernetes.io/contains-group-kinds"
+	applySetNamespacesAnnotation = "applyset.kubernetes.io/additional-namespaces"
+	applySetTooling              = "eno/v1"
+)
+
+var configMapGVK = schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+// applySetController maintains an ApplySet parent ConfigMap in the downstream cluster for each composition,
+// such that tools like `kubectl apply --prune --applyset` can understand the set of resources managed by Eno.
+// This also allows migrating away from Eno without losing track of which resources belong to a composition.
+type applySetController struct {
+	client    client.Client // upstream
+	parents   client.Client // downstream
+	cache     *reconstitution.Cache
+	namespace string
+}
+
+func newApplySetController(mgr ctrl.Manager, downstream client.Client, cache *reconstitution.Cache, namespace string) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("applySetController").
+		For(&apiv1.Composition{}).
+		WithLogConstructor(manager.NewLogConstructor(mgr, "applySetController")).
+		Complete(manager.WithController("applySetController", &applySetController{
+			client:    mgr.GetClient(),
+			parents:   downstream,
+			cache:     cache,
+			namespace: namespace,
+		}))
+}
+
+func (a *applySetController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	comp := &apiv1.Composition{}
+	err := a.client.Get(ctx, req.NamespacedName, comp)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	parent := newApplySetParent(comp, a.namespace)
+
+	// Orphaned resources retain their parent so they can be adopted by other tooling
+	if comp.DeletionTimestamp != nil {
+		if comp.ShouldOrphan() {
+			return ctrl.Result{}, nil
+		}
+		err = a.parents.Delete(ctx, parent)
+		if err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("deleting applyset parent: %w", err))
+		}
+		logger.V(1).Info("deleted applyset parent")
+		return ctrl.Result{}, nil
+	}
+
+	if comp.Status.CurrentSynthesis == nil || comp.Status.CurrentSynthesis.Synthesized == nil {
+		return ctrl.Result{}, nil
+	}
+	inv, ok := a.cache.GetInventory(ctx, reconstitution.NewSynthesisRef(comp))
+	if !ok {
+		return ctrl.Result{RequeueAfter: time.Second}, nil // wait for the cache to be filled
+	}
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(configMapGVK)
+	err = a.parents.Get(ctx, client.ObjectKeyFromObject(parent), current)
+	if errors.IsNotFound(err) {
+		setApplySetParentMeta(parent, inv)
+		err = a.parents.Create(ctx, parent)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("creating applyset parent: %w", err)
+		}
+		logger.V(0).Info("created applyset parent")
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("getting applyset parent: %w", err)
+	}
+
+	patch := client.MergeFrom(current.DeepCopy())
+	if !setApplySetParentMeta(current, inv) {
+		return ctrl.Result{}, nil
+	}
+	err = a.parents.Patch(ctx, current, patch)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("updating applyset parent: %w", err)
+	}
+	logger.V(0).Info("updated applyset parent")
+	return ctrl.Result{}, nil
+}
+
+func newApplySetParent(comp *apiv1.Composition, namespace string) *unstructured.Unstructured {
+	parent := &unstructured.Unstructured{}
+	parent.SetGroupVersionKind(configMapGVK)
+	parent.SetName(applySetParentName(comp))
+	parent.SetNamespace(namespace)
+	return parent
+}
+
+func applySetParentName(comp *apiv1.Composition) string {
+	return fmt.Sprintf("eno.%s.%s", comp.Namespace, comp.Name)
+}
+
+// applySetID returns the ID of the ApplySet whose parent is the given ConfigMap, as defined by the ApplySet spec.
+func applySetID(parent types.NamespacedName) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s.%s.%s.%s", parent.Name, parent.Namespace, configMapGVK.Kind, configMapGVK.Group)))
+	return fmt.Sprintf("applyset-%s-v1", base64.RawURLEncoding.EncodeToString(hash[:]))
+}
+
+// setApplySetParentMeta sets the labels and annotations of an ApplySet parent. Returns true if any changed.
+func setApplySetParentMeta(parent *unstructured.Unstructured, inv *reconstitution.Inventory) bool {
+	var changed bool
+
+	labels := parent.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	id := applySetID(types.NamespacedName{Name: parent.GetName(), Namespace: parent.GetNamespace()})
+	if labels[applySetIDLabel] != id {
+		labels[applySetIDLabel] = id
+		changed = true
+	}
+	parent.SetLabels(labels)
+
+	gks := make([]string, len(inv.GroupKinds))
+	for i, gk := range inv.GroupKinds {
+		gks[i] = gk.String()
+	}
+	var nses []string
+	for _, ns := range inv.Namespaces {
+		if ns != parent.GetNamespace() {
+			nses = append(nses, ns)
+		}
+	}
+
+	anno := parent.GetAnnotations()
+	if anno == nil {
+		anno = map[string]string{}
+	}
+	for key, val := range map[string]string{
+		applySetToolingAnnotation:    applySetTooling,
+		applySetGroupKindsAnnotation: strings.Join(gks, ","),
+		applySetNamespacesAnnotation: strings.Join(nses, ","),
+	} {
+		if anno[key] != val {
+			anno[key] = val
+			changed = true
+		}
+	}
+	parent.SetAnnotations(anno)
+
+	return changed
+}
+
+func (c *Controller) applySetID(comp *apiv1.Composition) string {
+	return applySetID(types.NamespacedName{Name: applySetParentName(comp), Namespace: c.applySetNamespace})
+}
+
+// setApplySetLabel marks the given resource as a member of the composition's ApplySet.
+func setApplySetLabel(obj *unstructured.Unstructured, id string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[applySetPartOfLabel] = id
+	obj.SetLabels(labels)
+}
+
+func addApplySetLabel(js []byte, id string) ([]byte, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(js); err != nil {
+		return nil, err
+	}
+	setApplySetLabel(obj, id)
+	return obj.MarshalJSON()
+}
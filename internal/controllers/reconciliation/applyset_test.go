@@ -0,0 +1,56 @@
+package reconciliation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/reconstitution"
+)
+
+func TestApplySetID(t *testing.T) {
+	id := applySetID(types.NamespacedName{Name: "foo", Namespace: "bar"})
+	assert.True(t, strings.HasPrefix(id, "applyset-"))
+	assert.True(t, strings.HasSuffix(id, "-v1"))
+	assert.LessOrEqual(t, len(id), 63) // must be a valid label value
+	assert.Equal(t, id, applySetID(types.NamespacedName{Name: "foo", Namespace: "bar"}))
+	assert.NotEqual(t, id, applySetID(types.NamespacedName{Name: "foo", Namespace: "baz"}))
+}
+
+func TestSetApplySetParentMeta(t *testing.T) {
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	parent := newApplySetParent(comp, "eno-system")
+	assert.Equal(t, "eno.default.test-comp", parent.GetName())
+
+	inv := &reconstitution.Inventory{
+		GroupKinds: []schema.GroupKind{{Kind: "ConfigMap"}, {Group: "apps", Kind: "Deployment"}},
+		Namespaces: []string{"eno-system", "foo"},
+	}
+	assert.True(t, setApplySetParentMeta(parent, inv))
+	assert.Equal(t, applySetID(types.NamespacedName{Name: parent.GetName(), Namespace: "eno-system"}), parent.GetLabels()[applySetIDLabel])
+	assert.Equal(t, map[string]string{
+		applySetToolingAnnotation:    "eno/v1",
+		applySetGroupKindsAnnotation: "ConfigMap,Deployment.apps",
+		applySetNamespacesAnnotation: "foo", // the parent's namespace is implied
+	}, parent.GetAnnotations())
+
+	// Idempotence
+	assert.False(t, setApplySetParentMeta(parent, inv))
+
+	// Inventory changes
+	inv.Namespaces = nil
+	assert.True(t, setApplySetParentMeta(parent, inv))
+	assert.Equal(t, "", parent.GetAnnotations()[applySetNamespacesAnnotation])
+}
+
+func TestAddApplySetLabel(t *testing.T) {
+	js, err := addApplySetLabel([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo","labels":{"a":"b"}}}`), "applyset-test-v1")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo","labels":{"a":"b","applyset.kubernetes.io/part-of":"applyset-test-v1"}}}`, string(js))
+}
@@ -0,0 +1,85 @@
+package reconciliation
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// circuitBreaker stops writes to resource types that are consistently failing server-side,
+// most commonly because an admission webhook for that type is down.
+// Writes resume after a cooldown period, and the circuit opens again on the next failure.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mut   sync.Mutex
+	state map[schema.GroupKind]*breakerState
+}
+
+type breakerState struct {
+	Failures  int
+	OpenUntil time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, state: map[schema.GroupKind]*breakerState{}}
+}
+
+// Allow returns the remaining time until writes of the given type are allowed, or zero if they are allowed now.
+func (b *circuitBreaker) Allow(gk schema.GroupKind, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	state := b.state[gk]
+	if state == nil {
+		return 0
+	}
+	return max(state.OpenUntil.Sub(now), 0)
+}
+
+// Observe records the result of writing a resource of the given type.
+func (b *circuitBreaker) Observe(gk schema.GroupKind, err error, now time.Time) (opened bool) {
+	if b == nil {
+		return false
+	}
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if !isBreakingError(err) {
+		if _, ok := b.state[gk]; ok {
+			delete(b.state, gk)
+			circuitBreakerOpen.WithLabelValues(gk.String()).Set(0)
+		}
+		return false
+	}
+
+	state := b.state[gk]
+	if state == nil {
+		state = &breakerState{}
+		b.state[gk] = state
+	}
+	state.Failures++
+	if state.Failures < b.threshold {
+		return false
+	}
+	state.OpenUntil = now.Add(b.cooldown)
+	circuitBreakerOpen.WithLabelValues(gk.String()).Set(1)
+	return true
+}
+
+// isBreakingError returns true for errors that indicate the apiserver (or one of its webhooks) can't
+// currently handle writes, as opposed to problems with the request itself.
+func isBreakingError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.IsInternalError(err) || errors.IsServiceUnavailable(err) || errors.IsTimeout(err) || errors.IsServerTimeout(err) || errors.IsTooManyRequests(err) ||
+		strings.Contains(err.Error(), "failed calling webhook")
+}
@@ -0,0 +1,47 @@
+package reconciliation
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(2, time.Minute)
+	gk := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	other := schema.GroupKind{Kind: "ConfigMap"}
+	webhookErr := fmt.Errorf("applying patch: %w", apierrors.NewInternalError(errors.New(`failed calling webhook "foo": connection refused`)))
+
+	// Client errors don't count
+	assert.False(t, b.Observe(gk, apierrors.NewBadRequest("invalid"), now))
+	assert.False(t, b.Observe(gk, apierrors.NewBadRequest("invalid"), now))
+	assert.Zero(t, b.Allow(gk, now))
+
+	// Opens at the threshold
+	assert.False(t, b.Observe(gk, webhookErr, now))
+	assert.Zero(t, b.Allow(gk, now))
+	assert.True(t, b.Observe(gk, webhookErr, now))
+	assert.Equal(t, time.Minute, b.Allow(gk, now))
+	assert.Zero(t, b.Allow(other, now))
+
+	// Allows a retry after the cooldown, which re-opens the circuit if it fails
+	assert.Zero(t, b.Allow(gk, now.Add(time.Minute)))
+	assert.True(t, b.Observe(gk, webhookErr, now.Add(time.Minute)))
+	assert.Equal(t, time.Second*30, b.Allow(gk, now.Add(time.Second*90)))
+
+	// Closes after a success
+	assert.False(t, b.Observe(gk, nil, now.Add(time.Minute*2)))
+	assert.Zero(t, b.Allow(gk, now.Add(time.Minute*2)))
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	var b *circuitBreaker
+	assert.False(t, b.Observe(schema.GroupKind{}, apierrors.NewServiceUnavailable("down"), time.Now()))
+	assert.Zero(t, b.Allow(schema.GroupKind{}, time.Now()))
+}
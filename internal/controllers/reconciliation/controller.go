@@ -4,20 +4,26 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"maps"
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kube-openapi/pkg/util/proto"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -26,32 +32,139 @@ import (
 	apiv1 "github.com/Azure/eno/api/v1"
 	"github.com/Azure/eno/internal/discovery"
 	"github.com/Azure/eno/internal/flowcontrol"
+	"github.com/Azure/eno/internal/freeze"
+	"github.com/Azure/eno/internal/manager"
+	"github.com/Azure/eno/internal/readiness"
 	"github.com/Azure/eno/internal/reconstitution"
+	"github.com/Azure/eno/pkg/faults"
 	"github.com/go-logr/logr"
 )
 
-var insecureLogPatch = os.Getenv("INSECURE_LOG_PATCH") == "true"
-
 type Options struct {
 	Manager     ctrl.Manager
 	Cache       *reconstitution.Cache
 	WriteBuffer *flowcontrol.ResourceSliceWriteBuffer
 	Downstream  *rest.Config
 
-	DiscoveryRPS float32
+	// HealthProbeInterval is the interval at which the downstream apiserver is probed.
+	// Reconciliation is paused after HealthProbeThreshold consecutive failures, and resumes once a probe succeeds.
+	// Zero disables the health gate.
+	HealthProbeInterval  time.Duration
+	HealthProbeThreshold int
+
+	// Writes of a resource type are paused for CircuitBreakerCooldown after CircuitBreakerThreshold consecutive
+	// server-side failures e.g. because its admission webhook is unavailable. Zero threshold disables the breaker.
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+
+	// ListGroupKinds are the resource types whose current state is read using a single LIST per namespace
+	// (filtered by ListSelector) and shared for ListTTL, instead of a GET per resource.
+	ListGroupKinds []schema.GroupKind
+	ListSelector   labels.Selector
+	ListTTL        time.Duration
 
 	Timeout               time.Duration
 	ReadinessPollInterval time.Duration
+
+	// AdmissionSettleDuration is how long webhook configurations and APIServices must have been ready before
+	// later readiness groups are reconciled, unless they set the settle duration annotation. Zero disables the delay.
+	AdmissionSettleDuration time.Duration
+
+	// OwnerAnnotations enables setting annotations on reconciled resources that identify their composition.
+	OwnerAnnotations bool
+
+	// ApplySetNamespace enables maintaining an ApplySet for each composition, with a parent ConfigMap in this
+	// downstream namespace. Reconciled resources are labeled as members of their composition's ApplySet.
+	ApplySetNamespace string
+
+	// StatusMirrorNamespace enables maintaining a ConfigMap that summarizes the status of each composition
+	// in this downstream namespace, for users without access to the upstream cluster.
+	StatusMirrorNamespace string
+
+	// LogPatchGroupKinds are the resource types for which full patch contents will be logged.
+	// Only the modified field paths are logged for other types, to avoid leaking sensitive values.
+	LogPatchGroupKinds []schema.GroupKind
+
+	// PatchStrategies override the patch strategy of particular resource types.
+	// SchemalessPatchStrategy is used for types without a usable openapi model e.g. those served by some aggregated apiservers.
+	// Defaults to strategic merge for types with a model, and merge patches otherwise.
+	PatchStrategies         map[schema.GroupKind]PatchStrategy
+	SchemalessPatchStrategy PatchStrategy
+
+	// ListMergeKeys cause merge patches to merge the items of particular lists by key instead of replacing them.
+	ListMergeKeys ListMergeKeys
+
+	// Dependencies resolves the readiness of resources in other compositions that resources depend on.
+	// Defaults to the reconstitution cache, which only knows about compositions reconciled by this process.
+	Dependencies DependencyResolver
+
+	// Resources with more than ManagedFieldsThreshold managedFields entries are reported, and their managedFields
+	// are reset when ManagedFieldsCleanup is set. Zero disables the threshold.
+	ManagedFieldsThreshold int
+	ManagedFieldsCleanup   bool
+
+	// Profiles adjust reconciliation behavior for particular compositions. Optional.
+	Profiles *Profiles
+
+	// Protobuf enables reading native resource types from the downstream apiserver using protobuf instead of json.
+	Protobuf bool
+
+	// SecretRefs enables resolving $(secret:namespace/name/key) placeholders in synthesized manifests from Secrets
+	// in the composition's namespace when resources are applied, so secret values aren't written to resource slices.
+	SecretRefs bool
+
+	// Faults are injected into downstream writes, discovery, and status patches. Only set by tests.
+	Faults faults.Injector
+}
+
+// DependencyResolver determines whether resources managed by other compositions are ready.
+type DependencyResolver interface {
+	DependencyReady(ctx context.Context, dep *reconstitution.Dependency) (bool, error)
+}
+
+// schemaGetter returns the openapi schema of a type, or nil if it doesn't support strategic merge.
+// Implemented by discovery.Cache.
+type schemaGetter interface {
+	Get(ctx context.Context, gvk schema.GroupVersionKind) (proto.Schema, error)
+}
+
+// requirementPollInterval is how often resources are retried while the downstream cluster doesn't meet their requirements.
+const requirementPollInterval = time.Minute
+
+// capabilityChecker determines whether the downstream cluster meets the requirements of resources.
+// Implemented by discovery.Cache.
+type capabilityChecker interface {
+	ServerVersion(ctx context.Context) (*version.Version, error)
+	Serves(ctx context.Context, gvk schema.GroupVersionKind) (bool, error)
 }
 
 type Controller struct {
-	client                client.Client
-	writeBuffer           *flowcontrol.ResourceSliceWriteBuffer
-	resourceClient        reconstitution.Client
-	timeout               time.Duration
-	readinessPollInterval time.Duration
-	upstreamClient        client.Client
-	discovery             *discovery.Cache
+	client                 client.Client
+	writeBuffer            *flowcontrol.ResourceSliceWriteBuffer
+	resourceClient         reconstitution.Client
+	timeout                time.Duration
+	readinessPollInterval  time.Duration
+	admissionSettle        time.Duration
+	upstreamClient         client.Client
+	discovery              schemaGetter
+	capabilities           capabilityChecker
+	logPatchGroupKinds     map[schema.GroupKind]struct{}
+	patchStrategies        *patchStrategies
+	listMergeKeys          ListMergeKeys
+	ownerAnnotations       bool
+	applySetNamespace      string
+	health                 *healthGate
+	breaker                *circuitBreaker
+	lists                  *listCache
+	protobuf               *protobufGetter
+	dependencies           DependencyResolver
+	profiles               *Profiles
+	managedFieldsThreshold int
+	managedFieldsCleanup   bool
+	recorder               record.EventRecorder
+	faults                 faults.Injector
+	fieldManager           string
+	secretReader           client.Reader
 }
 
 func New(opts Options) (*Controller, error) {
@@ -62,32 +175,132 @@ func New(opts Options) (*Controller, error) {
 		return nil, err
 	}
 
-	disc, err := discovery.NewCache(opts.Downstream, opts.DiscoveryRPS)
+	disc, err := discovery.NewCache(opts.Downstream)
 	if err != nil {
 		return nil, err
 	}
+	disc.Faults = opts.Faults
+	if opts.WriteBuffer != nil {
+		opts.WriteBuffer.Faults = opts.Faults
+	}
+
+	var health *healthGate
+	if opts.HealthProbeInterval > 0 {
+		health, err = newHealthGate(opts.Downstream, opts.HealthProbeInterval, opts.HealthProbeThreshold)
+		if err != nil {
+			return nil, err
+		}
+		if err := opts.Manager.Add(health); err != nil {
+			return nil, err
+		}
+	}
+
+	var breaker *circuitBreaker
+	if opts.CircuitBreakerThreshold > 0 {
+		breaker = newCircuitBreaker(opts.CircuitBreakerThreshold, opts.CircuitBreakerCooldown)
+	}
+
+	var lists *listCache
+	if len(opts.ListGroupKinds) > 0 {
+		lists = newListCache(upstreamClient, opts.ListGroupKinds, opts.ListSelector, opts.ListTTL)
+	}
+
+	var protobuf *protobufGetter
+	if opts.Protobuf {
+		protobuf, err = newProtobufGetter(opts.Downstream)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.ApplySetNamespace != "" {
+		err = newApplySetController(opts.Manager, upstreamClient, opts.Cache, opts.ApplySetNamespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.StatusMirrorNamespace != "" {
+		err = newStatusMirrorController(opts.Manager, upstreamClient, opts.StatusMirrorNamespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deps := opts.Dependencies
+	if deps == nil && opts.Cache != nil {
+		deps = opts.Cache
+	}
+
+	var secretReader client.Reader
+	if opts.SecretRefs {
+		secretReader = opts.Manager.GetAPIReader() // avoid caching every secret in the cluster
+	}
+
+	logPatchGKs := map[schema.GroupKind]struct{}{}
+	for _, gk := range opts.LogPatchGroupKinds {
+		logPatchGKs[gk] = struct{}{}
+	}
 
 	return &Controller{
-		client:                opts.Manager.GetClient(),
-		writeBuffer:           opts.WriteBuffer,
-		resourceClient:        opts.Cache,
-		timeout:               opts.Timeout,
-		readinessPollInterval: opts.ReadinessPollInterval,
-		upstreamClient:        upstreamClient,
-		discovery:             disc,
+		client:                 opts.Manager.GetClient(),
+		writeBuffer:            opts.WriteBuffer,
+		resourceClient:         opts.Cache,
+		timeout:                opts.Timeout,
+		readinessPollInterval:  opts.ReadinessPollInterval,
+		admissionSettle:        opts.AdmissionSettleDuration,
+		upstreamClient:         upstreamClient,
+		discovery:              disc,
+		capabilities:           disc,
+		logPatchGroupKinds:     logPatchGKs,
+		patchStrategies:        newPatchStrategies(opts.PatchStrategies, opts.SchemalessPatchStrategy),
+		listMergeKeys:          opts.ListMergeKeys,
+		ownerAnnotations:       opts.OwnerAnnotations,
+		applySetNamespace:      opts.ApplySetNamespace,
+		health:                 health,
+		breaker:                breaker,
+		lists:                  lists,
+		protobuf:               protobuf,
+		dependencies:           deps,
+		profiles:               opts.Profiles,
+		managedFieldsThreshold: opts.ManagedFieldsThreshold,
+		managedFieldsCleanup:   opts.ManagedFieldsCleanup,
+		recorder:               opts.Manager.GetEventRecorderFor("eno-reconciler"),
+		faults:                 opts.Faults,
+		fieldManager:           fieldManagerForUserAgent(opts.Downstream.UserAgent),
+		secretReader:           secretReader,
 	}, nil
 }
 
 func (c *Controller) Reconcile(ctx context.Context, req *reconstitution.Request) (ctrl.Result, error) {
+	result, err := c.reconcile(ctx, req)
+	if err != nil {
+		requeues.WithLabelValues("error").Inc()
+	}
+	return result, err
+}
+
+func (c *Controller) reconcile(ctx context.Context, req *reconstitution.Request) (ctrl.Result, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
+	// Don't bother hitting a downstream apiserver that is known to be unhealthy.
+	// Requeues are spread over the probe interval to avoid a thundering herd once it recovers.
+	if !c.health.Healthy() {
+		logr.FromContextOrDiscard(ctx).V(1).Info("skipping because the downstream apiserver is unhealthy")
+		requeues.WithLabelValues("downstream-unhealthy").Inc()
+		return ctrl.Result{RequeueAfter: wait.Jitter(c.health.interval, 1)}, nil
+	}
+
 	comp := &apiv1.Composition{}
 	err := c.client.Get(ctx, types.NamespacedName{Name: req.Composition.Name, Namespace: req.Composition.Namespace}, comp)
 	if err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("getting composition: %w", err))
 	}
 	logger := logr.FromContextOrDiscard(ctx).WithValues("compositionGeneration", comp.Generation)
+	if manager.DebugLoggingEnabled(comp, time.Now()) {
+		logger = manager.WithDebugLogging(logger)
+	}
 
 	if comp.Status.CurrentSynthesis == nil || comp.Status.CurrentSynthesis.Failed() {
 		return ctrl.Result{}, nil // nothing to do
@@ -132,15 +345,13 @@ func (c *Controller) Reconcile(ctx context.Context, req *reconstitution.Request)
 	// i.e. ordering is necessary to handle adding a new property and populating it in the same synthesis.
 	crdResource, ok := c.resourceClient.GetDefiningCRD(ctx, synRef, resource.GVK.GroupKind())
 	if ok {
-		slice := &apiv1.ResourceSlice{}
-		err = c.client.Get(ctx, crdResource.ManifestRef.Slice, slice)
+		status, err := c.getResourceState(ctx, synRef, crdResource)
 		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("getting resource slice: %w", err)
+			return ctrl.Result{}, err
 		}
-		status := crdResource.FindStatus(slice)
 		if status == nil || status.Ready == nil {
 			logger.V(1).Info("skipping because the CRD that defines this resource type isn't ready")
-			return ctrl.Result{}, nil
+			return explain(resource, &reconstitution.Explanation{Decision: "WaitingForCRD", WaitingOnCRD: crdResource.Ref.Name}, ctrl.Result{}), nil
 		}
 
 		// apiserver doesn't "close the loop" on CRD loading, so there is no way to know
@@ -148,60 +359,259 @@ func (c *Controller) Reconcile(ctx context.Context, req *reconstitution.Request)
 		// but we round up to a full second here to be safe.
 		if delta := time.Second - time.Since(status.Ready.Time); delta > 0 {
 			logger.V(1).Info("deferring until the defining CRD has been ready for 1 second")
-			return ctrl.Result{RequeueAfter: delta}, nil
+			return explain(resource, &reconstitution.Explanation{Decision: "WaitingForCRD", WaitingOnCRD: crdResource.Ref.Name}, ctrl.Result{RequeueAfter: delta}), nil
+		}
+	}
+
+	status, err := c.getResourceState(ctx, synRef, resource)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Evaluate the readiness of resources in the previous readiness group.
+	// This only depends on cached state, so it's done before reading the current resource to avoid wasted requests.
+	if (status == nil || !status.Reconciled) && !resource.Deleted() {
+		if group, ready := c.resourceClient.PreviousReadinessGroupReady(ctx, synRef, resource.ReadinessGroup); !ready {
+			logger.V(1).Info("skipping because at least one resource in an earlier readiness group isn't ready yet")
+			return explain(resource, &reconstitution.Explanation{Decision: "WaitingForReadinessGroup", WaitingOnReadinessGroup: ptr.To(group)}, ctrl.Result{}), nil
+		}
+		if result, waiting, err := c.waitForSettle(ctx, synRef, resource); err != nil || waiting {
+			return result, err
+		}
+		if tier, ok := c.resourceClient.PrecedingKindTiersReconciled(ctx, synRef, resource); !ok {
+			logger.V(1).Info("skipping because at least one resource of a kind that is applied first hasn't been reconciled yet")
+			return explain(resource, &reconstitution.Explanation{Decision: "WaitingForKindOrder", WaitingOnKindTier: ptr.To(tier)}, ctrl.Result{}), nil
+		}
+		if !c.resourceClient.PrecedingHookStagesReady(ctx, synRef, resource) {
+			logger.V(1).Info("skipping because at least one resource in an earlier hook stage of the readiness group isn't ready yet")
+			return explain(resource, &reconstitution.Explanation{Decision: "WaitingForHook"}, ctrl.Result{}), nil
+		}
+
+		// Other compositions don't trigger reconciliation of this one, so poll until dependencies are ready
+		dep, err := c.firstUnreadyDependency(ctx, comp, resource)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if dep != nil {
+			logger.V(1).Info("skipping because a resource of another composition that this resource depends on isn't ready yet", "dependency", dep.String())
+			return explain(resource, &reconstitution.Explanation{Decision: "WaitingForDependency", WaitingOnDependency: dep.String()}, ctrl.Result{RequeueAfter: wait.Jitter(c.readinessPollInterval, 0.1)}), nil
+		}
+
+		// Resources can declare the API versions, kinds, or Kubernetes version they need instead of failing until they're available
+		req, err := c.firstUnmetRequirement(ctx, resource)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if req != nil {
+			logger.V(1).Info("skipping because the downstream cluster doesn't meet one of the resource's requirements", "requirement", req.String())
+			c.writeBuffer.PatchStatusAsync(ctx, &resource.ManifestRef, patchResourceMessage(fmt.Sprintf("downstream cluster doesn't meet requirement %s", req.String())))
+			return explain(resource, &reconstitution.Explanation{Decision: "MissingRequirement", MissingRequirement: req.String()}, ctrl.Result{RequeueAfter: wait.Jitter(requirementPollInterval, 0.1)}), nil
+		}
+	}
+
+	// Changes are deferred during the composition's freeze windows, but resources that have already been reconciled are still observed.
+	// Deleting the composition is an explicit request, so it isn't blocked.
+	if (status == nil || !status.Reconciled) && comp.DeletionTimestamp == nil {
+		if until, frozen := freeze.ForComposition(comp, time.Now()); frozen {
+			logger.V(1).Info("skipping because the composition is within a freeze window", "frozenUntil", until)
+			return explain(resource, &reconstitution.Explanation{Decision: "Frozen", FrozenUntil: &until}, ctrl.Result{RequeueAfter: time.Until(until) + wait.Jitter(time.Second, 10)}), nil
 		}
 	}
 
 	// Fetch the current resource
-	current, hasChanged, err := c.getCurrent(ctx, resource)
+	name := resolveName(resource, status)
+	current, hasChanged, err := c.getCurrent(ctx, resource, name)
 	if client.IgnoreNotFound(err) != nil && !isErrMissingNS(err) {
 		return ctrl.Result{}, fmt.Errorf("getting current state: %w", err)
 	}
+	if hasChanged && current != nil {
+		if err := c.checkManagedFields(ctx, comp, resource, current); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Users can suspend updates of individual resources e.g. during break-glass operations
+	var handsOffUntil *metav1.Time
+	if current != nil && !resource.Deleted() && comp.DeletionTimestamp == nil {
+		handsOffUntil, err = reconstitution.HandsOffUntil(current, time.Now())
+		if err != nil {
+			logger.V(0).Info("ignoring invalid hands-off annotation", "error", err.Error())
+		}
+	} else if !hasChanged && status != nil && status.HandsOffUntil != nil && status.HandsOffUntil.After(time.Now()) {
+		handsOffUntil = status.HandsOffUntil // the resource hasn't changed since the annotation was observed
+	}
+	if handsOffUntil != nil && hasChanged {
+		logger.V(1).Info("not updating resource because it has been marked hands-off", "handsOffUntil", handsOffUntil.Time)
+		hasChanged = false
+	}
+
+	// Hooks run once per synthesis, so Jobs left behind by earlier syntheses are replaced
+	if resource.Hook != "" && !resource.Deleted() && (status == nil || status.Ready == nil) && current != nil && current.GetAnnotations()[reconstitution.HookSynthesisAnnotation] != synRef.UUID {
+		if current.GetDeletionTimestamp() == nil {
+			if err := c.deleteHook(ctx, resource, current); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		logger.V(1).Info("waiting for hook from an earlier synthesis to be deleted")
+		return explain(resource, &reconstitution.Explanation{Decision: "ReplacingHook"}, ctrl.Result{RequeueAfter: wait.Jitter(time.Second, 1)}), nil
+	}
+
+	// Expired resources have already been deleted, and aren't recreated until the next synthesis
+	expired := resource.ExpiresAfter != nil && !resource.Deleted() && status != nil && status.Expired
+	if expired {
+		hasChanged = false
+	}
 
 	// Evaluate resource readiness
 	// - Readiness checks are skipped when this version of the resource's desired state has already become ready
 	// - Readiness checks are skipped when the resource hasn't changed since the last check
 	// - Readiness defaults to true if no checks are given
-	slice := &apiv1.ResourceSlice{}
-	err = c.client.Get(ctx, resource.ManifestRef.Slice, slice)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("getting resource slice: %w", err)
-	}
+	// - Resources that expired before becoming ready aren't waited on
 	var ready *metav1.Time
-	status := resource.FindStatus(slice)
-	if status == nil || status.Ready == nil {
-		readiness, ok := resource.ReadinessChecks.EvalOptionally(ctx, current)
+	var readinessMsg string
+	var degraded bool
+	if expired {
+		ready = status.Ready
+		if ready == nil {
+			ready = status.ExpiresAt
+		}
+	} else if status == nil || status.Ready == nil {
+		readiness, ok := resource.EvalReadiness(ctx, current)
+		if ok && !resource.Deleted() && len(resource.WaitForDeletion) > 0 {
+			ref, err := c.firstUndeletedRef(ctx, resource)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if ref != nil {
+				ok = false
+				readinessMsg = fmt.Sprintf("waiting for deletion of %s", ref.String())
+			}
+		}
 		if ok {
 			ready = &readiness.ReadyTime
+		} else if !resource.Deleted() && readinessMsg == "" {
+			readinessMsg = buildReadinessMessage(resource, current)
 		}
 	} else {
 		ready = status.Ready
+
+		// Readiness latches, but regressions are still reported
+		degraded = status.Degraded
+		readinessMsg = status.Message
+		if hasChanged && !resource.Deleted() && resource.Patch == nil && resource.Hook == "" {
+			_, ok := resource.ReadinessChecks.EvalOptionally(ctx, current)
+			degraded = current == nil || !ok
+			readinessMsg = ""
+			if degraded {
+				readinessMsg = buildReadinessMessage(resource, current)
+			}
+			if degraded && !status.Degraded {
+				c.reportRegression(comp, resource, readinessMsg)
+			}
+			if degraded && resource.ContinuousReadiness {
+				ready = nil // readiness doesn't latch
+				degraded = false
+			}
+		}
 	}
 
-	// Evaluate the readiness of resources in the previous readiness group
-	if (status == nil || !status.Reconciled) && !resource.Deleted() {
-		dependencies := c.resourceClient.RangeByReadinessGroup(ctx, synRef, resource.ReadinessGroup, reconstitution.RangeDesc)
-		for _, dep := range dependencies {
-			slice := &apiv1.ResourceSlice{}
-			err = c.client.Get(ctx, dep.ManifestRef.Slice, slice)
-			if err != nil {
-				return ctrl.Result{}, fmt.Errorf("getting resource slice: %w", err)
+	// Protected resources are never deleted - report the blocked deletion in status instead
+	blocked := resource.Deleted() && current != nil && current.GetDeletionTimestamp() == nil && !comp.ShouldOrphan() && resource.IsProtected(current)
+	if blocked && hasChanged {
+		logger.V(0).Info("refusing to delete protected resource")
+	}
+
+	// Hooks that have already succeeded for this synthesis aren't re-run if their Job is removed
+	if resource.Hook != "" && !resource.Deleted() && ready != nil {
+		if current != nil && current.GetDeletionTimestamp() == nil && resource.HookDeletePolicy == reconstitution.HookDeleteSucceeded {
+			if err := c.deleteHook(ctx, resource, current); err != nil {
+				return ctrl.Result{}, err
 			}
-			status := dep.FindStatus(slice)
-			if status == nil || status.Ready == nil {
-				logger.V(1).Info("skipping because at least one resource in an earlier readiness group isn't ready yet")
-				return ctrl.Result{}, nil
+		}
+		hasChanged = false
+	}
+
+	// Delete resources once their eno.azure.io/expires-after duration has elapsed
+	var expiresAt *metav1.Time
+	if expired {
+		expiresAt = status.ExpiresAt
+	} else if resource.ExpiresAfter != nil && !resource.Deleted() && resource.Patch == nil && comp.DeletionTimestamp == nil {
+		var created *metav1.Time
+		if current != nil {
+			created = ptr.To(current.GetCreationTimestamp())
+		}
+		expiresAt = resource.ExpiresAt(created, ready)
+		if expiresAt == nil && status != nil {
+			expiresAt = status.ExpiresAt // nil current struct means the resource hasn't changed since it was last observed
+		}
+		if expiresAt != nil && !expiresAt.After(time.Now()) && !resource.IsProtected(current) {
+			if err := c.deleteExpired(ctx, resource, name); err != nil {
+				return ctrl.Result{}, err
 			}
+			expired = true
+			hasChanged = false
+			if ready == nil {
+				ready = expiresAt
+				readinessMsg = ""
+			}
+		}
+	}
+
+	// Resources that have already been reconciled for this synthesis are left alone when drift correction is disabled
+	profile := c.profiles.For(comp)
+	if hasChanged && !profile.CorrectsDrift() && status != nil && status.Reconciled && !resource.Deleted() && comp.DeletionTimestamp == nil {
+		logger.V(1).Info("not correcting drift because it's disabled by the composition's profile")
+		hasChanged = false
+	}
+
+	// Changes made by other clients (e.g. break-glass edits) can be left in place for a grace period before they're reverted
+	var editor *metav1.ManagedFieldsEntry
+	if hasChanged && !blocked && current != nil && status != nil && status.Reconciled && !resource.Deleted() && resource.Patch == nil && comp.DeletionTimestamp == nil {
+		var remaining time.Duration
+		editor, remaining = c.concurrentEdit(comp, current, time.Now())
+		if remaining > 0 {
+			c.reportConcurrentEdit(ctx, comp, resource, editor, "backoff", remaining)
+			return explain(resource, &reconstitution.Explanation{Decision: "ConcurrentEditBackoff"}, ctrl.Result{RequeueAfter: remaining}), nil
 		}
 	}
 
 	// Nil current struct means the resource version hasn't changed since it was last observed
 	// Skip without logging since this is a very hot path
 	var modified bool
-	if hasChanged {
-		resource.ObserveVersion("") // in case reconciliation fails, invalidate the cache first to avoid skipping the next attempt
+	if hasChanged && !blocked {
+		gk := resource.GVK.GroupKind()
+		if remaining := c.breaker.Allow(gk, time.Now()); remaining > 0 {
+			logger.V(1).Info("skipping because the circuit breaker is open for this resource type")
+			return explain(resource, &reconstitution.Explanation{Decision: "CircuitOpen"}, ctrl.Result{RequeueAfter: remaining}), nil
+		}
+		if !profile.TryAcquireWrite() {
+			logger.V(1).Info("skipping because the composition's profile has reached its concurrent write limit")
+			return explain(resource, &reconstitution.Explanation{Decision: "ProfileWriteLimit"}, ctrl.Result{RequeueAfter: wait.Jitter(time.Second, 1)}), nil
+		}
+
+		seen := resource.HasBeenSeen() // i.e. the resource version changed since it was last observed
+		resource.ObserveVersion("")    // in case reconciliation fails, invalidate the cache first to avoid skipping the next attempt
 		modified, err = c.reconcileResource(ctx, comp, prev, resource, current)
+		profile.ReleaseWrite()
+		if modified && editor != nil {
+			c.reportConcurrentEdit(ctx, comp, resource, editor, "takeover", 0)
+		}
+		if errors.IsConflict(err) && comp.ConcurrentEditWarningsEnabled() && c.recorder != nil {
+			c.recorder.Event(comp, corev1.EventTypeWarning, "ConcurrentEdit", fmt.Sprintf("%s was modified by another client while it was being reconciled", resourceName(resource)))
+		}
+		if modified {
+			resource.ObserveNoopPatch(false)
+		} else if err == nil && seen && current != nil && !resource.Deleted() && resource.Patch == nil && !resource.DisableUpdates {
+			c.reportNoopPatch(ctx, resource, current)
+		}
+		if modified || err != nil {
+			c.lists.Invalidate(resource.GVK, types.NamespacedName{Name: name, Namespace: resource.Ref.Namespace})
+		}
+		if c.breaker.Observe(gk, err, time.Now()) {
+			logger.Error(err, "opening circuit breaker because writes to this resource type are consistently failing", "groupKind", gk.String())
+		}
 		if err != nil {
+			explain(resource, &reconstitution.Explanation{Decision: "Error"}, ctrl.Result{})
 			return ctrl.Result{}, err
 		}
 	}
@@ -209,24 +619,240 @@ func (c *Controller) Reconcile(ctx context.Context, req *reconstitution.Request)
 	// We requeue to make sure the resource is in sync before updating our cache's resource version
 	// Otherwise the next sync would just hit the cache without actually diffing the resource.
 	if modified {
-		return ctrl.Result{Requeue: true}, nil
+		return explain(resource, &reconstitution.Explanation{Decision: "Modified"}, ctrl.Result{Requeue: true}), nil
 	}
-	if current != nil {
+	if current != nil && handsOffUntil == nil { // the resource needs to be diffed again once it's no longer hands-off
 		if rv := current.GetResourceVersion(); rv != "" {
 			resource.ObserveVersion(rv)
 		}
 	}
 
+	// Extract exported values once the resource is ready
+	// - Nil current struct usually means the resource hasn't changed since the values were last extracted
+	var exports map[string]string
+	if ready != nil && !resource.Deleted() && len(resource.Exports) > 0 {
+		if current != nil {
+			exports = resource.Exports.Eval(ctx, current)
+		} else if status != nil {
+			exports = status.Exports
+		}
+	}
+
 	// Store the results
-	deleted := current == nil || current.GetDeletionTimestamp() != nil
-	c.writeBuffer.PatchStatusAsync(ctx, &resource.ManifestRef, patchResourceState(deleted, ready))
+	deleted := current == nil || current.GetDeletionTimestamp() != nil || expired
+	c.writeBuffer.PatchStatusAsync(ctx, &resource.ManifestRef, patchResourceState(&apiv1.ResourceState{Deleted: deleted, Ready: ready, Message: readinessMsg, Blocked: blocked, Degraded: degraded, GeneratedName: resource.GeneratedName(), HandsOffUntil: handsOffUntil, Exports: exports, ExpiresAt: expiresAt, Expired: expired}))
+	explanation := &reconstitution.Explanation{Decision: "InSync", Ready: ready, ReadinessMessage: readinessMsg}
+	if blocked {
+		explanation.Decision = "DeletionBlocked"
+	}
 	if ready == nil {
-		return ctrl.Result{RequeueAfter: wait.Jitter(c.readinessPollInterval, 0.1)}, nil
+		explanation.Decision = "WaitingForReadiness"
+		return explain(resource, explanation, ctrl.Result{RequeueAfter: wait.Jitter(c.readinessPollInterval, 0.1)}), nil
 	}
+	if degraded {
+		explanation.Decision = "Degraded"
+		return explain(resource, explanation, ctrl.Result{RequeueAfter: wait.Jitter(c.readinessPollInterval, 0.1)}), nil
+	}
+	if handsOffUntil != nil {
+		explanation.Decision = "HandsOff"
+		return explain(resource, explanation, ctrl.Result{RequeueAfter: time.Until(handsOffUntil.Time) + wait.Jitter(time.Second, 1)}), nil
+	}
+	result := ctrl.Result{}
 	if resource != nil && !resource.Deleted() && resource.ReconcileInterval != nil {
-		return ctrl.Result{RequeueAfter: wait.Jitter(resource.ReconcileInterval.Duration, 0.1)}, nil
+		result.RequeueAfter = wait.Jitter(profile.ScaleInterval(resource.ReconcileInterval.Duration), 0.1)
+	}
+	if expiresAt != nil && !expired && expiresAt.After(time.Now()) {
+		if remaining := time.Until(expiresAt.Time) + wait.Jitter(time.Second, 1); result.RequeueAfter == 0 || remaining < result.RequeueAfter {
+			result.RequeueAfter = remaining
+		}
+	}
+	return explain(resource, explanation, result), nil
+}
+
+// resolveName returns the name of the resource, which isn't known until it's been created for resources that use generateName.
+func resolveName(resource *reconstitution.Resource, status *apiv1.ResourceState) string {
+	if resource.GenerateName == "" {
+		return resource.Ref.Name
+	}
+	if name := resource.GeneratedName(); name != "" {
+		return name
+	}
+	if status != nil && status.GeneratedName != "" {
+		resource.ObserveGeneratedName(status.GeneratedName)
+		return status.GeneratedName
+	}
+	return ""
+}
+
+// getResourceState returns the resource's status from the reconstitution cache, falling back to reading its slice.
+func (c *Controller) getResourceState(ctx context.Context, syn *reconstitution.SynthesisRef, res *reconstitution.Resource) (*apiv1.ResourceState, error) {
+	if state, ok := c.resourceClient.GetStatus(ctx, syn, res); ok {
+		return state, nil
+	}
+	sliceStatusCacheMisses.Inc()
+
+	slice := &apiv1.ResourceSlice{}
+	err := c.client.Get(ctx, res.ManifestRef.Slice, slice)
+	if err != nil {
+		return nil, fmt.Errorf("getting resource slice: %w", err)
+	}
+	return res.FindStatus(slice), nil
+}
+
+// waitForSettle defers reconciliation of the resource until resources in the previous readiness group have settled.
+// The returned bool is true when the resource should wait, in which case the result requeues it once they have.
+func (c *Controller) waitForSettle(ctx context.Context, syn *reconstitution.SynthesisRef, res *reconstitution.Resource) (ctrl.Result, bool, error) {
+	settling, remaining, err := c.previousGroupSettling(ctx, syn, res)
+	if err != nil {
+		return ctrl.Result{}, false, err
+	}
+	if remaining <= 0 {
+		return ctrl.Result{}, false, nil
+	}
+	logr.FromContextOrDiscard(ctx).V(1).Info("deferring until resources in the previous readiness group have settled", "settlingResource", resourceName(settling))
+	return explain(res, &reconstitution.Explanation{Decision: "WaitingForSettle", WaitingOnSettle: resourceName(settling)}, ctrl.Result{RequeueAfter: remaining}), true, nil
+}
+
+// previousGroupSettling returns the resource of the previous readiness group that has been ready for the least time
+// relative to its settle duration, along with how long it has left to settle. Admission webhooks and aggregated
+// apiservers generally take a moment to start serving after they're registered, so later resources would otherwise be
+// rejected by (or fail open on) admission infrastructure that isn't ready yet.
+func (c *Controller) previousGroupSettling(ctx context.Context, syn *reconstitution.SynthesisRef, res *reconstitution.Resource) (*reconstitution.Resource, time.Duration, error) {
+	var settling *reconstitution.Resource
+	var remaining time.Duration
+	for _, prev := range c.resourceClient.RangeByReadinessGroup(ctx, syn, res.ReadinessGroup, reconstitution.RangeDesc) {
+		settle := c.settleDuration(prev)
+		if settle <= 0 || prev.Deleted() {
+			continue
+		}
+		status, err := c.getResourceState(ctx, syn, prev)
+		if err != nil {
+			return nil, 0, err
+		}
+		if status == nil || status.Ready == nil {
+			continue // the readiness group gate covers this case
+		}
+		if delta := settle - time.Since(status.Ready.Time); delta > remaining {
+			settling = prev
+			remaining = delta
+		}
+	}
+	return settling, remaining, nil
+}
+
+// settleDuration returns how long the given resource must have been ready before the next readiness group is reconciled.
+func (c *Controller) settleDuration(res *reconstitution.Resource) time.Duration {
+	if res.SettleDuration != nil {
+		return *res.SettleDuration
+	}
+	if reconstitution.IsAdmissionKind(res.GVK.GroupKind()) {
+		return c.admissionSettle
+	}
+	return 0
+}
+
+// firstUnreadyDependency returns the first of the resource's cross-composition dependencies that isn't ready, or nil if they all are.
+func (c *Controller) firstUnreadyDependency(ctx context.Context, comp *apiv1.Composition, res *reconstitution.Resource) (*reconstitution.Dependency, error) {
+	for _, dep := range res.Dependencies {
+		if dep.CompositionNamespace == "" {
+			dep.CompositionNamespace = comp.Namespace
+		}
+		ready, err := c.dependencies.DependencyReady(ctx, &dep)
+		if err != nil {
+			return nil, fmt.Errorf("checking readiness of dependency %s: %w", dep.String(), err)
+		}
+		if !ready {
+			return &dep, nil
+		}
+	}
+	return nil, nil
+}
+
+// firstUnmetRequirement returns the first of the resource's requirements that the downstream cluster doesn't meet, or nil if they're all met.
+func (c *Controller) firstUnmetRequirement(ctx context.Context, res *reconstitution.Resource) (*reconstitution.Requirement, error) {
+	for i := range res.Requires {
+		req := &res.Requires[i]
+		if req.MinVersion != nil {
+			v, err := c.capabilities.ServerVersion(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("getting downstream version: %w", err)
+			}
+			if !v.AtLeast(req.MinVersion) {
+				return req, nil
+			}
+			continue
+		}
+
+		ok, err := c.capabilities.Serves(ctx, req.GroupVersion.WithKind(req.Kind))
+		if err != nil {
+			return nil, fmt.Errorf("discovering downstream capabilities: %w", err)
+		}
+		if !ok {
+			return req, nil
+		}
+	}
+	return nil, nil
+}
+
+// firstUndeletedRef returns the first object that the resource waits to be deleted that still exists, or nil if none of them do.
+// Objects that are being deleted (have a deletion timestamp) still exist.
+func (c *Controller) firstUndeletedRef(ctx context.Context, res *reconstitution.Resource) (*reconstitution.DeletionRef, error) {
+	for _, ref := range res.WaitForDeletion {
+		obj := &metav1.PartialObjectMetadata{}
+		obj.Name = ref.Name
+		obj.Namespace = ref.Namespace
+		obj.Kind = ref.Kind
+		obj.APIVersion = ref.APIVersion
+
+		err := c.upstreamClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue // the type may not exist either
+		}
+		if err != nil {
+			return nil, fmt.Errorf("checking existence of %s: %w", ref.String(), err)
+		}
+		return &ref, nil
+	}
+	return nil, nil
+}
+
+// explain records the given explanation for the resource, including the next requeue time (if any) of the result.
+func explain(resource *reconstitution.Resource, e *reconstitution.Explanation, result ctrl.Result) ctrl.Result {
+	e.Time = time.Now()
+	if result.RequeueAfter > 0 {
+		e.NextRequeue = ptr.To(e.Time.Add(result.RequeueAfter))
+	}
+	if result.Requeue || result.RequeueAfter > 0 {
+		requeues.WithLabelValues(requeueReason(e.Decision)).Inc()
+	}
+	resource.ObserveDecision(e)
+	return result
+}
+
+// requeueReason maps the decision that caused a resource to be requeued to the reason label of eno_reconciliation_requeues_total.
+func requeueReason(decision string) string {
+	switch decision {
+	case "WaitingForCRD":
+		return "crd-wait"
+	case "WaitingForDependency":
+		return "dependency-wait"
+	case "MissingRequirement":
+		return "requirement-wait"
+	case "WaitingForSettle":
+		return "settle-wait"
+	case "WaitingForReadiness", "Degraded":
+		return "readiness-wait"
+	case "Frozen":
+		return "freeze-window"
+	case "HandsOff":
+		return "hands-off"
+	case "Modified", "ReplacingHook":
+		return "modified"
+	case "ConcurrentEditBackoff", "CircuitOpen", "ProfileWriteLimit":
+		return "backoff"
+	default:
+		return "resync" // in sync, but periodically reconciled
 	}
-	return ctrl.Result{}, nil
 }
 
 func (c *Controller) reconcileResource(ctx context.Context, comp *apiv1.Composition, prev, resource *reconstitution.Resource, current *unstructured.Unstructured) (bool, error) {
@@ -240,12 +866,16 @@ func (c *Controller) reconcileResource(ctx context.Context, comp *apiv1.Composit
 		if current == nil || current.GetDeletionTimestamp() != nil {
 			return false, nil // already deleted - nothing to do
 		}
-		if comp.Annotations["eno.azure.io/deletion-strategy"] == "orphan" {
+		if comp.ShouldOrphan() {
 			return false, nil
 		}
 
 		reconciliationActions.WithLabelValues("delete").Inc()
-		err := c.upstreamClient.Delete(ctx, current)
+		err := faults.Inject(ctx, c.faults, faults.DownstreamWrite)
+		if err == nil {
+			err = c.upstreamClient.Delete(ctx, current)
+		}
+		resource.ObserveAction("delete", client.IgnoreNotFound(err))
 		if err != nil {
 			return false, client.IgnoreNotFound(fmt.Errorf("deleting resource: %w", err))
 		}
@@ -265,25 +895,49 @@ func (c *Controller) reconcileResource(ctx context.Context, comp *apiv1.Composit
 		if err != nil {
 			return false, fmt.Errorf("invalid resource: %w", err)
 		}
-		err = c.upstreamClient.Create(ctx, obj)
+		obj, err = c.resolveObjectSecretRefs(ctx, comp, obj)
+		if err != nil {
+			return false, fmt.Errorf("resolving secret references: %w", err)
+		}
+		if c.ownerAnnotations {
+			setOwnerAnnotations(obj, comp)
+		}
+		if c.applySetNamespace != "" {
+			setApplySetLabel(obj, c.applySetID(comp))
+		}
+		if resource.Hook != "" {
+			setHookSynthesisAnnotation(obj, comp)
+		}
+		err = faults.Inject(ctx, c.faults, faults.DownstreamWrite)
+		if err == nil {
+			err = c.upstreamClient.Create(ctx, obj)
+		}
+		resource.ObserveAction("create", err)
 		if err != nil {
 			return false, fmt.Errorf("creating resource: %w", err)
 		}
+		if resource.GenerateName != "" {
+			resource.ObserveGeneratedName(obj.GetName())
+			logger = logger.WithValues("generatedName", obj.GetName())
+		}
 		logger.V(0).Info("created resource")
+		if _, err := c.applyStatus(ctx, prev, resource, obj); err != nil {
+			return false, err
+		}
 		return true, nil
 	}
 
-	if resource.DisableUpdates {
+	if resource.DisableUpdates || resource.Hook != "" {
 		return false, nil
 	}
 
 	// Compute a merge patch
 	prevRV := current.GetResourceVersion()
-	patch, patchType, err := c.buildPatch(ctx, prev, resource, current)
+	patch, patchType, err := c.buildPatch(ctx, comp, prev, resource, current)
 	if err != nil {
 		return false, fmt.Errorf("building patch: %w", err)
 	}
-	if patchType != types.JSONPatchType {
+	if patchType == types.MergePatchType || patchType == types.StrategicMergePatchType {
 		patch, err = mungePatch(patch, current.GetResourceVersion())
 		if err != nil {
 			return false, fmt.Errorf("adding resource version: %w", err)
@@ -291,22 +945,109 @@ func (c *Controller) reconcileResource(ctx context.Context, comp *apiv1.Composit
 	}
 	if len(patch) == 0 {
 		logger.V(1).Info("skipping empty patch")
-		return false, nil
+		return c.applyStatus(ctx, prev, resource, current)
 	}
 	reconciliationActions.WithLabelValues("patch").Inc()
-	if insecureLogPatch {
-		logger.V(1).Info("INSECURE logging patch", "patch", string(patch))
+	if c.shouldLogPatch(resource) {
+		logger.V(1).Info("patching resource", "fields", patchFieldPaths(patch, patchType), "patch", string(patch))
+	} else {
+		logger.V(1).Info("patching resource", "fields", patchFieldPaths(patch, patchType))
+	}
+	var opts []client.PatchOption
+	if patchType == types.ApplyPatchType {
+		opts = append(opts, client.FieldOwner(c.fieldManager), client.ForceOwnership)
+	}
+	err = faults.Inject(ctx, c.faults, faults.DownstreamWrite)
+	if err == nil {
+		err = c.upstreamClient.Patch(ctx, current, client.RawPatch(patchType, patch), opts...)
+	}
+	resource.ObserveAction("patch", err)
+	if patchType == types.ApplyPatchType && errors.IsUnsupportedMediaType(err) {
+		logger.V(0).Info("server-side apply is not supported by this type - falling back to merge patch")
+		c.patchStrategies.ApplyUnsupported(resource.GVK)
 	}
-	err = c.upstreamClient.Patch(ctx, current, client.RawPatch(patchType, patch))
 	if err != nil {
 		return false, fmt.Errorf("applying patch: %w", err)
 	}
 	logger.V(0).Info("patched resource", "patchType", string(patchType), "resourceVersion", current.GetResourceVersion(), "previousResourceVersion", prevRV)
 
+	if _, err := c.applyStatus(ctx, prev, resource, current); err != nil {
+		return false, err
+	}
 	return true, nil
 }
 
-func (c *Controller) buildPatch(ctx context.Context, prev, next *reconstitution.Resource, current *unstructured.Unstructured) ([]byte, types.PatchType, error) {
+// applyStatus writes the status of resources that opt into it using the status subresource,
+// since it's ignored by other writes. Like the rest of the resource, status is three-way merged:
+// fields set by other clients (e.g. the resource's controller) are left alone unless they were previously synthesized.
+func (c *Controller) applyStatus(ctx context.Context, prev, next *reconstitution.Resource, current *unstructured.Unstructured) (bool, error) {
+	if !next.ApplyStatus || current == nil {
+		return false, nil
+	}
+	logger := logr.FromContextOrDiscard(ctx)
+
+	nextJS, ok, err := statusJSON(next)
+	if err != nil || !ok {
+		return false, err // nothing to apply
+	}
+	prevJS, _, err := statusJSON(prev)
+	if err != nil {
+		return false, err
+	}
+	currentJS, err := json.Marshal(map[string]any{"status": current.Object["status"]})
+	if err != nil {
+		return false, reconcile.TerminalError(err)
+	}
+
+	patch, err := jsonmergepatch.CreateThreeWayJSONMergePatch(prevJS, nextJS, currentJS)
+	if err != nil {
+		return false, reconcile.TerminalError(fmt.Errorf("building status patch: %w", err))
+	}
+	if string(patch) == "{}" {
+		return false, nil
+	}
+
+	reconciliationActions.WithLabelValues("status").Inc()
+	err = faults.Inject(ctx, c.faults, faults.DownstreamWrite)
+	if err == nil {
+		err = c.upstreamClient.Status().Patch(ctx, current, client.RawPatch(types.MergePatchType, patch))
+	}
+	next.ObserveAction("status", err)
+	if err != nil {
+		return false, fmt.Errorf("applying status: %w", err)
+	}
+	logger.V(0).Info("applied resource status", "resourceVersion", current.GetResourceVersion())
+	return true, nil
+}
+
+// statusJSON returns only the status of the resource's manifest, wrapped in an object.
+func statusJSON(res *reconstitution.Resource) ([]byte, bool, error) {
+	if res == nil {
+		return []byte("{}"), false, nil
+	}
+	obj, err := res.Parse()
+	if err != nil {
+		return nil, false, reconcile.TerminalError(fmt.Errorf("parsing resource: %w", err))
+	}
+	status, ok := obj.Object["status"]
+	if !ok {
+		return []byte("{}"), false, nil
+	}
+	js, err := json.Marshal(map[string]any{"status": status})
+	if err != nil {
+		return nil, false, reconcile.TerminalError(err)
+	}
+	return js, true, nil
+}
+
+// shouldLogPatch returns true when the full contents of the resource's patches can be logged.
+// Patches of resources that reference secrets are never logged in full, since they may contain resolved secret values.
+func (c *Controller) shouldLogPatch(resource *reconstitution.Resource) bool {
+	_, ok := c.logPatchGroupKinds[resource.GVK.GroupKind()]
+	return ok && !hasSecretRefs(resource)
+}
+
+func (c *Controller) buildPatch(ctx context.Context, comp *apiv1.Composition, prev, next *reconstitution.Resource, current *unstructured.Unstructured) ([]byte, types.PatchType, error) {
 	if next.Patch != nil {
 		if !next.NeedsToBePatched(current) {
 			return []byte{}, types.JSONPatchType, nil
@@ -325,6 +1066,28 @@ func (c *Controller) buildPatch(ctx context.Context, prev, next *reconstitution.
 		return nil, "", reconcile.TerminalError(fmt.Errorf("building json representation of next state: %w", err))
 	}
 
+	// Both states are resolved using the secrets' current values, so changes to the secrets are applied as drift.
+	// The previous state is best-effort since it may reference secrets that no longer exist.
+	if resolved, err := c.resolveSecretRefs(ctx, comp, prevJS); err == nil {
+		prevJS = resolved
+	}
+	nextJS, err = c.resolveSecretRefs(ctx, comp, nextJS)
+	if err != nil {
+		return nil, "", fmt.Errorf("resolving secret references: %w", err)
+	}
+	if c.ownerAnnotations {
+		nextJS, err = addOwnerAnnotations(nextJS, comp)
+		if err != nil {
+			return nil, "", reconcile.TerminalError(fmt.Errorf("adding owner annotations: %w", err))
+		}
+	}
+	if c.applySetNamespace != "" {
+		nextJS, err = addApplySetLabel(nextJS, c.applySetID(comp))
+		if err != nil {
+			return nil, "", reconcile.TerminalError(fmt.Errorf("adding applyset label: %w", err))
+		}
+	}
+
 	currentJS, err := current.MarshalJSON()
 	if err != nil {
 		return nil, "", reconcile.TerminalError(fmt.Errorf("building json representation of current state: %w", err))
@@ -338,12 +1101,35 @@ func (c *Controller) buildPatch(ctx context.Context, prev, next *reconstitution.
 	// FIXME: This is a very nasty hack which should not be needed once we have
 	// support for semantic equality checks.
 	pdbGVK := schema.GroupVersionKind{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"}
-	if model == nil || (next != nil && next.GVK == pdbGVK) {
-		patch, err := jsonmergepatch.CreateThreeWayJSONMergePatch(prevJS, nextJS, currentJS)
+	if next != nil && next.GVK == pdbGVK {
+		model = nil
+	}
+
+	switch c.patchStrategies.Get(next.GVK, model) {
+	case PatchStrategyMerge:
+		var patch []byte
+		if keys := c.listMergeKeys[next.GVK.GroupKind()]; len(keys) > 0 {
+			patch, err = buildListMergePatch(keys, prevJS, nextJS, currentJS)
+		} else {
+			patch, err = jsonmergepatch.CreateThreeWayJSONMergePatch(prevJS, nextJS, currentJS)
+		}
 		if err != nil {
 			return nil, "", reconcile.TerminalError(err)
 		}
 		return patch, types.MergePatchType, err
+
+	case PatchStrategyApply:
+		// The merge patch is only used to determine whether the resource has drifted.
+		// Server-side apply is idempotent, but sending it on every reconcile would be wasteful.
+		patch, err := jsonmergepatch.CreateThreeWayJSONMergePatch(prevJS, nextJS, currentJS)
+		if err != nil {
+			return nil, "", reconcile.TerminalError(err)
+		}
+		patch, err = mungePatch(patch, current.GetResourceVersion())
+		if err != nil || len(patch) == 0 {
+			return nil, types.ApplyPatchType, err
+		}
+		return nextJS, types.ApplyPatchType, nil
 	}
 
 	patchmeta := strategicpatch.NewPatchMetaFromOpenAPI(model)
@@ -354,14 +1140,45 @@ func (c *Controller) buildPatch(ctx context.Context, prev, next *reconstitution.
 	return patch, types.StrategicMergePatchType, err
 }
 
-func (c *Controller) getCurrent(ctx context.Context, resource *reconstitution.Resource) (*unstructured.Unstructured, bool, error) {
+// getCurrent returns the current state of the resource with the given name.
+// An empty name refers to a resource using generateName that hasn't been created yet.
+func (c *Controller) getCurrent(ctx context.Context, resource *reconstitution.Resource, name string) (*unstructured.Unstructured, bool, error) {
+	if name == "" {
+		return nil, true, nil
+	}
+
+	current, ok, err := c.lists.Get(ctx, resource.GVK, types.NamespacedName{Name: name, Namespace: resource.Ref.Namespace})
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		if resource.HasBeenSeen() && !resource.Deleted() {
+			if resource.MatchesLastSeen(current.GetResourceVersion()) {
+				return nil, false, nil
+			}
+			resourceVersionChanges.Inc()
+		}
+		return current, true, nil
+	}
+
+	// Deleting a resource only requires its metadata (existence, deletion timestamp, protection annotation),
+	// so the body is only fetched when readiness checks need to evaluate it
+	if resource.Deleted() && len(resource.ReadinessChecks) == 0 {
+		meta, err := c.getMetadata(ctx, resource, name)
+		if err != nil {
+			return nil, true, err
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(meta)
+		if err != nil {
+			return nil, true, err
+		}
+		current = &unstructured.Unstructured{Object: obj}
+		current.SetGroupVersionKind(resource.GVK)
+		return current, true, nil
+	}
+
 	if resource.HasBeenSeen() && !resource.Deleted() {
-		meta := &metav1.PartialObjectMetadata{}
-		meta.Name = resource.Ref.Name
-		meta.Namespace = resource.Ref.Namespace
-		meta.Kind = resource.GVK.Kind
-		meta.APIVersion = resource.GVK.GroupVersion().String()
-		err := c.upstreamClient.Get(ctx, client.ObjectKeyFromObject(meta), meta)
+		meta, err := c.getMetadata(ctx, resource, name)
 		if err != nil {
 			return nil, false, err
 		}
@@ -371,18 +1188,113 @@ func (c *Controller) getCurrent(ctx context.Context, resource *reconstitution.Re
 		resourceVersionChanges.Inc()
 	}
 
-	current := &unstructured.Unstructured{}
-	current.SetName(resource.Ref.Name)
+	start := time.Now()
+	nsn := types.NamespacedName{Name: name, Namespace: resource.Ref.Namespace}
+	current, ok, err = c.protobuf.Get(ctx, resource.GVK, nsn)
+	if ok {
+		downstreamGetLatency.WithLabelValues(resource.GVK.Group, resource.GVK.Kind, "protobuf").Observe(time.Since(start).Seconds())
+		if err != nil {
+			return nil, true, err
+		}
+		return current, true, nil
+	}
+
+	current = &unstructured.Unstructured{}
+	current.SetName(name)
 	current.SetNamespace(resource.Ref.Namespace)
 	current.SetKind(resource.GVK.Kind)
 	current.SetAPIVersion(resource.GVK.GroupVersion().String())
-	err := c.upstreamClient.Get(ctx, client.ObjectKeyFromObject(current), current)
+	err = c.upstreamClient.Get(ctx, client.ObjectKeyFromObject(current), current)
+	downstreamGetLatency.WithLabelValues(resource.GVK.Group, resource.GVK.Kind, "json").Observe(time.Since(start).Seconds())
 	if err != nil {
 		return nil, true, err
 	}
 	return current, true, nil
 }
 
+// getMetadata reads only the metadata of the given resource, which is much cheaper than reading the full object.
+func (c *Controller) getMetadata(ctx context.Context, resource *reconstitution.Resource, name string) (*metav1.PartialObjectMetadata, error) {
+	meta := &metav1.PartialObjectMetadata{}
+	meta.Name = name
+	meta.Namespace = resource.Ref.Namespace
+	meta.Kind = resource.GVK.Kind
+	meta.APIVersion = resource.GVK.GroupVersion().String()
+
+	start := time.Now()
+	err := c.upstreamClient.Get(ctx, client.ObjectKeyFromObject(meta), meta)
+	downstreamGetLatency.WithLabelValues(resource.GVK.Group, resource.GVK.Kind, "metadata").Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// deleteHook deletes a hook Job along with its pods.
+func (c *Controller) deleteHook(ctx context.Context, resource *reconstitution.Resource, current *unstructured.Unstructured) error {
+	reconciliationActions.WithLabelValues("delete").Inc()
+	err := faults.Inject(ctx, c.faults, faults.DownstreamWrite)
+	if err == nil {
+		err = c.upstreamClient.Delete(ctx, current, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	}
+	resource.ObserveAction("delete", client.IgnoreNotFound(err))
+	if err = client.IgnoreNotFound(err); err != nil {
+		return fmt.Errorf("deleting hook: %w", err)
+	}
+	logr.FromContextOrDiscard(ctx).V(0).Info("deleted hook")
+	return nil
+}
+
+// deleteExpired deletes a resource whose eno.azure.io/expires-after duration has elapsed.
+// The current state isn't required, since it isn't read when the resource hasn't changed since it was last observed.
+func (c *Controller) deleteExpired(ctx context.Context, resource *reconstitution.Resource, name string) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(resource.GVK)
+	obj.SetName(name)
+	obj.SetNamespace(resource.Ref.Namespace)
+
+	reconciliationActions.WithLabelValues("delete").Inc()
+	err := faults.Inject(ctx, c.faults, faults.DownstreamWrite)
+	if err == nil {
+		err = c.upstreamClient.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	}
+	resource.ObserveAction("delete", client.IgnoreNotFound(err))
+	if err = client.IgnoreNotFound(err); err != nil {
+		return fmt.Errorf("deleting expired resource: %w", err)
+	}
+	logr.FromContextOrDiscard(ctx).V(0).Info("deleted expired resource")
+	return nil
+}
+
+// setHookSynthesisAnnotation records the synthesis that ran the given hook.
+func setHookSynthesisAnnotation(obj *unstructured.Unstructured, comp *apiv1.Composition) {
+	anno := obj.GetAnnotations()
+	if anno == nil {
+		anno = map[string]string{}
+	}
+	anno[reconstitution.HookSynthesisAnnotation] = comp.Status.GetCurrentSynthesisUUID()
+	obj.SetAnnotations(anno)
+}
+
+// setOwnerAnnotations identifies the composition that manages the given resource.
+func setOwnerAnnotations(obj *unstructured.Unstructured, comp *apiv1.Composition) {
+	anno := obj.GetAnnotations()
+	if anno == nil {
+		anno = map[string]string{}
+	}
+	anno[apiv1.CompositionNameAnnotation] = comp.Name
+	anno[apiv1.CompositionNamespaceAnnotation] = comp.Namespace
+	obj.SetAnnotations(anno)
+}
+
+func addOwnerAnnotations(js []byte, comp *apiv1.Composition) ([]byte, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(js); err != nil {
+		return nil, err
+	}
+	setOwnerAnnotations(obj, comp)
+	return obj.MarshalJSON()
+}
+
 func mungePatch(patch []byte, rv string) ([]byte, error) {
 	var patchMap map[string]interface{}
 	err := json.Unmarshal(patch, &patchMap)
@@ -406,17 +1318,46 @@ func mungePatch(patch []byte, rv string) ([]byte, error) {
 	return json.Marshal(patchMap)
 }
 
-func patchResourceState(deleted bool, ready *metav1.Time) flowcontrol.StatusPatchFn {
+func patchResourceState(next *apiv1.ResourceState) flowcontrol.StatusPatchFn {
+	next.Reconciled = true
+	return func(rs *apiv1.ResourceState) *apiv1.ResourceState {
+		if rs != nil && rs.Deleted == next.Deleted && rs.Reconciled && ptr.Deref(rs.Ready, metav1.Time{}) == ptr.Deref(next.Ready, metav1.Time{}) && rs.Message == next.Message && rs.Blocked == next.Blocked && rs.Degraded == next.Degraded && rs.GeneratedName == next.GeneratedName && ptr.Deref(rs.HandsOffUntil, metav1.Time{}) == ptr.Deref(next.HandsOffUntil, metav1.Time{}) && maps.Equal(rs.Exports, next.Exports) && ptr.Deref(rs.ExpiresAt, metav1.Time{}) == ptr.Deref(next.ExpiresAt, metav1.Time{}) && rs.Expired == next.Expired {
+			return nil
+		}
+		return next
+	}
+}
+
+// patchResourceMessage sets the message of a resource that hasn't been reconciled yet.
+func patchResourceMessage(msg string) flowcontrol.StatusPatchFn {
 	return func(rs *apiv1.ResourceState) *apiv1.ResourceState {
-		if rs != nil && rs.Deleted == deleted && rs.Reconciled && ptr.Deref(rs.Ready, metav1.Time{}) == ptr.Deref(ready, metav1.Time{}) {
+		if rs != nil && rs.Message == msg {
 			return nil
 		}
-		return &apiv1.ResourceState{
-			Deleted:    deleted,
-			Ready:      ready,
-			Reconciled: true,
+		next := rs.DeepCopy()
+		if next == nil {
+			next = &apiv1.ResourceState{}
 		}
+		next.Message = msg
+		return next
+	}
+}
+
+// reportRegression records that a resource is no longer ready after having become ready.
+func (c *Controller) reportRegression(comp *apiv1.Composition, resource *reconstitution.Resource, msg string) {
+	readinessRegressions.WithLabelValues(resource.GVK.GroupKind().String()).Inc()
+	if c.recorder != nil {
+		c.recorder.Event(comp, corev1.EventTypeWarning, "ReadinessRegressed", msg)
+	}
+}
+
+// buildReadinessMessage describes why a resource isn't ready yet in a form suitable for status.
+func buildReadinessMessage(resource *reconstitution.Resource, current *unstructured.Unstructured) string {
+	msg := readiness.Describe(current)
+	if msg == "" {
+		msg = "readiness checks have not passed"
 	}
+	return fmt.Sprintf("%s: %s", resourceName(resource), msg)
 }
 
 // isErrMissingNS returns true when given the client-go error returned by mutating requests that do not include a namespace.
@@ -1,18 +1,27 @@
 package reconciliation
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	apiv1 "github.com/Azure/eno/api/v1"
 	"github.com/Azure/eno/internal/discovery"
 	"github.com/Azure/eno/internal/flowcontrol"
+	"github.com/Azure/eno/internal/readiness"
 	"github.com/Azure/eno/internal/reconstitution"
+	"github.com/Azure/eno/internal/resource"
 	"github.com/Azure/eno/internal/testutil"
+	openapi_v2 "github.com/google/gnostic-models/openapiv2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/kube-openapi/pkg/util/proto"
 )
 
 func TestMungePatch(t *testing.T) {
@@ -33,10 +42,20 @@ func TestMungePatchOnlyCreationTimestamp(t *testing.T) {
 	assert.Nil(t, patch)
 }
 
+func TestAddOwnerAnnotations(t *testing.T) {
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "test-ns"
+
+	js, err := addOwnerAnnotations([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo","annotations":{"bar":"baz"}}}`), comp)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo","annotations":{"bar":"baz","eno.azure.io/composition-name":"test-comp","eno.azure.io/composition-namespace":"test-ns"}}}`, string(js))
+}
+
 func TestBuildPatchEmpty(t *testing.T) {
 	ctx := testutil.NewContext(t)
 	mgr := testutil.NewManager(t)
-	dc, err := discovery.NewCache(mgr.DownstreamRestConfig, 10)
+	dc, err := discovery.NewCache(mgr.DownstreamRestConfig)
 	require.NoError(t, err)
 	c := &Controller{discovery: dc}
 
@@ -155,7 +174,7 @@ func TestBuildPatchEmpty(t *testing.T) {
 			current, prev := mapToResource(t, test.Current)
 			_, next := mapToResource(t, test.Next)
 
-			patch, kind, err := c.buildPatch(ctx, prev, next, current)
+			patch, kind, err := c.buildPatch(ctx, &apiv1.Composition{}, prev, next, current)
 			require.NoError(t, err)
 
 			patch, err = mungePatch(patch, "random-rv")
@@ -186,7 +205,6 @@ func setupTestSubject(t *testing.T, mgr *testutil.Manager) *Controller {
 		Cache:                 cache,
 		WriteBuffer:           rswb,
 		Downstream:            mgr.DownstreamRestConfig,
-		DiscoveryRPS:          5,
 		Timeout:               time.Minute,
 		ReadinessPollInterval: time.Hour,
 	})
@@ -197,3 +215,256 @@ func setupTestSubject(t *testing.T, mgr *testutil.Manager) *Controller {
 
 	return rc
 }
+
+func BenchmarkBuildPatch(b *testing.B) {
+	doc, err := openapi_v2.ParseDocument([]byte(benchmarkOpenAPIDoc))
+	if err != nil {
+		b.Fatal(err)
+	}
+	models, err := proto.NewOpenAPIData(doc)
+	if err != nil {
+		b.Fatal(err)
+	}
+	podSchema := models.LookupModel("io.k8s.api.core.v1.Pod")
+	if podSchema == nil {
+		b.Fatal("pod schema not found")
+	}
+
+	newPod := func(image string, containers int) map[string]any {
+		cs := make([]any, containers)
+		for i := range cs {
+			cs[i] = map[string]any{"name": fmt.Sprintf("container-%d", i), "image": image, "args": []any{"--foo", "--bar"}}
+		}
+		return map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]any{"name": "foo", "namespace": "default", "labels": map[string]any{"app": "foo"}},
+			"spec":       map[string]any{"serviceAccountName": "foo", "containers": cs},
+		}
+	}
+
+	tests := []struct {
+		Name   string
+		Schema proto.Schema
+	}{
+		{Name: "strategic", Schema: podSchema},
+		{Name: "merge", Schema: nil},
+	}
+	for _, tc := range tests {
+		b.Run(tc.Name, func(b *testing.B) {
+			ctx := context.Background()
+			c := &Controller{discovery: staticSchema{Schema: tc.Schema}}
+			comp := &apiv1.Composition{}
+
+			prev := benchmarkResource(b, newPod("image:1", 20))
+			next := benchmarkResource(b, newPod("image:2", 20))
+			current := &unstructured.Unstructured{Object: newPod("image:1", 20)}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := c.buildPatch(ctx, comp, prev, next, current); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func benchmarkResource(b *testing.B, obj map[string]any) *reconstitution.Resource {
+	u := &unstructured.Unstructured{Object: obj}
+	js, err := u.MarshalJSON()
+	if err != nil {
+		b.Fatal(err)
+	}
+	return &reconstitution.Resource{
+		Manifest: &apiv1.Manifest{Manifest: string(js)},
+		GVK:      u.GroupVersionKind(),
+	}
+}
+
+type staticSchema struct {
+	Schema proto.Schema
+}
+
+func (s staticSchema) Get(ctx context.Context, gvk schema.GroupVersionKind) (proto.Schema, error) {
+	return s.Schema, nil
+}
+
+// benchmarkOpenAPIDoc is the subset of the Pod schema needed to exercise strategic merge of lists.
+const benchmarkOpenAPIDoc = `{
+  "swagger": "2.0",
+  "info": {"title": "benchmark", "version": "v1.30.0"},
+  "paths": {},
+  "definitions": {
+    "io.k8s.api.core.v1.Pod": {
+      "type": "object",
+      "properties": {
+        "apiVersion": {"type": "string"},
+        "kind": {"type": "string"},
+        "metadata": {"$ref": "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"},
+        "spec": {"$ref": "#/definitions/io.k8s.api.core.v1.PodSpec"}
+      },
+      "x-kubernetes-group-version-kind": [{"group": "", "kind": "Pod", "version": "v1"}]
+    },
+    "io.k8s.api.core.v1.PodSpec": {
+      "type": "object",
+      "properties": {
+        "serviceAccountName": {"type": "string"},
+        "containers": {
+          "type": "array",
+          "items": {"$ref": "#/definitions/io.k8s.api.core.v1.Container"},
+          "x-kubernetes-patch-merge-key": "name",
+          "x-kubernetes-patch-strategy": "merge"
+        }
+      }
+    },
+    "io.k8s.api.core.v1.Container": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "image": {"type": "string"},
+        "args": {"type": "array", "items": {"type": "string"}}
+      }
+    },
+    "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "namespace": {"type": "string"},
+        "resourceVersion": {"type": "string"},
+        "creationTimestamp": {"type": "string"},
+        "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+        "annotations": {"type": "object", "additionalProperties": {"type": "string"}}
+      }
+    }
+  }
+}`
+
+func TestGetCurrentDeletedMetadataOnly(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cm := &corev1.ConfigMap{}
+	cm.Name = "foo"
+	cm.Namespace = "default"
+	cm.Annotations = map[string]string{"eno.azure.io/deletion-strategy": "orphan"}
+	cm.Data = map[string]string{"foo": "bar"}
+	c := &Controller{upstreamClient: testutil.NewClient(t, cm)}
+
+	res := &reconstitution.Resource{
+		Manifest: &apiv1.Manifest{Deleted: true},
+		GVK:      schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+	}
+	res.Ref.Name = cm.Name
+	res.Ref.Namespace = cm.Namespace
+
+	// Only metadata is read for deleted resources
+	current, hasChanged, err := c.getCurrent(ctx, res, res.Ref.Name)
+	require.NoError(t, err)
+	assert.True(t, hasChanged)
+	assert.Equal(t, res.GVK, current.GroupVersionKind())
+	assert.Equal(t, cm.Annotations, current.GetAnnotations())
+	assert.NotContains(t, current.Object, "data")
+
+	// The body is read when readiness checks need it
+	env, err := readiness.NewEnv()
+	require.NoError(t, err)
+	check, err := readiness.ParseCheck(env, "self.data.foo == 'bar'")
+	require.NoError(t, err)
+	res.ReadinessChecks = readiness.Checks{check}
+
+	current, _, err = c.getCurrent(ctx, res, res.Ref.Name)
+	require.NoError(t, err)
+	assert.Contains(t, current.Object, "data")
+}
+
+func TestResolveName(t *testing.T) {
+	res := &reconstitution.Resource{}
+	res.Ref.Name = "foo"
+	assert.Equal(t, "foo", resolveName(res, nil))
+
+	// Not created yet
+	res = &reconstitution.Resource{GenerateName: "foo-"}
+	res.Ref.Name = res.GenerateName
+	assert.Equal(t, "", resolveName(res, nil))
+	assert.Equal(t, "", resolveName(res, &apiv1.ResourceState{}))
+
+	// Recorded in status
+	assert.Equal(t, "foo-abcde", resolveName(res, &apiv1.ResourceState{GeneratedName: "foo-abcde"}))
+
+	// Observed names take precedence over (possibly stale) status
+	res.ObserveGeneratedName("foo-fghij")
+	assert.Equal(t, "foo-fghij", resolveName(res, &apiv1.ResourceState{}))
+}
+
+func TestGetCurrentNotYetGenerated(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	c := &Controller{upstreamClient: testutil.NewClient(t)}
+
+	res := &reconstitution.Resource{GenerateName: "foo-", GVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}}
+	current, hasChanged, err := c.getCurrent(ctx, res, "")
+	require.NoError(t, err)
+	assert.True(t, hasChanged)
+	assert.Nil(t, current)
+}
+
+func TestRequeueReason(t *testing.T) {
+	assert.Equal(t, "crd-wait", requeueReason("WaitingForCRD"))
+	assert.Equal(t, "readiness-wait", requeueReason("WaitingForReadiness"))
+	assert.Equal(t, "readiness-wait", requeueReason("Degraded"))
+	assert.Equal(t, "backoff", requeueReason("CircuitOpen"))
+	assert.Equal(t, "requirement-wait", requeueReason("MissingRequirement"))
+	assert.Equal(t, "resync", requeueReason("InSync"))
+}
+
+type staticCapabilities struct {
+	Version *version.Version
+	Kinds   map[schema.GroupVersionKind]bool
+}
+
+func (s staticCapabilities) ServerVersion(ctx context.Context) (*version.Version, error) {
+	return s.Version, nil
+}
+
+func (s staticCapabilities) Serves(ctx context.Context, gvk schema.GroupVersionKind) (bool, error) {
+	return s.Kinds[gvk], nil
+}
+
+func TestFirstUnmetRequirement(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	c := &Controller{capabilities: staticCapabilities{
+		Version: version.MustParseGeneric("v1.28.3"),
+		Kinds: map[schema.GroupVersionKind]bool{
+			{Group: "apps", Version: "v1"}:                   true,
+			{Group: "batch", Version: "v1", Kind: "CronJob"}: true,
+		},
+	}}
+
+	newResource := func(anno string) *reconstitution.Resource {
+		reqs, err := resource.ParseRequirements(anno)
+		require.NoError(t, err)
+		return &reconstitution.Resource{Requires: reqs}
+	}
+
+	req, err := c.firstUnmetRequirement(ctx, newResource(""))
+	require.NoError(t, err)
+	assert.Nil(t, req)
+
+	req, err = c.firstUnmetRequirement(ctx, newResource("apps/v1,batch/v1.CronJob,kubernetes>=1.28"))
+	require.NoError(t, err)
+	assert.Nil(t, req)
+
+	req, err = c.firstUnmetRequirement(ctx, newResource("apps/v1,policy/v1beta1"))
+	require.NoError(t, err)
+	assert.Equal(t, "policy/v1beta1", req.String())
+
+	req, err = c.firstUnmetRequirement(ctx, newResource("kubernetes>=1.29"))
+	require.NoError(t, err)
+	assert.Equal(t, "kubernetes>=1.29", req.String())
+}
+
+func TestPatchResourceMessage(t *testing.T) {
+	fn := patchResourceMessage("waiting")
+	assert.Equal(t, &apiv1.ResourceState{Message: "waiting"}, fn(nil))
+	assert.Nil(t, fn(&apiv1.ResourceState{Message: "waiting"}))
+	assert.Equal(t, &apiv1.ResourceState{Message: "waiting", Deleted: true}, fn(&apiv1.ResourceState{Message: "other", Deleted: true}))
+}
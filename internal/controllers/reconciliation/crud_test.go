@@ -28,11 +28,6 @@ import (
 	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
 )
 
-func init() {
-	// safe for tests since they don't have any secrets
-	insecureLogPatch = true
-}
-
 var defaultConf = &synthesis.Config{
 	SliceCreationQPS: 20,
 	PodNamespace:     "default",
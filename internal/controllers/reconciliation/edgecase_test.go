@@ -4,8 +4,10 @@ import (
 	"context"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/util/retry"
@@ -13,7 +15,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/flowcontrol"
+	"github.com/Azure/eno/internal/reconstitution"
 	"github.com/Azure/eno/internal/testutil"
+	"github.com/Azure/eno/pkg/faults"
 	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
 )
 
@@ -139,3 +144,64 @@ func TestEmptySynthesis(t *testing.T) {
 		return errors.IsNotFound(upstream.Get(ctx, client.ObjectKeyFromObject(comp), comp))
 	})
 }
+
+// TestFaultInjection proves that compositions converge despite failed downstream writes, slow discovery, and dropped status patches.
+func TestFaultInjection(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	mgr := testutil.NewManager(t)
+	upstream := mgr.GetClient()
+
+	registerControllers(t, mgr)
+	testutil.WithFakeExecutor(t, mgr, func(ctx context.Context, s *apiv1.Synthesizer, input *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+		output := &krmv1.ResourceList{}
+		output.Items = []*unstructured.Unstructured{{
+			Object: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]any{
+					"name":      "test-obj",
+					"namespace": "default",
+				},
+				"data": map[string]any{"image": s.Spec.Image},
+			},
+		}}
+		return output, nil
+	})
+
+	// Test subject
+	cache := reconstitution.NewCache(mgr.GetClient())
+	rc, err := New(Options{
+		Manager:               mgr.Manager,
+		Cache:                 cache,
+		WriteBuffer:           flowcontrol.NewResourceSliceWriteBufferForManager(mgr.Manager, time.Millisecond*10, 1),
+		Downstream:            mgr.DownstreamRestConfig,
+		Timeout:               time.Minute,
+		ReadinessPollInterval: time.Hour,
+		Faults:                &faults.Random{Rate: 0.5, Delay: time.Millisecond * 10},
+	})
+	require.NoError(t, err)
+	require.NoError(t, reconstitution.New(mgr.Manager, cache, rc))
+	mgr.Start(t)
+	syn, comp := writeGenericComposition(t, upstream)
+
+	testutil.SomewhatEventually(t, time.Minute, func() bool {
+		err := upstream.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+		return err == nil && comp.Status.CurrentSynthesis != nil && comp.Status.CurrentSynthesis.Ready != nil && comp.Status.CurrentSynthesis.ObservedSynthesizerGeneration == syn.Generation
+	})
+
+	// Updates should also converge
+	err = retry.RetryOnConflict(testutil.Backoff, func() error {
+		upstream.Get(ctx, client.ObjectKeyFromObject(syn), syn)
+		syn.Spec.Image = "updated"
+		return upstream.Update(ctx, syn)
+	})
+	require.NoError(t, err)
+
+	testutil.SomewhatEventually(t, time.Minute, func() bool {
+		cm := &corev1.ConfigMap{}
+		cm.Name = "test-obj"
+		cm.Namespace = "default"
+		err := mgr.DownstreamClient.Get(ctx, client.ObjectKeyFromObject(cm), cm)
+		return err == nil && cm.Data["image"] == "updated"
+	})
+}
@@ -0,0 +1,46 @@
+package reconciliation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/reconstitution"
+	"github.com/Azure/eno/internal/resource"
+)
+
+// ServeHTTP explains why a particular resource is in its current state.
+// The resource is identified by query params: composition, compositionNamespace, group, kind, name, namespace.
+func (c *Controller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	comp := &apiv1.Composition{}
+	err := c.client.Get(r.Context(), types.NamespacedName{Name: q.Get("composition"), Namespace: q.Get("compositionNamespace")}, comp)
+	if errors.IsNotFound(err) {
+		http.Error(w, "composition not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("getting composition: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	ref := &resource.Ref{Name: q.Get("name"), Namespace: q.Get("namespace"), Group: q.Get("group"), Kind: q.Get("kind")}
+	res, ok := c.resourceClient.Get(r.Context(), reconstitution.NewSynthesisRef(comp), ref)
+	if !ok {
+		http.Error(w, "resource not found in the current synthesis", http.StatusNotFound)
+		return
+	}
+
+	explanation := res.Explain()
+	if explanation == nil {
+		http.Error(w, "resource has not been reconciled yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(explanation)
+}
@@ -46,4 +46,5 @@ type TestValue struct {
 }
 
 type TestResourceStatus struct {
+	Phase string `json:"phase,omitempty"`
 }
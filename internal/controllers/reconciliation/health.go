@@ -0,0 +1,80 @@
+package reconciliation
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	kdiscovery "k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+// healthGate periodically probes the downstream apiserver and pauses reconciliation while it's consistently failing.
+// Otherwise an unreachable apiserver causes every work item to hang until its timeout, fail, and retry in a hot loop.
+type healthGate struct {
+	client    rest.Interface
+	interval  time.Duration
+	threshold int
+
+	failures int // only accessed by the probe loop
+	healthy  atomic.Bool
+}
+
+func newHealthGate(rc *rest.Config, interval time.Duration, threshold int) (*healthGate, error) {
+	disc, err := kdiscovery.NewDiscoveryClientForConfig(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &healthGate{client: disc.RESTClient(), interval: interval, threshold: max(threshold, 1)}
+	h.healthy.Store(true)
+	downstreamHealthy.Set(1)
+	return h, nil
+}
+
+func (h *healthGate) Start(ctx context.Context) error {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		h.probe(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *healthGate) probe(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, h.interval)
+	defer cancel()
+	h.observe(ctx, h.client.Get().AbsPath("/readyz").Do(ctx).Error())
+}
+
+// observe records the result of a probe. The gate closes after threshold consecutive failures,
+// and opens again as soon as a probe succeeds.
+func (h *healthGate) observe(ctx context.Context, err error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	if err == nil {
+		if !h.healthy.Load() {
+			logger.V(0).Info("downstream apiserver is healthy - resuming reconciliation")
+		}
+		h.failures = 0
+		h.healthy.Store(true)
+		downstreamHealthy.Set(1)
+		return
+	}
+
+	h.failures++
+	downstreamHealthProbeFailures.Inc()
+	if h.failures < h.threshold || !h.healthy.Load() {
+		return
+	}
+	logger.Error(err, "downstream apiserver is unhealthy - pausing reconciliation", "consecutiveFailures", h.failures)
+	h.healthy.Store(false)
+	downstreamHealthy.Set(0)
+}
+
+// Healthy returns false while reconciliation should be paused.
+func (h *healthGate) Healthy() bool { return h == nil || h.healthy.Load() }
@@ -0,0 +1,57 @@
+package reconciliation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+
+	"github.com/Azure/eno/internal/testutil"
+)
+
+func TestHealthGate(t *testing.T) {
+	ctx := testutil.NewContext(t)
+
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+
+	h, err := newHealthGate(&rest.Config{Host: srv.URL}, time.Second, 2)
+	require.NoError(t, err)
+
+	h.probe(ctx)
+	assert.True(t, h.Healthy())
+
+	// Tolerates failures under the threshold
+	failing.Store(true)
+	h.probe(ctx)
+	assert.True(t, h.Healthy())
+
+	// Closes at the threshold
+	h.probe(ctx)
+	assert.False(t, h.Healthy())
+	h.probe(ctx)
+	assert.False(t, h.Healthy())
+
+	// Opens again after a single success
+	failing.Store(false)
+	h.probe(ctx)
+	assert.True(t, h.Healthy())
+	assert.Zero(t, h.failures)
+}
+
+func TestHealthGateDisabled(t *testing.T) {
+	var h *healthGate
+	assert.True(t, h.Healthy())
+}
@@ -20,16 +20,16 @@ import (
 )
 
 func registerControllers(t *testing.T, mgr *testutil.Manager) {
-	require.NoError(t, aggregation.NewSliceController(mgr.Manager))
+	require.NoError(t, aggregation.NewSliceController(mgr.Manager, 0))
 	require.NoError(t, synthesis.NewPodLifecycleController(mgr.Manager, defaultConf))
 	require.NoError(t, synthesis.NewSliceCleanupController(mgr.Manager))
-	require.NoError(t, watchdog.NewController(mgr.Manager, time.Second*10))
+	require.NoError(t, watchdog.NewController(mgr.Manager, time.Second*10, nil))
 	require.NoError(t, replication.NewSymphonyController(mgr.Manager))
 	require.NoError(t, aggregation.NewSymphonyController(mgr.Manager))
 	require.NoError(t, aggregation.NewCompositionController(mgr.Manager))
 	require.NoError(t, rollout.NewController(mgr.Manager, time.Millisecond))
-	require.NoError(t, rollout.NewSynthesizerController(mgr.Manager))
-	require.NoError(t, flowcontrol.NewSynthesisConcurrencyLimiter(mgr.Manager, 10, 0))
+	require.NoError(t, rollout.NewSynthesizerController(mgr.Manager, 0))
+	require.NoError(t, flowcontrol.NewSynthesisConcurrencyLimiter(mgr.Manager, 10, 0, 0))
 	require.NoError(t, liveness.NewNamespaceController(mgr.Manager, 3, time.Second))
 	require.NoError(t, watch.NewController(mgr.Manager))
 }
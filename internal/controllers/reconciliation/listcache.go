@@ -0,0 +1,116 @@
+package reconciliation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// listCache serves reads of selected resource types from a single LIST per type and namespace
+// instead of one GET per resource. This is useful for compositions with hundreds of resources of the same kind.
+//
+// Entries are short-lived: only the burst of reconciliations caused by a single sync wave shares a LIST.
+// Resources missing from the LIST (e.g. created after it or excluded by the selector) fall back to a GET.
+type listCache struct {
+	client   client.Reader
+	kinds    map[schema.GroupKind]struct{}
+	selector labels.Selector
+	ttl      time.Duration
+
+	mut     sync.Mutex
+	entries map[listKey]*listEntry
+}
+
+type listKey struct {
+	GVK       schema.GroupVersionKind
+	Namespace string
+}
+
+type listEntry struct {
+	mut    sync.Mutex
+	filled time.Time
+	items  map[string]*unstructured.Unstructured
+}
+
+func newListCache(cli client.Reader, kinds []schema.GroupKind, selector labels.Selector, ttl time.Duration) *listCache {
+	l := &listCache{client: cli, kinds: map[schema.GroupKind]struct{}{}, selector: selector, ttl: ttl, entries: map[listKey]*listEntry{}}
+	for _, gk := range kinds {
+		l.kinds[gk] = struct{}{}
+	}
+	if l.selector == nil {
+		l.selector = labels.Everything()
+	}
+	return l
+}
+
+// Get returns the current state of the given resource. ok is false when the resource can't be served from a LIST.
+func (l *listCache) Get(ctx context.Context, gvk schema.GroupVersionKind, nsn types.NamespacedName) (obj *unstructured.Unstructured, ok bool, err error) {
+	if l == nil {
+		return nil, false, nil
+	}
+	if _, enabled := l.kinds[gvk.GroupKind()]; !enabled {
+		return nil, false, nil
+	}
+
+	key := listKey{GVK: gvk, Namespace: nsn.Namespace}
+	l.mut.Lock()
+	entry, exists := l.entries[key]
+	if !exists {
+		entry = &listEntry{}
+		l.entries[key] = entry
+	}
+	l.mut.Unlock()
+
+	// Concurrent readers of the same type wait for a single LIST
+	entry.mut.Lock()
+	defer entry.mut.Unlock()
+
+	if time.Since(entry.filled) > l.ttl {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		err := l.client.List(ctx, list, client.InNamespace(nsn.Namespace), client.MatchingLabelsSelector{Selector: l.selector})
+		if err != nil {
+			return nil, false, fmt.Errorf("listing resources: %w", err)
+		}
+		listRequests.Inc()
+
+		entry.items = make(map[string]*unstructured.Unstructured, len(list.Items))
+		for i := range list.Items {
+			entry.items[list.Items[i].GetName()] = &list.Items[i]
+		}
+		entry.filled = time.Now()
+	}
+
+	item, found := entry.items[nsn.Name]
+	if !found {
+		return nil, false, nil
+	}
+	listCacheHits.Inc()
+	return item.DeepCopy(), true, nil
+}
+
+// Invalidate removes a resource from the cache such that the next read falls back to a GET.
+// This is necessary after writing the resource to avoid reading back stale state.
+func (l *listCache) Invalidate(gvk schema.GroupVersionKind, nsn types.NamespacedName) {
+	if l == nil {
+		return
+	}
+
+	l.mut.Lock()
+	entry := l.entries[listKey{GVK: gvk, Namespace: nsn.Namespace}]
+	l.mut.Unlock()
+	if entry == nil {
+		return
+	}
+
+	entry.mut.Lock()
+	delete(entry.items, nsn.Name)
+	entry.mut.Unlock()
+}
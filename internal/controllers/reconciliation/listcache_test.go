@@ -0,0 +1,69 @@
+package reconciliation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Azure/eno/internal/testutil"
+)
+
+type countingReader struct {
+	client.Reader
+	lists int
+}
+
+func (c *countingReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.lists++
+	return c.Reader.List(ctx, list, opts...)
+}
+
+func TestListCache(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := &countingReader{Reader: fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", Labels: map[string]string{"managed": "true"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default", Labels: map[string]string{"managed": "true"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "default"}},
+	).Build()}
+
+	l := newListCache(cli, []schema.GroupKind{{Kind: "ConfigMap"}}, labels.SelectorFromSet(labels.Set{"managed": "true"}), time.Hour)
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+	// Multiple resources are served by one LIST
+	obj, ok, err := l.Get(ctx, gvk, types.NamespacedName{Name: "a", Namespace: "default"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "a", obj.GetName())
+
+	_, ok, err = l.Get(ctx, gvk, types.NamespacedName{Name: "b", Namespace: "default"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, cli.lists)
+
+	// Resources excluded by the selector fall back to GET
+	_, ok, err = l.Get(ctx, gvk, types.NamespacedName{Name: "c", Namespace: "default"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Invalidated resources fall back to GET
+	l.Invalidate(gvk, types.NamespacedName{Name: "a", Namespace: "default"})
+	_, ok, err = l.Get(ctx, gvk, types.NamespacedName{Name: "a", Namespace: "default"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Other kinds aren't listed
+	_, ok, err = l.Get(ctx, schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, types.NamespacedName{Name: "a", Namespace: "default"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1, cli.lists)
+}
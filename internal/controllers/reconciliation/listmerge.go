@@ -0,0 +1,83 @@
+package reconciliation
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// ListMergeKeys hold the key used to merge the items of particular list fields, by resource type and field path (e.g. "spec.routes").
+// Without them, merge patches replace lists wholesale - removing items added by other clients.
+type ListMergeKeys map[schema.GroupKind]map[string]string
+
+// ParseListMergeKeys parses comma-separated Kind.group:path=key entries e.g. "Widget.example.com:spec.routes=name".
+func ParseListMergeKeys(input string) (ListMergeKeys, error) {
+	keys := ListMergeKeys{}
+	for _, entry := range strings.Split(input, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		kind, rest, ok := strings.Cut(entry, ":")
+		path, key, ok2 := strings.Cut(rest, "=")
+		if !ok || !ok2 || kind == "" || path == "" || key == "" {
+			return nil, fmt.Errorf("invalid list merge key %q: expected Kind.group:path=key", entry)
+		}
+		gk := schema.ParseGroupKind(kind)
+		if keys[gk] == nil {
+			keys[gk] = map[string]string{}
+		}
+		keys[gk][path] = key
+	}
+	return keys, nil
+}
+
+// buildListMergePatch returns a merge patch that merges the given lists by key instead of replacing them.
+//
+// Custom resources don't support strategic merge patches, so the strategic merge is applied to the current
+// state locally and then sent as a merge patch. The resource version precondition prevents lost updates.
+func buildListMergePatch(keys map[string]string, prevJS, nextJS, currentJS []byte) ([]byte, error) {
+	meta := &listMergeMeta{keys: keys}
+	patch, err := strategicpatch.CreateThreeWayMergePatch(prevJS, nextJS, currentJS, meta, true)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := strategicpatch.StrategicMergePatchUsingLookupPatchMeta(currentJS, patch, meta)
+	if err != nil {
+		return nil, err
+	}
+	return jsonmergepatch.CreateThreeWayJSONMergePatch(currentJS, merged, currentJS)
+}
+
+// listMergeMeta implements strategicpatch.LookupPatchMeta using merge keys configured by field path.
+// Lists without a configured key are replaced, and maps are merged, just like merge patches.
+type listMergeMeta struct {
+	keys map[string]string
+	path string
+}
+
+func (l *listMergeMeta) LookupPatchMetadataForStruct(key string) (strategicpatch.LookupPatchMeta, strategicpatch.PatchMeta, error) {
+	return l.child(key), strategicpatch.PatchMeta{}, nil
+}
+
+func (l *listMergeMeta) LookupPatchMetadataForSlice(key string) (strategicpatch.LookupPatchMeta, strategicpatch.PatchMeta, error) {
+	child := l.child(key)
+	meta := strategicpatch.PatchMeta{}
+	if mergeKey, ok := l.keys[child.path]; ok {
+		meta.SetPatchStrategies([]string{"merge"})
+		meta.SetPatchMergeKey(mergeKey)
+	}
+	return child, meta, nil
+}
+
+func (l *listMergeMeta) Name() string { return l.path }
+
+func (l *listMergeMeta) child(key string) *listMergeMeta {
+	path := key
+	if l.path != "" {
+		path = l.path + "." + key
+	}
+	return &listMergeMeta{keys: l.keys, path: path}
+}
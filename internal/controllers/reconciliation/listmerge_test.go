@@ -0,0 +1,101 @@
+package reconciliation
+
+import (
+	"encoding/json"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListMergeKeys(t *testing.T) {
+	keys, err := ParseListMergeKeys("Widget.example.com:spec.routes=name, Widget.example.com:spec.ports=port,ConfigMap:data.items=id")
+	require.NoError(t, err)
+	assert.Equal(t, ListMergeKeys{
+		{Group: "example.com", Kind: "Widget"}: {"spec.routes": "name", "spec.ports": "port"},
+		{Kind: "ConfigMap"}:                    {"data.items": "id"},
+	}, keys)
+
+	for _, input := range []string{"Widget.example.com", "Widget.example.com:spec.routes", "Widget.example.com:=name", ":spec.routes=name"} {
+		_, err = ParseListMergeKeys(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestBuildListMergePatch(t *testing.T) {
+	keys := map[string]string{"spec.routes": "name", "spec.routes.backends": "host"}
+
+	prev := map[string]any{"spec": map[string]any{
+		"routes": []any{
+			map[string]any{"name": "a", "path": "/a"},
+			map[string]any{"name": "b", "path": "/b"},
+		},
+		"tags": []any{"one"},
+	}}
+	next := map[string]any{"spec": map[string]any{
+		"routes": []any{
+			map[string]any{"name": "a", "path": "/a2", "backends": []any{map[string]any{"host": "foo"}}},
+			map[string]any{"name": "c", "path": "/c"},
+		},
+		"tags": []any{"two"},
+	}}
+	current := map[string]any{"spec": map[string]any{
+		"routes": []any{
+			map[string]any{"name": "a", "path": "/a", "timeout": "10s"},
+			map[string]any{"name": "b", "path": "/b"},
+			map[string]any{"name": "x", "path": "/x"}, // added by another client
+		},
+		"tags": []any{"one", "three"},
+	}}
+
+	patch, err := buildListMergePatch(keys, mustJSON(t, prev), mustJSON(t, next), mustJSON(t, current))
+	require.NoError(t, err)
+
+	result, err := jsonpatch.MergePatch(mustJSON(t, current), patch)
+	require.NoError(t, err)
+
+	actual := struct {
+		Spec struct {
+			Routes []map[string]any `json:"routes"`
+			Tags   []string         `json:"tags"`
+		} `json:"spec"`
+	}{}
+	require.NoError(t, json.Unmarshal(result, &actual))
+	assert.ElementsMatch(t, []map[string]any{
+		{"name": "a", "path": "/a2", "timeout": "10s", "backends": []any{map[string]any{"host": "foo"}}},
+		{"name": "c", "path": "/c"},
+		{"name": "x", "path": "/x"},
+	}, actual.Spec.Routes)
+	assert.Equal(t, []string{"two"}, actual.Spec.Tags)
+
+	// No drift
+	patch, err = buildListMergePatch(keys, mustJSON(t, next), mustJSON(t, next), result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(patch))
+}
+
+func TestListMergeMetaPaths(t *testing.T) {
+	meta := &listMergeMeta{keys: map[string]string{"spec.routes": "name"}}
+
+	spec, pm, err := meta.LookupPatchMetadataForStruct("spec")
+	require.NoError(t, err)
+	assert.Empty(t, pm.GetPatchMergeKey())
+	assert.Equal(t, "spec", spec.Name())
+
+	routes, pm, err := spec.LookupPatchMetadataForSlice("routes")
+	require.NoError(t, err)
+	assert.Equal(t, "name", pm.GetPatchMergeKey())
+	assert.Equal(t, []string{"merge"}, pm.GetPatchStrategies())
+	assert.Equal(t, "spec.routes", routes.Name())
+
+	_, pm, err = spec.LookupPatchMetadataForSlice("other")
+	require.NoError(t, err)
+	assert.Empty(t, pm.GetPatchStrategies())
+}
+
+func mustJSON(t *testing.T, obj any) []byte {
+	js, err := json.Marshal(obj)
+	require.NoError(t, err)
+	return js
+}
@@ -0,0 +1,190 @@
+package reconciliation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/reconstitution"
+)
+
+// checkManagedFields detects resources whose managedFields have grown past the configured threshold,
+// which is a common symptom of multiple clients fighting over a resource. Left alone, the managedFields
+// bloat etcd and slow down every patch of the resource.
+//
+// When cleanup is enabled, managedFields are reset by the documented method of writing a single empty entry.
+// This is safe for Eno since it doesn't rely on server-side apply field ownership.
+func (c *Controller) checkManagedFields(ctx context.Context, comp *apiv1.Composition, resource *reconstitution.Resource, current *unstructured.Unstructured) error {
+	entries := len(current.GetManagedFields())
+	managedFieldsEntries.Observe(float64(entries))
+	if c.managedFieldsThreshold <= 0 || entries <= c.managedFieldsThreshold {
+		return nil
+	}
+	logger := logr.FromContextOrDiscard(ctx).WithValues("managedFieldsEntries", entries)
+	managedFieldsPressure.WithLabelValues(resource.GVK.GroupKind().String()).Inc()
+
+	msg := fmt.Sprintf("%s has %d managedFields entries, which suggests that multiple clients are repeatedly modifying it", resourceName(resource), entries)
+	if c.recorder != nil {
+		c.recorder.Event(comp, corev1.EventTypeWarning, "ManagedFieldsPressure", msg)
+	}
+	if !c.managedFieldsCleanup || resource.Deleted() {
+		logger.V(0).Info("resource's managedFields have grown past the threshold")
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"managedFields":   []any{map[string]any{}},
+			"resourceVersion": current.GetResourceVersion(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.upstreamClient.Patch(ctx, current, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("resetting managedFields: %w", err)
+	}
+	managedFieldsCleanups.Inc()
+	logger.V(0).Info("reset resource's managedFields because they grew past the threshold")
+	return nil
+}
+
+func resourceName(resource *reconstitution.Resource) string {
+	name := resource.Ref.Name
+	if resource.Ref.Namespace != "" {
+		name = resource.Ref.Namespace + "/" + name
+	}
+	return resource.Ref.Kind + " " + name
+}
+
+// noopPatchLogThreshold is the number of consecutive no-op patches after which they're logged at the default verbosity.
+const noopPatchLogThreshold = 3
+
+// reportNoopPatch is called when a resource's version changed but the resulting patch was empty i.e. another client
+// modified fields that Eno doesn't manage. The most recent writer (per managedFields) is recorded so users can
+// distinguish benign churn (e.g. status updates) from clients that are actually fighting over the resource.
+func (c *Controller) reportNoopPatch(ctx context.Context, resource *reconstitution.Resource, current *unstructured.Unstructured) {
+	manager, subresource := "unknown", ""
+	var fields []string
+	if entry := latestManagedFieldsEntry(current); entry != nil {
+		manager, subresource = entry.Manager, entry.Subresource
+		if entry.FieldsV1 != nil {
+			fields = fieldPaths(entry.FieldsV1.Raw, 10)
+		}
+	}
+	noopPatches.WithLabelValues(resource.GVK.GroupKind().String(), manager, subresource).Inc()
+
+	count := resource.ObserveNoopPatch(true)
+	logger := logr.FromContextOrDiscard(ctx).WithValues("manager", manager, "subresource", subresource, "fields", fields, "consecutiveNoopPatches", count)
+	if count >= noopPatchLogThreshold && subresource == "" {
+		logger.V(0).Info("resource keeps changing without drifting from its desired state")
+		return
+	}
+	logger.V(1).Info("resource changed without drifting from its desired state")
+}
+
+// latestManagedFieldsEntry returns the managedFields entry that was most recently updated.
+func latestManagedFieldsEntry(obj *unstructured.Unstructured) *metav1.ManagedFieldsEntry {
+	entries := obj.GetManagedFields()
+	if len(entries) == 0 {
+		return nil
+	}
+	latest := &entries[len(entries)-1]
+	for i := range entries {
+		if entries[i].Time != nil && (latest.Time == nil || latest.Time.Before(entries[i].Time)) {
+			latest = &entries[i]
+		}
+	}
+	return latest
+}
+
+// fieldPaths returns up to max leaf field paths of the given FieldsV1 json, sorted.
+func fieldPaths(raw []byte, max int) []string {
+	tree := map[string]any{}
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return nil
+	}
+
+	var paths []string
+	var walk func(prefix string, node map[string]any)
+	walk = func(prefix string, node map[string]any) {
+		for key, val := range node {
+			if key == "." {
+				continue
+			}
+			key = strings.TrimPrefix(key, "f:")
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			child, _ := val.(map[string]any)
+			if len(child) == 0 || (len(child) == 1 && child["."] != nil) {
+				paths = append(paths, key)
+				continue
+			}
+			walk(key, child)
+		}
+	}
+	walk("", tree)
+
+	sort.Strings(paths)
+	if len(paths) > max {
+		paths = paths[:max]
+	}
+	return paths
+}
+
+// concurrentEdit returns the most recent write to the resource when it was made by another client i.e. not Eno and not a
+// status update, along with the remaining time in the composition's concurrent edit grace period (if any).
+func (c *Controller) concurrentEdit(comp *apiv1.Composition, current *unstructured.Unstructured, now time.Time) (*metav1.ManagedFieldsEntry, time.Duration) {
+	entry := latestManagedFieldsEntry(current)
+	if entry == nil || entry.Manager == c.fieldManager || entry.Subresource != "" {
+		return nil, 0
+	}
+	grace := comp.ConcurrentEditGracePeriod()
+	if grace <= 0 || entry.Time == nil {
+		return entry, 0
+	}
+	return entry, max(0, entry.Time.Add(grace).Sub(now))
+}
+
+// reportConcurrentEdit records that Eno either reverted ("takeover") or left in place ("backoff") a change
+// made to the resource by another client. Warning events are only emitted for compositions that opt in.
+func (c *Controller) reportConcurrentEdit(ctx context.Context, comp *apiv1.Composition, resource *reconstitution.Resource, entry *metav1.ManagedFieldsEntry, action string, remaining time.Duration) {
+	concurrentEdits.WithLabelValues(resource.GVK.GroupKind().String(), action).Inc()
+
+	var fields []string
+	if entry.FieldsV1 != nil {
+		fields = fieldPaths(entry.FieldsV1.Raw, 10)
+	}
+	logr.FromContextOrDiscard(ctx).V(1).Info("resource was modified by another client", "manager", entry.Manager, "fields", fields, "action", action)
+
+	if c.recorder == nil || !comp.ConcurrentEditWarningsEnabled() {
+		return
+	}
+	msg := fmt.Sprintf("%s was modified by %s and has been reverted to its desired state", resourceName(resource), entry.Manager)
+	if action == "backoff" {
+		msg = fmt.Sprintf("%s was modified by %s - waiting %s before reverting it", resourceName(resource), entry.Manager, remaining.Round(time.Second))
+	}
+	c.recorder.Event(comp, corev1.EventTypeWarning, "ConcurrentEdit", msg)
+}
+
+// fieldManagerForUserAgent returns the field manager name that apiserver assigns to requests with the given user agent.
+func fieldManagerForUserAgent(ua string) string {
+	if ua == "" {
+		ua = rest.DefaultKubernetesUserAgent()
+	}
+	manager, _, _ := strings.Cut(ua, "/")
+	return manager
+}
@@ -0,0 +1,182 @@
+package reconciliation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/reconstitution"
+	"github.com/Azure/eno/internal/testutil"
+)
+
+func TestCheckManagedFields(t *testing.T) {
+	ctx := testutil.NewContext(t)
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = "foo"
+	cm.Namespace = "default"
+	for i := 0; i < 10; i++ {
+		cm.ManagedFields = append(cm.ManagedFields, metav1.ManagedFieldsEntry{Manager: fmt.Sprintf("manager-%d", i), Operation: metav1.ManagedFieldsOperationUpdate})
+	}
+	cli := testutil.NewClient(t, cm)
+
+	res := &reconstitution.Resource{
+		Manifest: &apiv1.Manifest{},
+		GVK:      schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+	}
+	res.Ref.Name = cm.Name
+	res.Ref.Namespace = cm.Namespace
+
+	getCurrent := func() *unstructured.Unstructured {
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(res.GVK)
+		require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(cm), current))
+		return current
+	}
+	recorder := record.NewFakeRecorder(10)
+	comp := &apiv1.Composition{}
+
+	// Under the threshold
+	c := &Controller{upstreamClient: cli, recorder: recorder, managedFieldsThreshold: 10, managedFieldsCleanup: true}
+	require.NoError(t, c.checkManagedFields(ctx, comp, res, getCurrent()))
+	assert.Len(t, recorder.Events, 0)
+	assert.Len(t, getCurrent().GetManagedFields(), 10)
+
+	// Over the threshold without cleanup
+	c.managedFieldsThreshold = 5
+	c.managedFieldsCleanup = false
+	require.NoError(t, c.checkManagedFields(ctx, comp, res, getCurrent()))
+	assert.Len(t, recorder.Events, 1)
+	assert.Len(t, getCurrent().GetManagedFields(), 10)
+
+	// Over the threshold with cleanup
+	c.managedFieldsCleanup = true
+	require.NoError(t, c.checkManagedFields(ctx, comp, res, getCurrent()))
+	assert.Less(t, len(getCurrent().GetManagedFields()), 5)
+
+	// Disabled
+	c = &Controller{managedFieldsThreshold: 0}
+	current := &unstructured.Unstructured{Object: map[string]any{}}
+	current.SetManagedFields(cm.ManagedFields)
+	require.NoError(t, c.checkManagedFields(ctx, comp, res, current))
+}
+
+func TestLatestManagedFieldsEntry(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	assert.Nil(t, latestManagedFieldsEntry(obj))
+
+	early := metav1.NewTime(metav1.Now().Add(-time.Hour))
+	late := metav1.Now()
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "a", Time: &early},
+		{Manager: "b", Time: &late, Subresource: "status"},
+		{Manager: "c", Time: &early},
+	})
+	entry := latestManagedFieldsEntry(obj)
+	require.NotNil(t, entry)
+	assert.Equal(t, "b", entry.Manager)
+	assert.Equal(t, "status", entry.Subresource)
+}
+
+func TestFieldPaths(t *testing.T) {
+	raw := []byte(`{"f:metadata":{"f:labels":{".":{},"f:foo":{},"f:bar":{}}},"f:status":{"f:conditions":{"k:{\"type\":\"Ready\"}":{".":{},"f:status":{}}}}}`)
+	assert.Equal(t, []string{
+		"metadata.labels.bar",
+		"metadata.labels.foo",
+		`status.conditions.k:{"type":"Ready"}.status`,
+	}, fieldPaths(raw, 10))
+	assert.Len(t, fieldPaths(raw, 2), 2)
+	assert.Nil(t, fieldPaths([]byte("not json"), 10))
+}
+
+func TestReportNoopPatch(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	c := &Controller{}
+	res := &reconstitution.Resource{GVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}}
+
+	current := &unstructured.Unstructured{}
+	current.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl", FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:foo":{}}}}`)}}})
+
+	for i := 0; i < noopPatchLogThreshold; i++ {
+		c.reportNoopPatch(ctx, res, current)
+	}
+	assert.Equal(t, noopPatchLogThreshold+1, res.ObserveNoopPatch(true))
+	assert.Equal(t, 0, res.ObserveNoopPatch(false))
+}
+
+func TestConcurrentEdit(t *testing.T) {
+	now := time.Now().Truncate(time.Second) // managed fields timestamps have second precision
+	c := &Controller{fieldManager: "eno-reconciler"}
+	comp := &apiv1.Composition{}
+
+	newCurrent := func(entries ...metav1.ManagedFieldsEntry) *unstructured.Unstructured {
+		current := &unstructured.Unstructured{Object: map[string]any{}}
+		current.SetManagedFields(entries)
+		return current
+	}
+	edited := newCurrent(
+		metav1.ManagedFieldsEntry{Manager: "eno-reconciler", Time: &metav1.Time{Time: now.Add(-time.Hour)}},
+		metav1.ManagedFieldsEntry{Manager: "kubectl-edit", Time: &metav1.Time{Time: now.Add(-time.Minute)}},
+	)
+
+	// Most recently written by Eno
+	entry, _ := c.concurrentEdit(comp, newCurrent(metav1.ManagedFieldsEntry{Manager: "eno-reconciler", Time: &metav1.Time{Time: now}}), now)
+	assert.Nil(t, entry)
+
+	// Status updates aren't edits
+	entry, _ = c.concurrentEdit(comp, newCurrent(metav1.ManagedFieldsEntry{Manager: "some-operator", Subresource: "status", Time: &metav1.Time{Time: now}}), now)
+	assert.Nil(t, entry)
+
+	// Takeover by default
+	entry, remaining := c.concurrentEdit(comp, edited, now)
+	require.NotNil(t, entry)
+	assert.Equal(t, "kubectl-edit", entry.Manager)
+	assert.Zero(t, remaining)
+
+	// Back off within the grace period
+	comp.Annotations = map[string]string{apiv1.ConcurrentEditGracePeriodAnnotation: "10m"}
+	_, remaining = c.concurrentEdit(comp, edited, now)
+	assert.Equal(t, 9*time.Minute, remaining)
+
+	// Grace period has elapsed
+	_, remaining = c.concurrentEdit(comp, edited, now.Add(time.Hour))
+	assert.Zero(t, remaining)
+}
+
+func TestReportConcurrentEdit(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{recorder: recorder}
+	res := &reconstitution.Resource{GVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}}
+	res.Ref.Name = "foo"
+	res.Ref.Kind = "ConfigMap"
+	entry := &metav1.ManagedFieldsEntry{Manager: "kubectl-edit"}
+
+	// Warnings are opt-in
+	comp := &apiv1.Composition{}
+	c.reportConcurrentEdit(ctx, comp, res, entry, "takeover", 0)
+	assert.Len(t, recorder.Events, 0)
+
+	comp.Annotations = map[string]string{apiv1.ConcurrentEditWarningsAnnotation: "true"}
+	c.reportConcurrentEdit(ctx, comp, res, entry, "takeover", 0)
+	assert.Equal(t, "Warning ConcurrentEdit ConfigMap foo was modified by kubectl-edit and has been reverted to its desired state", <-recorder.Events)
+
+	c.reportConcurrentEdit(ctx, comp, res, entry, "backoff", time.Minute)
+	assert.Equal(t, "Warning ConcurrentEdit ConfigMap foo was modified by kubectl-edit - waiting 1m0s before reverting it", <-recorder.Events)
+}
+
+func TestFieldManagerForUserAgent(t *testing.T) {
+	assert.Equal(t, "eno-reconciler", fieldManagerForUserAgent("eno-reconciler"))
+	assert.Equal(t, "eno-reconciler", fieldManagerForUserAgent("eno-reconciler/v0.0.0 (linux/amd64) kubernetes/$Format"))
+	assert.NotEmpty(t, fieldManagerForUserAgent(""))
+}
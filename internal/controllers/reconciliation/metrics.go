@@ -2,7 +2,8 @@ package reconciliation
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
-	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/Azure/eno/internal/telemetry"
 )
 
 var (
@@ -24,10 +25,18 @@ var (
 	reconciliationActions = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "eno_reconciliation_actions_total",
-			Help: "Attempts to reconcile managed resources into the desired state, partitioned by action i.e. create, patch, delete",
+			Help: "Attempts to reconcile managed resources into the desired state, partitioned by action i.e. create, patch, status, delete",
 		}, []string{"action"},
 	)
 
+	downstreamGetLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "eno_downstream_get_duration_seconds",
+			Help:    "Samples latency of reading the current state of managed resources from the downstream apiserver, partitioned by resource type and encoding (json, protobuf, or metadata-only)",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5},
+		}, []string{"group", "kind", "encoding"},
+	)
+
 	reconciliationScheduleDelta = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Name:    "eno_reconciliation_schedule_delta_seconds",
@@ -35,8 +44,100 @@ var (
 			Buckets: []float64{0.1, 0.5, 1.0, 5.0, 15.0, 30.0, 60.0},
 		},
 	)
+
+	downstreamHealthy = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "eno_downstream_healthy",
+			Help: "1 when the downstream apiserver is healthy, 0 while reconciliation is paused because it's consistently unreachable or erroring",
+		},
+	)
+
+	circuitBreakerOpen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eno_circuit_breaker_open",
+			Help: "1 while writes to a resource type are paused because they consistently fail server-side e.g. due to an unavailable admission webhook",
+		}, []string{"kind"},
+	)
+
+	listRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "eno_list_cache_fills_total",
+			Help: "LIST requests sent to the downstream apiserver to serve reads of resource types configured for batching",
+		},
+	)
+
+	listCacheHits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "eno_list_cache_hits_total",
+			Help: "Reads of resources served from a LIST instead of a GET",
+		},
+	)
+
+	sliceStatusCacheMisses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "eno_slice_status_cache_misses_total",
+			Help: "Reads of resource slice status that weren't served by the reconstitution cache",
+		},
+	)
+
+	readinessRegressions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eno_readiness_regressions_total",
+			Help: "Resources that became unready (or were deleted out-of-band) after having become ready, partitioned by kind",
+		}, []string{"kind"},
+	)
+
+	managedFieldsEntries = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "eno_managed_fields_entries",
+			Help:    "Samples the number of managedFields entries of managed resources when they're read after changing",
+			Buckets: []float64{1, 2, 3, 5, 10, 20, 50, 100},
+		},
+	)
+
+	managedFieldsPressure = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eno_managed_fields_threshold_exceeded_total",
+			Help: "Reads of managed resources with more managedFields entries than the configured threshold, partitioned by kind",
+		}, []string{"kind"},
+	)
+
+	managedFieldsCleanups = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "eno_managed_fields_cleanups_total",
+			Help: "Managed resources whose managedFields were reset because they exceeded the configured threshold",
+		},
+	)
+
+	noopPatches = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eno_noop_patches_total",
+			Help: "Resources whose version changed without drifting from their desired state, partitioned by kind and the manager and subresource of their most recent write",
+		}, []string{"kind", "manager", "subresource"},
+	)
+
+	concurrentEdits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eno_concurrent_edits_total",
+			Help: "Changes made to managed resources by other clients, partitioned by kind and whether Eno reverted them or backed off",
+		}, []string{"kind", "action"},
+	)
+
+	requeues = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eno_reconciliation_requeues_total",
+			Help: "Resources requeued by the reconciliation controller, partitioned by reason e.g. crd-wait, readiness-wait, error",
+		}, []string{"reason"},
+	)
+
+	downstreamHealthProbeFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "eno_downstream_health_probe_failures_total",
+			Help: "Failed health probes against the downstream apiserver",
+		},
+	)
 )
 
 func init() {
-	metrics.Registry.MustRegister(reconciliationLatency, resourceVersionChanges, reconciliationActions, reconciliationScheduleDelta)
+	telemetry.MustRegister(reconciliationLatency, resourceVersionChanges, reconciliationActions, downstreamGetLatency, reconciliationScheduleDelta, downstreamHealthy, downstreamHealthProbeFailures, circuitBreakerOpen, listRequests, listCacheHits, sliceStatusCacheMisses, readinessRegressions, managedFieldsEntries, managedFieldsPressure, managedFieldsCleanups, noopPatches, concurrentEdits, requeues)
 }
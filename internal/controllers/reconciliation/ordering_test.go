@@ -7,9 +7,12 @@ import (
 	"slices"
 	"strconv"
 	"testing"
+	"time"
 
 	apiv1 "github.com/Azure/eno/api/v1"
 	testv1 "github.com/Azure/eno/internal/controllers/reconciliation/fixtures/v1"
+	"github.com/Azure/eno/internal/flowcontrol"
+	"github.com/Azure/eno/internal/reconstitution"
 	"github.com/Azure/eno/internal/testutil"
 	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
 	"github.com/stretchr/testify/assert"
@@ -157,6 +160,95 @@ func TestReadinessGroups(t *testing.T) {
 	})
 }
 
+func TestWaitForDeletion(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	mgr := testutil.NewManager(t)
+	upstream := mgr.GetClient()
+
+	registerControllers(t, mgr)
+	testutil.WithFakeExecutor(t, mgr, func(ctx context.Context, s *apiv1.Synthesizer, input *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+		output := &krmv1.ResourceList{}
+		output.Items = []*unstructured.Unstructured{
+			{
+				Object: map[string]any{
+					"apiVersion": "v1",
+					"kind":       "ConfigMap",
+					"metadata": map[string]any{
+						"name":      "test-obj-0",
+						"namespace": "default",
+						"annotations": map[string]string{
+							"eno.azure.io/wait-for-deletion": `[{"apiVersion": "v1", "kind": "ConfigMap", "name": "legacy"}]`,
+						},
+					},
+				},
+			},
+			{
+				Object: map[string]any{
+					"apiVersion": "v1",
+					"kind":       "ConfigMap",
+					"metadata": map[string]any{
+						"name":      "test-obj-1",
+						"namespace": "default",
+						"annotations": map[string]string{
+							"eno.azure.io/readiness-group": "1",
+						},
+					},
+				},
+			},
+		}
+		return output, nil
+	})
+
+	// Test subject - deletions don't trigger reconciliation so we rely on polling
+	cache := reconstitution.NewCache(mgr.GetClient())
+	rc, err := New(Options{
+		Manager:               mgr.Manager,
+		Cache:                 cache,
+		WriteBuffer:           flowcontrol.NewResourceSliceWriteBufferForManager(mgr.Manager, time.Millisecond*10, 1),
+		Downstream:            mgr.DownstreamRestConfig,
+		Timeout:               time.Minute,
+		ReadinessPollInterval: time.Millisecond * 100,
+	})
+	require.NoError(t, err)
+	require.NoError(t, reconstitution.New(mgr.Manager, cache, rc))
+	mgr.Start(t)
+
+	legacy := &corev1.ConfigMap{}
+	legacy.Name = "legacy"
+	legacy.Namespace = "default"
+	require.NoError(t, mgr.DownstreamClient.Create(ctx, legacy))
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = "test-syn"
+	require.NoError(t, upstream.Create(ctx, syn))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Synthesizer.Name = syn.Name
+	require.NoError(t, upstream.Create(ctx, comp))
+
+	// The first resource is created, but doesn't become ready while the legacy resource exists
+	testutil.Eventually(t, func() bool {
+		cm := &corev1.ConfigMap{}
+		return mgr.DownstreamClient.Get(ctx, client.ObjectKey{Name: "test-obj-0", Namespace: "default"}, cm) == nil
+	})
+	require.NoError(t, upstream.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Nil(t, comp.Status.CurrentSynthesis.Ready)
+
+	cm := &corev1.ConfigMap{}
+	err = mgr.DownstreamClient.Get(ctx, client.ObjectKey{Name: "test-obj-1", Namespace: "default"}, cm)
+	assert.True(t, errors.IsNotFound(err), "the next readiness group should not have been reconciled")
+
+	// Removing the legacy resource unblocks the next readiness group
+	require.NoError(t, mgr.DownstreamClient.Delete(ctx, legacy))
+	testutil.Eventually(t, func() bool {
+		err := upstream.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+		return err == nil && comp.Status.CurrentSynthesis != nil && comp.Status.CurrentSynthesis.Ready != nil
+	})
+	require.NoError(t, mgr.DownstreamClient.Get(ctx, client.ObjectKey{Name: "test-obj-1", Namespace: "default"}, cm))
+}
+
 func TestCRDOrdering(t *testing.T) {
 	if !testutil.AtLeastVersion(t, 16) {
 		t.Skipf("test does not support the old v1beta1 crd api")
@@ -0,0 +1,64 @@
+package reconciliation
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// patchFieldPaths returns the (sorted) set of field paths modified by a patch.
+// Values are intentionally not included so the result is always safe to log.
+func patchFieldPaths(patch []byte, patchType types.PatchType) []string {
+	if patchType == types.JSONPatchType {
+		ops := []struct {
+			Path string `json:"path"`
+		}{}
+		if err := json.Unmarshal(patch, &ops); err != nil {
+			return nil
+		}
+		paths := make([]string, len(ops))
+		for i, op := range ops {
+			paths[i] = op.Path
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	obj := map[string]any{}
+	if err := json.Unmarshal(patch, &obj); err != nil {
+		return nil
+	}
+	paths := []string{}
+	walkPatchPaths(obj, "", &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+func walkPatchPaths(obj map[string]any, prefix string, paths *[]string) {
+	for key, val := range obj {
+		if strings.HasPrefix(key, "$") {
+			continue // strategic merge patch directives
+		}
+		if prefix == "" && key == "metadata" {
+			// resourceVersion is always added to patches for optimistic concurrency
+			if mp, ok := val.(map[string]any); ok {
+				delete(mp, "resourceVersion")
+				if len(mp) == 0 {
+					continue
+				}
+			}
+		}
+
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if mp, ok := val.(map[string]any); ok && len(mp) > 0 {
+			walkPatchPaths(mp, path, paths)
+			continue
+		}
+		*paths = append(*paths, path)
+	}
+}
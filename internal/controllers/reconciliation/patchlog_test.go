@@ -0,0 +1,53 @@
+package reconciliation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestPatchFieldPaths(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Type     types.PatchType
+		Patch    string
+		Expected []string
+	}{
+		{
+			Name:     "merge",
+			Type:     types.MergePatchType,
+			Patch:    `{"metadata":{"resourceVersion":"123","labels":{"foo":"bar"}},"data":{"password":"secret","username":null}}`,
+			Expected: []string{"data.password", "data.username", "metadata.labels.foo"},
+		},
+		{
+			Name:     "resource version only",
+			Type:     types.MergePatchType,
+			Patch:    `{"metadata":{"resourceVersion":"123"},"spec":{"replicas":3}}`,
+			Expected: []string{"spec.replicas"},
+		},
+		{
+			Name:     "strategic",
+			Type:     types.StrategicMergePatchType,
+			Patch:    `{"spec":{"$setElementOrder/containers":[{"name":"app"}],"containers":[{"name":"app","image":"foo"}]}}`,
+			Expected: []string{"spec.containers"},
+		},
+		{
+			Name:     "json",
+			Type:     types.JSONPatchType,
+			Patch:    `[{"op":"add","path":"/data/foo","value":"secret"},{"op":"remove","path":"/data/bar"}]`,
+			Expected: []string{"/data/bar", "/data/foo"},
+		},
+		{
+			Name:  "invalid",
+			Type:  types.MergePatchType,
+			Patch: `not json`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, patchFieldPaths([]byte(tc.Patch), tc.Type))
+		})
+	}
+}
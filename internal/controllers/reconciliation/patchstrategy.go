@@ -0,0 +1,96 @@
+package reconciliation
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+)
+
+// PatchStrategy determines how existing resources are updated.
+type PatchStrategy string
+
+const (
+	// PatchStrategyMerge uses JSON merge patches, which replace lists wholesale.
+	PatchStrategyMerge PatchStrategy = "merge"
+
+	// PatchStrategyStrategic uses strategic merge patches. Requires the type's openapi model.
+	PatchStrategyStrategic PatchStrategy = "strategic"
+
+	// PatchStrategyApply uses server-side apply, which merges lists using the schema known to the apiserver.
+	// Falls back to merge patches for types whose apiserver doesn't support it (e.g. some aggregated apiservers).
+	PatchStrategyApply PatchStrategy = "apply"
+)
+
+// ParsePatchStrategies parses comma-separated Kind.group=strategy pairs e.g. "Widget.example.com=apply".
+func ParsePatchStrategies(input string) (map[schema.GroupKind]PatchStrategy, error) {
+	m := map[schema.GroupKind]PatchStrategy{}
+	for _, pair := range strings.Split(input, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kind, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid patch strategy %q: expected Kind.group=strategy", pair)
+		}
+		strategy, err := ParsePatchStrategy(val)
+		if err != nil {
+			return nil, err
+		}
+		m[schema.ParseGroupKind(kind)] = strategy
+	}
+	return m, nil
+}
+
+// ParsePatchStrategy returns an error if the given value isn't a known patch strategy.
+func ParsePatchStrategy(val string) (PatchStrategy, error) {
+	switch s := PatchStrategy(val); s {
+	case PatchStrategyMerge, PatchStrategyStrategic, PatchStrategyApply:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown patch strategy %q", val)
+	}
+}
+
+// patchStrategies picks the patch strategy for each resource type.
+type patchStrategies struct {
+	overrides  map[schema.GroupKind]PatchStrategy
+	schemaless PatchStrategy // used for types without a (usable) openapi model
+
+	// applyUnsupported holds the types whose apiserver rejected server-side apply.
+	applyUnsupported sync.Map
+}
+
+func newPatchStrategies(overrides map[schema.GroupKind]PatchStrategy, schemaless PatchStrategy) *patchStrategies {
+	if schemaless == "" || schemaless == PatchStrategyStrategic {
+		schemaless = PatchStrategyMerge
+	}
+	return &patchStrategies{overrides: overrides, schemaless: schemaless}
+}
+
+func (p *patchStrategies) Get(gvk schema.GroupVersionKind, model proto.Schema) PatchStrategy {
+	if p == nil {
+		p = newPatchStrategies(nil, "")
+	}
+	strategy, ok := p.overrides[gvk.GroupKind()]
+	if !ok || (strategy == PatchStrategyStrategic && model == nil) {
+		strategy = PatchStrategyStrategic
+		if model == nil {
+			strategy = p.schemaless
+		}
+	}
+	if _, unsupported := p.applyUnsupported.Load(gvk); unsupported && strategy == PatchStrategyApply {
+		return PatchStrategyMerge
+	}
+	return strategy
+}
+
+// ApplyUnsupported causes merge patches to be used for the given type instead of server-side apply.
+func (p *patchStrategies) ApplyUnsupported(gvk schema.GroupVersionKind) {
+	if p == nil {
+		return
+	}
+	p.applyUnsupported.Store(gvk, struct{}{})
+}
@@ -0,0 +1,100 @@
+package reconciliation
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kube-openapi/pkg/util/proto"
+)
+
+func TestParsePatchStrategies(t *testing.T) {
+	m, err := ParsePatchStrategies("Widget.example.com=apply, ConfigMap=merge,")
+	require.NoError(t, err)
+	assert.Equal(t, map[schema.GroupKind]PatchStrategy{
+		{Group: "example.com", Kind: "Widget"}: PatchStrategyApply,
+		{Kind: "ConfigMap"}:                    PatchStrategyMerge,
+	}, m)
+
+	_, err = ParsePatchStrategies("Widget.example.com")
+	assert.Error(t, err)
+
+	_, err = ParsePatchStrategies("Widget.example.com=json")
+	assert.Error(t, err)
+}
+
+func TestPatchStrategiesGet(t *testing.T) {
+	widget := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	gadget := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Gadget"}
+	model := &proto.Kind{}
+
+	tests := []struct {
+		Name       string
+		Overrides  map[schema.GroupKind]PatchStrategy
+		Schemaless PatchStrategy
+		GVK        schema.GroupVersionKind
+		Model      proto.Schema
+		Expected   PatchStrategy
+	}{
+		{Name: "model", GVK: widget, Model: model, Expected: PatchStrategyStrategic},
+		{Name: "no model", GVK: widget, Expected: PatchStrategyMerge},
+		{Name: "schemaless apply", Schemaless: PatchStrategyApply, GVK: widget, Expected: PatchStrategyApply},
+		{Name: "schemaless apply with model", Schemaless: PatchStrategyApply, GVK: widget, Model: model, Expected: PatchStrategyStrategic},
+		{Name: "override", Overrides: map[schema.GroupKind]PatchStrategy{widget.GroupKind(): PatchStrategyApply}, GVK: widget, Model: model, Expected: PatchStrategyApply},
+		{Name: "override other kind", Overrides: map[schema.GroupKind]PatchStrategy{widget.GroupKind(): PatchStrategyApply}, GVK: gadget, Expected: PatchStrategyMerge},
+		{Name: "strategic override without model", Overrides: map[schema.GroupKind]PatchStrategy{widget.GroupKind(): PatchStrategyStrategic}, GVK: widget, Expected: PatchStrategyMerge},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			p := newPatchStrategies(tc.Overrides, tc.Schemaless)
+			assert.Equal(t, tc.Expected, p.Get(tc.GVK, tc.Model))
+		})
+	}
+
+	// Fall back to merge when apply isn't supported
+	p := newPatchStrategies(nil, PatchStrategyApply)
+	p.ApplyUnsupported(widget)
+	assert.Equal(t, PatchStrategyMerge, p.Get(widget, nil))
+	assert.Equal(t, PatchStrategyApply, p.Get(gadget, nil))
+
+	// Nil is safe
+	var nilp *patchStrategies
+	nilp.ApplyUnsupported(widget)
+	assert.Equal(t, PatchStrategyMerge, nilp.Get(widget, nil))
+}
+
+func TestBuildPatchApply(t *testing.T) {
+	ctx := context.Background()
+	c := &Controller{discovery: staticSchema{}, patchStrategies: newPatchStrategies(nil, PatchStrategyApply)}
+
+	cm := map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]any{"name": "foo", "namespace": "default"},
+		"spec":       map[string]any{"items": []any{"a", "b"}},
+	}
+	current, prev := mapToResource(t, cm)
+	current.SetResourceVersion("1")
+
+	// No drift
+	patch, patchType, err := c.buildPatch(ctx, &apiv1.Composition{}, prev, prev, current)
+	require.NoError(t, err)
+	assert.Empty(t, patch)
+	assert.Equal(t, types.ApplyPatchType, patchType)
+
+	// The entire desired state is applied
+	_, next := mapToResource(t, map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]any{"name": "foo", "namespace": "default"},
+		"spec":       map[string]any{"items": []any{"a", "b", "c"}},
+	})
+	patch, patchType, err = c.buildPatch(ctx, &apiv1.Composition{}, prev, next, current)
+	require.NoError(t, err)
+	assert.Equal(t, types.ApplyPatchType, patchType)
+	assert.JSONEq(t, next.Manifest.Manifest, string(patch))
+}
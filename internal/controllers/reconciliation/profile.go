@@ -0,0 +1,131 @@
+package reconciliation
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+// ProfileLabel selects the behavior profile of a composition, taking precedence over namespace mappings.
+const ProfileLabel = "eno.azure.io/profile"
+
+// Profiles bundle reconciliation behavior settings such that compositions in different environments
+// (e.g. dev and prod namespaces) can be reconciled with different risk postures by the same reconciler.
+type Profiles struct {
+	Profiles map[string]*Profile `json:"profiles"`
+
+	// Namespaces maps composition namespaces to profile names.
+	Namespaces map[string]string `json:"namespaces,omitempty"`
+
+	// Default is the profile of compositions that aren't otherwise mapped to a profile.
+	Default string `json:"default,omitempty"`
+}
+
+// Profile holds reconciliation behavior settings.
+type Profile struct {
+	// ReconcileIntervalScale multiplies the reconcile interval of every resource. Defaults to 1.
+	ReconcileIntervalScale float64 `json:"reconcileIntervalScale,omitempty"`
+
+	// MaxConcurrentWrites bounds the number of resources that can be created, updated, or deleted at once
+	// across all compositions using the profile. Zero means unlimited.
+	MaxConcurrentWrites int `json:"maxConcurrentWrites,omitempty"`
+
+	// DisableDriftCorrection stops resources from being updated once they've been reconciled for the current synthesis.
+	// Changes made outside of Eno are left alone until the next synthesis.
+	DisableDriftCorrection bool `json:"disableDriftCorrection,omitempty"`
+
+	writes chan struct{}
+}
+
+// LoadProfiles reads yaml or json encoded Profiles from the given file.
+func LoadProfiles(path string) (*Profiles, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading profiles: %w", err)
+	}
+	p := &Profiles{}
+	if err := yaml.UnmarshalStrict(raw, p); err != nil {
+		return nil, fmt.Errorf("parsing profiles: %w", err)
+	}
+	return p, p.init()
+}
+
+func (p *Profiles) init() error {
+	for name, profile := range p.Profiles {
+		if profile == nil {
+			profile = &Profile{}
+			p.Profiles[name] = profile
+		}
+		if profile.ReconcileIntervalScale < 0 {
+			return fmt.Errorf("profile %q: reconcileIntervalScale must not be negative", name)
+		}
+		if profile.MaxConcurrentWrites < 0 {
+			return fmt.Errorf("profile %q: maxConcurrentWrites must not be negative", name)
+		}
+		if profile.MaxConcurrentWrites > 0 {
+			profile.writes = make(chan struct{}, profile.MaxConcurrentWrites)
+		}
+	}
+	for ns, name := range p.Namespaces {
+		if _, ok := p.Profiles[name]; !ok {
+			return fmt.Errorf("namespace %q references unknown profile %q", ns, name)
+		}
+	}
+	if _, ok := p.Profiles[p.Default]; p.Default != "" && !ok {
+		return fmt.Errorf("default profile %q does not exist", p.Default)
+	}
+	return nil
+}
+
+// For returns the profile of the given composition, or nil if it doesn't have one.
+// Unknown profile labels are ignored in favor of the namespace mapping and default profile.
+func (p *Profiles) For(comp *apiv1.Composition) *Profile {
+	if p == nil {
+		return nil
+	}
+	if profile, ok := p.Profiles[comp.Labels[ProfileLabel]]; ok {
+		return profile
+	}
+	if profile, ok := p.Profiles[p.Namespaces[comp.Namespace]]; ok {
+		return profile
+	}
+	return p.Profiles[p.Default]
+}
+
+// ScaleInterval applies the profile's scaling factor to the given reconcile interval.
+func (p *Profile) ScaleInterval(d time.Duration) time.Duration {
+	if p == nil || p.ReconcileIntervalScale == 0 {
+		return d
+	}
+	return time.Duration(float64(d) * p.ReconcileIntervalScale)
+}
+
+// CorrectsDrift returns true if resources that have already been reconciled should be updated when they change.
+func (p *Profile) CorrectsDrift() bool {
+	return p == nil || !p.DisableDriftCorrection
+}
+
+// TryAcquireWrite returns false if the profile's concurrent write limit has been reached.
+// Callers must call ReleaseWrite when the write completes.
+func (p *Profile) TryAcquireWrite() bool {
+	if p == nil || p.writes == nil {
+		return true
+	}
+	select {
+	case p.writes <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Profile) ReleaseWrite() {
+	if p == nil || p.writes == nil {
+		return
+	}
+	<-p.writes
+}
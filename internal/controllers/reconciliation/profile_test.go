@@ -0,0 +1,117 @@
+package reconciliation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	tests := []struct {
+		Name  string
+		YAML  string
+		Error bool
+	}{
+		{
+			Name: "valid",
+			YAML: "profiles:\n  dev:\n    reconcileIntervalScale: 0.5\n  prod:\n    maxConcurrentWrites: 2\nnamespaces:\n  prod-ns: prod\ndefault: dev\n",
+		},
+		{
+			Name:  "unknown field",
+			YAML:  "profiles:\n  dev:\n    reconcileIntervalScal: 0.5\n",
+			Error: true,
+		},
+		{
+			Name:  "negative scale",
+			YAML:  "profiles:\n  dev:\n    reconcileIntervalScale: -1\n",
+			Error: true,
+		},
+		{
+			Name:  "negative concurrency",
+			YAML:  "profiles:\n  dev:\n    maxConcurrentWrites: -1\n",
+			Error: true,
+		},
+		{
+			Name:  "unknown namespace profile",
+			YAML:  "profiles:\n  dev: {}\nnamespaces:\n  prod-ns: prod\n",
+			Error: true,
+		},
+		{
+			Name:  "unknown default profile",
+			YAML:  "profiles:\n  dev: {}\ndefault: prod\n",
+			Error: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "profiles.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.YAML), 0644))
+
+			_, err := LoadProfiles(path)
+			if tc.Error {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	_, err := LoadProfiles(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestProfilesFor(t *testing.T) {
+	dev, prod, test := &Profile{}, &Profile{}, &Profile{}
+	p := &Profiles{
+		Profiles:   map[string]*Profile{"dev": dev, "prod": prod, "test": test},
+		Namespaces: map[string]string{"prod-ns": "prod"},
+		Default:    "dev",
+	}
+
+	comp := &apiv1.Composition{ObjectMeta: metav1.ObjectMeta{Namespace: "other-ns"}}
+	assert.Same(t, dev, p.For(comp))
+
+	comp.Namespace = "prod-ns"
+	assert.Same(t, prod, p.For(comp))
+
+	comp.Labels = map[string]string{ProfileLabel: "test"}
+	assert.Same(t, test, p.For(comp))
+
+	comp.Labels = map[string]string{ProfileLabel: "unknown"}
+	assert.Same(t, prod, p.For(comp))
+
+	p.Default = ""
+	comp.Namespace = "other-ns"
+	assert.Nil(t, p.For(comp))
+
+	p = nil
+	assert.Nil(t, p.For(comp))
+}
+
+func TestProfileBehavior(t *testing.T) {
+	var p *Profile
+	assert.Equal(t, time.Minute, p.ScaleInterval(time.Minute))
+	assert.True(t, p.CorrectsDrift())
+	assert.True(t, p.TryAcquireWrite())
+	p.ReleaseWrite()
+
+	ps := &Profiles{Profiles: map[string]*Profile{"prod": {ReconcileIntervalScale: 1.5, MaxConcurrentWrites: 2, DisableDriftCorrection: true}}}
+	require.NoError(t, ps.init())
+	p = ps.Profiles["prod"]
+
+	assert.Equal(t, 90*time.Second, p.ScaleInterval(time.Minute))
+	assert.False(t, p.CorrectsDrift())
+
+	assert.True(t, p.TryAcquireWrite())
+	assert.True(t, p.TryAcquireWrite())
+	assert.False(t, p.TryAcquireWrite())
+	p.ReleaseWrite()
+	assert.True(t, p.TryAcquireWrite())
+}
@@ -0,0 +1,54 @@
+package reconciliation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Azure/eno/internal/testutil"
+)
+
+func TestProtobufGetter(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	p := &protobufGetter{
+		scheme: scheme,
+		client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}, Data: map[string]string{"foo": "bar"}},
+		).Build(),
+	}
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+	// Native types are converted to unstructured
+	obj, ok, err := p.Get(ctx, gvk, types.NamespacedName{Name: "a", Namespace: "default"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, gvk, obj.GroupVersionKind())
+	assert.Equal(t, map[string]any{"foo": "bar"}, obj.Object["data"])
+
+	// Errors are returned as-is
+	_, ok, err = p.Get(ctx, gvk, types.NamespacedName{Name: "b", Namespace: "default"})
+	assert.True(t, ok)
+	assert.True(t, errors.IsNotFound(err))
+
+	// Custom resources fall back to json
+	_, ok, err = p.Get(ctx, schema.GroupVersionKind{Group: "enotest.azure.io", Version: "v1", Kind: "TestResource"}, types.NamespacedName{Name: "a", Namespace: "default"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Disabled
+	p = nil
+	_, ok, err = p.Get(ctx, gvk, types.NamespacedName{Name: "a", Namespace: "default"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,91 @@
+package reconciliation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/reconstitution"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// secretRefPattern matches placeholders like $(secret:namespace/name/key) in synthesized manifests.
+var secretRefPattern = regexp.MustCompile(`\$\(secret:([^/()]+)/([^/()]+)/([^/()]+)\)`)
+
+// resolveSecretRefs replaces secret reference placeholders in the given json with values read from Secrets in the composition's namespace (upstream).
+// This keeps secret values out of resource slices - they're only read when the resource is applied.
+//
+// Secrets are read without a cache, so each call reads every referenced secret once.
+func (c *Controller) resolveSecretRefs(ctx context.Context, comp *apiv1.Composition, js []byte) ([]byte, error) {
+	if c.secretReader == nil || !bytes.Contains(js, []byte("$(secret:")) {
+		return js, nil
+	}
+
+	secrets := map[types.NamespacedName]*corev1.Secret{}
+	var resolveErr error
+	resolved := secretRefPattern.ReplaceAllFunc(js, func(match []byte) []byte {
+		if resolveErr != nil {
+			return match
+		}
+		parts := secretRefPattern.FindSubmatch(match)
+		nsn := types.NamespacedName{Namespace: string(parts[1]), Name: string(parts[2])}
+		key := string(parts[3])
+		if nsn.Namespace != comp.Namespace {
+			resolveErr = reconcile.TerminalError(fmt.Errorf("referenced secret %q is not in the composition's namespace", nsn))
+			return match
+		}
+
+		secret, ok := secrets[nsn]
+		if !ok {
+			secret = &corev1.Secret{}
+			if err := c.secretReader.Get(ctx, nsn, secret); err != nil {
+				resolveErr = fmt.Errorf("getting referenced secret %q: %w", nsn, err)
+				return match
+			}
+			secrets[nsn] = secret
+		}
+		val, ok := secret.Data[key]
+		if !ok {
+			resolveErr = fmt.Errorf("referenced secret %q has no key %q", nsn, key)
+			return match
+		}
+
+		// Placeholders are always within json strings
+		escaped, err := json.Marshal(string(val))
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		return escaped[1 : len(escaped)-1]
+	})
+	return resolved, resolveErr
+}
+
+// resolveObjectSecretRefs is equivalent to resolveSecretRefs, but for parsed objects.
+func (c *Controller) resolveObjectSecretRefs(ctx context.Context, comp *apiv1.Composition, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if c.secretReader == nil {
+		return obj, nil
+	}
+	js, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	js, err = c.resolveSecretRefs(ctx, comp, js)
+	if err != nil {
+		return nil, err
+	}
+	resolved := &unstructured.Unstructured{}
+	return resolved, resolved.UnmarshalJSON(js)
+}
+
+// hasSecretRefs returns true when the resource's manifest contains secret reference placeholders.
+func hasSecretRefs(resource *reconstitution.Resource) bool {
+	return resource.Manifest != nil && strings.Contains(resource.Manifest.Manifest, "$(secret:")
+}
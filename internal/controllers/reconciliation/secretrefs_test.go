@@ -0,0 +1,77 @@
+package reconciliation
+
+import (
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/reconstitution"
+	"github.com/Azure/eno/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestResolveSecretRefs(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	secret := &corev1.Secret{}
+	secret.Name = "test-secret"
+	secret.Namespace = "default"
+	secret.Data = map[string][]byte{"password": []byte(`p@ss"word`)}
+	require.NoError(t, cli.Create(ctx, secret))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+
+	c := &Controller{secretReader: cli}
+
+	// Values are escaped
+	js, err := c.resolveSecretRefs(ctx, comp, []byte(`{"data":{"password":"$(secret:default/test-secret/password)","url":"https://user:$(secret:default/test-secret/password)@host"}}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":{"password":"p@ss\"word","url":"https://user:p@ss\"word@host"}}`, string(js))
+
+	// Missing keys and secrets are errors
+	_, err = c.resolveSecretRefs(ctx, comp, []byte(`{"value":"$(secret:default/test-secret/nope)"}`))
+	assert.Error(t, err)
+	_, err = c.resolveSecretRefs(ctx, comp, []byte(`{"value":"$(secret:default/nope/password)"}`))
+	assert.Error(t, err)
+
+	// Secrets in other namespaces can't be referenced
+	_, err = c.resolveSecretRefs(ctx, comp, []byte(`{"value":"$(secret:kube-system/test-secret/password)"}`))
+	assert.Error(t, err)
+
+	// Objects are resolved too
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"data":       map[string]any{"password": "$(secret:default/test-secret/password)"},
+	}}
+	obj, err = c.resolveObjectSecretRefs(ctx, comp, obj)
+	require.NoError(t, err)
+	val, _, _ := unstructured.NestedString(obj.Object, "data", "password")
+	assert.Equal(t, `p@ss"word`, val)
+
+	// Disabled by default
+	c = &Controller{}
+	js, err = c.resolveSecretRefs(ctx, comp, []byte(`{"value":"$(secret:default/test-secret/password)"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"value":"$(secret:default/test-secret/password)"}`, string(js))
+}
+
+func TestShouldLogPatch(t *testing.T) {
+	gk := schema.GroupKind{Kind: "ConfigMap"}
+	c := &Controller{logPatchGroupKinds: map[schema.GroupKind]struct{}{gk: {}}}
+
+	res := &reconstitution.Resource{GVK: gk.WithVersion("v1"), Manifest: &apiv1.Manifest{Manifest: `{"data":{"foo":"bar"}}`}}
+	assert.True(t, c.shouldLogPatch(res))
+
+	res.Manifest.Manifest = `{"data":{"foo":"$(secret:default/test-secret/password)"}}`
+	assert.False(t, c.shouldLogPatch(res), "patches may contain resolved secret values")
+
+	res = &reconstitution.Resource{GVK: schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, Manifest: &apiv1.Manifest{Manifest: `{}`}}
+	assert.False(t, c.shouldLogPatch(res), "kind isn't configured")
+}
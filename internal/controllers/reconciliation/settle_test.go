@@ -0,0 +1,100 @@
+package reconciliation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/reconstitution"
+	"github.com/Azure/eno/internal/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+// settleTestClient serves a fixed previous readiness group and resource states.
+type settleTestClient struct {
+	reconstitution.Client
+	prev   []*reconstitution.Resource
+	states map[*reconstitution.Resource]*apiv1.ResourceState
+}
+
+func (s *settleTestClient) RangeByReadinessGroup(ctx context.Context, syn *reconstitution.SynthesisRef, group int, dir reconstitution.RangeDirection) []*reconstitution.Resource {
+	return s.prev
+}
+
+func (s *settleTestClient) GetStatus(ctx context.Context, syn *reconstitution.SynthesisRef, res *reconstitution.Resource) (*apiv1.ResourceState, bool) {
+	state, ok := s.states[res]
+	return state, ok
+}
+
+func TestWaitForSettle(t *testing.T) {
+	ctx := context.Background()
+	webhook := &reconstitution.Resource{
+		Ref:      resource.Ref{Name: "webhook", Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"},
+		GVK:      schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"},
+		Manifest: &apiv1.Manifest{},
+	}
+	annotated := &reconstitution.Resource{
+		Ref:            resource.Ref{Name: "cm", Namespace: "default", Kind: "ConfigMap"},
+		GVK:            schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		SettleDuration: ptr.To(time.Hour),
+		Manifest:       &apiv1.Manifest{},
+	}
+	plain := &reconstitution.Resource{
+		Ref:      resource.Ref{Name: "other", Namespace: "default", Kind: "ConfigMap"},
+		GVK:      schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		Manifest: &apiv1.Manifest{},
+	}
+
+	justReady := &apiv1.ResourceState{Ready: ptr.To(metav1.Now())}
+	longReady := &apiv1.ResourceState{Ready: ptr.To(metav1.NewTime(time.Now().Add(-2 * time.Hour)))}
+
+	res := &reconstitution.Resource{Ref: resource.Ref{Name: "next", Kind: "ConfigMap"}, ReadinessGroup: 1}
+	syn := &reconstitution.SynthesisRef{}
+
+	t.Run("admission kind default", func(t *testing.T) {
+		cli := &settleTestClient{prev: []*reconstitution.Resource{webhook, plain}, states: map[*reconstitution.Resource]*apiv1.ResourceState{webhook: justReady, plain: justReady}}
+		c := &Controller{resourceClient: cli, admissionSettle: time.Minute}
+
+		result, waiting, err := c.waitForSettle(ctx, syn, res)
+		require.NoError(t, err)
+		assert.True(t, waiting)
+		assert.Greater(t, result.RequeueAfter, 50*time.Second)
+		assert.LessOrEqual(t, result.RequeueAfter, time.Minute)
+		assert.Equal(t, "WaitingForSettle", res.Explain().Decision)
+		assert.Equal(t, "ValidatingWebhookConfiguration webhook", res.Explain().WaitingOnSettle)
+	})
+
+	t.Run("admission kind disabled", func(t *testing.T) {
+		cli := &settleTestClient{prev: []*reconstitution.Resource{webhook}, states: map[*reconstitution.Resource]*apiv1.ResourceState{webhook: justReady}}
+		c := &Controller{resourceClient: cli}
+
+		_, waiting, err := c.waitForSettle(ctx, syn, res)
+		require.NoError(t, err)
+		assert.False(t, waiting)
+	})
+
+	t.Run("annotation overrides default", func(t *testing.T) {
+		cli := &settleTestClient{prev: []*reconstitution.Resource{webhook, annotated}, states: map[*reconstitution.Resource]*apiv1.ResourceState{webhook: justReady, annotated: justReady}}
+		c := &Controller{resourceClient: cli, admissionSettle: time.Minute}
+
+		result, waiting, err := c.waitForSettle(ctx, syn, res)
+		require.NoError(t, err)
+		assert.True(t, waiting)
+		assert.Greater(t, result.RequeueAfter, 59*time.Minute)
+		assert.Equal(t, "ConfigMap default/cm", res.Explain().WaitingOnSettle)
+	})
+
+	t.Run("settled", func(t *testing.T) {
+		cli := &settleTestClient{prev: []*reconstitution.Resource{webhook, annotated}, states: map[*reconstitution.Resource]*apiv1.ResourceState{webhook: longReady, annotated: longReady}}
+		c := &Controller{resourceClient: cli, admissionSettle: time.Minute}
+
+		_, waiting, err := c.waitForSettle(ctx, syn, res)
+		require.NoError(t, err)
+		assert.False(t, waiting)
+	})
+}
@@ -3,14 +3,17 @@ package reconciliation
 import (
 	"context"
 	"testing"
+	"time"
 
 	apiv1 "github.com/Azure/eno/api/v1"
 	testv1 "github.com/Azure/eno/internal/controllers/reconciliation/fixtures/v1"
 	"github.com/Azure/eno/internal/testutil"
 	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -155,4 +158,182 @@ func TestReconcileStatus(t *testing.T) {
 	})
 }
 
+// TestHandsOff proves that updates are suspended while the resource's hands-off annotation is in the future.
+func TestHandsOff(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	mgr := testutil.NewManager(t)
+	upstream := mgr.GetClient()
+
+	setupTestSubject(t, mgr)
+	mgr.Start(t)
+	_, comp := writeGenericComposition(t, upstream)
+
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = comp.Namespace
+	require.NoError(t, controllerutil.SetControllerReference(comp, slice, upstream.Scheme()))
+	slice.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{ "kind": "ConfigMap", "apiVersion": "v1", "metadata": { "name": "test", "namespace": "default", "annotations": { "eno.azure.io/reconcile-interval": "100ms" } }, "data": { "foo": "bar" } }`},
+	}
+	require.NoError(t, upstream.Create(ctx, slice))
+
+	now := metav1.Now()
+	err := retry.RetryOnConflict(testutil.Backoff, func() error {
+		upstream.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+		comp.Status.CurrentSynthesis = &apiv1.Synthesis{
+			UUID:           uuid.NewString(),
+			Synthesized:    &now,
+			ResourceSlices: []*apiv1.ResourceSliceRef{{Name: slice.Name}},
+		}
+		return upstream.Status().Update(ctx, comp)
+	})
+	require.NoError(t, err)
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = "test"
+	cm.Namespace = "default"
+	testutil.Eventually(t, func() bool {
+		return mgr.DownstreamClient.Get(ctx, client.ObjectKeyFromObject(cm), cm) == nil
+	})
+
+	// Manual changes are left alone while the resource is hands-off
+	setOverride := func(deadline time.Time, value string) {
+		err := retry.RetryOnConflict(testutil.Backoff, func() error {
+			mgr.DownstreamClient.Get(ctx, client.ObjectKeyFromObject(cm), cm)
+			cm.Annotations = map[string]string{"eno.azure.io/hands-off-until": deadline.Format(time.RFC3339)}
+			cm.Data["foo"] = value
+			return mgr.DownstreamClient.Update(ctx, cm)
+		})
+		require.NoError(t, err)
+	}
+	setOverride(time.Now().Add(time.Hour), "manual")
+
+	testutil.Eventually(t, func() bool {
+		err = upstream.Get(ctx, client.ObjectKeyFromObject(slice), slice)
+		return err == nil && len(slice.Status.Resources) == 1 && slice.Status.Resources[0].HandsOffUntil != nil
+	})
+	require.NoError(t, mgr.DownstreamClient.Get(ctx, client.ObjectKeyFromObject(cm), cm))
+	assert.Equal(t, "manual", cm.Data["foo"])
+
+	// Changes are reverted once the deadline has passed
+	setOverride(time.Now().Add(-time.Minute), "manual")
+	testutil.Eventually(t, func() bool {
+		err = mgr.DownstreamClient.Get(ctx, client.ObjectKeyFromObject(cm), cm)
+		return err == nil && cm.Data["foo"] == "bar"
+	})
+	testutil.Eventually(t, func() bool {
+		err = upstream.Get(ctx, client.ObjectKeyFromObject(slice), slice)
+		return err == nil && len(slice.Status.Resources) == 1 && slice.Status.Resources[0].HandsOffUntil == nil
+	})
+}
+
+// TestExpiry proves that resources are deleted once their expires-after duration has elapsed, and aren't recreated.
+func TestExpiry(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	mgr := testutil.NewManager(t)
+	upstream := mgr.GetClient()
+
+	setupTestSubject(t, mgr)
+	mgr.Start(t)
+	_, comp := writeGenericComposition(t, upstream)
+
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = comp.Namespace
+	require.NoError(t, controllerutil.SetControllerReference(comp, slice, upstream.Scheme()))
+	slice.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{ "kind": "ConfigMap", "apiVersion": "v1", "metadata": { "name": "test", "namespace": "default", "annotations": { "eno.azure.io/expires-after": "2s", "eno.azure.io/reconcile-interval": "100ms" } } }`},
+	}
+	require.NoError(t, upstream.Create(ctx, slice))
+
+	now := metav1.Now()
+	err := retry.RetryOnConflict(testutil.Backoff, func() error {
+		upstream.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+		comp.Status.CurrentSynthesis = &apiv1.Synthesis{
+			UUID:           uuid.NewString(),
+			Synthesized:    &now,
+			ResourceSlices: []*apiv1.ResourceSliceRef{{Name: slice.Name}},
+		}
+		return upstream.Status().Update(ctx, comp)
+	})
+	require.NoError(t, err)
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = "test"
+	cm.Namespace = "default"
+	testutil.Eventually(t, func() bool {
+		return mgr.DownstreamClient.Get(ctx, client.ObjectKeyFromObject(cm), cm) == nil
+	})
+
+	// The expiry is reported before the resource expires
+	testutil.Eventually(t, func() bool {
+		err = upstream.Get(ctx, client.ObjectKeyFromObject(slice), slice)
+		return err == nil && len(slice.Status.Resources) == 1 && slice.Status.Resources[0].ExpiresAt != nil
+	})
+	assert.True(t, cm.CreationTimestamp.Add(2*time.Second).Equal(slice.Status.Resources[0].ExpiresAt.Time))
+
+	// The resource is deleted once it expires
+	testutil.Eventually(t, func() bool {
+		err = upstream.Get(ctx, client.ObjectKeyFromObject(slice), slice)
+		return err == nil && len(slice.Status.Resources) == 1 && slice.Status.Resources[0].Expired && slice.Status.Resources[0].Deleted && slice.Status.Resources[0].Ready != nil
+	})
+	assert.True(t, errors.IsNotFound(mgr.DownstreamClient.Get(ctx, client.ObjectKeyFromObject(cm), cm)))
+
+	// ...and isn't recreated
+	time.Sleep(time.Second)
+	assert.True(t, errors.IsNotFound(mgr.DownstreamClient.Get(ctx, client.ObjectKeyFromObject(cm), cm)))
+}
+
 func isNotReady(state apiv1.ResourceState) bool { return state.Ready == nil }
+
+// TestApplyStatus proves that the status of resources annotated with eno.azure.io/apply-status is written using the status subresource.
+func TestApplyStatus(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	mgr := testutil.NewManager(t)
+	upstream := mgr.GetClient()
+	downstream := mgr.DownstreamClient
+
+	registerControllers(t, mgr)
+	testutil.WithFakeExecutor(t, mgr, func(ctx context.Context, s *apiv1.Synthesizer, input *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+		output := &krmv1.ResourceList{}
+		output.Items = []*unstructured.Unstructured{{
+			Object: map[string]any{
+				"apiVersion": "enotest.azure.io/v1",
+				"kind":       "TestResource",
+				"metadata": map[string]any{
+					"name":        "test-obj",
+					"namespace":   "default",
+					"annotations": map[string]string{"eno.azure.io/apply-status": "true"},
+				},
+				"status": map[string]any{"phase": s.Spec.Image},
+			},
+		}}
+		return output, nil
+	})
+
+	setupTestSubject(t, mgr)
+	mgr.Start(t)
+	syn, _ := writeGenericComposition(t, upstream)
+
+	// Status is applied after the resource is created
+	obj := &testv1.TestResource{}
+	obj.Name = "test-obj"
+	obj.Namespace = "default"
+	testutil.Eventually(t, func() bool {
+		err := downstream.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		return err == nil && obj.Status.Phase == "create"
+	})
+
+	// ...and updated along with the synthesis
+	err := retry.RetryOnConflict(testutil.Backoff, func() error {
+		upstream.Get(ctx, client.ObjectKeyFromObject(syn), syn)
+		syn.Spec.Image = "update"
+		return upstream.Update(ctx, syn)
+	})
+	require.NoError(t, err)
+
+	testutil.Eventually(t, func() bool {
+		err := downstream.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		return err == nil && obj.Status.Phase == "update"
+	})
+}
@@ -0,0 +1,180 @@
+package reconciliation
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/manager"
+)
+
+// StatusMirrorLabel is set on the status mirror ConfigMaps written to the downstream cluster.
+const StatusMirrorLabel = "eno.azure.io/status-mirror"
+
+// statusMirrorController maintains a ConfigMap in the downstream cluster for each composition that summarizes
+// the composition's status, so that users with access only to the downstream cluster can see what Eno is doing there.
+type statusMirrorController struct {
+	client    client.Client // upstream
+	mirrors   client.Client // downstream
+	namespace string
+}
+
+func newStatusMirrorController(mgr ctrl.Manager, downstream client.Client, namespace string) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("statusMirrorController").
+		For(&apiv1.Composition{}).
+		WithLogConstructor(manager.NewLogConstructor(mgr, "statusMirrorController")).
+		Complete(manager.WithController("statusMirrorController", &statusMirrorController{
+			client:    mgr.GetClient(),
+			mirrors:   downstream,
+			namespace: namespace,
+		}))
+}
+
+func (s *statusMirrorController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	mirror := newStatusMirror(req.NamespacedName, s.namespace)
+
+	comp := &apiv1.Composition{}
+	err := s.client.Get(ctx, req.NamespacedName, comp)
+	if errors.IsNotFound(err) {
+		err = s.mirrors.Delete(ctx, mirror)
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("deleting status mirror: %w", err)
+		}
+		logger.V(1).Info("deleted status mirror")
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(configMapGVK)
+	err = s.mirrors.Get(ctx, client.ObjectKeyFromObject(mirror), current)
+	if errors.IsNotFound(err) {
+		setStatusMirrorData(mirror, comp)
+		err = s.mirrors.Create(ctx, mirror)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("creating status mirror: %w", err)
+		}
+		logger.V(1).Info("created status mirror")
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("getting status mirror: %w", err)
+	}
+
+	patch := client.MergeFrom(current.DeepCopy())
+	if !setStatusMirrorData(current, comp) {
+		return ctrl.Result{}, nil
+	}
+	err = s.mirrors.Patch(ctx, current, patch)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("updating status mirror: %w", err)
+	}
+	logger.V(1).Info("updated status mirror")
+	return ctrl.Result{}, nil
+}
+
+func newStatusMirror(comp types.NamespacedName, namespace string) *unstructured.Unstructured {
+	mirror := &unstructured.Unstructured{}
+	mirror.SetGroupVersionKind(configMapGVK)
+	mirror.SetName(fmt.Sprintf("eno-status.%s.%s", comp.Namespace, comp.Name))
+	mirror.SetNamespace(namespace)
+	return mirror
+}
+
+// setStatusMirrorData sets the metadata and data of a status mirror to reflect the composition. Returns true if any changed.
+func setStatusMirrorData(mirror *unstructured.Unstructured, comp *apiv1.Composition) bool {
+	var changed bool
+
+	labels := mirror.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	if labels[StatusMirrorLabel] != "true" {
+		labels[StatusMirrorLabel] = "true"
+		changed = true
+	}
+	mirror.SetLabels(labels)
+
+	anno := mirror.GetAnnotations()
+	if anno == nil {
+		anno = map[string]string{}
+	}
+	for key, val := range map[string]string{
+		apiv1.CompositionNameAnnotation:      comp.Name,
+		apiv1.CompositionNamespaceAnnotation: comp.Namespace,
+	} {
+		if anno[key] != val {
+			anno[key] = val
+			changed = true
+		}
+	}
+	mirror.SetAnnotations(anno)
+
+	data := statusMirrorData(comp)
+	current, _, _ := unstructured.NestedStringMap(mirror.Object, "data")
+	if !maps.Equal(current, data) {
+		unstructured.SetNestedStringMap(mirror.Object, data, "data")
+		changed = true
+	}
+
+	return changed
+}
+
+// statusMirrorData summarizes the status of a composition as ConfigMap data.
+func statusMirrorData(comp *apiv1.Composition) map[string]string {
+	data := map[string]string{
+		"synthesizer": comp.Spec.Synthesizer.Name,
+		"health":      string(comp.Status.Health),
+	}
+	if s := comp.Status.Simplified; s != nil {
+		data["status"] = s.Status
+		if s.Error != "" {
+			data["error"] = s.Error
+		}
+	}
+	if comp.DeletionTimestamp != nil {
+		data["deleting"] = "true"
+		if p := comp.Status.DeletionProgress; p != nil {
+			data["remainingResources"] = strconv.Itoa(p.RemainingResources)
+		}
+	}
+
+	syn := comp.Status.CurrentSynthesis
+	if syn == nil {
+		return data
+	}
+	data["synthesisUUID"] = syn.UUID
+	for key, ts := range map[string]*metav1.Time{
+		"synthesized": syn.Synthesized,
+		"reconciled":  syn.Reconciled,
+		"ready":       syn.Ready,
+	} {
+		if ts != nil {
+			data[key] = ts.UTC().Format(time.RFC3339)
+		}
+	}
+	if p := syn.Progress; p != nil {
+		data["resources"] = strconv.Itoa(p.Total)
+		data["resourcesReconciled"] = strconv.Itoa(p.Reconciled)
+		data["resourcesReady"] = strconv.Itoa(p.Ready)
+	}
+	return data
+}
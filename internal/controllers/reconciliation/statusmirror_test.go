@@ -0,0 +1,73 @@
+package reconciliation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+func TestSetStatusMirrorData(t *testing.T) {
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Synthesizer.Name = "test-synth"
+	comp.Status.Health = apiv1.HealthProgressing
+	comp.Status.Simplified = &apiv1.SimplifiedStatus{Status: "Reconciling"}
+
+	mirror := newStatusMirror(types.NamespacedName{Name: comp.Name, Namespace: comp.Namespace}, "eno-system")
+	assert.Equal(t, "eno-status.default.test-comp", mirror.GetName())
+	assert.Equal(t, "eno-system", mirror.GetNamespace())
+
+	assert.True(t, setStatusMirrorData(mirror, comp))
+	assert.Equal(t, "true", mirror.GetLabels()[StatusMirrorLabel])
+	assert.Equal(t, map[string]string{
+		apiv1.CompositionNameAnnotation:      "test-comp",
+		apiv1.CompositionNamespaceAnnotation: "default",
+	}, mirror.GetAnnotations())
+	assert.Equal(t, map[string]string{
+		"synthesizer": "test-synth",
+		"health":      "Progressing",
+		"status":      "Reconciling",
+	}, mirrorData(t, mirror))
+
+	// Idempotence
+	assert.False(t, setStatusMirrorData(mirror, comp))
+
+	// Synthesis progress
+	synthesized := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{
+		UUID:        "test-uuid",
+		Synthesized: &synthesized,
+		Progress:    &apiv1.SynthesisProgress{Total: 10, Reconciled: 9, Ready: 5, Percent: 50},
+	}
+	assert.True(t, setStatusMirrorData(mirror, comp))
+	assert.Equal(t, map[string]string{
+		"synthesizer":         "test-synth",
+		"health":              "Progressing",
+		"status":              "Reconciling",
+		"synthesisUUID":       "test-uuid",
+		"synthesized":         "2024-01-01T00:00:00Z",
+		"resources":           "10",
+		"resourcesReconciled": "9",
+		"resourcesReady":      "5",
+	}, mirrorData(t, mirror))
+
+	// Deletion
+	comp.DeletionTimestamp = &synthesized
+	comp.Status.DeletionProgress = &apiv1.DeletionProgress{RemainingResources: 3}
+	assert.True(t, setStatusMirrorData(mirror, comp))
+	assert.Equal(t, "true", mirrorData(t, mirror)["deleting"])
+	assert.Equal(t, "3", mirrorData(t, mirror)["remainingResources"])
+}
+
+func mirrorData(t *testing.T, mirror *unstructured.Unstructured) map[string]string {
+	data, _, err := unstructured.NestedStringMap(mirror.Object, "data")
+	assert.NoError(t, err)
+	return data
+}
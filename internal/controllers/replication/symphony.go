@@ -28,10 +28,10 @@ func NewSymphonyController(mgr ctrl.Manager) error {
 		For(&apiv1.Symphony{}).
 		Owns(&apiv1.Composition{}).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "symphonyReplicationController")).
-		Complete(&symphonyController{
+		Complete(manager.WithController("symphonyReplicationController", &symphonyController{
 			client: mgr.GetClient(),
 			reader: mgr.GetAPIReader(),
-		})
+		}))
 }
 
 func (c *symphonyController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -0,0 +1,69 @@
+package rollout
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+// CanaryLabel designates a composition as a canary for its synthesizer.
+const CanaryLabel = "eno.azure.io/canary"
+
+func isCanary(comp *apiv1.Composition) bool {
+	return comp.Labels[CanaryLabel] == "true"
+}
+
+// canariesSoaked returns true when every canary composition has been ready with the synthesizer's
+// current generation for at least the soak period. Otherwise, the time until the soak period will
+// elapse is also returned (zero if it can't be known yet).
+//
+// Canaries that can't receive the current generation (e.g. their inputs aren't in lockstep) are skipped
+// rather than blocking the rollout indefinitely. Canaries that failed synthesis with the current generation
+// block the rollout, and are returned so they can be surfaced in the synthesizer's status.
+func canariesSoaked(syn *apiv1.Synthesizer, comps []apiv1.Composition, now time.Time, soak time.Duration) (soaked bool, wait time.Duration, failed []*apiv1.Composition) {
+	soaked = true
+	for i := range comps {
+		comp := &comps[i]
+		if !isCanary(comp) || comp.Status.CurrentSynthesis == nil || comp.DeletionTimestamp != nil || comp.ShouldIgnoreSideEffects() {
+			continue
+		}
+
+		current := comp.Status.CurrentSynthesis
+		if !isInSync(comp, syn) {
+			if comp.Status.PendingResynthesis == nil && (comp.InputsOutOfLockstep(syn) || comp.PinMismatched(syn) || comp.SequencingBlocked()) {
+				continue // can't receive the current generation yet
+			}
+			soaked = false
+			continue
+		}
+		if current.Failed() {
+			failed = append(failed, comp)
+			soaked = false
+			continue
+		}
+		if current.Ready == nil {
+			soaked = false // the composition will be requeued when it becomes ready
+			continue
+		}
+		if remaining := soak - now.Sub(current.Ready.Time); remaining > wait {
+			wait = remaining
+		}
+	}
+	if !soaked {
+		return false, 0, failed
+	}
+	return wait <= 0, wait, nil
+}
+
+// canaryFailedMessage describes the canaries blocking the synthesizer's rollout.
+func canaryFailedMessage(failed []*apiv1.Composition) string {
+	names := make([]string, len(failed))
+	for i, comp := range failed {
+		names[i] = comp.Namespace + "/" + comp.Name
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("canary compositions failed synthesis: %s", strings.Join(names, ", "))
+}
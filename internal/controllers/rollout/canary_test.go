@@ -0,0 +1,80 @@
+package rollout
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestCanariesSoaked(t *testing.T) {
+	now := time.Now()
+	syn := &apiv1.Synthesizer{}
+	syn.Generation = 2
+
+	canary := apiv1.Composition{}
+	canary.Labels = map[string]string{CanaryLabel: "true"}
+	canary.Status.CurrentSynthesis = &apiv1.Synthesis{ObservedSynthesizerGeneration: 1, Synthesized: &metav1.Time{Time: now}}
+
+	other := apiv1.Composition{}
+	other.Status.CurrentSynthesis = &apiv1.Synthesis{ObservedSynthesizerGeneration: 1, Synthesized: &metav1.Time{Time: now}}
+
+	// No canaries
+	soaked, wait, _ := canariesSoaked(syn, []apiv1.Composition{other}, now, time.Minute)
+	assert.True(t, soaked)
+	assert.Zero(t, wait)
+
+	// Canary hasn't been resynthesized yet
+	soaked, wait, _ = canariesSoaked(syn, []apiv1.Composition{canary, other}, now, time.Minute)
+	assert.False(t, soaked)
+	assert.Zero(t, wait)
+
+	// Canary is resynthesized but not ready
+	canary.Status.CurrentSynthesis.ObservedSynthesizerGeneration = 2
+	soaked, _, _ = canariesSoaked(syn, []apiv1.Composition{canary, other}, now, time.Minute)
+	assert.False(t, soaked)
+
+	// Canary is ready but still soaking
+	canary.Status.CurrentSynthesis.Ready = &metav1.Time{Time: now.Add(-time.Second * 15)}
+	soaked, wait, _ = canariesSoaked(syn, []apiv1.Composition{canary, other}, now, time.Minute)
+	assert.False(t, soaked)
+	assert.Equal(t, time.Second*45, wait)
+
+	// Canary has soaked
+	soaked, _, _ = canariesSoaked(syn, []apiv1.Composition{canary, other}, now.Add(time.Minute), time.Minute)
+	assert.True(t, soaked)
+
+	// Canary failed synthesis
+	canary.Status.CurrentSynthesis.Results = []apiv1.Result{{Severity: "error"}}
+	soaked, _, failed := canariesSoaked(syn, []apiv1.Composition{canary, other}, now.Add(time.Minute), time.Minute)
+	assert.False(t, soaked)
+	assert.Len(t, failed, 1)
+}
+
+func TestCanariesSoakedIneligible(t *testing.T) {
+	now := time.Now()
+	syn := &apiv1.Synthesizer{}
+	syn.Generation = 2
+	syn.Spec.Refs = []apiv1.Ref{{Key: "foo"}, {Key: "bar"}}
+
+	// Canary can't be resynthesized because its inputs aren't in lockstep
+	canary := apiv1.Composition{}
+	canary.Labels = map[string]string{CanaryLabel: "true"}
+	canary.Spec.Bindings = []apiv1.Binding{{Key: "foo"}, {Key: "bar"}}
+	canary.Status.InputRevisions = []apiv1.InputRevisions{{Key: "foo", Revision: ptr.To(1)}, {Key: "bar", Revision: ptr.To(2)}}
+	canary.Status.CurrentSynthesis = &apiv1.Synthesis{ObservedSynthesizerGeneration: 1, Synthesized: &metav1.Time{Time: now}}
+	require.True(t, canary.InputsOutOfLockstep(syn))
+
+	soaked, _, failed := canariesSoaked(syn, []apiv1.Composition{canary}, now, time.Minute)
+	assert.True(t, soaked)
+	assert.Empty(t, failed)
+
+	// Staged resynthesis is still waited on
+	canary.Status.PendingResynthesis = &metav1.Time{Time: now}
+	soaked, _, _ = canariesSoaked(syn, []apiv1.Composition{canary}, now, time.Minute)
+	assert.False(t, soaked)
+}
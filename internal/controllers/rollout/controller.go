@@ -8,9 +8,13 @@ import (
 
 	"github.com/go-logr/logr"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	apiv1 "github.com/Azure/eno/api/v1"
 	"github.com/Azure/eno/internal/controllers/synthesis"
@@ -29,20 +33,26 @@ func NewController(mgr ctrl.Manager, cooldown time.Duration) error {
 	}
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("rolloutController").
-		Watches(&apiv1.Composition{}, manager.SingleEventHandler()).
+		Watches(&apiv1.Composition{}, newDeferredSynthesisHandler()).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "rolloutController")).
-		Complete(c)
+		Complete(manager.WithController("rolloutController", c))
 }
 
 func (c *controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := logr.FromContextOrDiscard(ctx)
-
 	comps := &apiv1.CompositionList{}
 	err := c.client.List(ctx, comps)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("listing compositions: %w", err)
 	}
 
+	// Canaries receive synthesizer changes without waiting for the cooldown period
+	for i := range comps.Items {
+		comp := &comps.Items[i]
+		if isCanary(comp) && comp.Status.PendingResynthesisReason == apiv1.SynthesisReasonSynthesizerRollout && dispatchable(comp) {
+			return c.dispatch(ctx, comp)
+		}
+	}
+
 	// Find the current cooldown period, wait for the next if needed
 	// Block on any active deferred syntheses with less than 2 attempts to avoid high concurrency
 	var lastDeferredSynthesisCompletion *metav1.Time
@@ -71,6 +81,7 @@ func (c *controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	for _, comp := range comps.Items {
 		if comp.Status.PendingResynthesis != nil && comp.ShouldIgnoreSideEffects() {
 			comp.Status.PendingResynthesis = nil
+			comp.Status.PendingResynthesisReason = ""
 			err := c.client.Status().Update(ctx, &comp)
 			if err != nil {
 				return ctrl.Result{}, fmt.Errorf("clearing PendingResythesis field for %s in namespace %s: %w", comp.Name, comp.Namespace, err)
@@ -84,12 +95,9 @@ func (c *controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ptr.Deref(comps.Items[j].Status.PendingResynthesis, metav1.Time{}).After(ptr.Deref(comps.Items[i].Status.PendingResynthesis, metav1.Time{}).Time)
 	})
 
-	for _, comp := range comps.Items {
-		logger := logger.WithValues("compositionName", comp.Name,
-			"compositionNamespace", comp.Namespace,
-			"compositionGeneration", comp.Generation,
-			"synthesisID", comp.Status.GetCurrentSynthesisUUID())
-		if comp.Status.PendingResynthesis == nil || comp.Status.CurrentSynthesis == nil {
+	for i := range comps.Items {
+		comp := &comps.Items[i]
+		if !dispatchable(comp) {
 			continue
 		}
 
@@ -101,19 +109,68 @@ func (c *controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			}
 		}
 
-		pendingTime := comp.Status.PendingResynthesis
-		synthesis.SwapStates(&comp)
-		comp.Status.CurrentSynthesis.Deferred = true
-		comp.Status.PendingResynthesis = nil
-		err = c.client.Status().Update(ctx, &comp)
-		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("initiating resynthesis: %w", err)
-		}
-
-		logger.Info("progressing deferred resynthesis", "latency", time.Since(pendingTime.Time).Abs().Milliseconds())
-		return ctrl.Result{RequeueAfter: c.cooldown}, nil
+		return c.dispatch(ctx, comp)
 	}
 
 	// drop the work item until a composition changes
 	return ctrl.Result{}, nil
 }
+
+// newDeferredSynthesisHandler enqueues the controller's single work item only for events that can affect deferred syntheses.
+// Every reconcile lists all compositions, so skipping unrelated events (e.g. resource status aggregation) avoids
+// an O(total compositions) pass every time any composition changes.
+func newDeferredSynthesisHandler() handler.EventHandler {
+	apply := func(rli workqueue.RateLimitingInterface, objs ...client.Object) {
+		for _, obj := range objs {
+			comp, ok := obj.(*apiv1.Composition)
+			if ok && involvesDeferredSynthesis(comp) {
+				rli.Add(reconcile.Request{})
+				return
+			}
+		}
+	}
+	return &handler.Funcs{
+		CreateFunc: func(ctx context.Context, ce event.CreateEvent, rli workqueue.RateLimitingInterface) {
+			apply(rli, ce.Object)
+		},
+		UpdateFunc: func(ctx context.Context, ue event.UpdateEvent, rli workqueue.RateLimitingInterface) {
+			apply(rli, ue.ObjectOld, ue.ObjectNew)
+		},
+		DeleteFunc: func(ctx context.Context, de event.DeleteEvent, rli workqueue.RateLimitingInterface) {
+			apply(rli, de.Object)
+		},
+	}
+}
+
+func involvesDeferredSynthesis(comp *apiv1.Composition) bool {
+	return comp.Status.PendingResynthesis != nil || (comp.Status.CurrentSynthesis != nil && comp.Status.CurrentSynthesis.Deferred)
+}
+
+func dispatchable(comp *apiv1.Composition) bool {
+	return comp.Status.PendingResynthesis != nil && comp.Status.CurrentSynthesis != nil && !comp.SequencingBlocked()
+}
+
+// dispatch starts the composition's pending resynthesis.
+func (c *controller) dispatch(ctx context.Context, comp *apiv1.Composition) (ctrl.Result, error) {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("compositionName", comp.Name,
+		"compositionNamespace", comp.Namespace,
+		"compositionGeneration", comp.Generation,
+		"synthesisID", comp.Status.GetCurrentSynthesisUUID())
+
+	pendingTime := comp.Status.PendingResynthesis
+	reason := comp.Status.PendingResynthesisReason
+	if reason == "" {
+		reason = apiv1.SynthesisReasonManual // set by some other client
+	}
+	synthesis.SwapStates(comp, reason)
+	comp.Status.CurrentSynthesis.Deferred = true
+	comp.Status.PendingResynthesis = nil
+	comp.Status.PendingResynthesisReason = ""
+	err := c.client.Status().Update(ctx, comp)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("initiating resynthesis: %w", err)
+	}
+
+	logger.Info("progressing deferred resynthesis", "latency", time.Since(pendingTime.Time).Abs().Milliseconds(), "reason", reason)
+	return ctrl.Result{RequeueAfter: c.cooldown}, nil
+}
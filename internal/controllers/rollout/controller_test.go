@@ -9,8 +9,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 func TestController(t *testing.T) {
@@ -250,6 +253,68 @@ func TestController(t *testing.T) {
 	}
 }
 
+func TestControllerPendingResynthesisReason(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test"
+	require.NoError(t, cli.Create(ctx, comp))
+	comp.Status.PendingResynthesis = inThePast(4)
+	comp.Status.PendingResynthesisReason = apiv1.SynthesisReasonInputChanged
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{Synthesized: inThePast(8)}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	c := &controller{client: cli, cooldown: time.Second}
+	_, err := c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Equal(t, apiv1.SynthesisReasonInputChanged, comp.Status.CurrentSynthesis.Reason)
+	assert.Nil(t, comp.Status.PendingResynthesis)
+	assert.Empty(t, comp.Status.PendingResynthesisReason)
+
+	// Resynthesis requested by other clients
+	comp.Status.PendingResynthesis = inThePast(4)
+	comp.Status.CurrentSynthesis.Synthesized = inThePast(8)
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	_, err = c.Reconcile(ctx, ctrl.Request{})
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Equal(t, apiv1.SynthesisReasonManual, comp.Status.CurrentSynthesis.Reason)
+}
+
+func TestDeferredSynthesisHandler(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	h := newDeferredSynthesisHandler()
+
+	unrelated := &apiv1.Composition{}
+	unrelated.Status.CurrentSynthesis = &apiv1.Synthesis{Synthesized: inThePast(8)}
+
+	pending := unrelated.DeepCopy()
+	pending.Status.PendingResynthesis = inThePast(1)
+
+	deferred := unrelated.DeepCopy()
+	deferred.Status.CurrentSynthesis.Deferred = true
+
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+
+	h.Update(ctx, event.UpdateEvent{ObjectOld: unrelated, ObjectNew: unrelated}, q)
+	h.Create(ctx, event.CreateEvent{Object: unrelated}, q)
+	assert.Equal(t, 0, q.Len())
+
+	h.Update(ctx, event.UpdateEvent{ObjectOld: pending, ObjectNew: unrelated}, q)
+	assert.Equal(t, 1, q.Len())
+
+	q.Get()
+	q.Done(ctrl.Request{})
+	h.Delete(ctx, event.DeleteEvent{Object: deferred}, q)
+	assert.Equal(t, 1, q.Len())
+}
+
 func inThePast(seconds int) *metav1.Time {
 	return ptr.To(metav1.Time{Time: time.Now().Add(-time.Duration(seconds) * time.Second)})
 }
@@ -0,0 +1,109 @@
+package rollout
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/resource"
+)
+
+const defaultReadinessTimeout = time.Minute * 15
+
+// maxRollbackSliceJsonBytes matches the executor's limit for synthesized resource slices.
+const maxRollbackSliceJsonBytes = 1024 * 512
+
+// evaluateRolloutPolicy finds compositions that have failed synthesis or readiness since being resynthesized
+// with the synthesizer's current generation, and determines whether they exceed the rollout policy's threshold.
+// The returned duration is the time until the next pending composition's readiness timeout expires, if any.
+func evaluateRolloutPolicy(syn *apiv1.Synthesizer, comps []apiv1.Composition, now time.Time) (failed []*apiv1.Composition, abort bool, requeue time.Duration) {
+	policy := syn.Spec.Rollout
+	if policy == nil {
+		return nil, false, 0
+	}
+	timeout := defaultReadinessTimeout
+	if policy.ReadinessTimeout != nil {
+		timeout = policy.ReadinessTimeout.Duration
+	}
+
+	var total int
+	for i := range comps {
+		comp := &comps[i]
+		current := comp.Status.CurrentSynthesis
+		if current == nil || comp.Status.PreviousSynthesis == nil || comp.DeletionTimestamp != nil || !isInSync(comp, syn) {
+			continue // only resynthesized compositions are considered
+		}
+
+		if current.Failed() {
+			total++
+			failed = append(failed, comp)
+			continue
+		}
+		if current.Synthesized == nil {
+			continue
+		}
+		total++
+		if current.Ready != nil {
+			continue
+		}
+
+		remaining := timeout - now.Sub(current.Synthesized.Time)
+		if remaining <= 0 {
+			failed = append(failed, comp)
+			continue
+		}
+		if requeue == 0 || remaining < requeue {
+			requeue = remaining
+		}
+	}
+
+	abort = total > 0 && len(failed)*100 > policy.MaxFailurePercent*total
+	return failed, abort, requeue
+}
+
+// rolloutAborted returns true when the synthesizer's current generation should not be rolled out any further.
+func rolloutAborted(status *apiv1.SynthesizerStatus) bool {
+	return meta.IsStatusConditionTrue(status.Conditions, apiv1.FailedRolloutCondition)
+}
+
+// rollbackSynthesis returns a synthesis that restores the resources of the composition's previous synthesis,
+// along with the resource slices that hold them. Resources that were only created by the failed current synthesis
+// are tombstoned, and the failed synthesis becomes the previous synthesis so that its removals are reconciled.
+func rollbackSynthesis(comp *apiv1.Composition, previous, failed []*apiv1.ResourceSlice) (*apiv1.Synthesis, []*apiv1.ResourceSlice, error) {
+	var outputs []*unstructured.Unstructured
+	for _, slice := range previous {
+		for i, res := range slice.Spec.Resources {
+			if res.Deleted {
+				continue
+			}
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON([]byte(res.Manifest)); err != nil {
+				return nil, nil, fmt.Errorf("decoding resource %d of slice %s: %w", i, slice.Name, err)
+			}
+			outputs = append(outputs, obj)
+		}
+	}
+
+	slices, err := resource.Slice(comp, failed, outputs, maxRollbackSliceJsonBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("slicing previous resources: %w", err)
+	}
+
+	synthesis := comp.Status.PreviousSynthesis.DeepCopy()
+	synthesis.UUID = uuid.NewSHA1(uuid.NameSpaceOID, []byte("eno.azure.io/rollback/"+comp.Status.CurrentSynthesis.UUID)).String()
+	synthesis.Reconciled = nil
+	synthesis.Ready = nil
+	synthesis.ReadinessMessages = nil
+	synthesis.Progress = nil
+	synthesis.ResourceSlices = nil
+	synthesis.Reason = apiv1.SynthesisReasonRollback
+	for _, slice := range slices {
+		slice.Spec.SynthesisUUID = synthesis.UUID
+		slice.Spec.Attempt = 0
+	}
+	return synthesis, slices, nil
+}
@@ -0,0 +1,120 @@
+package rollout
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEvaluateRolloutPolicy(t *testing.T) {
+	now := time.Now()
+	syn := &apiv1.Synthesizer{}
+	syn.Generation = 2
+
+	previous := &apiv1.Synthesis{ObservedSynthesizerGeneration: 1}
+	comps := []apiv1.Composition{
+		{ // ready
+			Status: apiv1.CompositionStatus{
+				PreviousSynthesis: previous,
+				CurrentSynthesis:  &apiv1.Synthesis{ObservedSynthesizerGeneration: 2, Synthesized: &metav1.Time{Time: now.Add(-time.Hour)}, Ready: &metav1.Time{Time: now}},
+			},
+		},
+		{ // failed synthesis
+			Status: apiv1.CompositionStatus{
+				PreviousSynthesis: previous,
+				CurrentSynthesis:  &apiv1.Synthesis{ObservedSynthesizerGeneration: 2, Results: []apiv1.Result{{Severity: "error"}}},
+			},
+		},
+		{ // readiness timeout expired
+			Status: apiv1.CompositionStatus{
+				PreviousSynthesis: previous,
+				CurrentSynthesis:  &apiv1.Synthesis{ObservedSynthesizerGeneration: 2, Synthesized: &metav1.Time{Time: now.Add(-time.Hour)}},
+			},
+		},
+		{ // still waiting for readiness
+			Status: apiv1.CompositionStatus{
+				PreviousSynthesis: previous,
+				CurrentSynthesis:  &apiv1.Synthesis{ObservedSynthesizerGeneration: 2, Synthesized: &metav1.Time{Time: now.Add(-time.Minute * 10)}},
+			},
+		},
+		{ // not yet resynthesized
+			Status: apiv1.CompositionStatus{
+				CurrentSynthesis: &apiv1.Synthesis{ObservedSynthesizerGeneration: 1, Synthesized: &metav1.Time{Time: now.Add(-time.Hour)}},
+			},
+		},
+	}
+
+	// No policy
+	failed, abort, requeue := evaluateRolloutPolicy(syn, comps, now)
+	assert.Empty(t, failed)
+	assert.False(t, abort)
+	assert.Zero(t, requeue)
+
+	// Under threshold
+	syn.Spec.Rollout = &apiv1.RolloutPolicy{MaxFailurePercent: 50}
+	failed, abort, requeue = evaluateRolloutPolicy(syn, comps, now)
+	assert.Len(t, failed, 2)
+	assert.False(t, abort)
+	assert.Equal(t, time.Minute*5, requeue)
+
+	// Over threshold
+	syn.Spec.Rollout.MaxFailurePercent = 49
+	_, abort, _ = evaluateRolloutPolicy(syn, comps, now)
+	assert.True(t, abort)
+
+	// Longer readiness timeout
+	syn.Spec.Rollout.ReadinessTimeout = &metav1.Duration{Duration: time.Hour * 2}
+	failed, abort, requeue = evaluateRolloutPolicy(syn, comps, now)
+	assert.Len(t, failed, 1)
+	assert.False(t, abort)
+	assert.Equal(t, time.Hour, requeue)
+}
+
+func TestRollbackSynthesis(t *testing.T) {
+	previous := &apiv1.Synthesis{UUID: "previous", ObservedSynthesizerGeneration: 1, Ready: &metav1.Time{}, Reason: apiv1.SynthesisReasonInitial}
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Status.PreviousSynthesis = previous
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "current", ObservedSynthesizerGeneration: 2}
+
+	previousSlices := []*apiv1.ResourceSlice{{
+		Spec: apiv1.ResourceSliceSpec{
+			Resources: []apiv1.Manifest{
+				{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"existing","namespace":"default"}}`},
+				{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"removed","namespace":"default"}}`, Deleted: true},
+			},
+		},
+	}}
+	failedSlices := []*apiv1.ResourceSlice{{
+		Spec: apiv1.ResourceSliceSpec{
+			Resources: []apiv1.Manifest{
+				{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"existing","namespace":"default"},"data":{"foo":"bar"}}`},
+				{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"added","namespace":"default"}}`},
+			},
+		},
+	}}
+
+	next, slices, err := rollbackSynthesis(comp, previousSlices, failedSlices)
+	require.NoError(t, err)
+	assert.Equal(t, apiv1.SynthesisReasonRollback, next.Reason)
+	assert.Equal(t, int64(1), next.ObservedSynthesizerGeneration)
+	assert.NotEqual(t, previous.UUID, next.UUID)
+	assert.Nil(t, next.Ready)
+	assert.Equal(t, "previous", comp.Status.PreviousSynthesis.UUID, "inputs are not mutated")
+
+	require.Len(t, slices, 1)
+	assert.Equal(t, next.UUID, slices[0].Spec.SynthesisUUID)
+	assert.Equal(t, []apiv1.Manifest{
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"existing","namespace":"default"}}` + "\n"}, // re-encoded by resource.Slice
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"added","namespace":"default"}}`, Deleted: true},
+	}, slices[0].Spec.Resources)
+
+	// The same failed synthesis always rolls back to the same synthesis
+	again, _, err := rollbackSynthesis(comp, previousSlices, failedSlices)
+	require.NoError(t, err)
+	assert.Equal(t, next.UUID, again.UUID)
+}
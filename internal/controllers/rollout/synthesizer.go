@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sort"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
@@ -18,22 +21,66 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/controllers/synthesis"
 	"github.com/Azure/eno/internal/manager"
 )
 
 type synthController struct {
-	client client.Client
+	client        client.Client
+	noCacheReader client.Reader
+	canarySoak    time.Duration
 }
 
-func NewSynthesizerController(mgr ctrl.Manager) error {
+func NewSynthesizerController(mgr ctrl.Manager, canarySoak time.Duration) error {
 	c := &synthController{
-		client: mgr.GetClient(),
+		client:        mgr.GetClient(),
+		noCacheReader: mgr.GetAPIReader(),
+		canarySoak:    canarySoak,
 	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&apiv1.Synthesizer{}).
 		Watches(&apiv1.Composition{}, newCompositionHandler()).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "synthesizerRolloutController")).
-		Complete(c)
+		Complete(manager.WithController("synthesizerRolloutController", c))
+}
+
+// rollback restores the resources of the composition's previous synthesis by writing them to a new synthesis.
+// The failed synthesis is kept as the previous synthesis so that resources it created are deleted.
+func (c *synthController) rollback(ctx context.Context, comp *apiv1.Composition) error {
+	previous, err := c.getSlices(ctx, comp, comp.Status.PreviousSynthesis)
+	if err != nil {
+		return fmt.Errorf("getting previous resource slices: %w", err)
+	}
+	failed, err := c.getSlices(ctx, comp, comp.Status.CurrentSynthesis)
+	if err != nil {
+		return fmt.Errorf("getting failed resource slices: %w", err)
+	}
+
+	next, slices, err := rollbackSynthesis(comp, previous, failed)
+	if err != nil {
+		return err
+	}
+	next.ResourceSlices, err = synthesis.WriteSlices(ctx, c.client, c.noCacheReader, comp, slices, comp.Name+"-rollback-"+next.UUID[:8])
+	if err != nil {
+		return err
+	}
+
+	comp.Status.PreviousSynthesis = comp.Status.CurrentSynthesis
+	comp.Status.CurrentSynthesis = next
+	return c.client.Status().Update(ctx, comp)
+}
+
+func (c *synthController) getSlices(ctx context.Context, comp *apiv1.Composition, syn *apiv1.Synthesis) ([]*apiv1.ResourceSlice, error) {
+	slices := make([]*apiv1.ResourceSlice, len(syn.ResourceSlices))
+	for i, ref := range syn.ResourceSlices {
+		slice := &apiv1.ResourceSlice{}
+		err := c.noCacheReader.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: comp.Namespace}, slice)
+		if err != nil {
+			return nil, err
+		}
+		slices[i] = slice
+	}
+	return slices, nil
 }
 
 func (c *synthController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -54,9 +101,103 @@ func (c *synthController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, fmt.Errorf("listing compositions: %w", err)
 	}
 
+	now := metav1.Now()
+	next := buildRolloutStatus(syn, compList.Items, now)
+	failed, abort, requeue := evaluateRolloutPolicy(syn, compList.Items, now.Time)
+	if abort && !rolloutAborted(next) {
+		meta.SetStatusCondition(&next.Conditions, metav1.Condition{
+			Type:               apiv1.FailedRolloutCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: syn.Generation,
+			Reason:             "FailureThresholdExceeded",
+			Message:            fmt.Sprintf("%d resynthesized compositions failed synthesis or readiness", len(failed)),
+		})
+		logger.V(0).Info("aborted synthesizer rollout", "failedCompositions", len(failed))
+	}
+	soaked, soakWait, failedCanaries := canariesSoaked(syn, compList.Items, now.Time, c.canarySoak)
+	if len(failedCanaries) > 0 {
+		meta.SetStatusCondition(&next.Conditions, metav1.Condition{
+			Type:               apiv1.CanaryFailedCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: syn.Generation,
+			Reason:             "CanarySynthesisFailed",
+			Message:            canaryFailedMessage(failedCanaries),
+		})
+	} else {
+		meta.RemoveStatusCondition(&next.Conditions, apiv1.CanaryFailedCondition)
+	}
+	if !equality.Semantic.DeepEqual(next, &syn.Status) {
+		copy := syn.DeepCopy()
+		copy.Status = *next
+		if err := c.client.Status().Patch(ctx, copy, client.MergeFrom(syn)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("updating synthesizer status: %w", err)
+		}
+		logger.V(1).Info("updated synthesizer rollout status", "rolloutFailures", next.RolloutFailures, "rolloutFinished", next.RolloutFinished != nil)
+	}
+
+	if rolloutAborted(next) {
+		// Stop any staged resyntheses that haven't started yet
+		for i := range compList.Items {
+			comp := &compList.Items[i]
+			if comp.Status.PendingResynthesis == nil || comp.Status.CurrentSynthesis == nil || isInSync(comp, syn) {
+				continue
+			}
+			comp.Status.PendingResynthesis = nil
+			comp.Status.PendingResynthesisReason = ""
+			err = c.client.Status().Update(ctx, comp)
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("canceling staged resynthesis: %w", err)
+			}
+			logger.V(1).Info("canceled staged resynthesis because the rollout was aborted", "compositionName", comp.Name, "compositionNamespace", comp.Namespace)
+			return ctrl.Result{Requeue: true}, nil
+		}
+
+		if syn.Spec.Rollout == nil || !syn.Spec.Rollout.Rollback {
+			return ctrl.Result{}, nil
+		}
+		for _, comp := range failed {
+			if err := c.rollback(ctx, comp); err != nil {
+				return ctrl.Result{}, fmt.Errorf("rolling back composition synthesis: %w", err)
+			}
+			logger.V(0).Info("rolled back composition to its previous synthesis because the rollout was aborted", "compositionName", comp.Name, "compositionNamespace", comp.Namespace)
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Nothing is rolled out until the impact analysis of the current generation has completed
+	if syn.ImpactAnalysisPending() {
+		logger.V(1).Info("waiting for impact analysis before starting rollout")
+		return ctrl.Result{}, nil
+	}
+
 	// randomize list to avoid always rolling out changes in the same order
 	rand.Shuffle(len(compList.Items), func(i, j int) { compList.Items[i], compList.Items[j] = compList.Items[j], compList.Items[i] })
 
+	// Canaries receive synthesizer changes immediately (bypassing the cooldown period)
+	for i := range compList.Items {
+		comp := &compList.Items[i]
+		if !isCanary(comp) || !rolloutEligible(comp, syn) {
+			continue
+		}
+
+		comp.Status.PendingResynthesis = ptr.To(metav1.Now())
+		comp.Status.PendingResynthesisReason = apiv1.SynthesisReasonSynthesizerRollout
+		err = c.client.Status().Update(ctx, comp)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("staging canary composition resynthesis: %w", err)
+		}
+
+		logger.V(0).Info("staged resynthesis of canary composition because its synthesizer changed", "compositionName", comp.Name, "compositionNamespace", comp.Namespace)
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// The rest of the fleet waits until the canaries have been ready for the soak period
+	if !soaked {
+		logger.V(1).Info("waiting for canary compositions to soak before continuing rollout", "failedCanaries", len(failedCanaries))
+		return ctrl.Result{RequeueAfter: soakWait}, nil
+	}
+
 	for _, comp := range compList.Items {
 		comp := comp
 		logger := logger.WithValues("compositionName", comp.Name,
@@ -64,25 +205,12 @@ func (c *synthController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			"compositionGeneration", comp.Generation,
 			"synthesisID", comp.Status.GetCurrentSynthesisUUID())
 
-		// Compositions aren't eligible to receive an updated synthesizer when:
-		// - They haven't ever been synthesized (they'll use the latest inputs anyway)
-		// - They are already on the latest version of the synthesizer
-		// - They are currently being synthesized or deleted
-		// - They are already pending resynthesis
-		// - They are already in sync with the latest synth
-		// - Their input revisions are not in lockstep
-		// - They're ignoring side effects
-		if comp.Status.CurrentSynthesis == nil ||
-			comp.Status.CurrentSynthesis.Synthesized == nil ||
-			comp.DeletionTimestamp != nil ||
-			comp.Status.PendingResynthesis != nil ||
-			isInSync(&comp, syn) ||
-			comp.InputsOutOfLockstep(syn) ||
-			comp.ShouldIgnoreSideEffects() {
+		if !rolloutEligible(&comp, syn) {
 			continue
 		}
 
 		comp.Status.PendingResynthesis = ptr.To(metav1.Now())
+		comp.Status.PendingResynthesisReason = apiv1.SynthesisReasonSynthesizerRollout
 		err = c.client.Status().Update(ctx, &comp)
 		if err != nil {
 			return ctrl.Result{}, fmt.Errorf("swapping compisition state: %w", err)
@@ -92,7 +220,74 @@ func (c *synthController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeue}, nil
+}
+
+// buildRolloutStatus summarizes the progress of the synthesizer's current generation across its compositions.
+func buildRolloutStatus(syn *apiv1.Synthesizer, comps []apiv1.Composition, now metav1.Time) *apiv1.SynthesizerStatus {
+	status := syn.Status.DeepCopy()
+	if status.ObservedGeneration != syn.Generation {
+		status.ObservedGeneration = syn.Generation
+		status.RolloutStarted = &now
+		status.RolloutFinished = nil
+		meta.RemoveStatusCondition(&status.Conditions, apiv1.FailedRolloutCondition)
+	}
+
+	counts := map[int64]int{}
+	var pending, failures int
+	for _, comp := range comps {
+		current := comp.Status.CurrentSynthesis
+		if current == nil || comp.DeletionTimestamp != nil {
+			continue
+		}
+		counts[current.ObservedSynthesizerGeneration]++
+
+		switch {
+		case isInSync(&comp, syn) && current.Failed():
+			failures++
+		case isInSync(&comp, syn) && current.Synthesized == nil:
+			pending++ // still being synthesized
+		case !isInSync(&comp, syn) && !comp.ShouldIgnoreSideEffects():
+			pending++
+		}
+	}
+
+	status.CompositionsByGeneration = nil
+	for gen, n := range counts {
+		status.CompositionsByGeneration = append(status.CompositionsByGeneration, apiv1.GenerationCount{Generation: gen, Compositions: n})
+	}
+	sort.Slice(status.CompositionsByGeneration, func(i, j int) bool {
+		return status.CompositionsByGeneration[i].Generation < status.CompositionsByGeneration[j].Generation
+	})
+
+	status.RolloutFailures = failures
+	if pending == 0 && status.RolloutFinished == nil {
+		status.RolloutFinished = &now
+	}
+	return status
+}
+
+// rolloutEligible returns true when the composition should receive the synthesizer's current generation.
+//
+// Compositions aren't eligible to receive an updated synthesizer when:
+// - They haven't ever been synthesized (they'll use the latest inputs anyway)
+// - They are already on the latest version of the synthesizer
+// - They are currently being synthesized or deleted
+// - They are already pending resynthesis
+// - They are already in sync with the latest synth
+// - Their input revisions are not in lockstep
+// - They're pinned to another synthesizer generation
+// - They're ignoring side effects
+func rolloutEligible(comp *apiv1.Composition, syn *apiv1.Synthesizer) bool {
+	return comp.Status.CurrentSynthesis != nil &&
+		comp.Status.CurrentSynthesis.Synthesized != nil &&
+		comp.DeletionTimestamp == nil &&
+		comp.Status.PendingResynthesis == nil &&
+		!isInSync(comp, syn) &&
+		!comp.InputsOutOfLockstep(syn) &&
+		!comp.PinMismatched(syn) &&
+		!comp.ShouldIgnoreSideEffects() &&
+		!comp.SequencingBlocked()
 }
 
 func isInSync(comp *apiv1.Composition, syn *apiv1.Synthesizer) bool {
@@ -130,10 +325,15 @@ func newCompositionHandler() handler.EventHandler {
 				oldComp.Status.CurrentSynthesis != nil && newComp.Status.CurrentSynthesis != nil &&
 				oldComp.Status.CurrentSynthesis.UUID == newComp.Status.CurrentSynthesis.UUID &&
 				equality.Semantic.DeepEqual(oldComp.Status.CurrentSynthesis.Synthesized, newComp.Status.CurrentSynthesis.Synthesized) &&
+				equality.Semantic.DeepEqual(oldComp.Status.CurrentSynthesis.Ready, newComp.Status.CurrentSynthesis.Ready) &&
 				oldComp.ShouldIgnoreSideEffects() == newComp.ShouldIgnoreSideEffects() {
 				return
 			}
 
+			// The previous synthesizer's rollout status no longer includes this composition
+			if oldComp.Spec.Synthesizer.Name != newComp.Spec.Synthesizer.Name {
+				rli.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: oldComp.Spec.Synthesizer.Name}})
+			}
 			rli.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: newComp.Spec.Synthesizer.Name}})
 		},
 	}
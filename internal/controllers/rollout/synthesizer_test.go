@@ -0,0 +1,52 @@
+package rollout
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildRolloutStatus(t *testing.T) {
+	now := metav1.Now()
+	syn := &apiv1.Synthesizer{}
+	syn.Generation = 2
+
+	comps := []apiv1.Composition{
+		{Status: apiv1.CompositionStatus{CurrentSynthesis: &apiv1.Synthesis{ObservedSynthesizerGeneration: 1, Synthesized: &now}}},
+		{Status: apiv1.CompositionStatus{CurrentSynthesis: &apiv1.Synthesis{ObservedSynthesizerGeneration: 2, Synthesized: &now}}},
+		{Status: apiv1.CompositionStatus{CurrentSynthesis: &apiv1.Synthesis{ObservedSynthesizerGeneration: 2, Results: []apiv1.Result{{Severity: "error"}}}}},
+		{Status: apiv1.CompositionStatus{}}, // never synthesized
+	}
+
+	// Rollout starts when a new generation is observed
+	status := buildRolloutStatus(syn, comps, now)
+	assert.Equal(t, int64(2), status.ObservedGeneration)
+	assert.Equal(t, &now, status.RolloutStarted)
+	assert.Nil(t, status.RolloutFinished)
+	assert.Equal(t, 1, status.RolloutFailures)
+	assert.Equal(t, []apiv1.GenerationCount{{Generation: 1, Compositions: 1}, {Generation: 2, Compositions: 2}}, status.CompositionsByGeneration)
+
+	// Rollout finishes when the last composition is resynthesized
+	syn.Status = *status
+	comps[0].Status.CurrentSynthesis.ObservedSynthesizerGeneration = 2
+	later := metav1.NewTime(now.Add(time.Minute))
+	status = buildRolloutStatus(syn, comps, later)
+	assert.Equal(t, &now, status.RolloutStarted)
+	assert.Equal(t, &later, status.RolloutFinished)
+	assert.Equal(t, []apiv1.GenerationCount{{Generation: 2, Compositions: 3}}, status.CompositionsByGeneration)
+
+	// Compositions ignoring side effects don't block the rollout
+	syn.Status = apiv1.SynthesizerStatus{}
+	syn.Generation = 3
+	for i := range comps {
+		comps[i].Annotations = map[string]string{"eno.azure.io/ignore-side-effects": "true"}
+	}
+	comps[2].Status.CurrentSynthesis.Results = nil
+	comps[2].Status.CurrentSynthesis.Synthesized = &now
+	status = buildRolloutStatus(syn, comps, later)
+	assert.Equal(t, &later, status.RolloutFinished)
+	assert.Equal(t, 0, status.RolloutFailures)
+}
@@ -0,0 +1,49 @@
+package synthesis
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseRegistryMirrors parses a comma-separated list of registry=mirror pairs
+// e.g. "docker.io=mirror.example.com/dockerhub,mcr.microsoft.com=mirror.example.com/mcr".
+func ParseRegistryMirrors(str string) (map[string]string, error) {
+	mirrors := map[string]string{}
+	for _, pair := range strings.Split(str, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		registry, mirror, ok := strings.Cut(pair, "=")
+		if !ok || registry == "" || mirror == "" {
+			return nil, fmt.Errorf("invalid registry mirror %q: expected registry=mirror", pair)
+		}
+		mirrors[registry] = strings.TrimSuffix(mirror, "/")
+	}
+	return mirrors, nil
+}
+
+// mirrorImage replaces the registry of the given image reference with its mirror, if one exists.
+// Images without a registry are assumed to be from docker.io, following the usual container runtime convention.
+func mirrorImage(mirrors map[string]string, image string) string {
+	if len(mirrors) == 0 {
+		return image
+	}
+	registry, repo := splitRegistry(image)
+	mirror, ok := mirrors[registry]
+	if !ok {
+		return image
+	}
+	return mirror + "/" + repo
+}
+
+func splitRegistry(image string) (registry, repo string) {
+	first, rest, ok := strings.Cut(image, "/")
+	if ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first, rest
+	}
+	if !ok {
+		return "docker.io", "library/" + image
+	}
+	return "docker.io", image
+}
@@ -0,0 +1,38 @@
+package synthesis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorImage(t *testing.T) {
+	mirrors, err := ParseRegistryMirrors("docker.io=mirror.local/dockerhub/, mcr.microsoft.com=mirror.local/mcr,localhost:5000=mirror.local")
+	require.NoError(t, err)
+
+	tests := []struct {
+		Image, Expected string
+	}{
+		{Image: "nginx:1.27", Expected: "mirror.local/dockerhub/library/nginx:1.27"},
+		{Image: "foo/bar@sha256:abcd", Expected: "mirror.local/dockerhub/foo/bar@sha256:abcd"},
+		{Image: "docker.io/foo/bar", Expected: "mirror.local/dockerhub/foo/bar"},
+		{Image: "mcr.microsoft.com/oss/eno:v1", Expected: "mirror.local/mcr/oss/eno:v1"},
+		{Image: "localhost:5000/eno", Expected: "mirror.local/eno"},
+		{Image: "ghcr.io/azure/eno", Expected: "ghcr.io/azure/eno"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Image, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, mirrorImage(mirrors, tc.Image))
+		})
+	}
+
+	assert.Equal(t, "nginx", mirrorImage(nil, "nginx"))
+}
+
+func TestParseRegistryMirrorsErrors(t *testing.T) {
+	for _, str := range []string{"docker.io", "=mirror", "docker.io="} {
+		_, err := ParseRegistryMirrors(str)
+		assert.Error(t, err, str)
+	}
+}
@@ -0,0 +1,294 @@
+package synthesis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/execution"
+	"github.com/Azure/eno/internal/manager"
+)
+
+// Dry run pods aren't labeled with their composition's name and namespace (or synthesis UUID)
+// so they're ignored by the pod lifecycle controller.
+const (
+	impactSynthesizerLabelKey          = "eno.azure.io/impact-analysis-synthesizer"
+	impactGenerationLabelKey           = "eno.azure.io/impact-analysis-generation"
+	impactCompositionNameLabelKey      = "eno.azure.io/impact-analysis-composition-name"
+	impactCompositionNamespaceLabelKey = "eno.azure.io/impact-analysis-composition-namespace"
+)
+
+type impactController struct {
+	config        *Config
+	client        client.Client
+	noCacheReader client.Reader
+}
+
+// NewImpactAnalysisController dry-runs new synthesizer generations against a sample of their compositions
+// and reports the results in the synthesizer's status. Rollouts wait for the analysis to complete.
+func NewImpactAnalysisController(mgr ctrl.Manager, cfg *Config) error {
+	c := &impactController{
+		config:        cfg,
+		client:        mgr.GetClient(),
+		noCacheReader: mgr.GetAPIReader(),
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("impactAnalysisController").
+		For(&apiv1.Synthesizer{}).
+		Owns(&corev1.Pod{}).
+		WithLogConstructor(manager.NewLogConstructor(mgr, "impactAnalysisController")).
+		Complete(manager.WithController("impactAnalysisController", c))
+}
+
+func (c *impactController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	syn := &apiv1.Synthesizer{}
+	err := c.client.Get(ctx, req.NamespacedName, syn)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("getting synthesizer: %w", err))
+	}
+	logger = logger.WithValues("synthesizerName", syn.Name, "synthesizerGeneration", syn.Generation)
+
+	// Pods are listed without trusting informers to avoid creating more than one per composition
+	pods := &corev1.PodList{}
+	err = c.noCacheReader.List(ctx, pods, client.InNamespace(c.config.PodNamespace), client.MatchingLabels{impactSynthesizerLabelKey: syn.Name})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("listing dry run pods: %w", err)
+	}
+
+	current := map[string]*corev1.Pod{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if !impactPodNeeded(syn, pod) {
+			if err := c.client.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, fmt.Errorf("deleting dry run pod: %w", err)
+			}
+			logger.V(1).Info("deleted dry run pod", "podName", pod.Name)
+			continue
+		}
+		current[impactKey(pod.Labels[impactCompositionNamespaceLabelKey], pod.Labels[impactCompositionNameLabelKey])] = pod
+	}
+
+	if !syn.ImpactAnalysisPending() {
+		return ctrl.Result{}, nil
+	}
+	now := metav1.Now()
+
+	if status := syn.Status.ImpactAnalysis; status == nil || status.ObservedGeneration != syn.Generation {
+		comps := &apiv1.CompositionList{}
+		err = c.client.List(ctx, comps, client.MatchingFields{
+			manager.IdxCompositionsBySynthesizer: syn.Name,
+		})
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("listing compositions: %w", err)
+		}
+
+		next := &apiv1.ImpactAnalysisStatus{
+			ObservedGeneration: syn.Generation,
+			Started:            &now,
+			Compositions:       sampleCompositions(syn, comps.Items, syn.Spec.ImpactAnalysis.SampleSize),
+		}
+		summarizeImpact(next, now)
+		if err := c.patchStatus(ctx, syn, next); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.V(0).Info("started impact analysis", "compositions", len(next.Compositions))
+		return ctrl.Result{}, nil
+	}
+
+	next := syn.Status.ImpactAnalysis.DeepCopy()
+	var requeue time.Duration
+	for i := range next.Compositions {
+		impact := &next.Compositions[i]
+		if impact.Analyzed != nil {
+			continue
+		}
+
+		pod := current[impactKey(impact.Namespace, impact.Name)]
+		if pod != nil {
+			result, wait := impactPodResult(syn, pod, now.Time)
+			if result == nil {
+				if wait > 0 && (requeue == 0 || wait < requeue) {
+					requeue = wait
+				}
+				continue
+			}
+			impact.Added, impact.Removed, impact.Modified, impact.Error = result.Added, result.Removed, result.Modified, result.Error
+			impact.Analyzed = &now
+			continue
+		}
+
+		comp := &apiv1.Composition{}
+		err = c.client.Get(ctx, types.NamespacedName{Name: impact.Name, Namespace: impact.Namespace}, comp)
+		if errors.IsNotFound(err) || (err == nil && comp.DeletionTimestamp != nil) {
+			impact.Error = "composition was deleted"
+			impact.Analyzed = &now
+			continue
+		}
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("getting composition: %w", err)
+		}
+		if comp.Status.CurrentSynthesis == nil || comp.Status.CurrentSynthesis.Synthesized == nil {
+			impact.Error = "composition is being synthesized"
+			impact.Analyzed = &now
+			continue
+		}
+
+		pod = newImpactPod(c.config, comp, syn)
+		if err := controllerutil.SetControllerReference(syn, pod, c.client.Scheme()); err != nil {
+			return ctrl.Result{}, fmt.Errorf("setting pod owner: %w", err)
+		}
+		if err := c.client.Create(ctx, pod); err != nil {
+			return ctrl.Result{}, fmt.Errorf("creating dry run pod: %w", err)
+		}
+		logger.V(0).Info("created dry run pod", "podName", pod.Name, "compositionName", comp.Name, "compositionNamespace", comp.Namespace)
+	}
+
+	summarizeImpact(next, now)
+	if !equality.Semantic.DeepEqual(next, syn.Status.ImpactAnalysis) {
+		if err := c.patchStatus(ctx, syn, next); err != nil {
+			return ctrl.Result{}, err
+		}
+		if next.Completed != nil {
+			logger.V(0).Info("completed impact analysis", "added", next.Added, "removed", next.Removed, "modified", next.Modified, "failed", next.Failed)
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeue}, nil
+}
+
+func (c *impactController) patchStatus(ctx context.Context, syn *apiv1.Synthesizer, status *apiv1.ImpactAnalysisStatus) error {
+	copy := syn.DeepCopy()
+	copy.Status.ImpactAnalysis = status
+	if err := c.client.Status().Patch(ctx, copy, client.MergeFrom(syn)); err != nil {
+		return fmt.Errorf("updating impact analysis status: %w", err)
+	}
+	return nil
+}
+
+// sampleCompositions randomly selects up to n compositions that would be resynthesized by the synthesizer's rollout.
+func sampleCompositions(syn *apiv1.Synthesizer, comps []apiv1.Composition, n int) []apiv1.CompositionImpact {
+	var eligible []apiv1.CompositionImpact
+	for _, comp := range comps {
+		current := comp.Status.CurrentSynthesis
+		if current == nil || current.Synthesized == nil || current.Failed() ||
+			current.ObservedSynthesizerGeneration >= syn.Generation ||
+			comp.DeletionTimestamp != nil ||
+			comp.PinMismatched(syn) ||
+			comp.ShouldIgnoreSideEffects() {
+			continue
+		}
+		eligible = append(eligible, apiv1.CompositionImpact{Name: comp.Name, Namespace: comp.Namespace})
+	}
+
+	if n <= 0 {
+		n = 5 // the default sample size, in case the policy wasn't defaulted by apiserver
+	}
+	rand.Shuffle(len(eligible), func(i, j int) { eligible[i], eligible[j] = eligible[j], eligible[i] })
+	if len(eligible) > n {
+		eligible = eligible[:n]
+	}
+	return eligible
+}
+
+// summarizeImpact totals the results of the analyzed compositions, and completes the analysis once they've all been analyzed.
+func summarizeImpact(status *apiv1.ImpactAnalysisStatus, now metav1.Time) {
+	status.Added, status.Removed, status.Modified, status.Failed = 0, 0, 0, 0
+	done := true
+	for _, impact := range status.Compositions {
+		if impact.Analyzed == nil {
+			done = false
+			continue
+		}
+		status.Added += impact.Added
+		status.Removed += impact.Removed
+		status.Modified += impact.Modified
+		if impact.Error != "" {
+			status.Failed++
+		}
+	}
+	if done && status.Completed == nil {
+		status.Completed = &now
+	}
+}
+
+// impactPodResult returns the result of a dry run pod, or how long to wait before checking again if it's still running.
+func impactPodResult(syn *apiv1.Synthesizer, pod *corev1.Pod, now time.Time) (*execution.DryRunResult, time.Duration) {
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != "executor" || status.State.Terminated == nil {
+				continue
+			}
+			result := &execution.DryRunResult{}
+			if err := json.Unmarshal([]byte(status.State.Terminated.Message), result); err != nil {
+				return &execution.DryRunResult{Error: "dry run result couldn't be decoded"}, 0
+			}
+			return result, 0
+		}
+		return &execution.DryRunResult{Error: "dry run result is missing"}, 0
+
+	case corev1.PodFailed:
+		return &execution.DryRunResult{Error: "dry run pod failed"}, 0
+	}
+
+	if syn.Spec.PodTimeout == nil {
+		return nil, 0
+	}
+	remaining := syn.Spec.PodTimeout.Duration - now.Sub(pod.CreationTimestamp.Time)
+	if remaining <= 0 {
+		return &execution.DryRunResult{Error: "dry run timed out"}, 0
+	}
+	return nil, remaining
+}
+
+// impactPodNeeded returns false for dry run pods that have finished or are no longer relevant.
+func impactPodNeeded(syn *apiv1.Synthesizer, pod *corev1.Pod) bool {
+	status := syn.Status.ImpactAnalysis
+	if !syn.ImpactAnalysisPending() || status == nil || status.ObservedGeneration != syn.Generation ||
+		pod.Labels[impactGenerationLabelKey] != strconv.FormatInt(syn.Generation, 10) {
+		return false
+	}
+	for _, impact := range status.Compositions {
+		if impact.Name == pod.Labels[impactCompositionNameLabelKey] && impact.Namespace == pod.Labels[impactCompositionNamespaceLabelKey] {
+			return impact.Analyzed == nil
+		}
+	}
+	return false
+}
+
+// newImpactPod returns a pod that dry-runs the synthesizer against the composition.
+func newImpactPod(cfg *Config, comp *apiv1.Composition, syn *apiv1.Synthesizer) *corev1.Pod {
+	pod := newPod(cfg, comp, syn)
+	pod.GenerateName = "impact-"
+	delete(pod.Labels, manager.CompositionNameLabelKey)
+	delete(pod.Labels, manager.CompositionNamespaceLabelKey)
+	delete(pod.Labels, "eno.azure.io/synthesis-uuid")
+	pod.Labels[impactSynthesizerLabelKey] = syn.Name
+	pod.Labels[impactGenerationLabelKey] = strconv.FormatInt(syn.Generation, 10)
+	pod.Labels[impactCompositionNameLabelKey] = comp.Name
+	pod.Labels[impactCompositionNamespaceLabelKey] = comp.Namespace
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, corev1.EnvVar{Name: "DRY_RUN", Value: "true"})
+	return pod
+}
+
+func impactKey(namespace, name string) string {
+	return namespace + "/" + name
+}
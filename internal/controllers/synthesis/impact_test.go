@@ -0,0 +1,163 @@
+package synthesis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/execution"
+	"github.com/Azure/eno/internal/manager"
+)
+
+func TestSampleCompositions(t *testing.T) {
+	now := metav1.Now()
+	syn := &apiv1.Synthesizer{}
+	syn.Generation = 2
+
+	newComp := func(name string, synthesis *apiv1.Synthesis) apiv1.Composition {
+		comp := apiv1.Composition{}
+		comp.Name = name
+		comp.Namespace = "default"
+		comp.Status.CurrentSynthesis = synthesis
+		return comp
+	}
+	deleting := newComp("deleting", &apiv1.Synthesis{ObservedSynthesizerGeneration: 1, Synthesized: &now})
+	deleting.DeletionTimestamp = &now
+	ignoring := newComp("ignoring", &apiv1.Synthesis{ObservedSynthesizerGeneration: 1, Synthesized: &now})
+	ignoring.Annotations = map[string]string{"eno.azure.io/ignore-side-effects": "true"}
+
+	comps := []apiv1.Composition{
+		newComp("eligible-1", &apiv1.Synthesis{ObservedSynthesizerGeneration: 1, Synthesized: &now}),
+		newComp("eligible-2", &apiv1.Synthesis{ObservedSynthesizerGeneration: 1, Synthesized: &now}),
+		newComp("current", &apiv1.Synthesis{ObservedSynthesizerGeneration: 2, Synthesized: &now}),
+		newComp("synthesizing", &apiv1.Synthesis{ObservedSynthesizerGeneration: 1}),
+		newComp("failed", &apiv1.Synthesis{ObservedSynthesizerGeneration: 1, Synthesized: &now, Results: []apiv1.Result{{Severity: "error"}}}),
+		newComp("never-synthesized", nil),
+		deleting,
+		ignoring,
+	}
+
+	sample := sampleCompositions(syn, comps, 10)
+	assert.ElementsMatch(t, []apiv1.CompositionImpact{{Name: "eligible-1", Namespace: "default"}, {Name: "eligible-2", Namespace: "default"}}, sample)
+
+	sample = sampleCompositions(syn, comps, 1)
+	assert.Len(t, sample, 1)
+}
+
+func TestSummarizeImpact(t *testing.T) {
+	now := metav1.Now()
+	status := &apiv1.ImpactAnalysisStatus{Compositions: []apiv1.CompositionImpact{
+		{Name: "foo", Analyzed: &now, Added: 1, Removed: 2, Modified: 3},
+		{Name: "bar", Analyzed: &now, Error: "synthesizer failed"},
+		{Name: "baz"},
+	}}
+
+	summarizeImpact(status, now)
+	assert.Equal(t, 1, status.Added)
+	assert.Equal(t, 2, status.Removed)
+	assert.Equal(t, 3, status.Modified)
+	assert.Equal(t, 1, status.Failed)
+	assert.Nil(t, status.Completed)
+
+	status.Compositions[2].Analyzed = &now
+	status.Compositions[2].Modified = 1
+	summarizeImpact(status, now)
+	assert.Equal(t, 4, status.Modified)
+	assert.Equal(t, &now, status.Completed)
+
+	// Nothing to analyze
+	status = &apiv1.ImpactAnalysisStatus{}
+	summarizeImpact(status, now)
+	assert.Equal(t, &now, status.Completed)
+}
+
+func TestImpactPodResult(t *testing.T) {
+	now := time.Now()
+	syn := &apiv1.Synthesizer{}
+	syn.Spec.PodTimeout = &metav1.Duration{Duration: time.Minute}
+
+	pod := &corev1.Pod{}
+	pod.CreationTimestamp = metav1.NewTime(now.Add(-time.Second * 10))
+
+	// Running
+	result, wait := impactPodResult(syn, pod, now)
+	assert.Nil(t, result)
+	assert.Equal(t, time.Second*50, wait)
+
+	// Timed out
+	result, _ = impactPodResult(syn, pod, now.Add(time.Minute))
+	assert.Equal(t, &execution.DryRunResult{Error: "dry run timed out"}, result)
+
+	// Succeeded
+	pod.Status.Phase = corev1.PodSucceeded
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "executor",
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: `{"added":1,"removed":2,"modified":3}`}},
+	}}
+	result, _ = impactPodResult(syn, pod, now)
+	assert.Equal(t, &execution.DryRunResult{Added: 1, Removed: 2, Modified: 3}, result)
+
+	// Invalid result
+	pod.Status.ContainerStatuses[0].State.Terminated.Message = "not json"
+	result, _ = impactPodResult(syn, pod, now)
+	assert.Equal(t, &execution.DryRunResult{Error: "dry run result couldn't be decoded"}, result)
+}
+
+func TestImpactPodNeeded(t *testing.T) {
+	now := metav1.Now()
+	syn := &apiv1.Synthesizer{}
+	syn.Generation = 2
+	syn.Spec.ImpactAnalysis = &apiv1.ImpactAnalysisPolicy{SampleSize: 2}
+	syn.Status.ImpactAnalysis = &apiv1.ImpactAnalysisStatus{
+		ObservedGeneration: 2,
+		Compositions: []apiv1.CompositionImpact{
+			{Name: "pending", Namespace: "default"},
+			{Name: "analyzed", Namespace: "default", Analyzed: &now},
+		},
+	}
+
+	newImpactTestPod := func(name, gen string) *corev1.Pod {
+		pod := &corev1.Pod{}
+		pod.Labels = map[string]string{
+			impactGenerationLabelKey:           gen,
+			impactCompositionNameLabelKey:      name,
+			impactCompositionNamespaceLabelKey: "default",
+		}
+		return pod
+	}
+
+	assert.True(t, impactPodNeeded(syn, newImpactTestPod("pending", "2")))
+	assert.False(t, impactPodNeeded(syn, newImpactTestPod("pending", "1")))
+	assert.False(t, impactPodNeeded(syn, newImpactTestPod("analyzed", "2")))
+	assert.False(t, impactPodNeeded(syn, newImpactTestPod("unknown", "2")))
+
+	syn.Spec.ImpactAnalysis = nil
+	assert.False(t, impactPodNeeded(syn, newImpactTestPod("pending", "2")))
+}
+
+func TestNewImpactPod(t *testing.T) {
+	comp := &apiv1.Composition{}
+	comp.Name = "test-composition"
+	comp.Namespace = "test-composition-ns"
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid"}
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = "test-synth"
+	syn.Generation = 3
+
+	pod := newImpactPod(minimalTestConfig, comp, syn)
+	assert.Equal(t, "impact-", pod.GenerateName)
+	assert.Equal(t, map[string]string{
+		manager.ManagerLabelKey:            manager.ManagerLabelValue,
+		impactSynthesizerLabelKey:          "test-synth",
+		impactGenerationLabelKey:           "3",
+		impactCompositionNameLabelKey:      "test-composition",
+		impactCompositionNamespaceLabelKey: "test-composition-ns",
+	}, pod.Labels)
+	assert.False(t, manager.PodReferencesComposition(pod))
+	assert.Contains(t, pod.Spec.Containers[0].Env, corev1.EnvVar{Name: "DRY_RUN", Value: "true"})
+}
@@ -0,0 +1,280 @@
+package synthesis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/resource"
+)
+
+// maxImportSliceJsonBytes matches the executor's limit for synthesized resource slices.
+const maxImportSliceJsonBytes = 1024 * 512
+
+// importResources writes the current state of the resources selected by the composition's spec.import
+// to resource slices, and returns a synthesis that references them to be used as the previous synthesis.
+// Only resources in the composition's namespace are imported, since the controller reads them with its own credentials.
+//
+// The synthesis UUID and slice names are derived from the composition's UID so that retrying after a failed
+// status write replaces the slices written by the earlier attempt instead of orphaning them.
+func (c *podLifecycleController) importResources(ctx context.Context, comp *apiv1.Composition) (*apiv1.Synthesis, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	spec := comp.Spec.Import
+
+	if spec.Namespace != "" && spec.Namespace != comp.Namespace {
+		return nil, reconcile.TerminalError(fmt.Errorf("resources can only be imported from the composition's namespace"))
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("parsing selector: %w", err)
+	}
+
+	if spec.ImportsSecrets() && !c.config.AllowSecretImports {
+		return nil, reconcile.TerminalError(fmt.Errorf("importing secrets is not allowed"))
+	}
+
+	var objs []*unstructured.Unstructured
+	for _, ref := range spec.Resources {
+
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{Group: ref.Group, Version: ref.Version, Kind: ref.Kind + "List"})
+		err := c.noCacheReader.List(ctx, list, client.MatchingLabelsSelector{Selector: selector}, client.InNamespace(comp.Namespace))
+		if err != nil {
+			return nil, fmt.Errorf("listing %s resources: %w", ref.Kind, err)
+		}
+
+		for i := range list.Items {
+			if list.Items[i].GetNamespace() != comp.Namespace {
+				continue // cluster-scoped
+			}
+			obj := importedState(&list.Items[i], spec.FieldManager)
+			if obj == nil {
+				continue // no fields are owned by the field manager
+			}
+			objs = append(objs, obj)
+		}
+	}
+
+	slices, err := resource.Slice(comp, nil, objs, maxImportSliceJsonBytes)
+	if err != nil {
+		return nil, fmt.Errorf("slicing imported resources: %w", err)
+	}
+
+	now := metav1.Now()
+	synthesis := &apiv1.Synthesis{
+		UUID:                          uuid.NewSHA1(uuid.NameSpaceOID, []byte("eno.azure.io/import/"+string(comp.UID))).String(),
+		ObservedCompositionGeneration: comp.Generation,
+		Initialized:                   &now,
+		Synthesized:                   &now,
+		Reason:                        apiv1.SynthesisReasonImport,
+	}
+	for _, slice := range slices {
+		slice.Spec.SynthesisUUID = synthesis.UUID
+	}
+	synthesis.ResourceSlices, err = WriteSlices(ctx, c.client, c.noCacheReader, comp, slices, comp.Name+"-import")
+	if err != nil {
+		return nil, err
+	}
+
+	logger.V(0).Info("imported existing resources", "resources", len(objs), "resourceSlices", len(slices))
+	return synthesis, nil
+}
+
+// importedState returns the parts of the given resource to be recorded as its previous state.
+// Metadata that is populated by the apiserver or other controllers is never included.
+// Returns nil if fieldManager is set and doesn't own any of the resource's fields.
+func importedState(obj *unstructured.Unstructured, fieldManager string) *unstructured.Unstructured {
+	out := &unstructured.Unstructured{Object: map[string]any{}}
+	if fieldManager == "" {
+		out.Object = obj.DeepCopy().Object
+		delete(out.Object, "status")
+		unstructured.RemoveNestedField(out.Object, "metadata")
+	} else {
+		fields := ownedFields(obj, fieldManager)
+		if fields == nil {
+			return nil
+		}
+		out.Object, _ = filterFields(obj.Object, fields).(map[string]any)
+		if out.Object == nil {
+			out.Object = map[string]any{}
+		}
+		delete(out.Object, "status")
+		unstructured.RemoveNestedField(out.Object, "metadata", "ownerReferences")
+		unstructured.RemoveNestedField(out.Object, "metadata", "finalizers")
+	}
+
+	// Only the fields that could have been set by a synthesizer are imported
+	out.SetAPIVersion(obj.GetAPIVersion())
+	out.SetKind(obj.GetKind())
+	out.SetName(obj.GetName())
+	out.SetNamespace(obj.GetNamespace())
+	if fieldManager == "" {
+		out.SetLabels(obj.GetLabels())
+		out.SetAnnotations(obj.GetAnnotations())
+	}
+	if anno := out.GetAnnotations(); anno != nil {
+		delete(anno, "kubectl.kubernetes.io/last-applied-configuration")
+		if len(anno) == 0 {
+			anno = nil
+		}
+		out.SetAnnotations(anno)
+	}
+	return out
+}
+
+// ownedFields merges the fields owned by the given field manager, or returns nil if it doesn't own any.
+func ownedFields(obj *unstructured.Unstructured, fieldManager string) map[string]any {
+	var fields map[string]any
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager != fieldManager || entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+		set := map[string]any{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &set); err != nil {
+			continue
+		}
+		fields = mergeFields(fields, set)
+	}
+	return fields
+}
+
+func mergeFields(a, b map[string]any) map[string]any {
+	if a == nil {
+		return b
+	}
+	for key, bv := range b {
+		am, _ := a[key].(map[string]any)
+		bm, _ := bv.(map[string]any)
+		merged := mergeFields(am, bm)
+		if merged == nil {
+			merged = map[string]any{}
+		}
+		a[key] = merged
+	}
+	return a
+}
+
+// filterFields returns the parts of value that are included in the given managed fields set (FieldsV1 format).
+// An empty set means the entire value is included.
+func filterFields(value any, fields map[string]any) any {
+	if len(fields) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		out := map[string]any{}
+		for key, child := range fields {
+			name, ok := strings.CutPrefix(key, "f:")
+			if !ok {
+				continue
+			}
+			if val, exists := v[name]; exists {
+				childFields, _ := child.(map[string]any)
+				out[name] = filterFields(val, childFields)
+			}
+		}
+		return out
+
+	case []any:
+		out := []any{}
+		for i, item := range v {
+			childFields, ok := listItemFields(fields, i, item)
+			if !ok {
+				continue
+			}
+			filtered := filterFields(item, childFields)
+
+			// Keep the merge keys of list items even when they aren't owned by the field manager
+			if key, _ := keyedItem(fields, item); key != nil {
+				if m, ok := filtered.(map[string]any); ok {
+					for k, kv := range key {
+						m[k] = kv
+					}
+				}
+			}
+			out = append(out, filtered)
+		}
+		return out
+
+	default:
+		return value
+	}
+}
+
+// listItemFields returns the fields of the given list item, if it's included in the set.
+func listItemFields(fields map[string]any, i int, item any) (map[string]any, bool) {
+	if child, ok := fields["i:"+strconv.Itoa(i)]; ok {
+		m, _ := child.(map[string]any)
+		return m, true
+	}
+	if _, child := keyedItem(fields, item); child != nil {
+		return child, true
+	}
+	js, err := json.Marshal(item)
+	if err != nil {
+		return nil, false
+	}
+	if child, ok := fields["v:"+string(js)]; ok {
+		m, _ := child.(map[string]any)
+		return m, true
+	}
+	return nil, false
+}
+
+// keyedItem finds the "k:" entry of the set that matches the given list item.
+func keyedItem(fields map[string]any, item any) (map[string]any, map[string]any) {
+	m, ok := item.(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	for key, child := range fields {
+		raw, ok := strings.CutPrefix(key, "k:")
+		if !ok {
+			continue
+		}
+		keys := map[string]any{}
+		if err := json.Unmarshal([]byte(raw), &keys); err != nil {
+			continue
+		}
+		matches := true
+		for k, kv := range keys {
+			if !reflect.DeepEqual(normalizeNumber(m[k]), normalizeNumber(kv)) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			childFields, _ := child.(map[string]any)
+			if childFields == nil {
+				childFields = map[string]any{}
+			}
+			return keys, childFields
+		}
+	}
+	return nil, nil
+}
+
+// normalizeNumber allows numbers decoded from managed fields (float64) to be compared with those of unstructured objects (int64).
+func normalizeNumber(v any) any {
+	switch n := v.(type) {
+	case int64:
+		return float64(n)
+	case int:
+		return float64(n)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,234 @@
+package synthesis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/controllers/flowcontrol"
+	"github.com/Azure/eno/internal/testutil"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+)
+
+func TestImportedState(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name":            "test",
+			"namespace":       "default",
+			"uid":             "test-uid",
+			"resourceVersion": "123",
+			"finalizers":      []any{"example.com/finalizer"},
+			"labels":          map[string]any{"app": "test", "other": "label"},
+			"annotations":     map[string]any{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+			"managedFields": []any{
+				map[string]any{
+					"manager":    "kubectl-client-side-apply",
+					"operation":  "Update",
+					"apiVersion": "apps/v1",
+					"fieldsType": "FieldsV1",
+					"fieldsV1": map[string]any{
+						"f:metadata": map[string]any{"f:labels": map[string]any{".": map[string]any{}, "f:app": map[string]any{}}},
+						"f:spec": map[string]any{
+							"f:template": map[string]any{"f:spec": map[string]any{"f:containers": map[string]any{
+								`k:{"name":"app"}`: map[string]any{".": map[string]any{}, "f:image": map[string]any{}},
+							}}},
+						},
+					},
+				},
+				map[string]any{
+					"manager":    "hpa",
+					"operation":  "Update",
+					"apiVersion": "apps/v1",
+					"fieldsType": "FieldsV1",
+					"fieldsV1":   map[string]any{"f:spec": map[string]any{"f:replicas": map[string]any{}}},
+				},
+			},
+		},
+		"spec": map[string]any{
+			"replicas": int64(3),
+			"template": map[string]any{"spec": map[string]any{"containers": []any{
+				map[string]any{"name": "app", "image": "app:v1", "imagePullPolicy": "Always"},
+				map[string]any{"name": "sidecar", "image": "sidecar:v1"},
+			}}},
+		},
+		"status": map[string]any{"replicas": int64(3)},
+	}}
+
+	t.Run("all fields", func(t *testing.T) {
+		out := importedState(obj, "")
+		assert.Equal(t, map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]any{
+				"name":      "test",
+				"namespace": "default",
+				"labels":    map[string]any{"app": "test", "other": "label"},
+			},
+			"spec": obj.Object["spec"],
+		}, out.Object)
+	})
+
+	t.Run("field manager", func(t *testing.T) {
+		out := importedState(obj, "kubectl-client-side-apply")
+		assert.Equal(t, map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]any{
+				"name":      "test",
+				"namespace": "default",
+				"labels":    map[string]any{"app": "test"},
+			},
+			"spec": map[string]any{
+				"template": map[string]any{"spec": map[string]any{"containers": []any{
+					map[string]any{"name": "app", "image": "app:v1"},
+				}}},
+			},
+		}, out.Object)
+	})
+
+	t.Run("unknown field manager", func(t *testing.T) {
+		assert.Nil(t, importedState(obj, "helm"))
+	})
+}
+
+// TestImportIntegration proves that existing resources are recorded as the previous synthesis of new compositions.
+func TestImportIntegration(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	mgr := testutil.NewManager(t)
+	cli := mgr.GetClient()
+
+	require.NoError(t, flowcontrol.NewSynthesisConcurrencyLimiter(mgr.Manager, 10, 0, 0))
+	require.NoError(t, NewPodLifecycleController(mgr.Manager, minimalTestConfig))
+	testutil.WithFakeExecutor(t, mgr, func(ctx context.Context, s *apiv1.Synthesizer, input *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+		return &krmv1.ResourceList{}, nil
+	})
+	mgr.Start(t)
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = "existing"
+	cm.Namespace = "default"
+	cm.Labels = map[string]string{"app": "test"}
+	cm.Data = map[string]string{"foo": "bar"}
+	require.NoError(t, cli.Create(ctx, cm))
+
+	other := &corev1.ConfigMap{}
+	other.Name = "unrelated"
+	other.Namespace = "default"
+	require.NoError(t, cli.Create(ctx, other))
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = "test-syn"
+	syn.Spec.Image = "test-syn-image"
+	require.NoError(t, cli.Create(ctx, syn))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Synthesizer.Name = syn.Name
+	comp.Spec.Import = &apiv1.ImportSpec{
+		Resources: []apiv1.ResourceRef{{Version: "v1", Kind: "ConfigMap"}},
+		Namespace: "default",
+		Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+	}
+	require.NoError(t, cli.Create(ctx, comp))
+
+	testutil.Eventually(t, func() bool {
+		cli.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+		return comp.Status.PreviousSynthesis != nil && comp.Status.CurrentSynthesis != nil
+	})
+	assert.Equal(t, apiv1.SynthesisReasonImport, comp.Status.PreviousSynthesis.Reason)
+	assert.Equal(t, apiv1.SynthesisReasonInitial, comp.Status.CurrentSynthesis.Reason)
+	require.Len(t, comp.Status.PreviousSynthesis.ResourceSlices, 1)
+
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = comp.Status.PreviousSynthesis.ResourceSlices[0].Name
+	slice.Namespace = comp.Namespace
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(slice), slice))
+	assert.Equal(t, comp.Status.PreviousSynthesis.UUID, slice.Spec.SynthesisUUID)
+	require.Len(t, slice.Spec.Resources, 1)
+	assert.Contains(t, slice.Spec.Resources[0].Manifest, `"name":"existing"`)
+	assert.Contains(t, slice.Spec.Resources[0].Manifest, `"foo":"bar"`)
+}
+
+// TestImportResourcesRetry proves that importing again (e.g. after a failed status write) replaces the slices of the earlier attempt.
+func TestImportResourcesRetry(t *testing.T) {
+	ctx := testutil.NewContext(t)
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = "existing"
+	cm.Namespace = "default"
+	cm.Labels = map[string]string{"app": "test"}
+
+	foreign := &corev1.ConfigMap{}
+	foreign.Name = "foreign"
+	foreign.Namespace = "kube-system"
+	foreign.Labels = map[string]string{"app": "test"}
+
+	cli := testutil.NewClient(t, cm, foreign)
+	c := &podLifecycleController{config: &Config{}, client: cli, noCacheReader: cli}
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.UID = "test-uid"
+	comp.Spec.Import = &apiv1.ImportSpec{
+		Resources: []apiv1.ResourceRef{{Version: "v1", Kind: "ConfigMap"}},
+		Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+	}
+
+	first, err := c.importResources(ctx, comp)
+	require.NoError(t, err)
+	second, err := c.importResources(ctx, comp)
+	require.NoError(t, err)
+	assert.Equal(t, first.UUID, second.UUID)
+	assert.Equal(t, first.ResourceSlices, second.ResourceSlices)
+
+	slices := &apiv1.ResourceSliceList{}
+	require.NoError(t, cli.List(ctx, slices))
+	require.Len(t, slices.Items, 1)
+	require.Len(t, slices.Items[0].Spec.Resources, 1)
+	assert.Contains(t, slices.Items[0].Spec.Resources[0].Manifest, `"name":"existing"`)
+
+	// Other namespaces can't be imported from
+	comp.Spec.Import.Namespace = "kube-system"
+	_, err = c.importResources(ctx, comp)
+	assert.Error(t, err)
+}
+
+// TestImportResourcesSecrets proves that secrets are only imported when allowed by the operator.
+func TestImportResourcesSecrets(t *testing.T) {
+	ctx := testutil.NewContext(t)
+
+	secret := &corev1.Secret{}
+	secret.Name = "existing"
+	secret.Namespace = "default"
+	secret.Labels = map[string]string{"app": "test"}
+
+	cli := testutil.NewClient(t, secret)
+	c := &podLifecycleController{config: &Config{}, client: cli, noCacheReader: cli}
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Import = &apiv1.ImportSpec{
+		Resources: []apiv1.ResourceRef{{Version: "v1", Kind: "Secret"}},
+		Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+	}
+
+	_, err := c.importResources(ctx, comp)
+	assert.Error(t, err)
+
+	c.config.AllowSecretImports = true
+	syn, err := c.importResources(ctx, comp)
+	require.NoError(t, err)
+	assert.Len(t, syn.ResourceSlices, 1)
+}
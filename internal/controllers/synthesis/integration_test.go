@@ -34,7 +34,7 @@ func TestControllerHappyPath(t *testing.T) {
 	mgr := testutil.NewManager(t)
 	cli := mgr.GetClient()
 
-	require.NoError(t, flowcontrol.NewSynthesisConcurrencyLimiter(mgr.Manager, 10, 0))
+	require.NoError(t, flowcontrol.NewSynthesisConcurrencyLimiter(mgr.Manager, 10, 0, 0))
 	require.NoError(t, NewPodLifecycleController(mgr.Manager, minimalTestConfig))
 
 	calls := atomic.Int64{}
@@ -186,7 +186,7 @@ func TestControllerSwitchingSynthesizers(t *testing.T) {
 		return output, nil
 	})
 
-	require.NoError(t, flowcontrol.NewSynthesisConcurrencyLimiter(mgr.Manager, 10, 0))
+	require.NoError(t, flowcontrol.NewSynthesisConcurrencyLimiter(mgr.Manager, 10, 0, 0))
 	require.NoError(t, NewPodLifecycleController(mgr.Manager, minimalTestConfig))
 	mgr.Start(t)
 
@@ -234,3 +234,37 @@ func TestControllerSwitchingSynthesizers(t *testing.T) {
 		assert.NotEqual(t, comp.Status.CurrentSynthesis.ResourceSlices, initialSlices)
 	})
 }
+
+// TestControllerPassthrough proves that compositions with static manifests are synthesized without pods or synthesizers.
+func TestControllerPassthrough(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	mgr := testutil.NewManager(t)
+	cli := mgr.GetClient()
+
+	require.NoError(t, flowcontrol.NewSynthesisConcurrencyLimiter(mgr.Manager, 10, 0, 0))
+	require.NoError(t, NewPodLifecycleController(mgr.Manager, minimalTestConfig))
+	mgr.Start(t)
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Manifests = &apiv1.ManifestSource{Inline: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test-cm\n  namespace: default\n"}
+	require.NoError(t, cli.Create(ctx, comp))
+
+	testutil.Eventually(t, func() bool {
+		cli.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+		return comp.Status.CurrentSynthesis != nil && comp.Status.CurrentSynthesis.Synthesized != nil
+	})
+	require.Len(t, comp.Status.CurrentSynthesis.ResourceSlices, 1)
+
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = comp.Status.CurrentSynthesis.ResourceSlices[0].Name
+	slice.Namespace = comp.Namespace
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(slice), slice))
+	require.Len(t, slice.Spec.Resources, 1)
+	assert.Contains(t, slice.Spec.Resources[0].Manifest, "test-cm")
+
+	pods := &corev1.PodList{}
+	require.NoError(t, cli.List(ctx, pods))
+	assert.Empty(t, pods.Items)
+}
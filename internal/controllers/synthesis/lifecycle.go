@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -13,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -20,6 +22,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/archive"
+	"github.com/Azure/eno/internal/execution"
 	"github.com/Azure/eno/internal/manager"
 )
 
@@ -36,26 +40,96 @@ type Config struct {
 	NodeAffinityValue string
 
 	ContainerCreationTimeout time.Duration
+
+	// ImagePullSecrets are referenced by every synthesis pod. Synthesizers can add their own.
+	ImagePullSecrets []string
+
+	// ImagePullPolicy is the default pull policy of synthesis pod containers. Synthesizers can override it.
+	ImagePullPolicy corev1.PullPolicy
+
+	// RegistryMirrors rewrites the registry of the executor and synthesizer images e.g. for air-gapped environments.
+	// Keyed by the original registry host.
+	RegistryMirrors map[string]string
+
+	// PodLabels are added to every synthesis pod e.g. to match the NetworkPolicies that allow their egress.
+	PodLabels map[string]string
+
+	// EgressProxy optionally sets the HTTP_PROXY and HTTPS_PROXY of synthesis pods.
+	// NoProxy is always set when it isn't empty.
+	EgressProxy string
+	NoProxy     string
+
+	// RequiredEndpoints are host:port addresses that must be reachable from synthesis pods before the synthesizer
+	// is executed, so network misconfiguration fails fast instead of hanging mid-synthesis.
+	RequiredEndpoints []string
+
+	// PostProcessorURL is an optional HTTP endpoint that receives the complete output of every synthesis.
+	PostProcessorURL     string
+	PostProcessorTimeout time.Duration
+
+	// SopsDecryption enables decryption of SOPS-encrypted inputs by synthesis pods.
+	// SopsKeySecret optionally names a secret in the pod namespace that holds keys for sops e.g. SOPS_AGE_KEY.
+	SopsDecryption bool
+	SopsKeySecret  string
+
+	// AllowSecretImports allows compositions to import Secrets with spec.import.
+	AllowSecretImports bool
+
+	// Archive optionally receives the final manifests and status of compositions before they are deleted.
+	Archive archive.Sink
 }
 
 type podLifecycleController struct {
-	config        *Config
-	client        client.Client
-	noCacheReader client.Reader
+	config          *Config
+	client          client.Client
+	noCacheReader   client.Reader
+	recorder        record.EventRecorder
+	archiveFailures *failureCounter
+}
+
+const (
+	maxArchiveAttempts   = 5
+	archiveRetryInterval = time.Second * 5
+)
+
+// failureCounter tracks consecutive failures per composition in memory.
+type failureCounter struct {
+	lock   sync.Mutex
+	counts map[types.UID]int
+}
+
+func newFailureCounter() *failureCounter {
+	return &failureCounter{counts: map[types.UID]int{}}
+}
+
+// Observe records a failure and returns the number of consecutive failures.
+func (f *failureCounter) Observe(uid types.UID) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.counts[uid]++
+	return f.counts[uid]
+}
+
+func (f *failureCounter) Forget(uid types.UID) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.counts, uid)
 }
 
 // NewPodLifecycleController is responsible for creating and deleting pods as needed to synthesize compositions.
 func NewPodLifecycleController(mgr ctrl.Manager, cfg *Config) error {
 	c := &podLifecycleController{
-		config:        cfg,
-		client:        mgr.GetClient(),
-		noCacheReader: mgr.GetAPIReader(),
+		config:          cfg,
+		client:          mgr.GetClient(),
+		noCacheReader:   mgr.GetAPIReader(),
+		recorder:        mgr.GetEventRecorderFor("eno-controller"),
+		archiveFailures: newFailureCounter(),
 	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&apiv1.Composition{}).
 		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(manager.PodToCompMapFunc)).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "podLifecycleController")).
-		Complete(c)
+		Complete(manager.WithController("podLifecycleController", c))
 }
 
 func (c *podLifecycleController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -73,6 +147,9 @@ func (c *podLifecycleController) Reconcile(ctx context.Context, req ctrl.Request
 		"compositionNamespace", comp.Namespace,
 		"compositionGeneration", comp.Generation,
 		"synthesisID", comp.Status.GetCurrentSynthesisUUID())
+	if manager.DebugLoggingEnabled(comp, time.Now()) {
+		logger = manager.WithDebugLogging(logger)
+	}
 
 	// It isn't safe to delete compositions until their resource slices have been cleaned up,
 	// since reconciling resources necessarily requires the composition.
@@ -97,7 +174,12 @@ func (c *podLifecycleController) Reconcile(ctx context.Context, req ctrl.Request
 	// Tolerate missing synths since we may still need to cleanup
 	syn := &apiv1.Synthesizer{}
 	syn.Name = comp.Spec.Synthesizer.Name
-	err = c.client.Get(ctx, client.ObjectKeyFromObject(syn), syn)
+	if comp.Passthrough() {
+		// Passthrough compositions are synthesized in-process, so any pods left over from an earlier synthesizer time out immediately
+		syn.Spec.PodTimeout = &metav1.Duration{}
+	} else {
+		err = c.client.Get(ctx, client.ObjectKeyFromObject(syn), syn)
+	}
 	// It's only safe to ignore as a missing synth if we have already started synthesis,
 	// otherwise creating the synth and composition around the same time could result in a deadlock
 	// if the composition is processed before the synth hits the informer cache.
@@ -117,6 +199,9 @@ func (c *podLifecycleController) Reconcile(ctx context.Context, req ctrl.Request
 		if err := c.client.Delete(ctx, toDelete); err != nil {
 			return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("deleting pod: %w", err))
 		}
+		if synthesisCanceled(comp, syn) {
+			synthesesCanceled.Inc()
+		}
 		logger.V(0).Info("deleted synthesizer pod", "podName", toDelete.Name)
 		return ctrl.Result{}, nil
 	}
@@ -140,11 +225,19 @@ func (c *podLifecycleController) Reconcile(ctx context.Context, req ctrl.Request
 
 	// Swap the state to prepare for resynthesis if needed
 	if shouldSwapStates(syn, comp) {
-		SwapStates(comp)
+		reason := synthesisReason(comp)
+		if comp.Status.CurrentSynthesis == nil && comp.Spec.Import != nil && comp.DeletionTimestamp == nil {
+			prev, err := c.importResources(logr.NewContext(ctx, logger), comp)
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("importing existing resources: %w", err)
+			}
+			comp.Status.PreviousSynthesis = prev
+		}
+		SwapStates(comp, reason)
 		if err := c.client.Status().Update(ctx, comp); err != nil {
 			return ctrl.Result{}, fmt.Errorf("swapping compisition state: %w", err)
 		}
-		logger.V(0).Info("start to synthesize")
+		logger.V(0).Info("start to synthesize", "reason", reason)
 		return ctrl.Result{Requeue: true}, nil
 	}
 
@@ -153,6 +246,11 @@ func (c *podLifecycleController) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, nil
 	}
 
+	if comp.Passthrough() {
+		ctx = logr.NewContext(ctx, logger)
+		return ctrl.Result{}, c.synthesizeManifests(ctx, comp)
+	}
+
 	// Back off to avoid constantly re-synthesizing impossible compositions (unlikely but possible)
 	if shouldBackOffPodCreation(comp) {
 		const base = time.Millisecond * 250
@@ -187,7 +285,7 @@ func (c *podLifecycleController) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, fmt.Errorf("creating pod: %w", err)
 	}
 	logger.V(0).Info("created synthesizer pod", "podName", pod.Name)
-	sytheses.Inc()
+	sytheses.WithLabelValues(comp.Status.CurrentSynthesis.Reason).Inc()
 
 	// This metadata is optional - it's safe for the process to crash before reaching this point
 	patch := []map[string]any{
@@ -208,9 +306,43 @@ func (c *podLifecycleController) Reconcile(ctx context.Context, req ctrl.Request
 	return ctrl.Result{}, nil
 }
 
+// synthesizeManifests synthesizes passthrough compositions in-process instead of creating a pod.
+func (c *podLifecycleController) synthesizeManifests(ctx context.Context, comp *apiv1.Composition) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	e := &execution.Executor{
+		Reader:  c.noCacheReader,
+		Writer:  c.client,
+		Handler: execution.NewManifestHandler(c.noCacheReader, comp),
+	}
+	if c.config.PostProcessorURL != "" {
+		e.PostProcessor = execution.NewHTTPPostProcessor(c.config.PostProcessorURL, c.config.PostProcessorTimeout)
+	}
+	env := &execution.Env{
+		CompositionName:      comp.Name,
+		CompositionNamespace: comp.Namespace,
+		SynthesisUUID:        comp.Status.CurrentSynthesis.UUID,
+		SynthesisAttempt:     comp.Status.CurrentSynthesis.Attempts + 1,
+	}
+
+	start := time.Now()
+	err := e.Synthesize(ctx, env)
+	if err != nil {
+		return fmt.Errorf("synthesizing manifests: %w", err)
+	}
+	sytheses.WithLabelValues(comp.Status.CurrentSynthesis.Reason).Inc()
+	logger.V(0).Info("synthesized manifests", "latency", time.Since(start).Milliseconds())
+	return nil
+}
+
 func (c *podLifecycleController) reconcileDeletedComposition(ctx context.Context, comp *apiv1.Composition) (ctrl.Result, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 
+	if comp.DeletionBlocked() {
+		logger.V(1).Info("refusing to delete composition because deletion protection is enabled and deletion has not been confirmed")
+		return ctrl.Result{}, nil
+	}
+
 	// If the composition was being synthesized at the time of deletion we need to swap the previous
 	// state back to current. Otherwise we'll get stuck waiting for a synthesis that can't happen.
 	if shouldRevertStateSwap(comp) {
@@ -224,6 +356,41 @@ func (c *podLifecycleController) reconcileDeletedComposition(ctx context.Context
 		return ctrl.Result{}, nil
 	}
 
+	// Archive the composition before its resource slices start being cleaned up.
+	// Archival is best-effort: deletion proceeds once the sink has failed a few times.
+	if c.config.Archive != nil && comp.Annotations[archive.AnnotationKey] == "" {
+		result := archive.AnnotationValueArchived
+		a, err := archive.New(ctx, c.client, comp)
+		if err == nil {
+			err = c.config.Archive.Write(ctx, a)
+		}
+		if err != nil {
+			attempts := c.archiveFailures.Observe(comp.UID)
+			if attempts < maxArchiveAttempts {
+				logger.V(0).Info("failed to archive composition - will retry", "error", err.Error(), "attempts", attempts)
+				return ctrl.Result{RequeueAfter: time.Duration(attempts) * archiveRetryInterval}, nil
+			}
+			logger.Error(err, "giving up on archiving composition", "attempts", attempts)
+			if c.recorder != nil {
+				c.recorder.Eventf(comp, corev1.EventTypeWarning, "ArchiveFailed", "giving up on archiving the composition after %d attempts: %s", attempts, err)
+			}
+			result = archive.AnnotationValueFailed
+		}
+		if comp.Annotations == nil {
+			comp.Annotations = map[string]string{}
+		}
+		comp.Annotations[archive.AnnotationKey] = result
+		err = c.client.Update(ctx, comp)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("marking composition as archived: %w", err)
+		}
+		c.archiveFailures.Forget(comp.UID)
+		if result == archive.AnnotationValueArchived {
+			logger.V(0).Info("archived composition", "manifests", len(a.Manifests))
+		}
+		return ctrl.Result{}, nil
+	}
+
 	// Deletion increments the composition's generation, but the reconstitution cache is only invalidated
 	// when the synthesized generation (from the status) changes, which will never happen because synthesis
 	// is righly disabled for deleted compositions. We break out of this deadlock condition by updating
@@ -352,6 +519,12 @@ func shouldDeletePod(logger logr.Logger, comp *apiv1.Composition, syn *apiv1.Syn
 	return logger, nil, false
 }
 
+// synthesisCanceled returns true when deleting the composition's synthesizer pod cancels an unfinished synthesis
+// because the composition is being deleted.
+func synthesisCanceled(comp *apiv1.Composition, syn *apiv1.Synthesizer) bool {
+	return syn != nil && comp.DeletionTimestamp != nil && comp.Status.CurrentSynthesis != nil && comp.Status.CurrentSynthesis.Synthesized == nil
+}
+
 // deletePod deletes one Pod associated to the given comp unconditionally.
 // Should only be used when the composition no longer exists.
 func (c *podLifecycleController) deletePod(ctx context.Context, comp types.NamespacedName) error {
@@ -376,8 +549,19 @@ func (c *podLifecycleController) deletePod(ctx context.Context, comp types.Names
 	return nil
 }
 
-func SwapStates(comp *apiv1.Composition) {
+// SwapStates starts a new synthesis of the composition for the given reason.
+//
+// A pending synthesis that hasn't been dispatched yet is collapsed into the new one
+// rather than replaced, since only the latest generation would be synthesized anyway.
+func SwapStates(comp *apiv1.Composition, reason string) {
 	current := comp.Status.CurrentSynthesis
+	if current != nil && current.UUID == "" && current.Synthesized == nil {
+		current.ObservedCompositionGeneration = comp.Generation
+		current.Reason = reason
+		synthesesCollapsed.Inc()
+		return
+	}
+
 	if current != nil && current.Synthesized != nil && !current.Failed() {
 		comp.Status.PreviousSynthesis = current
 	}
@@ -385,6 +569,21 @@ func SwapStates(comp *apiv1.Composition) {
 	comp.Status.CurrentSynthesis = &apiv1.Synthesis{
 		ObservedCompositionGeneration: comp.Generation,
 		Initialized:                   ptr.To(metav1.Now()),
+		Reason:                        reason,
+	}
+}
+
+// synthesisReason returns the reason for a synthesis that was initiated because shouldSwapStates returned true.
+func synthesisReason(comp *apiv1.Composition) string {
+	switch {
+	case comp.Status.CurrentSynthesis == nil:
+		return apiv1.SynthesisReasonInitial
+	case comp.DeletionTimestamp != nil:
+		return apiv1.SynthesisReasonDeletion
+	case comp.Status.CurrentSynthesis.ObservedCompositionGeneration != comp.Generation:
+		return apiv1.SynthesisReasonSpecChanged
+	default:
+		return apiv1.SynthesisReasonInputChanged
 	}
 }
 
@@ -397,12 +596,14 @@ func shouldSwapStates(synth *apiv1.Synthesizer, comp *apiv1.Composition) bool {
 	//			- changes to non-defferred inputs.
 	// AND
 	// - synthesis is not already pending
-	// - all bound input resources exist and are in lockstep (or composition is being deleted)
+	// - all bound input resources exist, are in lockstep, and match the composition's pin (or composition is being deleted)
+	// - the current synthesis has been reconciled (only when strict sequencing is enabled)
 	syn := comp.Status.CurrentSynthesis
 	return (syn == nil ||
 		syn.ObservedCompositionGeneration != comp.Generation ||
 		(!inputRevisionsEqual(synth, comp.Status.InputRevisions, syn.InputRevisions) && syn.Synthesized != nil && !comp.ShouldIgnoreSideEffects())) &&
-		(comp.DeletionTimestamp != nil || (comp.InputsExist(synth) && !comp.InputsOutOfLockstep(synth)))
+		(comp.DeletionTimestamp != nil || (comp.InputsExist(synth) && !comp.InputsOutOfLockstep(synth) && !comp.PinMismatched(synth))) &&
+		!comp.SequencingBlocked()
 }
 
 func shouldBackOffPodCreation(comp *apiv1.Composition) bool {
@@ -12,11 +12,13 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/archive"
 	"github.com/Azure/eno/internal/controllers/flowcontrol"
 	"github.com/Azure/eno/internal/testutil"
 	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
@@ -46,7 +48,7 @@ func TestCompositionDeletion(t *testing.T) {
 
 	require.NoError(t, NewPodLifecycleController(mgr.Manager, minimalTestConfig))
 	require.NoError(t, NewSliceCleanupController(mgr.Manager))
-	require.NoError(t, flowcontrol.NewSynthesisConcurrencyLimiter(mgr.Manager, 10, 0))
+	require.NoError(t, flowcontrol.NewSynthesisConcurrencyLimiter(mgr.Manager, 10, 0, 0))
 	mgr.Start(t)
 
 	syn := &apiv1.Synthesizer{}
@@ -125,7 +127,7 @@ func TestDeleteCompositionWhenSynthesizerMissing(t *testing.T) {
 
 	require.NoError(t, NewPodLifecycleController(mgr.Manager, minimalTestConfig))
 	require.NoError(t, NewSliceCleanupController(mgr.Manager))
-	require.NoError(t, flowcontrol.NewSynthesisConcurrencyLimiter(mgr.Manager, 10, 0))
+	require.NoError(t, flowcontrol.NewSynthesisConcurrencyLimiter(mgr.Manager, 10, 0, 0))
 	mgr.Start(t)
 
 	syn := &apiv1.Synthesizer{}
@@ -250,6 +252,29 @@ var shouldDeletePodTests = []struct {
 		PodShouldExist:     true,
 		PodShouldBeDeleted: false,
 	},
+	{
+		Name: "preempted",
+		Pods: []corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: metav1.Now(),
+				Labels: map[string]string{
+					"eno.azure.io/synthesis-uuid": "test-uuid",
+				},
+			},
+		}},
+		Composition: &apiv1.Composition{
+			Status: apiv1.CompositionStatus{
+				CurrentSynthesis: &apiv1.Synthesis{}, // preemption removes the UUID
+			},
+		},
+		Synth: &apiv1.Synthesizer{
+			Spec: apiv1.SynthesizerSpec{
+				PodTimeout: ptr.To(metav1.Duration{Duration: time.Hour}),
+			},
+		},
+		PodShouldExist:     true,
+		PodShouldBeDeleted: true,
+	},
 	{
 		Name: "success",
 		Pods: []corev1.Pod{{
@@ -670,6 +695,22 @@ func TestShouldDeletePod(t *testing.T) {
 	}
 }
 
+func TestSynthesisCanceled(t *testing.T) {
+	now := metav1.Now()
+	syn := &apiv1.Synthesizer{}
+
+	comp := &apiv1.Composition{}
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid"}
+	assert.False(t, synthesisCanceled(comp, syn), "not deleted")
+
+	comp.DeletionTimestamp = &now
+	assert.True(t, synthesisCanceled(comp, syn))
+	assert.False(t, synthesisCanceled(comp, nil), "synthesizer deleted")
+
+	comp.Status.CurrentSynthesis.Synthesized = &now
+	assert.False(t, synthesisCanceled(comp, syn), "already synthesized")
+}
+
 func TestShouldSwapStates(t *testing.T) {
 	tests := []struct {
 		Name        string
@@ -708,6 +749,49 @@ func TestShouldSwapStates(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:        "strict sequencing with unreconciled synthesis",
+			Expectation: false,
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation:  2,
+					Annotations: map[string]string{apiv1.StrictSequencingAnnotation: "true"},
+				},
+				Status: apiv1.CompositionStatus{
+					CurrentSynthesis: &apiv1.Synthesis{
+						UUID:                          "test-uuid",
+						ObservedCompositionGeneration: 1,
+						Synthesized:                   &metav1.Time{},
+					},
+				},
+			},
+		},
+		{
+			Name:        "pinned input mismatch",
+			Expectation: false,
+			Composition: apiv1.Composition{
+				Spec: apiv1.CompositionSpec{
+					Bindings: []apiv1.Binding{{Key: "foo"}},
+					Pin:      &apiv1.CompositionPin{InputRevisions: []apiv1.InputRevisions{{Key: "foo", ResourceVersion: "1"}}},
+				},
+				Status: apiv1.CompositionStatus{
+					InputRevisions: []apiv1.InputRevisions{{Key: "foo", ResourceVersion: "2"}},
+				},
+			},
+		},
+		{
+			Name:        "pinned input match",
+			Expectation: true,
+			Composition: apiv1.Composition{
+				Spec: apiv1.CompositionSpec{
+					Bindings: []apiv1.Binding{{Key: "foo"}},
+					Pin:      &apiv1.CompositionPin{InputRevisions: []apiv1.InputRevisions{{Key: "foo", ResourceVersion: "1"}}},
+				},
+				Status: apiv1.CompositionStatus{
+					InputRevisions: []apiv1.InputRevisions{{Key: "foo", ResourceVersion: "1"}},
+				},
+			},
+		},
 		{
 			Name:        "non-matching composition generation",
 			Expectation: true,
@@ -989,3 +1073,85 @@ func TestInputRevisionsEqualOrdering(t *testing.T) {
 		{Key: "foo"}, {Key: "bar"},
 	}))
 }
+
+func TestSynthesisReason(t *testing.T) {
+	comp := &apiv1.Composition{}
+	comp.Generation = 2
+	assert.Equal(t, apiv1.SynthesisReasonInitial, synthesisReason(comp))
+
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{ObservedCompositionGeneration: 1}
+	assert.Equal(t, apiv1.SynthesisReasonSpecChanged, synthesisReason(comp))
+
+	comp.Status.CurrentSynthesis.ObservedCompositionGeneration = 2
+	assert.Equal(t, apiv1.SynthesisReasonInputChanged, synthesisReason(comp))
+
+	comp.DeletionTimestamp = &metav1.Time{}
+	assert.Equal(t, apiv1.SynthesisReasonDeletion, synthesisReason(comp))
+
+	SwapStates(comp, apiv1.SynthesisReasonManual)
+	assert.Equal(t, apiv1.SynthesisReasonManual, comp.Status.CurrentSynthesis.Reason)
+}
+
+func TestSwapStatesCollapsesPending(t *testing.T) {
+	comp := &apiv1.Composition{}
+	comp.Generation = 1
+	SwapStates(comp, apiv1.SynthesisReasonInitial)
+	initialized := comp.Status.CurrentSynthesis.Initialized
+
+	// Not yet dispatched: the pending synthesis is updated in place
+	comp.Generation = 2
+	SwapStates(comp, apiv1.SynthesisReasonSpecChanged)
+	assert.Equal(t, int64(2), comp.Status.CurrentSynthesis.ObservedCompositionGeneration)
+	assert.Equal(t, apiv1.SynthesisReasonSpecChanged, comp.Status.CurrentSynthesis.Reason)
+	assert.Same(t, initialized, comp.Status.CurrentSynthesis.Initialized)
+	assert.Nil(t, comp.Status.PreviousSynthesis)
+
+	// Dispatched: a new synthesis replaces it
+	comp.Status.CurrentSynthesis.UUID = "test-uuid"
+	comp.Generation = 3
+	SwapStates(comp, apiv1.SynthesisReasonSpecChanged)
+	assert.Equal(t, int64(3), comp.Status.CurrentSynthesis.ObservedCompositionGeneration)
+	assert.Empty(t, comp.Status.CurrentSynthesis.UUID)
+}
+
+type failingArchiveSink struct{ calls int }
+
+func (f *failingArchiveSink) Write(ctx context.Context, a *archive.Archive) error {
+	f.calls++
+	return errors.NewServiceUnavailable("sink is down")
+}
+
+// TestArchiveBestEffort proves that a failing archive sink doesn't block composition deletion forever.
+func TestArchiveBestEffort(t *testing.T) {
+	ctx := testutil.NewContext(t)
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.UID = "test-uid"
+	comp.Finalizers = []string{"eno.azure.io/cleanup"}
+	comp.DeletionTimestamp = ptr.To(metav1.Now())
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid", Synthesized: ptr.To(metav1.Now())}
+
+	cli := testutil.NewClient(t, comp)
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+
+	sink := &failingArchiveSink{}
+	recorder := record.NewFakeRecorder(10)
+	c := &podLifecycleController{config: &Config{Archive: sink}, client: cli, noCacheReader: cli, recorder: recorder, archiveFailures: newFailureCounter()}
+
+	for i := 1; i < maxArchiveAttempts; i++ {
+		result, err := c.reconcileDeletedComposition(ctx, comp)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(i)*archiveRetryInterval, result.RequeueAfter)
+		assert.Empty(t, comp.Annotations[archive.AnnotationKey])
+	}
+
+	_, err := c.reconcileDeletedComposition(ctx, comp)
+	require.NoError(t, err)
+	assert.Equal(t, maxArchiveAttempts, sink.calls)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Equal(t, archive.AnnotationValueFailed, comp.Annotations[archive.AnnotationKey])
+	assert.Contains(t, <-recorder.Events, "ArchiveFailed")
+}
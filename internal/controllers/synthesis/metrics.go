@@ -2,15 +2,16 @@ package synthesis
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
-	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/Azure/eno/internal/telemetry"
 )
 
 var (
-	sytheses = prometheus.NewCounter(
+	sytheses = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "eno_syntheses_total",
-			Help: "Initiated synthesis operations",
-		},
+			Help: "Initiated synthesis operations, partitioned by the reason that the synthesis was initiated",
+		}, []string{"reason"},
 	)
 
 	synthesPodRecreations = prometheus.NewCounter(
@@ -19,8 +20,22 @@ var (
 			Help: "Pods deleted due to timeout",
 		},
 	)
+
+	synthesesCollapsed = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "eno_syntheses_collapsed_total",
+			Help: "Pending syntheses that were skipped because the composition changed again before they were dispatched",
+		},
+	)
+
+	synthesesCanceled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "eno_syntheses_canceled_total",
+			Help: "In-flight syntheses canceled because their composition was deleted",
+		},
+	)
 )
 
 func init() {
-	metrics.Registry.MustRegister(sytheses, synthesPodRecreations)
+	telemetry.MustRegister(sytheses, synthesPodRecreations, synthesesCollapsed, synthesesCanceled)
 }
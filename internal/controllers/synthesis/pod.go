@@ -3,12 +3,14 @@ package synthesis
 import (
 	"slices"
 	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 
 	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/execution"
 	"github.com/Azure/eno/internal/manager"
 )
 
@@ -22,6 +24,9 @@ func newPod(cfg *Config, comp *apiv1.Composition, syn *apiv1.Synthesizer) *corev
 		manager.ManagerLabelKey:              manager.ManagerLabelValue,
 		"eno.azure.io/synthesis-uuid":        comp.Status.CurrentSynthesis.UUID,
 	}
+	for k, v := range cfg.PodLabels {
+		pod.Labels[k] = v
+	}
 	for k, v := range syn.Spec.PodOverrides.Labels {
 		pod.Labels[k] = v
 	}
@@ -50,6 +55,37 @@ func newPod(cfg *Config, comp *apiv1.Composition, syn *apiv1.Synthesizer) *corev
 		},
 	}
 
+	if cfg.PostProcessorURL != "" {
+		env = append(env, corev1.EnvVar{Name: "POST_PROCESSOR_URL", Value: cfg.PostProcessorURL})
+		if cfg.PostProcessorTimeout > 0 {
+			env = append(env, corev1.EnvVar{Name: "POST_PROCESSOR_TIMEOUT", Value: cfg.PostProcessorTimeout.String()})
+		}
+	}
+
+	if cfg.EgressProxy != "" {
+		env = append(env, corev1.EnvVar{Name: "HTTP_PROXY", Value: cfg.EgressProxy}, corev1.EnvVar{Name: "HTTPS_PROXY", Value: cfg.EgressProxy})
+	}
+	if cfg.NoProxy != "" {
+		env = append(env, corev1.EnvVar{Name: "NO_PROXY", Value: cfg.NoProxy})
+	}
+	if len(cfg.RequiredEndpoints) > 0 {
+		env = append(env, corev1.EnvVar{Name: "REQUIRED_ENDPOINTS", Value: strings.Join(cfg.RequiredEndpoints, ",")})
+	}
+
+	var envFrom []corev1.EnvFromSource
+	if cfg.SopsDecryption {
+		env = append(env, corev1.EnvVar{Name: "SOPS_BINARY", Value: "/eno/sops"})
+		if cfg.SopsKeySecret != "" {
+			// Prefixed so the executor can pass the keys to sops without exposing them to the synthesizer
+			envFrom = append(envFrom, corev1.EnvFromSource{
+				Prefix: execution.SopsKeyEnvPrefix,
+				SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: cfg.SopsKeySecret},
+				},
+			})
+		}
+	}
+
 	for _, ev := range filterEnv(env, comp.Spec.SynthesisEnv) {
 		env = append(env, corev1.EnvVar{Name: ev.Name, Value: ev.Value})
 	}
@@ -73,24 +109,27 @@ func newPod(cfg *Config, comp *apiv1.Composition, syn *apiv1.Synthesizer) *corev
 			},
 		},
 		InitContainers: []corev1.Container{{
-			Name:    "synth-installer",
-			Image:   cfg.ExecutorImage,
-			Command: []string{"/eno-controller", "install-executor"},
+			Name:            "synth-installer",
+			Image:           mirrorImage(cfg.RegistryMirrors, cfg.ExecutorImage),
+			ImagePullPolicy: cfg.ImagePullPolicy,
+			Command:         []string{"/eno-controller", "install-executor"},
 			VolumeMounts: []corev1.VolumeMount{{
 				Name:      "sharedfs",
 				MountPath: "/eno",
 			}},
 		}},
 		Containers: []corev1.Container{{
-			Name:    "executor",
-			Image:   syn.Spec.Image,
-			Command: []string{"/eno/executor"},
+			Name:            "executor",
+			Image:           mirrorImage(cfg.RegistryMirrors, syn.Spec.Image),
+			ImagePullPolicy: cfg.ImagePullPolicy,
+			Command:         []string{"/eno/executor"},
 			VolumeMounts: []corev1.VolumeMount{{
 				Name:      "sharedfs",
 				MountPath: "/eno",
 			}},
 			Resources: syn.Spec.PodOverrides.Resources,
 			Env:       env,
+			EnvFrom:   envFrom,
 			SecurityContext: &corev1.SecurityContext{
 				AllowPrivilegeEscalation: ptr.To(false),
 				ReadOnlyRootFilesystem:   ptr.To(true),
@@ -115,6 +154,14 @@ func newPod(cfg *Config, comp *apiv1.Composition, syn *apiv1.Synthesizer) *corev
 		}},
 	}
 
+	for _, name := range cfg.ImagePullSecrets {
+		pod.Spec.ImagePullSecrets = append(pod.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+	pod.Spec.ImagePullSecrets = append(pod.Spec.ImagePullSecrets, syn.Spec.PodOverrides.ImagePullSecrets...)
+	if syn.Spec.PodOverrides.ImagePullPolicy != "" {
+		pod.Spec.Containers[0].ImagePullPolicy = syn.Spec.PodOverrides.ImagePullPolicy
+	}
+
 	if cfg.TaintTolerationKey != "" {
 		toleration := corev1.Toleration{
 			Key:      cfg.TaintTolerationKey,
@@ -153,12 +200,16 @@ func podIsCurrent(comp *apiv1.Composition, pod *corev1.Pod) bool {
 	return pod.Labels != nil && comp.Status.CurrentSynthesis != nil && comp.Status.CurrentSynthesis.UUID == pod.Labels["eno.azure.io/synthesis-uuid"]
 }
 
+// reservedEnv configure the executor, so they can't be set by compositions even when the controller doesn't set them.
+// Otherwise a composition could e.g. send its synthesis output to an arbitrary post-processor, or route the executor's traffic through its own proxy.
+var reservedEnv = []string{"POST_PROCESSOR_URL", "POST_PROCESSOR_TIMEOUT", "REQUIRED_ENDPOINTS", "SOPS_BINARY", "DRY_RUN", "HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"}
+
 // filterEnv returns env taking out any items that have the same name as
 // any item in filter.
 func filterEnv(filter []corev1.EnvVar, env []apiv1.EnvVar) []apiv1.EnvVar {
 	res := []apiv1.EnvVar{}
 	for _, ev := range env {
-		if slices.ContainsFunc(filter, func(f corev1.EnvVar) bool {
+		if slices.Contains(reservedEnv, ev.Name) || strings.HasPrefix(ev.Name, execution.SopsKeyEnvPrefix) || slices.ContainsFunc(filter, func(f corev1.EnvVar) bool {
 			return f.Name == ev.Name
 		}) {
 			continue
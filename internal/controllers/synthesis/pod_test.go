@@ -2,6 +2,7 @@ package synthesis
 
 import (
 	"testing"
+	"time"
 
 	apiv1 "github.com/Azure/eno/api/v1"
 	"github.com/stretchr/testify/assert"
@@ -71,6 +72,73 @@ var newPodTests = []struct {
 			assert.Equal(t, corev1.TolerationOpExists, p.Spec.Tolerations[0].Operator)
 		},
 	},
+	{
+		Name: "with network config",
+		Cfg: &Config{
+			PodLabels:         map[string]string{"network-policy": "synthesis"},
+			EgressProxy:       "http://proxy:3128",
+			NoProxy:           "10.0.0.1",
+			RequiredEndpoints: []string{"registry:443", "charts:443"},
+		},
+		Assert: func(t *testing.T, p *corev1.Pod) {
+			assert.Equal(t, "synthesis", p.Labels["network-policy"])
+			assert.Equal(t, "eno", p.Labels["app.kubernetes.io/managed-by"])
+			env := p.Spec.Containers[0].Env
+			assert.Contains(t, env, corev1.EnvVar{Name: "HTTP_PROXY", Value: "http://proxy:3128"})
+			assert.Contains(t, env, corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy:3128"})
+			assert.Contains(t, env, corev1.EnvVar{Name: "NO_PROXY", Value: "10.0.0.1"})
+			assert.Contains(t, env, corev1.EnvVar{Name: "REQUIRED_ENDPOINTS", Value: "registry:443,charts:443"})
+		},
+	},
+	{
+		Name: "with sops decryption",
+		Cfg: &Config{
+			SopsDecryption: true,
+			SopsKeySecret:  "sops-keys",
+		},
+		Comp: func() *apiv1.Composition {
+			comp := &apiv1.Composition{}
+			comp.Name = "test-composition"
+			comp.Namespace = "test-composition-ns"
+			comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid"}
+			comp.Spec.SynthesisEnv = []apiv1.EnvVar{{Name: "ENO_SOPS_KEY_SOPS_AGE_KEY", Value: "attacker-key"}}
+			return comp
+		}(),
+		Assert: func(t *testing.T, p *corev1.Pod) {
+			assert.Contains(t, p.Spec.Containers[0].Env, corev1.EnvVar{Name: "SOPS_BINARY", Value: "/eno/sops"})
+			require.Len(t, p.Spec.Containers[0].EnvFrom, 1)
+			assert.Equal(t, "sops-keys", p.Spec.Containers[0].EnvFrom[0].SecretRef.Name)
+			assert.Equal(t, "ENO_SOPS_KEY_", p.Spec.Containers[0].EnvFrom[0].Prefix, "keys are hidden from the synthesizer by prefix")
+			for _, ev := range p.Spec.Containers[0].Env {
+				assert.NotEqual(t, "ENO_SOPS_KEY_SOPS_AGE_KEY", ev.Name, "compositions can't set keys")
+			}
+		},
+	},
+	{
+		Name: "with image config",
+		Cfg: &Config{
+			ExecutorImage:    "mcr.microsoft.com/eno:v1",
+			ImagePullSecrets: []string{"controller-secret"},
+			ImagePullPolicy:  corev1.PullIfNotPresent,
+			RegistryMirrors:  map[string]string{"mcr.microsoft.com": "mirror.local/mcr", "docker.io": "mirror.local/dockerhub"},
+		},
+		Synth: &apiv1.Synthesizer{
+			Spec: apiv1.SynthesizerSpec{
+				Image: "synth:v2",
+				PodOverrides: apiv1.PodOverrides{
+					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "synth-secret"}},
+					ImagePullPolicy:  corev1.PullAlways,
+				},
+			},
+		},
+		Assert: func(t *testing.T, p *corev1.Pod) {
+			assert.Equal(t, "mirror.local/mcr/eno:v1", p.Spec.InitContainers[0].Image)
+			assert.Equal(t, corev1.PullIfNotPresent, p.Spec.InitContainers[0].ImagePullPolicy)
+			assert.Equal(t, "mirror.local/dockerhub/library/synth:v2", p.Spec.Containers[0].Image)
+			assert.Equal(t, corev1.PullAlways, p.Spec.Containers[0].ImagePullPolicy)
+			assert.Equal(t, []corev1.LocalObjectReference{{Name: "controller-secret"}, {Name: "synth-secret"}}, p.Spec.ImagePullSecrets)
+		},
+	},
 	{
 		Name: "with full overrides struct",
 		Synth: &apiv1.Synthesizer{
@@ -122,6 +190,41 @@ var newPodTests = []struct {
 			assert.Contains(t, p.Spec.Containers[0].Env, corev1.EnvVar{Name: "COMPOSITION_NAME", Value: "test-composition"})
 		},
 	},
+	{
+		Name: "executor variables are reserved",
+		Comp: func() *apiv1.Composition {
+			comp := &apiv1.Composition{}
+			comp.Name = "test-composition"
+			comp.Namespace = "test-composition-ns"
+			comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid"}
+			comp.Spec.SynthesisEnv = []apiv1.EnvVar{
+				{Name: "POST_PROCESSOR_URL", Value: "http://attacker.example.com"},
+				{Name: "POST_PROCESSOR_TIMEOUT", Value: "1h"},
+				{Name: "REQUIRED_ENDPOINTS", Value: "attacker.example.com:443"},
+				{Name: "HTTPS_PROXY", Value: "http://attacker.example.com"},
+				{Name: "https_proxy", Value: "http://attacker.example.com"},
+			}
+			return comp
+		}(),
+		Assert: func(t *testing.T, p *corev1.Pod) {
+			for _, ev := range p.Spec.Containers[0].Env {
+				assert.NotContains(t, []string{"POST_PROCESSOR_URL", "POST_PROCESSOR_TIMEOUT", "REQUIRED_ENDPOINTS", "HTTPS_PROXY", "https_proxy"}, ev.Name)
+			}
+		},
+	},
+	{
+		Name: "post-processor",
+		Cfg: &Config{
+			PodNamespace:         "test-ns",
+			ExecutorImage:        "test-image",
+			PostProcessorURL:     "http://post-processor.example.com",
+			PostProcessorTimeout: 30 * time.Second,
+		},
+		Assert: func(t *testing.T, p *corev1.Pod) {
+			assert.Contains(t, p.Spec.Containers[0].Env, corev1.EnvVar{Name: "POST_PROCESSOR_URL", Value: "http://post-processor.example.com"})
+			assert.Contains(t, p.Spec.Containers[0].Env, corev1.EnvVar{Name: "POST_PROCESSOR_TIMEOUT", Value: "30s"})
+		},
+	},
 }
 
 func TestNewPod(t *testing.T) {
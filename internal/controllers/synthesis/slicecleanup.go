@@ -27,10 +27,10 @@ func NewSliceCleanupController(mgr ctrl.Manager) error {
 		For(&apiv1.ResourceSlice{}).
 		Watches(&apiv1.Composition{}, manager.NewCompositionToResourceSliceHandler(mgr.GetClient())).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "resourceSliceCleanupController")).
-		Complete(&sliceCleanupController{
+		Complete(manager.WithController("resourceSliceCleanupController", &sliceCleanupController{
 			client:        mgr.GetClient(),
 			noCacheReader: mgr.GetAPIReader(),
-		})
+		}))
 }
 
 func (c *sliceCleanupController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -155,7 +155,7 @@ func shouldDeleteSlice(comp *apiv1.Composition, slice *apiv1.ResourceSlice) bool
 		hasBeenRetried     = slice.Spec.Attempt != 0 && comp.Status.CurrentSynthesis.Attempts > slice.Spec.Attempt && slice.Spec.SynthesisUUID == comp.Status.CurrentSynthesis.UUID
 		isReferencedByComp = synthesisReferencesSlice(comp.Status.CurrentSynthesis, slice) || synthesisReferencesSlice(comp.Status.PreviousSynthesis, slice)
 		isSynthesized      = comp.Status.CurrentSynthesis.Synthesized != nil
-		compIsDeleted      = comp.DeletionTimestamp != nil
+		compIsDeleted      = comp.DeletionTimestamp != nil && !comp.DeletionBlocked()
 		fromOldComposition = slice.Spec.CompositionGeneration < comp.Status.CurrentSynthesis.ObservedCompositionGeneration
 	)
 
@@ -191,7 +191,7 @@ func resourcesRemain(comp *apiv1.Composition, slice *apiv1.ResourceSlice) bool {
 	if len(slice.Status.Resources) == 0 && len(slice.Spec.Resources) > 0 {
 		return true // status is lagging behind
 	}
-	shouldOrphan := comp != nil && comp.Annotations != nil && comp.Annotations["eno.azure.io/deletion-strategy"] == "orphan"
+	shouldOrphan := comp != nil && comp.ShouldOrphan()
 	for _, state := range slice.Status.Resources {
 		if !state.Deleted && !shouldOrphan {
 			return true
@@ -162,6 +162,39 @@ func TestShouldDeleteSlice(t *testing.T) {
 			slice:    &apiv1.ResourceSlice{},
 			expected: true,
 		},
+		{
+			name: "composition is deleted but deletion has not been confirmed",
+			comp: &apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec: apiv1.CompositionSpec{DeletionProtection: true},
+				Status: apiv1.CompositionStatus{
+					CurrentSynthesis: &apiv1.Synthesis{
+						Synthesized: &metav1.Time{Time: time.Now()},
+					},
+				},
+			},
+			slice:    &apiv1.ResourceSlice{},
+			expected: false,
+		},
+		{
+			name: "composition is deleted and deletion has been confirmed",
+			comp: &apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+					Annotations:       map[string]string{apiv1.DeletionConfirmedAnnotation: "true"},
+				},
+				Spec: apiv1.CompositionSpec{DeletionProtection: true},
+				Status: apiv1.CompositionStatus{
+					CurrentSynthesis: &apiv1.Synthesis{
+						Synthesized: &metav1.Time{Time: time.Now()},
+					},
+				},
+			},
+			slice:    &apiv1.ResourceSlice{},
+			expected: true,
+		},
 		{
 			name: "synthesis terminated and slice referenced",
 			comp: &apiv1.Composition{
@@ -0,0 +1,65 @@
+package synthesis
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+// WriteSlices writes resource slices that were built by the controller (rather than a synthesizer pod) using
+// deterministic names i.e. <prefix>-<index>. Retrying after a failed status write replaces the slices written by
+// the earlier attempt instead of orphaning them, and any extra slices left behind by the earlier attempt are deleted.
+func WriteSlices(ctx context.Context, cli client.Client, reader client.Reader, comp *apiv1.Composition, slices []*apiv1.ResourceSlice, prefix string) ([]*apiv1.ResourceSliceRef, error) {
+	var refs []*apiv1.ResourceSliceRef
+	for i, slice := range slices {
+		slice.GenerateName = ""
+		slice.Name = fmt.Sprintf("%s-%d", prefix, i)
+		if err := writeSlice(ctx, cli, reader, comp, slice); err != nil {
+			return nil, err
+		}
+		refs = append(refs, &apiv1.ResourceSliceRef{Name: slice.Name})
+	}
+
+	for i := len(slices); ; i++ {
+		slice := &apiv1.ResourceSlice{}
+		slice.Name = fmt.Sprintf("%s-%d", prefix, i)
+		slice.Namespace = comp.Namespace
+		err := cli.Delete(ctx, slice)
+		if errors.IsNotFound(err) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("deleting extra resource slice: %w", err)
+		}
+	}
+	return refs, nil
+}
+
+// writeSlice creates the given slice, or replaces the spec of the one written by an earlier attempt.
+func writeSlice(ctx context.Context, cli client.Client, reader client.Reader, comp *apiv1.Composition, slice *apiv1.ResourceSlice) error {
+	err := cli.Create(ctx, slice)
+	if !errors.IsAlreadyExists(err) {
+		if err != nil {
+			return fmt.Errorf("creating resource slice: %w", err)
+		}
+		return nil
+	}
+
+	existing := &apiv1.ResourceSlice{}
+	if err := reader.Get(ctx, client.ObjectKeyFromObject(slice), existing); err != nil {
+		return fmt.Errorf("getting existing resource slice: %w", err)
+	}
+	if owner := metav1.GetControllerOf(existing); owner == nil || owner.UID != comp.UID {
+		return fmt.Errorf("resource slice %q already exists and isn't owned by the composition", slice.Name)
+	}
+	existing.Spec = slice.Spec
+	if err := cli.Update(ctx, existing); err != nil {
+		return fmt.Errorf("updating existing resource slice: %w", err)
+	}
+	return nil
+}
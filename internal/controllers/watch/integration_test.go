@@ -8,7 +8,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -400,3 +402,46 @@ func TestRemoveInput(t *testing.T) {
 		return len(comp.Status.InputRevisions) == 0
 	})
 }
+
+func TestUpstreamChange(t *testing.T) {
+	mgr := testutil.NewManager(t)
+	require.NoError(t, NewController(mgr.Manager))
+	mgr.Start(t)
+
+	ctx := testutil.NewContext(t)
+	cli := mgr.GetClient()
+
+	upstream := &apiv1.Composition{}
+	upstream.Name = "test-upstream"
+	upstream.Namespace = "default"
+	require.NoError(t, cli.Create(ctx, upstream))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Upstreams = []apiv1.UpstreamInput{{Key: "up", Composition: upstream.Name}}
+	require.NoError(t, cli.Create(ctx, comp))
+
+	setSynthesis := func(uuid string) {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			cli.Get(ctx, client.ObjectKeyFromObject(upstream), upstream)
+			upstream.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: uuid, Synthesized: ptr.To(metav1.Now())}
+			return cli.Status().Update(ctx, upstream)
+		})
+		require.NoError(t, err)
+	}
+
+	// The upstream's synthesis is recorded as an input revision
+	setSynthesis("uuid-1")
+	testutil.Eventually(t, func() bool {
+		cli.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+		return len(comp.Status.InputRevisions) == 1 && comp.Status.InputRevisions[0].Key == "up" && comp.Status.InputRevisions[0].ResourceVersion == "uuid-1"
+	})
+
+	// Resynthesizing the upstream updates the revision
+	setSynthesis("uuid-2")
+	testutil.Eventually(t, func() bool {
+		cli.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+		return len(comp.Status.InputRevisions) == 1 && comp.Status.InputRevisions[0].ResourceVersion == "uuid-2"
+	})
+}
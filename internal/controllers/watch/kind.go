@@ -72,7 +72,7 @@ func (k *KindWatchController) newResourceWatchController(parent *WatchController
 			// Maybe expose as a flag in the future.
 			Limiter: rate.NewLimiter(rate.Every(time.Second), 2),
 		},
-		Reconciler: k,
+		Reconciler: manager.WithController("kindWatchController", k),
 	})
 	if err != nil {
 		return nil, err
@@ -203,6 +203,7 @@ func (k *KindWatchController) Reconcile(ctx context.Context, req ctrl.Request) (
 
 			if deferred && comp.Status.PendingResynthesis == nil && !comp.ShouldIgnoreSideEffects() {
 				comp.Status.PendingResynthesis = ptr.To(metav1.Now())
+				comp.Status.PendingResynthesisReason = apiv1.SynthesisReasonInputChanged
 			}
 
 			// TODO: Reduce risk of conflict errors here
@@ -44,5 +44,10 @@ func hasBindingKey(comp *apiv1.Composition, key string) bool {
 			return true
 		}
 	}
+	for _, up := range comp.Spec.Upstreams {
+		if up.Key == key {
+			return true
+		}
+	}
 	return false
 }
@@ -0,0 +1,84 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/manager"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// upstreamController records the output revision (most recent successful synthesis and exports) of each upstream composition
+// as an input revision of its downstream compositions, which causes them to be resynthesized.
+type upstreamController struct {
+	client client.Client
+}
+
+func (c *upstreamController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	comp := &apiv1.Composition{}
+	err := c.client.Get(ctx, req.NamespacedName, comp)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	var changed bool
+	for _, up := range comp.Spec.Upstreams {
+		upstream := &apiv1.Composition{}
+		upstream.Name = up.Composition
+		upstream.Namespace = comp.Namespace
+		err := c.client.Get(ctx, client.ObjectKeyFromObject(upstream), upstream)
+		if errors.IsNotFound(err) {
+			continue // keep the last known revision
+		}
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("getting upstream composition: %w", err)
+		}
+
+		rev := upstream.OutputRevision()
+		if rev == "" {
+			continue // not synthesized yet
+		}
+		if setInputRevisions(comp, &apiv1.InputRevisions{Key: up.Key, ResourceVersion: rev}) {
+			changed = true
+			logger.V(0).Info("noticed upstream composition change", "compositionName", comp.Name, "compositionNamespace", comp.Namespace, "ref", up.Key, "upstreamCompositionName", upstream.Name, "upstreamRevision", rev)
+		}
+	}
+	if !changed {
+		return ctrl.Result{}, nil
+	}
+
+	err = c.client.Status().Update(ctx, comp)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("updating input revisions: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// mapComposition enqueues the composition itself (when it has upstreams) and every composition that consumes it.
+func (c *upstreamController) mapComposition(ctx context.Context, obj client.Object) []reconcile.Request {
+	var reqs []reconcile.Request
+	if comp, ok := obj.(*apiv1.Composition); ok && len(comp.Spec.Upstreams) > 0 {
+		reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(comp)})
+	}
+
+	list := &apiv1.CompositionList{}
+	err := c.client.List(ctx, list, client.MatchingFields{
+		manager.IdxCompositionsByUpstream: path.Join(obj.GetNamespace(), obj.GetName()),
+	})
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "listing downstream compositions")
+		return reqs
+	}
+	for _, comp := range list.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&comp)})
+	}
+	return reqs
+}
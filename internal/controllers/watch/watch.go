@@ -6,6 +6,7 @@ import (
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	apiv1 "github.com/Azure/eno/api/v1"
 	"github.com/Azure/eno/internal/manager"
@@ -22,21 +23,31 @@ func NewController(mgr ctrl.Manager) error {
 		Named("watchControllerController").
 		Watches(&apiv1.Synthesizer{}, manager.SingleEventHandler()).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "watchController")).
-		Complete(&WatchController{
+		Complete(manager.WithController("watchController", &WatchController{
 			mgr:            mgr,
 			client:         mgr.GetClient(),
 			refControllers: map[apiv1.ResourceRef]*KindWatchController{},
-		})
+		}))
 	if err != nil {
 		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	err = ctrl.NewControllerManagedBy(mgr).
 		For(&apiv1.Composition{}).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "watchPruningController")).
-		Complete(&pruningController{
+		Complete(manager.WithController("watchPruningController", &pruningController{
 			client: mgr.GetClient(),
-		})
+		}))
+	if err != nil {
+		return err
+	}
+
+	uc := &upstreamController{client: mgr.GetClient()}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("upstreamController").
+		Watches(&apiv1.Composition{}, handler.EnqueueRequestsFromMapFunc(uc.mapComposition)).
+		WithLogConstructor(manager.NewLogConstructor(mgr, "upstreamController")).
+		Complete(manager.WithController("upstreamController", uc))
 }
 
 func (c *WatchController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -2,64 +2,185 @@ package watchdog
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	apiv1 "github.com/Azure/eno/api/v1"
 	"github.com/Azure/eno/internal/manager"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// synthesizersWorkItem is the name of the work item that summarizes synthesizers for the fleet status.
+const synthesizersWorkItem = "synthesizers"
+
+// removalResyncInterval is how often compositions with pending resource removals are re-evaluated.
+// Resource slice status isn't watched, so removals are otherwise only noticed when the composition changes.
+const removalResyncInterval = time.Minute * 5
+
 // watchdogController exposes metrics that track the states of Eno resources relative to the current time.
 // The idea is to identify deadlock states so they can be alerted on.
+//
+// Compositions are evaluated individually as they change, and their contribution to the gauges is tracked incrementally.
+// Each composition is requeued when its next time-based transition (e.g. crossing the threshold) is due,
+// so large fleets don't require a full list every time any composition changes.
 type watchdogController struct {
 	client    client.Client
 	threshold time.Duration
+	fleet     *Fleet
+
+	mut    sync.Mutex
+	states map[types.NamespacedName]*compositionState
+	counts *stateCounts
 }
 
-func NewController(mgr ctrl.Manager, threshold time.Duration) error {
-	return ctrl.NewControllerManagedBy(mgr).
+// NewController starts the watchdog. When fleet is non-nil, it's also kept up to date with a summary of every composition and synthesizer.
+func NewController(mgr ctrl.Manager, threshold time.Duration, fleet *Fleet) error {
+	b := ctrl.NewControllerManagedBy(mgr).
 		Named("watchdogController").
-		Watches(&apiv1.Composition{}, manager.SingleEventHandler()).
+		Watches(&apiv1.Composition{}, &handler.EnqueueRequestForObject{})
+	if fleet != nil {
+		// Synthesizers are summarized by a separate work item so synthesizer changes don't re-evaluate every composition
+		b = b.Watches(&apiv1.Synthesizer{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: synthesizersWorkItem}}}
+		}))
+	}
+	return b.
 		WithLogConstructor(manager.NewLogConstructor(mgr, "watchdogController")).
-		Complete(&watchdogController{
-			client:    mgr.GetClient(),
-			threshold: threshold,
-		})
+		Complete(manager.WithController("watchdogController", newController(mgr.GetClient(), threshold, fleet)))
+}
+
+func newController(cli client.Client, threshold time.Duration, fleet *Fleet) *watchdogController {
+	return &watchdogController{
+		client:    cli,
+		threshold: threshold,
+		fleet:     fleet,
+		states:    map[types.NamespacedName]*compositionState{},
+		counts:    newStateCounts(),
+	}
 }
 
 func (c *watchdogController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	list := &apiv1.CompositionList{}
-	err := c.client.List(ctx, list)
+	if req.Name == synthesizersWorkItem && req.Namespace == "" {
+		synths := &apiv1.SynthesizerList{}
+		err := c.client.List(ctx, synths)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		summary := summarizeSynthesizers(synths.Items)
+		c.fleet.update(func(fs *FleetStatus) { fs.Synthesizers = summary })
+		return ctrl.Result{}, nil
+	}
+
+	comp := &apiv1.Composition{}
+	err := c.client.Get(ctx, req.NamespacedName, comp)
+	if errors.IsNotFound(err) {
+		c.setState(req.NamespacedName, nil)
+		return ctrl.Result{}, nil
+	}
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	var pendingInit int
-	var pending int
-	var unready int
-	var terminal int
-	for _, comp := range list.Items {
-		if c.pendingInitialReconciliation(&comp) {
-			pendingInit++
+	slices, err := c.getSlices(ctx, comp)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := time.Now()
+	state := c.evaluate(comp, slices, now)
+	c.setState(req.NamespacedName, state)
+	return ctrl.Result{RequeueAfter: c.nextTransition(comp, state, now)}, nil
+}
+
+// getSlices returns the resource slices of the composition's current synthesis, keyed by name.
+func (c *watchdogController) getSlices(ctx context.Context, comp *apiv1.Composition) (map[types.NamespacedName]*apiv1.ResourceSlice, error) {
+	slices := map[types.NamespacedName]*apiv1.ResourceSlice{}
+	if comp.Status.CurrentSynthesis == nil || comp.ShouldOrphan() {
+		return slices, nil
+	}
+	for _, ref := range comp.Status.CurrentSynthesis.ResourceSlices {
+		slice := &apiv1.ResourceSlice{}
+		key := types.NamespacedName{Name: ref.Name, Namespace: comp.Namespace}
+		err := c.client.Get(ctx, key, slice)
+		if errors.IsNotFound(err) {
+			continue
 		}
-		if c.pendingReconciliation(&comp) {
-			pending++
+		if err != nil {
+			return nil, err
 		}
-		if c.pendingReadiness(&comp) {
-			unready++
+		slices[key] = slice
+	}
+	return slices, nil
+}
+
+func (c *watchdogController) evaluate(comp *apiv1.Composition, slices map[types.NamespacedName]*apiv1.ResourceSlice, now time.Time) *compositionState {
+	return &compositionState{
+		PendingInitialReconciliation: c.pendingInitialReconciliation(comp),
+		StuckReconciling:             c.pendingReconciliation(comp),
+		NotReady:                     c.pendingReadiness(comp),
+		TerminalError:                c.inTerminalError(comp),
+		PendingRemovals:              pendingRemovals(comp, slices),
+		DeletionAge:                  deletionAgeBucket(comp, now),
+		Health:                       compositionHealthState(comp),
+	}
+}
+
+// nextTransition returns the time until the composition's state would change without any other events, or zero if it won't.
+func (c *watchdogController) nextTransition(comp *apiv1.Composition, state *compositionState, now time.Time) time.Duration {
+	var next time.Duration
+	consider := func(at time.Time) {
+		// the predicates compare with ">" so requeue slightly after the deadline
+		if d := at.Sub(now) + time.Second; d > 0 && (next == 0 || d < next) {
+			next = d
 		}
-		if c.inTerminalError(&comp) {
-			terminal++
+	}
+
+	current := comp.Status.CurrentSynthesis
+	if !state.PendingInitialReconciliation && !synthesisHasReconciled(current) && !synthesisHasReconciled(comp.Status.PreviousSynthesis) {
+		consider(comp.CreationTimestamp.Add(c.threshold))
+	}
+	if !state.StuckReconciling && current != nil && current.Initialized != nil && !synthesisHasReconciled(current) {
+		consider(current.Initialized.Add(c.threshold))
+	}
+	if !state.NotReady && synthesisHasReconciled(current) && !synthesisIsReady(current) && !synthesisIsReady(comp.Status.PreviousSynthesis) {
+		consider(current.Reconciled.Add(c.threshold))
+	}
+	for _, bucket := range deletionAgeBuckets {
+		if comp.DeletionTimestamp != nil && bucket.Label == state.DeletionAge && bucket.Max > 0 {
+			consider(comp.DeletionTimestamp.Add(bucket.Max))
 		}
 	}
+	if state.PendingRemovals > 0 {
+		consider(now.Add(removalResyncInterval))
+	}
+	return next
+}
 
-	pendingInitialReconciliation.Set(float64(pendingInit))
-	stuckReconciling.Set(float64(pending))
-	pendingReadiness.Set(float64(unready))
-	terminalErrors.Set(float64(terminal))
+// setState replaces the composition's contribution to the gauges (and fleet status, if enabled). Nil removes it.
+func (c *watchdogController) setState(key types.NamespacedName, state *compositionState) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if prev, ok := c.states[key]; ok {
+		c.counts.add(prev, -1)
+	}
+	if state == nil {
+		delete(c.states, key)
+	} else {
+		c.states[key] = state
+		c.counts.add(state, 1)
+	}
+	c.counts.publish()
 
-	return ctrl.Result{}, nil
+	if c.fleet != nil {
+		summary := c.counts.summarize()
+		c.fleet.update(func(fs *FleetStatus) { fs.Compositions = summary })
+	}
 }
 
 func (c *watchdogController) pendingInitialReconciliation(comp *apiv1.Composition) bool {
@@ -90,5 +211,65 @@ func (c *watchdogController) timeSinceReconcilePastThreshold(comp *apiv1.Composi
 	return comp.Status.CurrentSynthesis != nil && comp.Status.CurrentSynthesis.Reconciled != nil && time.Since(comp.Status.CurrentSynthesis.Reconciled.Time) > c.threshold
 }
 
+// pendingRemovals returns the number of resources removed from the composition's current synthesis
+// (tombstones) that have not yet been deleted.
+func pendingRemovals(comp *apiv1.Composition, slices map[types.NamespacedName]*apiv1.ResourceSlice) int {
+	if comp.Status.CurrentSynthesis == nil || comp.ShouldOrphan() {
+		return 0
+	}
+
+	var n int
+	for _, ref := range comp.Status.CurrentSynthesis.ResourceSlices {
+		slice, ok := slices[types.NamespacedName{Name: ref.Name, Namespace: comp.Namespace}]
+		if !ok {
+			continue
+		}
+		for i, res := range slice.Spec.Resources {
+			if !res.Deleted {
+				continue
+			}
+			if len(slice.Status.Resources) <= i || !slice.Status.Resources[i].Deleted {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+var deletionAgeBuckets = []struct {
+	Label string
+	Max   time.Duration
+}{
+	{Label: "5m", Max: time.Minute * 5},
+	{Label: "1h", Max: time.Hour},
+	{Label: "24h", Max: time.Hour * 24},
+	{Label: "+Inf"},
+}
+
+// deletionAgeBucket returns the label of the smallest bucket that contains the time since the composition was deleted,
+// or an empty string if the composition has not been deleted.
+func deletionAgeBucket(comp *apiv1.Composition, now time.Time) string {
+	if comp.DeletionTimestamp == nil {
+		return ""
+	}
+	age := now.Sub(comp.DeletionTimestamp.Time)
+	for _, bucket := range deletionAgeBuckets {
+		if bucket.Max == 0 || age <= bucket.Max {
+			return bucket.Label
+		}
+	}
+	return ""
+}
+
+var healthStates = []apiv1.CompositionHealth{apiv1.HealthHealthy, apiv1.HealthProgressing, apiv1.HealthDegraded, apiv1.HealthUnknown}
+
+// compositionHealthState returns the health of the composition as last reported by the aggregation controller.
+func compositionHealthState(comp *apiv1.Composition) apiv1.CompositionHealth {
+	if comp.Status.Health == "" {
+		return apiv1.HealthUnknown
+	}
+	return comp.Status.Health
+}
+
 func synthesisHasReconciled(syn *apiv1.Synthesis) bool { return syn != nil && syn.Reconciled != nil }
 func synthesisIsReady(syn *apiv1.Synthesis) bool       { return syn != nil && syn.Ready != nil }
@@ -4,9 +4,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Azure/eno/internal/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1 "github.com/Azure/eno/api/v1"
 )
@@ -142,3 +147,112 @@ func TestControllerLogic(t *testing.T) {
 		})
 	}
 }
+
+func TestPendingRemovals(t *testing.T) {
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = "default"
+	slice.Spec.Resources = []apiv1.Manifest{{}, {Deleted: true}, {Deleted: true}, {Deleted: true}}
+	slice.Status.Resources = []apiv1.ResourceState{{}, {Deleted: true}, {Reconciled: true}}
+	slices := map[types.NamespacedName]*apiv1.ResourceSlice{{Name: slice.Name, Namespace: slice.Namespace}: slice}
+
+	comp := &apiv1.Composition{}
+	comp.Namespace = "default"
+	assert.Equal(t, 0, pendingRemovals(comp, slices))
+
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{ResourceSlices: []*apiv1.ResourceSliceRef{{Name: slice.Name}, {Name: "missing"}}}
+	assert.Equal(t, 2, pendingRemovals(comp, slices))
+
+	comp.Annotations = map[string]string{"eno.azure.io/deletion-strategy": "orphan"}
+	assert.Equal(t, 0, pendingRemovals(comp, slices))
+}
+
+func TestDeletionAgeBucket(t *testing.T) {
+	now := time.Now()
+	comp := &apiv1.Composition{}
+	assert.Equal(t, "", deletionAgeBucket(comp, now))
+
+	comp.DeletionTimestamp = &metav1.Time{Time: now.Add(-time.Minute)}
+	assert.Equal(t, "5m", deletionAgeBucket(comp, now))
+
+	comp.DeletionTimestamp = &metav1.Time{Time: now.Add(-time.Minute * 30)}
+	assert.Equal(t, "1h", deletionAgeBucket(comp, now))
+
+	comp.DeletionTimestamp = &metav1.Time{Time: now.Add(-time.Hour * 2)}
+	assert.Equal(t, "24h", deletionAgeBucket(comp, now))
+
+	comp.DeletionTimestamp = &metav1.Time{Time: now.Add(-time.Hour * 48)}
+	assert.Equal(t, "+Inf", deletionAgeBucket(comp, now))
+}
+
+func TestIncrementalEvaluation(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+	fleet := &Fleet{}
+	c := newController(cli, time.Minute, fleet)
+
+	healthy := &apiv1.Composition{}
+	healthy.Name = "healthy"
+	healthy.Namespace = "default"
+	require.NoError(t, cli.Create(ctx, healthy))
+	healthy.Status.Health = apiv1.HealthHealthy
+	healthy.Status.CurrentSynthesis = &apiv1.Synthesis{Reconciled: &metav1.Time{}, Ready: &metav1.Time{}}
+	require.NoError(t, cli.Status().Update(ctx, healthy))
+
+	failed := &apiv1.Composition{}
+	failed.Name = "failed"
+	failed.Namespace = "default"
+	require.NoError(t, cli.Create(ctx, failed))
+	failed.Status.Health = apiv1.HealthDegraded
+	failed.Status.CurrentSynthesis = &apiv1.Synthesis{Results: []apiv1.Result{{Severity: "error"}}}
+	require.NoError(t, cli.Status().Update(ctx, failed))
+
+	for _, comp := range []*apiv1.Composition{healthy, failed} {
+		_, err := c.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(comp)})
+		require.NoError(t, err)
+	}
+
+	summary := fleet.Get().Compositions
+	assert.Equal(t, 2, summary.Total)
+	assert.Equal(t, 1, summary.TerminalErrors)
+	assert.Equal(t, 1, summary.ByHealth[apiv1.HealthHealthy])
+	assert.Equal(t, 1, summary.ByHealth[apiv1.HealthDegraded])
+
+	// Re-evaluating a composition replaces its previous contribution
+	_, err := c.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(failed)})
+	require.NoError(t, err)
+	assert.Equal(t, summary, fleet.Get().Compositions)
+
+	// Deleted compositions are removed
+	require.NoError(t, cli.Delete(ctx, failed))
+	_, err = c.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(failed)})
+	require.NoError(t, err)
+
+	summary = fleet.Get().Compositions
+	assert.Equal(t, 1, summary.Total)
+	assert.Equal(t, 0, summary.TerminalErrors)
+	assert.Equal(t, 0, summary.ByHealth[apiv1.HealthDegraded])
+}
+
+func TestNextTransition(t *testing.T) {
+	now := time.Now()
+	c := &watchdogController{threshold: time.Minute}
+
+	// Ready compositions don't transition
+	comp := &apiv1.Composition{}
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{Reconciled: &metav1.Time{Time: now}, Ready: &metav1.Time{Time: now}}
+	assert.Zero(t, c.nextTransition(comp, c.evaluate(comp, nil, now), now))
+
+	// Reconciled compositions are requeued when they cross the readiness threshold
+	comp.Status.CurrentSynthesis.Ready = nil
+	comp.Status.CurrentSynthesis.Reconciled = &metav1.Time{Time: now.Add(-time.Second * 30)}
+	assert.Equal(t, time.Second*31, c.nextTransition(comp, c.evaluate(comp, nil, now), now))
+
+	// ...but not after crossing it
+	comp.Status.CurrentSynthesis.Reconciled = &metav1.Time{Time: now.Add(-time.Minute * 2)}
+	assert.Zero(t, c.nextTransition(comp, c.evaluate(comp, nil, now), now))
+
+	// Deleting compositions are requeued when they move to the next age bucket
+	comp.DeletionTimestamp = &metav1.Time{Time: now.Add(-time.Minute * 4)}
+	assert.Equal(t, time.Minute+time.Second, c.nextTransition(comp, c.evaluate(comp, nil, now), now))
+}
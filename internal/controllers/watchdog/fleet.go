@@ -0,0 +1,90 @@
+package watchdog
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// FleetStatus summarizes the state of every composition and synthesizer in the cluster.
+type FleetStatus struct {
+	Updated time.Time `json:"updated"`
+
+	Compositions FleetCompositions `json:"compositions"`
+	Synthesizers FleetSynthesizers `json:"synthesizers"`
+}
+
+type FleetCompositions struct {
+	Total    int                             `json:"total"`
+	ByHealth map[apiv1.CompositionHealth]int `json:"byHealth"`
+
+	PendingInitialReconciliation int `json:"pendingInitialReconciliation"`
+	StuckReconciling             int `json:"stuckReconciling"`
+	NotReady                     int `json:"notReady"`
+	TerminalErrors               int `json:"terminalErrors"`
+	Deleting                     int `json:"deleting"`
+}
+
+type FleetSynthesizers struct {
+	Total           int `json:"total"`
+	RollingOut      int `json:"rollingOut"`
+	FailedRollout   int `json:"failedRollout"`
+	RolloutFinished int `json:"rolloutFinished"`
+}
+
+// Fleet holds the latest FleetStatus computed by the watchdog and serves it as JSON,
+// such that external systems can poll a single endpoint instead of listing every resource.
+type Fleet struct {
+	lock   sync.RWMutex
+	status *FleetStatus
+}
+
+// update applies fn to a copy of the latest status, since compositions and synthesizers are summarized independently.
+func (f *Fleet) update(fn func(*FleetStatus)) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	next := &FleetStatus{}
+	if f.status != nil {
+		*next = *f.status
+	}
+	fn(next)
+	next.Updated = time.Now()
+	f.status = next
+}
+
+// Get returns the latest fleet status, or nil if the watchdog hasn't run yet.
+func (f *Fleet) Get() *FleetStatus {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.status
+}
+
+func (f *Fleet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := f.Get()
+	if status == nil {
+		http.Error(w, "fleet status has not been computed yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+func summarizeSynthesizers(synths []apiv1.Synthesizer) FleetSynthesizers {
+	summary := FleetSynthesizers{Total: len(synths)}
+	for _, synth := range synths {
+		switch {
+		case meta.IsStatusConditionTrue(synth.Status.Conditions, apiv1.FailedRolloutCondition):
+			summary.FailedRollout++
+		case synth.Status.RolloutFinished != nil:
+			summary.RolloutFinished++
+		case synth.Status.RolloutStarted != nil:
+			summary.RollingOut++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,49 @@
+package watchdog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+func TestSummarizeSynthesizers(t *testing.T) {
+	synths := []apiv1.Synthesizer{
+		{},
+		{Status: apiv1.SynthesizerStatus{RolloutStarted: &metav1.Time{}}},
+		{Status: apiv1.SynthesizerStatus{RolloutStarted: &metav1.Time{}, RolloutFinished: &metav1.Time{}}},
+		{Status: apiv1.SynthesizerStatus{
+			RolloutStarted: &metav1.Time{},
+			Conditions:     []metav1.Condition{{Type: apiv1.FailedRolloutCondition, Status: metav1.ConditionTrue}},
+		}},
+	}
+	assert.Equal(t, FleetSynthesizers{Total: 4, RollingOut: 1, FailedRollout: 1, RolloutFinished: 1}, summarizeSynthesizers(synths))
+}
+
+func TestFleetServeHTTP(t *testing.T) {
+	f := &Fleet{}
+
+	w := httptest.NewRecorder()
+	f.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fleet", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	f.update(func(fs *FleetStatus) {
+		fs.Compositions = FleetCompositions{Total: 2, ByHealth: map[apiv1.CompositionHealth]int{apiv1.HealthHealthy: 2}}
+	})
+	f.update(func(fs *FleetStatus) { fs.Synthesizers = FleetSynthesizers{Total: 1} })
+	w = httptest.NewRecorder()
+	f.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fleet", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	status := &FleetStatus{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), status))
+	assert.Equal(t, 2, status.Compositions.Total)
+	assert.Equal(t, 2, status.Compositions.ByHealth[apiv1.HealthHealthy])
+	assert.Equal(t, 1, status.Synthesizers.Total)
+}
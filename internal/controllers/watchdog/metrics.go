@@ -2,7 +2,8 @@ package watchdog
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
-	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/Azure/eno/internal/telemetry"
 )
 
 var (
@@ -33,8 +34,29 @@ var (
 			Help: "Number of compositions that terminally failed synthesis and will not be retried",
 		},
 	)
+
+	pendingResourceRemovals = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "eno_resources_pending_removal_total",
+			Help: "Number of resources that have been removed from their composition's synthesis but not yet deleted",
+		},
+	)
+
+	deletingCompositions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eno_compositions_deleting_total",
+			Help: "Number of compositions that are being deleted, bucketed by time since deletion",
+		}, []string{"age"},
+	)
+
+	compositionHealth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eno_compositions_health_total",
+			Help: "Number of compositions in each health state",
+		}, []string{"health"},
+	)
 )
 
 func init() {
-	metrics.Registry.MustRegister(pendingInitialReconciliation, stuckReconciling, pendingReadiness, terminalErrors)
+	telemetry.MustRegister(pendingInitialReconciliation, stuckReconciling, pendingReadiness, terminalErrors, pendingResourceRemovals, deletingCompositions, compositionHealth)
 }
@@ -0,0 +1,96 @@
+package watchdog
+
+import (
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+// compositionState is the watchdog's evaluation of a single composition.
+type compositionState struct {
+	PendingInitialReconciliation bool
+	StuckReconciling             bool
+	NotReady                     bool
+	TerminalError                bool
+	PendingRemovals              int
+	DeletionAge                  string // empty when not deleting
+	Health                       apiv1.CompositionHealth
+}
+
+// stateCounts are the running totals of every composition's state.
+type stateCounts struct {
+	Total                        int
+	PendingInitialReconciliation int
+	StuckReconciling             int
+	NotReady                     int
+	TerminalErrors               int
+	PendingRemovals              int
+	Deleting                     map[string]int
+	Health                       map[apiv1.CompositionHealth]int
+}
+
+func newStateCounts() *stateCounts {
+	s := &stateCounts{Deleting: map[string]int{}, Health: map[apiv1.CompositionHealth]int{}}
+	for _, bucket := range deletionAgeBuckets {
+		s.Deleting[bucket.Label] = 0
+	}
+	for _, state := range healthStates {
+		s.Health[state] = 0
+	}
+	return s
+}
+
+// add adds (sign=1) or removes (sign=-1) the state's contribution to the totals.
+func (s *stateCounts) add(state *compositionState, sign int) {
+	s.Total += sign
+	if state.PendingInitialReconciliation {
+		s.PendingInitialReconciliation += sign
+	}
+	if state.StuckReconciling {
+		s.StuckReconciling += sign
+	}
+	if state.NotReady {
+		s.NotReady += sign
+	}
+	if state.TerminalError {
+		s.TerminalErrors += sign
+	}
+	s.PendingRemovals += state.PendingRemovals * sign
+	if state.DeletionAge != "" {
+		s.Deleting[state.DeletionAge] += sign
+	}
+	s.Health[state.Health] += sign
+}
+
+func (s *stateCounts) publish() {
+	pendingInitialReconciliation.Set(float64(s.PendingInitialReconciliation))
+	stuckReconciling.Set(float64(s.StuckReconciling))
+	pendingReadiness.Set(float64(s.NotReady))
+	terminalErrors.Set(float64(s.TerminalErrors))
+	pendingResourceRemovals.Set(float64(s.PendingRemovals))
+	for bucket, n := range s.Deleting {
+		deletingCompositions.WithLabelValues(bucket).Set(float64(n))
+	}
+	for state, n := range s.Health {
+		compositionHealth.WithLabelValues(string(state)).Set(float64(n))
+	}
+}
+
+// summarize returns the fleet status of compositions. The returned value doesn't share memory with the counts.
+func (s *stateCounts) summarize() FleetCompositions {
+	health := make(map[apiv1.CompositionHealth]int, len(s.Health))
+	for state, n := range s.Health {
+		health[state] = n
+	}
+	var deleting int
+	for _, n := range s.Deleting {
+		deleting += n
+	}
+	return FleetCompositions{
+		Total:                        s.Total,
+		ByHealth:                     health,
+		PendingInitialReconciliation: s.PendingInitialReconciliation,
+		StuckReconciling:             s.StuckReconciling,
+		NotReady:                     s.NotReady,
+		TerminalErrors:               s.TerminalErrors,
+		Deleting:                     deleting,
+	}
+}
@@ -0,0 +1,203 @@
+// Package diff compares the resources of two syntheses of a composition,
+// so reviewers can see what a particular synthesis (e.g. a synthesizer rollout) actually changed.
+package diff
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "Added"
+	ChangeRemoved  ChangeType = "Removed"
+	ChangeModified ChangeType = "Modified"
+)
+
+// Ref identifies a resource within a synthesis.
+type Ref struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+func (r Ref) String() string {
+	kind := r.Kind
+	if r.Group != "" {
+		kind += "." + r.Group
+	}
+	if r.Namespace == "" {
+		return kind + "/" + r.Name
+	}
+	return kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// Change describes how a resource differs between two syntheses.
+type Change struct {
+	Ref
+	Type ChangeType `json:"type"`
+
+	// Diff is a unified diff of the resource's manifests (as YAML).
+	Diff string `json:"diff"`
+}
+
+// Synthesis maps the resources of a synthesis to their manifests (as YAML).
+type Synthesis map[Ref]string
+
+// Load returns the resources of the composition's synthesis with the given UUID.
+// Syntheses other than the current and previous syntheses can only be loaded until their resource slices are cleaned up.
+func Load(ctx context.Context, reader client.Reader, comp *apiv1.Composition, uuid string) (Synthesis, error) {
+	slices, err := findSlices(ctx, reader, comp, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	syn := Synthesis{}
+	for _, slice := range slices {
+		for i, res := range slice.Spec.Resources {
+			if res.Deleted {
+				continue // tombstones aren't part of the synthesis
+			}
+			if err := syn.add([]byte(res.Manifest)); err != nil {
+				return nil, fmt.Errorf("resource %d of slice %s: %w", i, slice.Name, err)
+			}
+		}
+	}
+	return syn, nil
+}
+
+// FromObjects returns a synthesis of the given resources e.g. synthesizer output that hasn't been written to resource slices.
+func FromObjects(objs []*unstructured.Unstructured) (Synthesis, error) {
+	syn := Synthesis{}
+	for i, obj := range objs {
+		js, err := obj.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("encoding resource %d: %w", i, err)
+		}
+		if err := syn.add(js); err != nil {
+			return nil, fmt.Errorf("resource %d: %w", i, err)
+		}
+	}
+	return syn, nil
+}
+
+func (s Synthesis) add(js []byte) error {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(js); err != nil {
+		return fmt.Errorf("decoding: %w", err)
+	}
+	manifest, err := yaml.JSONToYAML(js)
+	if err != nil {
+		return fmt.Errorf("converting to yaml: %w", err)
+	}
+	gvk := obj.GroupVersionKind()
+	s[Ref{Group: gvk.Group, Kind: gvk.Kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}] = string(manifest)
+	return nil
+}
+
+func findSlices(ctx context.Context, reader client.Reader, comp *apiv1.Composition, uuid string) ([]*apiv1.ResourceSlice, error) {
+	// The status references the exact slices of the current and previous syntheses
+	for _, synthesis := range []*apiv1.Synthesis{comp.Status.CurrentSynthesis, comp.Status.PreviousSynthesis} {
+		if synthesis == nil || synthesis.UUID != uuid || synthesis.Synthesized == nil {
+			continue
+		}
+		slices := make([]*apiv1.ResourceSlice, len(synthesis.ResourceSlices))
+		for i, ref := range synthesis.ResourceSlices {
+			slice := &apiv1.ResourceSlice{}
+			err := reader.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: comp.Namespace}, slice)
+			if err != nil {
+				return nil, fmt.Errorf("getting resource slice %q: %w", ref.Name, err)
+			}
+			slices[i] = slice
+		}
+		return slices, nil
+	}
+
+	// Older syntheses may have been retried, so use the slices of the latest attempt
+	list := &apiv1.ResourceSliceList{}
+	err := reader.List(ctx, list, client.InNamespace(comp.Namespace))
+	if err != nil {
+		return nil, fmt.Errorf("listing resource slices: %w", err)
+	}
+	var slices []*apiv1.ResourceSlice
+	attempt := -1
+	for i := range list.Items {
+		slice := &list.Items[i]
+		if slice.Spec.SynthesisUUID != uuid || !ownedBy(slice, comp) || slice.Spec.Attempt < attempt {
+			continue
+		}
+		if slice.Spec.Attempt > attempt {
+			slices = nil
+			attempt = slice.Spec.Attempt
+		}
+		slices = append(slices, slice)
+	}
+	if len(slices) == 0 {
+		return nil, fmt.Errorf("resource slices of synthesis %q were not found - they may have been cleaned up", uuid)
+	}
+	return slices, nil
+}
+
+func ownedBy(slice *apiv1.ResourceSlice, comp *apiv1.Composition) bool {
+	for _, ref := range slice.OwnerReferences {
+		if ref.Kind == "Composition" && ref.Name == comp.Name && (comp.UID == "" || ref.UID == comp.UID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare returns the changes between two syntheses, sorted by resource.
+// Unchanged resources are not included.
+func Compare(from, to Synthesis) []*Change {
+	var changes []*Change
+	for ref, next := range to {
+		prev, ok := from[ref]
+		switch {
+		case !ok:
+			changes = append(changes, &Change{Ref: ref, Type: ChangeAdded, Diff: unifiedDiff(ref, "", next)})
+		case prev != next:
+			changes = append(changes, &Change{Ref: ref, Type: ChangeModified, Diff: unifiedDiff(ref, prev, next)})
+		}
+	}
+	for ref, prev := range from {
+		if _, ok := to[ref]; !ok {
+			changes = append(changes, &Change{Ref: ref, Type: ChangeRemoved, Diff: unifiedDiff(ref, prev, "")})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Ref.String() < changes[j].Ref.String() })
+	return changes
+}
+
+func unifiedDiff(ref Ref, a, b string) string {
+	str, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: "a/" + ref.String(),
+		ToFile:   "b/" + ref.String(),
+		Context:  3,
+	})
+	return str
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil // difflib would return a single empty line
+	}
+	lines := difflib.SplitLines(s)
+	if strings.HasSuffix(s, "\n") {
+		lines = lines[:len(lines)-1] // difflib adds an empty line after the trailing newline
+	}
+	return lines
+}
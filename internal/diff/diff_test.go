@@ -0,0 +1,111 @@
+package diff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+func TestCompare(t *testing.T) {
+	cm := Ref{Kind: "ConfigMap", Namespace: "default", Name: "foo"}
+	deploy := Ref{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "foo"}
+	role := Ref{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "foo"}
+
+	changes := Compare(
+		Synthesis{cm: "data:\n  foo: bar\n", deploy: "replicas: 1\n", role: "rules: []\n"},
+		Synthesis{cm: "data:\n  foo: baz\n", deploy: "replicas: 1\n", Ref{Kind: "Secret", Namespace: "default", Name: "foo"}: "type: Opaque\n"},
+	)
+	require.Len(t, changes, 3)
+
+	assert.Equal(t, ChangeRemoved, changes[0].Type)
+	assert.Equal(t, "ClusterRole.rbac.authorization.k8s.io/foo", changes[0].String())
+	assert.Contains(t, changes[0].Diff, "-rules: []\n")
+
+	assert.Equal(t, ChangeModified, changes[1].Type)
+	assert.Equal(t, "ConfigMap/default/foo", changes[1].String())
+	assert.Equal(t, "--- a/ConfigMap/default/foo\n+++ b/ConfigMap/default/foo\n@@ -1,2 +1,2 @@\n data:\n-  foo: bar\n+  foo: baz\n", changes[1].Diff)
+
+	assert.Equal(t, ChangeAdded, changes[2].Type)
+	assert.Equal(t, "Secret/default/foo", changes[2].String())
+	assert.Equal(t, "--- a/Secret/default/foo\n+++ b/Secret/default/foo\n@@ -0,0 +1 @@\n+type: Opaque\n", changes[2].Diff)
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.SchemeBuilder.AddToScheme(scheme))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.UID = "test-uid"
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "current", Synthesized: ptrNow(), ResourceSlices: []*apiv1.ResourceSliceRef{{Name: "current-slice"}}}
+
+	newSlice := func(name, uuid string, attempt int, cmName string, deleted bool) *apiv1.ResourceSlice {
+		slice := &apiv1.ResourceSlice{}
+		slice.Name = name
+		slice.Namespace = comp.Namespace
+		slice.OwnerReferences = []metav1.OwnerReference{{Kind: "Composition", Name: comp.Name, UID: comp.UID}}
+		slice.Spec.SynthesisUUID = uuid
+		slice.Spec.Attempt = attempt
+		slice.Spec.Resources = []apiv1.Manifest{{
+			Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"` + cmName + `","namespace":"default"}}`,
+			Deleted:  deleted,
+		}}
+		return slice
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newSlice("current-slice", "current", 1, "current", false),
+		newSlice("retried-slice", "current", 2, "retried", false), // not referenced by the status
+		newSlice("old-slice-1", "old", 1, "first-attempt", false),
+		newSlice("old-slice-2", "old", 2, "second-attempt", false),
+		newSlice("old-slice-3", "old", 2, "tombstone", true),
+	).Build()
+
+	syn, err := Load(ctx, cli, comp, "current")
+	require.NoError(t, err)
+	assert.Equal(t, []Ref{{Kind: "ConfigMap", Namespace: "default", Name: "current"}}, refs(syn))
+	assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: current\n  namespace: default\n", syn[Ref{Kind: "ConfigMap", Namespace: "default", Name: "current"}])
+
+	syn, err = Load(ctx, cli, comp, "old")
+	require.NoError(t, err)
+	assert.Equal(t, []Ref{{Kind: "ConfigMap", Namespace: "default", Name: "second-attempt"}}, refs(syn))
+
+	_, err = Load(ctx, cli, comp, "missing")
+	assert.Error(t, err)
+}
+
+func TestFromObjects(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": "current", "namespace": "default"},
+	}}
+
+	syn, err := FromObjects([]*unstructured.Unstructured{obj})
+	require.NoError(t, err)
+	assert.Equal(t, Synthesis{
+		{Kind: "ConfigMap", Namespace: "default", Name: "current"}: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: current\n  namespace: default\n",
+	}, syn)
+}
+
+func refs(syn Synthesis) []Ref {
+	var refs []Ref
+	for ref := range syn {
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func ptrNow() *metav1.Time {
+	now := metav1.Now()
+	return &now
+}
@@ -3,6 +3,7 @@ package discovery
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -10,8 +11,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/kube-openapi/pkg/util/proto"
+
+	"github.com/Azure/eno/pkg/faults"
 )
 
 // Cache is useful to prevent excessive QPS to the discovery APIs while
@@ -22,13 +24,24 @@ type Cache struct {
 	fillWhenNotFound bool
 	lastFill         time.Time
 	current          map[schema.GroupVersionKind]proto.Schema
+
+	capabilities
+
+	// Faults are injected before every lookup. Only set by tests.
+	Faults faults.Injector
 }
 
-func NewCache(rc *rest.Config, qps float32) (*Cache, error) {
+// NewCache returns a discovery cache for the given apiserver.
+// Requests are rate limited by an AdaptiveRateLimiter, since parsing the spec is expensive for both sides.
+func NewCache(rc *rest.Config) (*Cache, error) {
+	limiter := NewAdaptiveRateLimiter()
 	conf := rest.CopyConfig(rc)
-	conf.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(qps, 1) // parsing the spec is expensive, rate limit to be careful
+	conf.RateLimiter = limiter
+	conf.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &observingTransport{next: rt, limiter: limiter}
+	})
 
-	disc, err := discovery.NewDiscoveryClientForConfig(rc)
+	disc, err := discovery.NewDiscoveryClientForConfig(conf)
 	if err != nil {
 		return nil, err
 	}
@@ -40,6 +53,9 @@ func NewCache(rc *rest.Config, qps float32) (*Cache, error) {
 
 func (c *Cache) Get(ctx context.Context, gvk schema.GroupVersionKind) (proto.Schema, error) {
 	logger := logr.FromContextOrDiscard(ctx)
+	if err := faults.Inject(ctx, c.Faults, faults.Discovery); err != nil {
+		return nil, err
+	}
 	c.mut.Lock()
 	defer c.mut.Unlock()
 
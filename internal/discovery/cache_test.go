@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery/fake"
+	"k8s.io/kube-openapi/pkg/util/proto"
 )
 
 func TestDiscoveryCacheRefill(t *testing.T) {
@@ -105,7 +106,7 @@ func TestDiscoveryCacheTimeout(t *testing.T) {
 func TestWithRealApiserver(t *testing.T) {
 	ctx := testutil.NewContext(t)
 	mgr := testutil.NewManager(t)
-	cache, err := NewCache(mgr.DownstreamRestConfig, 10)
+	cache, err := NewCache(mgr.DownstreamRestConfig)
 	require.NoError(t, err)
 
 	gvk := schema.GroupVersionKind{
@@ -128,3 +129,13 @@ func (f *fakeDiscovery) OpenAPISchema() (*openapi_v2.Document, error) {
 	f.Calls++
 	return &openapi_v2.Document{Info: f.Info}, nil
 }
+
+func TestUsableModel(t *testing.T) {
+	meta := map[string]proto.Schema{"apiVersion": &proto.Primitive{}, "kind": &proto.Primitive{}, "metadata": &proto.Kind{}}
+	assert.False(t, usableModel(&proto.Kind{Fields: meta}))
+	assert.False(t, usableModel(&proto.Arbitrary{}))
+	assert.False(t, usableModel(&proto.Kind{}))
+
+	meta["spec"] = &proto.Kind{}
+	assert.True(t, usableModel(&proto.Kind{Fields: meta}))
+}
@@ -0,0 +1,116 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	// capabilitiesTTL is how long the apiserver's version and API groups are cached.
+	capabilitiesTTL = time.Minute * 10
+
+	// capabilitiesRefillInterval limits how often the cache is refilled because a capability was missing.
+	capabilitiesRefillInterval = time.Minute
+)
+
+// capabilities caches the downstream apiserver's version and the kinds it serves.
+type capabilities struct {
+	capsMut    sync.Mutex
+	capsFilled time.Time
+	version    *version.Version
+	kinds      map[schema.GroupVersion]map[string]struct{}
+}
+
+// ServerVersion returns the version of the apiserver.
+func (c *Cache) ServerVersion(ctx context.Context) (*version.Version, error) {
+	c.capsMut.Lock()
+	defer c.capsMut.Unlock()
+
+	if err := c.fillCapabilitiesUnlocked(ctx, false); err != nil {
+		return nil, err
+	}
+	return c.version, nil
+}
+
+// Serves returns true if the apiserver serves the given group version and kind.
+// An empty kind matches any kind in the group version.
+func (c *Cache) Serves(ctx context.Context, gvk schema.GroupVersionKind) (bool, error) {
+	c.capsMut.Lock()
+	defer c.capsMut.Unlock()
+
+	for i := 0; i < 2; i++ {
+		if err := c.fillCapabilitiesUnlocked(ctx, i > 0); err != nil {
+			return false, err
+		}
+		kinds, ok := c.kinds[gvk.GroupVersion()]
+		if ok && gvk.Kind == "" {
+			return true, nil
+		}
+		if _, ok := kinds[gvk.Kind]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fillCapabilitiesUnlocked refreshes the cache when it has expired, or when forced (at most once per capabilitiesRefillInterval).
+func (c *Cache) fillCapabilitiesUnlocked(ctx context.Context, force bool) error {
+	age := time.Since(c.capsFilled)
+	if c.kinds != nil && age < capabilitiesTTL && (!force || age < capabilitiesRefillInterval) {
+		return nil
+	}
+	logger := logr.FromContextOrDiscard(ctx)
+
+	info, err := c.client.ServerVersion()
+	if err != nil {
+		return fmt.Errorf("getting server version: %w", err)
+	}
+	v, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return fmt.Errorf("parsing server version: %w", err)
+	}
+
+	// Groups that couldn't be discovered (e.g. unavailable aggregated apiservers) are treated as missing
+	_, lists, err := c.client.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return fmt.Errorf("discovering api groups: %w", err)
+	}
+	if err != nil {
+		logger.V(0).Info("some api groups couldn't be discovered", "error", err.Error())
+	}
+
+	kinds := map[schema.GroupVersion]map[string]struct{}{}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		if kinds[gv] == nil {
+			kinds[gv] = map[string]struct{}{}
+		}
+		for _, res := range list.APIResources {
+			kinds[gv][res.Kind] = struct{}{}
+		}
+	}
+
+	if c.version == nil || c.version.String() != v.String() {
+		if c.version != nil {
+			downstreamVersion.DeleteLabelValues(c.version.String())
+		}
+		downstreamVersion.WithLabelValues(v.String()).Set(1)
+		logger.V(0).Info("discovered downstream apiserver version", "version", v.String())
+	}
+	downstreamGroupVersions.Set(float64(len(kinds)))
+
+	c.version = v
+	c.kinds = kinds
+	c.capsFilled = time.Now()
+	return nil
+}
@@ -0,0 +1,67 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/Azure/eno/internal/testutil"
+)
+
+func TestCapabilities(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	client := &fakeDiscovery{FakeDiscovery: fake.FakeDiscovery{
+		Fake:               &clienttesting.Fake{},
+		FakedServerVersion: &version.Info{GitVersion: "v1.29.4+k3s1"},
+	}}
+	client.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap"}}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment"}}},
+	}
+	d := &Cache{client: client}
+
+	v, err := d.ServerVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint(29), v.Minor())
+
+	ok, err := d.Serves(ctx, schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = d.Serves(ctx, schema.GroupVersionKind{Group: "apps", Version: "v1"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Missing capabilities are rediscovered at most once per interval
+	ok, err = d.Serves(ctx, schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	client.Resources = append(client.Resources, &metav1.APIResourceList{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "cronjobs", Kind: "CronJob"}}})
+	ok, err = d.Serves(ctx, schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	d.capsFilled = d.capsFilled.Add(-capabilitiesRefillInterval)
+	ok, err = d.Serves(ctx, schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Expiration
+	client.FakedServerVersion = &version.Info{GitVersion: "v1.30.0"}
+	v, err = d.ServerVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint(29), v.Minor())
+
+	d.capsFilled = time.Now().Add(-capabilitiesTTL)
+	v, err = d.ServerVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint(30), v.Minor())
+}
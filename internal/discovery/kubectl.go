@@ -51,7 +51,7 @@ func buildCurrentSchemaMap(doc *openapi_v2.Document) (map[schema.GroupVersionKin
 		gvkList := parseGroupVersionKind(model)
 		for _, gvk := range gvkList {
 			if len(gvk.Kind) > 0 {
-				if _, ok := allSupported[gvk]; ok {
+				if _, ok := allSupported[gvk]; ok && usableModel(model) {
 					m[gvk] = model
 				} else {
 					m[gvk] = nil // unsupported == map key with nil model
@@ -63,6 +63,21 @@ func buildCurrentSchemaMap(doc *openapi_v2.Document) (map[schema.GroupVersionKin
 	return m, nil
 }
 
+// usableModel returns false for models that don't describe any fields other than type/object metadata.
+// Some aggregated apiservers publish models like this, which would cause strategic merge to replace lists wholesale.
+func usableModel(s proto.Schema) bool {
+	kind, ok := s.(*proto.Kind)
+	if !ok {
+		return false
+	}
+	for name := range kind.Fields {
+		if name != "apiVersion" && name != "kind" && name != "metadata" {
+			return true
+		}
+	}
+	return false
+}
+
 func parseGroupVersionKind(s proto.Schema) []schema.GroupVersionKind {
 	extensions := s.GetExtensions()
 	gvkListResult := []schema.GroupVersionKind{}
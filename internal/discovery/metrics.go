@@ -2,7 +2,8 @@ package discovery
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
-	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/Azure/eno/internal/telemetry"
 )
 
 var (
@@ -12,8 +13,37 @@ var (
 			Help: "Discovery cache misses excluding fill events (filling cache on startup, etc.)",
 		},
 	)
+
+	discoveryRequestLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "eno_discovery_request_duration_seconds",
+			Help:    "Samples latency of discovery requests to the downstream apiserver, partitioned by status code (or error when there was no response)",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0},
+		}, []string{"code"},
+	)
+
+	discoveryRateLimit = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "eno_discovery_rate_limit",
+			Help: "Current limit of discovery requests per second, adjusted automatically based on the downstream apiserver's latency and throttling",
+		},
+	)
+
+	downstreamVersion = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eno_downstream_version_info",
+			Help: "Always 1, labeled with the Kubernetes version of the downstream apiserver",
+		}, []string{"version"},
+	)
+
+	downstreamGroupVersions = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "eno_downstream_api_group_versions",
+			Help: "Number of API group versions served by the downstream apiserver",
+		},
+	)
 )
 
 func init() {
-	metrics.Registry.MustRegister(discoveryCacheChanges)
+	telemetry.MustRegister(discoveryCacheChanges, discoveryRequestLatency, discoveryRateLimit, downstreamVersion, downstreamGroupVersions)
 }
@@ -0,0 +1,95 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// Discovery requests are limited to between minRPS and maxRPS, starting at minRPS.
+	minRPS = 1
+	maxRPS = 20
+
+	// slowRequestThreshold is the latency above which the apiserver is assumed to be under pressure.
+	// Generous because fetching the openapi spec of clusters with many CRDs can take a while.
+	slowRequestThreshold = time.Second * 5
+)
+
+// AdaptiveRateLimiter limits the rate of discovery requests to a downstream apiserver without a static limit.
+// The limit is halved when requests are throttled, fail server-side, or are slow, and increased by minRPS otherwise.
+//
+// Implements flowcontrol.RateLimiter.
+type AdaptiveRateLimiter struct {
+	mut     sync.Mutex
+	limiter *rate.Limiter
+	current float64
+}
+
+func NewAdaptiveRateLimiter() *AdaptiveRateLimiter {
+	discoveryRateLimit.Set(minRPS)
+	return &AdaptiveRateLimiter{limiter: rate.NewLimiter(minRPS, 1), current: minRPS}
+}
+
+// Observe adjusts the limit given the latency and status code of a completed request.
+// Zero status code means the request failed without a response.
+func (a *AdaptiveRateLimiter) Observe(latency time.Duration, code int) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	next := a.current + minRPS
+	if code == 0 || code == http.StatusTooManyRequests || code >= 500 || latency > slowRequestThreshold {
+		next = a.current / 2
+	}
+	next = max(minRPS, min(maxRPS, next))
+	if next == a.current {
+		return
+	}
+
+	a.current = next
+	a.limiter.SetLimit(rate.Limit(next))
+	discoveryRateLimit.Set(next)
+}
+
+func (a *AdaptiveRateLimiter) TryAccept() bool { return a.limiter.Allow() }
+
+func (a *AdaptiveRateLimiter) Accept() { _ = a.limiter.Wait(context.Background()) }
+
+func (a *AdaptiveRateLimiter) Wait(ctx context.Context) error { return a.limiter.Wait(ctx) }
+
+func (a *AdaptiveRateLimiter) Stop() {}
+
+func (a *AdaptiveRateLimiter) QPS() float32 {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	return float32(a.current)
+}
+
+// observingTransport reports the latency and status of every discovery request to the rate limiter and metrics.
+type observingTransport struct {
+	next    http.RoundTripper
+	limiter *AdaptiveRateLimiter
+}
+
+func (o *observingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := o.next.RoundTrip(req)
+	latency := time.Since(start)
+
+	var code int
+	if err == nil {
+		code = resp.StatusCode
+	}
+	o.limiter.Observe(latency, code)
+
+	label := strconv.Itoa(code)
+	if code == 0 {
+		label = "error"
+	}
+	discoveryRequestLatency.WithLabelValues(label).Observe(latency.Seconds())
+	return resp, err
+}
@@ -0,0 +1,72 @@
+package discovery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveRateLimiter(t *testing.T) {
+	a := NewAdaptiveRateLimiter()
+	assert.Equal(t, float32(minRPS), a.QPS())
+
+	// Fast successful requests increase the limit additively
+	a.Observe(time.Millisecond, http.StatusOK)
+	a.Observe(time.Millisecond, http.StatusOK)
+	a.Observe(time.Millisecond, http.StatusOK)
+	assert.Equal(t, float32(4), a.QPS())
+
+	// Throttling halves it
+	a.Observe(time.Millisecond, http.StatusTooManyRequests)
+	assert.Equal(t, float32(2), a.QPS())
+
+	// So do slow requests, server errors, and requests that failed without a response
+	a.Observe(slowRequestThreshold+time.Second, http.StatusOK)
+	assert.Equal(t, float32(1), a.QPS())
+	a.Observe(time.Millisecond, http.StatusOK)
+	a.Observe(time.Millisecond, http.StatusOK)
+	a.Observe(time.Millisecond, http.StatusServiceUnavailable)
+	assert.Equal(t, float32(1.5), a.QPS())
+	a.Observe(time.Millisecond, 0)
+	assert.Equal(t, float32(minRPS), a.QPS())
+
+	// Client errors aren't the apiserver's fault
+	a.Observe(time.Millisecond, http.StatusNotFound)
+	assert.Equal(t, float32(2), a.QPS())
+
+	// Bounded on both sides
+	for i := 0; i < maxRPS*2; i++ {
+		a.Observe(time.Millisecond, http.StatusOK)
+	}
+	assert.Equal(t, float32(maxRPS), a.QPS())
+	for i := 0; i < 10; i++ {
+		a.Observe(time.Millisecond, http.StatusTooManyRequests)
+	}
+	assert.Equal(t, float32(minRPS), a.QPS())
+}
+
+func TestObservingTransport(t *testing.T) {
+	code := http.StatusOK
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}))
+	defer svr.Close()
+
+	a := NewAdaptiveRateLimiter()
+	client := &http.Client{Transport: &observingTransport{next: http.DefaultTransport, limiter: a}}
+
+	resp, err := client.Get(svr.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, float32(2), a.QPS())
+
+	code = http.StatusTooManyRequests
+	resp, err = client.Get(svr.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, float32(1), a.QPS())
+}
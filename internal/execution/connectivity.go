@@ -0,0 +1,27 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// connectivityTimeout bounds each attempt to reach a required endpoint.
+const connectivityTimeout = time.Second * 5
+
+// checkConnectivity returns an error if any of the given host:port addresses can't be reached over TCP.
+func checkConnectivity(ctx context.Context, endpoints []string) error {
+	dialer := &net.Dialer{Timeout: connectivityTimeout}
+	var errs []error
+	for _, endpoint := range endpoints {
+		conn, err := dialer.DialContext(ctx, "tcp", endpoint)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("endpoint %q is unreachable: %w", endpoint, err))
+			continue
+		}
+		conn.Close()
+	}
+	return errors.Join(errs...)
+}
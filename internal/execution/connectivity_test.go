@@ -0,0 +1,30 @@
+package execution
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConnectivity(t *testing.T) {
+	ctx := context.Background()
+
+	open, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer open.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed.Close()
+
+	assert.NoError(t, checkConnectivity(ctx, nil))
+	assert.NoError(t, checkConnectivity(ctx, []string{open.Addr().String()}))
+
+	err = checkConnectivity(ctx, []string{open.Addr().String(), closed.Addr().String()})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), closed.Addr().String())
+	assert.NotContains(t, err.Error(), open.Addr().String())
+}
@@ -0,0 +1,92 @@
+package execution
+
+import (
+	"strconv"
+	"strings"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// applyResourceDefaults merges the synthesizer's per-kind defaults into the annotations of the given objects.
+func applyResourceDefaults(syn *apiv1.Synthesizer, objs []*unstructured.Unstructured) {
+	if len(syn.Spec.ResourceDefaults) == 0 {
+		return
+	}
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		for i := range syn.Spec.ResourceDefaults {
+			defaults := &syn.Spec.ResourceDefaults[i]
+			if defaults.Group == gvk.Group && defaults.Kind == gvk.Kind {
+				applyDefaults(defaults, obj)
+				break
+			}
+		}
+	}
+}
+
+func applyDefaults(defaults *apiv1.ResourceDefaults, obj *unstructured.Unstructured) {
+	anno := obj.GetAnnotations()
+	if anno == nil {
+		anno = map[string]string{}
+	}
+
+	if _, ok := anno["eno.azure.io/readiness-group"]; !ok && defaults.ReadinessGroup != nil {
+		anno["eno.azure.io/readiness-group"] = strconv.Itoa(*defaults.ReadinessGroup)
+	}
+	if _, ok := anno["eno.azure.io/reconcile-interval"]; !ok && defaults.ReconcileInterval != nil {
+		anno["eno.azure.io/reconcile-interval"] = defaults.ReconcileInterval.Duration.String()
+	}
+	if !hasReadinessChecks(anno) {
+		for name, expr := range defaults.ReadinessChecks {
+			if name == "default" {
+				anno["eno.azure.io/readiness"] = expr
+			} else {
+				anno["eno.azure.io/readiness-"+name] = expr
+			}
+		}
+	}
+
+	if len(anno) > 0 {
+		obj.SetAnnotations(anno)
+	}
+}
+
+func hasReadinessChecks(anno map[string]string) bool {
+	for key := range anno {
+		if strings.HasPrefix(key, "eno.azure.io/readiness") && key != "eno.azure.io/readiness-group" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyCommonMetadata stamps the composition's common labels and annotations onto the given objects.
+// Patches are skipped, since they modify resources that other compositions manage.
+func applyCommonMetadata(comp *apiv1.Composition, objs []*unstructured.Unstructured) {
+	if len(comp.Spec.CommonLabels) == 0 && len(comp.Spec.CommonAnnotations) == 0 {
+		return
+	}
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		if gvk.Group == "eno.azure.io" && gvk.Kind == "Patch" {
+			continue
+		}
+		if len(comp.Spec.CommonLabels) > 0 {
+			obj.SetLabels(mergeStringMaps(obj.GetLabels(), comp.Spec.CommonLabels))
+		}
+		if len(comp.Spec.CommonAnnotations) > 0 {
+			obj.SetAnnotations(mergeStringMaps(obj.GetAnnotations(), comp.Spec.CommonAnnotations))
+		}
+	}
+}
+
+func mergeStringMaps(base, overrides map[string]string) map[string]string {
+	if base == nil {
+		base = make(map[string]string, len(overrides))
+	}
+	for key, val := range overrides {
+		base[key] = val
+	}
+	return base
+}
@@ -0,0 +1,94 @@
+package execution
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+func TestApplyResourceDefaults(t *testing.T) {
+	syn := &apiv1.Synthesizer{}
+	syn.Spec.ResourceDefaults = []apiv1.ResourceDefaults{
+		{
+			Group:             "apps",
+			Kind:              "Deployment",
+			ReadinessGroup:    ptr.To(2),
+			ReconcileInterval: &metav1.Duration{Duration: time.Minute},
+			ReadinessChecks:   map[string]string{"default": "self.status.ready", "other": "true"},
+		},
+		{
+			Group:          "apps",
+			Kind:           "Deployment",
+			ReadinessGroup: ptr.To(3), // shadowed by the first entry
+		},
+		{
+			Kind:           "ConfigMap",
+			ReadinessGroup: ptr.To(-1),
+		},
+	}
+
+	newObj := func(apiVersion, kind string, anno map[string]string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]any{"apiVersion": apiVersion, "kind": kind}}
+		obj.SetName("test")
+		obj.SetAnnotations(anno)
+		return obj
+	}
+	deploy := newObj("apps/v1", "Deployment", nil)
+	overridden := newObj("apps/v1", "Deployment", map[string]string{
+		"eno.azure.io/readiness-group": "5",
+		"eno.azure.io/readiness-foo":   "false",
+	})
+	cm := newObj("v1", "ConfigMap", map[string]string{"foo": "bar"})
+	secret := newObj("v1", "Secret", nil)
+
+	applyResourceDefaults(syn, []*unstructured.Unstructured{deploy, overridden, cm, secret})
+
+	assert.Equal(t, map[string]string{
+		"eno.azure.io/readiness-group":    "2",
+		"eno.azure.io/reconcile-interval": "1m0s",
+		"eno.azure.io/readiness":          "self.status.ready",
+		"eno.azure.io/readiness-other":    "true",
+	}, deploy.GetAnnotations())
+
+	assert.Equal(t, map[string]string{
+		"eno.azure.io/readiness-group":    "5",
+		"eno.azure.io/readiness-foo":      "false",
+		"eno.azure.io/reconcile-interval": "1m0s",
+	}, overridden.GetAnnotations())
+
+	assert.Equal(t, map[string]string{
+		"foo":                          "bar",
+		"eno.azure.io/readiness-group": "-1",
+	}, cm.GetAnnotations())
+
+	assert.Nil(t, secret.GetAnnotations())
+}
+
+func TestApplyCommonMetadata(t *testing.T) {
+	comp := &apiv1.Composition{}
+	comp.Spec.CommonLabels = map[string]string{"team": "a", "env": "prod"}
+	comp.Spec.CommonAnnotations = map[string]string{"cost-center": "123"}
+
+	objs := []*unstructured.Unstructured{
+		{Object: map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": "a"}}},
+		{Object: map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{
+			"name":        "b",
+			"labels":      map[string]any{"env": "dev", "app": "b"},
+			"annotations": map[string]any{"foo": "bar"},
+		}}},
+		{Object: map[string]any{"apiVersion": "eno.azure.io/v1", "kind": "Patch", "metadata": map[string]any{"name": "c"}}},
+	}
+	applyCommonMetadata(comp, objs)
+
+	assert.Equal(t, map[string]string{"team": "a", "env": "prod"}, objs[0].GetLabels())
+	assert.Equal(t, map[string]string{"cost-center": "123"}, objs[0].GetAnnotations())
+	assert.Equal(t, map[string]string{"team": "a", "env": "prod", "app": "b"}, objs[1].GetLabels())
+	assert.Equal(t, map[string]string{"cost-center": "123", "foo": "bar"}, objs[1].GetAnnotations())
+	assert.Empty(t, objs[2].GetLabels())
+	assert.Empty(t, objs[2].GetAnnotations())
+}
@@ -0,0 +1,111 @@
+package execution
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// determinismEnv returns the environment variables that fix the synthesizer's clock and random seed, if configured.
+func determinismEnv(syn *apiv1.Synthesizer) []string {
+	opts := syn.Spec.Determinism
+	if opts == nil {
+		return nil
+	}
+
+	var env []string
+	if opts.Clock != nil {
+		env = append(env,
+			"ENO_SYNTHESIS_TIME="+opts.Clock.UTC().Format(time.RFC3339),
+			"SOURCE_DATE_EPOCH="+strconv.FormatInt(opts.Clock.Unix(), 10))
+	}
+	if opts.RandomSeed != nil {
+		env = append(env, "ENO_RANDOM_SEED="+strconv.FormatInt(*opts.RandomSeed, 10))
+	}
+	return env
+}
+
+// execute invokes the synthesizer. When nondeterminism detection is enabled, it's invoked a second time with
+// identical inputs and true is returned if the outputs differ - in which case an error result is added to the output.
+func (e *Executor) execute(ctx context.Context, syn *apiv1.Synthesizer, input *krmv1.ResourceList) (*krmv1.ResourceList, bool, error) {
+	if syn.Spec.Determinism == nil || !syn.Spec.Determinism.DetectNondeterminism {
+		output, err := e.Handler(ctx, syn, input)
+		return output, false, err
+	}
+
+	first, err := e.Handler(ctx, syn, input.DeepCopy())
+	if err != nil {
+		return nil, false, err
+	}
+	second, err := e.Handler(ctx, syn, input.DeepCopy())
+	if err != nil {
+		return nil, false, err
+	}
+
+	diff, err := describeOutputDiff(first, second)
+	if err != nil {
+		return nil, false, fmt.Errorf("comparing synthesizer outputs: %w", err)
+	}
+	if diff == "" {
+		logr.FromContextOrDiscard(ctx).V(0).Info("synthesizer output is deterministic")
+		return first, false, nil
+	}
+
+	first.Results = append(first.Results, &krmv1.Result{
+		Message:  "synthesizer output differed between two runs with identical inputs: " + diff,
+		Severity: krmv1.ResultSeverityError,
+	})
+	return first, true, nil
+}
+
+// describeOutputDiff returns a description of the first difference between two synthesizer outputs, or an empty string if they're equal.
+// Results are compared along with resources, since they're also persisted.
+func describeOutputDiff(a, b *krmv1.ResourceList) (string, error) {
+	if len(a.Items) != len(b.Items) {
+		return fmt.Sprintf("%d resources vs. %d resources", len(a.Items), len(b.Items)), nil
+	}
+	for i := range a.Items {
+		equal, err := jsonEqual(a.Items[i], b.Items[i])
+		if err != nil {
+			return "", err
+		}
+		if !equal {
+			return "resource " + describeOutputResource(a.Items[i]), nil
+		}
+	}
+	equal, err := jsonEqual(a.Results, b.Results)
+	if err != nil {
+		return "", err
+	}
+	if !equal {
+		return "results", nil
+	}
+	return "", nil
+}
+
+func jsonEqual(a, b any) (bool, error) {
+	aj, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	bj, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aj, bj), nil
+}
+
+func describeOutputResource(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+}
@@ -0,0 +1,87 @@
+package execution
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+func TestDeterminismEnv(t *testing.T) {
+	syn := &apiv1.Synthesizer{}
+	assert.Empty(t, determinismEnv(syn))
+
+	syn.Spec.Determinism = &apiv1.DeterminismOptions{
+		Clock:      &metav1.Time{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		RandomSeed: ptr.To(int64(42)),
+	}
+	assert.Equal(t, []string{"ENO_SYNTHESIS_TIME=2024-01-02T03:04:05Z", "SOURCE_DATE_EPOCH=1704164645", "ENO_RANDOM_SEED=42"}, determinismEnv(syn))
+}
+
+func TestExecHandlerDeterminismEnv(t *testing.T) {
+	handle := NewExecHandler()
+
+	syn := &apiv1.Synthesizer{}
+	syn.Spec.Command = []string{"/bin/sh", "-c", `echo "{\"apiVersion\":\"config.kubernetes.io/v1\",\"kind\":\"ResourceList\",\"items\":[{\"apiVersion\":\"v1\",\"kind\":\"ConfigMap\",\"metadata\":{\"name\":\"seed-$ENO_RANDOM_SEED\"}}]}"`}
+	syn.Spec.Determinism = &apiv1.DeterminismOptions{RandomSeed: ptr.To(int64(123))}
+
+	out, err := handle(context.Background(), syn, &krmv1.ResourceList{})
+	require.NoError(t, err)
+	require.Len(t, out.Items, 1)
+	assert.Equal(t, "seed-123", out.Items[0].GetName())
+}
+
+func TestDetectNondeterminism(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int
+	var nondeterministic bool
+	e := &Executor{
+		Handler: func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+			calls++
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion("v1")
+			obj.SetKind("ConfigMap")
+			obj.SetName("test")
+			obj.SetNamespace("default")
+			if nondeterministic {
+				obj.SetLabels(map[string]string{"call": string(rune('0' + calls))})
+			}
+			return &krmv1.ResourceList{Items: []*unstructured.Unstructured{obj}}, nil
+		},
+	}
+
+	// Disabled
+	syn := &apiv1.Synthesizer{}
+	out, failed, err := e.execute(ctx, syn, &krmv1.ResourceList{})
+	require.NoError(t, err)
+	assert.False(t, failed)
+	assert.Len(t, out.Items, 1)
+	assert.Equal(t, 1, calls)
+
+	// Deterministic
+	calls = 0
+	syn.Spec.Determinism = &apiv1.DeterminismOptions{DetectNondeterminism: true}
+	out, failed, err = e.execute(ctx, syn, &krmv1.ResourceList{})
+	require.NoError(t, err)
+	assert.False(t, failed)
+	assert.Empty(t, out.Results)
+	assert.Equal(t, 2, calls)
+
+	// Nondeterministic
+	calls = 0
+	nondeterministic = true
+	out, failed, err = e.execute(ctx, syn, &krmv1.ResourceList{})
+	require.NoError(t, err)
+	assert.True(t, failed)
+	require.Len(t, out.Results, 1)
+	assert.Equal(t, krmv1.ResultSeverityError, out.Results[0].Severity)
+	assert.Equal(t, "synthesizer output differed between two runs with identical inputs: resource ConfigMap default/test", out.Results[0].Message)
+}
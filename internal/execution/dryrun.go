@@ -0,0 +1,102 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/diff"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+)
+
+// DryRunResult summarizes how a dry run's output differs from the composition's current synthesis.
+type DryRunResult struct {
+	Added    int `json:"added"`
+	Removed  int `json:"removed"`
+	Modified int `json:"modified"`
+
+	// Error is set when synthesis would have failed.
+	Error string `json:"error,omitempty"`
+}
+
+// DryRun synthesizes the composition using the current synthesizer without writing the output anywhere,
+// and compares the output to the composition's current synthesis.
+//
+// Synthesis failures are reported by the result. Returned errors are transient.
+func (e *Executor) DryRun(ctx context.Context, env *Env) (*DryRunResult, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	comp := &apiv1.Composition{}
+	comp.Name = env.CompositionName
+	comp.Namespace = env.CompositionNamespace
+	err := e.Reader.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+	if err != nil {
+		return nil, fmt.Errorf("fetching composition: %w", err)
+	}
+	current := comp.Status.CurrentSynthesis
+	if current == nil || current.Synthesized == nil {
+		return &DryRunResult{Error: "composition hasn't been synthesized"}, nil
+	}
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = comp.Spec.Synthesizer.Name
+	err = e.Reader.Get(ctx, client.ObjectKeyFromObject(syn), syn)
+	if err != nil {
+		return nil, fmt.Errorf("fetching synthesizer: %w", err)
+	}
+
+	input, _, err := e.buildPodInput(ctx, comp, syn, true)
+	if err != nil {
+		return &DryRunResult{Error: fmt.Sprintf("building synthesizer input: %s", err)}, nil
+	}
+
+	output, _, err := e.execute(ctx, syn, input)
+	if err != nil {
+		return &DryRunResult{Error: fmt.Sprintf("executing synthesizer: %s", err)}, nil
+	}
+	output, _, err = e.processOutput(ctx, comp, syn, output)
+	if err != nil {
+		return nil, err
+	}
+	if msg := errorResults(output); msg != "" {
+		return &DryRunResult{Error: msg}, nil
+	}
+
+	prev, err := diff.Load(ctx, e.Reader, comp, current.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("loading current synthesis: %w", err)
+	}
+	next, err := diff.FromObjects(output.Items)
+	if err != nil {
+		return nil, fmt.Errorf("loading synthesizer output: %w", err)
+	}
+
+	result := &DryRunResult{}
+	for _, change := range diff.Compare(prev, next) {
+		switch change.Type {
+		case diff.ChangeAdded:
+			result.Added++
+		case diff.ChangeRemoved:
+			result.Removed++
+		case diff.ChangeModified:
+			result.Modified++
+		}
+	}
+	logger.V(0).Info("dry run complete", "added", result.Added, "removed", result.Removed, "modified", result.Modified)
+	return result, nil
+}
+
+// errorResults returns the messages of the output's error results, if any.
+func errorResults(rl *krmv1.ResourceList) string {
+	var msgs []string
+	for _, result := range rl.Results {
+		if result.Severity == krmv1.ResultSeverityError {
+			msgs = append(msgs, result.Message)
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
@@ -0,0 +1,169 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.SchemeBuilder.AddToScheme(scheme))
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = "test-synth"
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Synthesizer.Name = syn.Name
+	comp.Spec.CommonLabels = map[string]string{"team": "foo"}
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{
+		UUID:           "test-uuid",
+		Synthesized:    ptr.To(metav1.Now()),
+		ResourceSlices: []*apiv1.ResourceSliceRef{{Name: "test-slice"}},
+	}
+
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = comp.Namespace
+	slice.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"labels":{"team":"foo"},"name":"unchanged","namespace":"default"}}`},
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","data":{"foo":"bar"},"metadata":{"labels":{"team":"foo"},"name":"modified","namespace":"default"}}`},
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"labels":{"team":"foo"},"name":"removed","namespace":"default"}}`},
+	}
+
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(syn, comp, slice).Build()
+
+	newConfigMap := func(name string, data map[string]any) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]any{"name": name, "namespace": "default"},
+		}}
+		if data != nil {
+			obj.Object["data"] = data
+		}
+		return obj
+	}
+
+	var output *krmv1.ResourceList
+	var outputErr error
+	e := &Executor{
+		Reader: cli,
+		Writer: cli,
+		Handler: func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+			return output, outputErr
+		},
+	}
+	env := &Env{CompositionName: comp.Name, CompositionNamespace: comp.Namespace, DryRun: true}
+
+	t.Run("changes", func(t *testing.T) {
+		output = &krmv1.ResourceList{Items: []*unstructured.Unstructured{
+			newConfigMap("unchanged", nil),
+			newConfigMap("modified", map[string]any{"foo": "baz"}),
+			newConfigMap("added", nil),
+		}}
+
+		result, err := e.DryRun(ctx, env)
+		require.NoError(t, err)
+		assert.Equal(t, &DryRunResult{Added: 1, Removed: 1, Modified: 1}, result)
+	})
+
+	t.Run("state", func(t *testing.T) {
+		state := &unstructured.Unstructured{Object: map[string]any{"data": map[string]any{"foo": "bar"}}}
+		state.SetAPIVersion(apiv1.SchemeGroupVersion.String())
+		state.SetKind(StateKind)
+		state.SetName(comp.Name)
+		output = &krmv1.ResourceList{Items: []*unstructured.Unstructured{
+			newConfigMap("unchanged", nil),
+			newConfigMap("modified", map[string]any{"foo": "bar"}),
+			newConfigMap("removed", nil),
+			state,
+		}}
+
+		result, err := e.DryRun(ctx, env)
+		require.NoError(t, err)
+		assert.Equal(t, &DryRunResult{}, result)
+	})
+
+	t.Run("error result", func(t *testing.T) {
+		output = &krmv1.ResourceList{Results: []*krmv1.Result{{Message: "foo", Severity: krmv1.ResultSeverityError}, {Message: "bar", Severity: krmv1.ResultSeverityError}}}
+
+		result, err := e.DryRun(ctx, env)
+		require.NoError(t, err)
+		assert.Equal(t, &DryRunResult{Error: "foo; bar"}, result)
+	})
+
+	t.Run("synthesizer error", func(t *testing.T) {
+		output = nil
+		outputErr = errors.New("exit status 1")
+
+		result, err := e.DryRun(ctx, env)
+		require.NoError(t, err)
+		assert.Equal(t, &DryRunResult{Error: "executing synthesizer: exit status 1"}, result)
+	})
+
+	t.Run("missing composition", func(t *testing.T) {
+		_, err := e.DryRun(ctx, &Env{CompositionName: "missing", CompositionNamespace: "default", DryRun: true})
+		assert.Error(t, err)
+	})
+}
+
+func TestDryRunIdentifiers(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.SchemeBuilder.AddToScheme(scheme))
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = "test-synth"
+
+	pool := &apiv1.IdentifierPool{}
+	pool.Name = "test-pool"
+	pool.Namespace = "default"
+	pool.Spec.Start = 100
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Synthesizer.Name = syn.Name
+	comp.Spec.Identifiers = []apiv1.IdentifierRequest{{Key: "id", Pool: pool.Name}}
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid", Synthesized: ptr.To(metav1.Now())}
+
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(syn, pool, comp).WithStatusSubresource(pool).Build()
+
+	var value any
+	e := &Executor{
+		Reader: cli,
+		Writer: cli,
+		Handler: func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+			for _, item := range rl.Items {
+				if item.GetKind() == IdentifierKind {
+					value = item.Object["value"]
+				}
+			}
+			return &krmv1.ResourceList{}, nil
+		},
+	}
+
+	_, err := e.DryRun(ctx, &Env{CompositionName: comp.Name, CompositionNamespace: comp.Namespace, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, "100", value)
+
+	// The pool isn't modified
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(pool), pool))
+	assert.Empty(t, pool.Status.Allocations)
+	assert.Zero(t, pool.Status.Next)
+}
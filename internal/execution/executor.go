@@ -10,6 +10,7 @@ import (
 	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -24,6 +25,21 @@ type Executor struct {
 	Reader  client.Reader
 	Writer  client.Client
 	Handler SynthesizerHandle
+
+	// PostProcessor is optionally invoked on the complete synthesizer output before it's written to resource slices.
+	PostProcessor SynthesizerHandle
+
+	// Schemas are used to find unknown fields in the synthesizer output when enabled by the composition. Optional.
+	Schemas SchemaGetter
+
+	// Mapper is used to validate the namespaces of synthesized resources against their scope. Optional.
+	Mapper meta.RESTMapper
+
+	// Logs holds the synthesizer's output, which is copied upstream when enabled by the synthesizer. Optional.
+	Logs *LogBuffer
+
+	// Decrypter decrypts inputs annotated as SOPS-encrypted. Optional - encrypted inputs are an error without it.
+	Decrypter Decrypter
 }
 
 func (e *Executor) Synthesize(ctx context.Context, env *Env) error {
@@ -41,32 +57,108 @@ func (e *Executor) Synthesize(ctx context.Context, env *Env) error {
 		return nil
 	}
 
+	// Fail fast (the pod will be restarted) instead of hanging mid-synthesis when the network is misconfigured
+	if len(env.RequiredEndpoints) > 0 {
+		start := time.Now()
+		if err := checkConnectivity(ctx, env.RequiredEndpoints); err != nil {
+			return fmt.Errorf("checking connectivity: %w", err)
+		}
+		logger.V(0).Info("required endpoints are reachable", "latency", time.Since(start).Milliseconds())
+	}
+
+	// Passthrough compositions don't have a synthesizer - an empty one results in the default behavior
 	syn := &apiv1.Synthesizer{}
-	syn.Name = comp.Spec.Synthesizer.Name
-	err = e.Reader.Get(ctx, client.ObjectKeyFromObject(syn), syn)
-	if err != nil {
-		return fmt.Errorf("fetching synthesizer: %w", err)
+	if !comp.Passthrough() {
+		syn.Name = comp.Spec.Synthesizer.Name
+		err = e.Reader.Get(ctx, client.ObjectKeyFromObject(syn), syn)
+		if err != nil {
+			return fmt.Errorf("fetching synthesizer: %w", err)
+		}
 	}
 
-	input, revs, err := e.buildPodInput(ctx, comp, syn)
+	input, revs, err := e.buildPodInput(ctx, comp, syn, false)
 	if err != nil {
 		return fmt.Errorf("building synthesizer input: %w", err)
 	}
+	if err := checkPin(comp, syn, revs); err != nil {
+		return err
+	}
 
-	output, err := e.Handler(ctx, syn, input)
+	output, nondeterministic, err := e.execute(ctx, syn, input)
+	e.writeLogs(ctx, env, comp, syn)
 	if err != nil {
 		return fmt.Errorf("executing synthesizer: %w", err)
 	}
+	output, state, err := e.processOutput(ctx, comp, syn, output)
+	if err != nil {
+		return err
+	}
+
+	// The composition may have been deleted or resynthesized while the synthesizer was running
+	current := &apiv1.Composition{}
+	err = e.Reader.Get(ctx, client.ObjectKeyFromObject(comp), current)
+	if errors.IsNotFound(err) {
+		logger.V(0).Info("composition no longer exists - discarding synthesizer output")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("fetching composition: %w", err)
+	}
+	if reason, skip := skipSynthesis(current, env); skip {
+		logger.V(0).Info("synthesis is no longer relevant - discarding its output", "reason", reason)
+		return nil
+	}
 
-	sliceRefs, err := e.writeSlices(ctx, comp, output)
+	previous, err := e.fetchPreviousSlices(ctx, comp)
 	if err != nil {
 		return err
 	}
+	exceeded, err := e.checkResourceQuota(ctx, comp, previous, output)
+	if err != nil {
+		return fmt.Errorf("checking resource quota: %w", err)
+	}
+
+	var sliceRefs []*apiv1.ResourceSliceRef
+	if !exceeded && !nondeterministic {
+		sliceRefs, err = e.writeSlices(ctx, comp, previous, output)
+		if err != nil {
+			return err
+		}
+	}
+
+	return e.updateComposition(ctx, env, comp, syn, sliceRefs, revs, output, state)
+}
+
+// processOutput post-processes, validates, and transforms the synthesizer's output before it's written or compared.
+// State pseudo-resources are removed from the output and returned separately.
+func (e *Executor) processOutput(ctx context.Context, comp *apiv1.Composition, syn *apiv1.Synthesizer, output *krmv1.ResourceList) (*krmv1.ResourceList, map[string]string, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	flattenOutput(output)
+
+	if e.PostProcessor != nil {
+		start := time.Now()
+		var err error
+		output, err = e.PostProcessor(ctx, syn, output)
+		if err != nil {
+			return nil, nil, fmt.Errorf("post-processing synthesizer output: %w", err)
+		}
+		logger.V(0).Info("post-processed synthesizer output", "latency", time.Since(start).Milliseconds())
+	}
+	state := extractState(output)
 
-	return e.updateComposition(ctx, env, comp, syn, sliceRefs, revs, output)
+	applyResourceDefaults(syn, output.Items)
+	if err := e.checkScopes(comp, output); err != nil {
+		return nil, nil, fmt.Errorf("checking resource scopes: %w", err)
+	}
+	applyNameTransform(comp, output.Items)
+	applyCommonMetadata(comp, output.Items)
+	if err := e.checkUnknownFields(ctx, comp, output); err != nil {
+		return nil, nil, fmt.Errorf("checking for unknown fields: %w", err)
+	}
+	return output, state, nil
 }
 
-func (e *Executor) buildPodInput(ctx context.Context, comp *apiv1.Composition, syn *apiv1.Synthesizer) (*krmv1.ResourceList, []apiv1.InputRevisions, error) {
+func (e *Executor) buildPodInput(ctx context.Context, comp *apiv1.Composition, syn *apiv1.Synthesizer, dryRun bool) (*krmv1.ResourceList, []apiv1.InputRevisions, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 	bindings := map[string]*apiv1.Binding{}
 	for _, b := range comp.Spec.Bindings {
@@ -102,23 +194,58 @@ func (e *Executor) buildPodInput(ctx context.Context, comp *apiv1.Composition, s
 		}
 		anno["eno.azure.io/input-key"] = key
 		obj.SetAnnotations(anno)
-		rl.Items = append(rl.Items, obj)
-		logger.V(0).Info("retrieved input", "key", key, "latency", time.Since(start).Abs().Milliseconds())
 
 		// Store the revision to be written to the synthesis status later
 		revs = append(revs, *resource.NewInputRevisions(obj, key))
+
+		if err := e.decryptInput(ctx, obj); err != nil {
+			return nil, nil, fmt.Errorf("decrypting resource for ref %q: %w", key, err)
+		}
+		rl.Items = append(rl.Items, obj)
+		logger.V(0).Info("retrieved input", "key", key, "latency", time.Since(start).Abs().Milliseconds())
+	}
+
+	ids, err := e.buildIdentifierInputs(ctx, comp, dryRun)
+	if err != nil {
+		return nil, nil, err
+	}
+	rl.Items = append(rl.Items, ids...)
+
+	upstreams, upstreamRevs, err := e.buildUpstreamInputs(ctx, comp)
+	if err != nil {
+		return nil, nil, err
+	}
+	rl.Items = append(rl.Items, upstreams...)
+	revs = append(revs, upstreamRevs...)
+
+	if state := buildStateInput(comp); state != nil {
+		rl.Items = append(rl.Items, state)
 	}
 
 	return rl, revs, nil
 }
 
-func (e *Executor) writeSlices(ctx context.Context, comp *apiv1.Composition, rl *krmv1.ResourceList) ([]*apiv1.ResourceSliceRef, error) {
-	logger := logr.FromContextOrDiscard(ctx)
-
-	previous, err := e.fetchPreviousSlices(ctx, comp)
-	if err != nil {
-		return nil, err
+// checkPin returns an error when the synthesizer or inputs changed since the composition's pin was last checked by the controller.
+func checkPin(comp *apiv1.Composition, syn *apiv1.Synthesizer, revs []apiv1.InputRevisions) error {
+	pin := comp.Spec.Pin
+	if pin == nil {
+		return nil
+	}
+	if pin.SynthesizerGeneration != 0 && pin.SynthesizerGeneration != syn.Generation {
+		return fmt.Errorf("synthesizer generation %d doesn't match the composition's pinned generation %d", syn.Generation, pin.SynthesizerGeneration)
 	}
+	for _, want := range pin.InputRevisions {
+		for _, rev := range revs {
+			if rev.Key == want.Key && !want.Equal(rev) {
+				return fmt.Errorf("input %q doesn't match the composition's pinned revision", rev.Key)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Executor) writeSlices(ctx context.Context, comp *apiv1.Composition, previous []*apiv1.ResourceSlice, rl *krmv1.ResourceList) ([]*apiv1.ResourceSliceRef, error) {
+	logger := logr.FromContextOrDiscard(ctx)
 
 	slices, err := resource.Slice(comp, previous, rl.Items, maxSliceJsonBytes)
 	if err != nil {
@@ -183,7 +310,7 @@ func (e *Executor) writeResourceSlice(ctx context.Context, slice *apiv1.Resource
 	})
 }
 
-func (e *Executor) updateComposition(ctx context.Context, env *Env, oldComp *apiv1.Composition, syn *apiv1.Synthesizer, refs []*apiv1.ResourceSliceRef, revs []apiv1.InputRevisions, rl *krmv1.ResourceList) error {
+func (e *Executor) updateComposition(ctx context.Context, env *Env, oldComp *apiv1.Composition, syn *apiv1.Synthesizer, refs []*apiv1.ResourceSliceRef, revs []apiv1.InputRevisions, rl *krmv1.ResourceList, state map[string]string) error {
 	logger := logr.FromContextOrDiscard(ctx)
 	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		comp := &apiv1.Composition{}
@@ -208,6 +335,9 @@ func (e *Executor) updateComposition(ctx context.Context, env *Env, oldComp *api
 				Tags:     result.Tags,
 			})
 		}
+		if state != nil && !comp.Status.CurrentSynthesis.Failed() {
+			comp.Status.SynthesizerState = state
+		}
 
 		err = e.Writer.Status().Update(ctx, comp)
 		if err != nil {
@@ -227,6 +357,9 @@ func skipSynthesis(comp *apiv1.Composition, env *Env) (string, bool) {
 	if synthesis.Synthesized != nil {
 		return "AlreadySynthesized", true
 	}
+	if comp.DeletionTimestamp != nil {
+		return "CompositionDeleted", true
+	}
 	if synthesis.UUID != env.SynthesisUUID {
 		return "UUIDMismatch", true
 	}
@@ -327,6 +327,73 @@ func TestUUIDMismatch(t *testing.T) {
 	assert.Nil(t, comp.Status.CurrentSynthesis.Synthesized)
 }
 
+// TestPreemptedDuringSynthesis proves that the output of a synthesis preempted by the concurrency limiter is discarded.
+func TestPreemptedDuringSynthesis(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.SchemeBuilder.AddToScheme(scheme))
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&apiv1.ResourceSlice{}, &apiv1.Composition{}).
+		Build()
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = "test-synth"
+	err := cli.Create(ctx, syn)
+	require.NoError(t, err)
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Synthesizer.Name = syn.Name
+	err = cli.Create(ctx, comp)
+	require.NoError(t, err)
+
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid"}
+	err = cli.Status().Update(ctx, comp)
+	require.NoError(t, err)
+
+	env := &Env{
+		CompositionName:      comp.Name,
+		CompositionNamespace: comp.Namespace,
+		SynthesisUUID:        comp.Status.CurrentSynthesis.UUID,
+	}
+	e := &Executor{
+		Reader: cli,
+		Writer: cli,
+		Handler: func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+			// Preemption returns the synthesis to the pending state by removing its UUID
+			err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+				comp.Status.CurrentSynthesis.UUID = ""
+				return cli.Status().Update(ctx, comp)
+			})
+			require.NoError(t, err)
+
+			out := &unstructured.Unstructured{
+				Object: map[string]any{
+					"apiVersion": "v1",
+					"kind":       "ConfigMap",
+					"metadata": map[string]string{
+						"name":      "test",
+						"namespace": "default",
+					},
+				},
+			}
+			return &krmv1.ResourceList{Items: []*unstructured.Unstructured{out}}, nil
+		},
+	}
+
+	err = e.Synthesize(ctx, env)
+	require.NoError(t, err)
+
+	err = cli.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+	require.NoError(t, err)
+	assert.Nil(t, comp.Status.CurrentSynthesis.Synthesized)
+	assert.Empty(t, comp.Status.CurrentSynthesis.ResourceSlices)
+}
+
 func TestCompletionMismatchDuringSynthesis(t *testing.T) {
 	ctx := context.Background()
 	scheme := runtime.NewScheme()
@@ -402,3 +469,67 @@ func TestCompletionMismatchDuringSynthesis(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, originalSynthTime, *comp.Status.CurrentSynthesis.Synthesized)
 }
+
+func TestCompositionDeletedDuringSynthesis(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.SchemeBuilder.AddToScheme(scheme))
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&apiv1.ResourceSlice{}, &apiv1.Composition{}).
+		Build()
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = "test-synth"
+	err := cli.Create(ctx, syn)
+	require.NoError(t, err)
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Finalizers = []string{"eno.azure.io/cleanup"}
+	comp.Spec.Synthesizer.Name = syn.Name
+	err = cli.Create(ctx, comp)
+	require.NoError(t, err)
+
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid"}
+	err = cli.Status().Update(ctx, comp)
+	require.NoError(t, err)
+
+	env := &Env{
+		CompositionName:      comp.Name,
+		CompositionNamespace: comp.Namespace,
+		SynthesisUUID:        comp.Status.CurrentSynthesis.UUID,
+	}
+	e := &Executor{
+		Reader: cli,
+		Writer: cli,
+		Handler: func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+			require.NoError(t, cli.Delete(ctx, comp.DeepCopy()))
+
+			out := &unstructured.Unstructured{
+				Object: map[string]any{
+					"apiVersion": "v1",
+					"kind":       "ConfigMap",
+					"metadata": map[string]string{
+						"name":      "test",
+						"namespace": "default",
+					},
+				},
+			}
+			return &krmv1.ResourceList{Items: []*unstructured.Unstructured{out}}, nil
+		},
+	}
+
+	err = e.Synthesize(ctx, env)
+	require.NoError(t, err)
+
+	err = cli.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+	require.NoError(t, err)
+	assert.Nil(t, comp.Status.CurrentSynthesis.Synthesized)
+
+	slices := &apiv1.ResourceSliceList{}
+	require.NoError(t, cli.List(ctx, slices))
+	assert.Empty(t, slices.Items)
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"os"
 	"os/exec"
 	"strconv"
+	"strings"
+	"time"
 
 	apiv1 "github.com/Azure/eno/api/v1"
 	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
@@ -17,21 +20,46 @@ type Env struct {
 	CompositionNamespace string
 	SynthesisUUID        string
 	SynthesisAttempt     int
+	PostProcessorURL     string
+	PostProcessorTimeout time.Duration
+	RequiredEndpoints    []string
+	SopsBinary           string
+
+	// DryRun compares the synthesizer's output to the composition's current synthesis instead of writing it.
+	DryRun bool
 }
 
 func LoadEnv() *Env {
 	attempt, _ := strconv.Atoi(os.Getenv("SYNTHESIS_ATTEMPT"))
+	ppTimeout, err := time.ParseDuration(os.Getenv("POST_PROCESSOR_TIMEOUT"))
+	if err != nil || ppTimeout <= 0 {
+		ppTimeout = time.Minute
+	}
+	var endpoints []string
+	if str := os.Getenv("REQUIRED_ENDPOINTS"); str != "" {
+		endpoints = strings.Split(str, ",")
+	}
 	return &Env{
 		CompositionName:      os.Getenv("COMPOSITION_NAME"),
 		CompositionNamespace: os.Getenv("COMPOSITION_NAMESPACE"),
 		SynthesisUUID:        os.Getenv("SYNTHESIS_UUID"),
 		SynthesisAttempt:     attempt,
+		PostProcessorURL:     os.Getenv("POST_PROCESSOR_URL"),
+		PostProcessorTimeout: ppTimeout,
+		RequiredEndpoints:    endpoints,
+		SopsBinary:           os.Getenv("SOPS_BINARY"),
+		DryRun:               os.Getenv("DRY_RUN") == "true",
 	}
 }
 
 type SynthesizerHandle func(context.Context, *apiv1.Synthesizer, *krmv1.ResourceList) (*krmv1.ResourceList, error)
 
 func NewExecHandler() SynthesizerHandle {
+	return NewExecHandlerWithLogs(nil)
+}
+
+// NewExecHandlerWithLogs is NewExecHandler but also copies the synthesizer's stderr to the given writer, if not nil.
+func NewExecHandlerWithLogs(logs io.Writer) SynthesizerHandle {
 	return func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
 		stdin := &bytes.Buffer{}
 		stdout := &bytes.Buffer{}
@@ -55,18 +83,16 @@ func NewExecHandler() SynthesizerHandle {
 		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
 		cmd.Stdin = stdin
 		cmd.Stderr = os.Stdout // logger uses stderr, so use stdout to avoid race condition
+		if logs != nil {
+			cmd.Stderr = io.MultiWriter(os.Stdout, logs)
+		}
 		cmd.Stdout = stdout
+		cmd.Env = append(synthesizerEnv(os.Environ()), determinismEnv(s)...)
 		err = cmd.Run()
 		if err != nil {
 			return nil, err
 		}
 
-		output := &krmv1.ResourceList{}
-		err = json.NewDecoder(stdout).Decode(output)
-		if err != nil {
-			return nil, err
-		}
-
-		return output, nil
+		return decodeOutput(stdout)
 	}
 }
@@ -0,0 +1,114 @@
+package execution
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IdentifierKind is the kind of the pseudo-resource used to pass allocated identifiers to synthesizers.
+const IdentifierKind = "Identifier"
+
+// buildIdentifierInputs allocates the composition's requested identifiers (if they haven't been already) and returns them as inputs.
+// Dry runs see the identifiers that would be allocated, but the pools are never written.
+func (e *Executor) buildIdentifierInputs(ctx context.Context, comp *apiv1.Composition, dryRun bool) ([]*unstructured.Unstructured, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	items := []*unstructured.Unstructured{}
+	for _, req := range comp.Spec.Identifiers {
+		pool := &apiv1.IdentifierPool{}
+		var value string
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			pool.Name = req.Pool
+			pool.Namespace = comp.Namespace
+			err := e.Reader.Get(ctx, client.ObjectKeyFromObject(pool), pool)
+			if err != nil {
+				return err
+			}
+
+			var allocated bool
+			value, allocated, err = allocateIdentifier(pool, comp, metav1.Now())
+			if err != nil || !allocated || dryRun {
+				return err
+			}
+			if err := e.Writer.Status().Update(ctx, pool); err != nil {
+				return err
+			}
+			logger.V(0).Info("allocated identifier", "pool", pool.Name, "value", value)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("allocating identifier %q from pool %q: %w", req.Key, req.Pool, err)
+		}
+
+		obj := &unstructured.Unstructured{Object: map[string]any{"value": value}}
+		obj.SetAPIVersion(apiv1.SchemeGroupVersion.String())
+		obj.SetKind(IdentifierKind)
+		obj.SetName(pool.Name)
+		obj.SetNamespace(pool.Namespace)
+		obj.SetAnnotations(map[string]string{"eno.azure.io/input-key": req.Key})
+		items = append(items, obj)
+	}
+	return items, nil
+}
+
+// allocateIdentifier returns the composition's value from the pool, allocating one if necessary.
+// The returned bool is true when the pool's status was modified.
+func allocateIdentifier(pool *apiv1.IdentifierPool, comp *apiv1.Composition, now metav1.Time) (string, bool, error) {
+	held := map[string]struct{}{}
+	for _, alloc := range pool.Status.Allocations {
+		if alloc.CompositionUID == comp.UID {
+			return alloc.Value, false, nil
+		}
+		held[alloc.Value] = struct{}{}
+	}
+
+	var value string
+	switch pool.Spec.Strategy {
+	case "", apiv1.IdentifierStrategySequence:
+		next := max(pool.Status.Next, pool.Spec.Start)
+		value = strconv.FormatInt(next, 10)
+		pool.Status.Next = next + 1
+
+	case apiv1.IdentifierStrategySlot:
+		for i := pool.Spec.Start; i < pool.Spec.Start+pool.Spec.Size; i++ {
+			if _, ok := held[strconv.FormatInt(i, 10)]; !ok {
+				value = strconv.FormatInt(i, 10)
+				break
+			}
+		}
+		if value == "" {
+			return "", false, fmt.Errorf("all %d slots are allocated", pool.Spec.Size)
+		}
+
+	case apiv1.IdentifierStrategyHash:
+		for attempt := 0; value == ""; attempt++ {
+			sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s/%d", comp.Namespace, comp.Name, pool.Name, attempt)))
+			candidate := hex.EncodeToString(sum[:5])
+			if _, ok := held[candidate]; !ok {
+				value = candidate
+			}
+		}
+
+	default:
+		return "", false, fmt.Errorf("unknown strategy %q", pool.Spec.Strategy)
+	}
+
+	pool.Status.Allocations = append(pool.Status.Allocations, apiv1.IdentifierAllocation{
+		Composition:    comp.Name,
+		CompositionUID: comp.UID,
+		Value:          value,
+		Allocated:      now,
+	})
+	pool.Status.Allocated = len(pool.Status.Allocations)
+	return value, true, nil
+}
@@ -0,0 +1,179 @@
+package execution
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAllocateIdentifier(t *testing.T) {
+	newComp := func(name string) *apiv1.Composition {
+		comp := &apiv1.Composition{}
+		comp.Name = name
+		comp.Namespace = "default"
+		comp.UID = types.UID(name + "-uid")
+		return comp
+	}
+	allocate := func(t *testing.T, pool *apiv1.IdentifierPool, name string) string {
+		value, _, err := allocateIdentifier(pool, newComp(name), metav1.Now())
+		require.NoError(t, err)
+		return value
+	}
+
+	t.Run("sequence", func(t *testing.T) {
+		pool := &apiv1.IdentifierPool{}
+		pool.Spec.Start = 100
+		assert.Equal(t, "100", allocate(t, pool, "a"))
+		assert.Equal(t, "101", allocate(t, pool, "b"))
+		assert.Equal(t, "100", allocate(t, pool, "a"))
+		assert.Equal(t, 2, pool.Status.Allocated)
+
+		// Released values aren't reused
+		pool.Status.Allocations = pool.Status.Allocations[1:]
+		assert.Equal(t, "102", allocate(t, pool, "a"))
+	})
+
+	t.Run("slot", func(t *testing.T) {
+		pool := &apiv1.IdentifierPool{}
+		pool.Spec.Strategy = apiv1.IdentifierStrategySlot
+		pool.Spec.Start = 1
+		pool.Spec.Size = 2
+		assert.Equal(t, "1", allocate(t, pool, "a"))
+		assert.Equal(t, "2", allocate(t, pool, "b"))
+
+		_, _, err := allocateIdentifier(pool, newComp("c"), metav1.Now())
+		assert.EqualError(t, err, "all 2 slots are allocated")
+
+		// Released values are reused
+		pool.Status.Allocations = pool.Status.Allocations[1:]
+		assert.Equal(t, "1", allocate(t, pool, "c"))
+	})
+
+	t.Run("recreated composition", func(t *testing.T) {
+		pool := &apiv1.IdentifierPool{}
+		assert.Equal(t, "0", allocate(t, pool, "a"))
+
+		// A new composition with the same name doesn't inherit the old one's value
+		comp := newComp("a")
+		comp.UID = "new-uid"
+		value, allocated, err := allocateIdentifier(pool, comp, metav1.Now())
+		require.NoError(t, err)
+		assert.True(t, allocated)
+		assert.Equal(t, "1", value)
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		pool := &apiv1.IdentifierPool{}
+		pool.Name = "test-pool"
+		pool.Spec.Strategy = apiv1.IdentifierStrategyHash
+		value := allocate(t, pool, "a")
+		assert.Len(t, value, 10)
+
+		// Stable across pools of the same name
+		other := &apiv1.IdentifierPool{}
+		other.Name = "test-pool"
+		other.Spec.Strategy = apiv1.IdentifierStrategyHash
+		assert.Equal(t, value, allocate(t, other, "a"))
+
+		// Collisions are rehashed
+		other = &apiv1.IdentifierPool{}
+		other.Name = "test-pool"
+		other.Spec.Strategy = apiv1.IdentifierStrategyHash
+		other.Status.Allocations = []apiv1.IdentifierAllocation{{Composition: "b", CompositionUID: "b-uid", Value: value}}
+		assert.NotEqual(t, value, allocate(t, other, "a"))
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		pool := &apiv1.IdentifierPool{}
+		pool.Spec.Strategy = "Random"
+		_, _, err := allocateIdentifier(pool, newComp("a"), metav1.Now())
+		assert.Error(t, err)
+	})
+}
+
+func TestIdentifierInputs(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.SchemeBuilder.AddToScheme(scheme))
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&apiv1.ResourceSlice{}, &apiv1.Composition{}, &apiv1.IdentifierPool{}).
+		Build()
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = "test-synth"
+	require.NoError(t, cli.Create(ctx, syn))
+
+	pool := &apiv1.IdentifierPool{}
+	pool.Name = "test-pool"
+	pool.Namespace = "default"
+	pool.Spec.Start = 10
+	require.NoError(t, cli.Create(ctx, pool))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Synthesizer.Name = syn.Name
+	comp.Spec.Identifiers = []apiv1.IdentifierRequest{{Key: "shard", Pool: pool.Name}}
+	require.NoError(t, cli.Create(ctx, comp))
+
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid"}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	var values []string
+	e := &Executor{
+		Reader: cli,
+		Writer: cli,
+		Handler: func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+			for _, item := range rl.Items {
+				if item.GetAnnotations()["eno.azure.io/input-key"] == "shard" {
+					assert.Equal(t, IdentifierKind, item.GetKind())
+					value, _, _ := unstructured.NestedString(item.Object, "value")
+					values = append(values, value)
+				}
+			}
+			return &krmv1.ResourceList{}, nil
+		},
+	}
+	env := &Env{
+		CompositionName:      comp.Name,
+		CompositionNamespace: comp.Namespace,
+		SynthesisUUID:        comp.Status.CurrentSynthesis.UUID,
+	}
+
+	resynthesize := func(uuid string) error {
+		require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+		comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: uuid}
+		require.NoError(t, cli.Status().Update(ctx, comp))
+		env.SynthesisUUID = uuid
+		return e.Synthesize(ctx, env)
+	}
+
+	// The value is allocated once and reused by later syntheses
+	require.NoError(t, e.Synthesize(ctx, env))
+	require.NoError(t, resynthesize("test-uuid-2"))
+	assert.Equal(t, []string{"10", "10"}, values)
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(pool), pool))
+	require.Len(t, pool.Status.Allocations, 1)
+	assert.Equal(t, comp.Name, pool.Status.Allocations[0].Composition)
+	assert.Equal(t, comp.UID, pool.Status.Allocations[0].CompositionUID)
+	assert.Equal(t, int64(11), pool.Status.Next)
+
+	// Missing pools fail the synthesis
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	comp.Spec.Identifiers = []apiv1.IdentifierRequest{{Key: "shard", Pool: "nope"}}
+	require.NoError(t, cli.Update(ctx, comp))
+	assert.Error(t, resynthesize("test-uuid-3"))
+}
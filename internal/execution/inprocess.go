@@ -0,0 +1,71 @@
+package execution
+
+import (
+	"context"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// RunPodsInProcess runs synthesizer pods in-process by calling the given handler, since envtest doesn't run pods.
+// Only used by tests.
+func RunPodsInProcess(mgr ctrl.Manager, handler SynthesizerHandle) error {
+	cli := mgr.GetAPIReader()
+	podCtrl := reconcile.Func(func(ctx context.Context, r reconcile.Request) (reconcile.Result, error) {
+		pod := &corev1.Pod{}
+		err := cli.Get(ctx, r.NamespacedName, pod)
+		if err != nil {
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+		if pod.DeletionTimestamp != nil {
+			return reconcile.Result{}, nil
+		}
+
+		env := &Env{}
+		for _, e := range pod.Spec.Containers[0].Env {
+			switch e.Name {
+			case "COMPOSITION_NAME":
+				env.CompositionName = e.Value
+			case "COMPOSITION_NAMESPACE":
+				env.CompositionNamespace = e.Value
+			case "SYNTHESIS_UUID":
+				env.SynthesisUUID = e.Value
+			case "SYNTHESIS_ATTEMPT":
+				val, _ := strconv.Atoi(e.Value)
+				env.SynthesisAttempt = val
+			}
+		}
+
+		e := &Executor{
+			Reader:  cli,
+			Writer:  mgr.GetClient(),
+			Handler: handler,
+		}
+		err = e.Synthesize(ctx, env)
+		if err != nil {
+			// Returning an error from the synth would eventually result in a timeout.
+			// To avoid waiting that long in the tests we can just delete the pod after the first try.
+			return reconcile.Result{}, mgr.GetClient().Delete(ctx, pod)
+		}
+
+		err = mgr.GetClient().Get(ctx, client.ObjectKeyFromObject(pod), pod)
+		if err != nil {
+			return reconcile.Result{}, nil
+		}
+		pod.Status.Phase = corev1.PodSucceeded
+		err = mgr.GetClient().Status().Update(ctx, pod)
+		if err != nil {
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, nil
+	})
+
+	_, err := ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Pod{}).
+		Build(podCtrl)
+	return err
+}
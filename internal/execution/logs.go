@@ -0,0 +1,99 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxLogBytes bounds the synthesizer output copied into the logs ConfigMap, well under the 1MiB object limit.
+const maxLogBytes = 1024 * 256
+
+// LogBuffer retains the tail of the synthesizer's output so it can be written upstream after synthesis.
+type LogBuffer struct {
+	mut       sync.Mutex
+	buf       []byte
+	truncated bool
+}
+
+func (l *LogBuffer) Write(p []byte) (int, error) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	l.buf = append(l.buf, p...)
+	if over := len(l.buf) - maxLogBytes; over > 0 {
+		l.buf = append(l.buf[:0], l.buf[over:]...)
+		l.truncated = true
+	}
+	return len(p), nil
+}
+
+func (l *LogBuffer) String() string {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if l.truncated {
+		return "[truncated]\n" + string(l.buf)
+	}
+	return string(l.buf)
+}
+
+// LogsConfigMapName returns the name of the ConfigMap that holds the composition's synthesizer logs.
+func LogsConfigMapName(comp *apiv1.Composition) string {
+	return comp.Name + "-synthesis-logs"
+}
+
+// writeLogs copies the synthesizer's logs into a ConfigMap owned by the composition.
+// Failures are logged but don't fail the synthesis, since logs are only a debugging aid.
+func (e *Executor) writeLogs(ctx context.Context, env *Env, comp *apiv1.Composition, syn *apiv1.Synthesizer) {
+	if e.Logs == nil || !syn.StreamLogs() {
+		return
+	}
+	logger := logr.FromContextOrDiscard(ctx)
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = LogsConfigMapName(comp)
+	cm.Namespace = comp.Namespace
+	err := e.Reader.Get(ctx, client.ObjectKeyFromObject(cm), cm)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "getting synthesizer logs configmap")
+		return
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(cm, comp) {
+		logger.V(0).Info("not writing synthesizer logs because the configmap isn't owned by the composition", "configMapName", cm.Name)
+		return
+	}
+
+	cm.Labels = map[string]string{"eno.azure.io/synthesis-logs": "true"}
+	cm.Annotations = map[string]string{
+		"eno.azure.io/synthesis-uuid":    env.SynthesisUUID,
+		"eno.azure.io/synthesis-attempt": fmt.Sprint(env.SynthesisAttempt),
+	}
+	cm.Data = map[string]string{"log": e.Logs.String()}
+	if !exists {
+		cm.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: apiv1.SchemeGroupVersion.Identifier(),
+			Kind:       "Composition",
+			Name:       comp.Name,
+			UID:        comp.UID,
+			Controller: ptr.To(true),
+		}}
+		err = e.Writer.Create(ctx, cm)
+	} else {
+		err = e.Writer.Update(ctx, cm)
+	}
+	if err != nil {
+		logger.Error(err, "writing synthesizer logs configmap")
+		return
+	}
+	logger.V(1).Info("wrote synthesizer logs", "configMapName", cm.Name)
+}
@@ -0,0 +1,97 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLogBufferTruncation(t *testing.T) {
+	l := &LogBuffer{}
+	l.Write([]byte("hello\n"))
+	assert.Equal(t, "hello\n", l.String())
+
+	l.Write([]byte(strings.Repeat("a", maxLogBytes)))
+	assert.Equal(t, "[truncated]\n"+strings.Repeat("a", maxLogBytes), l.String())
+}
+
+func TestWriteLogs(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.SchemeBuilder.AddToScheme(scheme))
+	require.NoError(t, corev1.SchemeBuilder.AddToScheme(scheme))
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&apiv1.ResourceSlice{}, &apiv1.Composition{}).
+		Build()
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = "test-synth"
+	syn.Annotations = map[string]string{apiv1.StreamLogsAnnotation: "true"}
+	require.NoError(t, cli.Create(ctx, syn))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Synthesizer.Name = syn.Name
+	require.NoError(t, cli.Create(ctx, comp))
+
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid"}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	logs := &LogBuffer{}
+	e := &Executor{
+		Reader: cli,
+		Writer: cli,
+		Logs:   logs,
+		Handler: func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+			logs.Write([]byte("something went wrong\n"))
+			return nil, errors.New("synthesizer failed")
+		},
+	}
+	env := &Env{
+		CompositionName:      comp.Name,
+		CompositionNamespace: comp.Namespace,
+		SynthesisUUID:        comp.Status.CurrentSynthesis.UUID,
+		SynthesisAttempt:     1,
+	}
+
+	// Logs are written even when the synthesizer fails
+	require.Error(t, e.Synthesize(ctx, env))
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = "test-comp-synthesis-logs"
+	cm.Namespace = comp.Namespace
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(cm), cm))
+	assert.Equal(t, "something went wrong\n", cm.Data["log"])
+	assert.Equal(t, "test-uuid", cm.Annotations["eno.azure.io/synthesis-uuid"])
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.True(t, metav1.IsControlledBy(cm, comp))
+
+	// Later attempts update the configmap
+	env.SynthesisAttempt = 2
+	require.Error(t, e.Synthesize(ctx, env))
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(cm), cm))
+	assert.Equal(t, "something went wrong\nsomething went wrong\n", cm.Data["log"])
+	assert.Equal(t, "2", cm.Annotations["eno.azure.io/synthesis-attempt"])
+
+	// Nothing is written when the synthesizer hasn't opted in
+	require.NoError(t, cli.Delete(ctx, cm))
+	syn.Annotations = nil
+	require.NoError(t, cli.Update(ctx, syn))
+	require.Error(t, e.Synthesize(ctx, env))
+	assert.True(t, apierrors.IsNotFound(cli.Get(ctx, client.ObjectKeyFromObject(cm), cm)))
+}
@@ -0,0 +1,54 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewManifestHandler returns a handler that outputs the static manifests of a passthrough composition
+// instead of running a synthesizer. The synthesizer input is ignored.
+func NewManifestHandler(reader client.Reader, comp *apiv1.Composition) SynthesizerHandle {
+	return func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+		src := comp.Spec.Manifests
+		if src == nil {
+			return nil, fmt.Errorf("composition does not have any manifests")
+		}
+
+		docs := []string{src.Inline}
+		if src.ConfigMap != "" {
+			cm := &corev1.ConfigMap{}
+			err := reader.Get(ctx, client.ObjectKey{Name: src.ConfigMap, Namespace: comp.Namespace}, cm)
+			if err != nil {
+				return nil, fmt.Errorf("fetching manifest configmap: %w", err)
+			}
+
+			keys := make([]string, 0, len(cm.Data))
+			for key := range cm.Data {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				docs = append(docs, cm.Data[key])
+			}
+		}
+
+		// Invalid manifests won't fix themselves, so fail the synthesis instead of retrying it
+		output, err := decodeOutput(strings.NewReader(strings.Join(docs, "\n---\n")))
+		if err != nil {
+			return &krmv1.ResourceList{
+				Results: []*krmv1.Result{{
+					Message:  fmt.Sprintf("invalid manifests: %s", err),
+					Severity: krmv1.ResultSeverityError,
+				}},
+			}, nil
+		}
+		return output, nil
+	}
+}
@@ -0,0 +1,93 @@
+package execution
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestManifestHandler(t *testing.T) {
+	ctx := context.Background()
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = "test-manifests"
+	cm.Namespace = "default"
+	cm.Data = map[string]string{
+		"b.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: from-b\n",
+		"a.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: from-a\n---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: from-a\n",
+	}
+	cli := fake.NewClientBuilder().WithObjects(cm).Build()
+
+	tests := []struct {
+		Name    string
+		Source  apiv1.ManifestSource
+		Names   []string
+		Failed  bool
+		Missing bool
+	}{
+		{
+			Name:   "inline",
+			Source: apiv1.ManifestSource{Inline: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"inline"}}`},
+			Names:  []string{"inline"},
+		},
+		{
+			Name:   "configmap",
+			Source: apiv1.ManifestSource{ConfigMap: cm.Name},
+			Names:  []string{"from-a", "from-a", "from-b"},
+		},
+		{
+			Name:   "inline and configmap",
+			Source: apiv1.ManifestSource{Inline: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: inline\n", ConfigMap: cm.Name},
+			Names:  []string{"inline", "from-a", "from-a", "from-b"},
+		},
+		{
+			Name:   "invalid",
+			Source: apiv1.ManifestSource{Inline: "not: [valid"},
+			Failed: true,
+		},
+		{
+			Name:   "empty",
+			Source: apiv1.ManifestSource{Inline: "---\n"},
+			Failed: true,
+		},
+		{
+			Name:    "missing configmap",
+			Source:  apiv1.ManifestSource{ConfigMap: "nope"},
+			Missing: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			comp := &apiv1.Composition{}
+			comp.Namespace = "default"
+			comp.Spec.Manifests = &tc.Source
+
+			rl, err := NewManifestHandler(cli, comp)(ctx, &apiv1.Synthesizer{}, &krmv1.ResourceList{})
+			if tc.Missing {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if tc.Failed {
+				require.Len(t, rl.Results, 1)
+				assert.Equal(t, krmv1.ResultSeverityError, rl.Results[0].Severity)
+				return
+			}
+			assert.Empty(t, rl.Results)
+
+			var names []string
+			for _, item := range rl.Items {
+				names = append(names, item.GetName())
+			}
+			assert.Equal(t, tc.Names, names)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package execution
+
+import (
+	"strings"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// podSpecReferences are the fields of a pod spec that refer to other resources by name.
+var podSpecReferences = []apiv1.NameReference{
+	{Path: "volumes[].configMap.name", TargetKind: "ConfigMap"},
+	{Path: "volumes[].secret.secretName", TargetKind: "Secret"},
+	{Path: "volumes[].persistentVolumeClaim.claimName", TargetKind: "PersistentVolumeClaim"},
+	{Path: "volumes[].projected.sources[].configMap.name", TargetKind: "ConfigMap"},
+	{Path: "volumes[].projected.sources[].secret.name", TargetKind: "Secret"},
+	{Path: "serviceAccountName", TargetKind: "ServiceAccount"},
+	{Path: "imagePullSecrets[].name", TargetKind: "Secret"},
+	{Path: "containers[].envFrom[].configMapRef.name", TargetKind: "ConfigMap"},
+	{Path: "containers[].envFrom[].secretRef.name", TargetKind: "Secret"},
+	{Path: "containers[].env[].valueFrom.configMapKeyRef.name", TargetKind: "ConfigMap"},
+	{Path: "containers[].env[].valueFrom.secretKeyRef.name", TargetKind: "Secret"},
+	{Path: "initContainers[].envFrom[].configMapRef.name", TargetKind: "ConfigMap"},
+	{Path: "initContainers[].envFrom[].secretRef.name", TargetKind: "Secret"},
+	{Path: "initContainers[].env[].valueFrom.configMapKeyRef.name", TargetKind: "ConfigMap"},
+	{Path: "initContainers[].env[].valueFrom.secretKeyRef.name", TargetKind: "Secret"},
+}
+
+// podSpecPaths locates the pod spec within built in workload kinds.
+var podSpecPaths = map[string]string{
+	"Pod":         "spec",
+	"Deployment":  "spec.template.spec",
+	"StatefulSet": "spec.template.spec",
+	"DaemonSet":   "spec.template.spec",
+	"ReplicaSet":  "spec.template.spec",
+	"Job":         "spec.template.spec",
+	"CronJob":     "spec.jobTemplate.spec.template.spec",
+}
+
+type nameKey struct {
+	Kind, Name string
+}
+
+// applyNameTransform renames the given objects per the composition's name transform,
+// along with any references to them from the other objects.
+func applyNameTransform(comp *apiv1.Composition, objs []*unstructured.Unstructured) {
+	nt := comp.Spec.NameTransform
+	if nt == nil || (nt.Prefix == "" && nt.Suffix == "") {
+		return
+	}
+
+	renamed := map[nameKey]string{}
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		if gvk.Group == "eno.azure.io" && gvk.Kind == "Patch" {
+			continue // patches modify resources that aren't managed by this composition
+		}
+		if name := obj.GetName(); name != "" {
+			next := nt.Prefix + name + nt.Suffix
+			renamed[nameKey{Kind: gvk.Kind, Name: name}] = next
+			obj.SetName(next)
+		} else if gen := obj.GetGenerateName(); gen != "" {
+			obj.SetGenerateName(nt.Prefix + gen) // the suffix is generated
+		}
+	}
+
+	for _, obj := range objs {
+		kind := obj.GetKind()
+		if prefix, ok := podSpecPaths[kind]; ok {
+			for _, ref := range podSpecReferences {
+				renameReferences(obj.Object, splitPath(prefix+"."+ref.Path), ref.TargetKind, renamed)
+			}
+		}
+		for _, ref := range nt.References {
+			if ref.Kind == kind {
+				renameReferences(obj.Object, splitPath(ref.Path), ref.TargetKind, renamed)
+			}
+		}
+	}
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.ReplaceAll(path, "[]", ".[]"), ".")
+}
+
+// renameReferences walks the given path, replacing names that refer to renamed resources of the target kind.
+func renameReferences(val any, path []string, targetKind string, renamed map[nameKey]string) {
+	if len(path) == 0 {
+		return
+	}
+	if path[0] == "[]" {
+		list, ok := val.([]any)
+		if !ok {
+			return
+		}
+		for _, item := range list {
+			renameReferences(item, path[1:], targetKind, renamed)
+		}
+		return
+	}
+
+	obj, ok := val.(map[string]any)
+	if !ok {
+		return
+	}
+	if len(path) > 1 {
+		renameReferences(obj[path[0]], path[1:], targetKind, renamed)
+		return
+	}
+	name, ok := obj[path[0]].(string)
+	if !ok {
+		return
+	}
+	if next, ok := renamed[nameKey{Kind: targetKind, Name: name}]; ok {
+		obj[path[0]] = next
+	}
+}
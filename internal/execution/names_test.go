@@ -0,0 +1,82 @@
+package execution
+
+import (
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestApplyNameTransform(t *testing.T) {
+	comp := &apiv1.Composition{}
+	comp.Spec.NameTransform = &apiv1.NameTransform{
+		Prefix:     "a-",
+		Suffix:     "-z",
+		References: []apiv1.NameReference{{Kind: "Example", Path: "spec.refs[].name", TargetKind: "ConfigMap"}},
+	}
+
+	objs := []*unstructured.Unstructured{
+		{Object: map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": "config"}}},
+		{Object: map[string]any{"apiVersion": "v1", "kind": "Secret", "metadata": map[string]any{"name": "secret"}}},
+		{Object: map[string]any{"apiVersion": "batch/v1", "kind": "Job", "metadata": map[string]any{"generateName": "job-"}}},
+		{Object: map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]any{"name": "deploy"},
+			"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+				"volumes": []any{
+					map[string]any{"configMap": map[string]any{"name": "config"}},
+					map[string]any{"configMap": map[string]any{"name": "external"}},
+					map[string]any{"secret": map[string]any{"secretName": "secret"}},
+				},
+				"containers": []any{map[string]any{
+					"envFrom": []any{map[string]any{"secretRef": map[string]any{"name": "secret"}}},
+				}},
+			}}},
+		}},
+		{Object: map[string]any{
+			"apiVersion": "example.com/v1",
+			"kind":       "Example",
+			"metadata":   map[string]any{"name": "example"},
+			"spec": map[string]any{"refs": []any{
+				map[string]any{"name": "config"},
+				map[string]any{"name": "secret"}, // wrong kind
+			}},
+		}},
+		{Object: map[string]any{
+			"apiVersion": "eno.azure.io/v1",
+			"kind":       "Patch",
+			"metadata":   map[string]any{"name": "config"},
+		}},
+	}
+	applyNameTransform(comp, objs)
+
+	assert.Equal(t, "a-config-z", objs[0].GetName())
+	assert.Equal(t, "a-secret-z", objs[1].GetName())
+	assert.Equal(t, "a-job-", objs[2].GetGenerateName())
+	assert.Equal(t, "a-deploy-z", objs[3].GetName())
+	assert.Equal(t, "a-example-z", objs[4].GetName())
+	assert.Equal(t, "config", objs[5].GetName())
+
+	volumes, _, _ := unstructured.NestedSlice(objs[3].Object, "spec", "template", "spec", "volumes")
+	assert.Equal(t, "a-config-z", volumes[0].(map[string]any)["configMap"].(map[string]any)["name"])
+	assert.Equal(t, "external", volumes[1].(map[string]any)["configMap"].(map[string]any)["name"])
+	assert.Equal(t, "a-secret-z", volumes[2].(map[string]any)["secret"].(map[string]any)["secretName"])
+
+	containers, _, _ := unstructured.NestedSlice(objs[3].Object, "spec", "template", "spec", "containers")
+	envFrom := containers[0].(map[string]any)["envFrom"].([]any)
+	assert.Equal(t, "a-secret-z", envFrom[0].(map[string]any)["secretRef"].(map[string]any)["name"])
+
+	refs, _, _ := unstructured.NestedSlice(objs[4].Object, "spec", "refs")
+	assert.Equal(t, "a-config-z", refs[0].(map[string]any)["name"])
+	assert.Equal(t, "secret", refs[1].(map[string]any)["name"])
+}
+
+func TestApplyNameTransformDisabled(t *testing.T) {
+	objs := []*unstructured.Unstructured{
+		{Object: map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": "config"}}},
+	}
+	applyNameTransform(&apiv1.Composition{}, objs)
+	assert.Equal(t, "config", objs[0].GetName())
+}
@@ -0,0 +1,139 @@
+package execution
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// decodeOutput parses synthesizer output, which is either a single KRM ResourceList
+// or a stream of JSON/YAML documents (e.g. the output of `helm template` or `kustomize build`).
+func decodeOutput(r io.Reader) (*krmv1.ResourceList, error) {
+	output := &krmv1.ResourceList{}
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	var docs int
+	var resourceList bool
+	for i := 0; ; i++ {
+		raw := json.RawMessage{}
+		err := decoder.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding document %d: %w", i, err)
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue // empty documents are common in templated yaml
+		}
+
+		meta := &metav1.TypeMeta{}
+		if err := json.Unmarshal(raw, meta); err != nil {
+			return nil, fmt.Errorf("decoding document %d: %w", i, err)
+		}
+		// Documents without a kind are ResourceLists written by synthesizers that don't set it
+		if meta.Kind == krmv1.ResourceListKind || meta.Kind == "" || resourceList {
+			if docs > 0 {
+				return nil, fmt.Errorf("document %d: a ResourceList must be the only document in the output", i)
+			}
+			if err := json.Unmarshal(raw, output); err != nil {
+				return nil, fmt.Errorf("decoding resource list: %w", err)
+			}
+			resourceList = true
+			docs++
+			continue
+		}
+		docs++
+
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw); err != nil {
+			return nil, fmt.Errorf("decoding document %d: %w", i, err)
+		}
+		output.Items = append(output.Items, obj)
+	}
+
+	// Guard against accidentally deleting every resource when the synthesizer silently produces nothing
+	if !resourceList && len(output.Items) == 0 {
+		return nil, errors.New("synthesizer did not produce any output")
+	}
+	if output.Kind == "" {
+		output.APIVersion = krmv1.SchemeGroupVersion.String()
+		output.Kind = krmv1.ResourceListKind
+	}
+	return output, nil
+}
+
+// flattenOutput expands List kinds (e.g. v1 List) into their items and reports
+// malformed resources as error results, which fail the synthesis.
+func flattenOutput(rl *krmv1.ResourceList) {
+	items := make([]*unstructured.Unstructured, 0, len(rl.Items))
+	var flatten func(obj *unstructured.Unstructured, index string)
+	flatten = func(obj *unstructured.Unstructured, index string) {
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				rl.Results = append(rl.Results, &krmv1.Result{
+					Message:  fmt.Sprintf("output item %s is an invalid list: %s", index, err),
+					Severity: krmv1.ResultSeverityError,
+				})
+				return
+			}
+			for i := range list.Items {
+				flatten(&list.Items[i], fmt.Sprintf("%s.%d", index, i))
+			}
+			return
+		}
+
+		msg := validateOutputItem(obj)
+		if msg != "" {
+			// In-process handlers may use typed maps (e.g. map[string]string metadata) that aren't supported by unstructured's accessors
+			if normalized, err := normalizeOutputItem(obj); err == nil {
+				obj = normalized
+				msg = validateOutputItem(obj)
+			}
+		}
+		if msg != "" {
+			rl.Results = append(rl.Results, &krmv1.Result{
+				Message:  fmt.Sprintf("output item %s %s", index, msg),
+				Severity: krmv1.ResultSeverityError,
+			})
+			return
+		}
+		items = append(items, obj)
+	}
+
+	for i, item := range rl.Items {
+		if item == nil {
+			continue
+		}
+		flatten(item, fmt.Sprint(i))
+	}
+	rl.Items = items
+}
+
+func normalizeOutputItem(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	js, err := json.Marshal(obj.Object)
+	if err != nil {
+		return nil, err
+	}
+	normalized := &unstructured.Unstructured{}
+	return normalized, normalized.UnmarshalJSON(js)
+}
+
+func validateOutputItem(obj *unstructured.Unstructured) string {
+	switch {
+	case obj.GetAPIVersion() == "":
+		return "is missing apiVersion"
+	case obj.GetKind() == "":
+		return "is missing kind"
+	case obj.GetName() == "" && obj.GetGenerateName() == "":
+		return fmt.Sprintf("(%s) is missing metadata.name", obj.GetKind())
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,121 @@
+package execution
+
+import (
+	"strings"
+	"testing"
+
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDecodeOutput(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Input string
+		Names []string
+		Error string
+	}{
+		{
+			Name:  "resource list",
+			Input: `{"apiVersion":"config.kubernetes.io/v1","kind":"ResourceList","items":[{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}]}`,
+			Names: []string{"foo"},
+		},
+		{
+			Name:  "resource list without kind",
+			Input: `{"items":[{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}]}`,
+			Names: []string{"foo"},
+		},
+		{
+			Name:  "empty resource list",
+			Input: `{"apiVersion":"config.kubernetes.io/v1","kind":"ResourceList","items":[]}`,
+		},
+		{
+			Name: "yaml stream",
+			Input: `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+---
+# empty document
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: bar
+`,
+			Names: []string{"foo", "bar"},
+		},
+		{
+			Name:  "json stream",
+			Input: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}` + "\n" + `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"bar"}}`,
+			Names: []string{"foo", "bar"},
+		},
+		{
+			Name:  "resource list in stream",
+			Input: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n---\napiVersion: config.kubernetes.io/v1\nkind: ResourceList\nitems: []\n",
+			Error: "document 1: a ResourceList must be the only document in the output",
+		},
+		{
+			Name:  "stream after resource list",
+			Input: "apiVersion: config.kubernetes.io/v1\nkind: ResourceList\nitems: []\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n",
+			Error: "document 1: a ResourceList must be the only document in the output",
+		},
+		{
+			Name:  "empty",
+			Input: "---\n",
+			Error: "synthesizer did not produce any output",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			rl, err := decodeOutput(strings.NewReader(tc.Input))
+			if tc.Error != "" {
+				require.EqualError(t, err, tc.Error)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, krmv1.ResourceListKind, rl.Kind)
+
+			var names []string
+			for _, item := range rl.Items {
+				names = append(names, item.GetName())
+			}
+			assert.Equal(t, tc.Names, names)
+		})
+	}
+}
+
+func TestFlattenOutput(t *testing.T) {
+	cm := func(name string) map[string]any {
+		return map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": name}}
+	}
+	rl := &krmv1.ResourceList{Items: []*unstructured.Unstructured{
+		{Object: cm("foo")},
+		{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items": []any{
+				cm("bar"),
+				map[string]any{"apiVersion": "v1", "kind": "List", "items": []any{cm("baz")}},
+				map[string]any{"apiVersion": "v1", "kind": "ConfigMap"},
+			},
+		}},
+		{Object: map[string]any{"kind": "ConfigMap", "metadata": map[string]any{"name": "nope"}}},
+	}}
+	flattenOutput(rl)
+
+	var names []string
+	for _, item := range rl.Items {
+		names = append(names, item.GetName())
+	}
+	assert.Equal(t, []string{"foo", "bar", "baz"}, names)
+
+	require.Len(t, rl.Results, 2)
+	assert.Equal(t, "output item 1.2 (ConfigMap) is missing metadata.name", rl.Results[0].Message)
+	assert.Equal(t, "output item 2 is missing apiVersion", rl.Results[1].Message)
+	assert.Equal(t, "error", rl.Results[0].Severity)
+}
@@ -0,0 +1,63 @@
+package execution
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+)
+
+// NewHTTPPostProcessor returns a handle that POSTs the complete synthesizer output to an external service
+// and uses the ResourceList it returns in place of the original output.
+//
+// This allows central policy/mutation services to see (and modify) the entire set of synthesized
+// resources before they are written to resource slices.
+func NewHTTPPostProcessor(url string, timeout time.Duration) SynthesizerHandle {
+	client := &http.Client{Timeout: timeout}
+	return func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+		body := &bytes.Buffer{}
+		err := json.NewEncoder(body).Encode(rl)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, msg)
+		}
+
+		output := &krmv1.ResourceList{}
+		err = json.NewDecoder(resp.Body).Decode(output)
+		if err != nil {
+			return nil, fmt.Errorf("decoding response: %w", err)
+		}
+
+		// An empty response is more likely a broken post-processor than an intentional deletion of every resource
+		if len(output.Items) == 0 && len(rl.Items) > 0 {
+			return nil, fmt.Errorf("post-processor returned no items (%d were sent)", len(rl.Items))
+		}
+
+		// Results emitted by the synthesizer shouldn't be lost just because the post-processor didn't echo them back
+		output.Results = append(rl.Results, output.Results...)
+
+		return output, nil
+	}
+}
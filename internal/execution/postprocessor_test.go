@@ -0,0 +1,80 @@
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestHTTPPostProcessor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rl := &krmv1.ResourceList{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(rl))
+		for _, item := range rl.Items {
+			item.SetLabels(map[string]string{"mutated": "true"})
+		}
+		rl.Results = []*krmv1.Result{{Message: "from post-processor"}}
+		require.NoError(t, json.NewEncoder(w).Encode(rl))
+	}))
+	defer srv.Close()
+
+	handle := NewHTTPPostProcessor(srv.URL, time.Second*10)
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName("test")
+	rl := &krmv1.ResourceList{
+		Items:   []*unstructured.Unstructured{obj},
+		Results: []*krmv1.Result{{Message: "from synthesizer"}},
+	}
+
+	out, err := handle(context.Background(), &apiv1.Synthesizer{}, rl)
+	require.NoError(t, err)
+	require.Len(t, out.Items, 1)
+	assert.Equal(t, map[string]string{"mutated": "true"}, out.Items[0].GetLabels())
+	require.Len(t, out.Results, 2)
+	assert.Equal(t, "from synthesizer", out.Results[0].Message)
+	assert.Equal(t, "from post-processor", out.Results[1].Message)
+}
+
+func TestHTTPPostProcessorError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("denied by policy"))
+	}))
+	defer srv.Close()
+
+	handle := NewHTTPPostProcessor(srv.URL, time.Second*10)
+	_, err := handle(context.Background(), &apiv1.Synthesizer{}, &krmv1.ResourceList{})
+	require.EqualError(t, err, "unexpected status 403: denied by policy")
+}
+
+func TestHTTPPostProcessorEmptyResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	handle := NewHTTPPostProcessor(srv.URL, time.Second*10)
+
+	// Empty input can result in empty output
+	_, err := handle(context.Background(), &apiv1.Synthesizer{}, &krmv1.ResourceList{})
+	require.NoError(t, err)
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName("test")
+	_, err = handle(context.Background(), &apiv1.Synthesizer{}, &krmv1.ResourceList{Items: []*unstructured.Unstructured{obj}})
+	require.EqualError(t, err, "post-processor returned no items (1 were sent)")
+}
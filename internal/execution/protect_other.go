@@ -0,0 +1,6 @@
+//go:build !linux
+
+package execution
+
+// ProtectEnv is a no-op outside of Linux, where synthesizer pods don't run.
+func ProtectEnv() error { return nil }
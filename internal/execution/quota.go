@@ -0,0 +1,49 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// checkResourceQuota fails the synthesis when its resources would exceed the MaxResources limit of any EnoQuota in the composition's namespace.
+// Returns true when the quota has been exceeded, in which case the output shouldn't be written to resource slices.
+//
+// Usage is taken from the quota's status, which counts the composition's previous synthesis - not the output of this one.
+// So it's eventually consistent: concurrent syntheses in the same namespace can briefly exceed the limit together.
+func (e *Executor) checkResourceQuota(ctx context.Context, comp *apiv1.Composition, previous []*apiv1.ResourceSlice, rl *krmv1.ResourceList) (bool, error) {
+	quotas := &apiv1.EnoQuotaList{}
+	err := e.Reader.List(ctx, quotas, client.InNamespace(comp.Namespace))
+	if err != nil {
+		return false, fmt.Errorf("listing quotas: %w", err)
+	}
+
+	var prev int64
+	for _, slice := range previous {
+		for _, res := range slice.Spec.Resources {
+			if !res.Deleted {
+				prev++
+			}
+		}
+	}
+
+	for _, quota := range quotas.Items {
+		limit := quota.Spec.MaxResources
+		if limit == nil {
+			continue
+		}
+		usage := max(quota.Status.Resources-prev, 0) + int64(len(rl.Items))
+		if usage <= *limit {
+			continue
+		}
+		rl.Results = append(rl.Results, &krmv1.Result{
+			Message:  fmt.Sprintf("synthesized %d resources, which would exceed the limit of %d resources set by quota %q (%d in use by other compositions)", len(rl.Items), *limit, quota.Name, usage-int64(len(rl.Items))),
+			Severity: krmv1.ResultSeverityError,
+		})
+		return true, nil
+	}
+	return false, nil
+}
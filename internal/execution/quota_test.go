@@ -0,0 +1,103 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResourceQuota(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.SchemeBuilder.AddToScheme(scheme))
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&apiv1.ResourceSlice{}, &apiv1.Composition{}).
+		Build()
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = "test-synth"
+	require.NoError(t, cli.Create(ctx, syn))
+
+	quota := &apiv1.EnoQuota{}
+	quota.Name = "test-quota"
+	quota.Namespace = "default"
+	quota.Spec.MaxResources = ptr.To(int64(3))
+	quota.Status.Resources = 3 // one of which belongs to the composition
+	require.NoError(t, cli.Create(ctx, quota))
+
+	// The previous synthesis held one resource (and a tombstone, which doesn't count)
+	prev := &apiv1.ResourceSlice{}
+	prev.Name = "test-comp-prev"
+	prev.Namespace = "default"
+	prev.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"test-0","namespace":"default"}}`},
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"deleted","namespace":"default"}}`, Deleted: true},
+	}
+	require.NoError(t, cli.Create(ctx, prev))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Synthesizer.Name = syn.Name
+	require.NoError(t, cli.Create(ctx, comp))
+
+	comp.Status.PreviousSynthesis = &apiv1.Synthesis{UUID: "prev-uuid", ResourceSlices: []*apiv1.ResourceSliceRef{{Name: prev.Name}}}
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid"}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	var outputs int
+	e := &Executor{
+		Reader: cli,
+		Writer: cli,
+		Handler: func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+			out := &krmv1.ResourceList{}
+			for i := 0; i < outputs; i++ {
+				obj := &unstructured.Unstructured{}
+				obj.SetAPIVersion("v1")
+				obj.SetKind("ConfigMap")
+				obj.SetName(fmt.Sprintf("test-%d", i))
+				obj.SetNamespace("default")
+				out.Items = append(out.Items, obj)
+			}
+			return out, nil
+		},
+	}
+	env := &Env{
+		CompositionName:      comp.Name,
+		CompositionNamespace: comp.Namespace,
+		SynthesisUUID:        comp.Status.CurrentSynthesis.UUID,
+	}
+
+	// Exceeding the quota fails the synthesis without writing any slices
+	outputs = 2
+	require.NoError(t, e.Synthesize(ctx, env))
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.NotNil(t, comp.Status.CurrentSynthesis.Synthesized)
+	assert.True(t, comp.Status.CurrentSynthesis.Failed())
+	assert.Empty(t, comp.Status.CurrentSynthesis.ResourceSlices)
+
+	// Replacing the previous resource fits within the quota
+	outputs = 1
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "next-uuid"}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+	env.SynthesisUUID = comp.Status.CurrentSynthesis.UUID
+	require.NoError(t, e.Synthesize(ctx, env))
+
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.NotNil(t, comp.Status.CurrentSynthesis.Synthesized)
+	assert.False(t, comp.Status.CurrentSynthesis.Failed())
+	assert.Len(t, comp.Status.CurrentSynthesis.ResourceSlices, 1)
+}
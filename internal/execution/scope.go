@@ -0,0 +1,61 @@
+package execution
+
+import (
+	"fmt"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// checkScopes uses discovery to validate the namespace of each synthesized resource before it's written to resource slices.
+// Namespaced resources that don't specify a namespace are defaulted to the composition's target namespace (if any)
+// or the composition's own namespace, and cluster-scoped resources that set a namespace are reported as error results.
+//
+// Types that aren't known to discovery (e.g. those defined by CRDs in the same synthesis) can't be checked.
+func (e *Executor) checkScopes(comp *apiv1.Composition, rl *krmv1.ResourceList) error {
+	if e.Mapper == nil {
+		return nil
+	}
+
+	for _, obj := range rl.Items {
+		gvk := targetGVK(obj)
+		mapping, err := e.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("getting rest mapping for %s: %w", gvk, err)
+		}
+
+		ns := obj.GetNamespace()
+		switch {
+		case mapping.Scope.Name() == meta.RESTScopeNameNamespace:
+			if target := comp.Spec.TargetNamespace; target != nil && (ns == "" || target.Override) {
+				obj.SetNamespace(target.Name)
+			} else if ns == "" {
+				obj.SetNamespace(comp.Namespace)
+			}
+
+		case mapping.Scope.Name() == meta.RESTScopeNameRoot && ns != "":
+			rl.Results = append(rl.Results, &krmv1.Result{
+				Message:  fmt.Sprintf("%s %s is cluster-scoped but sets metadata.namespace to %q", gvk.Kind, obj.GetName(), ns),
+				Severity: krmv1.ResultSeverityError,
+			})
+		}
+	}
+	return nil
+}
+
+// targetGVK returns the GVK of the resource, or the resource it modifies in the case of Eno patches.
+func targetGVK(obj *unstructured.Unstructured) schema.GroupVersionKind {
+	gvk := obj.GroupVersionKind()
+	if gvk.Group != "eno.azure.io" || gvk.Kind != "Patch" {
+		return gvk
+	}
+	apiVersion, _, _ := unstructured.NestedString(obj.Object, "patch", "apiVersion")
+	kind, _, _ := unstructured.NestedString(obj.Object, "patch", "kind")
+	return schema.FromAPIVersionAndKind(apiVersion, kind)
+}
@@ -0,0 +1,87 @@
+package execution
+
+import (
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCheckScopes(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	e := &Executor{Mapper: mapper}
+
+	comp := &apiv1.Composition{}
+	comp.Namespace = "comp-ns"
+
+	newObj := func(apiVersion, kind, ns string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName("test")
+		obj.SetNamespace(ns)
+		return obj
+	}
+	patch := newObj("eno.azure.io/v1", "Patch", "")
+	patch.Object["patch"] = map[string]any{"apiVersion": "v1", "kind": "ConfigMap"}
+
+	rl := &krmv1.ResourceList{Items: []*unstructured.Unstructured{
+		newObj("v1", "ConfigMap", ""),
+		newObj("v1", "ConfigMap", "other-ns"),
+		newObj("v1", "Namespace", ""),
+		newObj("v1", "Namespace", "other-ns"),
+		newObj("example.com/v1", "Unknown", ""),
+		patch,
+	}}
+	require.NoError(t, e.checkScopes(comp, rl))
+
+	assert.Equal(t, "comp-ns", rl.Items[0].GetNamespace())
+	assert.Equal(t, "other-ns", rl.Items[1].GetNamespace())
+	assert.Equal(t, "", rl.Items[2].GetNamespace())
+	assert.Equal(t, "", rl.Items[4].GetNamespace())
+	assert.Equal(t, "comp-ns", rl.Items[5].GetNamespace())
+
+	require.Len(t, rl.Results, 1)
+	assert.Equal(t, `Namespace test is cluster-scoped but sets metadata.namespace to "other-ns"`, rl.Results[0].Message)
+	assert.Equal(t, krmv1.ResultSeverityError, rl.Results[0].Severity)
+}
+
+func TestCheckScopesTargetNamespace(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	e := &Executor{Mapper: mapper}
+
+	newList := func() *krmv1.ResourceList {
+		return &krmv1.ResourceList{Items: []*unstructured.Unstructured{
+			{Object: map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": "a"}}},
+			{Object: map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": "b", "namespace": "other-ns"}}},
+			{Object: map[string]any{"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]any{"name": "c"}}},
+		}}
+	}
+
+	comp := &apiv1.Composition{}
+	comp.Namespace = "comp-ns"
+	comp.Spec.TargetNamespace = &apiv1.TargetNamespace{Name: "target-ns"}
+
+	rl := newList()
+	require.NoError(t, e.checkScopes(comp, rl))
+	assert.Equal(t, "target-ns", rl.Items[0].GetNamespace())
+	assert.Equal(t, "other-ns", rl.Items[1].GetNamespace())
+	assert.Equal(t, "", rl.Items[2].GetNamespace())
+
+	comp.Spec.TargetNamespace.Override = true
+	rl = newList()
+	require.NoError(t, e.checkScopes(comp, rl))
+	assert.Equal(t, "target-ns", rl.Items[0].GetNamespace())
+	assert.Equal(t, "target-ns", rl.Items[1].GetNamespace())
+	assert.Equal(t, "", rl.Items[2].GetNamespace())
+	assert.Empty(t, rl.Results)
+}
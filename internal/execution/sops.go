@@ -0,0 +1,135 @@
+package execution
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SopsEncryptedAnnotation marks ConfigMap and Secret inputs whose data values are SOPS-encrypted documents.
+const SopsEncryptedAnnotation = "eno.azure.io/sops-encrypted"
+
+// SopsKeyEnvPrefix prefixes the executor's environment variables that hold keys for sops e.g. ENO_SOPS_KEY_SOPS_AGE_KEY.
+// They're passed to sops without the prefix, and never to synthesizers, since the keys can decrypt the inputs of
+// every composition.
+const SopsKeyEnvPrefix = "ENO_SOPS_KEY_"
+
+// Decrypter decrypts a single SOPS-encrypted document of the given format (json, yaml, dotenv, ini, or binary).
+type Decrypter func(ctx context.Context, format string, ciphertext []byte) ([]byte, error)
+
+// NewSopsDecrypter returns a Decrypter that executes the sops binary at the given path.
+// Keys are configured through the process's environment e.g. SOPS_AGE_KEY or cloud KMS credentials,
+// optionally prefixed with SopsKeyEnvPrefix to keep them from synthesizers.
+func NewSopsDecrypter(binary string) Decrypter {
+	return func(ctx context.Context, format string, ciphertext []byte) ([]byte, error) {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+
+		cmd := exec.CommandContext(ctx, binary, "--decrypt", "--input-type", format, "--output-type", format, "/dev/stdin")
+		cmd.Env = sopsEnv(os.Environ())
+		cmd.Stdin = bytes.NewReader(ciphertext)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return stdout.Bytes(), nil
+	}
+}
+
+// decryptInput decrypts every data value of the given input in place if it's annotated as SOPS-encrypted.
+func (e *Executor) decryptInput(ctx context.Context, obj *unstructured.Unstructured) error {
+	if obj.GetAnnotations()[SopsEncryptedAnnotation] != "true" {
+		return nil
+	}
+	if e.Decrypter == nil {
+		return fmt.Errorf("input is SOPS-encrypted but decryption is not enabled")
+	}
+
+	gvk := obj.GroupVersionKind()
+	switch {
+	case gvk.Group == "" && gvk.Kind == "ConfigMap":
+		if err := e.decryptField(ctx, obj, "data", false); err != nil {
+			return err
+		}
+		return e.decryptField(ctx, obj, "binaryData", true)
+	case gvk.Group == "" && gvk.Kind == "Secret":
+		return e.decryptField(ctx, obj, "data", true)
+	default:
+		return fmt.Errorf("SOPS decryption is only supported for ConfigMaps and Secrets")
+	}
+}
+
+func (e *Executor) decryptField(ctx context.Context, obj *unstructured.Unstructured, field string, encoded bool) error {
+	data, _, err := unstructured.NestedStringMap(obj.Object, field)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", field, err)
+	}
+	for key, val := range data {
+		ciphertext := []byte(val)
+		if encoded {
+			ciphertext, err = base64.StdEncoding.DecodeString(val)
+			if err != nil {
+				return fmt.Errorf("decoding %s key %q: %w", field, key, err)
+			}
+		}
+
+		plaintext, err := e.Decrypter(ctx, sopsFormat(key), ciphertext)
+		if err != nil {
+			return fmt.Errorf("decrypting %s key %q: %w", field, key, err)
+		}
+
+		if encoded {
+			data[key] = base64.StdEncoding.EncodeToString(plaintext)
+		} else {
+			data[key] = string(plaintext)
+		}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return unstructured.SetNestedStringMap(obj.Object, data, field)
+}
+
+// sopsFormat infers the format of a SOPS document from the file extension of its data key, like the sops cli.
+func sopsFormat(key string) string {
+	switch path.Ext(key) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".env":
+		return "dotenv"
+	case ".ini":
+		return "ini"
+	default:
+		return "binary"
+	}
+}
+
+// sopsEnv returns the given environment with the prefix removed from sops keys.
+func sopsEnv(environ []string) []string {
+	env := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		env = append(env, strings.TrimPrefix(kv, SopsKeyEnvPrefix))
+	}
+	return env
+}
+
+// synthesizerEnv returns the given environment without sops keys.
+func synthesizerEnv(environ []string) []string {
+	env := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		if !strings.HasPrefix(kv, SopsKeyEnvPrefix) {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
@@ -0,0 +1,135 @@
+package execution
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+)
+
+func TestDecryptInput(t *testing.T) {
+	ctx := context.Background()
+	formats := map[string]string{}
+	e := &Executor{
+		Decrypter: func(ctx context.Context, format string, ciphertext []byte) ([]byte, error) {
+			if !strings.HasPrefix(string(ciphertext), "ENC:") {
+				return nil, errors.New("not encrypted")
+			}
+			formats[string(ciphertext)] = format
+			return []byte(strings.TrimPrefix(string(ciphertext), "ENC:")), nil
+		},
+	}
+
+	t.Run("configmap", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"name":        "test",
+				"annotations": map[string]any{SopsEncryptedAnnotation: "true"},
+			},
+			"data":       map[string]any{"values.yaml": "ENC:foo: bar", "config.json": "ENC:{}"},
+			"binaryData": map[string]any{"blob": base64.StdEncoding.EncodeToString([]byte("ENC:blob"))},
+		}}
+		require.NoError(t, e.decryptInput(ctx, obj))
+
+		data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+		assert.Equal(t, map[string]string{"values.yaml": "foo: bar", "config.json": "{}"}, data)
+		binData, _, _ := unstructured.NestedStringMap(obj.Object, "binaryData")
+		assert.Equal(t, map[string]string{"blob": base64.StdEncoding.EncodeToString([]byte("blob"))}, binData)
+		assert.Equal(t, map[string]string{"ENC:foo: bar": "yaml", "ENC:{}": "json", "ENC:blob": "binary"}, formats)
+	})
+
+	t.Run("secret", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]any{
+				"name":        "test",
+				"annotations": map[string]any{SopsEncryptedAnnotation: "true"},
+			},
+			"data": map[string]any{"token": base64.StdEncoding.EncodeToString([]byte("ENC:secret"))},
+		}}
+		require.NoError(t, e.decryptInput(ctx, obj))
+
+		data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+		assert.Equal(t, map[string]string{"token": base64.StdEncoding.EncodeToString([]byte("secret"))}, data)
+	})
+
+	t.Run("not annotated", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"data":       map[string]any{"foo": "bar"},
+		}}
+		require.NoError(t, e.decryptInput(ctx, obj))
+
+		data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+		assert.Equal(t, map[string]string{"foo": "bar"}, data)
+	})
+
+	t.Run("decryption error", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"annotations": map[string]any{SopsEncryptedAnnotation: "true"},
+			},
+			"data": map[string]any{"foo": "plaintext"},
+		}}
+		assert.ErrorContains(t, e.decryptInput(ctx, obj), `decrypting data key "foo": not encrypted`)
+	})
+
+	t.Run("unsupported kind", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "example.com/v1",
+			"kind":       "Example",
+			"metadata": map[string]any{
+				"annotations": map[string]any{SopsEncryptedAnnotation: "true"},
+			},
+		}}
+		assert.Error(t, e.decryptInput(ctx, obj))
+	})
+
+	t.Run("decryption disabled", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"annotations": map[string]any{SopsEncryptedAnnotation: "true"},
+			},
+		}}
+		assert.ErrorContains(t, (&Executor{}).decryptInput(ctx, obj), "decryption is not enabled")
+	})
+}
+
+func TestSopsKeyEnv(t *testing.T) {
+	t.Setenv("ENO_SOPS_KEY_SOPS_AGE_KEY", "test-key")
+	t.Setenv("TEST_UNRELATED", "visible")
+
+	// sops receives the key without the prefix
+	binary := filepath.Join(t.TempDir(), "sops")
+	require.NoError(t, os.WriteFile(binary, []byte("#!/bin/sh\nprintf %s \"$SOPS_AGE_KEY\"\n"), 0755))
+	out, err := NewSopsDecrypter(binary)(context.Background(), "json", []byte("{}"))
+	require.NoError(t, err)
+	assert.Equal(t, "test-key", string(out))
+
+	// The synthesizer doesn't receive it at all
+	syn := &apiv1.Synthesizer{}
+	syn.Spec.Command = []string{"/bin/sh", "-c", `echo "{\"apiVersion\":\"config.kubernetes.io/v1\",\"kind\":\"ResourceList\",\"items\":[{\"apiVersion\":\"v1\",\"kind\":\"ConfigMap\",\"metadata\":{\"name\":\"test\"},\"data\":{\"key\":\"$ENO_SOPS_KEY_SOPS_AGE_KEY$SOPS_AGE_KEY\",\"other\":\"$TEST_UNRELATED\"}}]}"`}
+	rl, err := NewExecHandler()(context.Background(), syn, &krmv1.ResourceList{})
+	require.NoError(t, err)
+	require.Len(t, rl.Items, 1)
+	data, _, _ := unstructured.NestedStringMap(rl.Items[0].Object, "data")
+	assert.Equal(t, map[string]string{"key": "", "other": "visible"}, data)
+}
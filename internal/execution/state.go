@@ -0,0 +1,83 @@
+package execution
+
+import (
+	"fmt"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// StateKind is the kind of the pseudo-resource used to exchange the composition's synthesizer state with synthesizers.
+	// It's passed as an input (when the composition has state) and can be returned as an output to replace the state.
+	StateKind = "SynthesizerState"
+
+	// StateInputKey is the input key of the synthesizer state.
+	StateInputKey = "eno.azure.io/synthesizer-state"
+
+	// maxStateBytes bounds the size of the state since it's stored in the composition's status.
+	maxStateBytes = 1024 * 16
+)
+
+// buildStateInput returns the composition's synthesizer state as an input item, or nil if it doesn't have any.
+func buildStateInput(comp *apiv1.Composition) *unstructured.Unstructured {
+	if len(comp.Status.SynthesizerState) == 0 {
+		return nil
+	}
+	data := map[string]any{}
+	for key, val := range comp.Status.SynthesizerState {
+		data[key] = val
+	}
+	obj := &unstructured.Unstructured{Object: map[string]any{"data": data}}
+	obj.SetAPIVersion(apiv1.SchemeGroupVersion.String())
+	obj.SetKind(StateKind)
+	obj.SetName(comp.Name)
+	obj.SetNamespace(comp.Namespace)
+	obj.SetAnnotations(map[string]string{"eno.azure.io/input-key": StateInputKey})
+	return obj
+}
+
+// extractState removes synthesizer state items from the output and returns their merged data.
+// Nil is returned when the synthesizer didn't output any state, in which case the existing state is retained.
+// Invalid state is reported as an error result.
+func extractState(rl *krmv1.ResourceList) map[string]string {
+	var state map[string]string
+	var size int
+	items := rl.Items[:0]
+	for _, obj := range rl.Items {
+		gvk := obj.GroupVersionKind()
+		if gvk.Group != apiv1.SchemeGroupVersion.Group || gvk.Kind != StateKind {
+			items = append(items, obj)
+			continue
+		}
+		if state == nil {
+			state = map[string]string{}
+		}
+		if obj.Object["data"] == nil {
+			continue
+		}
+
+		data, _, err := unstructured.NestedStringMap(obj.Object, "data")
+		if err != nil {
+			rl.Results = append(rl.Results, &krmv1.Result{
+				Message:  fmt.Sprintf("synthesizer state %q is invalid: %s", obj.GetName(), err),
+				Severity: krmv1.ResultSeverityError,
+			})
+			continue
+		}
+		for key, val := range data {
+			state[key] = val
+			size += len(key) + len(val)
+		}
+	}
+	rl.Items = items
+
+	if size > maxStateBytes {
+		rl.Results = append(rl.Results, &krmv1.Result{
+			Message:  fmt.Sprintf("synthesizer state is %d bytes, which exceeds the limit of %d", size, maxStateBytes),
+			Severity: krmv1.ResultSeverityError,
+		})
+	}
+	return state
+}
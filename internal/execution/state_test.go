@@ -0,0 +1,139 @@
+package execution
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSynthesizerState(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.SchemeBuilder.AddToScheme(scheme))
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&apiv1.ResourceSlice{}, &apiv1.Composition{}).
+		Build()
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = "test-synth"
+	require.NoError(t, cli.Create(ctx, syn))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Synthesizer.Name = syn.Name
+	require.NoError(t, cli.Create(ctx, comp))
+
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid"}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	// The synthesizer allocates a value the first time, and reuses it after that
+	var inputs []map[string]string
+	e := &Executor{
+		Reader: cli,
+		Writer: cli,
+		Handler: func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+			subnet := "10.0.1.0/24"
+			var input map[string]string
+			for _, item := range rl.Items {
+				if item.GetAnnotations()["eno.azure.io/input-key"] == StateInputKey {
+					input, _, _ = unstructured.NestedStringMap(item.Object, "data")
+					subnet = input["subnet"]
+				}
+			}
+			inputs = append(inputs, input)
+
+			state := &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "eno.azure.io/v1",
+				"kind":       "SynthesizerState",
+				"metadata":   map[string]any{"name": "state"},
+				"data":       map[string]any{"subnet": subnet},
+			}}
+			cm := &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]any{"name": "test", "namespace": "default"},
+				"data":       map[string]any{"subnet": subnet},
+			}}
+			return &krmv1.ResourceList{Items: []*unstructured.Unstructured{state, cm}}, nil
+		},
+	}
+	env := &Env{
+		CompositionName:      comp.Name,
+		CompositionNamespace: comp.Namespace,
+		SynthesisUUID:        comp.Status.CurrentSynthesis.UUID,
+	}
+
+	require.NoError(t, e.Synthesize(ctx, env))
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Equal(t, map[string]string{"subnet": "10.0.1.0/24"}, comp.Status.SynthesizerState)
+
+	// The state isn't written to resource slices
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = comp.Status.CurrentSynthesis.ResourceSlices[0].Name
+	slice.Namespace = comp.Namespace
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(slice), slice))
+	assert.Len(t, slice.Spec.Resources, 1)
+
+	// Resynthesize with the previous state
+	comp.Status.SynthesizerState["subnet"] = "10.0.2.0/24"
+	comp.Status.PreviousSynthesis = comp.Status.CurrentSynthesis
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "test-uuid-2"}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	env.SynthesisUUID = "test-uuid-2"
+	require.NoError(t, e.Synthesize(ctx, env))
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.Equal(t, map[string]string{"subnet": "10.0.2.0/24"}, comp.Status.SynthesizerState)
+	assert.Equal(t, []map[string]string{nil, {"subnet": "10.0.2.0/24"}}, inputs)
+}
+
+func TestExtractState(t *testing.T) {
+	newState := func(data any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "eno.azure.io/v1",
+			"kind":       "SynthesizerState",
+			"metadata":   map[string]any{"name": "state"},
+			"data":       data,
+		}}
+	}
+	cm := &unstructured.Unstructured{Object: map[string]any{"apiVersion": "v1", "kind": "ConfigMap"}}
+
+	// No state
+	rl := &krmv1.ResourceList{Items: []*unstructured.Unstructured{cm}}
+	assert.Nil(t, extractState(rl))
+	assert.Len(t, rl.Items, 1)
+
+	// Multiple state items are merged
+	rl = &krmv1.ResourceList{Items: []*unstructured.Unstructured{newState(map[string]any{"a": "1"}), cm, newState(map[string]any{"b": "2"})}}
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, extractState(rl))
+	assert.Equal(t, []*unstructured.Unstructured{cm}, rl.Items)
+	assert.Empty(t, rl.Results)
+
+	// Empty state clears any existing state
+	rl = &krmv1.ResourceList{Items: []*unstructured.Unstructured{newState(nil)}}
+	assert.Equal(t, map[string]string{}, extractState(rl))
+
+	// Values must be strings
+	rl = &krmv1.ResourceList{Items: []*unstructured.Unstructured{newState(map[string]any{"a": 1})}}
+	extractState(rl)
+	require.Len(t, rl.Results, 1)
+	assert.Equal(t, krmv1.ResultSeverityError, rl.Results[0].Severity)
+
+	// Size is limited
+	big := make([]byte, maxStateBytes)
+	rl = &krmv1.ResourceList{Items: []*unstructured.Unstructured{newState(map[string]any{"a": string(big)})}}
+	extractState(rl)
+	require.Len(t, rl.Results, 1)
+	assert.Contains(t, rl.Results[0].Message, "exceeds the limit")
+}
@@ -0,0 +1,134 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+)
+
+// UnknownFieldsAnnotation sets how a composition's synthesized resources are checked against the apiserver's openapi schema
+// before they're written to resource slices. Unknown fields would otherwise be silently dropped by apiserver.
+//
+//   - "warn": unknown fields are reported as warning results
+//   - "error": unknown fields are reported as error results, failing the synthesis
+//   - "prune": unknown fields are removed and reported as warning results
+//
+// Resources aren't checked when the annotation isn't set.
+const UnknownFieldsAnnotation = "eno.azure.io/unknown-fields"
+
+const (
+	UnknownFieldsWarn  = "warn"
+	UnknownFieldsError = "error"
+	UnknownFieldsPrune = "prune"
+)
+
+// SchemaGetter returns the openapi schema of a resource type, or nil if it isn't known.
+type SchemaGetter interface {
+	Get(ctx context.Context, gvk schema.GroupVersionKind) (proto.Schema, error)
+}
+
+func (e *Executor) checkUnknownFields(ctx context.Context, comp *apiv1.Composition, rl *krmv1.ResourceList) error {
+	mode := comp.Annotations[UnknownFieldsAnnotation]
+	if e.Schemas == nil || (mode != UnknownFieldsWarn && mode != UnknownFieldsError && mode != UnknownFieldsPrune) {
+		return nil
+	}
+
+	severity := krmv1.ResultSeverityWarning
+	if mode == UnknownFieldsError {
+		severity = krmv1.ResultSeverityError
+	}
+	verb := "unknown"
+	if mode == UnknownFieldsPrune {
+		verb = "pruned unknown"
+	}
+
+	for _, obj := range rl.Items {
+		gvk := obj.GroupVersionKind()
+		s, err := e.Schemas.Get(ctx, gvk)
+		if err != nil {
+			return fmt.Errorf("getting schema for %s: %w", gvk, err)
+		}
+		if s == nil {
+			continue // types missing from the schema can't be checked
+		}
+
+		for _, path := range unknownFields("", obj.Object, s, mode == UnknownFieldsPrune) {
+			rl.Results = append(rl.Results, &krmv1.Result{
+				Message:  fmt.Sprintf("%s field %q in %s %s", verb, path, gvk.Kind, refString(obj.GetNamespace(), obj.GetName())),
+				Severity: severity,
+			})
+		}
+	}
+	return nil
+}
+
+// unknownFields returns the paths of fields in val that aren't defined by s, optionally removing them.
+func unknownFields(path string, val any, s proto.Schema, prune bool) []string {
+	var paths []string
+	switch s := s.(type) {
+	case *proto.Ref:
+		return unknownFields(path, val, s.SubSchema(), prune)
+
+	case *proto.Kind:
+		obj, ok := val.(map[string]any)
+		if !ok || len(s.Fields) == 0 || preservesUnknownFields(s) {
+			return nil
+		}
+		for key, child := range obj {
+			field, ok := s.Fields[key]
+			if !ok {
+				paths = append(paths, joinFieldPath(path, key))
+				if prune {
+					delete(obj, key)
+				}
+				continue
+			}
+			paths = append(paths, unknownFields(joinFieldPath(path, key), child, field, prune)...)
+		}
+
+	case *proto.Map:
+		obj, ok := val.(map[string]any)
+		if !ok || preservesUnknownFields(s) {
+			return nil
+		}
+		for key, child := range obj {
+			paths = append(paths, unknownFields(joinFieldPath(path, key), child, s.SubType, prune)...)
+		}
+
+	case *proto.Array:
+		items, ok := val.([]any)
+		if !ok {
+			return nil
+		}
+		for i, child := range items {
+			paths = append(paths, unknownFields(path+"["+strconv.Itoa(i)+"]", child, s.SubType, prune)...)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func preservesUnknownFields(s proto.Schema) bool {
+	preserve, _ := s.GetExtensions()["x-kubernetes-preserve-unknown-fields"].(bool)
+	return preserve
+}
+
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func refString(ns, name string) string {
+	if ns == "" {
+		return name
+	}
+	return ns + "/" + name
+}
@@ -0,0 +1,126 @@
+package execution
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+	openapi_v2 "github.com/google/gnostic-models/openapiv2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+)
+
+func TestCheckUnknownFields(t *testing.T) {
+	doc, err := openapi_v2.ParseDocument([]byte(testOpenAPIDoc))
+	require.NoError(t, err)
+	models, err := proto.NewOpenAPIData(doc)
+	require.NoError(t, err)
+	schemas := staticSchemas{
+		{Kind: "Widget"}:    models.LookupModel("widget"),
+		{Kind: "Arbitrary"}: models.LookupModel("arbitrary"),
+	}
+
+	newOutput := func() *krmv1.ResourceList {
+		return &krmv1.ResourceList{Items: []*unstructured.Unstructured{
+			{Object: map[string]any{
+				"kind":     "Widget",
+				"metadata": map[string]any{"name": "foo", "namespace": "default", "bad": "value"},
+				"spec": map[string]any{
+					"size":   1,
+					"extra":  true,
+					"labels": map[string]any{"any": "key"},
+					"items":  []any{map[string]any{"name": "a"}, map[string]any{"name": "b", "typo": "c"}},
+					"raw":    map[string]any{"anything": map[string]any{"goes": true}},
+				},
+			}},
+			{Object: map[string]any{"kind": "Arbitrary", "foo": "bar"}},
+			{Object: map[string]any{"kind": "Missing", "foo": "bar"}},
+		}}
+	}
+	expectedPaths := []string{"metadata.bad", "spec.extra", "spec.items[1].typo"}
+
+	tests := []struct {
+		Mode     string
+		Severity string
+		Message  string
+		Pruned   bool
+	}{
+		{Mode: "", Severity: ""},
+		{Mode: UnknownFieldsWarn, Severity: krmv1.ResultSeverityWarning, Message: "unknown field"},
+		{Mode: UnknownFieldsError, Severity: krmv1.ResultSeverityError, Message: "unknown field"},
+		{Mode: UnknownFieldsPrune, Severity: krmv1.ResultSeverityWarning, Message: "pruned unknown field", Pruned: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Mode, func(t *testing.T) {
+			comp := &apiv1.Composition{}
+			comp.Annotations = map[string]string{UnknownFieldsAnnotation: tc.Mode}
+			rl := newOutput()
+
+			e := &Executor{Schemas: schemas}
+			require.NoError(t, e.checkUnknownFields(context.Background(), comp, rl))
+
+			if tc.Mode == "" {
+				assert.Empty(t, rl.Results)
+				return
+			}
+			require.Len(t, rl.Results, len(expectedPaths))
+			for i, path := range expectedPaths {
+				assert.Equal(t, tc.Severity, rl.Results[i].Severity)
+				assert.Equal(t, tc.Message+" \""+path+"\" in Widget default/foo", rl.Results[i].Message)
+			}
+
+			_, found, _ := unstructured.NestedFieldNoCopy(rl.Items[0].Object, "spec", "extra")
+			assert.Equal(t, !tc.Pruned, found)
+			_, found, _ = unstructured.NestedFieldNoCopy(rl.Items[0].Object, "spec", "raw", "anything", "goes")
+			assert.True(t, found)
+			items, _, _ := unstructured.NestedSlice(rl.Items[0].Object, "spec", "items")
+			_, found = items[1].(map[string]any)["typo"]
+			assert.Equal(t, !tc.Pruned, found)
+		})
+	}
+}
+
+type staticSchemas map[schema.GroupVersionKind]proto.Schema
+
+func (s staticSchemas) Get(ctx context.Context, gvk schema.GroupVersionKind) (proto.Schema, error) {
+	return s[gvk], nil
+}
+
+const testOpenAPIDoc = `{
+  "swagger": "2.0",
+  "info": {"title": "test", "version": "v1.30.0"},
+  "paths": {},
+  "definitions": {
+    "widget": {
+      "type": "object",
+      "properties": {
+        "apiVersion": {"type": "string"},
+        "kind": {"type": "string"},
+        "metadata": {"$ref": "#/definitions/meta"},
+        "spec": {
+          "type": "object",
+          "properties": {
+            "size": {"type": "integer"},
+            "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+            "items": {"type": "array", "items": {"type": "object", "properties": {"name": {"type": "string"}}}},
+            "raw": {"type": "object", "properties": {"known": {"type": "string"}}, "x-kubernetes-preserve-unknown-fields": true}
+          }
+        }
+      }
+    },
+    "meta": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "namespace": {"type": "string"}
+      }
+    },
+    "arbitrary": {
+      "type": "object"
+    }
+  }
+}`
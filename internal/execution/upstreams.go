@@ -0,0 +1,116 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// UpstreamKind is the kind of the pseudo-resource used to summarize an upstream composition's synthesis.
+const UpstreamKind = "UpstreamComposition"
+
+// buildUpstreamInputs returns the selected resources of each upstream composition's most recent successful synthesis as inputs,
+// along with a summary of the synthesis and the upstream's exports. The upstream's output revision is recorded as the input's revision
+// so changes to the upstream trigger resynthesis.
+func (e *Executor) buildUpstreamInputs(ctx context.Context, comp *apiv1.Composition) ([]*unstructured.Unstructured, []apiv1.InputRevisions, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	items := []*unstructured.Unstructured{}
+	revs := []apiv1.InputRevisions{}
+	for _, up := range comp.Spec.Upstreams {
+		start := time.Now()
+		upstream := &apiv1.Composition{}
+		upstream.Name = up.Composition
+		upstream.Namespace = comp.Namespace
+		err := e.Reader.Get(ctx, client.ObjectKeyFromObject(upstream), upstream)
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting upstream composition %q: %w", up.Composition, err)
+		}
+
+		syn := upstream.OutputSynthesis()
+		if syn == nil {
+			return nil, nil, fmt.Errorf("upstream composition %q has not been synthesized", up.Composition)
+		}
+
+		for _, ref := range syn.ResourceSlices {
+			slice := &apiv1.ResourceSlice{}
+			slice.Name = ref.Name
+			slice.Namespace = upstream.Namespace
+			err := e.Reader.Get(ctx, client.ObjectKeyFromObject(slice), slice)
+			if errors.IsNotFound(err) {
+				// The upstream is being resynthesized - the new synthesis UUID will trigger another synthesis
+				logger.V(0).Info("resource slice referenced by upstream composition was not found - skipping", "resourceSliceName", slice.Name)
+				continue
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("getting resource slice %q of upstream composition %q: %w", slice.Name, up.Composition, err)
+			}
+
+			for _, res := range slice.Spec.Resources {
+				if res.Deleted {
+					continue
+				}
+				obj := &unstructured.Unstructured{}
+				if err := obj.UnmarshalJSON([]byte(res.Manifest)); err != nil {
+					return nil, nil, fmt.Errorf("parsing manifest of upstream composition %q: %w", up.Composition, err)
+				}
+				if !matchesUpstreamSelector(up.Resources, obj) {
+					continue
+				}
+				anno := obj.GetAnnotations()
+				if anno == nil {
+					anno = map[string]string{}
+				}
+				anno["eno.azure.io/input-key"] = up.Key
+				obj.SetAnnotations(anno)
+				items = append(items, obj)
+			}
+		}
+
+		items = append(items, buildUpstreamSummary(upstream, syn, up.Key))
+		revs = append(revs, apiv1.InputRevisions{Key: up.Key, ResourceVersion: upstream.OutputRevision()})
+		logger.V(0).Info("retrieved upstream input", "key", up.Key, "upstreamCompositionName", upstream.Name, "upstreamSynthesisUUID", syn.UUID, "latency", time.Since(start).Milliseconds())
+	}
+	return items, revs, nil
+}
+
+func matchesUpstreamSelector(selectors []apiv1.UpstreamResourceSelector, obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	for _, sel := range selectors {
+		if sel.Group == gvk.Group && sel.Kind == gvk.Kind && (sel.Name == "" || sel.Name == obj.GetName()) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildUpstreamSummary(upstream *apiv1.Composition, syn *apiv1.Synthesis, key string) *unstructured.Unstructured {
+	status := map[string]any{
+		"synthesisUUID":                 syn.UUID,
+		"observedSynthesizerGeneration": syn.ObservedSynthesizerGeneration,
+	}
+	if syn.Synthesized != nil {
+		status["synthesized"] = syn.Synthesized.UTC().Format(time.RFC3339)
+	}
+	if len(upstream.Status.Exports) > 0 {
+		exports := map[string]any{}
+		for name, val := range upstream.Status.Exports {
+			exports[name] = val
+		}
+		status["exports"] = exports
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]any{"status": status}}
+	obj.SetAPIVersion(apiv1.SchemeGroupVersion.String())
+	obj.SetKind(UpstreamKind)
+	obj.SetName(upstream.Name)
+	obj.SetNamespace(upstream.Namespace)
+	obj.SetAnnotations(map[string]string{"eno.azure.io/input-key": key})
+	return obj
+}
@@ -0,0 +1,100 @@
+package execution
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpstreamInputs(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.SchemeBuilder.AddToScheme(scheme))
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&apiv1.ResourceSlice{}, &apiv1.Composition{}).
+		Build()
+
+	slice := &apiv1.ResourceSlice{}
+	slice.Name = "upstream-slice"
+	slice.Namespace = "default"
+	slice.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"selected","namespace":"default"}}`},
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"deleted","namespace":"default"}}`, Deleted: true},
+		{Manifest: `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"not-selected","namespace":"default"}}`},
+		{Manifest: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"any","namespace":"default"}}`},
+	}
+	require.NoError(t, cli.Create(ctx, slice))
+
+	upstream := &apiv1.Composition{}
+	upstream.Name = "upstream"
+	upstream.Namespace = "default"
+	require.NoError(t, cli.Create(ctx, upstream))
+
+	comp := &apiv1.Composition{}
+	comp.Name = "downstream"
+	comp.Namespace = "default"
+	comp.Spec.Upstreams = []apiv1.UpstreamInput{{
+		Key:         "up",
+		Composition: upstream.Name,
+		Resources: []apiv1.UpstreamResourceSelector{
+			{Kind: "ConfigMap", Name: "selected"},
+			{Kind: "ConfigMap", Name: "deleted"},
+			{Group: "apps", Kind: "Deployment"},
+		},
+	}}
+
+	e := &Executor{Reader: cli, Writer: cli}
+
+	// Upstreams that haven't been synthesized block synthesis
+	_, _, err := e.buildUpstreamInputs(ctx, comp)
+	assert.Error(t, err)
+
+	// Failed syntheses are ignored in favor of the previous synthesis
+	upstream.Status.PreviousSynthesis = &apiv1.Synthesis{UUID: "prev-uuid", Synthesized: ptr.To(metav1.Now()), ResourceSlices: []*apiv1.ResourceSliceRef{{Name: slice.Name}}}
+	upstream.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "failed-uuid", Synthesized: ptr.To(metav1.Now()), Results: []apiv1.Result{{Severity: "error"}}}
+	require.NoError(t, cli.Status().Update(ctx, upstream))
+
+	items, revs, err := e.buildUpstreamInputs(ctx, comp)
+	require.NoError(t, err)
+	assert.Equal(t, []apiv1.InputRevisions{{Key: "up", ResourceVersion: "prev-uuid"}}, revs)
+
+	names := []string{}
+	for _, item := range items {
+		assert.Equal(t, "up", item.GetAnnotations()["eno.azure.io/input-key"])
+		names = append(names, item.GetKind()+"/"+item.GetName())
+	}
+	assert.Equal(t, []string{"ConfigMap/selected", "Deployment/any", UpstreamKind + "/upstream"}, names)
+
+	uuid, _, _ := unstructured.NestedString(items[2].Object, "status", "synthesisUUID")
+	assert.Equal(t, "prev-uuid", uuid)
+
+	// Exports are passed in the summary, and changes to them change the revision
+	upstream.Status.Exports = map[string]string{"ip": "10.0.0.1"}
+	require.NoError(t, cli.Status().Update(ctx, upstream))
+
+	items, revs, err = e.buildUpstreamInputs(ctx, comp)
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	assert.NotEqual(t, "prev-uuid", revs[0].ResourceVersion)
+	assert.Equal(t, upstream.OutputRevision(), revs[0].ResourceVersion)
+
+	ip, _, _ := unstructured.NestedString(items[2].Object, "status", "exports", "ip")
+	assert.Equal(t, "10.0.0.1", ip)
+
+	// Only the summary is passed when no resources are selected
+	comp.Spec.Upstreams[0].Resources = nil
+	items, _, err = e.buildUpstreamInputs(ctx, comp)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, UpstreamKind, items[0].GetKind())
+}
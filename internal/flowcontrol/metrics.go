@@ -2,7 +2,8 @@ package flowcontrol
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
-	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/Azure/eno/internal/telemetry"
 )
 
 var (
@@ -15,5 +16,5 @@ var (
 )
 
 func init() {
-	metrics.Registry.MustRegister(sliceStatusUpdates)
+	telemetry.MustRegister(sliceStatusUpdates)
 }
@@ -1,6 +1,7 @@
 package flowcontrol
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -17,6 +18,7 @@ import (
 
 	apiv1 "github.com/Azure/eno/api/v1"
 	"github.com/Azure/eno/internal/resource"
+	"github.com/Azure/eno/pkg/faults"
 	"github.com/go-logr/logr"
 )
 
@@ -37,6 +39,9 @@ type ResourceSliceWriteBuffer struct {
 	mut   sync.Mutex
 	state map[types.NamespacedName][]*resourceSliceStatusUpdate
 	queue workqueue.RateLimitingInterface
+
+	// Faults are injected before every status patch. Only set by tests.
+	Faults faults.Injector
 }
 
 func NewResourceSliceWriteBufferForManager(mgr ctrl.Manager, batchInterval time.Duration, burst int) *ResourceSliceWriteBuffer {
@@ -164,7 +169,7 @@ func (w *ResourceSliceWriteBuffer) updateSlice(ctx context.Context, sliceNSN typ
 
 	// Transform the set of patch funcs into a set of jsonpatch objects
 	unsafeSlice := slice.Status.Resources
-	var patches []*jsonPatch
+	patches := make([]jsonPatch, 0, len(updates))
 	for _, update := range updates {
 		unsafeStatusPtr := &unsafeSlice[update.SlicedResource.Index]
 		patch := update.PatchFn(unsafeStatusPtr)
@@ -172,7 +177,7 @@ func (w *ResourceSliceWriteBuffer) updateSlice(ctx context.Context, sliceNSN typ
 			continue
 		}
 
-		patches = append(patches, &jsonPatch{
+		patches = append(patches, jsonPatch{
 			Op:    "replace",
 			Path:  fmt.Sprintf("/status/resources/%d", update.SlicedResource.Index),
 			Value: patch,
@@ -183,11 +188,20 @@ func (w *ResourceSliceWriteBuffer) updateSlice(ctx context.Context, sliceNSN typ
 	}
 
 	// Encode/apply the patch(es)
-	patchJson, err := json.Marshal(&patches)
-	if err != nil {
+	buf := patchBufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		patchBufferPool.Put(buf)
+	}()
+	if err := json.NewEncoder(buf).Encode(&patches); err != nil {
 		logger.Error(err, "unable to encode patch")
 		return false
 	}
+	patchJson := buf.Bytes()
+	if err := faults.Inject(ctx, w.Faults, faults.StatusPatch); err != nil {
+		logger.Error(err, "unable to update resource slice")
+		return false
+	}
 	err = w.client.Status().Patch(ctx, slice, client.RawPatch(types.JSONPatchType, patchJson))
 	if errors.IsNotFound(err) {
 		logger.V(1).Info("resource slice deleted - dropping buffered status updates")
@@ -203,6 +217,12 @@ func (w *ResourceSliceWriteBuffer) updateSlice(ctx context.Context, sliceNSN typ
 	return true
 }
 
+// patchBufferPool holds buffers used to encode status patches, since they're allocated for every slice update.
+// The patch is only referenced during the request, so buffers are safe to reuse after it returns.
+var patchBufferPool = sync.Pool{
+	New: func() any { return &bytes.Buffer{} },
+}
+
 type jsonPatch struct {
 	Op    string `json:"op"`
 	Path  string `json:"path"`
@@ -3,6 +3,7 @@ package flowcontrol
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -243,3 +244,37 @@ func TestRateLimiter(t *testing.T) {
 	assert.Less(t, wait, 2*time.Second)
 	assert.Greater(t, wait, 600*time.Millisecond)
 }
+
+func BenchmarkResourceSliceWriteBufferFlush(b *testing.B) {
+	for _, n := range []int{1, 100, 1000} {
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			ctx := context.Background()
+			cli := testutil.NewClient(b)
+			w := NewResourceSliceWriteBuffer(cli, 0, 1)
+
+			slice := &apiv1.ResourceSlice{}
+			slice.Name = "test-slice-1"
+			slice.Spec.Resources = make([]apiv1.Manifest, n)
+			if err := cli.Create(ctx, slice); err != nil {
+				b.Fatal(err)
+			}
+
+			// Flip the state every time so every flush results in a patch
+			toggle := func(rs *apiv1.ResourceState) *apiv1.ResourceState {
+				return &apiv1.ResourceState{Reconciled: !rs.Reconciled}
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for j := 0; j < n; j++ {
+					req := &resource.ManifestRef{}
+					req.Slice.Name = slice.Name
+					req.Index = j
+					w.PatchStatusAsync(ctx, req, toggle)
+				}
+				w.processQueueItem(ctx)
+			}
+		})
+	}
+}
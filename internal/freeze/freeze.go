@@ -0,0 +1,184 @@
+// Package freeze implements schedules during which changes to a composition's resources are deferred.
+package freeze
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+// Annotation holds a composition's freeze schedule: one or more windows separated by semicolons.
+// Each window is "<days> <HH:MM>-<HH:MM> [timezone]", for example "Mon-Fri 17:00-09:00 America/New_York; Sat,Sun 00:00-24:00".
+// Days can be a range, a comma-separated list, or "*". Windows that end before they start continue into the next day.
+// Times are UTC unless an IANA timezone is given.
+const Annotation = "eno.azure.io/freeze-window"
+
+// Schedule is a set of recurring windows.
+type Schedule []*Window
+
+// Window is a recurring span of time on particular days of the week.
+type Window struct {
+	Days       [7]bool // indexed by time.Weekday
+	Start, End time.Duration
+	Location   *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Parse parses a schedule in the format described by Annotation.
+func Parse(str string) (Schedule, error) {
+	var s Schedule
+	for _, chunk := range strings.Split(str, ";") {
+		chunk = strings.TrimSpace(chunk)
+		if chunk == "" {
+			continue
+		}
+		w, err := parseWindow(chunk)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window %q: %w", chunk, err)
+		}
+		s = append(s, w)
+	}
+	if len(s) == 0 {
+		return nil, fmt.Errorf("at least one window is required")
+	}
+	return s, nil
+}
+
+func parseWindow(str string) (*Window, error) {
+	fields := strings.Fields(str)
+	if len(fields) != 2 && len(fields) != 3 {
+		return nil, fmt.Errorf("expected \"<days> <HH:MM>-<HH:MM> [timezone]\"")
+	}
+
+	w := &Window{Location: time.UTC}
+	if err := w.parseDays(fields[0]); err != nil {
+		return nil, err
+	}
+
+	start, end, ok := strings.Cut(fields[1], "-")
+	if !ok {
+		return nil, fmt.Errorf("expected a time range like 09:00-17:00")
+	}
+	var err error
+	if w.Start, err = parseTimeOfDay(start); err != nil {
+		return nil, err
+	}
+	if w.End, err = parseTimeOfDay(end); err != nil {
+		return nil, err
+	}
+	if w.Start == w.End {
+		return nil, fmt.Errorf("window is empty")
+	}
+
+	if len(fields) == 3 {
+		w.Location, err = time.LoadLocation(fields[2])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+func (w *Window) parseDays(str string) error {
+	if str == "*" {
+		for i := range w.Days {
+			w.Days[i] = true
+		}
+		return nil
+	}
+	for _, item := range strings.Split(str, ",") {
+		from, to, isRange := strings.Cut(strings.ToLower(item), "-")
+		first, ok := weekdays[from]
+		if !ok {
+			return fmt.Errorf("unknown day %q", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[to]; !ok {
+				return fmt.Errorf("unknown day %q", to)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			w.Days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func parseTimeOfDay(str string) (time.Duration, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(str, "%d:%d", &h, &m); err != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m > 0) {
+		return 0, fmt.Errorf("invalid time %q", str)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// Until returns the time at which the window containing now ends, or false if now isn't within a window.
+// Adjacent or overlapping windows are treated as a single window.
+func (s Schedule) Until(now time.Time) (time.Time, bool) {
+	var frozen bool
+	for i := 0; i < 14; i++ { // at most one week of chained windows, each of which is at most ~2 days
+		end, ok := s.windowEnd(now)
+		if !ok {
+			break
+		}
+		frozen = true
+		now = end
+	}
+	return now, frozen
+}
+
+func (s Schedule) windowEnd(now time.Time) (time.Time, bool) {
+	var latest time.Time
+	for _, w := range s {
+		if end, ok := w.end(now); ok && end.After(latest) {
+			latest = end
+		}
+	}
+	return latest, !latest.IsZero()
+}
+
+func (w *Window) end(now time.Time) (time.Time, bool) {
+	local := now.In(w.Location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, w.Location)
+
+	// Windows that wrap past midnight may have started yesterday
+	for _, offset := range []int{0, -1} {
+		day := midnight.AddDate(0, 0, offset)
+		if !w.Days[day.Weekday()] {
+			continue
+		}
+		start := day.Add(w.Start)
+		end := day.Add(w.End)
+		if w.End <= w.Start {
+			end = end.Add(time.Hour * 24)
+		}
+		if !now.Before(start) && now.Before(end) {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ForComposition returns the end of the composition's current freeze window, or false if it isn't frozen.
+// Invalid schedules are ignored (they're rejected at admission when the webhook is enabled).
+func ForComposition(comp *apiv1.Composition, now time.Time) (time.Time, bool) {
+	str, ok := comp.Annotations[Annotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	s, err := Parse(str)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return s.Until(now)
+}
@@ -0,0 +1,132 @@
+package freeze
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, str := range []string{
+		"",
+		";",
+		"Mon",
+		"Funday 09:00-17:00",
+		"Mon-Fri 09:00",
+		"Mon-Fri 09:00-25:00",
+		"Mon-Fri 09:60-17:00",
+		"Mon-Fri 09:00-09:00",
+		"Mon-Fri 09:00-17:00 Not/AZone",
+		"Mon-Fri 09:00-17:00 UTC extra",
+	} {
+		t.Run(str, func(t *testing.T) {
+			_, err := Parse(str)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestScheduleUntil(t *testing.T) {
+	// 2024-06-03 is a Monday
+	at := func(day, hour, min int) time.Time { return time.Date(2024, 6, day, hour, min, 0, 0, time.UTC) }
+
+	tests := []struct {
+		Name     string
+		Schedule string
+		Now      time.Time
+		Frozen   bool
+		Until    time.Time
+	}{
+		{
+			Name:     "within simple window",
+			Schedule: "Mon-Fri 09:00-17:00",
+			Now:      at(3, 12, 0),
+			Frozen:   true,
+			Until:    at(3, 17, 0),
+		},
+		{
+			Name:     "at window end",
+			Schedule: "Mon-Fri 09:00-17:00",
+			Now:      at(3, 17, 0),
+		},
+		{
+			Name:     "wrong day",
+			Schedule: "Tue 09:00-17:00",
+			Now:      at(3, 12, 0),
+		},
+		{
+			Name:     "wraps midnight",
+			Schedule: "Mon 22:00-06:00",
+			Now:      at(4, 2, 0),
+			Frozen:   true,
+			Until:    at(4, 6, 0),
+		},
+		{
+			Name:     "day list",
+			Schedule: "Sun,Wed 00:00-24:00",
+			Now:      at(5, 23, 59),
+			Frozen:   true,
+			Until:    at(6, 0, 0),
+		},
+		{
+			Name:     "wrapping day range",
+			Schedule: "Sat-Mon 00:00-24:00",
+			Now:      at(2, 12, 0), // Sunday
+			Frozen:   true,
+			Until:    at(4, 0, 0),
+		},
+		{
+			Name:     "every day",
+			Schedule: "* 12:00-13:00",
+			Now:      at(8, 12, 30),
+			Frozen:   true,
+			Until:    at(8, 13, 0),
+		},
+		{
+			Name:     "chained windows",
+			Schedule: "Fri 17:00-24:00; Sat,Sun 00:00-24:00; Mon 00:00-09:00",
+			Now:      at(7, 18, 0),
+			Frozen:   true,
+			Until:    at(10, 9, 0),
+		},
+		{
+			Name:     "timezone",
+			Schedule: "Mon 09:00-17:00 America/New_York",
+			Now:      at(3, 14, 0), // 10:00 EDT
+			Frozen:   true,
+			Until:    at(3, 21, 0),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			s, err := Parse(tc.Schedule)
+			require.NoError(t, err)
+
+			until, frozen := s.Until(tc.Now)
+			assert.Equal(t, tc.Frozen, frozen)
+			if tc.Frozen {
+				assert.True(t, tc.Until.Equal(until), "expected %s, got %s", tc.Until, until)
+			}
+		})
+	}
+}
+
+func TestForComposition(t *testing.T) {
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	comp := &apiv1.Composition{}
+
+	_, frozen := ForComposition(comp, now)
+	assert.False(t, frozen)
+
+	comp.Annotations = map[string]string{Annotation: "invalid"}
+	_, frozen = ForComposition(comp, now)
+	assert.False(t, frozen)
+
+	comp.Annotations = map[string]string{Annotation: "* 00:00-24:00"}
+	_, frozen = ForComposition(comp, now)
+	assert.True(t, frozen)
+}
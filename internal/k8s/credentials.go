@@ -0,0 +1,190 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// minReloadInterval limits how often 401 responses can cause the kubeconfig to be reloaded.
+const minReloadInterval = time.Second * 5
+
+// CountAuthFailures returns a copy of the config that reports requests rejected by the apiserver's authentication (401)
+// or authorization (403) using a metric.
+func CountAuthFailures(rc *rest.Config) *rest.Config {
+	conf := rest.CopyConfig(rc)
+	conf.Wrap(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err == nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+				downstreamAuthFailures.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+			}
+			return resp, err
+		})
+	})
+	return conf
+}
+
+// CredentialReloader keeps the credentials (tokens, exec plugins, auth providers, basic auth) used to connect to an apiserver
+// in sync with a kubeconfig file, so short-lived credentials written by another process can be rotated without restarting.
+//
+// The file is reloaded periodically, and soon after the apiserver rejects the current credentials.
+// Other kubeconfig fields (server, TLS, etc.) are only read when the process starts.
+// Exec plugins that return client certificates instead of tokens aren't supported.
+type CredentialReloader struct {
+	path     string
+	interval time.Duration
+	reloads  chan struct{}
+
+	mut        sync.Mutex
+	raw        []byte
+	lastLoaded time.Time
+	current    atomic.Pointer[rest.Config]
+}
+
+func NewCredentialReloader(path string, interval time.Duration) *CredentialReloader {
+	return &CredentialReloader{path: path, interval: interval, reloads: make(chan struct{}, 1)}
+}
+
+// Apply returns a copy of the config that uses the kubeconfig's current credentials.
+// Load must succeed before the config is used.
+func (c *CredentialReloader) Apply(rc *rest.Config) *rest.Config {
+	conf := rest.CopyConfig(rc)
+	conf.BearerToken, conf.BearerTokenFile = "", ""
+	conf.Username, conf.Password = "", ""
+	conf.ExecProvider, conf.AuthProvider, conf.AuthConfigPersister = nil, nil, nil
+	conf.Wrap(func(next http.RoundTripper) http.RoundTripper {
+		return &credentialTransport{next: next, reloader: c}
+	})
+	return conf
+}
+
+// Start reloads the kubeconfig periodically or when requested because of an authentication failure.
+// Errors are logged, and the previous credentials are used until the file is fixed.
+func (c *CredentialReloader) Start(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-c.reloads:
+		}
+		if err := c.Load(ctx); err != nil {
+			logger.Error(err, "reloading downstream credentials")
+		}
+	}
+}
+
+// Load reads the kubeconfig and starts using its credentials if it has changed.
+func (c *CredentialReloader) Load(ctx context.Context) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.lastLoaded = time.Now()
+
+	raw, err := os.ReadFile(c.path)
+	if err != nil {
+		credentialReloads.WithLabelValues("error").Inc()
+		return fmt.Errorf("reading kubeconfig: %w", err)
+	}
+	if c.raw != nil && bytes.Equal(raw, c.raw) {
+		return nil
+	}
+
+	rc, err := clientcmd.RESTConfigFromKubeConfig(raw)
+	if err != nil {
+		credentialReloads.WithLabelValues("error").Inc()
+		return fmt.Errorf("parsing kubeconfig: %w", err)
+	}
+
+	// Exec plugins are given the cluster's connection details, so they're kept along with the credentials
+	c.current.Store(&rest.Config{
+		Host:                rc.Host,
+		TLSClientConfig:     rest.TLSClientConfig{ServerName: rc.ServerName, CAData: rc.CAData, CAFile: rc.CAFile, Insecure: rc.Insecure},
+		Proxy:               rc.Proxy,
+		BearerToken:         rc.BearerToken,
+		BearerTokenFile:     rc.BearerTokenFile,
+		Username:            rc.Username,
+		Password:            rc.Password,
+		ExecProvider:        rc.ExecProvider,
+		AuthProvider:        rc.AuthProvider,
+		AuthConfigPersister: rc.AuthConfigPersister,
+	})
+	c.raw = raw
+	credentialReloads.WithLabelValues("success").Inc()
+	logr.FromContextOrDiscard(ctx).V(0).Info("loaded downstream credentials")
+	return nil
+}
+
+// requestReload asks Start to reload the kubeconfig without blocking, unless it was loaded very recently.
+func (c *CredentialReloader) requestReload() {
+	c.mut.Lock()
+	recent := time.Since(c.lastLoaded) < minReloadInterval
+	c.mut.Unlock()
+	if recent {
+		return
+	}
+	select {
+	case c.reloads <- struct{}{}:
+	default:
+	}
+}
+
+// credentialTransport authenticates requests using the reloader's current credentials.
+type credentialTransport struct {
+	next     http.RoundTripper
+	reloader *CredentialReloader
+
+	mut     sync.Mutex
+	conf    *rest.Config
+	current http.RoundTripper
+}
+
+func (c *credentialTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rt.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		c.reloader.requestReload()
+	}
+	return resp, err
+}
+
+func (c *credentialTransport) get() (http.RoundTripper, error) {
+	conf := c.reloader.current.Load()
+	if conf == nil {
+		return nil, errors.New("downstream credentials haven't been loaded")
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.conf == conf {
+		return c.current, nil
+	}
+
+	rt, err := rest.HTTPWrappersForConfig(conf, c.next)
+	if err != nil {
+		return nil, fmt.Errorf("building credential transport: %w", err)
+	}
+	c.conf, c.current = conf, rt
+	return rt, nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (r roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return r(req) }
@@ -0,0 +1,117 @@
+package k8s
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+
+	"github.com/Azure/eno/internal/testutil"
+)
+
+func TestCredentialReloader(t *testing.T) {
+	ctx := testutil.NewContext(t)
+
+	var token atomic.Value
+	token.Store("token-1")
+	svr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer svr.Close()
+
+	// Credentials are only used for TLS connections
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	writeKubeconfig := func(token string) {
+		require.NoError(t, os.WriteFile(path, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: `+svr.URL+`
+    insecure-skip-tls-verify: true
+users:
+- name: test
+  user:
+    token: `+token+`
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+`), 0600))
+	}
+	writeKubeconfig("token-1")
+
+	initial, err := GetRESTConfig(path)
+	require.NoError(t, err)
+
+	reloader := NewCredentialReloader(path, time.Hour)
+	conf := CountAuthFailures(reloader.Apply(initial))
+	assert.Empty(t, conf.BearerToken)
+
+	hc, err := rest.HTTPClientFor(conf)
+	require.NoError(t, err)
+	get := func() (int, error) {
+		resp, err := hc.Get(svr.URL)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	// Not loaded yet
+	_, err = get()
+	assert.Error(t, err)
+
+	require.NoError(t, reloader.Load(ctx))
+	code, err := get()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+
+	// The token expires
+	token.Store("token-2")
+	reloader.lastLoaded = time.Time{}
+	code, err = get()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Len(t, reloader.reloads, 1, "reload requested")
+
+	// The kubeconfig is updated
+	writeKubeconfig("token-2")
+	require.NoError(t, reloader.Load(ctx))
+	code, err = get()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+
+	// Invalid kubeconfigs are ignored
+	require.NoError(t, os.WriteFile(path, []byte("not a kubeconfig"), 0600))
+	assert.Error(t, reloader.Load(ctx))
+	code, err = get()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestCredentialReloaderDebounce(t *testing.T) {
+	reloader := NewCredentialReloader("", time.Hour)
+	reloader.lastLoaded = time.Now()
+	reloader.requestReload()
+	assert.Len(t, reloader.reloads, 0)
+
+	reloader.lastLoaded = time.Now().Add(-minReloadInterval)
+	reloader.requestReload()
+	reloader.requestReload()
+	assert.Len(t, reloader.reloads, 1)
+}
@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// Values of the apiserver label of client metrics.
+const (
+	Upstream   = "upstream"   // the cluster that holds compositions, synthesizers, and resource slices
+	Downstream = "downstream" // the cluster that resources are reconciled into
+)
+
+// InstrumentClient returns a copy of the config that reports the latency and errors of requests, partitioned by
+// the given apiserver (Upstream or Downstream) and the request's verb.
+func InstrumentClient(rc *rest.Config, apiserver string) *rest.Config {
+	conf := rest.CopyConfig(rc)
+	conf.Wrap(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			verb := requestVerb(req)
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			// Watches are long-running, so their latency isn't meaningful
+			if verb != "watch" {
+				clientRequestLatency.WithLabelValues(apiserver, verb).Observe(time.Since(start).Seconds())
+			}
+
+			switch {
+			case err != nil && req.Context().Err() == nil: // canceled requests aren't the apiserver's fault
+				clientRequestErrors.WithLabelValues(apiserver, verb, "error").Inc()
+			case err == nil && (resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests):
+				clientRequestErrors.WithLabelValues(apiserver, verb, strconv.Itoa(resp.StatusCode)).Inc()
+			}
+			return resp, err
+		})
+	})
+	return conf
+}
+
+// requestVerb returns the Kubernetes API verb of a request e.g. get, list, watch, patch.
+// Requests to non-resource paths (discovery, healthz, etc.) are reported using their lowercase HTTP method.
+func requestVerb(req *http.Request) string {
+	method := strings.ToLower(req.Method)
+	switch req.Method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	}
+
+	collection, ok := isCollection(req.URL.Path)
+	if !ok {
+		return method
+	}
+	switch req.Method {
+	case http.MethodGet:
+		if req.URL.Query().Get("watch") == "true" {
+			return "watch"
+		}
+		if collection {
+			return "list"
+		}
+		return "get"
+	case http.MethodDelete:
+		if collection {
+			return "deletecollection"
+		}
+		return "delete"
+	}
+	return method
+}
+
+// isCollection returns true if the path refers to a collection of resources rather than a single resource.
+// The second value is false when the path doesn't refer to resources at all.
+func isCollection(path string) (bool, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) > 2 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) > 3 && parts[0] == "apis":
+		parts = parts[3:]
+	default:
+		return false, false // e.g. discovery
+	}
+
+	// Namespaced resources, as opposed to the namespaces themselves
+	if parts[0] == "namespaces" && len(parts) > 2 {
+		parts = parts[2:]
+	}
+	return len(parts) == 1, true
+}
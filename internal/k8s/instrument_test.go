@@ -0,0 +1,57 @@
+package k8s
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestRequestVerb(t *testing.T) {
+	tests := []struct {
+		Method, URL, Expected string
+	}{
+		{Method: "GET", URL: "/api/v1/namespaces/default/configmaps/foo", Expected: "get"},
+		{Method: "GET", URL: "/api/v1/namespaces/default/configmaps", Expected: "list"},
+		{Method: "GET", URL: "/api/v1/namespaces/default/configmaps?watch=true", Expected: "watch"},
+		{Method: "GET", URL: "/api/v1/namespaces", Expected: "list"},
+		{Method: "GET", URL: "/api/v1/namespaces/default", Expected: "get"},
+		{Method: "GET", URL: "/apis/apps/v1/deployments", Expected: "list"},
+		{Method: "GET", URL: "/apis/eno.azure.io/v1/namespaces/default/compositions/foo/status", Expected: "get"},
+		{Method: "POST", URL: "/api/v1/namespaces/default/configmaps", Expected: "create"},
+		{Method: "PUT", URL: "/apis/eno.azure.io/v1/namespaces/default/compositions/foo/status", Expected: "update"},
+		{Method: "PATCH", URL: "/apis/apps/v1/namespaces/default/deployments/foo", Expected: "patch"},
+		{Method: "DELETE", URL: "/apis/apps/v1/namespaces/default/deployments/foo", Expected: "delete"},
+		{Method: "DELETE", URL: "/apis/apps/v1/namespaces/default/deployments", Expected: "deletecollection"},
+		{Method: "GET", URL: "/apis", Expected: "get"},
+		{Method: "GET", URL: "/apis/apps/v1", Expected: "get"},
+		{Method: "GET", URL: "/healthz", Expected: "get"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Method+" "+tc.URL, func(t *testing.T) {
+			req := httptest.NewRequest(tc.Method, tc.URL, nil)
+			assert.Equal(t, tc.Expected, requestVerb(req))
+		})
+	}
+}
+
+func TestInstrumentClient(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer svr.Close()
+
+	rc := &rest.Config{Host: svr.URL}
+	conf := InstrumentClient(rc, Downstream)
+	assert.Nil(t, rc.WrapTransport, "input is not mutated")
+
+	hc, err := rest.HTTPClientFor(conf)
+	require.NoError(t, err)
+	resp, err := hc.Get(svr.URL + "/api/v1/namespaces/default/configmaps/foo")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
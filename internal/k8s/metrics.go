@@ -0,0 +1,42 @@
+package k8s
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Azure/eno/internal/telemetry"
+)
+
+var (
+	downstreamAuthFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eno_downstream_auth_failures_total",
+			Help: "Requests to the downstream apiserver rejected because of expired/invalid credentials (401) or missing permissions (403)",
+		}, []string{"code"},
+	)
+
+	clientRequestLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "eno_apiserver_request_duration_seconds",
+			Help:    "Samples latency of requests to the upstream or downstream apiserver (apiserver label), partitioned by verb. Watches aren't included",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0},
+		}, []string{"apiserver", "verb"},
+	)
+
+	clientRequestErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eno_apiserver_request_errors_total",
+			Help: "Requests to the upstream or downstream apiserver (apiserver label) that failed with a server error, throttling (429), or no response (code=error), partitioned by verb",
+		}, []string{"apiserver", "verb", "code"},
+	)
+
+	credentialReloads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eno_downstream_credential_reloads_total",
+			Help: "Reloads of downstream credentials that changed, partitioned by result (success or error)",
+		}, []string{"result"},
+	)
+)
+
+func init() {
+	telemetry.MustRegister(downstreamAuthFailures, clientRequestLatency, clientRequestErrors, credentialReloads)
+}
@@ -0,0 +1,122 @@
+package k8s
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TLSSecretReloader keeps the client certificate and CA bundle used to connect to an apiserver in sync with a Secret,
+// so they can be rotated without restarting the process.
+//
+// The Secret's tls.crt and tls.key hold the client certificate, and ca.crt holds the CA bundle (like cert-manager's Secrets).
+// The rest.Config's own certificates are used for any keys that are missing.
+type TLSSecretReloader struct {
+	reader   client.Reader
+	secret   types.NamespacedName
+	interval time.Duration
+
+	base  rest.TLSClientConfig
+	proxy func(*http.Request) (*url.URL, error)
+	dial  func(ctx context.Context, network, address string) (net.Conn, error)
+
+	mut     sync.Mutex
+	version string
+	current atomic.Pointer[http.RoundTripper]
+}
+
+func NewTLSSecretReloader(reader client.Reader, secret types.NamespacedName, interval time.Duration) *TLSSecretReloader {
+	return &TLSSecretReloader{reader: reader, secret: secret, interval: interval}
+}
+
+// Apply returns a copy of the config that uses the Secret's certificates.
+// Load must succeed before the config is used.
+func (t *TLSSecretReloader) Apply(rc *rest.Config) *rest.Config {
+	t.base = rc.TLSClientConfig
+	t.proxy = rc.Proxy
+	t.dial = rc.Dial
+
+	conf := rest.CopyConfig(rc)
+	conf.TLSClientConfig = rest.TLSClientConfig{} // client-go doesn't allow TLS options with a custom transport
+	conf.Transport = t
+	return conf
+}
+
+// Start reloads the Secret periodically. Errors are logged, and the previous certificates are used until the Secret is fixed.
+func (t *TLSSecretReloader) Start(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := t.Load(ctx); err != nil {
+			logger.Error(err, "reloading downstream TLS secret")
+		}
+	}
+}
+
+// Load reads the Secret and starts using its certificates for new connections if it has changed.
+func (t *TLSSecretReloader) Load(ctx context.Context) error {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	secret := &corev1.Secret{}
+	if err := t.reader.Get(ctx, t.secret, secret); err != nil {
+		return fmt.Errorf("getting secret: %w", err)
+	}
+	if secret.ResourceVersion != "" && secret.ResourceVersion == t.version {
+		return nil
+	}
+
+	conf := t.base
+	if ca := secret.Data["ca.crt"]; len(ca) > 0 {
+		if !x509.NewCertPool().AppendCertsFromPEM(ca) {
+			return errors.New("ca.crt doesn't contain any valid certificates")
+		}
+		conf.CAData, conf.CAFile = ca, ""
+	}
+	crt, key := secret.Data["tls.crt"], secret.Data["tls.key"]
+	if len(crt) > 0 || len(key) > 0 {
+		conf.CertData, conf.CertFile = crt, ""
+		conf.KeyData, conf.KeyFile = key, ""
+	}
+
+	rt, err := rest.TransportFor(&rest.Config{TLSClientConfig: conf, Proxy: t.proxy, Dial: t.dial})
+	if err != nil {
+		return fmt.Errorf("building transport: %w", err)
+	}
+
+	// Connections using the previous certificates are closed once they're idle
+	if prev := t.current.Swap(&rt); prev != nil {
+		utilnet.CloseIdleConnectionsFor(*prev)
+	}
+	t.version = secret.ResourceVersion
+	logr.FromContextOrDiscard(ctx).V(0).Info("loaded downstream TLS secret", "resourceVersion", secret.ResourceVersion)
+	return nil
+}
+
+func (t *TLSSecretReloader) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt := t.current.Load()
+	if rt == nil {
+		return nil, errors.New("downstream TLS secret hasn't been loaded")
+	}
+	return (*rt).RoundTrip(req)
+}
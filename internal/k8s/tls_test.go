@@ -0,0 +1,148 @@
+package k8s
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Azure/eno/internal/testutil"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM encoded certificate and key signed by the CA.
+func (c *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, c.cert, &key.PublicKey, c.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestTLSSecretReloader(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	ca := newTestCA(t)
+
+	// The server requires client certificates signed by the CA, and uses an SNI name that doesn't match its address
+	serverCert, serverKey := ca.issue(t, "apiserver.internal", x509.ExtKeyUsageServerAuth)
+	pair, err := tls.X509KeyPair(serverCert, serverKey)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	svr := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	svr.TLS = &tls.Config{Certificates: []tls.Certificate{pair}, ClientCAs: pool, ClientAuth: tls.RequireAndVerifyClientCert}
+	svr.StartTLS()
+	defer svr.Close()
+
+	clientCert, clientKey := ca.issue(t, "client-1", x509.ExtKeyUsageClientAuth)
+	secret := &corev1.Secret{}
+	secret.Name = "downstream-tls"
+	secret.Namespace = "default"
+	secret.Data = map[string][]byte{"ca.crt": ca.pem, "tls.crt": clientCert, "tls.key": clientKey}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	reloader := NewTLSSecretReloader(cli, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, time.Hour)
+	conf := reloader.Apply(&rest.Config{Host: svr.URL, TLSClientConfig: rest.TLSClientConfig{ServerName: "apiserver.internal"}})
+	assert.Equal(t, rest.TLSClientConfig{}, conf.TLSClientConfig)
+
+	hc, err := rest.HTTPClientFor(conf)
+	require.NoError(t, err)
+	get := func() (string, error) {
+		resp, err := hc.Get(svr.URL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		buf := make([]byte, 64)
+		n, _ := resp.Body.Read(buf)
+		return string(buf[:n]), nil
+	}
+
+	// Not loaded yet
+	_, err = get()
+	assert.Error(t, err)
+
+	require.NoError(t, reloader.Load(ctx))
+	name, err := get()
+	require.NoError(t, err)
+	assert.Equal(t, "client-1", name)
+
+	// Rotation
+	clientCert, clientKey = ca.issue(t, "client-2", x509.ExtKeyUsageClientAuth)
+	secret.Data["tls.crt"] = clientCert
+	secret.Data["tls.key"] = clientKey
+	require.NoError(t, cli.Update(ctx, secret))
+	require.NoError(t, reloader.Load(ctx))
+
+	name, err = get()
+	require.NoError(t, err)
+	assert.Equal(t, "client-2", name)
+
+	// Invalid secrets are rejected and the previous certificates are still used
+	secret.Data["ca.crt"] = []byte("not a cert")
+	require.NoError(t, cli.Update(ctx, secret))
+	assert.Error(t, reloader.Load(ctx))
+
+	name, err = get()
+	require.NoError(t, err)
+	assert.Equal(t, "client-2", name)
+}
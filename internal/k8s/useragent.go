@@ -0,0 +1,33 @@
+package k8s
+
+import (
+	"net/http"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/Azure/eno/internal/manager"
+)
+
+// WithControllerUserAgent returns a copy of the config that appends the name of the controller making each request
+// (see manager.WithController) to its user agent e.g. eno-controller/synthesisController.
+// The user agent's prefix is unchanged, so the apiserver's default field manager name is too.
+func WithControllerUserAgent(rc *rest.Config) *rest.Config {
+	conf := rest.CopyConfig(rc)
+	conf.Wrap(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			name := manager.ControllerFromContext(req.Context())
+			if name == "" {
+				return next.RoundTrip(req)
+			}
+
+			ua := req.Header.Get("User-Agent")
+			if ua == "" {
+				ua = rest.DefaultKubernetesUserAgent()
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set("User-Agent", ua+"/"+name)
+			return next.RoundTrip(req)
+		})
+	})
+	return conf
+}
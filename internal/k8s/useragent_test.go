@@ -0,0 +1,39 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+
+	"github.com/Azure/eno/internal/manager"
+)
+
+func TestWithControllerUserAgent(t *testing.T) {
+	var ua atomic.Value
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ua.Store(r.Header.Get("User-Agent"))
+	}))
+	defer svr.Close()
+
+	conf := WithControllerUserAgent(&rest.Config{Host: svr.URL, UserAgent: "eno-controller"})
+	hc, err := rest.HTTPClientFor(conf)
+	require.NoError(t, err)
+
+	get := func(ctx context.Context) string {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, svr.URL, nil)
+		require.NoError(t, err)
+		resp, err := hc.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return ua.Load().(string)
+	}
+
+	assert.Equal(t, "eno-controller", get(context.Background()))
+	assert.Equal(t, "eno-controller/synthesisController", get(manager.NewControllerContext(context.Background(), "synthesisController")))
+}
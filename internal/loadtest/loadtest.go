@@ -0,0 +1,274 @@
+// Package loadtest generates synthetic compositions against a cluster running Eno
+// and measures how long it takes for them to converge.
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+const (
+	revisionEnvVar = "LOADTEST_REVISION"
+	runLabel       = "eno.azure.io/loadtest"
+)
+
+// Config describes the load to generate.
+type Config struct {
+	Compositions int
+	Resources    int
+	Namespace    string
+
+	// MutationRate is the number of composition updates per second once every composition has converged.
+	MutationRate float64
+	Duration     time.Duration
+
+	// Image must have bash, since the synthesizer is a shell script.
+	Image string
+}
+
+// Run creates the configured compositions, mutates them for the configured duration, and reports convergence latencies.
+// Compositions created by previous runs are deleted first.
+func Run(ctx context.Context, cli client.Client, cfg *Config) (*Report, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	if err := Cleanup(ctx, cli, cfg.Namespace); err != nil {
+		return nil, err
+	}
+
+	synth := NewSynthesizer(cfg)
+	err := cli.Create(ctx, synth)
+	if errors.IsAlreadyExists(err) {
+		current := &apiv1.Synthesizer{}
+		if err := cli.Get(ctx, client.ObjectKeyFromObject(synth), current); err != nil {
+			return nil, err
+		}
+		current.Spec = synth.Spec
+		err = cli.Update(ctx, current)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("writing synthesizer: %w", err)
+	}
+
+	t := newTracker()
+	for i := 0; i < cfg.Compositions; i++ {
+		comp := newComposition(cfg, synth, i)
+		t.Start(comp.Name, "0")
+		if err := cli.Create(ctx, comp); err != nil {
+			return nil, fmt.Errorf("creating composition: %w", err)
+		}
+	}
+	logger.Info("created compositions", "count", cfg.Compositions)
+
+	pollCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go poll(pollCtx, cli, cfg.Namespace, t)
+
+	if err := t.WaitForAll(ctx); err != nil {
+		return nil, err
+	}
+	report := &Report{Initial: t.Reset()}
+	logger.Info("initial convergence complete", "p50", report.Initial.P50)
+
+	if cfg.MutationRate > 0 {
+		if err := mutate(ctx, cli, cfg, t); err != nil {
+			return nil, err
+		}
+		if err := t.WaitForAll(ctx); err != nil {
+			return nil, err
+		}
+		report.Mutations = t.Reset()
+	}
+
+	return report, nil
+}
+
+// Cleanup deletes the compositions created by load tests in the given namespace.
+func Cleanup(ctx context.Context, cli client.Client, namespace string) error {
+	err := cli.DeleteAllOf(ctx, &apiv1.Composition{}, client.InNamespace(namespace), client.HasLabels{runLabel})
+	if err != nil {
+		return fmt.Errorf("deleting compositions: %w", err)
+	}
+	return nil
+}
+
+// NewSynthesizer returns a synthesizer that produces the configured number of ConfigMaps per composition.
+// Every ConfigMap includes the composition's revision, so mutations result in downstream writes.
+func NewSynthesizer(cfg *Config) *apiv1.Synthesizer {
+	script := fmt.Sprintf(`set -e
+echo -n '{"apiVersion":"config.kubernetes.io/v1","kind":"ResourceList","items":['
+for i in $(seq 1 %d); do
+  [ "$i" -gt 1 ] && echo -n ','
+  echo -n "{\"apiVersion\":\"v1\",\"kind\":\"ConfigMap\",\"metadata\":{\"name\":\"${COMPOSITION_NAME}-${i}\",\"namespace\":\"%s\"},\"data\":{\"revision\":\"${%s}\"}}"
+done
+echo ']}'`, cfg.Resources, cfg.Namespace, revisionEnvVar)
+
+	synth := &apiv1.Synthesizer{}
+	synth.Name = "eno-loadtest"
+	synth.Spec.Image = cfg.Image
+	synth.Spec.Command = []string{"/bin/bash", "-c", script}
+	return synth
+}
+
+func newComposition(cfg *Config, synth *apiv1.Synthesizer, i int) *apiv1.Composition {
+	comp := &apiv1.Composition{}
+	comp.Name = fmt.Sprintf("eno-loadtest-%d", i)
+	comp.Namespace = cfg.Namespace
+	comp.Labels = map[string]string{runLabel: "true"}
+	comp.Spec.Synthesizer.Name = synth.Name
+	comp.Spec.SynthesisEnv = []apiv1.EnvVar{{Name: revisionEnvVar, Value: "0"}}
+	return comp
+}
+
+func mutate(ctx context.Context, cli client.Client, cfg *Config, t *tracker) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	limiter := rate.NewLimiter(rate.Limit(cfg.MutationRate), 1)
+	deadline := time.Now().Add(cfg.Duration)
+
+	var n int
+	for time.Now().Before(deadline) {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+		n++
+
+		comp := &apiv1.Composition{}
+		comp.Name = fmt.Sprintf("eno-loadtest-%d", rand.Intn(cfg.Compositions))
+		comp.Namespace = cfg.Namespace
+		revision := strconv.Itoa(n)
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := cli.Get(ctx, client.ObjectKeyFromObject(comp), comp); err != nil {
+				return err
+			}
+			comp.Spec.SynthesisEnv = []apiv1.EnvVar{{Name: revisionEnvVar, Value: revision}}
+			return cli.Update(ctx, comp)
+		})
+		if err != nil {
+			return fmt.Errorf("updating composition: %w", err)
+		}
+		t.Start(comp.Name, revision)
+	}
+	logger.Info("finished mutating compositions", "count", n)
+	return nil
+}
+
+// poll observes the convergence of every composition until the context is canceled.
+func poll(ctx context.Context, cli client.Client, namespace string, t *tracker) {
+	logger := logr.FromContextOrDiscard(ctx)
+	ticker := time.NewTicker(time.Millisecond * 250)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		list := &apiv1.CompositionList{}
+		err := cli.List(ctx, list, client.InNamespace(namespace), client.HasLabels{runLabel})
+		if err != nil {
+			logger.Error(err, "listing compositions")
+			continue
+		}
+		for _, comp := range list.Items {
+			if converged(&comp) {
+				t.Observe(comp.Name, revisionOf(&comp))
+			}
+		}
+	}
+}
+
+func converged(comp *apiv1.Composition) bool {
+	syn := comp.Status.CurrentSynthesis
+	return syn != nil && syn.Ready != nil && syn.ObservedCompositionGeneration == comp.Generation && comp.Status.PendingResynthesis == nil
+}
+
+func revisionOf(comp *apiv1.Composition) string {
+	for _, env := range comp.Spec.SynthesisEnv {
+		if env.Name == revisionEnvVar {
+			return env.Value
+		}
+	}
+	return ""
+}
+
+// tracker records the time between a composition's revision being written and its convergence.
+type tracker struct {
+	lock      sync.Mutex
+	pending   map[string]pendingRevision
+	latencies []time.Duration
+	done      chan struct{}
+}
+
+type pendingRevision struct {
+	Revision string
+	Started  time.Time
+}
+
+func newTracker() *tracker {
+	return &tracker{pending: map[string]pendingRevision{}, done: make(chan struct{}, 1)}
+}
+
+// Start records that the given revision of a composition has been written.
+// Convergence of previous revisions that haven't been observed yet is no longer tracked.
+func (t *tracker) Start(name, revision string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.pending[name] = pendingRevision{Revision: revision, Started: time.Now()}
+}
+
+// Observe records that the given revision of a composition has converged.
+func (t *tracker) Observe(name, revision string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	p, ok := t.pending[name]
+	if !ok || p.Revision != revision {
+		return
+	}
+	t.latencies = append(t.latencies, time.Since(p.Started))
+	delete(t.pending, name)
+	if len(t.pending) == 0 {
+		select {
+		case t.done <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// WaitForAll blocks until every started revision has converged.
+func (t *tracker) WaitForAll(ctx context.Context) error {
+	for {
+		t.lock.Lock()
+		n := len(t.pending)
+		t.lock.Unlock()
+		if n == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d compositions did not converge: %w", n, ctx.Err())
+		case <-t.done:
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Reset returns the distribution of the latencies observed since the last reset.
+func (t *tracker) Reset() *Distribution {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	d := NewDistribution(t.latencies)
+	t.latencies = nil
+	return d
+}
@@ -0,0 +1,95 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+func TestNewDistribution(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Latencies []time.Duration
+		Expected  Distribution
+	}{
+		{
+			Name:     "empty",
+			Expected: Distribution{},
+		},
+		{
+			Name:      "single",
+			Latencies: []time.Duration{time.Second},
+			Expected:  Distribution{Count: 1, P50: time.Second, P90: time.Second, P99: time.Second, Max: time.Second},
+		},
+		{
+			Name:      "unsorted",
+			Latencies: []time.Duration{4, 10, 1, 7, 3, 9, 2, 6, 8, 5},
+			Expected:  Distribution{Count: 10, P50: 5, P90: 9, P99: 10, Max: 10},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, *NewDistribution(tc.Latencies))
+		})
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tr := newTracker()
+	tr.Start("a", "0")
+	tr.Start("b", "0")
+	tr.Start("b", "1") // supersedes the previous revision
+
+	tr.Observe("a", "0")
+	tr.Observe("b", "0")
+	tr.Observe("c", "0") // never started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	require.Error(t, tr.WaitForAll(ctx))
+
+	tr.Observe("b", "1")
+	require.NoError(t, tr.WaitForAll(context.Background()))
+	assert.Equal(t, 2, tr.Reset().Count)
+	assert.Equal(t, 0, tr.Reset().Count)
+}
+
+func TestConverged(t *testing.T) {
+	comp := &apiv1.Composition{}
+	comp.Generation = 2
+	assert.False(t, converged(comp))
+
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{ObservedCompositionGeneration: 2}
+	assert.False(t, converged(comp))
+
+	now := metav1.Now()
+	comp.Status.CurrentSynthesis.Ready = &now
+	assert.True(t, converged(comp))
+
+	comp.Generation = 3
+	assert.False(t, converged(comp))
+}
+
+func TestNewSynthesizer(t *testing.T) {
+	synth := NewSynthesizer(&Config{Resources: 3, Namespace: "test-ns", Image: "test-image"})
+	assert.Equal(t, "test-image", synth.Spec.Image)
+	require.Len(t, synth.Spec.Command, 3)
+	assert.Contains(t, synth.Spec.Command[2], "seq 1 3")
+	assert.Contains(t, synth.Spec.Command[2], `\"namespace\":\"test-ns\"`)
+	assert.Contains(t, synth.Spec.Command[2], "${LOADTEST_REVISION}")
+}
+
+func TestReportPrint(t *testing.T) {
+	r := &Report{Initial: &Distribution{Count: 2, P50: time.Second, P90: time.Second, P99: time.Second, Max: time.Second}}
+	buf := &bytes.Buffer{}
+	r.Print(buf)
+	assert.Contains(t, buf.String(), "initial")
+	assert.NotContains(t, buf.String(), "mutations")
+}
@@ -0,0 +1,70 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Report holds the convergence latencies of a load test run.
+type Report struct {
+	// Initial is the time from creation to readiness of every composition.
+	Initial *Distribution `json:"initial"`
+
+	// Mutations is the time from update to readiness of the new revision.
+	// Nil when mutations are disabled.
+	Mutations *Distribution `json:"mutations,omitempty"`
+}
+
+// Distribution summarizes a set of latencies.
+type Distribution struct {
+	Count int           `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+func NewDistribution(latencies []time.Duration) *Distribution {
+	d := &Distribution{Count: len(latencies)}
+	if len(latencies) == 0 {
+		return d
+	}
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	d.P50 = percentile(sorted, 50)
+	d.P90 = percentile(sorted, 90)
+	d.P99 = percentile(sorted, 99)
+	d.Max = sorted[len(sorted)-1]
+	return d
+}
+
+// percentile uses the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Print writes the report as a table.
+func (r *Report) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer tw.Flush()
+	fmt.Fprintf(tw, "PHASE\tCOUNT\tP50\tP90\tP99\tMAX\n")
+	for _, phase := range []struct {
+		Name string
+		Dist *Distribution
+	}{{"initial", r.Initial}, {"mutations", r.Mutations}} {
+		if phase.Dist == nil {
+			continue
+		}
+		d := phase.Dist
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n", phase.Name, d.Count, d.P50, d.P90, d.P99, d.Max)
+	}
+}
@@ -0,0 +1,28 @@
+package manager
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type controllerNameKey struct{}
+
+// WithController returns a reconciler that records the name of its controller in the context of every reconciliation,
+// such that requests made to the apiserver while reconciling can be attributed to the controller.
+func WithController(name string, r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		return r.Reconcile(NewControllerContext(ctx, name), req)
+	})
+}
+
+// NewControllerContext returns a context that identifies the named controller. See ControllerFromContext.
+func NewControllerContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, controllerNameKey{}, name)
+}
+
+// ControllerFromContext returns the name of the controller that is reconciling in the given context, or an empty string.
+func ControllerFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(controllerNameKey{}).(string)
+	return name
+}
@@ -0,0 +1,23 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestWithController(t *testing.T) {
+	assert.Empty(t, ControllerFromContext(context.Background()))
+
+	var name string
+	r := WithController("testController", reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		name = ControllerFromContext(ctx)
+		return reconcile.Result{}, nil
+	}))
+	_, err := r.Reconcile(context.Background(), reconcile.Request{})
+	require.NoError(t, err)
+	assert.Equal(t, "testController", name)
+}
@@ -20,6 +20,8 @@ const (
 	IdxResourceSlicesByComposition = ".resourceSlicesByComposition"
 	IdxCompositionsByBinding       = ".compositionsByBinding"
 	IdxSynthesizersByRef           = ".synthesizersByRef"
+	IdxCompositionsByState         = ".status.simplified.status"
+	IdxCompositionsByUpstream      = ".compositionsByUpstream"
 
 	CompositionNameLabelKey      = "eno.azure.io/composition-name"
 	CompositionNamespaceLabelKey = "eno.azure.io/composition-namespace"
@@ -73,6 +75,18 @@ func indexController() client.IndexerFunc {
 	}
 }
 
+// indexSynthesizer indexes compositions by the name of their synthesizer,
+// so controllers can find every composition of a synthesizer without listing the entire fleet.
+func indexSynthesizer() client.IndexerFunc {
+	return func(o client.Object) []string {
+		comp, ok := o.(*apiv1.Composition)
+		if !ok {
+			return nil
+		}
+		return []string{comp.Spec.Synthesizer.Name}
+	}
+}
+
 func indexResourceBindings() client.IndexerFunc {
 	return func(o client.Object) []string {
 		comp, ok := o.(*apiv1.Composition)
@@ -102,3 +116,31 @@ func indexSynthRefs() client.IndexerFunc {
 		return keys
 	}
 }
+
+// indexCompositionState indexes compositions by their simplified status e.g. "Ready".
+// Compositions that haven't been summarized yet aren't indexed.
+func indexCompositionState() client.IndexerFunc {
+	return func(o client.Object) []string {
+		comp, ok := o.(*apiv1.Composition)
+		if !ok || comp.Status.Simplified == nil || comp.Status.Simplified.Status == "" {
+			return nil
+		}
+		return []string{comp.Status.Simplified.Status}
+	}
+}
+
+// indexUpstreams indexes compositions by the "namespace/name" of each upstream composition they consume.
+func indexUpstreams() client.IndexerFunc {
+	return func(o client.Object) []string {
+		comp, ok := o.(*apiv1.Composition)
+		if !ok {
+			return nil
+		}
+
+		keys := []string{}
+		for _, up := range comp.Spec.Upstreams {
+			keys = append(keys, path.Join(comp.Namespace, up.Composition))
+		}
+		return keys
+	}
+}
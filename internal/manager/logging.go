@@ -0,0 +1,61 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// LogLevelAnnotation can be set to "debug" to elevate the log verbosity of a particular composition.
+	LogLevelAnnotation = "eno.azure.io/log-level"
+
+	// LogLevelExpirationAnnotation bounds the window during which LogLevelAnnotation is honored (RFC3339).
+	LogLevelExpirationAnnotation = "eno.azure.io/log-level-expiration"
+
+	// MaxDebugLoggingWindow caps the debug logging window to avoid unintentionally permanent log floods.
+	MaxDebugLoggingWindow = time.Hour * 24
+)
+
+// DebugLoggingEnabled returns true when the given object has opted into debug logging
+// and the (required) expiration time is in the future but within MaxDebugLoggingWindow.
+func DebugLoggingEnabled(obj client.Object, now time.Time) bool {
+	anno := obj.GetAnnotations()
+	if anno == nil || anno[LogLevelAnnotation] != "debug" {
+		return false
+	}
+	exp, err := time.Parse(time.RFC3339, anno[LogLevelExpirationAnnotation])
+	if err != nil {
+		return false
+	}
+	return exp.After(now) && exp.Sub(now) <= MaxDebugLoggingWindow
+}
+
+// WithDebugLogging returns a logger that emits debug (V(1)+) messages at the default verbosity,
+// regardless of the globally configured log level.
+func WithDebugLogging(logger logr.Logger) logr.Logger {
+	sink := logger.GetSink()
+	if sink == nil {
+		return logger
+	}
+	return logr.New(&debugSink{LogSink: sink}).WithValues("debugLogging", true)
+}
+
+type debugSink struct {
+	logr.LogSink
+}
+
+func (d *debugSink) Enabled(level int) bool { return d.LogSink.Enabled(0) }
+
+func (d *debugSink) Info(level int, msg string, keysAndValues ...any) {
+	d.LogSink.Info(0, msg, keysAndValues...)
+}
+
+func (d *debugSink) WithValues(keysAndValues ...any) logr.LogSink {
+	return &debugSink{LogSink: d.LogSink.WithValues(keysAndValues...)}
+}
+
+func (d *debugSink) WithName(name string) logr.LogSink {
+	return &debugSink{LogSink: d.LogSink.WithName(name)}
+}
@@ -0,0 +1,64 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugLoggingEnabled(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		Name        string
+		Annotations map[string]string
+		Expected    bool
+	}{
+		{
+			Name: "no annotations",
+		},
+		{
+			Name:        "missing expiration",
+			Annotations: map[string]string{LogLevelAnnotation: "debug"},
+		},
+		{
+			Name:        "expired",
+			Annotations: map[string]string{LogLevelAnnotation: "debug", LogLevelExpirationAnnotation: now.Add(-time.Minute).Format(time.RFC3339)},
+		},
+		{
+			Name:        "window too long",
+			Annotations: map[string]string{LogLevelAnnotation: "debug", LogLevelExpirationAnnotation: now.Add(MaxDebugLoggingWindow * 2).Format(time.RFC3339)},
+		},
+		{
+			Name:        "wrong level",
+			Annotations: map[string]string{LogLevelAnnotation: "info", LogLevelExpirationAnnotation: now.Add(time.Hour).Format(time.RFC3339)},
+		},
+		{
+			Name:        "enabled",
+			Annotations: map[string]string{LogLevelAnnotation: "debug", LogLevelExpirationAnnotation: now.Add(time.Hour).Format(time.RFC3339)},
+			Expected:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			comp := &apiv1.Composition{}
+			comp.Annotations = tc.Annotations
+			assert.Equal(t, tc.Expected, DebugLoggingEnabled(comp, now))
+		})
+	}
+}
+
+func TestWithDebugLogging(t *testing.T) {
+	var lines []string
+	logger := funcr.New(func(prefix, args string) { lines = append(lines, args) }, funcr.Options{Verbosity: 0})
+
+	logger.V(1).Info("dropped")
+	assert.Len(t, lines, 0)
+
+	WithDebugLogging(logger).WithValues("foo", "bar").V(1).Info("elevated")
+	assert.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "elevated")
+}
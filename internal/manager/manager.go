@@ -30,6 +30,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	apiv1 "github.com/Azure/eno/api/v1"
 )
@@ -104,6 +105,13 @@ func newMgr(logger logr.Logger, opts *Options, isController, isReconciler bool)
 		LeaderElectionReleaseOnCancel: true,
 	}
 
+	if opts.WebhookPort != 0 {
+		mgrOpts.WebhookServer = webhook.NewServer(webhook.Options{
+			Port:    opts.WebhookPort,
+			CertDir: opts.WebhookCertDir,
+		})
+	}
+
 	if ratioStr := os.Getenv("CHAOS_RATIO"); ratioStr != "" {
 		mgrOpts.NewClient = func(config *rest.Config, options client.Options) (client.Client, error) {
 			base, err := client.New(config, options)
@@ -160,10 +168,7 @@ func newMgr(logger logr.Logger, opts *Options, isController, isReconciler bool)
 			return nil, err
 		}
 
-		err = mgr.GetFieldIndexer().IndexField(context.Background(), &apiv1.Composition{}, IdxCompositionsBySynthesizer, func(o client.Object) []string {
-			comp := o.(*apiv1.Composition)
-			return []string{comp.Spec.Synthesizer.Name}
-		})
+		err = mgr.GetFieldIndexer().IndexField(context.Background(), &apiv1.Composition{}, IdxCompositionsBySynthesizer, indexSynthesizer())
 		if err != nil {
 			return nil, err
 		}
@@ -178,6 +183,16 @@ func newMgr(logger logr.Logger, opts *Options, isController, isReconciler bool)
 			return nil, err
 		}
 
+		err = mgr.GetFieldIndexer().IndexField(context.Background(), &apiv1.Composition{}, IdxCompositionsByState, indexCompositionState())
+		if err != nil {
+			return nil, err
+		}
+
+		err = mgr.GetFieldIndexer().IndexField(context.Background(), &apiv1.Composition{}, IdxCompositionsByUpstream, indexUpstreams())
+		if err != nil {
+			return nil, err
+		}
+
 		err = mgr.GetFieldIndexer().IndexField(context.Background(), &apiv1.Synthesizer{}, IdxSynthesizersByRef, indexSynthRefs())
 		if err != nil {
 			return nil, err
@@ -141,6 +141,39 @@ func TestManagerBasics(t *testing.T) {
 			time.Sleep(time.Millisecond * 50)
 		}
 	})
+
+	// Prove compositions can be listed by state
+	t.Run("composition state index", func(t *testing.T) {
+		comp := &apiv1.Composition{}
+		comp.Name = "test-comp"
+		comp.Namespace = "default"
+		err = mgr.GetClient().Create(ctx, comp)
+		require.NoError(t, err)
+
+		comp.Status.Simplified = &apiv1.SimplifiedStatus{Status: "Ready"}
+		err = mgr.GetClient().Status().Update(ctx, comp)
+		require.NoError(t, err)
+
+		for i := 0; true; i++ {
+			list := &apiv1.CompositionList{}
+			err = mgr.GetCache().List(ctx, list, client.MatchingFields{IdxCompositionsByState: "Ready"})
+			require.NoError(t, err)
+			if len(list.Items) == 1 {
+				break
+			}
+
+			// importing testutil would cause a cycle
+			if i > 50 {
+				t.Fatalf("timeout")
+			}
+			time.Sleep(time.Millisecond * 50)
+		}
+
+		list := &apiv1.CompositionList{}
+		err = mgr.GetCache().List(ctx, list, client.MatchingFields{IdxCompositionsByState: "NotReady"})
+		require.NoError(t, err)
+		assert.Empty(t, list.Items)
+	})
 }
 
 // TestReconcilerLimitedScope proves that the reconciler can be scoped down to a namespace + label selector.
@@ -22,6 +22,10 @@ type Options struct {
 	SynthesizerPodNamespace string  // set in cmd from synthesis config
 	qps                     float64 // flags don't support float32, bind to this value and copy over to Rest.QPS during initialization
 
+	// The webhook server is disabled when WebhookPort is zero
+	WebhookPort    int
+	WebhookCertDir string
+
 	// Only set by cmd in reconciler process
 	CompositionNamespace string
 	CompositionSelector  labels.Selector
@@ -30,6 +34,8 @@ type Options struct {
 func (o *Options) Bind(set *flag.FlagSet) {
 	set.StringVar(&o.HealthProbeAddr, "health-probe-addr", ":8081", "Address to serve health probes on")
 	set.StringVar(&o.MetricsAddr, "metrics-addr", ":8080", "Address to serve Prometheus metrics on")
+	set.IntVar(&o.WebhookPort, "webhook-port", 0, "Port to serve admission webhooks on. Zero disables webhooks")
+	set.StringVar(&o.WebhookCertDir, "webhook-cert-dir", "", "Directory containing the webhook server's tls.crt and tls.key. Defaults to controller-runtime's default location")
 	set.IntVar(&o.Rest.Burst, "burst", 50, "apiserver client rate limiter burst configuration")
 	set.Float64Var(&o.qps, "qps", 20, "Max requests per second to apiserver")
 	set.BoolVar(&o.LeaderElection, "leader-election", false, "Enable leader election")
@@ -0,0 +1,121 @@
+// Package migration rewrites stored Eno resources using the current storage version of their CRD.
+// This is necessary before a previous API version can be removed from a CRD.
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+const pageSize = 500
+
+var crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+// Resources are the Eno resource types that are migrated, keyed by the plural name of their CRD.
+var Resources = map[string]string{
+	"compositions":    "Composition",
+	"synthesizers":    "Synthesizer",
+	"resourceslices":  "ResourceSlice",
+	"symphonies":      "Symphony",
+	"identifierpools": "IdentifierPool",
+	"enoquotas":       "EnoQuota",
+}
+
+// MigrateStorage rewrites every Eno resource such that the apiserver persists it using the current storage version,
+// and then records the storage version as the only stored version of each CRD.
+func MigrateStorage(ctx context.Context, cli client.Client) error {
+	logger := logr.FromContextOrDiscard(ctx)
+	for plural, kind := range Resources {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(crdGVK)
+		err := cli.Get(ctx, types.NamespacedName{Name: plural + "." + apiv1.SchemeGroupVersion.Group}, crd)
+		if err != nil {
+			return fmt.Errorf("getting crd for %s: %w", kind, err)
+		}
+		version := storageVersion(crd)
+		if version == "" {
+			return fmt.Errorf("crd for %s doesn't have a storage version", kind)
+		}
+
+		n, err := migrateKind(ctx, cli, schema.GroupVersionKind{Group: apiv1.SchemeGroupVersion.Group, Version: version, Kind: kind})
+		if err != nil {
+			return fmt.Errorf("migrating %s: %w", kind, err)
+		}
+
+		patch := client.MergeFrom(crd.DeepCopy())
+		err = unstructured.SetNestedStringSlice(crd.Object, []string{version}, "status", "storedVersions")
+		if err != nil {
+			return err
+		}
+		err = cli.Status().Patch(ctx, crd, patch)
+		if err != nil {
+			return fmt.Errorf("updating stored versions of %s crd: %w", kind, err)
+		}
+		logger.V(0).Info("migrated resources to storage version", "kind", kind, "version", version, "count", n)
+	}
+	return nil
+}
+
+// migrateKind writes every resource of the given kind back to the apiserver without modification.
+func migrateKind(ctx context.Context, cli client.Client, gvk schema.GroupVersionKind) (int, error) {
+	var n int
+	var cont string
+	for {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		err := cli.List(ctx, list, client.Limit(pageSize), client.Continue(cont))
+		if err != nil {
+			return n, fmt.Errorf("listing: %w", err)
+		}
+
+		for i := range list.Items {
+			item := &list.Items[i]
+			err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				err := cli.Update(ctx, item)
+				if errors.IsConflict(err) {
+					if err := cli.Get(ctx, client.ObjectKeyFromObject(item), item); err != nil {
+						return err
+					}
+				}
+				return err
+			})
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return n, fmt.Errorf("updating %s: %w", client.ObjectKeyFromObject(item), err)
+			}
+			n++
+		}
+
+		cont = list.GetContinue()
+		if cont == "" {
+			return n, nil
+		}
+	}
+}
+
+func storageVersion(crd *unstructured.Unstructured) string {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		version, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		if storage, _ := version["storage"].(bool); storage {
+			name, _ := version["name"].(string)
+			return name
+		}
+	}
+	return ""
+}
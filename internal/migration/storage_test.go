@@ -0,0 +1,49 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/testutil"
+)
+
+func TestMigrateKind(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+
+	for _, name := range []string{"foo", "bar"} {
+		comp := &apiv1.Composition{}
+		comp.Name = name
+		comp.Namespace = "default"
+		require.NoError(t, cli.Create(ctx, comp))
+	}
+
+	n, err := migrateKind(ctx, cli, apiv1.SchemeGroupVersion.WithKind("Composition"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	// Every object should have been written back
+	comp := &apiv1.Composition{}
+	comp.Name = "foo"
+	comp.Namespace = "default"
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(comp), comp))
+	assert.NotEqual(t, "1", comp.ResourceVersion)
+}
+
+func TestStorageVersion(t *testing.T) {
+	crd := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"versions": []any{
+				map[string]any{"name": "v1", "storage": false},
+				map[string]any{"name": "v2", "storage": true},
+			},
+		},
+	}}
+	assert.Equal(t, "v2", storageVersion(crd))
+	assert.Equal(t, "", storageVersion(&unstructured.Unstructured{Object: map[string]any{}}))
+}
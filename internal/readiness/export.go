@@ -0,0 +1,68 @@
+package readiness
+
+import (
+	"context"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Export represents a parsed CEL expression that extracts a named value from a resource.
+type Export struct {
+	Name  string
+	check *Check
+}
+
+// ParseExport parses the given CEL expression in the context of an environment.
+func ParseExport(env *Env, name, expr string) (*Export, error) {
+	check, err := ParseCheck(env, expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Export{Name: name, check: check}, nil
+}
+
+// Eval returns the string representation of the expression's value for the given resource.
+// False is returned when the expression can't be evaluated (e.g. the field isn't set yet) or doesn't evaluate to a scalar.
+func (e *Export) Eval(ctx context.Context, resource *unstructured.Unstructured) (string, bool) {
+	if resource == nil {
+		return "", false
+	}
+	val, _, err := e.check.program.ContextEval(ctx, map[string]any{"self": resource.Object})
+	if err != nil {
+		return "", false
+	}
+
+	switch v := val.Value().(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+type Exports []*Export
+
+// Eval returns the values of every export that can be evaluated, or nil if none can.
+func (e Exports) Eval(ctx context.Context, resource *unstructured.Unstructured) map[string]string {
+	var values map[string]string
+	for _, export := range e {
+		val, ok := export.Eval(ctx, resource)
+		if !ok {
+			continue
+		}
+		if values == nil {
+			values = map[string]string{}
+		}
+		values[export.Name] = val
+	}
+	return values
+}
@@ -0,0 +1,46 @@
+package readiness
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExports(t *testing.T) {
+	ctx := context.Background()
+	env, err := NewEnv()
+	require.NoError(t, err)
+
+	resource := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"port": int64(443), "enabled": true},
+		"status": map[string]any{
+			"loadBalancer": map[string]any{
+				"ingress": []any{map[string]any{"ip": "10.0.0.1"}},
+			},
+		},
+	}}
+
+	exports := Exports{}
+	for name, expr := range map[string]string{
+		"ip":      "self.status.loadBalancer.ingress[0].ip",
+		"port":    "self.spec.port",
+		"enabled": "self.spec.enabled",
+		"object":  "self.status.loadBalancer",
+		"missing": "self.status.nope",
+	} {
+		export, err := ParseExport(env, name, expr)
+		require.NoError(t, err)
+		exports = append(exports, export)
+	}
+
+	assert.Equal(t, map[string]string{
+		"ip":      "10.0.0.1",
+		"port":    "443",
+		"enabled": "true",
+	}, exports.Eval(ctx, resource))
+
+	assert.Nil(t, exports.Eval(ctx, nil))
+}
@@ -2,7 +2,9 @@ package readiness
 
 import (
 	"context"
+	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	celtypes "github.com/google/cel-go/common/types"
@@ -122,7 +124,73 @@ func (r Checks) EvalOptionally(ctx context.Context, resource *unstructured.Unstr
 	return r.Eval(ctx, resource)
 }
 
+// GenerationObserved returns false when the resource's status.observedGeneration is behind its metadata.generation
+// i.e. its controller hasn't processed the latest spec yet. True is returned when observedGeneration isn't set.
+func GenerationObserved(resource *unstructured.Unstructured) bool {
+	if resource == nil {
+		return true
+	}
+	observed, ok, _ := unstructured.NestedInt64(resource.Object, "status", "observedGeneration")
+	return !ok || observed >= resource.GetGeneration()
+}
+
 type Status struct {
 	ReadyTime   metav1.Time
 	PreciseTime bool // true when time came from a condition, not the controller's metav1.Now
 }
+
+// conditionsImplyingReadiness are well-known condition types that are expected to be True when a resource is ready.
+var conditionsImplyingReadiness = map[string]struct{}{"Ready": {}, "Available": {}, "Established": {}, "Complete": {}}
+
+// Describe returns a human-readable summary of a resource's progress towards readiness, based on common status conventions.
+// An empty string is returned if nothing useful can be said.
+func Describe(resource *unstructured.Unstructured) string {
+	if resource == nil {
+		return "resource does not exist"
+	}
+
+	var parts []string
+	if !GenerationObserved(resource) {
+		observed, _, _ := unstructured.NestedInt64(resource.Object, "status", "observedGeneration")
+		parts = append(parts, fmt.Sprintf("generation %d not yet observed (observedGeneration=%d)", resource.GetGeneration(), observed))
+	}
+	if desired, ok, _ := unstructured.NestedInt64(resource.Object, "spec", "replicas"); ok {
+		available, _, _ := unstructured.NestedInt64(resource.Object, "status", "availableReplicas")
+		parts = append(parts, fmt.Sprintf("%d/%d replicas available", available, desired))
+	}
+
+	conditions, _, _ := unstructured.NestedFieldNoCopy(resource.Object, "status", "conditions")
+	list, _ := conditions.([]any)
+	for _, cond := range list {
+		mp, ok := cond.(map[string]any)
+		if !ok {
+			continue
+		}
+		typ, _ := mp["type"].(string)
+		status, _ := mp["status"].(string)
+		if _, ok := conditionsImplyingReadiness[typ]; !ok || status == "True" {
+			continue
+		}
+		msg := fmt.Sprintf("condition %s=%s", typ, status)
+		if reason, _ := mp["reason"].(string); reason != "" {
+			msg += ": " + reason
+		}
+		parts = append(parts, msg)
+	}
+
+	// Surface the reason containers are waiting (e.g. ImagePullBackOff) since pod conditions are vague
+	statuses, _, _ := unstructured.NestedFieldNoCopy(resource.Object, "status", "containerStatuses")
+	list, _ = statuses.([]any)
+	for _, cs := range list {
+		mp, ok := cs.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := mp["name"].(string)
+		if reason, _, _ := unstructured.NestedString(mp, "state", "waiting", "reason"); reason != "" {
+			parts = append(parts, fmt.Sprintf("container %s waiting: %s", name, reason))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
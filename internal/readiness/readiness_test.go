@@ -296,3 +296,74 @@ func mustParse(expr string) *Check {
 	}
 	return check
 }
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Resource *unstructured.Unstructured
+		Expected string
+	}{
+		{
+			Name:     "missing",
+			Expected: "resource does not exist",
+		},
+		{
+			Name:     "empty",
+			Resource: &unstructured.Unstructured{Object: map[string]any{}},
+			Expected: "",
+		},
+		{
+			Name: "observed generation",
+			Resource: &unstructured.Unstructured{Object: map[string]any{
+				"metadata": map[string]any{"generation": int64(3)},
+				"status":   map[string]any{"observedGeneration": int64(2)},
+			}},
+			Expected: "generation 3 not yet observed (observedGeneration=2)",
+		},
+		{
+			Name: "replicas",
+			Resource: &unstructured.Unstructured{Object: map[string]any{
+				"spec":   map[string]any{"replicas": int64(5)},
+				"status": map[string]any{"availableReplicas": int64(2)},
+			}},
+			Expected: "2/5 replicas available",
+		},
+		{
+			Name: "conditions",
+			Resource: &unstructured.Unstructured{Object: map[string]any{
+				"status": map[string]any{
+					"conditions": []any{
+						map[string]any{"type": "Ready", "status": "False", "reason": "ContainersNotReady"},
+						map[string]any{"type": "Available", "status": "True"},
+						map[string]any{"type": "SomethingElse", "status": "False"},
+					},
+					"containerStatuses": []any{
+						map[string]any{"name": "app", "state": map[string]any{"waiting": map[string]any{"reason": "ImagePullBackOff"}}},
+						map[string]any{"name": "sidecar", "state": map[string]any{"running": map[string]any{}}},
+					},
+				},
+			}},
+			Expected: "condition Ready=False: ContainersNotReady, container app waiting: ImagePullBackOff",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, Describe(tc.Resource))
+		})
+	}
+}
+
+func TestGenerationObserved(t *testing.T) {
+	assert.True(t, GenerationObserved(nil))
+
+	obj := &unstructured.Unstructured{Object: map[string]any{}}
+	obj.SetGeneration(2)
+	assert.True(t, GenerationObserved(obj), "observedGeneration isn't set")
+
+	obj.Object["status"] = map[string]any{"observedGeneration": int64(1)}
+	assert.False(t, GenerationObserved(obj))
+
+	obj.Object["status"] = map[string]any{"observedGeneration": int64(2)}
+	assert.True(t, GenerationObserved(obj))
+}
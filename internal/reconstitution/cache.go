@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -34,6 +37,86 @@ type resources struct {
 	ByReadinessGroup *redblacktree.Tree[int, []*Resource]
 	ByGroupKind      map[schema.GroupKind][]*Resource
 	CrdsByGroupKind  map[schema.GroupKind]*Resource
+
+	// Ready tracks the readiness of each resource as reported by its slice's status,
+	// and NotReadyByGroup counts the resources in each readiness group that aren't ready yet.
+	Ready           map[*Resource]bool
+	NotReadyByGroup map[int]int
+
+	// NotReadyByHookStage counts the resources in each hook stage of each readiness group that aren't ready yet.
+	NotReadyByHookStage map[hookStageKey]int
+
+	// Reconciled tracks whether each resource has been reconciled as reported by its slice's status,
+	// and UnreconciledByTier counts the (non-deleted) resources in each kind tier of each readiness group that haven't been.
+	Reconciled         map[*Resource]bool
+	UnreconciledByTier map[kindTierKey]int
+
+	// Slices holds the most recently observed status of each resource slice (by name) that is part of the synthesis.
+	Slices map[string]*sliceStatus
+}
+
+type kindTierKey struct {
+	ReadinessGroup, KindTier int
+}
+
+type hookStageKey struct {
+	ReadinessGroup, HookStage int
+}
+
+type sliceStatus struct {
+	ResourceVersion string
+	Resources       []apiv1.ResourceState
+}
+
+// setReady updates the readiness index for the given resource.
+func (r *resources) setReady(res *Resource, ready bool) {
+	if r.Ready[res] == ready {
+		return
+	}
+	r.Ready[res] = ready
+	key := hookStageKey{ReadinessGroup: res.ReadinessGroup, HookStage: res.HookStage()}
+	if ready {
+		r.NotReadyByGroup[res.ReadinessGroup]--
+		r.NotReadyByHookStage[key]--
+	} else {
+		r.NotReadyByGroup[res.ReadinessGroup]++
+		r.NotReadyByHookStage[key]++
+	}
+}
+
+// adjacentGroup returns the node of the readiness group before or after the given group, or nil if there isn't one.
+func (r *resources) adjacentGroup(group int, dir RangeDirection) *redblacktree.Node[int, []*Resource] {
+	if group == 0 && !dir {
+		return nil
+	}
+
+	node := r.ByReadinessGroup.GetNode(group)
+	if node == nil {
+		return nil // the given group must have a resource, otherwise we wouldn't be looking it up
+	}
+
+	// If we're adjacent...
+	if dir {
+		if node.Right != nil {
+			return node.Right
+		}
+	} else {
+		if node.Left != nil {
+			return node.Left
+		}
+	}
+
+	// ...otherwise we need to find it
+	var ok bool
+	if dir {
+		node, ok = r.ByReadinessGroup.Ceiling(group + 1)
+	} else {
+		node, ok = r.ByReadinessGroup.Floor(group - 1)
+	}
+	if !ok {
+		return nil // no previous node!
+	}
+	return node
 }
 
 type sliceIndex struct {
@@ -73,48 +156,175 @@ func (c *Cache) Get(ctx context.Context, comp *SynthesisRef, ref *resource.Ref)
 	return res, ok
 }
 
-func (c *Cache) RangeByReadinessGroup(ctx context.Context, comp *SynthesisRef, group int, dir RangeDirection) []*Resource {
+// setReconciled updates the kind tier index for the given resource.
+// Returns true when every resource in the resource's kind tier has been reconciled as a result.
+func (r *resources) setReconciled(res *Resource, reconciled bool) bool {
+	if res.Deleted() || r.Reconciled[res] == reconciled {
+		return false
+	}
+	r.Reconciled[res] = reconciled
+	key := kindTierKey{ReadinessGroup: res.ReadinessGroup, KindTier: res.KindTier}
+	if reconciled {
+		r.UnreconciledByTier[key]--
+	} else {
+		r.UnreconciledByTier[key]++
+	}
+	return r.UnreconciledByTier[key] == 0
+}
+
+// rangeByKindTier returns the resources in the given readiness group that belong to a later kind tier than the given one.
+func (c *Cache) rangeByKindTier(comp *SynthesisRef, key kindTierKey) []*Resource {
 	c.mut.Lock()
 	defer c.mut.Unlock()
 
-	if group == 0 && !dir {
+	resources, ok := c.resources[*comp]
+	if !ok {
 		return nil
 	}
 
+	group, _ := resources.ByReadinessGroup.Get(key.ReadinessGroup)
+	var next []*Resource
+	for _, res := range group {
+		if res.KindTier > key.KindTier {
+			next = append(next, res)
+		}
+	}
+	return next
+}
+
+// PrecedingKindTiersReconciled returns true when every resource in the same readiness group as the given resource
+// has been reconciled if it belongs to an earlier kind tier. The first tier that hasn't been reconciled is also returned.
+func (c *Cache) PrecedingKindTiersReconciled(ctx context.Context, comp *SynthesisRef, res *Resource) (int, bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
 	resources, ok := c.resources[*comp]
 	if !ok {
-		return nil
+		return 0, true
 	}
 
-	node := resources.ByReadinessGroup.GetNode(group)
-	if node == nil {
-		return nil // the given group must have a resource, otherwise we wouldn't be looking it up
+	for tier := 0; tier < res.KindTier; tier++ {
+		if resources.UnreconciledByTier[kindTierKey{ReadinessGroup: res.ReadinessGroup, KindTier: tier}] > 0 {
+			return tier, false
+		}
 	}
+	return 0, true
+}
 
-	// If we're adjacent...
-	if dir {
-		if node.Right != nil {
-			return node.Right.Value
-		}
-	} else {
-		if node.Left != nil {
-			return node.Left.Value
+// PrecedingHookStagesReady returns true when every resource in the same readiness group as the given resource
+// is ready if it belongs to an earlier hook stage i.e. pre-apply hooks block the rest of the group, which blocks post-ready hooks.
+func (c *Cache) PrecedingHookStagesReady(ctx context.Context, comp *SynthesisRef, res *Resource) bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	resources, ok := c.resources[*comp]
+	if !ok {
+		return true
+	}
+
+	for stage := 0; stage < res.HookStage(); stage++ {
+		if resources.NotReadyByHookStage[hookStageKey{ReadinessGroup: res.ReadinessGroup, HookStage: stage}] > 0 {
+			return false
 		}
 	}
+	return true
+}
 
-	// ...otherwise we need to find it
-	if dir {
-		node, ok = resources.ByReadinessGroup.Ceiling(group + 1)
-	} else {
-		node, ok = resources.ByReadinessGroup.Floor(group - 1)
+// rangeLaterHookStages returns the resources in the same readiness group as the given resource that belong to a later hook stage.
+func (c *Cache) rangeLaterHookStages(comp *SynthesisRef, res *Resource) []*Resource {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	resources, ok := c.resources[*comp]
+	if !ok {
+		return nil
 	}
+
+	group, _ := resources.ByReadinessGroup.Get(res.ReadinessGroup)
+	var next []*Resource
+	for _, other := range group {
+		if other.HookStage() > res.HookStage() {
+			next = append(next, other)
+		}
+	}
+	return next
+}
+
+func (c *Cache) RangeByReadinessGroup(ctx context.Context, comp *SynthesisRef, group int, dir RangeDirection) []*Resource {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	resources, ok := c.resources[*comp]
 	if !ok {
-		return nil // no previous node!
+		return nil
 	}
 
+	node := resources.adjacentGroup(group, dir)
+	if node == nil {
+		return nil
+	}
 	return node.Value
 }
 
+// PreviousReadinessGroupReady returns true when every resource in the readiness group preceding the given group is ready.
+// The preceding group (if any) is also returned.
+func (c *Cache) PreviousReadinessGroupReady(ctx context.Context, comp *SynthesisRef, group int) (int, bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	resources, ok := c.resources[*comp]
+	if !ok {
+		return 0, true
+	}
+
+	node := resources.adjacentGroup(group, RangeDesc)
+	if node == nil {
+		return 0, true
+	}
+	return node.Key, resources.NotReadyByGroup[node.Key] == 0
+}
+
+// Inventory summarizes the resources of a synthesis, including those that have been removed but not yet deleted.
+// Patches are not included since they modify resources that aren't managed by Eno.
+type Inventory struct {
+	GroupKinds []schema.GroupKind // sorted
+	Namespaces []string           // sorted
+}
+
+// GetInventory returns the inventory of the given synthesis, or false if it isn't in the cache.
+func (c *Cache) GetInventory(ctx context.Context, syn *SynthesisRef) (*Inventory, bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	resources, ok := c.resources[*syn]
+	if !ok {
+		return nil, false
+	}
+
+	gks := map[schema.GroupKind]struct{}{}
+	nses := map[string]struct{}{}
+	for _, res := range resources.ByRef {
+		if res.Patch != nil {
+			continue
+		}
+		gks[res.GVK.GroupKind()] = struct{}{}
+		if res.Ref.Namespace != "" {
+			nses[res.Ref.Namespace] = struct{}{}
+		}
+	}
+
+	inv := &Inventory{}
+	for gk := range gks {
+		inv.GroupKinds = append(inv.GroupKinds, gk)
+	}
+	slices.SortFunc(inv.GroupKinds, func(a, b schema.GroupKind) int { return strings.Compare(a.String(), b.String()) })
+	for ns := range nses {
+		inv.Namespaces = append(inv.Namespaces, ns)
+	}
+	slices.Sort(inv.Namespaces)
+	return inv, true
+}
+
 func (c *Cache) GetDefiningCRD(ctx context.Context, syn *SynthesisRef, gk schema.GroupKind) (*Resource, bool) {
 	c.mut.Lock()
 	defer c.mut.Unlock()
@@ -196,24 +406,38 @@ func (c *Cache) fill(ctx context.Context, comp *apiv1.Composition, synthesis *ap
 }
 
 func (c *Cache) buildResources(ctx context.Context, comp *apiv1.Composition, items []apiv1.ResourceSlice) (*resources, []*Request, error) {
+	// Size the per-resource collections up front since large syntheses otherwise spend most of the fill growing them
+	var n int
+	for _, slice := range items {
+		n += len(slice.Spec.Resources)
+	}
+
 	resources := &resources{
-		ByRef:            map[resource.Ref]*Resource{},
-		ByReadinessGroup: redblacktree.New[int, []*Resource](),
-		ByGroupKind:      map[schema.GroupKind][]*resource.Resource{},
-		CrdsByGroupKind:  map[schema.GroupKind]*resource.Resource{},
+		ByRef:               make(map[resource.Ref]*Resource, n),
+		ByReadinessGroup:    redblacktree.New[int, []*Resource](),
+		ByGroupKind:         map[schema.GroupKind][]*resource.Resource{},
+		CrdsByGroupKind:     map[schema.GroupKind]*resource.Resource{},
+		Ready:               make(map[*resource.Resource]bool, n),
+		NotReadyByGroup:     map[int]int{},
+		NotReadyByHookStage: map[hookStageKey]int{},
+		Reconciled:          make(map[*resource.Resource]bool, n),
+		UnreconciledByTier:  map[kindTierKey]int{},
+		Slices:              make(map[string]*sliceStatus, len(items)),
 	}
-	requests := []*Request{}
+	requests := make([]*Request, 0, n)
 	for _, slice := range items {
 		slice := slice
-		if slice.DeletionTimestamp == nil && comp.DeletionTimestamp != nil {
+		if slice.DeletionTimestamp == nil && comp.DeletionTimestamp != nil && !comp.DeletionBlocked() {
 			return nil, nil, errors.New("stale informer - refusing to fill cache")
 		}
+		resources.Slices[slice.Name] = &sliceStatus{ResourceVersion: slice.ResourceVersion, Resources: slices.Clone(slice.Status.Resources)}
 
 		for i := range slice.Spec.Resources {
 			res, err := resource.NewResource(ctx, c.renv, &slice, i)
 			if err != nil {
 				return nil, nil, fmt.Errorf("building resource at index %d of slice %s: %w", i, slice.Name, err)
 			}
+			res.ReconcileInterval = comp.Spec.ReconcileInterval.Apply(res.ReconcileInterval)
 			resources.ByRef[res.Ref] = res
 			c.byIndex[sliceIndex{Index: i, SliceName: slice.Name, Namespace: slice.Namespace}] = res
 			resources.ByGroupKind[res.GVK.GroupKind()] = append(resources.ByGroupKind[res.GVK.GroupKind()], res)
@@ -221,6 +445,16 @@ func (c *Cache) buildResources(ctx context.Context, comp *apiv1.Composition, ite
 			current, _ := resources.ByReadinessGroup.Get(res.ReadinessGroup)
 			resources.ByReadinessGroup.Put(res.ReadinessGroup, append(current, res))
 
+			resources.NotReadyByGroup[res.ReadinessGroup]++
+			resources.NotReadyByHookStage[hookStageKey{ReadinessGroup: res.ReadinessGroup, HookStage: res.HookStage()}]++
+			if !res.Deleted() {
+				resources.UnreconciledByTier[kindTierKey{ReadinessGroup: res.ReadinessGroup, KindTier: res.KindTier}]++
+			}
+			if state := res.FindStatus(&slice); state != nil {
+				resources.setReady(res, state.Ready != nil)
+				resources.setReconciled(res, state.Reconciled)
+			}
+
 			requests = append(requests, &Request{
 				Resource:    res.Ref,
 				Composition: types.NamespacedName{Name: comp.Name, Namespace: comp.Namespace},
@@ -235,6 +469,157 @@ func (c *Cache) buildResources(ctx context.Context, comp *apiv1.Composition, ite
 	return resources, requests, nil
 }
 
+// observeSliceStatus updates the cached status of the given slice, unless a newer version has already been observed.
+// This is necessary because the cache can be filled from a live read that is newer than the informer's next event.
+// Returns the kind tiers that have been fully reconciled as a result of the given status.
+func (c *Cache) observeSliceStatus(syn *SynthesisRef, slice *apiv1.ResourceSlice) []kindTierKey {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	resources, ok := c.resources[*syn]
+	if !ok {
+		return nil
+	}
+	if prev := resources.Slices[slice.Name]; prev != nil && !newerResourceVersion(slice.ResourceVersion, prev.ResourceVersion) {
+		return nil
+	}
+	resources.Slices[slice.Name] = &sliceStatus{ResourceVersion: slice.ResourceVersion, Resources: slices.Clone(slice.Status.Resources)}
+
+	var completed []kindTierKey
+	for i, state := range slice.Status.Resources {
+		res, ok := c.byIndex[sliceIndex{Index: i, SliceName: slice.Name, Namespace: slice.Namespace}]
+		if !ok {
+			continue
+		}
+		resources.setReady(res, state.Ready != nil)
+		if resources.setReconciled(res, state.Reconciled) {
+			completed = append(completed, kindTierKey{ReadinessGroup: res.ReadinessGroup, KindTier: res.KindTier})
+		}
+	}
+	return completed
+}
+
+// GetStatus returns the most recently observed status of the given resource.
+// False is returned when the resource's slice is not cached, in which case the caller should read it directly.
+func (c *Cache) GetStatus(ctx context.Context, syn *SynthesisRef, res *Resource) (*apiv1.ResourceState, bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	resources, ok := c.resources[*syn]
+	if !ok {
+		return nil, false
+	}
+	status, ok := resources.Slices[res.ManifestRef.Slice.Name]
+	if !ok {
+		return nil, false
+	}
+	if len(status.Resources) <= res.ManifestRef.Index {
+		return nil, true
+	}
+	state := status.Resources[res.ManifestRef.Index]
+	return &state, true
+}
+
+// DependencyReady returns true when the given resource of another composition's current synthesis is ready.
+// False is returned when the composition or its current synthesis isn't (yet) known to this process.
+func (c *Cache) DependencyReady(ctx context.Context, dep *Dependency) (bool, error) {
+	comp := &apiv1.Composition{}
+	err := c.client.Get(ctx, types.NamespacedName{Name: dep.CompositionName, Namespace: dep.CompositionNamespace}, comp)
+	if err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if comp.Status.CurrentSynthesis == nil {
+		return false, nil
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	resources, ok := c.resources[*NewSynthesisRef(comp)]
+	if !ok {
+		return false, nil
+	}
+	res, ok := resources.ByRef[dep.Ref()]
+	if !ok {
+		return false, nil
+	}
+	return resources.Ready[res], nil
+}
+
+// FindSyntheses returns every cached synthesis that includes the given resource.
+func (c *Cache) FindSyntheses(ctx context.Context, ref *resource.Ref) []SynthesisRef {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var refs []SynthesisRef
+	for syn, resources := range c.resources {
+		if _, ok := resources.ByRef[*ref]; ok {
+			refs = append(refs, syn)
+		}
+	}
+	slices.SortFunc(refs, compareSynthesisRefs)
+	return refs
+}
+
+// UnreadyResource identifies a resource that has not become ready.
+type UnreadyResource struct {
+	Synthesis SynthesisRef
+	Resource  resource.Ref
+}
+
+// RangeUnready returns the resources of every cached synthesis that have not become ready.
+func (c *Cache) RangeUnready(ctx context.Context) []UnreadyResource {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var unready []UnreadyResource
+	for syn, resources := range c.resources {
+		for ref, res := range resources.ByRef {
+			if !resources.Ready[res] {
+				unready = append(unready, UnreadyResource{Synthesis: syn, Resource: ref})
+			}
+		}
+	}
+	slices.SortFunc(unready, func(a, b UnreadyResource) int {
+		if a.Synthesis != b.Synthesis {
+			return compareSynthesisRefs(a.Synthesis, b.Synthesis)
+		}
+		return compareRefs(a.Resource, b.Resource)
+	})
+	return unready
+}
+
+func compareSynthesisRefs(a, b SynthesisRef) int {
+	if a.Namespace != b.Namespace {
+		return strings.Compare(a.Namespace, b.Namespace)
+	}
+	if a.CompositionName != b.CompositionName {
+		return strings.Compare(a.CompositionName, b.CompositionName)
+	}
+	return strings.Compare(a.UUID, b.UUID)
+}
+
+func compareRefs(a, b resource.Ref) int {
+	for _, pair := range [][2]string{{a.Group, b.Group}, {a.Kind, b.Kind}, {a.Namespace, b.Namespace}, {a.Name, b.Name}} {
+		if c := strings.Compare(pair[0], pair[1]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// newerResourceVersion returns true if resource version a is newer than b.
+// Resource versions are opaque, but in practice they're integers that can be compared.
+// Otherwise any change is assumed to be newer.
+func newerResourceVersion(a, b string) bool {
+	ai, errA := strconv.ParseUint(a, 10, 64)
+	bi, errB := strconv.ParseUint(b, 10, 64)
+	if errA != nil || errB != nil {
+		return a != b
+	}
+	return ai > bi
+}
+
 // purge removes resources associated with a particular composition synthesis from the cache.
 // If composition is set, resources from the active syntheses will be retained.
 // Otherwise all resources deriving from the referenced composition are removed.
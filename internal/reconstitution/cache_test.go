@@ -1,16 +1,20 @@
 package reconstitution
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1 "github.com/Azure/eno/api/v1"
@@ -330,3 +334,374 @@ func reqsToNames(resources []*Resource) []string {
 	}
 	return strs
 }
+
+func TestCachePreviousReadinessGroupReady(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	c := NewCache(testutil.NewClient(t))
+
+	comp := &apiv1.Composition{}
+	comp.Namespace = "default"
+	comp.Name = "test-comp"
+	synth := &apiv1.Synthesis{UUID: uuid.NewString()}
+	comp.Status.CurrentSynthesis = synth
+	compRef := NewSynthesisRef(comp)
+
+	slice := apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = comp.Namespace
+	for i, group := range []string{"1", "1", "2"} {
+		obj := &corev1.ConfigMap{}
+		obj.Name = fmt.Sprintf("obj-%d", i)
+		obj.Namespace = "default"
+		obj.Kind = "ConfigMap"
+		obj.APIVersion = "v1"
+		obj.Annotations = map[string]string{"eno.azure.io/readiness-group": group}
+		js, _ := json.Marshal(obj)
+		slice.Spec.Resources = append(slice.Spec.Resources, apiv1.Manifest{Manifest: string(js)})
+	}
+	slice.ResourceVersion = "1"
+	slice.Status.Resources = []apiv1.ResourceState{{Ready: &metav1.Time{}}, {}, {}}
+
+	_, err := c.fill(ctx, comp, synth, []apiv1.ResourceSlice{slice})
+	require.NoError(t, err)
+
+	// The first group has no dependencies
+	_, ready := c.PreviousReadinessGroupReady(ctx, compRef, 1)
+	assert.True(t, ready)
+
+	// Only one resource in the first group is ready
+	group, ready := c.PreviousReadinessGroupReady(ctx, compRef, 2)
+	assert.Equal(t, 1, group)
+	assert.False(t, ready)
+
+	// Both are ready
+	slice.ResourceVersion = "2"
+	slice.Status.Resources[1].Ready = &metav1.Time{}
+	c.observeSliceStatus(compRef, &slice)
+	_, ready = c.PreviousReadinessGroupReady(ctx, compRef, 2)
+	assert.True(t, ready)
+
+	// Observing the same status again is idempotent
+	c.observeSliceStatus(compRef, &slice)
+	_, ready = c.PreviousReadinessGroupReady(ctx, compRef, 2)
+	assert.True(t, ready)
+
+	// Readiness can regress
+	slice.ResourceVersion = "3"
+	slice.Status.Resources[0].Ready = nil
+	c.observeSliceStatus(compRef, &slice)
+	_, ready = c.PreviousReadinessGroupReady(ctx, compRef, 2)
+	assert.False(t, ready)
+
+	// Unknown syntheses aren't blocked
+	_, ready = c.PreviousReadinessGroupReady(ctx, &SynthesisRef{CompositionName: "nope"}, 2)
+	assert.True(t, ready)
+}
+
+func TestCacheGetStatus(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	c := NewCache(testutil.NewClient(t))
+
+	comp := &apiv1.Composition{}
+	comp.Namespace = "default"
+	comp.Name = "test-comp"
+	synth := &apiv1.Synthesis{UUID: uuid.NewString()}
+	comp.Status.CurrentSynthesis = synth
+	compRef := NewSynthesisRef(comp)
+
+	slice := apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = comp.Namespace
+	slice.ResourceVersion = "10"
+	slice.Spec.Resources = []apiv1.Manifest{{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a","namespace":"default"}}`}}
+
+	_, err := c.fill(ctx, comp, synth, []apiv1.ResourceSlice{slice})
+	require.NoError(t, err)
+	res, ok := c.Get(ctx, compRef, &resource.Ref{Name: "a", Namespace: "default", Kind: "ConfigMap"})
+	require.True(t, ok)
+
+	// No status yet
+	state, ok := c.GetStatus(ctx, compRef, res)
+	assert.True(t, ok)
+	assert.Nil(t, state)
+
+	// Status is updated
+	slice.ResourceVersion = "12"
+	slice.Status.Resources = []apiv1.ResourceState{{Reconciled: true}}
+	c.observeSliceStatus(compRef, &slice)
+	state, ok = c.GetStatus(ctx, compRef, res)
+	assert.True(t, ok)
+	assert.True(t, state.Reconciled)
+
+	// Older versions are ignored
+	slice.ResourceVersion = "11"
+	slice.Status.Resources = nil
+	c.observeSliceStatus(compRef, &slice)
+	state, _ = c.GetStatus(ctx, compRef, res)
+	assert.True(t, state.Reconciled)
+
+	// Unknown syntheses aren't cached
+	_, ok = c.GetStatus(ctx, &SynthesisRef{CompositionName: "nope"}, res)
+	assert.False(t, ok)
+}
+
+func TestNewerResourceVersion(t *testing.T) {
+	assert.True(t, newerResourceVersion("10", "9"))
+	assert.False(t, newerResourceVersion("9", "10"))
+	assert.False(t, newerResourceVersion("10", "10"))
+	assert.True(t, newerResourceVersion("b", "a"))
+	assert.False(t, newerResourceVersion("a", "a"))
+}
+
+func TestCacheReconcileIntervalOverride(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	c := NewCache(testutil.NewClient(t))
+
+	comp := &apiv1.Composition{}
+	comp.Namespace = "default"
+	comp.Name = "test-comp"
+	comp.Spec.ReconcileInterval = &apiv1.ReconcileIntervalOverride{ScalePercent: ptr.To(int32(200))}
+	synth := &apiv1.Synthesis{UUID: uuid.NewString()}
+	comp.Status.CurrentSynthesis = synth
+	compRef := NewSynthesisRef(comp)
+
+	slice := apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = comp.Namespace
+	slice.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a","namespace":"default","annotations":{"eno.azure.io/reconcile-interval":"10m"}}}`},
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"b","namespace":"default"}}`},
+	}
+
+	_, err := c.fill(ctx, comp, synth, []apiv1.ResourceSlice{slice})
+	require.NoError(t, err)
+
+	res, ok := c.Get(ctx, compRef, &resource.Ref{Name: "a", Namespace: "default", Kind: "ConfigMap"})
+	require.True(t, ok)
+	assert.Equal(t, 20*time.Minute, res.ReconcileInterval.Duration)
+
+	// Resources without an interval are not affected
+	res, ok = c.Get(ctx, compRef, &resource.Ref{Name: "b", Namespace: "default", Kind: "ConfigMap"})
+	require.True(t, ok)
+	assert.Nil(t, res.ReconcileInterval)
+}
+
+func TestCachePrecedingHookStagesReady(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	c := NewCache(testutil.NewClient(t))
+
+	comp := &apiv1.Composition{}
+	comp.Namespace = "default"
+	comp.Name = "test-comp"
+	synth := &apiv1.Synthesis{UUID: uuid.NewString()}
+	comp.Status.CurrentSynthesis = synth
+	compRef := NewSynthesisRef(comp)
+
+	slice := apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = comp.Namespace
+	slice.ResourceVersion = "1"
+	slice.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{"apiVersion":"batch/v1","kind":"Job","metadata":{"name":"pre","namespace":"default","annotations":{"eno.azure.io/hook":"pre-apply"}}}`},
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"test-cm","namespace":"default"}}`},
+		{Manifest: `{"apiVersion":"batch/v1","kind":"Job","metadata":{"name":"post","namespace":"default","annotations":{"eno.azure.io/hook":"post-ready"}}}`},
+		{Manifest: `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"other-group","namespace":"default","annotations":{"eno.azure.io/readiness-group":"1"}}}`},
+	}
+	slice.Status.Resources = make([]apiv1.ResourceState, len(slice.Spec.Resources))
+
+	_, err := c.fill(ctx, comp, synth, []apiv1.ResourceSlice{slice})
+	require.NoError(t, err)
+
+	get := func(name, kind, group string) *Resource {
+		res, ok := c.Get(ctx, compRef, &resource.Ref{Name: name, Namespace: "default", Kind: kind, Group: group})
+		require.True(t, ok)
+		return res
+	}
+	pre := get("pre", "Job", "batch")
+	cm := get("test-cm", "ConfigMap", "")
+	post := get("post", "Job", "batch")
+	other := get("other-group", "Secret", "")
+
+	// Pre-apply hooks go first
+	assert.True(t, c.PrecedingHookStagesReady(ctx, compRef, pre))
+	assert.False(t, c.PrecedingHookStagesReady(ctx, compRef, cm))
+	assert.False(t, c.PrecedingHookStagesReady(ctx, compRef, post))
+	assert.True(t, c.PrecedingHookStagesReady(ctx, compRef, other))
+	assert.Equal(t, []*Resource{cm, post}, c.rangeLaterHookStages(compRef, pre))
+
+	// The pre-apply hook succeeding unblocks the rest of the group
+	slice.ResourceVersion = "2"
+	slice.Status.Resources[0].Ready = &metav1.Time{}
+	c.observeSliceStatus(compRef, &slice)
+	assert.True(t, c.PrecedingHookStagesReady(ctx, compRef, cm))
+	assert.False(t, c.PrecedingHookStagesReady(ctx, compRef, post))
+
+	// Post-ready hooks run once the rest of the group is ready
+	slice.ResourceVersion = "3"
+	slice.Status.Resources[1].Ready = &metav1.Time{}
+	c.observeSliceStatus(compRef, &slice)
+	assert.True(t, c.PrecedingHookStagesReady(ctx, compRef, post))
+
+	// The next readiness group waits for post-ready hooks
+	_, ready := c.PreviousReadinessGroupReady(ctx, compRef, 1)
+	assert.False(t, ready)
+}
+
+func TestCachePrecedingKindTiersReconciled(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	c := NewCache(testutil.NewClient(t))
+
+	comp := &apiv1.Composition{}
+	comp.Namespace = "default"
+	comp.Name = "test-comp"
+	synth := &apiv1.Synthesis{UUID: uuid.NewString()}
+	comp.Status.CurrentSynthesis = synth
+	compRef := NewSynthesisRef(comp)
+
+	slice := apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = comp.Namespace
+	slice.ResourceVersion = "1"
+	slice.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"test-ns"}}`},
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"test-cm","namespace":"test-ns"}}`},
+		{Manifest: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"test-deploy","namespace":"test-ns"}}`},
+		{Manifest: `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"other-group","namespace":"test-ns","annotations":{"eno.azure.io/readiness-group":"1"}}}`},
+	}
+	slice.Status.Resources = make([]apiv1.ResourceState, len(slice.Spec.Resources))
+
+	_, err := c.fill(ctx, comp, synth, []apiv1.ResourceSlice{slice})
+	require.NoError(t, err)
+
+	get := func(name, ns, kind, group string) *Resource {
+		res, ok := c.Get(ctx, compRef, &resource.Ref{Name: name, Namespace: ns, Kind: kind, Group: group})
+		require.True(t, ok)
+		return res
+	}
+	ns := get("test-ns", "", "Namespace", "")
+	cm := get("test-cm", "test-ns", "ConfigMap", "")
+	deploy := get("test-deploy", "test-ns", "Deployment", "apps")
+	other := get("other-group", "test-ns", "Secret", "")
+
+	// Namespaces go first
+	_, ok := c.PrecedingKindTiersReconciled(ctx, compRef, ns)
+	assert.True(t, ok)
+	tier, ok := c.PrecedingKindTiersReconciled(ctx, compRef, deploy)
+	assert.False(t, ok)
+	assert.Equal(t, 0, tier)
+
+	// Other readiness groups are not affected
+	_, ok = c.PrecedingKindTiersReconciled(ctx, compRef, other)
+	assert.True(t, ok)
+
+	// Reconciling the namespace unblocks the configmap
+	slice.ResourceVersion = "2"
+	slice.Status.Resources[0].Reconciled = true
+	completed := c.observeSliceStatus(compRef, &slice)
+	assert.Equal(t, []kindTierKey{{ReadinessGroup: 0, KindTier: 0}}, completed)
+	assert.ElementsMatch(t, []*Resource{cm, deploy}, c.rangeByKindTier(compRef, completed[0]))
+
+	_, ok = c.PrecedingKindTiersReconciled(ctx, compRef, cm)
+	assert.True(t, ok)
+	tier, ok = c.PrecedingKindTiersReconciled(ctx, compRef, deploy)
+	assert.False(t, ok)
+	assert.Equal(t, 3, tier)
+
+	// Reconciling the configmap unblocks the deployment
+	slice.ResourceVersion = "3"
+	slice.Status.Resources[1].Reconciled = true
+	c.observeSliceStatus(compRef, &slice)
+	_, ok = c.PrecedingKindTiersReconciled(ctx, compRef, deploy)
+	assert.True(t, ok)
+}
+
+func TestCacheGetInventory(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	c := NewCache(testutil.NewClient(t))
+
+	comp := &apiv1.Composition{}
+	comp.Namespace = "default"
+	comp.Name = "test-comp"
+	synth := &apiv1.Synthesis{UUID: uuid.NewString()}
+	comp.Status.CurrentSynthesis = synth
+	compRef := NewSynthesisRef(comp)
+
+	slice := apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = comp.Namespace
+	slice.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"foo"}}`},
+		{Manifest: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"a","namespace":"foo"}}`},
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"b","namespace":"bar"}}`, Deleted: true},
+		{Manifest: `{"apiVersion":"eno.azure.io/v1","kind":"Patch","metadata":{"name":"c","namespace":"baz"},"patch":{"apiVersion":"v1","kind":"Secret","ops":[{"op":"add","path":"/data/foo","value":"bar"}]}}`},
+	}
+
+	_, err := c.fill(ctx, comp, synth, []apiv1.ResourceSlice{slice})
+	require.NoError(t, err)
+
+	inv, ok := c.GetInventory(ctx, compRef)
+	require.True(t, ok)
+	assert.Equal(t, []schema.GroupKind{{Kind: "ConfigMap"}, {Group: "apps", Kind: "Deployment"}, {Kind: "Namespace"}}, inv.GroupKinds)
+	assert.Equal(t, []string{"bar", "foo"}, inv.Namespaces)
+
+	_, ok = c.GetInventory(ctx, &SynthesisRef{CompositionName: "nope"})
+	assert.False(t, ok)
+}
+
+func BenchmarkCacheFill(b *testing.B) {
+	for _, size := range []struct{ Slices, Resources int }{{1, 10}, {10, 100}, {50, 200}} {
+		b.Run(fmt.Sprintf("%dx%d", size.Slices, size.Resources), func(b *testing.B) {
+			ctx := context.Background()
+			comp, synth, resources, _ := newCacheTestFixtures(size.Slices, size.Resources)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				c := NewCache(nil) // building the CEL env is expensive and not part of the hot path
+				b.StartTimer()
+
+				if _, err := c.fill(ctx, comp, synth, resources); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheDependencyReady(t *testing.T) {
+	ctx := testutil.NewContext(t)
+
+	comp, synth, resources, reqs := newCacheTestFixtures(1, 2)
+	c := NewCache(testutil.NewClient(t, comp))
+	_, err := c.fill(ctx, comp, synth, resources)
+	require.NoError(t, err)
+
+	dep := &Dependency{CompositionName: comp.Name, CompositionNamespace: comp.Namespace, Kind: "ConfigMap", Name: reqs[0].Resource.Name, Namespace: reqs[0].Resource.Namespace}
+	ready, err := c.DependencyReady(ctx, dep)
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	now := metav1.Now()
+	slice := resources[0].DeepCopy()
+	slice.ResourceVersion = "2"
+	slice.Status.Resources = []apiv1.ResourceState{{Ready: &now}, {}}
+	c.observeSliceStatus(NewSynthesisRef(comp), slice)
+
+	ready, err = c.DependencyReady(ctx, dep)
+	require.NoError(t, err)
+	assert.True(t, ready)
+
+	// Resources that aren't part of the composition's current synthesis are never ready
+	missing := *dep
+	missing.Name = "missing"
+	ready, err = c.DependencyReady(ctx, &missing)
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	// Same for compositions that don't exist
+	missing = *dep
+	missing.CompositionName = "missing"
+	ready, err = c.DependencyReady(ctx, &missing)
+	require.NoError(t, err)
+	assert.False(t, ready)
+}
@@ -23,7 +23,7 @@ type controller struct {
 	*Cache          // embedded because caching is logically part of the reconstituter's functionality
 	client          client.Client
 	nonCachedReader client.Reader
-	queue           workqueue.RateLimitingInterface
+	queue           *ageTrackingQueue
 }
 
 func newController(mgr ctrl.Manager, cache *Cache) (*controller, error) {
@@ -33,15 +33,15 @@ func newController(mgr ctrl.Manager, cache *Cache) (*controller, error) {
 		nonCachedReader: mgr.GetAPIReader(),
 	}
 	rateLimiter := workqueue.DefaultItemBasedRateLimiter()
-	r.queue = workqueue.NewRateLimitingQueueWithConfig(rateLimiter, workqueue.RateLimitingQueueConfig{
-		Name: "reconciliationController",
-	})
+	r.queue = newAgeTrackingQueue(workqueue.NewRateLimitingQueueWithConfig(rateLimiter, workqueue.RateLimitingQueueConfig{
+		Name: queueName,
+	}))
 
 	err := ctrl.NewControllerManagedBy(mgr).
 		Named("readinessTransitionResponder").
 		For(&apiv1.ResourceSlice{}).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "readinessTransitionResponder")).
-		Complete(reconcile.Func(r.HandleReadinessTransition))
+		Complete(manager.WithController("readinessTransitionResponder", reconcile.Func(r.HandleReadinessTransition)))
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +51,7 @@ func newController(mgr ctrl.Manager, cache *Cache) (*controller, error) {
 		For(&apiv1.Composition{}).
 		Owns(&apiv1.ResourceSlice{}).
 		WithLogConstructor(manager.NewLogConstructor(mgr, "reconstituter")).
-		Complete(r)
+		Complete(manager.WithController("reconstituter", r))
 }
 
 func (r *controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -136,6 +136,16 @@ func (r *controller) HandleReadinessTransition(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, nil
 	}
 
+	synRef := &SynthesisRef{CompositionName: owner.Name, Namespace: req.Namespace, UUID: slice.Spec.SynthesisUUID}
+	for _, tier := range r.Cache.observeSliceStatus(synRef, slice) {
+		for _, res := range r.Cache.rangeByKindTier(synRef, tier) {
+			r.queue.Add(Request{
+				Resource:    res.Ref,
+				Composition: types.NamespacedName{Namespace: slice.Namespace, Name: owner.Name},
+			})
+		}
+	}
+
 	for i, res := range slice.Status.Resources {
 		if res.Ready == nil {
 			continue // only care about resources that have become ready
@@ -151,8 +161,8 @@ func (r *controller) HandleReadinessTransition(ctx context.Context, req ctrl.Req
 			return ctrl.Result{}, nil
 		}
 
-		synRef := &SynthesisRef{CompositionName: owner.Name, Namespace: req.Namespace, UUID: slice.Spec.SynthesisUUID}
 		resources := r.Cache.RangeByReadinessGroup(ctx, synRef, res.ReadinessGroup, RangeAsc)
+		resources = append(resources, r.Cache.rangeLaterHookStages(synRef, res)...)
 		if res.DefinedGroupKind != nil {
 			resources = append(resources, r.Cache.getByGK(synRef, *res.DefinedGroupKind)...)
 		}
@@ -0,0 +1,21 @@
+package reconstitution
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Azure/eno/internal/telemetry"
+)
+
+var (
+	workItemAge = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "eno_work_item_age_seconds",
+			Help:    "Samples the time between a work item first being queued and being processed without error or immediate requeue, including any retries",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1.0, 5.0, 15.0, 30.0, 60.0, 300.0},
+		}, []string{"controller"},
+	)
+)
+
+func init() {
+	telemetry.MustRegister(workItemAge)
+}
@@ -2,13 +2,18 @@ package reconstitution
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/client-go/util/workqueue"
+
+	"github.com/Azure/eno/internal/manager"
 )
 
 type queueProcessor struct {
-	Queue   workqueue.RateLimitingInterface
+	Name    string // of the queue, used to label metrics
+	Queue   *ageTrackingQueue
 	Handler Reconciler
 	Logger  logr.Logger
 }
@@ -37,7 +42,7 @@ func (q *queueProcessor) processQueueItem(ctx context.Context) bool {
 	}
 
 	logger := q.Logger.WithValues("compositionName", req.Composition.Name, "compositionNamespace", req.Composition.Namespace, "resourceKind", req.Resource.Kind, "resourceName", req.Resource.Name, "resourceNamespace", req.Resource.Namespace)
-	ctx = logr.NewContext(ctx, logger)
+	ctx = manager.NewControllerContext(logr.NewContext(ctx, logger), q.Name)
 
 	result, err := q.Handler.Reconcile(ctx, &req)
 	if err != nil {
@@ -54,12 +59,46 @@ func (q *queueProcessor) processQueueItem(ctx context.Context) bool {
 		return true
 	}
 
+	q.Queue.Forget(item)
+	if age, ok := q.Queue.Age(item); ok {
+		workItemAge.WithLabelValues(q.Name).Observe(age.Seconds())
+	}
 	if result.RequeueAfter != 0 {
-		q.Queue.Forget(item)
 		q.Queue.AddAfter(item, result.RequeueAfter)
-		return true
 	}
-
-	q.Queue.Forget(item)
 	return true
 }
+
+// ageTrackingQueue records when each item was first added to the queue, so the time it took
+// to be processed (including retries) can be observed once the item has been forgotten.
+// Items requeued after a delay aren't tracked, since they're expected to wait.
+type ageTrackingQueue struct {
+	workqueue.RateLimitingInterface
+	mut   sync.Mutex
+	added map[any]time.Time
+}
+
+func newAgeTrackingQueue(queue workqueue.RateLimitingInterface) *ageTrackingQueue {
+	return &ageTrackingQueue{RateLimitingInterface: queue, added: map[any]time.Time{}}
+}
+
+func (q *ageTrackingQueue) Add(item any) {
+	q.mut.Lock()
+	if _, ok := q.added[item]; !ok {
+		q.added[item] = time.Now()
+	}
+	q.mut.Unlock()
+	q.RateLimitingInterface.Add(item)
+}
+
+// Age returns the time since the item was first added and stops tracking it.
+func (q *ageTrackingQueue) Age(item any) (time.Duration, bool) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	added, ok := q.added[item]
+	if !ok {
+		return 0, false
+	}
+	delete(q.added, item)
+	return time.Since(added), true
+}
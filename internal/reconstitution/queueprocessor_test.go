@@ -6,13 +6,14 @@ import (
 	"time"
 
 	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 func TestQueueProcessorRequeueLogic(t *testing.T) {
 	rateLimiter := workqueue.DefaultItemBasedRateLimiter()
-	queue := workqueue.NewRateLimitingQueueWithConfig(rateLimiter, workqueue.RateLimitingQueueConfig{})
+	queue := newAgeTrackingQueue(workqueue.NewRateLimitingQueueWithConfig(rateLimiter, workqueue.RateLimitingQueueConfig{}))
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -40,3 +41,22 @@ type reconcilerFunc func(ctx context.Context, req *Request) (ctrl.Result, error)
 func (r reconcilerFunc) Reconcile(ctx context.Context, req *Request) (ctrl.Result, error) {
 	return r(ctx, req)
 }
+
+func TestAgeTrackingQueue(t *testing.T) {
+	q := newAgeTrackingQueue(workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultItemBasedRateLimiter(), workqueue.RateLimitingQueueConfig{}))
+	defer q.ShutDown()
+
+	// Items are only tracked the first time they're added
+	q.Add("foo")
+	added := q.added["foo"]
+	q.Add("foo")
+	assert.Equal(t, added, q.added["foo"])
+
+	age, ok := q.Age("foo")
+	assert.True(t, ok)
+	assert.GreaterOrEqual(t, age, time.Duration(0))
+
+	// Not tracked again until re-added
+	_, ok = q.Age("foo")
+	assert.False(t, ok)
+}
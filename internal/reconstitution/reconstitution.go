@@ -12,6 +12,24 @@ import (
 
 type Resource = resource.Resource
 
+type Explanation = resource.Explanation
+
+type Dependency = resource.Dependency
+
+type DeletionRef = resource.DeletionRef
+
+type Requirement = resource.Requirement
+
+var (
+	HandsOffUntil   = resource.HandsOffUntil
+	IsAdmissionKind = resource.IsAdmissionKind
+)
+
+const (
+	HookSynthesisAnnotation = resource.HookSynthesisAnnotation
+	HookDeleteSucceeded     = resource.HookDeleteSucceeded
+)
+
 // Reconciler is implemented by types that can reconcile individual, reconstituted resources.
 type Reconciler interface {
 	Reconcile(ctx context.Context, req *Request) (ctrl.Result, error)
@@ -21,6 +39,10 @@ type Reconciler interface {
 type Client interface {
 	Get(ctx context.Context, syn *SynthesisRef, res *resource.Ref) (*resource.Resource, bool)
 	RangeByReadinessGroup(ctx context.Context, syn *SynthesisRef, group int, dir RangeDirection) []*Resource
+	PreviousReadinessGroupReady(ctx context.Context, syn *SynthesisRef, group int) (int, bool)
+	PrecedingKindTiersReconciled(ctx context.Context, syn *SynthesisRef, res *Resource) (int, bool)
+	PrecedingHookStagesReady(ctx context.Context, syn *SynthesisRef, res *Resource) bool
+	GetStatus(ctx context.Context, syn *SynthesisRef, res *Resource) (*apiv1.ResourceState, bool)
 	GetDefiningCRD(ctx context.Context, syn *SynthesisRef, gk schema.GroupKind) (*Resource, bool)
 }
 
@@ -44,6 +66,9 @@ func NewSynthesisRef(comp *apiv1.Composition) *SynthesisRef {
 	return c
 }
 
+// queueName identifies the reconciliation controller's work queue in logs and metrics.
+const queueName = "reconciliationController"
+
 // Request is like controller-runtime reconcile.Request but for reconstituted resources.
 // https://pkg.go.dev/sigs.k8s.io/controller-runtime/pkg/reconcile#Request
 type Request struct {
@@ -60,9 +85,10 @@ func New(mgr ctrl.Manager, cache *Cache, rec Reconciler) error {
 	}
 
 	qp := &queueProcessor{
+		Name:    queueName,
 		Queue:   ctrl.queue,
 		Handler: rec,
-		Logger:  mgr.GetLogger().WithValues("controller", "reconciliationController"),
+		Logger:  mgr.GetLogger().WithValues("controller", queueName),
 	}
 	return mgr.Add(qp)
 }
@@ -0,0 +1,168 @@
+package reconstitution
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/resource"
+)
+
+// SearchResult is a single result returned by the search handler.
+type SearchResult struct {
+	CompositionName       string `json:"compositionName"`
+	CompositionNamespace  string `json:"compositionNamespace"`
+	SynthesisUUID         string `json:"synthesisUUID,omitempty"`
+	SynthesizerGeneration int64  `json:"synthesizerGeneration,omitempty"`
+
+	// Current is true when the synthesis is the composition's current synthesis (as opposed to its previous synthesis).
+	Current bool `json:"current,omitempty"`
+
+	ResourceGroup     string `json:"resourceGroup,omitempty"`
+	ResourceKind      string `json:"resourceKind,omitempty"`
+	ResourceName      string `json:"resourceName,omitempty"`
+	ResourceNamespace string `json:"resourceNamespace,omitempty"`
+}
+
+type searchHandler struct {
+	client client.Reader
+	cache  *Cache
+}
+
+// NewSearchHandler returns an HTTP handler that answers questions about the compositions managed by this process
+// using the reconstitution cache and informers, rather than scanning resources in the apiserver.
+//
+// Supported queries:
+//   - query=managing&group=&kind=&name=&namespace=: compositions that manage the given downstream resource
+//   - query=synthesizer&synthesizer=&belowGeneration=: compositions that use the given synthesizer, optionally
+//     only those last synthesized by a generation older than belowGeneration
+//   - query=unready: resources of current syntheses that have not become ready
+func NewSearchHandler(cli client.Reader, cache *Cache) http.Handler {
+	return &searchHandler{client: cli, cache: cache}
+}
+
+func (s *searchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var results []*SearchResult
+	var err error
+	switch q.Get("query") {
+	case "managing":
+		ref := &resource.Ref{Name: q.Get("name"), Namespace: q.Get("namespace"), Group: q.Get("group"), Kind: q.Get("kind")}
+		results, err = s.findManaging(r, ref)
+
+	case "synthesizer":
+		var below int64
+		if str := q.Get("belowGeneration"); str != "" {
+			below, err = strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid belowGeneration", http.StatusBadRequest)
+				return
+			}
+		}
+		results, err = s.findBySynthesizer(r, q.Get("synthesizer"), below)
+
+	case "unready":
+		results, err = s.findUnready(r)
+
+	default:
+		http.Error(w, "query must be one of: managing, synthesizer, unready", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+func (s *searchHandler) findManaging(r *http.Request, ref *resource.Ref) ([]*SearchResult, error) {
+	results := []*SearchResult{}
+	for _, syn := range s.cache.FindSyntheses(r.Context(), ref) {
+		result, err := s.buildResult(r, syn, false)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			continue
+		}
+		result.ResourceGroup = ref.Group
+		result.ResourceKind = ref.Kind
+		result.ResourceName = ref.Name
+		result.ResourceNamespace = ref.Namespace
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *searchHandler) findBySynthesizer(r *http.Request, name string, belowGeneration int64) ([]*SearchResult, error) {
+	list := &apiv1.CompositionList{}
+	err := s.client.List(r.Context(), list)
+	if err != nil {
+		return nil, fmt.Errorf("listing compositions: %w", err)
+	}
+
+	results := []*SearchResult{}
+	for _, comp := range list.Items {
+		if comp.Spec.Synthesizer.Name != name {
+			continue
+		}
+		result := &SearchResult{CompositionName: comp.Name, CompositionNamespace: comp.Namespace, Current: true}
+		if syn := comp.Status.CurrentSynthesis; syn != nil {
+			result.SynthesisUUID = syn.UUID
+			result.SynthesizerGeneration = syn.ObservedSynthesizerGeneration
+		}
+		if belowGeneration > 0 && result.SynthesisUUID != "" && result.SynthesizerGeneration >= belowGeneration {
+			continue
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *searchHandler) findUnready(r *http.Request) ([]*SearchResult, error) {
+	results := []*SearchResult{}
+	for _, item := range s.cache.RangeUnready(r.Context()) {
+		result, err := s.buildResult(r, item.Synthesis, true)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			continue
+		}
+		result.ResourceGroup = item.Resource.Group
+		result.ResourceKind = item.Resource.Kind
+		result.ResourceName = item.Resource.Name
+		result.ResourceNamespace = item.Resource.Namespace
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// buildResult returns a result for the given synthesis, or nil if it's no longer referenced by its composition.
+func (s *searchHandler) buildResult(r *http.Request, ref SynthesisRef, currentOnly bool) (*SearchResult, error) {
+	comp := &apiv1.Composition{}
+	err := s.client.Get(r.Context(), types.NamespacedName{Name: ref.CompositionName, Namespace: ref.Namespace}, comp)
+	if err != nil {
+		return nil, client.IgnoreNotFound(fmt.Errorf("getting composition: %w", err))
+	}
+
+	result := &SearchResult{CompositionName: comp.Name, CompositionNamespace: comp.Namespace, SynthesisUUID: ref.UUID}
+	switch {
+	case comp.Status.CurrentSynthesis != nil && comp.Status.CurrentSynthesis.UUID == ref.UUID:
+		result.Current = true
+		result.SynthesizerGeneration = comp.Status.CurrentSynthesis.ObservedSynthesizerGeneration
+	case !currentOnly && comp.Status.PreviousSynthesis != nil && comp.Status.PreviousSynthesis.UUID == ref.UUID:
+		result.SynthesizerGeneration = comp.Status.PreviousSynthesis.ObservedSynthesizerGeneration
+	default:
+		return nil, nil
+	}
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package reconstitution
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/testutil"
+)
+
+func TestSearchHandler(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+	c := NewCache(cli)
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Synthesizer.Name = "test-synth"
+	require.NoError(t, cli.Create(ctx, comp))
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "current", ObservedSynthesizerGeneration: 2}
+	require.NoError(t, cli.Status().Update(ctx, comp))
+
+	other := &apiv1.Composition{}
+	other.Name = "other-comp"
+	other.Namespace = "default"
+	other.Spec.Synthesizer.Name = "other-synth"
+	require.NoError(t, cli.Create(ctx, other))
+
+	slice := apiv1.ResourceSlice{}
+	slice.Name = "test-slice"
+	slice.Namespace = comp.Namespace
+	slice.Spec.Resources = []apiv1.Manifest{
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"ready","namespace":"default"}}`},
+		{Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"unready","namespace":"default"}}`},
+	}
+	slice.Status.Resources = []apiv1.ResourceState{{Ready: &metav1.Time{}}, {}}
+	_, err := c.fill(ctx, comp, comp.Status.CurrentSynthesis, []apiv1.ResourceSlice{slice})
+	require.NoError(t, err)
+
+	// Stale syntheses are ignored
+	_, err = c.fill(ctx, comp, &apiv1.Synthesis{UUID: "stale"}, []apiv1.ResourceSlice{slice})
+	require.NoError(t, err)
+
+	h := NewSearchHandler(cli, c)
+	search := func(query string) (int, []*SearchResult) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		results := []*SearchResult{}
+		if w.Code == 200 {
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&results))
+		}
+		return w.Code, results
+	}
+
+	code, results := search("query=managing&kind=ConfigMap&name=ready&namespace=default")
+	assert.Equal(t, 200, code)
+	assert.Equal(t, []*SearchResult{{CompositionName: "test-comp", CompositionNamespace: "default", SynthesisUUID: "current", SynthesizerGeneration: 2, Current: true, ResourceKind: "ConfigMap", ResourceName: "ready", ResourceNamespace: "default"}}, results)
+
+	_, results = search("query=managing&kind=ConfigMap&name=nope&namespace=default")
+	assert.Empty(t, results)
+
+	_, results = search("query=unready")
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "unready", results[0].ResourceName)
+	}
+
+	_, results = search("query=synthesizer&synthesizer=test-synth")
+	assert.Len(t, results, 1)
+
+	_, results = search("query=synthesizer&synthesizer=test-synth&belowGeneration=3")
+	assert.Len(t, results, 1)
+
+	_, results = search("query=synthesizer&synthesizer=test-synth&belowGeneration=2")
+	assert.Empty(t, results)
+
+	code, _ = search("query=synthesizer&belowGeneration=nope")
+	assert.Equal(t, 400, code)
+
+	code, _ = search("")
+	assert.Equal(t, 400, code)
+}
@@ -0,0 +1,63 @@
+package resource
+
+import (
+	"fmt"
+)
+
+// DependsOnAnnotation holds a json list of Dependency objects.
+// The resource isn't reconciled until every dependency is ready.
+const DependsOnAnnotation = "eno.azure.io/depends-on"
+
+// Dependency refers to a resource managed by another composition.
+type Dependency struct {
+	// CompositionNamespace defaults to the namespace of the dependent resource's composition.
+	CompositionName      string `json:"compositionName"`
+	CompositionNamespace string `json:"compositionNamespace,omitempty"`
+
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (d *Dependency) Ref() Ref {
+	return Ref{Name: d.Name, Namespace: d.Namespace, Group: d.Group, Kind: d.Kind}
+}
+
+func (d *Dependency) String() string {
+	return fmt.Sprintf("%s/%s:%s/%s/%s/%s", d.CompositionNamespace, d.CompositionName, d.Group, d.Kind, d.Namespace, d.Name)
+}
+
+func (d *Dependency) validate() error {
+	if d.CompositionName == "" || d.Kind == "" || d.Name == "" {
+		return fmt.Errorf("compositionName, kind, and name are required")
+	}
+	return nil
+}
+
+// WaitForDeletionAnnotation holds a json list of DeletionRef objects.
+// The resource isn't considered ready until none of the referenced objects exist.
+const WaitForDeletionAnnotation = "eno.azure.io/wait-for-deletion"
+
+// DeletionRef refers to an object that is expected to be deleted (e.g. a legacy resource being torn down).
+type DeletionRef struct {
+	// Namespace defaults to the namespace of the resource waiting for the deletion.
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+func (d *DeletionRef) String() string {
+	if d.Namespace == "" {
+		return fmt.Sprintf("%s %s", d.Kind, d.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", d.Kind, d.Namespace, d.Name)
+}
+
+func (d *DeletionRef) validate() error {
+	if d.APIVersion == "" || d.Kind == "" || d.Name == "" {
+		return fmt.Errorf("apiVersion, kind, and name are required")
+	}
+	return nil
+}
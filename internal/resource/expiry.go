@@ -0,0 +1,41 @@
+package resource
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExpiresAfterAnnotation can be set to a duration on resources to have Eno delete them once the duration has elapsed.
+// Useful for one-shot Jobs and temporary (e.g. debugging) resources. Expired resources aren't recreated until the next synthesis.
+const ExpiresAfterAnnotation = "eno.azure.io/expires-after"
+
+// ExpiresFromAnnotation controls when the ExpiresAfterAnnotation's duration starts.
+const ExpiresFromAnnotation = "eno.azure.io/expires-from"
+
+type ExpiryBasis string
+
+const (
+	// ExpiresFromCreation starts the expiry duration when the resource is created (default).
+	ExpiresFromCreation ExpiryBasis = "creation"
+
+	// ExpiresFromReadiness starts the expiry duration when the resource becomes ready.
+	ExpiresFromReadiness ExpiryBasis = "readiness"
+)
+
+// ExpiresAt returns the time at which the resource expires given the time it was created and became ready (if known).
+// Nil is returned when the resource doesn't expire or the relevant time isn't known yet.
+func (r *Resource) ExpiresAt(created, ready *metav1.Time) *metav1.Time {
+	if r.ExpiresAfter == nil {
+		return nil
+	}
+
+	basis := created
+	if r.ExpiresFrom == ExpiresFromReadiness {
+		basis = ready
+	}
+	if basis == nil || basis.IsZero() {
+		return nil
+	}
+	return &metav1.Time{Time: basis.Add(*r.ExpiresAfter).Truncate(time.Second)}
+}
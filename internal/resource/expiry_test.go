@@ -0,0 +1,26 @@
+package resource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestExpiresAt(t *testing.T) {
+	created := &metav1.Time{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	ready := &metav1.Time{Time: created.Add(time.Minute + time.Millisecond)}
+
+	r := &Resource{}
+	assert.Nil(t, r.ExpiresAt(created, ready))
+
+	r.ExpiresAfter = ptr.To(time.Hour)
+	assert.Equal(t, created.Add(time.Hour), r.ExpiresAt(created, ready).Time)
+	assert.Nil(t, r.ExpiresAt(nil, ready))
+
+	r.ExpiresFrom = ExpiresFromReadiness
+	assert.Equal(t, created.Add(time.Hour+time.Minute), r.ExpiresAt(created, ready).Time)
+	assert.Nil(t, r.ExpiresAt(created, nil))
+}
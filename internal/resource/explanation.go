@@ -0,0 +1,98 @@
+package resource
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Explanation describes why a resource is in its current state from the perspective of the reconciliation controller.
+// It's essentially a machine-readable trace of the most recent pass through the reconciliation decision tree.
+type Explanation struct {
+	// Time at which the decision was made.
+	Time time.Time `json:"time"`
+
+	// Decision is a short machine-readable reason for the outcome of the most recent reconciliation.
+	Decision string `json:"decision"`
+
+	// WaitingOnCRD is set when reconciliation is blocked until the CRD (by name) that defines the resource's type becomes ready.
+	WaitingOnCRD string `json:"waitingOnCRD,omitempty"`
+
+	// WaitingOnReadinessGroup is set when reconciliation is blocked until resources in an earlier readiness group are ready.
+	WaitingOnReadinessGroup *int `json:"waitingOnReadinessGroup,omitempty"`
+
+	// WaitingOnSettle is set when reconciliation is blocked until a resource in the previous readiness group
+	// has been ready for its settle duration.
+	WaitingOnSettle string `json:"waitingOnSettle,omitempty"`
+
+	// WaitingOnKindTier is set when reconciliation is blocked until resources of kinds that are applied first
+	// (within the same readiness group) have been reconciled.
+	WaitingOnKindTier *int `json:"waitingOnKindTier,omitempty"`
+
+	// WaitingOnDependency is set when reconciliation is blocked until a resource of another composition becomes ready.
+	WaitingOnDependency string `json:"waitingOnDependency,omitempty"`
+
+	// MissingRequirement is set when reconciliation is blocked until the downstream cluster meets one of the resource's requirements
+	// e.g. serves a particular kind or runs a newer version of Kubernetes.
+	MissingRequirement string `json:"missingRequirement,omitempty"`
+
+	// FrozenUntil is set when changes are deferred until the end of the composition's freeze window.
+	FrozenUntil *time.Time `json:"frozenUntil,omitempty"`
+
+	Ready            *metav1.Time `json:"ready,omitempty"`
+	ReadinessMessage string       `json:"readinessMessage,omitempty"`
+
+	// LastAction is the most recent create/patch/delete attempt, which may have happened during an earlier reconciliation.
+	LastAction *Action `json:"lastAction,omitempty"`
+
+	// NextRequeue is the time at which the resource will be reconciled again, if one is scheduled.
+	NextRequeue *time.Time `json:"nextRequeue,omitempty"`
+}
+
+// Action describes an attempt to mutate a resource.
+type Action struct {
+	Type  string    `json:"type"`
+	Time  time.Time `json:"time"`
+	Error string    `json:"error,omitempty"`
+}
+
+type lastExplanationMeta struct {
+	lock        sync.Mutex
+	explanation *Explanation
+	lastAction  *Action
+}
+
+// ObserveDecision records the outcome of a reconciliation for later retrieval by Explain.
+func (l *lastExplanationMeta) ObserveDecision(e *Explanation) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.explanation = e
+}
+
+// ObserveAction records an attempt to mutate the resource.
+func (l *lastExplanationMeta) ObserveAction(action string, err error) {
+	a := &Action{Type: action, Time: time.Now()}
+	if err != nil {
+		a.Error = err.Error()
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.lastAction = a
+}
+
+// Explain returns a copy of the most recent explanation, or nil if the resource hasn't been reconciled yet.
+func (l *lastExplanationMeta) Explain() *Explanation {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.explanation == nil {
+		return nil
+	}
+	e := *l.explanation
+	if l.lastAction != nil {
+		a := *l.lastAction
+		e.LastAction = &a
+	}
+	return &e
+}
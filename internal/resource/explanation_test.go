@@ -0,0 +1,34 @@
+package resource
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplanation(t *testing.T) {
+	r := &Resource{}
+	assert.Nil(t, r.Explain())
+
+	r.ObserveAction("patch", errors.New("conflict"))
+	r.ObserveDecision(&Explanation{Decision: "Error"})
+
+	e := r.Explain()
+	require.NotNil(t, e)
+	assert.Equal(t, "Error", e.Decision)
+	require.NotNil(t, e.LastAction)
+	assert.Equal(t, "patch", e.LastAction.Type)
+	assert.Equal(t, "conflict", e.LastAction.Error)
+
+	// The last action is retained across decisions
+	r.ObserveDecision(&Explanation{Decision: "InSync"})
+	e = r.Explain()
+	assert.Equal(t, "InSync", e.Decision)
+	assert.Equal(t, "patch", e.LastAction.Type)
+
+	// Returned explanations are copies
+	e.LastAction.Type = "mutated"
+	assert.Equal(t, "patch", r.Explain().LastAction.Type)
+}
@@ -0,0 +1,44 @@
+package resource
+
+// HookAnnotation marks a Job as a hook that runs before the rest of its readiness group is applied
+// ("pre-apply") or after the rest of its readiness group has become ready ("post-ready").
+const HookAnnotation = "eno.azure.io/hook"
+
+// HookDeletePolicyAnnotation controls when hook Jobs are cleaned up.
+const HookDeletePolicyAnnotation = "eno.azure.io/hook-delete-policy"
+
+// HookSynthesisAnnotation is set on hook Jobs to the UUID of the synthesis that created them.
+// Jobs created by earlier syntheses are replaced so that hooks run once per synthesis.
+const HookSynthesisAnnotation = "eno.azure.io/hook-synthesis"
+
+type Hook string
+
+const (
+	HookPreApply  Hook = "pre-apply"
+	HookPostReady Hook = "post-ready"
+)
+
+type HookDeletePolicy string
+
+const (
+	// HookDeleteBeforeCreation leaves the Job in place until the next synthesis replaces it (default).
+	HookDeleteBeforeCreation HookDeletePolicy = "before-hook-creation"
+
+	// HookDeleteSucceeded deletes the Job as soon as it has succeeded.
+	HookDeleteSucceeded HookDeletePolicy = "hook-succeeded"
+)
+
+// hookReadinessCheck is used when hooks don't specify their own readiness checks.
+const hookReadinessCheck = `has(self.status.succeeded) && self.status.succeeded > 0`
+
+// HookStage orders resources within their readiness group: pre-apply hooks, then other resources, then post-ready hooks.
+func (r *Resource) HookStage() int {
+	switch r.Hook {
+	case HookPreApply:
+		return 0
+	case HookPostReady:
+		return 2
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,39 @@
+package resource
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+// DefaultKindTier is the tier of every kind not included in kindTiers e.g. workloads and custom resources.
+const DefaultKindTier = 4
+
+// kindTiers orders resources that share a readiness group by kind, similar to Helm's install order.
+// Resources are not reconciled until every resource in lower tiers of the same readiness group has been reconciled.
+var kindTiers = map[schema.GroupKind]int{
+	{Kind: "Namespace"}: 0,
+
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: 1,
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:               1,
+
+	{Kind: "ServiceAccount"}: 2,
+	{Kind: "ResourceQuota"}:  2,
+	{Kind: "LimitRange"}:     2,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:        2,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}: 2,
+	{Group: "rbac.authorization.k8s.io", Kind: "Role"}:               2,
+	{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"}:        2,
+	{Group: "networking.k8s.io", Kind: "NetworkPolicy"}:              2,
+
+	{Kind: "ConfigMap"}:                             3,
+	{Kind: "Secret"}:                                3,
+	{Kind: "PersistentVolume"}:                      3,
+	{Kind: "PersistentVolumeClaim"}:                 3,
+	{Kind: "Service"}:                               3,
+	{Group: "storage.k8s.io", Kind: "StorageClass"}: 3,
+}
+
+// KindTier returns the position of the given kind in the default apply order.
+func KindTier(gk schema.GroupKind) int {
+	if tier, ok := kindTiers[gk]; ok {
+		return tier
+	}
+	return DefaultKindTier
+}
@@ -0,0 +1,16 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestKindTier(t *testing.T) {
+	assert.Less(t, KindTier(schema.GroupKind{Kind: "Namespace"}), KindTier(schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}))
+	assert.Less(t, KindTier(schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}), KindTier(schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "Role"}))
+	assert.Less(t, KindTier(schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "Role"}), KindTier(schema.GroupKind{Kind: "ConfigMap"}))
+	assert.Less(t, KindTier(schema.GroupKind{Kind: "ConfigMap"}), KindTier(schema.GroupKind{Group: "apps", Kind: "Deployment"}))
+	assert.Equal(t, DefaultKindTier, KindTier(schema.GroupKind{Group: "example.com", Kind: "Anything"}))
+}
@@ -0,0 +1,73 @@
+package resource
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// RequiresAnnotation holds a comma-separated list of capabilities the downstream cluster must have before the resource is applied.
+// Entries are API group versions (apps/v1), kinds (batch/v1.CronJob, v1.ConfigMap), or a minimum Kubernetes version (kubernetes>=1.29).
+const RequiresAnnotation = "eno.azure.io/requires"
+
+const minVersionPrefix = "kubernetes>="
+
+// Requirement is a single capability the downstream cluster must have.
+// Either MinVersion or GroupVersion (and optionally Kind) is set.
+type Requirement struct {
+	GroupVersion schema.GroupVersion
+	Kind         string
+	MinVersion   *version.Version
+}
+
+func (r *Requirement) String() string {
+	if r.MinVersion != nil {
+		return minVersionPrefix + r.MinVersion.String()
+	}
+	if r.Kind != "" {
+		return r.GroupVersion.String() + "." + r.Kind
+	}
+	return r.GroupVersion.String()
+}
+
+// ParseRequirements parses the value of the RequiresAnnotation.
+func ParseRequirements(val string) ([]Requirement, error) {
+	var reqs []Requirement
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		req, err := parseRequirement(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid requirement %q: %w", entry, err)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+func parseRequirement(entry string) (Requirement, error) {
+	if v, ok := strings.CutPrefix(entry, minVersionPrefix); ok {
+		minVersion, err := version.ParseGeneric(v)
+		if err != nil {
+			return Requirement{}, err
+		}
+		return Requirement{MinVersion: minVersion}, nil
+	}
+
+	// The kind follows the first dot after the group e.g. batch/v1.CronJob or v1.ConfigMap
+	req := Requirement{}
+	group, rest, ok := strings.Cut(entry, "/")
+	if !ok {
+		group, rest = "", entry
+	}
+	req.GroupVersion.Group = group
+	req.GroupVersion.Version, req.Kind, _ = strings.Cut(rest, ".")
+	if req.GroupVersion.Version == "" || strings.Contains(req.GroupVersion.Version, "/") || strings.Contains(req.Kind, ".") {
+		return Requirement{}, fmt.Errorf("expected group/version, group/version.Kind, or %sX.Y", minVersionPrefix)
+	}
+	return req, nil
+}
@@ -0,0 +1,72 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/readiness"
+)
+
+func TestParseRequirements(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Expected []string
+		Error    bool
+	}{
+		{Input: "apps/v1", Expected: []string{"apps/v1"}},
+		{Input: "batch/v1.CronJob", Expected: []string{"batch/v1.CronJob"}},
+		{Input: "v1.ConfigMap, v1", Expected: []string{"v1.ConfigMap", "v1"}},
+		{Input: "kubernetes>=1.29, policy/v1", Expected: []string{"kubernetes>=1.29", "policy/v1"}},
+		{Input: " , "},
+		{Input: "kubernetes>=latest", Error: true},
+		{Input: "apps/", Error: true},
+		{Input: "example.com/v1/Foo", Error: true},
+		{Input: "batch/v1.CronJob.Extra", Error: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Input, func(t *testing.T) {
+			reqs, err := ParseRequirements(tc.Input)
+			if tc.Error {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var strs []string
+			for _, req := range reqs {
+				strs = append(strs, req.String())
+			}
+			assert.Equal(t, tc.Expected, strs)
+		})
+	}
+}
+
+func TestNewResourceRequires(t *testing.T) {
+	renv, err := readiness.NewEnv()
+	require.NoError(t, err)
+
+	newResource := func(anno string) *Resource {
+		r, err := NewResource(context.Background(), renv, &apiv1.ResourceSlice{
+			Spec: apiv1.ResourceSliceSpec{
+				Resources: []apiv1.Manifest{{
+					Manifest: `{ "apiVersion": "batch/v1", "kind": "CronJob", "metadata": { "name": "foo", "namespace": "default", "annotations": { "eno.azure.io/requires": "` + anno + `" } } }`,
+				}},
+			},
+		}, 0)
+		require.NoError(t, err)
+		return r
+	}
+
+	r := newResource("batch/v1.CronJob,kubernetes>=1.21")
+	require.Len(t, r.Requires, 2)
+	assert.Equal(t, "batch", r.Requires[0].GroupVersion.Group)
+	assert.Equal(t, "CronJob", r.Requires[0].Kind)
+	assert.Equal(t, uint(21), r.Requires[1].MinVersion.Minor())
+
+	r = newResource("not valid/v1/x")
+	assert.Nil(t, r.Requires)
+}
@@ -24,6 +24,33 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// ProtectAnnotation can be set to "true" on resources (either the manifest or the actual resource) to keep Eno from deleting them.
+const ProtectAnnotation = "eno.azure.io/protect"
+
+// ExportAnnotationPrefix is followed by the name of a value extracted from the resource by the annotation's CEL expression once it's ready.
+const ExportAnnotationPrefix = "eno.azure.io/export-"
+
+// HandsOffUntilAnnotation can be set to an RFC3339 timestamp on resources (the actual resource, not the manifest)
+// to keep Eno from updating them until the deadline has passed e.g. during break-glass operations.
+const HandsOffUntilAnnotation = "eno.azure.io/hands-off-until"
+
+// HandsOffUntil returns the deadline set by the HandsOffUntilAnnotation of the given resource,
+// or nil if the annotation isn't set or the deadline has passed.
+func HandsOffUntil(current *unstructured.Unstructured, now time.Time) (*metav1.Time, error) {
+	val := current.GetAnnotations()[HandsOffUntilAnnotation]
+	if val == "" {
+		return nil, nil
+	}
+	deadline, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", HandsOffUntilAnnotation, err)
+	}
+	if !deadline.After(now) {
+		return nil, nil
+	}
+	return &metav1.Time{Time: deadline}, nil
+}
+
 var patchGVK = schema.GroupVersionKind{
 	Group:   "eno.azure.io",
 	Version: "v1",
@@ -45,6 +72,9 @@ type ManifestRef struct {
 type Resource struct {
 	lastSeenMeta
 	lastReconciledMeta
+	lastExplanationMeta
+	generatedNameMeta
+	noopPatchMeta
 
 	Ref               Ref
 	Manifest          *apiv1.Manifest
@@ -57,14 +87,80 @@ type Resource struct {
 	DisableUpdates    bool
 	ReadinessGroup    int
 
+	// KindTier orders the resource relative to others in the same readiness group based on its kind.
+	KindTier int
+
+	// ContinuousReadiness resources are not considered ready once their readiness checks stop passing,
+	// instead of readiness latching once the checks have passed for the current synthesis.
+	ContinuousReadiness bool
+
+	// CheckObservedGeneration is set on custom resources to keep them from becoming ready until
+	// their status.observedGeneration has caught up with their metadata.generation.
+	CheckObservedGeneration bool
+
+	// SettleDuration is how long the resource must have been ready before the next readiness group is reconciled.
+	// Nil when not set by the manifest, in which case the reconciler's default for the resource's kind applies.
+	SettleDuration *time.Duration
+
+	// Protected resources are never deleted by Eno.
+	Protected bool
+
+	// ApplyStatus resources have the status of their manifest written using the status subresource
+	// after the resource is created or patched. Status is otherwise ignored.
+	ApplyStatus bool
+
 	// DefinedGroupKind is set on CRDs to represent the resource type they define.
 	DefinedGroupKind *schema.GroupKind
+
+	// Dependencies are resources managed by other compositions that must be ready before this resource is reconciled.
+	Dependencies []Dependency
+
+	// Hook is set on Jobs that run before or after the rest of their readiness group.
+	// Hooks run once per synthesis and are never updated.
+	Hook             Hook
+	HookDeletePolicy HookDeletePolicy
+
+	// Requires are capabilities the downstream cluster must have before the resource is applied.
+	Requires []Requirement
+
+	// WaitForDeletion are objects that must not exist for the resource to be considered ready.
+	WaitForDeletion []DeletionRef
+
+	// Exports extract named values from the resource once it's ready, which are published on the composition's status.
+	Exports readiness.Exports
+
+	// ExpiresAfter is how long after its ExpiresFrom basis (creation when empty) the resource is deleted.
+	// Nil when the resource doesn't expire.
+	ExpiresAfter *time.Duration
+	ExpiresFrom  ExpiryBasis
+
+	// GenerateName is set when the manifest uses metadata.generateName instead of a name.
+	// Ref.Name holds the prefix in that case, since the actual name isn't known until the resource has been created.
+	GenerateName string
 }
 
 func (r *Resource) Deleted() bool {
 	return r.SliceDeleted || r.Manifest.Deleted || (r.Patch != nil && r.patchSetsDeletionTimestamp())
 }
 
+// IsProtected returns true when either the manifest or the current state of the resource opt out of deletion.
+func (r *Resource) IsProtected(current *unstructured.Unstructured) bool {
+	return r.Protected || (current != nil && current.GetAnnotations()[ProtectAnnotation] == "true")
+}
+
+// EvalReadiness evaluates the resource's readiness checks, which also require custom resources to have observed their latest generation.
+func (r *Resource) EvalReadiness(ctx context.Context, current *unstructured.Unstructured) (*readiness.Status, bool) {
+	if r.CheckObservedGeneration && !r.Deleted() && !readiness.GenerationObserved(current) {
+		return nil, false
+	}
+	return r.ReadinessChecks.EvalOptionally(ctx, current)
+}
+
+// isCustomGroup returns true for API groups that aren't built in to Kubernetes.
+func isCustomGroup(group string) bool {
+	return strings.Contains(group, ".") && !strings.HasSuffix(group, ".k8s.io")
+}
+
 func (r *Resource) Parse() (*unstructured.Unstructured, error) {
 	u := &unstructured.Unstructured{}
 	return u, u.UnmarshalJSON([]byte(r.Manifest.Manifest))
@@ -150,8 +246,9 @@ func NewResource(ctx context.Context, renv *readiness.Env, slice *apiv1.Resource
 	logger := logr.FromContextOrDiscard(ctx)
 	resource := slice.Spec.Resources[index]
 	res := &Resource{
-		Manifest:     &resource,
-		SliceDeleted: slice.DeletionTimestamp != nil,
+		Manifest:       &resource,
+		SliceDeleted:   slice.DeletionTimestamp != nil,
+		DisableUpdates: resource.DisableUpdates,
 		ManifestRef: ManifestRef{
 			Slice: types.NamespacedName{
 				Namespace: slice.Namespace,
@@ -168,6 +265,10 @@ func NewResource(ctx context.Context, renv *readiness.Env, slice *apiv1.Resource
 	gvk := parsed.GroupVersionKind()
 	res.GVK = gvk
 	res.Ref.Name = parsed.GetName()
+	if res.Ref.Name == "" && parsed.GetGenerateName() != "" {
+		res.GenerateName = parsed.GetGenerateName()
+		res.Ref.Name = res.GenerateName
+	}
 	res.Ref.Namespace = parsed.GetNamespace()
 	res.Ref.Group = parsed.GroupVersionKind().Group
 	res.Ref.Kind = parsed.GetKind()
@@ -195,6 +296,9 @@ func NewResource(ctx context.Context, renv *readiness.Env, slice *apiv1.Resource
 		res.Patch = obj.Patch.Ops
 	}
 
+	res.KindTier = KindTier(res.GVK.GroupKind())
+	res.CheckObservedGeneration = isCustomGroup(res.GVK.Group)
+
 	if res.GVK.Group == "apiextensions.k8s.io" && res.GVK.Kind == "CustomResourceDefinition" {
 		res.DefinedGroupKind = &schema.GroupKind{}
 		res.DefinedGroupKind.Group, _, _ = unstructured.NestedString(parsed.Object, "spec", "group")
@@ -215,9 +319,50 @@ func NewResource(ctx context.Context, renv *readiness.Env, slice *apiv1.Resource
 	delete(anno, reconcileIntervalKey)
 
 	const disableUpdatesKey = "eno.azure.io/disable-updates"
-	res.DisableUpdates = anno[disableUpdatesKey] == "true"
+	res.DisableUpdates = res.DisableUpdates || anno[disableUpdatesKey] == "true" // slices written before the manifest field existed
 	delete(anno, disableUpdatesKey)
 
+	res.Protected = anno[ProtectAnnotation] == "true"
+
+	if val := anno[SettleDurationAnnotation]; val != "" {
+		settle, err := time.ParseDuration(val)
+		if err != nil || settle < 0 {
+			logger.V(0).Info("invalid settle duration - ignoring")
+		} else {
+			res.SettleDuration = &settle
+		}
+	}
+	delete(anno, SettleDurationAnnotation)
+
+	if val := anno[ExpiresAfterAnnotation]; val != "" {
+		ttl, err := time.ParseDuration(val)
+		if err != nil || ttl < 0 {
+			logger.V(0).Info("invalid expiry duration - ignoring")
+		} else {
+			res.ExpiresAfter = &ttl
+		}
+	}
+	switch basis := ExpiryBasis(anno[ExpiresFromAnnotation]); basis {
+	case "", ExpiresFromCreation, ExpiresFromReadiness:
+		res.ExpiresFrom = basis
+	default:
+		logger.V(0).Info("invalid expiry basis - ignoring", "basis", basis)
+	}
+	delete(anno, ExpiresAfterAnnotation)
+	delete(anno, ExpiresFromAnnotation)
+
+	const applyStatusKey = "eno.azure.io/apply-status"
+	res.ApplyStatus = anno[applyStatusKey] == "true"
+	delete(anno, applyStatusKey)
+
+	const disableObservedGenerationKey = "eno.azure.io/disable-observed-generation-check"
+	res.CheckObservedGeneration = res.CheckObservedGeneration && anno[disableObservedGenerationKey] != "true"
+	delete(anno, disableObservedGenerationKey)
+
+	const continuousReadinessKey = "eno.azure.io/continuous-readiness"
+	res.ContinuousReadiness = anno[continuousReadinessKey] == "true"
+	delete(anno, continuousReadinessKey)
+
 	const readinessGroupKey = "eno.azure.io/readiness-group"
 	rg, err := strconv.ParseInt(anno[readinessGroupKey], 10, 64)
 	if anno[readinessGroupKey] != "" && err != nil {
@@ -226,6 +371,69 @@ func NewResource(ctx context.Context, renv *readiness.Env, slice *apiv1.Resource
 	res.ReadinessGroup = int(rg)
 	delete(anno, readinessGroupKey)
 
+	if js := anno[DependsOnAnnotation]; js != "" {
+		err = json.Unmarshal([]byte(js), &res.Dependencies)
+		for i := 0; err == nil && i < len(res.Dependencies); i++ {
+			err = res.Dependencies[i].validate()
+		}
+		if err != nil {
+			logger.Error(err, "invalid dependencies - ignoring")
+			res.Dependencies = nil
+		}
+	}
+	delete(anno, DependsOnAnnotation)
+
+	if val := anno[RequiresAnnotation]; val != "" {
+		res.Requires, err = ParseRequirements(val)
+		if err != nil {
+			logger.Error(err, "invalid requirements - ignoring")
+		}
+	}
+	delete(anno, RequiresAnnotation)
+
+	if js := anno[WaitForDeletionAnnotation]; js != "" {
+		err = json.Unmarshal([]byte(js), &res.WaitForDeletion)
+		for i := 0; err == nil && i < len(res.WaitForDeletion); i++ {
+			err = res.WaitForDeletion[i].validate()
+			if res.WaitForDeletion[i].Namespace == "" {
+				res.WaitForDeletion[i].Namespace = res.Ref.Namespace
+			}
+		}
+		if err != nil {
+			logger.Error(err, "invalid wait-for-deletion references - ignoring")
+			res.WaitForDeletion = nil
+		}
+	}
+	delete(anno, WaitForDeletionAnnotation)
+
+	if hook := Hook(anno[HookAnnotation]); hook != "" {
+		if res.GVK.Group != "batch" || res.GVK.Kind != "Job" || (hook != HookPreApply && hook != HookPostReady) {
+			logger.V(0).Info("invalid hook - ignoring", "hook", hook)
+		} else {
+			res.Hook = hook
+			res.HookDeletePolicy = HookDeleteBeforeCreation
+			if anno[HookDeletePolicyAnnotation] == string(HookDeleteSucceeded) {
+				res.HookDeletePolicy = HookDeleteSucceeded
+			}
+		}
+	}
+	delete(anno, HookAnnotation)
+	delete(anno, HookDeletePolicyAnnotation)
+
+	for key, value := range anno {
+		if !strings.HasPrefix(key, ExportAnnotationPrefix) {
+			continue
+		}
+		delete(anno, key)
+
+		export, err := readiness.ParseExport(renv, strings.TrimPrefix(key, ExportAnnotationPrefix), value)
+		if err != nil {
+			logger.Error(err, "invalid export cel expression")
+			continue
+		}
+		res.Exports = append(res.Exports, export)
+	}
+
 	for key, value := range anno {
 		if !strings.HasPrefix(key, "eno.azure.io/readiness") {
 			continue
@@ -246,6 +454,15 @@ func NewResource(ctx context.Context, renv *readiness.Env, slice *apiv1.Resource
 		res.ReadinessChecks = append(res.ReadinessChecks, check)
 	}
 	parsed.SetAnnotations(anno)
+
+	if res.Hook != "" && len(res.ReadinessChecks) == 0 {
+		check, err := readiness.ParseCheck(renv, hookReadinessCheck)
+		if err != nil {
+			return nil, fmt.Errorf("parsing hook readiness check: %w", err)
+		}
+		check.Name = "hook"
+		res.ReadinessChecks = append(res.ReadinessChecks, check)
+	}
 	sort.Slice(res.ReadinessChecks, func(i, j int) bool { return res.ReadinessChecks[i].Name < res.ReadinessChecks[j].Name })
 
 	return res, nil
@@ -280,6 +497,43 @@ func (l *lastSeenMeta) MatchesLastSeen(rv string) bool {
 	return l.resourceVersion == rv
 }
 
+type generatedNameMeta struct {
+	lock sync.Mutex
+	name string
+}
+
+// ObserveGeneratedName records the name assigned to a resource that uses generateName when it's created.
+// Status is written asynchronously, so this prevents the resource from being created again in the meantime.
+func (g *generatedNameMeta) ObserveGeneratedName(name string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.name = name
+}
+
+func (g *generatedNameMeta) GeneratedName() string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.name
+}
+
+type noopPatchMeta struct {
+	lock  sync.Mutex
+	count int
+}
+
+// ObserveNoopPatch records whether the resource's version changed without drifting from its desired state,
+// returning the number of consecutive times this has happened.
+func (n *noopPatchMeta) ObserveNoopPatch(noop bool) int {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if noop {
+		n.count++
+	} else {
+		n.count = 0
+	}
+	return n.count
+}
+
 type lastReconciledMeta struct {
 	lock           sync.Mutex
 	lastReconciled *time.Time
@@ -9,8 +9,10 @@ import (
 	"github.com/Azure/eno/internal/readiness"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
 )
 
 var newResourceTests = []struct {
@@ -49,6 +51,42 @@ var newResourceTests = []struct {
 			assert.Equal(t, int(250), r.ReadinessGroup)
 		},
 	},
+	{
+		Name: "exports",
+		Manifest: `{
+			"apiVersion": "v1",
+			"kind": "Service",
+			"metadata": {
+				"name": "foo",
+				"annotations": {
+					"eno.azure.io/export-ip": "self.status.loadBalancer.ingress[0].ip",
+					"eno.azure.io/export-invalid": "self.status.("
+				}
+			}
+		}`,
+		Assert: func(t *testing.T, r *Resource) {
+			require.Len(t, r.Exports, 1)
+			assert.Equal(t, "ip", r.Exports[0].Name)
+			assert.Len(t, r.ReadinessChecks, 0)
+		},
+	},
+	{
+		Name: "protected",
+		Manifest: `{
+			"apiVersion": "v1",
+			"kind": "ConfigMap",
+			"metadata": {
+				"name": "foo",
+				"annotations": {
+					"eno.azure.io/protect": "true"
+				}
+			}
+		}`,
+		Assert: func(t *testing.T, r *Resource) {
+			assert.True(t, r.Protected)
+			assert.True(t, r.IsProtected(nil))
+		},
+	},
 	{
 		Name: "zero-readiness-group",
 		Manifest: `{
@@ -213,3 +251,370 @@ func TestNewResource(t *testing.T) {
 		})
 	}
 }
+
+func TestIsProtected(t *testing.T) {
+	r := &Resource{}
+	assert.False(t, r.IsProtected(nil))
+
+	current := &unstructured.Unstructured{}
+	assert.False(t, r.IsProtected(current))
+
+	current.SetAnnotations(map[string]string{ProtectAnnotation: "true"})
+	assert.True(t, r.IsProtected(current))
+}
+
+func TestNewResourceDisableUpdatesField(t *testing.T) {
+	renv, err := readiness.NewEnv()
+	require.NoError(t, err)
+
+	r, err := NewResource(context.Background(), renv, &apiv1.ResourceSlice{
+		Spec: apiv1.ResourceSliceSpec{
+			Resources: []apiv1.Manifest{{
+				Manifest:       `{ "apiVersion": "v1", "kind": "ConfigMap", "metadata": { "name": "foo" } }`,
+				DisableUpdates: true,
+			}},
+		},
+	}, 0)
+	require.NoError(t, err)
+	assert.True(t, r.DisableUpdates)
+}
+
+func TestNewResourceGenerateName(t *testing.T) {
+	renv, err := readiness.NewEnv()
+	require.NoError(t, err)
+
+	r, err := NewResource(context.Background(), renv, &apiv1.ResourceSlice{
+		Spec: apiv1.ResourceSliceSpec{
+			Resources: []apiv1.Manifest{{
+				Manifest: `{ "apiVersion": "batch/v1", "kind": "Job", "metadata": { "generateName": "foo-" } }`,
+			}},
+		},
+	}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "foo-", r.GenerateName)
+	assert.Equal(t, "foo-", r.Ref.Name)
+
+	// Names take precedence
+	r, err = NewResource(context.Background(), renv, &apiv1.ResourceSlice{
+		Spec: apiv1.ResourceSliceSpec{
+			Resources: []apiv1.Manifest{{
+				Manifest: `{ "apiVersion": "batch/v1", "kind": "Job", "metadata": { "name": "foo-abcde", "generateName": "foo-" } }`,
+			}},
+		},
+	}, 0)
+	require.NoError(t, err)
+	assert.Empty(t, r.GenerateName)
+	assert.Equal(t, "foo-abcde", r.Ref.Name)
+}
+
+func TestNewResourceObservedGeneration(t *testing.T) {
+	renv, err := readiness.NewEnv()
+	require.NoError(t, err)
+
+	tests := []struct {
+		Manifest    string
+		Expectation bool
+	}{
+		{Manifest: `{ "apiVersion": "v1", "kind": "ConfigMap", "metadata": { "name": "foo" } }`, Expectation: false},
+		{Manifest: `{ "apiVersion": "apps/v1", "kind": "Deployment", "metadata": { "name": "foo" } }`, Expectation: false},
+		{Manifest: `{ "apiVersion": "networking.k8s.io/v1", "kind": "Ingress", "metadata": { "name": "foo" } }`, Expectation: false},
+		{Manifest: `{ "apiVersion": "example.com/v1", "kind": "Example", "metadata": { "name": "foo" } }`, Expectation: true},
+		{Manifest: `{ "apiVersion": "cluster.x-k8s.io/v1beta1", "kind": "Cluster", "metadata": { "name": "foo" } }`, Expectation: true},
+		{Manifest: `{ "apiVersion": "example.com/v1", "kind": "Example", "metadata": { "name": "foo", "annotations": { "eno.azure.io/disable-observed-generation-check": "true" } } }`, Expectation: false},
+	}
+	for _, tc := range tests {
+		r, err := NewResource(context.Background(), renv, &apiv1.ResourceSlice{
+			Spec: apiv1.ResourceSliceSpec{Resources: []apiv1.Manifest{{Manifest: tc.Manifest}}},
+		}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, tc.Expectation, r.CheckObservedGeneration, tc.Manifest)
+	}
+}
+
+func TestEvalReadinessObservedGeneration(t *testing.T) {
+	r := &Resource{Manifest: &apiv1.Manifest{}, CheckObservedGeneration: true}
+
+	current := &unstructured.Unstructured{Object: map[string]any{"status": map[string]any{"observedGeneration": int64(1)}}}
+	current.SetGeneration(2)
+	_, ok := r.EvalReadiness(context.Background(), current)
+	assert.False(t, ok)
+
+	current.Object["status"] = map[string]any{"observedGeneration": int64(2)}
+	_, ok = r.EvalReadiness(context.Background(), current)
+	assert.True(t, ok)
+
+	// Deleted resources aren't affected
+	r.Manifest.Deleted = true
+	current.Object["status"] = map[string]any{"observedGeneration": int64(1)}
+	_, ok = r.EvalReadiness(context.Background(), current)
+	assert.True(t, ok)
+}
+
+func TestNewResourceContinuousReadiness(t *testing.T) {
+	renv, err := readiness.NewEnv()
+	require.NoError(t, err)
+
+	r, err := NewResource(context.Background(), renv, &apiv1.ResourceSlice{
+		Spec: apiv1.ResourceSliceSpec{
+			Resources: []apiv1.Manifest{{
+				Manifest: `{ "apiVersion": "v1", "kind": "ConfigMap", "metadata": { "name": "foo", "annotations": { "eno.azure.io/continuous-readiness": "true" } } }`,
+			}},
+		},
+	}, 0)
+	require.NoError(t, err)
+	assert.True(t, r.ContinuousReadiness)
+	assert.Empty(t, r.ReadinessChecks)
+}
+
+func TestNewResourceSettleDuration(t *testing.T) {
+	renv, err := readiness.NewEnv()
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name       string
+		Annotation string
+		Expected   *time.Duration
+	}{
+		{Name: "unset"},
+		{Name: "valid", Annotation: "30s", Expected: ptr.To(30 * time.Second)},
+		{Name: "zero", Annotation: "0s", Expected: ptr.To(time.Duration(0))},
+		{Name: "invalid", Annotation: "soon"},
+		{Name: "negative", Annotation: "-5s"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			anno := "{}"
+			if tc.Annotation != "" {
+				anno = `{ "eno.azure.io/settle-duration": "` + tc.Annotation + `" }`
+			}
+			r, err := NewResource(context.Background(), renv, &apiv1.ResourceSlice{
+				Spec: apiv1.ResourceSliceSpec{
+					Resources: []apiv1.Manifest{{
+						Manifest: `{ "apiVersion": "v1", "kind": "ConfigMap", "metadata": { "name": "foo", "annotations": ` + anno + ` } }`,
+					}},
+				},
+			}, 0)
+			require.NoError(t, err)
+			assert.Equal(t, tc.Expected, r.SettleDuration)
+		})
+	}
+}
+
+func TestNewResourceExpiry(t *testing.T) {
+	renv, err := readiness.NewEnv()
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name          string
+		Annotations   string
+		ExpectedAfter *time.Duration
+		ExpectedFrom  ExpiryBasis
+	}{
+		{Name: "unset", Annotations: "{}"},
+		{Name: "valid", Annotations: `{ "eno.azure.io/expires-after": "1h" }`, ExpectedAfter: ptr.To(time.Hour)},
+		{Name: "creation", Annotations: `{ "eno.azure.io/expires-after": "1h", "eno.azure.io/expires-from": "creation" }`, ExpectedAfter: ptr.To(time.Hour), ExpectedFrom: ExpiresFromCreation},
+		{Name: "readiness", Annotations: `{ "eno.azure.io/expires-after": "1h", "eno.azure.io/expires-from": "readiness" }`, ExpectedAfter: ptr.To(time.Hour), ExpectedFrom: ExpiresFromReadiness},
+		{Name: "invalid basis", Annotations: `{ "eno.azure.io/expires-after": "1h", "eno.azure.io/expires-from": "whenever" }`, ExpectedAfter: ptr.To(time.Hour)},
+		{Name: "invalid", Annotations: `{ "eno.azure.io/expires-after": "soon" }`},
+		{Name: "negative", Annotations: `{ "eno.azure.io/expires-after": "-5s" }`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			r, err := NewResource(context.Background(), renv, &apiv1.ResourceSlice{
+				Spec: apiv1.ResourceSliceSpec{
+					Resources: []apiv1.Manifest{{
+						Manifest: `{ "apiVersion": "v1", "kind": "ConfigMap", "metadata": { "name": "foo", "annotations": ` + tc.Annotations + ` } }`,
+					}},
+				},
+			}, 0)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedAfter, r.ExpiresAfter)
+			assert.Equal(t, tc.ExpectedFrom, r.ExpiresFrom)
+		})
+	}
+}
+
+func TestNewResourceDependencies(t *testing.T) {
+	renv, err := readiness.NewEnv()
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name       string
+		Annotation string
+		Expected   []Dependency
+	}{
+		{
+			Name:       "valid",
+			Annotation: `[{\"compositionName\": \"infra\", \"group\": \"apps\", \"kind\": \"Deployment\", \"name\": \"foo\", \"namespace\": \"bar\"}]`,
+			Expected:   []Dependency{{CompositionName: "infra", Group: "apps", Kind: "Deployment", Name: "foo", Namespace: "bar"}},
+		},
+		{
+			Name:       "invalid json",
+			Annotation: `not json`,
+		},
+		{
+			Name:       "missing name",
+			Annotation: `[{\"compositionName\": \"infra\", \"kind\": \"ConfigMap\"}]`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			r, err := NewResource(context.Background(), renv, &apiv1.ResourceSlice{
+				Spec: apiv1.ResourceSliceSpec{
+					Resources: []apiv1.Manifest{{
+						Manifest: `{ "apiVersion": "v1", "kind": "ConfigMap", "metadata": { "name": "foo", "annotations": { "eno.azure.io/depends-on": "` + tc.Annotation + `" } } }`,
+					}},
+				},
+			}, 0)
+			require.NoError(t, err)
+			assert.Equal(t, tc.Expected, r.Dependencies)
+		})
+	}
+}
+
+func TestNewResourceWaitForDeletion(t *testing.T) {
+	renv, err := readiness.NewEnv()
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name       string
+		Annotation string
+		Expected   []DeletionRef
+	}{
+		{
+			Name:       "valid",
+			Annotation: `[{\"apiVersion\": \"batch/v1\", \"kind\": \"Job\", \"name\": \"migrate\", \"namespace\": \"bar\"}]`,
+			Expected:   []DeletionRef{{APIVersion: "batch/v1", Kind: "Job", Name: "migrate", Namespace: "bar"}},
+		},
+		{
+			Name:       "namespace defaulted",
+			Annotation: `[{\"apiVersion\": \"apps/v1\", \"kind\": \"Deployment\", \"name\": \"legacy\"}]`,
+			Expected:   []DeletionRef{{APIVersion: "apps/v1", Kind: "Deployment", Name: "legacy", Namespace: "default"}},
+		},
+		{
+			Name:       "invalid json",
+			Annotation: `not json`,
+		},
+		{
+			Name:       "missing apiVersion",
+			Annotation: `[{\"kind\": \"Job\", \"name\": \"migrate\"}]`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			r, err := NewResource(context.Background(), renv, &apiv1.ResourceSlice{
+				Spec: apiv1.ResourceSliceSpec{
+					Resources: []apiv1.Manifest{{
+						Manifest: `{ "apiVersion": "v1", "kind": "ConfigMap", "metadata": { "name": "foo", "namespace": "default", "annotations": { "eno.azure.io/wait-for-deletion": "` + tc.Annotation + `" } } }`,
+					}},
+				},
+			}, 0)
+			require.NoError(t, err)
+			assert.Equal(t, tc.Expected, r.WaitForDeletion)
+		})
+	}
+}
+
+func TestNewResourceHook(t *testing.T) {
+	renv, err := readiness.NewEnv()
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name           string
+		Manifest       string
+		ExpectedHook   Hook
+		ExpectedPolicy HookDeletePolicy
+		ExpectedStage  int
+		ExpectedChecks []string
+	}{
+		{
+			Name:          "not a hook",
+			Manifest:      `{ "apiVersion": "batch/v1", "kind": "Job", "metadata": { "name": "foo" } }`,
+			ExpectedStage: 1,
+		},
+		{
+			Name:           "pre-apply",
+			Manifest:       `{ "apiVersion": "batch/v1", "kind": "Job", "metadata": { "name": "foo", "annotations": { "eno.azure.io/hook": "pre-apply" } } }`,
+			ExpectedHook:   HookPreApply,
+			ExpectedPolicy: HookDeleteBeforeCreation,
+			ExpectedStage:  0,
+			ExpectedChecks: []string{"hook"},
+		},
+		{
+			Name:           "post-ready with delete policy",
+			Manifest:       `{ "apiVersion": "batch/v1", "kind": "Job", "metadata": { "name": "foo", "annotations": { "eno.azure.io/hook": "post-ready", "eno.azure.io/hook-delete-policy": "hook-succeeded" } } }`,
+			ExpectedHook:   HookPostReady,
+			ExpectedPolicy: HookDeleteSucceeded,
+			ExpectedStage:  2,
+			ExpectedChecks: []string{"hook"},
+		},
+		{
+			Name:           "custom readiness check",
+			Manifest:       `{ "apiVersion": "batch/v1", "kind": "Job", "metadata": { "name": "foo", "annotations": { "eno.azure.io/hook": "pre-apply", "eno.azure.io/readiness-custom": "true" } } }`,
+			ExpectedHook:   HookPreApply,
+			ExpectedPolicy: HookDeleteBeforeCreation,
+			ExpectedStage:  0,
+			ExpectedChecks: []string{"custom"},
+		},
+		{
+			Name:          "invalid hook",
+			Manifest:      `{ "apiVersion": "batch/v1", "kind": "Job", "metadata": { "name": "foo", "annotations": { "eno.azure.io/hook": "pre-install" } } }`,
+			ExpectedStage: 1,
+		},
+		{
+			Name:          "not a job",
+			Manifest:      `{ "apiVersion": "v1", "kind": "Pod", "metadata": { "name": "foo", "annotations": { "eno.azure.io/hook": "pre-apply" } } }`,
+			ExpectedStage: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			r, err := NewResource(context.Background(), renv, &apiv1.ResourceSlice{
+				Spec: apiv1.ResourceSliceSpec{
+					Resources: []apiv1.Manifest{{Manifest: tc.Manifest}},
+				},
+			}, 0)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedHook, r.Hook)
+			assert.Equal(t, tc.ExpectedPolicy, r.HookDeletePolicy)
+			assert.Equal(t, tc.ExpectedStage, r.HookStage())
+
+			var names []string
+			for _, check := range r.ReadinessChecks {
+				names = append(names, check.Name)
+			}
+			assert.Equal(t, tc.ExpectedChecks, names)
+		})
+	}
+}
+
+func TestHandsOffUntil(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		Name       string
+		Annotation string
+		Expected   *metav1.Time
+		Error      bool
+	}{
+		{Name: "unset"},
+		{Name: "future", Annotation: "2024-01-01T13:00:00Z", Expected: &metav1.Time{Time: now.Add(time.Hour)}},
+		{Name: "past", Annotation: "2024-01-01T11:00:00Z"},
+		{Name: "invalid", Annotation: "tomorrow", Error: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			current := &unstructured.Unstructured{Object: map[string]any{}}
+			if tc.Annotation != "" {
+				current.SetAnnotations(map[string]string{HandsOffUntilAnnotation: tc.Annotation})
+			}
+			deadline, err := HandsOffUntil(current, now)
+			assert.Equal(t, tc.Error, err != nil)
+			if tc.Expected == nil {
+				assert.Nil(t, deadline)
+			} else {
+				require.NotNil(t, deadline)
+				assert.True(t, tc.Expected.Equal(deadline))
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package resource
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+// SettleDurationAnnotation can be set to a duration on resources to keep later readiness groups from being
+// reconciled until the resource has been ready for that long. Useful for resources that install admission
+// infrastructure, which usually takes a moment to start serving after it's been created.
+const SettleDurationAnnotation = "eno.azure.io/settle-duration"
+
+// admissionKinds register webhooks or aggregated apiservers that sit in the request path of other resources.
+var admissionKinds = map[schema.GroupKind]struct{}{
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: {},
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   {},
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:                           {},
+}
+
+// IsAdmissionKind returns true for kinds that register admission webhooks or aggregated apiservers.
+func IsAdmissionKind(gk schema.GroupKind) bool {
+	_, ok := admissionKinds[gk]
+	return ok
+}
@@ -0,0 +1,17 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestIsAdmissionKind(t *testing.T) {
+	assert.True(t, IsAdmissionKind(schema.GroupKind{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}))
+	assert.True(t, IsAdmissionKind(schema.GroupKind{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}))
+	assert.True(t, IsAdmissionKind(schema.GroupKind{Group: "apiregistration.k8s.io", Kind: "APIService"}))
+	assert.False(t, IsAdmissionKind(schema.GroupKind{Group: "admissionregistration.k8s.io", Kind: "ValidatingAdmissionPolicy"}))
+	assert.False(t, IsAdmissionKind(schema.GroupKind{Kind: "Service"}))
+	assert.False(t, IsAdmissionKind(schema.GroupKind{Group: "example.com", Kind: "APIService"}))
+}
@@ -16,13 +16,23 @@ import (
 func Slice(comp *apiv1.Composition, previous []*apiv1.ResourceSlice, outputs []*unstructured.Unstructured, maxJsonBytes int) ([]*apiv1.ResourceSlice, error) {
 	refs := map[resourceRef]struct{}{}
 	manifests := []apiv1.Manifest{}
+	generated := generatedNames(previous)
 	for i, output := range outputs {
+		// Resources using generateName keep the name they were given when first created
+		if output.GetName() == "" && output.GetGenerateName() != "" {
+			if name, ok := generated[newResourceRef(output)]; ok {
+				output = output.DeepCopy()
+				output.SetName(name)
+			}
+		}
+
 		js, err := output.MarshalJSON()
 		if err != nil {
 			return nil, reconcile.TerminalError(fmt.Errorf("encoding output %d: %w", i, err))
 		}
 		manifests = append(manifests, apiv1.Manifest{
-			Manifest: string(js),
+			Manifest:       string(js),
+			DisableUpdates: output.GetAnnotations()["eno.azure.io/disable-updates"] == "true",
 		})
 		refs[newResourceRef(output)] = struct{}{}
 	}
@@ -43,8 +53,18 @@ func Slice(comp *apiv1.Composition, previous []*apiv1.ResourceSlice, outputs []*
 				continue
 			}
 
+			// Tombstones of resources that use generateName need the name they were given to be deleted
+			if name := generatedName(slice, i, obj); name != "" && obj.GetName() == "" {
+				obj.SetName(name)
+				js, err := obj.MarshalJSON()
+				if err != nil {
+					return nil, reconcile.TerminalError(fmt.Errorf("encoding resource %d of slice %s: %w", i, slice.Name, err))
+				}
+				res.Manifest = string(js)
+			}
+
 			// We don't need a tombstone once the deleted resource has been reconciled
-			if _, ok := refs[newResourceRef(obj)]; ok || ((res.Deleted || slice.DeletionTimestamp != nil) && slice.Status.Resources != nil && slice.Status.Resources[i].Reconciled) {
+			if _, ok := refs[newResourceRef(obj)]; ok || ((res.Deleted || slice.DeletionTimestamp != nil) && removalReconciled(comp, slice, i)) {
 				continue // still exists or has already been deleted
 			}
 
@@ -89,6 +109,56 @@ func Slice(comp *apiv1.Composition, previous []*apiv1.ResourceSlice, outputs []*
 	return slices, nil
 }
 
+// removalReconciled returns true when the tombstone at the given index is no longer needed.
+// Status must confirm the resource's deletion (unless resources are orphaned), not just its reconciliation,
+// since losing the tombstone before the resource is actually gone would orphan it.
+func removalReconciled(comp *apiv1.Composition, slice *apiv1.ResourceSlice, i int) bool {
+	if len(slice.Status.Resources) <= i {
+		return false
+	}
+	state := slice.Status.Resources[i]
+	return state.Reconciled && (state.Deleted || comp.ShouldOrphan())
+}
+
+// generatedNames maps the generateName of resources in the given slices to the names they were given when created.
+func generatedNames(slices []*apiv1.ResourceSlice) map[resourceRef]string {
+	names := map[resourceRef]string{}
+	for _, slice := range slices {
+		for i, res := range slice.Spec.Resources {
+			if res.Deleted {
+				continue
+			}
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON([]byte(res.Manifest)); err != nil || obj.GetGenerateName() == "" {
+				continue
+			}
+			name := generatedName(slice, i, obj)
+			if name == "" {
+				continue // not created yet
+			}
+			ref := newResourceRef(obj)
+			ref.Name = obj.GetGenerateName()
+			names[ref] = name
+		}
+	}
+	return names
+}
+
+// generatedName returns the name of a resource that uses generateName, either from its manifest
+// (when carried over from an earlier synthesis) or as recorded in the slice's status.
+func generatedName(slice *apiv1.ResourceSlice, i int, obj *unstructured.Unstructured) string {
+	if obj.GetGenerateName() == "" {
+		return ""
+	}
+	if name := obj.GetName(); name != "" {
+		return name
+	}
+	if len(slice.Status.Resources) <= i {
+		return ""
+	}
+	return slice.Status.Resources[i].GeneratedName
+}
+
 type resourceRef struct {
 	Name, Namespace, Kind, Group string
 }
@@ -106,8 +176,12 @@ func newResourceRef(obj *unstructured.Unstructured) resourceRef {
 		}
 	}
 
+	name := obj.GetName()
+	if name == "" {
+		name = obj.GetGenerateName()
+	}
 	return resourceRef{
-		Name:      obj.GetName(),
+		Name:      name,
 		Namespace: obj.GetNamespace(),
 		Kind:      obj.GetKind(),
 		Group:     obj.GroupVersionKind().Group,
@@ -53,10 +53,40 @@ func TestSliceTombstonesBasics(t *testing.T) {
 	require.Len(t, slices[0].Spec.Resources, 1)
 	assert.True(t, slices[0].Spec.Resources[0].Deleted)
 
-	// The tombstone is removed once it has been reconciled
+	// Reconciliation alone isn't enough - the deletion must be confirmed
 	slices[0].Status.Resources = []apiv1.ResourceState{{Reconciled: true}}
 	slices, err = Slice(&apiv1.Composition{}, slices, []*unstructured.Unstructured{}, 100000)
 	require.NoError(t, err)
+	require.Len(t, slices, 1)
+	require.Len(t, slices[0].Spec.Resources, 1)
+	assert.True(t, slices[0].Spec.Resources[0].Deleted)
+
+	// The tombstone is removed once it has been deleted
+	slices[0].Status.Resources = []apiv1.ResourceState{{Reconciled: true, Deleted: true}}
+	slices, err = Slice(&apiv1.Composition{}, slices, []*unstructured.Unstructured{}, 100000)
+	require.NoError(t, err)
+	require.Len(t, slices, 0)
+}
+
+func TestSliceTombstonesOrphaned(t *testing.T) {
+	comp := &apiv1.Composition{}
+	comp.Annotations = map[string]string{"eno.azure.io/deletion-strategy": "orphan"}
+
+	previous := []*apiv1.ResourceSlice{{
+		Spec: apiv1.ResourceSliceSpec{
+			Resources: []apiv1.Manifest{{Manifest: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "test"}}`, Deleted: true}},
+		},
+	}}
+
+	// Tombstones are passed down while status is missing/lagging
+	slices, err := Slice(comp, previous, []*unstructured.Unstructured{}, 100000)
+	require.NoError(t, err)
+	require.Len(t, slices, 1)
+
+	// Orphaned resources aren't deleted, so reconciliation is sufficient
+	previous[0].Status.Resources = []apiv1.ResourceState{{Reconciled: true}}
+	slices, err = Slice(comp, previous, []*unstructured.Unstructured{}, 100000)
+	require.NoError(t, err)
 	require.Len(t, slices, 0)
 }
 
@@ -154,3 +184,46 @@ func TestSliceTombstonesVersionSemantics(t *testing.T) {
 	require.Len(t, slices, 1)
 	require.Len(t, slices[0].Spec.Resources, 2)
 }
+
+func TestSliceGenerateName(t *testing.T) {
+	newOutput := func() *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"kind":       "Job",
+			"apiVersion": "batch/v1",
+			"metadata":   map[string]any{"generateName": "test-", "namespace": "test-ns"},
+		}}
+	}
+	outputs := []*unstructured.Unstructured{newOutput()}
+
+	slices, err := Slice(&apiv1.Composition{}, []*apiv1.ResourceSlice{}, outputs, 100000)
+	require.NoError(t, err)
+	require.Len(t, slices, 1)
+	require.Len(t, slices[0].Spec.Resources, 1)
+
+	// Not created yet - the manifest still uses generateName and no tombstone is created
+	slices, err = Slice(&apiv1.Composition{}, slices, []*unstructured.Unstructured{newOutput()}, 100000)
+	require.NoError(t, err)
+	require.Len(t, slices[0].Spec.Resources, 1)
+	assert.NotContains(t, slices[0].Spec.Resources[0].Manifest, `"name"`)
+
+	// The generated name is carried forward once it has been recorded in status
+	slices[0].Status.Resources = []apiv1.ResourceState{{Reconciled: true, GeneratedName: "test-abcde"}}
+	slices, err = Slice(&apiv1.Composition{}, slices, []*unstructured.Unstructured{newOutput()}, 100000)
+	require.NoError(t, err)
+	require.Len(t, slices[0].Spec.Resources, 1)
+	assert.Contains(t, slices[0].Spec.Resources[0].Manifest, `"name":"test-abcde"`)
+	assert.False(t, slices[0].Spec.Resources[0].Deleted)
+
+	// ...and remains stable in subsequent syntheses
+	slices, err = Slice(&apiv1.Composition{}, slices, []*unstructured.Unstructured{newOutput()}, 100000)
+	require.NoError(t, err)
+	require.Len(t, slices[0].Spec.Resources, 1)
+	assert.Contains(t, slices[0].Spec.Resources[0].Manifest, `"name":"test-abcde"`)
+
+	// Tombstones refer to the generated name
+	slices, err = Slice(&apiv1.Composition{}, slices, []*unstructured.Unstructured{}, 100000)
+	require.NoError(t, err)
+	require.Len(t, slices[0].Spec.Resources, 1)
+	assert.True(t, slices[0].Spec.Resources[0].Deleted)
+	assert.Contains(t, slices[0].Spec.Resources[0].Manifest, `"name":"test-abcde"`)
+}
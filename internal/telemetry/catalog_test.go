@@ -0,0 +1,32 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/Azure/eno/internal/controllers/flowcontrol"
+	_ "github.com/Azure/eno/internal/controllers/reconciliation"
+	_ "github.com/Azure/eno/internal/controllers/synthesis"
+	_ "github.com/Azure/eno/internal/controllers/watchdog"
+	_ "github.com/Azure/eno/internal/discovery"
+	_ "github.com/Azure/eno/internal/flowcontrol"
+	_ "github.com/Azure/eno/internal/k8s"
+	_ "github.com/Azure/eno/internal/reconstitution"
+	"github.com/Azure/eno/internal/telemetry"
+)
+
+// TestCatalog proves that every metric exported by Eno can be documented and graphed.
+func TestCatalog(t *testing.T) {
+	metrics := telemetry.Metrics()
+	assert.NotEmpty(t, metrics)
+
+	for _, m := range metrics {
+		assert.Regexp(t, "^eno_", m.Name)
+		assert.NotEmpty(t, m.Help, "metric %s", m.Name)
+		assert.NotEqual(t, telemetry.TypeUntyped, m.Type, "metric %s", m.Name)
+	}
+
+	d := telemetry.NewDashboard("Eno", metrics)
+	assert.Len(t, d.Panels, len(metrics))
+}
@@ -0,0 +1,168 @@
+package telemetry
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	panelWidth  = 12
+	panelHeight = 8
+)
+
+// Dashboard is the subset of Grafana's dashboard JSON model used by the generated dashboards.
+// The datasource is a template variable, so the JSON can be imported into any Grafana instance.
+type Dashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Tags          []string   `json:"tags"`
+	Editable      bool       `json:"editable"`
+	Refresh       string     `json:"refresh"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Time          TimeRange  `json:"time"`
+	Templating    Templating `json:"templating"`
+	Panels        []*Panel   `json:"panels"`
+}
+
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type Templating struct {
+	List []*Variable `json:"list"`
+}
+
+type Variable struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Query string `json:"query"`
+}
+
+type Panel struct {
+	ID          int         `json:"id"`
+	Type        string      `json:"type"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Datasource  Datasource  `json:"datasource"`
+	GridPos     GridPos     `json:"gridPos"`
+	FieldConfig FieldConfig `json:"fieldConfig"`
+	Targets     []*Target   `json:"targets"`
+}
+
+type Datasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type FieldConfig struct {
+	Defaults FieldDefaults `json:"defaults"`
+}
+
+type FieldDefaults struct {
+	Unit string `json:"unit,omitempty"`
+}
+
+type Target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+}
+
+// NewDashboard generates a dashboard with one panel per metric:
+// counters are graphed as per-second rates, gauges as their current value, and histograms as p50/p99 quantiles.
+// Series are aggregated across pods but split by each metric's labels.
+func NewDashboard(title string, metrics []*Metric) *Dashboard {
+	d := &Dashboard{
+		UID:           strings.ReplaceAll(strings.ToLower(title), " ", "-"),
+		Title:         title,
+		Tags:          []string{"eno"},
+		Editable:      true,
+		Refresh:       "1m",
+		SchemaVersion: 39,
+		Time:          TimeRange{From: "now-6h", To: "now"},
+		Templating: Templating{List: []*Variable{{
+			Name:  "datasource",
+			Label: "Data source",
+			Type:  "datasource",
+			Query: "prometheus",
+		}}},
+		Panels: []*Panel{},
+	}
+
+	for _, m := range metrics {
+		targets := newTargets(m)
+		if targets == nil {
+			continue // untyped metrics can't be graphed meaningfully
+		}
+		n := len(d.Panels)
+		d.Panels = append(d.Panels, &Panel{
+			ID:          n + 1,
+			Type:        "timeseries",
+			Title:       m.Name,
+			Description: m.Help,
+			Datasource:  Datasource{Type: "prometheus", UID: "${datasource}"},
+			GridPos:     GridPos{H: panelHeight, W: panelWidth, X: (n % 2) * panelWidth, Y: (n / 2) * panelHeight},
+			FieldConfig: FieldConfig{Defaults: FieldDefaults{Unit: unitOf(m)}},
+			Targets:     targets,
+		})
+	}
+	return d
+}
+
+func newTargets(m *Metric) []*Target {
+	legend := legendFormat(m.Labels)
+	switch m.Type {
+	case TypeCounter:
+		return []*Target{{RefID: "A", Expr: sumBy(m.Labels, fmt.Sprintf("rate(%s[$__rate_interval])", m.Name)), LegendFormat: legend}}
+
+	case TypeGauge:
+		return []*Target{{RefID: "A", Expr: sumBy(m.Labels, m.Name), LegendFormat: legend}}
+
+	case TypeHistogram:
+		buckets := sumBy(append([]string{"le"}, m.Labels...), fmt.Sprintf("rate(%s_bucket[$__rate_interval])", m.Name))
+		return []*Target{
+			{RefID: "A", Expr: fmt.Sprintf("histogram_quantile(0.5, %s)", buckets), LegendFormat: strings.TrimSpace("p50 " + legend)},
+			{RefID: "B", Expr: fmt.Sprintf("histogram_quantile(0.99, %s)", buckets), LegendFormat: strings.TrimSpace("p99 " + legend)},
+		}
+
+	default:
+		return nil
+	}
+}
+
+func sumBy(labels []string, expr string) string {
+	if len(labels) == 0 {
+		return fmt.Sprintf("sum(%s)", expr)
+	}
+	return fmt.Sprintf("sum by (%s) (%s)", strings.Join(labels, ", "), expr)
+}
+
+func legendFormat(labels []string) string {
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = "{{" + label + "}}"
+	}
+	return strings.Join(parts, " ")
+}
+
+func unitOf(m *Metric) string {
+	switch {
+	case m.Type == TypeCounter:
+		return "ops"
+	case strings.HasSuffix(m.Name, "_seconds"):
+		return "s"
+	case strings.HasSuffix(m.Name, "_bytes"):
+		return "bytes"
+	default:
+		return ""
+	}
+}
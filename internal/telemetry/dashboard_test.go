@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDashboard(t *testing.T) {
+	d := NewDashboard("Eno Test", []*Metric{
+		{Name: "eno_foo_total", Type: TypeCounter, Help: "foo", Labels: []string{"a", "b"}},
+		{Name: "eno_bar", Type: TypeGauge, Help: "bar"},
+		{Name: "eno_untyped", Type: TypeUntyped},
+		{Name: "eno_baz_seconds", Type: TypeHistogram, Help: "baz", Labels: []string{"a"}},
+	})
+	assert.Equal(t, "eno-test", d.UID)
+	require.Len(t, d.Panels, 3)
+
+	counter := d.Panels[0]
+	assert.Equal(t, 1, counter.ID)
+	assert.Equal(t, "foo", counter.Description)
+	assert.Equal(t, "ops", counter.FieldConfig.Defaults.Unit)
+	assert.Equal(t, GridPos{H: panelHeight, W: panelWidth, X: 0, Y: 0}, counter.GridPos)
+	require.Len(t, counter.Targets, 1)
+	assert.Equal(t, "sum by (a, b) (rate(eno_foo_total[$__rate_interval]))", counter.Targets[0].Expr)
+	assert.Equal(t, "{{a}} {{b}}", counter.Targets[0].LegendFormat)
+
+	gauge := d.Panels[1]
+	assert.Equal(t, GridPos{H: panelHeight, W: panelWidth, X: panelWidth, Y: 0}, gauge.GridPos)
+	require.Len(t, gauge.Targets, 1)
+	assert.Equal(t, "sum(eno_bar)", gauge.Targets[0].Expr)
+	assert.Equal(t, "", gauge.Targets[0].LegendFormat)
+
+	histogram := d.Panels[2]
+	assert.Equal(t, 3, histogram.ID)
+	assert.Equal(t, "s", histogram.FieldConfig.Defaults.Unit)
+	assert.Equal(t, GridPos{H: panelHeight, W: panelWidth, X: 0, Y: panelHeight}, histogram.GridPos)
+	require.Len(t, histogram.Targets, 2)
+	assert.Equal(t, "histogram_quantile(0.5, sum by (le, a) (rate(eno_baz_seconds_bucket[$__rate_interval])))", histogram.Targets[0].Expr)
+	assert.Equal(t, "p50 {{a}}", histogram.Targets[0].LegendFormat)
+	assert.Equal(t, "histogram_quantile(0.99, sum by (le, a) (rate(eno_baz_seconds_bucket[$__rate_interval])))", histogram.Targets[1].Expr)
+}
+
+func TestDashboardHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	DashboardHandler("Eno Test").ServeHTTP(w, httptest.NewRequest("GET", "/metrics/dashboard", nil))
+
+	d := &Dashboard{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), d))
+	assert.Equal(t, "Eno Test", d.Title)
+	assert.Equal(t, "datasource", d.Templating.List[0].Type)
+}
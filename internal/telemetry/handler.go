@@ -0,0 +1,22 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// CatalogHandler serves every cataloged metric as JSON.
+func CatalogHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Metrics())
+	})
+}
+
+// DashboardHandler serves a Grafana dashboard of every cataloged metric, ready to be imported.
+func DashboardHandler(title string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(NewDashboard(title, Metrics()))
+	})
+}
@@ -0,0 +1,158 @@
+// Package telemetry catalogs Eno's Prometheus metrics so they can describe themselves.
+//
+// Packages register their collectors with MustRegister instead of controller-runtime's registry directly.
+// The catalog is served as JSON alongside the metrics endpoint, and Grafana dashboards are generated from it,
+// so documentation and dashboards can't drift from the metrics the process actually exports.
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Metric types. Matches the names used by the Prometheus exposition format.
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+	TypeUntyped   = "untyped"
+)
+
+// Metric describes a metric exported by Eno.
+type Metric struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Help   string   `json:"help"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+var (
+	lock    sync.Mutex
+	catalog = map[string]*Metric{}
+)
+
+// MustRegister registers the collectors with controller-runtime's metrics registry and adds them to the catalog.
+// Like prometheus.MustRegister, it panics if a collector can't be registered or described.
+func MustRegister(cs ...prometheus.Collector) {
+	metrics.Registry.MustRegister(cs...)
+
+	lock.Lock()
+	defer lock.Unlock()
+	for _, c := range cs {
+		for _, m := range describe(c) {
+			catalog[m.Name] = m
+		}
+	}
+}
+
+// Metrics returns every cataloged metric, sorted by name.
+func Metrics() []*Metric {
+	lock.Lock()
+	defer lock.Unlock()
+
+	list := make([]*Metric, 0, len(catalog))
+	for _, m := range catalog {
+		cp := *m
+		list = append(list, &cp)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func describe(c prometheus.Collector) []*Metric {
+	ch := make(chan *prometheus.Desc)
+	go func() {
+		c.Describe(ch)
+		close(ch)
+	}()
+
+	typ := collectorType(c)
+	var list []*Metric
+	for desc := range ch {
+		m, err := parseDesc(desc.String())
+		if err != nil {
+			panic(fmt.Sprintf("describing metric: %s", err))
+		}
+		m.Type = typ
+		list = append(list, m)
+	}
+	return list
+}
+
+// collectorType infers the type of a collector since descriptors don't include it.
+// Gauges are checked before counters because they also implement Inc and Add.
+func collectorType(c prometheus.Collector) string {
+	switch c.(type) {
+	case prometheus.Gauge, *prometheus.GaugeVec:
+		return TypeGauge
+	case prometheus.Counter, *prometheus.CounterVec:
+		return TypeCounter
+	case prometheus.Histogram, *prometheus.HistogramVec:
+		return TypeHistogram
+	default:
+		return TypeUntyped
+	}
+}
+
+// parseDesc extracts the metric's name, help, and variable labels from prometheus.Desc.String
+// since they aren't otherwise exposed:
+//
+//	Desc{fqName: "name", help: "help", constLabels: {}, variableLabels: {a,b}}
+func parseDesc(str string) (*Metric, error) {
+	m := &Metric{}
+	rest, ok := strings.CutPrefix(str, "Desc{fqName: ")
+	if !ok {
+		return nil, fmt.Errorf("unexpected descriptor format: %s", str)
+	}
+
+	var err error
+	m.Name, rest, err = cutQuoted(rest)
+	if err != nil {
+		return nil, fmt.Errorf("parsing name of %s: %w", str, err)
+	}
+	rest, ok = strings.CutPrefix(rest, ", help: ")
+	if !ok {
+		return nil, fmt.Errorf("unexpected descriptor format: %s", str)
+	}
+	m.Help, rest, err = cutQuoted(rest)
+	if err != nil {
+		return nil, fmt.Errorf("parsing help of %s: %w", str, err)
+	}
+
+	i := strings.LastIndex(rest, "variableLabels: {")
+	if i == -1 || !strings.HasSuffix(rest, "}}") {
+		return nil, fmt.Errorf("unexpected descriptor format: %s", str)
+	}
+	labels := rest[i+len("variableLabels: {") : len(rest)-2]
+	for _, label := range strings.Split(labels, ",") {
+		// Constrained labels are rendered as c(name)
+		label = strings.TrimSuffix(strings.TrimPrefix(label, "c("), ")")
+		if label != "" {
+			m.Labels = append(m.Labels, label)
+		}
+	}
+
+	if m.Name == "" {
+		return nil, errors.New("metric name is empty")
+	}
+	return m, nil
+}
+
+func cutQuoted(str string) (value, rest string, err error) {
+	quoted, err := strconv.QuotedPrefix(str)
+	if err != nil {
+		return "", "", err
+	}
+	value, err = strconv.Unquote(quoted)
+	if err != nil {
+		return "", "", err
+	}
+	return value, str[len(quoted):], nil
+}
@@ -0,0 +1,90 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Collector prometheus.Collector
+		Expected  *Metric
+	}{
+		{
+			Name:      "counter",
+			Collector: prometheus.NewCounter(prometheus.CounterOpts{Name: "test_counter_total", Help: "Counts \"things\", {sometimes}"}),
+			Expected:  &Metric{Name: "test_counter_total", Type: TypeCounter, Help: "Counts \"things\", {sometimes}"},
+		},
+		{
+			Name:      "counterVec",
+			Collector: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_counter_vec_total", Help: "foo"}, []string{"a", "b"}),
+			Expected:  &Metric{Name: "test_counter_vec_total", Type: TypeCounter, Help: "foo", Labels: []string{"a", "b"}},
+		},
+		{
+			Name:      "gauge",
+			Collector: prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_gauge", Help: "foo", ConstLabels: prometheus.Labels{"const": "}}"}}),
+			Expected:  &Metric{Name: "test_gauge", Type: TypeGauge, Help: "foo"},
+		},
+		{
+			Name:      "gaugeVec",
+			Collector: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_gauge_vec", Help: "foo"}, []string{"a"}),
+			Expected:  &Metric{Name: "test_gauge_vec", Type: TypeGauge, Help: "foo", Labels: []string{"a"}},
+		},
+		{
+			Name:      "histogramVec",
+			Collector: prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_histogram_seconds", Help: "foo"}, []string{"a"}),
+			Expected:  &Metric{Name: "test_histogram_seconds", Type: TypeHistogram, Help: "foo", Labels: []string{"a"}},
+		},
+		{
+			Name: "constrainedLabels",
+			Collector: prometheus.V2.NewCounterVec(prometheus.CounterVecOpts{
+				CounterOpts:    prometheus.CounterOpts{Name: "test_constrained_total", Help: "foo"},
+				VariableLabels: prometheus.ConstrainedLabels{{Name: "a", Constraint: func(s string) string { return s }}, {Name: "b"}},
+			}),
+			Expected: &Metric{Name: "test_constrained_total", Type: TypeCounter, Help: "foo", Labels: []string{"a", "b"}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			metrics := describe(tc.Collector)
+			require.Len(t, metrics, 1)
+			assert.Equal(t, tc.Expected, metrics[0])
+		})
+	}
+}
+
+func TestParseDescInvalid(t *testing.T) {
+	_, err := parseDesc("not a descriptor")
+	assert.Error(t, err)
+
+	_, err = parseDesc(`Desc{fqName: "foo", help: "bar"}`)
+	assert.Error(t, err)
+}
+
+func TestMustRegister(t *testing.T) {
+	MustRegister(prometheus.NewCounterVec(prometheus.CounterOpts{Name: "eno_test_registered_total", Help: "foo"}, []string{"a"}))
+
+	var found *Metric
+	for _, m := range Metrics() {
+		if m.Name == "eno_test_registered_total" {
+			found = m
+		}
+	}
+	require.NotNil(t, found)
+	assert.Equal(t, TypeCounter, found.Type)
+
+	// Served as JSON
+	w := httptest.NewRecorder()
+	CatalogHandler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics/catalog", nil))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var served []*Metric
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &served))
+	assert.Contains(t, served, found)
+}
@@ -0,0 +1,363 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/execution"
+	"github.com/Azure/eno/internal/freeze"
+	"github.com/Azure/eno/internal/manager"
+)
+
+// compositionValidator rejects compositions that would otherwise silently fail to synthesize or reconcile,
+// and warns about references that can't be resolved yet.
+type compositionValidator struct {
+	client    client.Reader
+	opts      CompositionValidatorOptions
+	authorize func(context.Context, *authorizationv1.SubjectAccessReview) (bool, error)
+}
+
+// DeletionConfirmationVerb is the verb on compositions that users must be authorized for in order to confirm
+// the deletion of a protected composition, or to disable its deletion protection.
+const DeletionConfirmationVerb = "confirm-deletion"
+
+// CompositionValidatorOptions mirror operator settings that limit what compositions are allowed to do.
+type CompositionValidatorOptions struct {
+	// AllowSecretImports allows compositions to import Secrets with spec.import.
+	AllowSecretImports bool
+}
+
+func NewCompositionValidator(mgr ctrl.Manager, opts CompositionValidatorOptions) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&apiv1.Composition{}).
+		WithValidator(&compositionValidator{client: mgr.GetClient(), opts: opts, authorize: newSubjectAccessReviewer(mgr.GetClient())}).
+		Complete()
+}
+
+func newSubjectAccessReviewer(cli client.Client) func(context.Context, *authorizationv1.SubjectAccessReview) (bool, error) {
+	return func(ctx context.Context, sar *authorizationv1.SubjectAccessReview) (bool, error) {
+		if err := cli.Create(ctx, sar); err != nil {
+			return false, err
+		}
+		return sar.Status.Allowed, nil
+	}
+}
+
+func (v *compositionValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	comp := obj.(*apiv1.Composition)
+	if isConfirmedBeforeDeletion(comp) {
+		return nil, errors.NewForbidden(apiv1.SchemeGroupVersion.WithResource("compositions").GroupResource(), comp.Name, fmt.Errorf("deletion can't be confirmed before the composition is deleted"))
+	}
+	if err := v.validateQuota(ctx, comp); err != nil {
+		return nil, err
+	}
+	return v.validate(ctx, comp)
+}
+
+func (v *compositionValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	comp := newObj.(*apiv1.Composition)
+	if err := v.validateDeletionConfirmation(ctx, oldObj.(*apiv1.Composition), comp); err != nil {
+		return nil, err
+	}
+	if comp.DeletionTimestamp != nil {
+		return nil, nil // don't block finalizer removal
+	}
+	return v.validate(ctx, comp)
+}
+
+func (v *compositionValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateDeletionConfirmation keeps a single actor from bypassing deletion protection.
+// Deletion can only be confirmed after the composition has been deleted, and only by users authorized
+// for the confirm-deletion verb, who are also the only users allowed to disable deletion protection.
+func (v *compositionValidator) validateDeletionConfirmation(ctx context.Context, old, comp *apiv1.Composition) error {
+	gr := apiv1.SchemeGroupVersion.WithResource("compositions").GroupResource()
+	if isConfirmedBeforeDeletion(comp) {
+		return errors.NewForbidden(gr, comp.Name, fmt.Errorf("deletion can't be confirmed before the composition is deleted"))
+	}
+
+	confirming := comp.Spec.DeletionProtection && comp.Annotations[apiv1.DeletionConfirmedAnnotation] == "true" && old.Annotations[apiv1.DeletionConfirmedAnnotation] != "true"
+	unprotecting := old.Spec.DeletionProtection && !comp.Spec.DeletionProtection
+	if !confirming && !unprotecting {
+		return nil
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("getting admission request: %w", err)
+	}
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   req.UserInfo.Username,
+			UID:    req.UserInfo.UID,
+			Groups: req.UserInfo.Groups,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Group:     gr.Group,
+				Resource:  gr.Resource,
+				Verb:      DeletionConfirmationVerb,
+				Namespace: comp.Namespace,
+				Name:      comp.Name,
+			},
+		},
+	}
+	if len(req.UserInfo.Extra) > 0 {
+		sar.Spec.Extra = map[string]authorizationv1.ExtraValue{}
+		for key, val := range req.UserInfo.Extra {
+			sar.Spec.Extra[key] = authorizationv1.ExtraValue(val)
+		}
+	}
+
+	allowed, err := v.authorize(ctx, sar)
+	if err != nil {
+		return fmt.Errorf("reviewing subject access: %w", err)
+	}
+	if !allowed {
+		return errors.NewForbidden(gr, comp.Name, fmt.Errorf("user %q is not allowed to %s compositions", req.UserInfo.Username, DeletionConfirmationVerb))
+	}
+	return nil
+}
+
+// isConfirmedBeforeDeletion returns true when a protected composition that hasn't been deleted carries the deletion
+// confirmation annotation. This is rejected regardless of the previous state of the composition, since otherwise the
+// annotation could be set while protection is disabled and protection enabled afterwards, pre-confirming the deletion.
+func isConfirmedBeforeDeletion(comp *apiv1.Composition) bool {
+	return comp.DeletionTimestamp == nil && comp.Spec.DeletionProtection && comp.Annotations[apiv1.DeletionConfirmedAnnotation] == "true"
+}
+
+// validateQuota rejects new compositions once their namespace holds the max number of compositions allowed by any EnoQuota.
+func (v *compositionValidator) validateQuota(ctx context.Context, comp *apiv1.Composition) error {
+	quotas := &apiv1.EnoQuotaList{}
+	err := v.client.List(ctx, quotas, client.InNamespace(comp.Namespace))
+	if err != nil {
+		return fmt.Errorf("listing quotas: %w", err)
+	}
+
+	var limited []apiv1.EnoQuota
+	for _, quota := range quotas.Items {
+		if quota.Spec.MaxCompositions != nil {
+			limited = append(limited, quota)
+		}
+	}
+	if len(limited) == 0 {
+		return nil
+	}
+
+	list := &apiv1.CompositionList{}
+	err = v.client.List(ctx, list, client.InNamespace(comp.Namespace))
+	if err != nil {
+		return fmt.Errorf("listing compositions: %w", err)
+	}
+
+	// Compositions that are being deleted will free up their slot soon
+	var count int64
+	for _, item := range list.Items {
+		if item.DeletionTimestamp == nil {
+			count++
+		}
+	}
+	for _, quota := range limited {
+		if limit := *quota.Spec.MaxCompositions; count >= limit {
+			return errors.NewForbidden(apiv1.SchemeGroupVersion.WithResource("compositions").GroupResource(), comp.Name, fmt.Errorf("exceeded quota %q: max compositions %d", quota.Name, limit))
+		}
+	}
+	return nil
+}
+
+func (v *compositionValidator) validate(ctx context.Context, comp *apiv1.Composition) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	errs := validateCompositionAnnotations(comp)
+	if _, ok := comp.Annotations["eno.azure.io/deletion-strategy"]; ok {
+		warnings = append(warnings, "the eno.azure.io/deletion-strategy annotation is deprecated - use spec.deletionStrategy instead")
+	}
+
+	errs = append(errs, metav1validation.ValidateLabels(comp.Spec.CommonLabels, field.NewPath("spec", "commonLabels"))...)
+	errs = append(errs, apivalidation.ValidateAnnotations(comp.Spec.CommonAnnotations, field.NewPath("spec", "commonAnnotations"))...)
+
+	keys := map[string]struct{}{}
+	for i, binding := range comp.Spec.Bindings {
+		if _, ok := keys[binding.Key]; ok {
+			errs = append(errs, field.Duplicate(field.NewPath("spec", "bindings").Index(i).Child("key"), binding.Key))
+		}
+		keys[binding.Key] = struct{}{}
+	}
+	pools := map[string]struct{}{}
+	for i, req := range comp.Spec.Identifiers {
+		if _, ok := keys[req.Key]; ok {
+			errs = append(errs, field.Duplicate(field.NewPath("spec", "identifiers").Index(i).Child("key"), req.Key))
+		}
+		keys[req.Key] = struct{}{}
+
+		// Allocations are held per composition, so a second request would receive the same value
+		if _, ok := pools[req.Pool]; ok {
+			errs = append(errs, field.Duplicate(field.NewPath("spec", "identifiers").Index(i).Child("pool"), req.Pool))
+		}
+		pools[req.Pool] = struct{}{}
+	}
+	for i, up := range comp.Spec.Upstreams {
+		path := field.NewPath("spec", "upstreams").Index(i)
+		if _, ok := keys[up.Key]; ok {
+			errs = append(errs, field.Duplicate(path.Child("key"), up.Key))
+		}
+		keys[up.Key] = struct{}{}
+
+		cycle, err := v.findUpstreamCycle(ctx, comp, up.Composition)
+		if err != nil {
+			return nil, err
+		}
+		if cycle != nil {
+			errs = append(errs, field.Invalid(path.Child("composition"), up.Composition, fmt.Sprintf("upstreams would form a cycle: %s", strings.Join(cycle, " -> "))))
+		}
+	}
+
+	if imp := comp.Spec.Import; imp != nil {
+		path := field.NewPath("spec", "import")
+		if len(imp.Resources) == 0 {
+			errs = append(errs, field.Required(path.Child("resources"), "at least one resource type is required"))
+		}
+		errs = append(errs, metav1validation.ValidateLabelSelector(&imp.Selector, metav1validation.LabelSelectorValidationOptions{}, path.Child("selector"))...)
+		if imp.Namespace != "" && imp.Namespace != comp.Namespace {
+			errs = append(errs, field.Invalid(path.Child("namespace"), imp.Namespace, "resources can only be imported from the composition's namespace"))
+		}
+		if imp.ImportsSecrets() && !v.opts.AllowSecretImports {
+			errs = append(errs, field.Forbidden(path.Child("resources"), "importing secrets is not allowed by the operator"))
+		}
+	}
+
+	if comp.Passthrough() {
+		if comp.Spec.Manifests.Inline == "" && comp.Spec.Manifests.ConfigMap == "" {
+			errs = append(errs, field.Required(field.NewPath("spec", "manifests"), "inline manifests or a configmap is required"))
+		}
+		return warnings, invalid(comp, errs) // no synthesizer to validate against
+	}
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = comp.Spec.Synthesizer.Name
+	err := v.client.Get(ctx, client.ObjectKeyFromObject(syn), syn)
+	if errors.IsNotFound(err) {
+		warnings = append(warnings, fmt.Sprintf("synthesizer %q does not exist - the composition will not be synthesized until it is created", syn.Name))
+		return warnings, invalid(comp, errs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting synthesizer: %w", err)
+	}
+
+	// Unknown bindings are allowed for forwards compatibility with future synthesizer versions
+	refs := map[string]struct{}{}
+	for _, ref := range syn.Spec.Refs {
+		refs[ref.Key] = struct{}{}
+	}
+	for _, binding := range comp.Spec.Bindings {
+		if _, ok := refs[binding.Key]; !ok {
+			warnings = append(warnings, fmt.Sprintf("binding %q does not correspond to any ref of synthesizer %q", binding.Key, syn.Name))
+		}
+	}
+
+	return warnings, invalid(comp, errs)
+}
+
+// findUpstreamCycle returns the names of the compositions that would form a cycle if comp consumed the given upstream, or nil if there is no cycle.
+// Compositions in a cycle would resynthesize each other indefinitely.
+func (v *compositionValidator) findUpstreamCycle(ctx context.Context, comp *apiv1.Composition, upstream string) ([]string, error) {
+	visited := map[string]struct{}{}
+	var visit func(name string, path []string) ([]string, error)
+	visit = func(name string, path []string) ([]string, error) {
+		path = append(path, name)
+		if name == comp.Name {
+			return path, nil
+		}
+		if _, ok := visited[name]; ok {
+			return nil, nil
+		}
+		visited[name] = struct{}{}
+
+		next := &apiv1.Composition{}
+		err := v.client.Get(ctx, types.NamespacedName{Name: name, Namespace: comp.Namespace}, next)
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("getting upstream composition: %w", err)
+		}
+		for _, up := range next.Spec.Upstreams {
+			cycle, err := visit(up.Composition, path)
+			if cycle != nil || err != nil {
+				return cycle, err
+			}
+		}
+		return nil, nil
+	}
+	return visit(upstream, []string{comp.Name})
+}
+
+func invalid(comp *apiv1.Composition, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.NewInvalid(apiv1.SchemeGroupVersion.WithKind("Composition").GroupKind(), comp.Name, errs)
+}
+
+// validateCompositionAnnotations returns errors for annotations that Eno would otherwise ignore because they can't be parsed.
+func validateCompositionAnnotations(comp *apiv1.Composition) field.ErrorList {
+	var errs field.ErrorList
+	anno := comp.Annotations
+	path := field.NewPath("metadata", "annotations")
+
+	if val, ok := anno["eno.azure.io/deletion-strategy"]; ok && val != "orphan" {
+		errs = append(errs, field.NotSupported(path.Key("eno.azure.io/deletion-strategy"), val, []string{"orphan"}))
+	}
+
+	if val, ok := anno["eno.azure.io/synthesis-priority"]; ok {
+		if _, err := strconv.Atoi(val); err != nil {
+			errs = append(errs, field.Invalid(path.Key("eno.azure.io/synthesis-priority"), val, "must be an integer"))
+		}
+	}
+
+	if val, ok := anno[manager.LogLevelExpirationAnnotation]; ok {
+		if _, err := time.Parse(time.RFC3339, val); err != nil {
+			errs = append(errs, field.Invalid(path.Key(manager.LogLevelExpirationAnnotation), val, "must be an RFC3339 timestamp"))
+		}
+	}
+
+	if val, ok := anno[freeze.Annotation]; ok {
+		if _, err := freeze.Parse(val); err != nil {
+			errs = append(errs, field.Invalid(path.Key(freeze.Annotation), val, err.Error()))
+		}
+	}
+
+	if val, ok := anno[execution.UnknownFieldsAnnotation]; ok && val != execution.UnknownFieldsWarn && val != execution.UnknownFieldsError && val != execution.UnknownFieldsPrune {
+		errs = append(errs, field.NotSupported(path.Key(execution.UnknownFieldsAnnotation), val, []string{execution.UnknownFieldsWarn, execution.UnknownFieldsError, execution.UnknownFieldsPrune}))
+	}
+
+	if val, ok := anno[apiv1.ConcurrentEditGracePeriodAnnotation]; ok {
+		if d, err := time.ParseDuration(val); err != nil || d < 0 {
+			errs = append(errs, field.Invalid(path.Key(apiv1.ConcurrentEditGracePeriodAnnotation), val, "must be a non-negative duration"))
+		}
+	}
+
+	for _, key := range []string{"eno.azure.io/ignore-side-effects", apiv1.DeletionConfirmedAnnotation, apiv1.StrictSequencingAnnotation, apiv1.ConcurrentEditWarningsAnnotation} {
+		if val, ok := anno[key]; ok {
+			if _, err := strconv.ParseBool(val); err != nil {
+				errs = append(errs, field.Invalid(path.Key(key), val, "must be a boolean"))
+			}
+		}
+	}
+
+	return errs
+}
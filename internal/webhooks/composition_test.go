@@ -0,0 +1,440 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/testutil"
+)
+
+func TestCompositionValidator(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+	v := &compositionValidator{client: cli}
+
+	syn := &apiv1.Synthesizer{}
+	syn.Name = "test-synth"
+	syn.Spec.Refs = []apiv1.Ref{{Key: "foo"}}
+	require.NoError(t, cli.Create(ctx, syn))
+
+	upstream := &apiv1.Composition{}
+	upstream.Name = "test-upstream"
+	upstream.Spec.Upstreams = []apiv1.UpstreamInput{{Key: "down", Composition: "test-downstream"}}
+	require.NoError(t, cli.Create(ctx, upstream))
+
+	tests := []struct {
+		Name        string
+		Composition apiv1.Composition
+		Warnings    int
+		Invalid     bool
+	}{
+		{
+			Name: "valid",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"eno.azure.io/synthesis-priority": "10"}},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Bindings: []apiv1.Binding{{Key: "foo"}}, DeletionStrategy: apiv1.DeletionStrategyOrphan},
+			},
+		},
+		{
+			Name: "deprecated deletion strategy annotation",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"eno.azure.io/deletion-strategy": "orphan"}},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}},
+			},
+			Warnings: 1,
+		},
+		{
+			Name:        "missing synthesizer",
+			Composition: apiv1.Composition{Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "nope"}}},
+			Warnings:    1,
+		},
+		{
+			Name: "unknown binding",
+			Composition: apiv1.Composition{
+				Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Bindings: []apiv1.Binding{{Key: "bar"}}},
+			},
+			Warnings: 1,
+		},
+		{
+			Name: "duplicate binding",
+			Composition: apiv1.Composition{
+				Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Bindings: []apiv1.Binding{{Key: "foo"}, {Key: "foo"}}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "identifier key conflicts with binding",
+			Composition: apiv1.Composition{
+				Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Bindings: []apiv1.Binding{{Key: "foo"}}, Identifiers: []apiv1.IdentifierRequest{{Key: "foo", Pool: "test-pool"}}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "duplicate identifier pool",
+			Composition: apiv1.Composition{
+				Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Identifiers: []apiv1.IdentifierRequest{{Key: "foo", Pool: "test-pool"}, {Key: "bar", Pool: "test-pool"}}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "upstream",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Name: "downstream"},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Upstreams: []apiv1.UpstreamInput{{Key: "up", Composition: "upstream"}}},
+			},
+		},
+		{
+			Name: "upstream key conflicts with binding",
+			Composition: apiv1.Composition{
+				Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Bindings: []apiv1.Binding{{Key: "foo"}}, Upstreams: []apiv1.UpstreamInput{{Key: "foo", Composition: "upstream"}}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "upstream is the composition itself",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-comp"},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Upstreams: []apiv1.UpstreamInput{{Key: "up", Composition: "test-comp"}}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "upstream cycle",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-downstream"},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Upstreams: []apiv1.UpstreamInput{{Key: "up", Composition: upstream.Name}}},
+			},
+			Invalid: true,
+		},
+		{
+			Name:        "passthrough",
+			Composition: apiv1.Composition{Spec: apiv1.CompositionSpec{Manifests: &apiv1.ManifestSource{ConfigMap: "test-manifests"}}},
+		},
+		{
+			Name:        "passthrough without manifests",
+			Composition: apiv1.Composition{Spec: apiv1.CompositionSpec{Manifests: &apiv1.ManifestSource{}}},
+			Invalid:     true,
+		},
+		{
+			Name: "import",
+			Composition: apiv1.Composition{
+				Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Import: &apiv1.ImportSpec{
+					Resources: []apiv1.ResourceRef{{Version: "v1", Kind: "ConfigMap"}},
+					Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				}},
+			},
+		},
+		{
+			Name: "import from another namespace",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+				Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Import: &apiv1.ImportSpec{
+					Resources: []apiv1.ResourceRef{{Version: "v1", Kind: "ConfigMap"}},
+					Namespace: "kube-system",
+					Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "import from the composition's namespace",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+				Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Import: &apiv1.ImportSpec{
+					Resources: []apiv1.ResourceRef{{Version: "v1", Kind: "ConfigMap"}},
+					Namespace: "default",
+					Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				}},
+			},
+		},
+		{
+			Name: "import secrets",
+			Composition: apiv1.Composition{
+				Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Import: &apiv1.ImportSpec{
+					Resources: []apiv1.ResourceRef{{Version: "v1", Kind: "Secret"}},
+					Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "import without resources",
+			Composition: apiv1.Composition{
+				Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, Import: &apiv1.ImportSpec{
+					Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "invalid deletion strategy",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"eno.azure.io/deletion-strategy": "orphaned"}},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}},
+			},
+			Warnings: 1,
+			Invalid:  true,
+		},
+		{
+			Name: "invalid priority",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"eno.azure.io/synthesis-priority": "high"}},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "invalid log level expiration",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"eno.azure.io/log-level-expiration": "tomorrow"}},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "invalid freeze window",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"eno.azure.io/freeze-window": "weekends"}},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "invalid common labels",
+			Composition: apiv1.Composition{
+				Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, CommonLabels: map[string]string{"team": "not a valid value"}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "invalid common annotations",
+			Composition: apiv1.Composition{
+				Spec: apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}, CommonAnnotations: map[string]string{"not/a/valid/key": "foo"}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "invalid strict sequencing",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"eno.azure.io/strict-sequencing": "always"}},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "invalid concurrent edit grace period",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"eno.azure.io/concurrent-edit-grace-period": "forever"}},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}},
+			},
+			Invalid: true,
+		},
+		{
+			Name: "valid concurrent edit settings",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"eno.azure.io/concurrent-edit-grace-period": "15m", "eno.azure.io/concurrent-edit-warnings": "true"}},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}},
+			},
+		},
+		{
+			Name: "invalid unknown fields mode",
+			Composition: apiv1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"eno.azure.io/unknown-fields": "ignore"}},
+				Spec:       apiv1.CompositionSpec{Synthesizer: apiv1.SynthesizerRef{Name: "test-synth"}},
+			},
+			Invalid: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			warnings, err := v.ValidateCreate(ctx, &tc.Composition)
+			assert.Len(t, warnings, tc.Warnings)
+			if tc.Invalid {
+				assert.True(t, errors.IsInvalid(err), "expected invalid error, got: %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCompositionValidatorAllowSecretImports(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	v := &compositionValidator{client: testutil.NewClient(t), opts: CompositionValidatorOptions{AllowSecretImports: true}}
+
+	comp := &apiv1.Composition{}
+	comp.Spec.Synthesizer.Name = "test-synth"
+	comp.Spec.Import = &apiv1.ImportSpec{
+		Resources: []apiv1.ResourceRef{{Version: "v1", Kind: "Secret"}},
+		Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+	}
+	_, err := v.ValidateCreate(ctx, comp)
+	assert.NoError(t, err)
+}
+
+func TestCompositionValidatorDeleting(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	v := &compositionValidator{client: testutil.NewClient(t)}
+
+	comp := &apiv1.Composition{}
+	comp.DeletionTimestamp = &metav1.Time{}
+	comp.Annotations = map[string]string{"eno.azure.io/synthesis-priority": "high"}
+	warnings, err := v.ValidateUpdate(ctx, comp, comp)
+	assert.Empty(t, warnings)
+	assert.NoError(t, err)
+}
+
+func TestCompositionValidatorDeletionConfirmation(t *testing.T) {
+	ctx := admission.NewContextWithRequest(testutil.NewContext(t), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Username: "approver", Groups: []string{"deletion-approvers"}}},
+	})
+
+	var reviews []*authorizationv1.SubjectAccessReview
+	allowed := false
+	v := &compositionValidator{client: testutil.NewClient(t), authorize: func(ctx context.Context, sar *authorizationv1.SubjectAccessReview) (bool, error) {
+		reviews = append(reviews, sar)
+		return allowed, nil
+	}}
+
+	old := &apiv1.Composition{}
+	old.Name = "test-comp"
+	old.Namespace = "default"
+	old.Spec.Synthesizer.Name = "test-synth"
+	old.Spec.DeletionProtection = true
+
+	// Can't be confirmed on creation
+	confirmed := old.DeepCopy()
+	confirmed.Annotations = map[string]string{apiv1.DeletionConfirmedAnnotation: "true"}
+	_, err := v.ValidateCreate(ctx, confirmed)
+	assert.True(t, errors.IsForbidden(err))
+
+	// Can't be confirmed before deletion, even by approvers
+	allowed = true
+	_, err = v.ValidateUpdate(ctx, old, confirmed)
+	assert.True(t, errors.IsForbidden(err))
+	assert.Empty(t, reviews)
+
+	// Unauthorized users can't confirm deletion
+	allowed = false
+	old.DeletionTimestamp = &metav1.Time{}
+	confirmed.DeletionTimestamp = old.DeletionTimestamp
+	_, err = v.ValidateUpdate(ctx, old, confirmed)
+	assert.True(t, errors.IsForbidden(err))
+	require.Len(t, reviews, 1)
+	assert.Equal(t, "approver", reviews[0].Spec.User)
+	assert.Equal(t, []string{"deletion-approvers"}, reviews[0].Spec.Groups)
+	assert.Equal(t, &authorizationv1.ResourceAttributes{Group: "eno.azure.io", Resource: "compositions", Verb: DeletionConfirmationVerb, Namespace: "default", Name: "test-comp"}, reviews[0].Spec.ResourceAttributes)
+
+	// Unauthorized users can't disable protection
+	unprotected := old.DeepCopy()
+	unprotected.Spec.DeletionProtection = false
+	_, err = v.ValidateUpdate(ctx, old, unprotected)
+	assert.True(t, errors.IsForbidden(err))
+
+	// Authorized users can confirm deletion
+	allowed = true
+	_, err = v.ValidateUpdate(ctx, old, confirmed)
+	assert.NoError(t, err)
+
+	// Unrelated updates aren't reviewed
+	reviews = nil
+	_, err = v.ValidateUpdate(ctx, confirmed, confirmed)
+	assert.NoError(t, err)
+	assert.Empty(t, reviews)
+}
+
+func TestCompositionValidatorDeletionPreconfirmation(t *testing.T) {
+	ctx := admission.NewContextWithRequest(testutil.NewContext(t), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Username: "requester"}},
+	})
+	v := &compositionValidator{client: testutil.NewClient(t), authorize: func(ctx context.Context, sar *authorizationv1.SubjectAccessReview) (bool, error) {
+		return false, nil
+	}}
+
+	// The annotation can be set while the composition isn't protected
+	unprotected := &apiv1.Composition{}
+	unprotected.Name = "test-comp"
+	unprotected.Namespace = "default"
+	unprotected.Spec.Synthesizer.Name = "test-synth"
+	unprotected.Annotations = map[string]string{apiv1.DeletionConfirmedAnnotation: "true"}
+	_, err := v.ValidateCreate(ctx, unprotected)
+	require.NoError(t, err)
+
+	// ...but protection can't be enabled afterwards without removing it
+	protected := unprotected.DeepCopy()
+	protected.Spec.DeletionProtection = true
+	_, err = v.ValidateUpdate(ctx, unprotected, protected)
+	assert.True(t, errors.IsForbidden(err))
+
+	// Nor can other fields of a composition that was already pre-confirmed be updated
+	_, err = v.ValidateUpdate(ctx, protected, protected)
+	assert.True(t, errors.IsForbidden(err))
+
+	// Removing the annotation is allowed
+	cleared := protected.DeepCopy()
+	cleared.Annotations = nil
+	_, err = v.ValidateUpdate(ctx, protected, cleared)
+	assert.NoError(t, err)
+}
+
+func TestCompositionValidatorQuota(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	cli := testutil.NewClient(t)
+	v := &compositionValidator{client: cli}
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.Spec.Synthesizer.Name = "test-synth"
+	require.NoError(t, cli.Create(ctx, comp))
+
+	// Quotas without a composition limit don't block creation
+	quota := &apiv1.EnoQuota{}
+	quota.Name = "test-quota"
+	quota.Namespace = comp.Namespace
+	quota.Spec.MaxResources = ptr.To(int64(10))
+	require.NoError(t, cli.Create(ctx, quota))
+
+	next := comp.DeepCopy()
+	next.Name = "next-comp"
+	next.ResourceVersion = ""
+	_, err := v.ValidateCreate(ctx, next)
+	assert.NoError(t, err)
+
+	// Limit reached
+	quota.Spec.MaxCompositions = ptr.To(int64(1))
+	require.NoError(t, cli.Update(ctx, quota))
+
+	_, err = v.ValidateCreate(ctx, next)
+	assert.True(t, errors.IsForbidden(err))
+
+	// Other namespaces aren't limited
+	next.Namespace = "other"
+	_, err = v.ValidateCreate(ctx, next)
+	assert.NoError(t, err)
+
+	// Updates aren't limited
+	_, err = v.ValidateUpdate(ctx, comp, comp)
+	assert.NoError(t, err)
+
+	// Compositions being deleted don't count
+	comp.Finalizers = []string{"eno.azure.io/cleanup"}
+	require.NoError(t, cli.Update(ctx, comp))
+	require.NoError(t, cli.Delete(ctx, comp))
+
+	next.Namespace = comp.Namespace
+	_, err = v.ValidateCreate(ctx, next)
+	assert.NoError(t, err)
+}
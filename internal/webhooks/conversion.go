@@ -0,0 +1,12 @@
+package webhooks
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+// NewConversionWebhook serves CRD conversion requests for every API version registered in the manager's scheme.
+// It must be registered before any other webhooks, since controller-runtime also registers this path for convertible types.
+func NewConversionWebhook(mgr ctrl.Manager) {
+	mgr.GetWebhookServer().Register("/convert", conversion.NewWebhookHandler(mgr.GetScheme()))
+}
@@ -0,0 +1,102 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+// DefaultingPolicy holds platform-wide defaults applied to compositions and synthesizers at admission.
+// Only unset properties are defaulted, so per-object specs always take precedence.
+//
+// Note that synthesizer timeouts are defaulted by the CRD schema before admission, so they can't be set here.
+type DefaultingPolicy struct {
+	// ReconcileInterval is used for synthesizers that don't specify one.
+	ReconcileInterval *metav1.Duration `json:"reconcileInterval,omitempty"`
+
+	// PodResources is used for synthesizers that don't specify any resource requests or limits in their pod overrides.
+	PodResources *corev1.ResourceRequirements `json:"podResources,omitempty"`
+
+	// SynthesisEnv is added to every composition, except for variables that the composition already sets.
+	SynthesisEnv []apiv1.EnvVar `json:"synthesisEnv,omitempty"`
+}
+
+// LoadDefaultingPolicy reads a yaml or json encoded DefaultingPolicy from the given file.
+func LoadDefaultingPolicy(path string) (*DefaultingPolicy, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading defaulting policy: %w", err)
+	}
+	policy := &DefaultingPolicy{}
+	if err := yaml.UnmarshalStrict(raw, policy); err != nil {
+		return nil, fmt.Errorf("parsing defaulting policy: %w", err)
+	}
+	return policy, nil
+}
+
+// NewDefaulters registers mutating webhooks that apply the given policy to compositions and synthesizers.
+func NewDefaulters(mgr ctrl.Manager, policy *DefaultingPolicy) error {
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&apiv1.Composition{}).
+		WithDefaulter(&compositionDefaulter{policy: policy}).
+		Complete()
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&apiv1.Synthesizer{}).
+		WithDefaulter(&synthesizerDefaulter{policy: policy}).
+		Complete()
+}
+
+type compositionDefaulter struct {
+	policy *DefaultingPolicy
+}
+
+func (d *compositionDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	comp := obj.(*apiv1.Composition)
+	if comp.DeletionTimestamp != nil {
+		return nil
+	}
+
+	existing := map[string]struct{}{}
+	for _, env := range comp.Spec.SynthesisEnv {
+		existing[env.Name] = struct{}{}
+	}
+	for _, env := range d.policy.SynthesisEnv {
+		if _, ok := existing[env.Name]; !ok {
+			comp.Spec.SynthesisEnv = append(comp.Spec.SynthesisEnv, env)
+		}
+	}
+	return nil
+}
+
+type synthesizerDefaulter struct {
+	policy *DefaultingPolicy
+}
+
+func (d *synthesizerDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	syn := obj.(*apiv1.Synthesizer)
+	if syn.DeletionTimestamp != nil {
+		return nil
+	}
+
+	if syn.Spec.ReconcileInterval == nil && d.policy.ReconcileInterval != nil {
+		syn.Spec.ReconcileInterval = &metav1.Duration{Duration: d.policy.ReconcileInterval.Duration}
+	}
+
+	res := &syn.Spec.PodOverrides.Resources
+	if len(res.Requests) == 0 && len(res.Limits) == 0 && d.policy.PodResources != nil {
+		d.policy.PodResources.DeepCopyInto(res)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package webhooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/testutil"
+)
+
+func TestLoadDefaultingPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+reconcileInterval: 5m
+podResources:
+  requests:
+    cpu: 100m
+synthesisEnv:
+  - name: REGION
+    value: westus
+`), 0644))
+
+	policy, err := LoadDefaultingPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute*5, policy.ReconcileInterval.Duration)
+	assert.Equal(t, "100m", policy.PodResources.Requests.Cpu().String())
+	assert.Equal(t, []apiv1.EnvVar{{Name: "REGION", Value: "westus"}}, policy.SynthesisEnv)
+
+	// Unknown fields are rejected to catch typos
+	require.NoError(t, os.WriteFile(path, []byte(`reconcileIntervall: 5m`), 0644))
+	_, err = LoadDefaultingPolicy(path)
+	assert.Error(t, err)
+}
+
+func TestCompositionDefaulter(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	d := &compositionDefaulter{policy: &DefaultingPolicy{SynthesisEnv: []apiv1.EnvVar{{Name: "REGION", Value: "westus"}, {Name: "TIER", Value: "prod"}}}}
+
+	comp := &apiv1.Composition{}
+	comp.Spec.SynthesisEnv = []apiv1.EnvVar{{Name: "TIER", Value: "dev"}}
+	require.NoError(t, d.Default(ctx, comp))
+	assert.Equal(t, []apiv1.EnvVar{{Name: "TIER", Value: "dev"}, {Name: "REGION", Value: "westus"}}, comp.Spec.SynthesisEnv)
+
+	// Idempotent
+	require.NoError(t, d.Default(ctx, comp))
+	assert.Len(t, comp.Spec.SynthesisEnv, 2)
+}
+
+func TestSynthesizerDefaulter(t *testing.T) {
+	ctx := testutil.NewContext(t)
+	d := &synthesizerDefaulter{policy: &DefaultingPolicy{
+		ReconcileInterval: &metav1.Duration{Duration: time.Minute},
+		PodResources:      &corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}},
+	}}
+
+	syn := &apiv1.Synthesizer{}
+	require.NoError(t, d.Default(ctx, syn))
+	assert.Equal(t, time.Minute, syn.Spec.ReconcileInterval.Duration)
+	assert.Equal(t, "100m", syn.Spec.PodOverrides.Resources.Requests.Cpu().String())
+
+	// Explicit values are retained
+	syn = &apiv1.Synthesizer{}
+	syn.Spec.ReconcileInterval = &metav1.Duration{Duration: time.Hour}
+	syn.Spec.PodOverrides.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}
+	require.NoError(t, d.Default(ctx, syn))
+	assert.Equal(t, time.Hour, syn.Spec.ReconcileInterval.Duration)
+	assert.Empty(t, syn.Spec.PodOverrides.Resources.Requests)
+}
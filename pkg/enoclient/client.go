@@ -0,0 +1,172 @@
+// Package enoclient is a Go client for Eno's API, for controllers and tools that integrate with Eno.
+//
+// The client is a controller-runtime client that understands Eno's types, along with helpers for common
+// operations like waiting for a composition to become ready, requesting resynthesis, and comparing syntheses.
+package enoclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/diff"
+)
+
+// DefaultPollInterval is how often WaitForReady checks the composition's status unless overridden by Client.PollInterval.
+const DefaultPollInterval = time.Second * 2
+
+// ErrSynthesisFailed is returned by WaitForReady when the composition's current synthesis has failed.
+var ErrSynthesisFailed = errors.New("synthesis failed")
+
+// Change is a resource that differs between two syntheses. See GetDiff.
+type Change = diff.Change
+
+// ChangeType describes how a resource differs between two syntheses.
+type ChangeType = diff.ChangeType
+
+const (
+	ChangeAdded    = diff.ChangeAdded
+	ChangeRemoved  = diff.ChangeRemoved
+	ChangeModified = diff.ChangeModified
+)
+
+// Client reads and writes Eno's resources.
+type Client struct {
+	client.Client
+
+	// PollInterval is how often WaitForReady checks the composition's status. Defaults to DefaultPollInterval.
+	PollInterval time.Duration
+}
+
+// NewScheme returns a scheme that includes Eno's types.
+func NewScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := apiv1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+// New returns a client for the apiserver that holds Eno's resources.
+func New(rc *rest.Config) (*Client, error) {
+	scheme, err := NewScheme()
+	if err != nil {
+		return nil, err
+	}
+	cli, err := client.New(rc, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Client: cli}, nil
+}
+
+// NewForClient wraps an existing client e.g. a controller-runtime manager's client. Its scheme must include Eno's types.
+func NewForClient(cli client.Client) *Client {
+	return &Client{Client: cli}
+}
+
+// IsReady returns true when the composition's current synthesis reflects its latest spec, no resynthesis is pending,
+// and every resource of the synthesis is ready.
+func IsReady(comp *apiv1.Composition) bool {
+	syn := comp.Status.CurrentSynthesis
+	return syn != nil && syn.Ready != nil && syn.ObservedCompositionGeneration == comp.Generation && comp.Status.PendingResynthesis == nil && comp.DeletionTimestamp == nil
+}
+
+// WaitForReady blocks until the composition is ready (see IsReady) and returns it.
+// ErrSynthesisFailed is returned if the current synthesis of the latest spec fails.
+// Use the context to limit how long to wait.
+func (c *Client) WaitForReady(ctx context.Context, nsn types.NamespacedName) (*apiv1.Composition, error) {
+	interval := c.PollInterval
+	if interval == 0 {
+		interval = DefaultPollInterval
+	}
+
+	comp := &apiv1.Composition{}
+	err := wait.PollUntilContextCancel(ctx, interval, true, func(ctx context.Context) (bool, error) {
+		if err := c.Get(ctx, nsn, comp); err != nil {
+			return false, fmt.Errorf("getting composition: %w", err)
+		}
+		syn := comp.Status.CurrentSynthesis
+		if syn != nil && syn.ObservedCompositionGeneration == comp.Generation && syn.Failed() {
+			return false, fmt.Errorf("%w: %s", ErrSynthesisFailed, resultMessages(syn))
+		}
+		return IsReady(comp), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comp, nil
+}
+
+func resultMessages(syn *apiv1.Synthesis) string {
+	var msgs []string
+	for _, result := range syn.Results {
+		if result.Severity == "error" {
+			msgs = append(msgs, result.Message)
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// TriggerResynthesis requests that the composition be resynthesized, even if its spec and inputs haven't changed.
+// The reason is recorded in the resulting synthesis and defaults to "Manual".
+func (c *Client) TriggerResynthesis(ctx context.Context, nsn types.NamespacedName, reason string) error {
+	if reason == "" {
+		reason = apiv1.SynthesisReasonManual
+	}
+
+	comp := &apiv1.Composition{}
+	if err := c.Get(ctx, nsn, comp); err != nil {
+		return fmt.Errorf("getting composition: %w", err)
+	}
+	patch := client.MergeFrom(comp.DeepCopy())
+	now := metav1.Now()
+	comp.Status.PendingResynthesis = &now
+	comp.Status.PendingResynthesisReason = reason
+	if err := c.Status().Patch(ctx, comp, patch); err != nil {
+		return fmt.Errorf("updating composition status: %w", err)
+	}
+	return nil
+}
+
+// GetDiff returns the resources that changed between two syntheses of the composition, sorted by resource.
+// Empty UUIDs default to the previous (from) and current (to) syntheses.
+// Syntheses other than the current and previous syntheses can only be compared until their resource slices are cleaned up.
+func (c *Client) GetDiff(ctx context.Context, nsn types.NamespacedName, from, to string) ([]*Change, error) {
+	comp := &apiv1.Composition{}
+	if err := c.Get(ctx, nsn, comp); err != nil {
+		return nil, fmt.Errorf("getting composition: %w", err)
+	}
+	if from == "" {
+		if comp.Status.PreviousSynthesis == nil {
+			return nil, errors.New("composition does not have a previous synthesis")
+		}
+		from = comp.Status.PreviousSynthesis.UUID
+	}
+	if to == "" {
+		if comp.Status.CurrentSynthesis == nil {
+			return nil, errors.New("composition has not been synthesized")
+		}
+		to = comp.Status.CurrentSynthesis.UUID
+	}
+
+	fromSyn, err := diff.Load(ctx, c, comp, from)
+	if err != nil {
+		return nil, fmt.Errorf("loading synthesis %q: %w", from, err)
+	}
+	toSyn, err := diff.Load(ctx, c, comp, to)
+	if err != nil {
+		return nil, fmt.Errorf("loading synthesis %q: %w", to, err)
+	}
+	return diff.Compare(fromSyn, toSyn), nil
+}
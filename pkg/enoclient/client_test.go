@@ -0,0 +1,135 @@
+package enoclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+)
+
+func newTestClient(t *testing.T, objs ...client.Object) *Client {
+	scheme, err := NewScheme()
+	require.NoError(t, err)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(&apiv1.Composition{}).Build()
+	c := NewForClient(cli)
+	c.PollInterval = time.Millisecond
+	return c
+}
+
+func newTestComposition() *apiv1.Composition {
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	comp.UID = "test-uid"
+	comp.Generation = 2
+	return comp
+}
+
+func TestIsReady(t *testing.T) {
+	comp := newTestComposition()
+	assert.False(t, IsReady(comp))
+
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{ObservedCompositionGeneration: 1, Ready: &metav1.Time{}}
+	assert.False(t, IsReady(comp), "stale generation")
+
+	comp.Status.CurrentSynthesis.ObservedCompositionGeneration = 2
+	assert.True(t, IsReady(comp))
+
+	comp.Status.PendingResynthesis = &metav1.Time{}
+	assert.False(t, IsReady(comp), "pending resynthesis")
+}
+
+func TestWaitForReady(t *testing.T) {
+	ctx := context.Background()
+	comp := newTestComposition()
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{ObservedCompositionGeneration: 2}
+	c := newTestClient(t, comp)
+	nsn := types.NamespacedName{Name: comp.Name, Namespace: comp.Namespace}
+
+	// Not ready yet
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Millisecond*20)
+	defer cancel()
+	_, err := c.WaitForReady(timeoutCtx, nsn)
+	assert.Error(t, err)
+
+	// Ready
+	comp.Status.CurrentSynthesis.Ready = ptrNow()
+	require.NoError(t, c.Status().Update(ctx, comp))
+	ready, err := c.WaitForReady(ctx, nsn)
+	require.NoError(t, err)
+	assert.NotNil(t, ready.Status.CurrentSynthesis.Ready)
+
+	// Failed
+	comp.Status.CurrentSynthesis.Ready = nil
+	comp.Status.CurrentSynthesis.Results = []apiv1.Result{{Severity: "error", Message: "boom"}}
+	require.NoError(t, c.Status().Update(ctx, comp))
+	_, err = c.WaitForReady(ctx, nsn)
+	assert.True(t, errors.Is(err, ErrSynthesisFailed))
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestTriggerResynthesis(t *testing.T) {
+	ctx := context.Background()
+	comp := newTestComposition()
+	c := newTestClient(t, comp)
+	nsn := types.NamespacedName{Name: comp.Name, Namespace: comp.Namespace}
+
+	require.NoError(t, c.TriggerResynthesis(ctx, nsn, ""))
+	require.NoError(t, c.Get(ctx, nsn, comp))
+	assert.NotNil(t, comp.Status.PendingResynthesis)
+	assert.Equal(t, apiv1.SynthesisReasonManual, comp.Status.PendingResynthesisReason)
+
+	require.NoError(t, c.TriggerResynthesis(ctx, nsn, "Nightly"))
+	require.NoError(t, c.Get(ctx, nsn, comp))
+	assert.Equal(t, "Nightly", comp.Status.PendingResynthesisReason)
+
+	assert.Error(t, c.TriggerResynthesis(ctx, types.NamespacedName{Name: "missing", Namespace: "default"}, ""))
+}
+
+func TestGetDiff(t *testing.T) {
+	ctx := context.Background()
+	comp := newTestComposition()
+	comp.Status.PreviousSynthesis = &apiv1.Synthesis{UUID: "previous", Synthesized: ptrNow(), ResourceSlices: []*apiv1.ResourceSliceRef{{Name: "previous-slice"}}}
+	comp.Status.CurrentSynthesis = &apiv1.Synthesis{UUID: "current", Synthesized: ptrNow(), ResourceSlices: []*apiv1.ResourceSliceRef{{Name: "current-slice"}}}
+
+	newSlice := func(name, uuid, value string) *apiv1.ResourceSlice {
+		slice := &apiv1.ResourceSlice{}
+		slice.Name = name
+		slice.Namespace = comp.Namespace
+		slice.OwnerReferences = []metav1.OwnerReference{{Kind: "Composition", Name: comp.Name, UID: comp.UID}}
+		slice.Spec.SynthesisUUID = uuid
+		slice.Spec.Resources = []apiv1.Manifest{{
+			Manifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo","namespace":"default"},"data":{"foo":"` + value + `"}}`,
+		}}
+		return slice
+	}
+	c := newTestClient(t, comp, newSlice("previous-slice", "previous", "bar"), newSlice("current-slice", "current", "baz"))
+	nsn := types.NamespacedName{Name: comp.Name, Namespace: comp.Namespace}
+
+	changes, err := c.GetDiff(ctx, nsn, "", "")
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, ChangeModified, changes[0].Type)
+	assert.Contains(t, changes[0].Diff, "+  foo: baz\n")
+
+	changes, err = c.GetDiff(ctx, nsn, "current", "current")
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	_, err = c.GetDiff(ctx, nsn, "missing", "")
+	assert.Error(t, err)
+}
+
+func ptrNow() *metav1.Time {
+	now := metav1.Now()
+	return &now
+}
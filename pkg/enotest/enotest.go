@@ -0,0 +1,199 @@
+// Package enotest runs Eno against a local envtest control plane, such that synthesizers and compositions
+// can be integration tested without a real cluster.
+//
+// Synthesizer pods are never actually created: they're replaced by an in-process function
+// that is called with the same inputs the synthesizer's container would receive.
+// The envtest binaries must be available, see https://book.kubebuilder.io/reference/envtest.
+package enotest
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	goruntime "runtime"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/controllers/aggregation"
+	synthflow "github.com/Azure/eno/internal/controllers/flowcontrol"
+	"github.com/Azure/eno/internal/controllers/reconciliation"
+	"github.com/Azure/eno/internal/controllers/rollout"
+	"github.com/Azure/eno/internal/controllers/synthesis"
+	"github.com/Azure/eno/internal/controllers/watch"
+	"github.com/Azure/eno/internal/flowcontrol"
+	"github.com/Azure/eno/internal/manager"
+	"github.com/Azure/eno/internal/reconstitution"
+	"github.com/Azure/eno/pkg/faults"
+)
+
+// Option configures an Environment.
+type Option func(*options)
+
+type options struct {
+	crdPaths  []string
+	schemes   []func(*runtime.Scheme) error
+	namespace string
+	faults    faults.Injector
+}
+
+// WithCRDDirectoryPaths installs the CRDs in the given directories before starting Eno.
+func WithCRDDirectoryPaths(paths ...string) Option {
+	return func(o *options) { o.crdPaths = append(o.crdPaths, paths...) }
+}
+
+// WithScheme registers types with the scheme of Environment.Client.
+func WithScheme(fn func(*runtime.Scheme) error) Option {
+	return func(o *options) { o.schemes = append(o.schemes, fn) }
+}
+
+// WithNamespace sets the namespace used for synthesizer pods. Defaults to "default".
+func WithNamespace(ns string) Option {
+	return func(o *options) { o.namespace = ns }
+}
+
+// WithFaults injects faults into the reconciler, such that tests can prove convergence under adverse conditions.
+func WithFaults(inj faults.Injector) Option {
+	return func(o *options) { o.faults = inj }
+}
+
+// Environment is a running control plane with every Eno controller required to synthesize and reconcile compositions.
+// The control plane acts as both the upstream (Eno resources) and downstream (synthesized resources) cluster.
+type Environment struct {
+	Client     client.Client
+	RestConfig *rest.Config
+}
+
+// NewEnvironment starts a control plane and Eno controllers, using fn in place of every synthesizer.
+// Everything is stopped when the test completes.
+func NewEnvironment(t *testing.T, fn Synthesizer, opts ...Option) *Environment {
+	o := &options{namespace: "default"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	_, b, _, _ := goruntime.Caller(0)
+	root := filepath.Join(filepath.Dir(b), "..", "..")
+	env := &envtest.Environment{
+		CRDDirectoryPaths:     append([]string{filepath.Join(root, "api", "v1", "config", "crd")}, o.crdPaths...),
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := env.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := env.Stop(); err != nil {
+			t.Logf("error while stopping test environment: %s", err)
+		}
+	})
+
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 1})
+	mgr, err := manager.NewTest(logger, &manager.Options{
+		Rest:                    cfg,
+		HealthProbeAddr:         "127.0.0.1:0",
+		MetricsAddr:             "127.0.0.1:0",
+		SynthesizerPodNamespace: o.namespace,
+		CompositionSelector:     labels.Everything(),
+	})
+	require.NoError(t, err)
+	for _, fn := range o.schemes {
+		require.NoError(t, fn(mgr.GetScheme()))
+	}
+
+	require.NoError(t, registerControllers(mgr, cfg, o))
+	require.NoError(t, NewFakeExecutor(mgr, fn))
+
+	ctx, cancel := context.WithCancel(logr.NewContext(context.Background(), logger))
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	go func() {
+		defer close(done)
+		if err := mgr.Start(ctx); err != nil {
+			panic(fmt.Sprintf("error while starting manager: %s", err))
+		}
+	}()
+	mgr.GetCache().WaitForCacheSync(ctx)
+
+	return &Environment{Client: mgr.GetClient(), RestConfig: cfg}
+}
+
+func registerControllers(mgr ctrl.Manager, cfg *rest.Config, o *options) error {
+	conf := &synthesis.Config{
+		SliceCreationQPS: 20,
+		PodNamespace:     o.namespace,
+		ExecutorImage:    "enotest",
+	}
+	for _, fn := range []func() error{
+		func() error { return synthesis.NewPodLifecycleController(mgr, conf) },
+		func() error { return synthesis.NewSliceCleanupController(mgr) },
+		func() error { return aggregation.NewSliceController(mgr, 0) },
+		func() error { return aggregation.NewCompositionController(mgr) },
+		func() error { return rollout.NewController(mgr, time.Millisecond) },
+		func() error { return rollout.NewSynthesizerController(mgr, 0) },
+		func() error { return synthflow.NewSynthesisConcurrencyLimiter(mgr, 10, 0, 0) },
+		func() error { return watch.NewController(mgr) },
+	} {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+
+	cache := reconstitution.NewCache(mgr.GetClient())
+	rc, err := reconciliation.New(reconciliation.Options{
+		Manager:               mgr,
+		Cache:                 cache,
+		WriteBuffer:           flowcontrol.NewResourceSliceWriteBufferForManager(mgr, time.Millisecond*10, 1),
+		Downstream:            cfg,
+		Timeout:               time.Minute,
+		ReadinessPollInterval: time.Second,
+		Faults:                o.faults,
+	})
+	if err != nil {
+		return err
+	}
+	return reconstitution.New(mgr, cache, rc)
+}
+
+// Synthesize creates the given synthesizer (if it doesn't already exist) and composition,
+// and waits for the composition's current synthesis to become ready.
+func (e *Environment) Synthesize(t *testing.T, synth *apiv1.Synthesizer, comp *apiv1.Composition) *apiv1.Composition {
+	t.Helper()
+	ctx := context.Background()
+
+	err := e.Client.Create(ctx, synth)
+	if client.IgnoreAlreadyExists(err) != nil {
+		require.NoError(t, err)
+	}
+	comp.Spec.Synthesizer.Name = synth.Name
+	require.NoError(t, e.Client.Create(ctx, comp))
+
+	e.Eventually(t, func() bool {
+		err := e.Client.Get(ctx, client.ObjectKeyFromObject(comp), comp)
+		return err == nil && comp.Status.CurrentSynthesis != nil && comp.Status.CurrentSynthesis.Ready != nil
+	})
+	return comp
+}
+
+// Eventually fails the test if fn doesn't return true within 30 seconds.
+func (e *Environment) Eventually(t *testing.T, fn func() bool) {
+	t.Helper()
+	start := time.Now()
+	for !fn() {
+		if time.Since(start) > time.Second*30 {
+			t.Fatalf("timeout while waiting for condition")
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+}
@@ -0,0 +1,41 @@
+package enotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+)
+
+func TestEnvironment(t *testing.T) {
+	env := NewEnvironment(t, func(ctx context.Context, s *apiv1.Synthesizer, rl *krmv1.ResourceList) (*krmv1.ResourceList, error) {
+		cm := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]any{"name": "test-cm", "namespace": "default"},
+			"data":       map[string]any{"foo": "bar"},
+		}}
+		return &krmv1.ResourceList{Items: []*unstructured.Unstructured{cm}}, nil
+	})
+
+	synth := &apiv1.Synthesizer{}
+	synth.Name = "test-synth"
+	synth.Spec.Image = "test-image"
+
+	comp := &apiv1.Composition{}
+	comp.Name = "test-comp"
+	comp.Namespace = "default"
+	env.Synthesize(t, synth, comp)
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = "test-cm"
+	cm.Namespace = "default"
+	require.NoError(t, env.Client.Get(context.Background(), client.ObjectKeyFromObject(cm), cm))
+	require.Equal(t, "bar", cm.Data["foo"])
+}
@@ -0,0 +1,25 @@
+package enotest
+
+import (
+	"context"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	apiv1 "github.com/Azure/eno/api/v1"
+	"github.com/Azure/eno/internal/execution"
+	krmv1 "github.com/Azure/eno/pkg/krm/functions/api/v1"
+)
+
+// Synthesizer is called in place of the synthesizer's container to synthesize a composition.
+type Synthesizer func(context.Context, *apiv1.Synthesizer, *krmv1.ResourceList) (*krmv1.ResourceList, error)
+
+// Exec runs the synthesizer's command (or "synthesize" if none is set) as a local process, exactly as it would be
+// run in the synthesizer's container. The binary must be on the PATH of the test.
+func Exec() Synthesizer {
+	return Synthesizer(execution.NewExecHandler())
+}
+
+// NewFakeExecutor runs synthesizer pods in-process by calling the given function, since envtest doesn't run pods.
+func NewFakeExecutor(mgr ctrl.Manager, fn Synthesizer) error {
+	return execution.RunPodsInProcess(mgr, execution.SynthesizerHandle(fn))
+}
@@ -0,0 +1,83 @@
+// Package faults injects failures and latency into Eno at well-known points, such that tests can prove
+// convergence under adverse conditions. Injectors are only configurable by tests and must never be used in production.
+package faults
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Point identifies a location in Eno at which faults can be injected.
+type Point string
+
+const (
+	// DownstreamWrite is reached before every create, patch, or delete of a downstream resource.
+	DownstreamWrite Point = "DownstreamWrite"
+
+	// Discovery is reached before every lookup of a downstream resource type's schema.
+	Discovery Point = "Discovery"
+
+	// StatusPatch is reached before every resource slice status patch. Failed patches are retried.
+	StatusPatch Point = "StatusPatch"
+)
+
+// ErrInjected is returned by the injectors in this package when they inject a failure.
+var ErrInjected = errors.New("injected fault")
+
+// Injector is called every time a fault point is reached.
+// Returning an error fails the operation at that point. Implementations may block to simulate latency.
+type Injector interface {
+	Inject(ctx context.Context, point Point) error
+}
+
+// Func adapts a function to the Injector interface.
+type Func func(ctx context.Context, point Point) error
+
+func (f Func) Inject(ctx context.Context, point Point) error { return f(ctx, point) }
+
+// Inject is a convenience function for calling injectors that may be nil.
+func Inject(ctx context.Context, inj Injector, point Point) error {
+	if inj == nil {
+		return nil
+	}
+	return inj.Inject(ctx, point)
+}
+
+// Random fails a fraction of the operations at the given points, and delays every operation at those points.
+// Every point is affected when Points is empty.
+type Random struct {
+	Points []Point
+	Rate   float64
+	Delay  time.Duration
+}
+
+func (r *Random) Inject(ctx context.Context, point Point) error {
+	if !r.matches(point) {
+		return nil
+	}
+	if r.Delay > 0 {
+		select {
+		case <-time.After(r.Delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if rand.Float64() < r.Rate {
+		return ErrInjected
+	}
+	return nil
+}
+
+func (r *Random) matches(point Point) bool {
+	if len(r.Points) == 0 {
+		return true
+	}
+	for _, p := range r.Points {
+		if p == point {
+			return true
+		}
+	}
+	return false
+}
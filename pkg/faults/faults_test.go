@@ -0,0 +1,48 @@
+package faults
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjectNil(t *testing.T) {
+	assert.NoError(t, Inject(context.Background(), nil, DownstreamWrite))
+}
+
+func TestRandom(t *testing.T) {
+	ctx := context.Background()
+
+	always := &Random{Rate: 1}
+	assert.ErrorIs(t, Inject(ctx, always, DownstreamWrite), ErrInjected)
+	assert.ErrorIs(t, Inject(ctx, always, StatusPatch), ErrInjected)
+
+	never := &Random{Rate: 0}
+	assert.NoError(t, Inject(ctx, never, DownstreamWrite))
+
+	filtered := &Random{Rate: 1, Points: []Point{Discovery}}
+	assert.ErrorIs(t, Inject(ctx, filtered, Discovery), ErrInjected)
+	assert.NoError(t, Inject(ctx, filtered, DownstreamWrite))
+}
+
+func TestRandomDelay(t *testing.T) {
+	start := time.Now()
+	assert.NoError(t, Inject(context.Background(), &Random{Delay: time.Millisecond * 10}, Discovery))
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, Inject(ctx, &Random{Delay: time.Hour}, Discovery), context.Canceled)
+}
+
+func TestFunc(t *testing.T) {
+	var points []Point
+	inj := Func(func(ctx context.Context, point Point) error {
+		points = append(points, point)
+		return nil
+	})
+	assert.NoError(t, Inject(context.Background(), inj, StatusPatch))
+	assert.Equal(t, []Point{StatusPatch}, points)
+}
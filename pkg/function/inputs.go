@@ -58,6 +58,19 @@ func (i *InputReader) All() map[string]*unstructured.Unstructured {
 	return m
 }
 
+// State returns the composition's synthesizer state, which is empty until a previous synthesis has set it with OutputWriter.SetState.
+func (i *InputReader) State() map[string]string {
+	for _, o := range i.resources.Items {
+		if getKey(o) == stateInputKey {
+			state, _, _ := unstructured.NestedStringMap(o.Object, "data")
+			if state != nil {
+				return state
+			}
+		}
+	}
+	return map[string]string{}
+}
+
 func getKey(obj client.Object) string {
 	if obj.GetAnnotations() == nil {
 		return ""
@@ -25,3 +25,14 @@ func TestInputReader(t *testing.T) {
 	err = ReadInput(r, "bar", cm)
 	require.EqualError(t, err, "input \"bar\" was not found")
 }
+
+func TestInputReaderState(t *testing.T) {
+	r, err := NewInputReader(bytes.NewBufferString(`{ "items": [] }`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{}, r.State())
+
+	input := bytes.NewBufferString(`{ "items": [{ "apiVersion": "eno.azure.io/v1", "kind": "SynthesizerState", "metadata": { "name": "test-comp", "annotations": { "eno.azure.io/input-key": "eno.azure.io/synthesizer-state" } }, "data": { "foo": "bar" } }] }`)
+	r, err = NewInputReader(input)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"foo": "bar"}, r.State())
+}
@@ -21,6 +21,8 @@ type OutputWriter struct {
 
 type MungeFunc func(*unstructured.Unstructured)
 
+const stateInputKey = "eno.azure.io/synthesizer-state"
+
 func NewDefaultOutputWriter() *OutputWriter {
 	return NewOutputWriter(os.Stdout, nil)
 }
@@ -62,6 +64,26 @@ func (w *OutputWriter) Add(outs ...client.Object) error {
 	return nil
 }
 
+// SetState records values (e.g. allocated names or IP ranges) in the composition's status.
+// They're passed back to subsequent syntheses, and can be read with InputReader.State.
+// The state is replaced by every successful synthesis that sets it, and retained by those that don't.
+func (w *OutputWriter) SetState(state map[string]string) error {
+	if w.committed {
+		return fmt.Errorf("cannot add to a committed output")
+	}
+	data := map[string]any{}
+	for key, val := range state {
+		data[key] = val
+	}
+	w.outputs = append(w.outputs, &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "eno.azure.io/v1",
+		"kind":       "SynthesizerState",
+		"metadata":   map[string]any{"name": "synthesizer-state"},
+		"data":       data,
+	}})
+	return nil
+}
+
 func (w *OutputWriter) Write() error {
 	rl := &krmv1.ResourceList{
 		Kind:       krmv1.ResourceListKind,
@@ -40,3 +40,14 @@ func TestOutputWriterMunge(t *testing.T) {
 	require.NoError(t, w.Write())
 	assert.Equal(t, "{\"apiVersion\":\"config.kubernetes.io/v1\",\"kind\":\"ResourceList\",\"items\":[{\"data\":{\"extra-val\":\"value from munge function\"},\"metadata\":{\"creationTimestamp\":null,\"name\":\"test-cm\"}}]}\n", out.String())
 }
+
+func TestOutputWriterSetState(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	w := NewOutputWriter(out, nil)
+
+	require.NoError(t, w.SetState(map[string]string{"foo": "bar"}))
+	require.NoError(t, w.Write())
+	assert.Equal(t, "{\"apiVersion\":\"config.kubernetes.io/v1\",\"kind\":\"ResourceList\",\"items\":[{\"apiVersion\":\"eno.azure.io/v1\",\"data\":{\"foo\":\"bar\"},\"kind\":\"SynthesizerState\",\"metadata\":{\"name\":\"synthesizer-state\"}}]}\n", out.String())
+
+	require.Error(t, w.SetState(nil))
+}